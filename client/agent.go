@@ -26,13 +26,19 @@ type AgentQARequest struct {
 type AgentResponseType string
 
 const (
-	AgentResponseTypeThinking   AgentResponseType = "thinking"
-	AgentResponseTypeToolCall   AgentResponseType = "tool_call"
-	AgentResponseTypeToolResult AgentResponseType = "tool_result"
-	AgentResponseTypeReferences AgentResponseType = "references"
-	AgentResponseTypeAnswer     AgentResponseType = "answer"
-	AgentResponseTypeReflection AgentResponseType = "reflection"
-	AgentResponseTypeError      AgentResponseType = "error"
+	AgentResponseTypeThinking     AgentResponseType = "thinking"
+	AgentResponseTypeToolCall     AgentResponseType = "tool_call"
+	AgentResponseTypeToolResult   AgentResponseType = "tool_result"
+	AgentResponseTypeToolApproval AgentResponseType = "tool_approval"
+	AgentResponseTypeReferences   AgentResponseType = "references"
+	AgentResponseTypeAnswer       AgentResponseType = "answer"
+	AgentResponseTypeReflection   AgentResponseType = "reflection"
+	AgentResponseTypeError        AgentResponseType = "error"
+	// AgentResponseTypePaused ends the stream of a paused run, resume it with ResumeAgentStream
+	AgentResponseTypePaused AgentResponseType = "paused"
+	// AgentResponseTypeSubAgent carries an event of an agent running a task delegated by the agent,
+	// Data["event_type"] holds the response type of the wrapped event
+	AgentResponseTypeSubAgent AgentResponseType = "sub_agent"
 )
 
 // AgentStreamResponse agent streaming response
@@ -86,6 +92,30 @@ func (c *Client) AgentQAStreamWithRequest(ctx context.Context,
 	return c.processAgentSSEStream(resp.Body, callback)
 }
 
+// ResumeAgentStream resumes a paused or interrupted agent run from its last completed round
+// and streams the events of the resumed rounds. Earlier events can be replayed with ContinueStream.
+func (c *Client) ResumeAgentStream(ctx context.Context,
+	sessionID string, messageID string, callback AgentEventCallback,
+) error {
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("messageID cannot be empty")
+	}
+
+	path := fmt.Sprintf("/api/v1/sessions/%s/resume", sessionID)
+	resp, err := c.doRequest(ctx, http.MethodPost, path, &ResumeSessionRequest{MessageID: messageID}, nil)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(body))
+	}
+
+	return c.processAgentSSEStream(resp.Body, callback)
+}
+
 // processAgentSSEStream processes the SSE stream and invokes callback for each event
 func (c *Client) processAgentSSEStream(reader io.Reader, callback AgentEventCallback) error {
 	scanner := bufio.NewScanner(reader)
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// API key scopes, the admin scope allows every API
+const (
+	APIKeyScopeSearch = "search"
+	APIKeyScopeChat   = "chat"
+	APIKeyScopeIngest = "ingest"
+	APIKeyScopeAdmin  = "admin"
+)
+
+// APIKey is a named tenant API key, only its prefix is returned after creation
+type APIKey struct {
+	ID               string     `json:"id"`
+	TenantID         uint64     `json:"tenant_id"`
+	Name             string     `json:"name"`
+	Prefix           string     `json:"prefix"`
+	Scopes           []string   `json:"scopes"`
+	KnowledgeBaseIDs []string   `json:"knowledge_base_ids"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedBy        string     `json:"created_by"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// CreateAPIKeyRequest is used to create an API key.
+// An empty KnowledgeBaseIDs allows every knowledge base and a nil ExpiresAt never expires.
+type CreateAPIKeyRequest struct {
+	Name             string     `json:"name"`
+	Scopes           []string   `json:"scopes"`
+	KnowledgeBaseIDs []string   `json:"knowledge_base_ids,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreatedAPIKey is a created API key with the key itself, which cannot be retrieved again
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKey creates an API key for the current tenant
+func (c *Client) CreateAPIKey(ctx context.Context, request *CreateAPIKeyRequest) (*CreatedAPIKey, error) {
+	resp, err := c.doRequest(ctx, http.MethodPost, "/api/v1/tenant/api-keys", request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool          `json:"success"`
+		Data    CreatedAPIKey `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// ListAPIKeys lists the API keys of the current tenant
+func (c *Client) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/tenant/api-keys", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool     `json:"success"`
+		Data    []APIKey `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// RevokeAPIKey revokes an API key
+func (c *Client) RevokeAPIKey(ctx context.Context, keyID string) error {
+	path := fmt.Sprintf("/api/v1/tenant/api-keys/%s", keyID)
+	resp, err := c.doRequest(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Success bool   `json:"success"`
+		Message string `json:"message,omitempty"`
+	}
+	return parseResponse(resp, &response)
+}
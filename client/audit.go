@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AuditLog is a record of an administrative or data access action.
+// The logs of a tenant form a hash chain, each log contains the hash of the previous one.
+type AuditLog struct {
+	ID           string          `json:"id"`
+	TenantID     uint64          `json:"tenant_id"`
+	Seq          uint64          `json:"seq"`
+	ActorType    string          `json:"actor_type"` // user, api_key, tenant_key or system
+	ActorID      string          `json:"actor_id"`
+	ActorName    string          `json:"actor_name"`
+	Action       string          `json:"action"` // e.g. knowledge_base.delete
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Method       string          `json:"method"`
+	Path         string          `json:"path"`
+	StatusCode   int             `json:"status_code"`
+	ClientIP     string          `json:"client_ip"`
+	RequestID    string          `json:"request_id"`
+	Changes      json.RawMessage `json:"changes"` // Changed fields with their values before and after
+	Detail       json.RawMessage `json:"detail"`
+	PrevHash     string          `json:"prev_hash"`
+	Hash         string          `json:"hash"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// AuditLogFilter filters audit logs, empty fields match every log
+type AuditLogFilter struct {
+	ActorID      string
+	ActorType    string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         *time.Time
+	To           *time.Time
+}
+
+// query converts the filter to query parameters
+func (f *AuditLogFilter) query() url.Values {
+	query := url.Values{}
+	if f == nil {
+		return query
+	}
+	for key, value := range map[string]string{
+		"actor_id":      f.ActorID,
+		"actor_type":    f.ActorType,
+		"action":        f.Action,
+		"resource_type": f.ResourceType,
+		"resource_id":   f.ResourceID,
+	} {
+		if value != "" {
+			query.Add(key, value)
+		}
+	}
+	if f.From != nil {
+		query.Add("from", f.From.Format(time.RFC3339))
+	}
+	if f.To != nil {
+		query.Add("to", f.To.Format(time.RFC3339))
+	}
+	return query
+}
+
+// AuditLogsPage contains paginated audit logs
+type AuditLogsPage struct {
+	Total    int64      `json:"total"`
+	Page     int        `json:"page"`
+	PageSize int        `json:"page_size"`
+	Logs     []AuditLog `json:"data"`
+}
+
+// AuditChainVerification is the result of verifying the audit log hash chain
+type AuditChainVerification struct {
+	Valid     bool   `json:"valid"`
+	Checked   int64  `json:"checked"`
+	FirstSeq  uint64 `json:"first_seq"`
+	LastSeq   uint64 `json:"last_seq"`
+	BrokenSeq uint64 `json:"broken_seq,omitempty"` // Sequence of the first changed or missing log
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ListAuditLogs lists the audit logs of the current tenant, newest first
+func (c *Client) ListAuditLogs(ctx context.Context,
+	filter *AuditLogFilter, page int, pageSize int,
+) (*AuditLogsPage, error) {
+	query := filter.query()
+	query.Add("page", strconv.Itoa(page))
+	query.Add("page_size", strconv.Itoa(pageSize))
+
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/audit-logs", nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool           `json:"success"`
+		Data    *AuditLogsPage `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// ExportAuditLogs writes the audit logs of the current tenant to w, oldest first.
+// The format is csv or jsonl.
+func (c *Client) ExportAuditLogs(ctx context.Context, filter *AuditLogFilter, format string, w io.Writer) error {
+	query := filter.query()
+	query.Add("format", format)
+
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/audit-logs/export", nil, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(body))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to write audit logs: %w", err)
+	}
+	return nil
+}
+
+// VerifyAuditChain verifies the audit log hash chain of the current tenant
+func (c *Client) VerifyAuditChain(ctx context.Context) (*AuditChainVerification, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/audit-logs/verify", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool                    `json:"success"`
+		Data    *AuditChainVerification `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
@@ -0,0 +1,221 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ConnectorConfig is the source configuration of a connector, the fields used depend on the connector type.
+type ConnectorConfig struct {
+	Path            string   `json:"path,omitempty"`
+	RepoURL         string   `json:"repo_url,omitempty"`
+	Branch          string   `json:"branch,omitempty"`
+	Username        string   `json:"username,omitempty"`
+	Token           string   `json:"token,omitempty"`
+	Endpoint        string   `json:"endpoint,omitempty"`
+	AccessKeyID     string   `json:"access_key_id,omitempty"`
+	SecretAccessKey string   `json:"secret_access_key,omitempty"`
+	Bucket          string   `json:"bucket,omitempty"`
+	Prefix          string   `json:"prefix,omitempty"`
+	UseSSL          bool     `json:"use_ssl,omitempty"`
+	SeedURL         string   `json:"seed_url,omitempty"`
+	MaxDepth        int      `json:"max_depth,omitempty"`
+	MaxPages        int      `json:"max_pages,omitempty"`
+	IncludePatterns []string `json:"include_patterns,omitempty"`
+	ExcludePatterns []string `json:"exclude_patterns,omitempty"`
+}
+
+// ConnectorSyncStats contains the results of a connector sync.
+type ConnectorSyncStats struct {
+	Listed    int      `json:"listed"`
+	Added     int      `json:"added"`
+	Updated   int      `json:"updated"`
+	Deleted   int      `json:"deleted"`
+	Unchanged int      `json:"unchanged"`
+	Skipped   int      `json:"skipped"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// Connector syncs the items of an external source (local, git, s3 or web) into a knowledge base.
+type Connector struct {
+	ID                  string             `json:"id"`
+	TenantID            uint64             `json:"tenant_id"`
+	KnowledgeBaseID     string             `json:"knowledge_base_id"`
+	Name                string             `json:"name"`
+	Type                string             `json:"type"`
+	Config              ConnectorConfig    `json:"config"`
+	Enabled             bool               `json:"enabled"`
+	SyncIntervalMinutes int                `json:"sync_interval_minutes"`
+	SyncStatus          string             `json:"sync_status"`
+	Cursor              string             `json:"cursor"`
+	LastError           string             `json:"last_error"`
+	LastSyncStats       ConnectorSyncStats `json:"last_sync_stats"`
+	ItemCount           int                `json:"item_count"`
+	LastSyncAt          *time.Time         `json:"last_sync_at"`
+	LastSuccessAt       *time.Time         `json:"last_success_at"`
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+}
+
+// ConnectorRequest is used to create or update a connector.
+type ConnectorRequest struct {
+	Name                string          `json:"name"`
+	Type                string          `json:"type,omitempty"`
+	Config              ConnectorConfig `json:"config"`
+	Enabled             *bool           `json:"enabled,omitempty"`
+	SyncIntervalMinutes int             `json:"sync_interval_minutes"`
+}
+
+// ConnectorSyncState is the sync state of a connector.
+type ConnectorSyncState struct {
+	ConnectorID     string             `json:"connector_id"`
+	KnowledgeBaseID string             `json:"knowledge_base_id"`
+	Type            string             `json:"type"`
+	Status          string             `json:"status"`
+	Cursor          string             `json:"cursor"`
+	LastError       string             `json:"last_error"`
+	LastSyncStats   ConnectorSyncStats `json:"last_sync_stats"`
+	ItemCount       int                `json:"item_count"`
+	LastSyncAt      *time.Time         `json:"last_sync_at"`
+	LastSuccessAt   *time.Time         `json:"last_success_at"`
+	NextSyncAt      *time.Time         `json:"next_sync_at"`
+}
+
+// ConnectorResponse wraps a single connector response.
+type ConnectorResponse struct {
+	Success bool      `json:"success"`
+	Data    Connector `json:"data"`
+}
+
+// ConnectorListResponse wraps a connector list response.
+type ConnectorListResponse struct {
+	Success bool        `json:"success"`
+	Data    []Connector `json:"data"`
+}
+
+// ConnectorSyncStateResponse wraps a connector sync state response.
+type ConnectorSyncStateResponse struct {
+	Success bool               `json:"success"`
+	Data    ConnectorSyncState `json:"data"`
+}
+
+// CreateConnector creates a connector for a knowledge base
+func (c *Client) CreateConnector(ctx context.Context,
+	knowledgeBaseID string, request *ConnectorRequest,
+) (*Connector, error) {
+	path := fmt.Sprintf("/api/v1/knowledge-bases/%s/connectors", knowledgeBaseID)
+	resp, err := c.doRequest(ctx, http.MethodPost, path, request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ConnectorResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// ListConnectors lists the connectors of a knowledge base
+func (c *Client) ListConnectors(ctx context.Context, knowledgeBaseID string) ([]Connector, error) {
+	path := fmt.Sprintf("/api/v1/knowledge-bases/%s/connectors", knowledgeBaseID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ConnectorListResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// GetConnector gets a connector by ID
+func (c *Client) GetConnector(ctx context.Context, connectorID string) (*Connector, error) {
+	path := fmt.Sprintf("/api/v1/connectors/%s", connectorID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ConnectorResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// UpdateConnector updates the name, configuration and schedule of a connector
+func (c *Client) UpdateConnector(ctx context.Context,
+	connectorID string, request *ConnectorRequest,
+) (*Connector, error) {
+	path := fmt.Sprintf("/api/v1/connectors/%s", connectorID)
+	resp, err := c.doRequest(ctx, http.MethodPut, path, request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ConnectorResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// DeleteConnector deletes a connector, the synced knowledge is deleted as well if deleteKnowledge is set
+func (c *Client) DeleteConnector(ctx context.Context, connectorID string, deleteKnowledge bool) error {
+	path := fmt.Sprintf("/api/v1/connectors/%s", connectorID)
+	query := url.Values{}
+	if deleteKnowledge {
+		query.Add("delete_knowledge", "true")
+	}
+	resp, err := c.doRequest(ctx, http.MethodDelete, path, nil, query)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Success bool   `json:"success"`
+		Message string `json:"message,omitempty"`
+	}
+	return parseResponse(resp, &response)
+}
+
+// SyncConnector enqueues a sync of a connector, force compares all items even if the source is unchanged
+func (c *Client) SyncConnector(ctx context.Context, connectorID string, force bool) (*ConnectorSyncState, error) {
+	path := fmt.Sprintf("/api/v1/connectors/%s/sync", connectorID)
+	query := url.Values{}
+	if force {
+		query.Add("force", "true")
+	}
+	resp, err := c.doRequest(ctx, http.MethodPost, path, nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ConnectorSyncStateResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// GetConnectorSyncStatus gets the sync state of a connector
+func (c *Client) GetConnectorSyncStatus(ctx context.Context, connectorID string) (*ConnectorSyncState, error) {
+	path := fmt.Sprintf("/api/v1/connectors/%s/sync-status", connectorID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ConnectorSyncStateResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
@@ -5,9 +5,15 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 )
 
 // EvaluationTask represents an evaluation task
@@ -43,10 +49,13 @@ type EvaluationResult struct {
 // EvaluationRequest represents an evaluation request
 // Parameters used to start a new evaluation task
 type EvaluationRequest struct {
-	DatasetID        string `json:"dataset_id"`   // Dataset ID to evaluate
-	EmbeddingModelID string `json:"embedding_id"` // Embedding model ID
-	ChatModelID      string `json:"chat_id"`      // Chat model ID
-	RerankModelID    string `json:"rerank_id"`    // Reranking model ID
+	Name             string `json:"name,omitempty"`              // Optional run name
+	DatasetID        string `json:"dataset_id"`                  // Dataset ID to evaluate, the built-in dataset when empty
+	KnowledgeBaseID  string `json:"knowledge_base_id,omitempty"` // Knowledge base to evaluate
+	AgentID          string `json:"agent_id,omitempty"`          // Custom agent whose configuration is evaluated
+	EmbeddingModelID string `json:"embedding_id"`                // Embedding model ID
+	ChatModelID      string `json:"chat_id"`                     // Chat model ID
+	RerankModelID    string `json:"rerank_id"`                   // Reranking model ID
 }
 
 // EvaluationTaskResponse represents an evaluation task response
@@ -111,3 +120,408 @@ func (c *Client) GetEvaluationResult(ctx context.Context, taskID string) (*Evalu
 
 	return &response.Data, nil
 }
+
+// Evaluation run statuses
+const (
+	EvaluationStatusPending = iota
+	EvaluationStatusRunning
+	EvaluationStatusSuccess
+	EvaluationStatusFailed
+)
+
+// EvaluationMetric contains the retrieval and generation metrics of a run or a question
+type EvaluationMetric struct {
+	RetrievalMetrics struct {
+		Precision float64 `json:"precision"`
+		Recall    float64 `json:"recall"`
+		NDCG3     float64 `json:"ndcg3"`
+		NDCG10    float64 `json:"ndcg10"`
+		MRR       float64 `json:"mrr"`
+		MAP       float64 `json:"map"`
+	} `json:"retrieval_metrics"`
+	GenerationMetrics struct {
+		BLEU1  float64 `json:"bleu1"`
+		BLEU2  float64 `json:"bleu2"`
+		BLEU4  float64 `json:"bleu4"`
+		ROUGE1 float64 `json:"rouge1"`
+		ROUGE2 float64 `json:"rouge2"`
+		ROUGEL float64 `json:"rougel"`
+	} `json:"generation_metrics"`
+}
+
+// EvaluationRunConfig is the retrieval and generation configuration a run was made with
+type EvaluationRunConfig struct {
+	ChatModelID              string  `json:"chat_model_id"`
+	RerankModelID            string  `json:"rerank_model_id"`
+	VectorThreshold          float64 `json:"vector_threshold"`
+	KeywordThreshold         float64 `json:"keyword_threshold"`
+	EmbeddingTopK            int     `json:"embedding_top_k"`
+	RerankTopK               int     `json:"rerank_top_k"`
+	RerankThreshold          float64 `json:"rerank_threshold"`
+	FAQPriorityEnabled       bool    `json:"faq_priority_enabled"`
+	FAQDirectAnswerThreshold float64 `json:"faq_direct_answer_threshold"`
+	FAQScoreBoost            float64 `json:"faq_score_boost"`
+	Temperature              float64 `json:"temperature"`
+}
+
+// EvaluationRun is a persisted evaluation run
+type EvaluationRun struct {
+	ID               string               `json:"id"`
+	Name             string               `json:"name"`
+	DatasetID        string               `json:"dataset_id"`
+	KnowledgeBaseIDs []string             `json:"knowledge_base_ids"`
+	AgentID          string               `json:"agent_id"`
+	Config           *EvaluationRunConfig `json:"config"`
+	Status           int                  `json:"status"`
+	ErrMsg           string               `json:"err_msg,omitempty"`
+	Total            int                  `json:"total"`
+	Finished         int                  `json:"finished"`
+	Metric           *EvaluationMetric    `json:"metric,omitempty"`
+	StartedAt        time.Time            `json:"started_at"`
+	FinishedAt       *time.Time           `json:"finished_at,omitempty"`
+	CreatedBy        string               `json:"created_by"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
+
+// EvaluationRunItem is the result of a question of a run
+type EvaluationRunItem struct {
+	RunID             string            `json:"run_id"`
+	Seq               int               `json:"seq"`
+	Question          string            `json:"question"`
+	ExpectedAnswer    string            `json:"expected_answer"`
+	GoldChunkIDs      []string          `json:"gold_chunk_ids"`
+	RetrievedChunkIDs []string          `json:"retrieved_chunk_ids"`
+	Answer            string            `json:"answer"`
+	Metric            *EvaluationMetric `json:"metric"`
+	Error             string            `json:"error,omitempty"`
+	LatencyMs         int64             `json:"latency_ms"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+// EvaluationRunFilter filters evaluation runs, empty fields match every run
+type EvaluationRunFilter struct {
+	DatasetID string
+	AgentID   string
+}
+
+// EvaluationRunsPage contains paginated evaluation runs
+type EvaluationRunsPage struct {
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Runs     []EvaluationRun `json:"data"`
+}
+
+// EvaluationRunItemsPage contains paginated per-question results
+type EvaluationRunItemsPage struct {
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	Items    []EvaluationRunItem `json:"data"`
+}
+
+// EvaluationDataset is an uploaded QA dataset
+type EvaluationDataset struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Format      string    `json:"format"`
+	ItemCount   int       `json:"item_count"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// EvaluationDatasetItem is a question of an uploaded dataset
+type EvaluationDatasetItem struct {
+	DatasetID    string   `json:"dataset_id"`
+	Seq          int      `json:"seq"`
+	Question     string   `json:"question"`
+	Answer       string   `json:"answer"`
+	GoldChunkIDs []string `json:"gold_chunk_ids"`
+}
+
+// EvaluationDatasetsPage contains paginated datasets
+type EvaluationDatasetsPage struct {
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	Datasets []EvaluationDataset `json:"data"`
+}
+
+// EvaluationDatasetItemsPage contains paginated dataset questions
+type EvaluationDatasetItemsPage struct {
+	Total    int64                   `json:"total"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+	Items    []EvaluationDatasetItem `json:"data"`
+}
+
+// MetricRegression is a retrieval metric that dropped more than the tolerance
+type MetricRegression struct {
+	Metric string  `json:"metric"`
+	Base   float64 `json:"base"`
+	Target float64 `json:"target"`
+	Delta  float64 `json:"delta"`
+}
+
+// QuestionComparison compares the results of a question in two runs
+type QuestionComparison struct {
+	Question  string             `json:"question"`
+	Base      *EvaluationRunItem `json:"base"`
+	Target    *EvaluationRunItem `json:"target"`
+	Delta     *EvaluationMetric  `json:"delta"`
+	Regressed bool               `json:"regressed"`
+}
+
+// EvaluationComparison compares a target run with a base run side by side.
+// Passed is false when a retrieval metric dropped more than the tolerance.
+type EvaluationComparison struct {
+	Base        *EvaluationRun       `json:"base"`
+	Target      *EvaluationRun       `json:"target"`
+	Tolerance   float64              `json:"tolerance"`
+	Delta       *EvaluationMetric    `json:"delta"`
+	Regressions []MetricRegression   `json:"regressions"`
+	Passed      bool                 `json:"passed"`
+	Questions   []QuestionComparison `json:"questions"`
+	Unmatched   int                  `json:"unmatched"`
+}
+
+// pageQuery returns the pagination query parameters
+func pageQuery(page, pageSize int) url.Values {
+	query := url.Values{}
+	query.Add("page", strconv.Itoa(page))
+	query.Add("page_size", strconv.Itoa(pageSize))
+	return query
+}
+
+// ListEvaluationRuns lists the evaluation runs of the current tenant, newest first
+func (c *Client) ListEvaluationRuns(ctx context.Context,
+	filter *EvaluationRunFilter, page int, pageSize int,
+) (*EvaluationRunsPage, error) {
+	query := pageQuery(page, pageSize)
+	if filter != nil {
+		if filter.DatasetID != "" {
+			query.Add("dataset_id", filter.DatasetID)
+		}
+		if filter.AgentID != "" {
+			query.Add("agent_id", filter.AgentID)
+		}
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/evaluation/runs", nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool                `json:"success"`
+		Data    *EvaluationRunsPage `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// GetEvaluationRun gets an evaluation run
+func (c *Client) GetEvaluationRun(ctx context.Context, runID string) (*EvaluationRun, error) {
+	path := fmt.Sprintf("/api/v1/evaluation/runs/%s", runID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool          `json:"success"`
+		Data    EvaluationRun `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// ListEvaluationRunItems lists the per-question results of an evaluation run in dataset order
+func (c *Client) ListEvaluationRunItems(ctx context.Context,
+	runID string, page int, pageSize int,
+) (*EvaluationRunItemsPage, error) {
+	path := fmt.Sprintf("/api/v1/evaluation/runs/%s/items", runID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, pageQuery(page, pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool                    `json:"success"`
+		Data    *EvaluationRunItemsPage `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// DeleteEvaluationRun deletes a finished evaluation run and its results
+func (c *Client) DeleteEvaluationRun(ctx context.Context, runID string) error {
+	path := fmt.Sprintf("/api/v1/evaluation/runs/%s", runID)
+	resp, err := c.doRequest(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Success bool   `json:"success"`
+		Message string `json:"message,omitempty"`
+	}
+	return parseResponse(resp, &response)
+}
+
+// CompareEvaluationRuns compares a target run with a base run. The comparison does not pass when
+// a retrieval metric dropped more than the tolerance, which can gate configuration changes.
+func (c *Client) CompareEvaluationRuns(ctx context.Context,
+	baseRunID, targetRunID string, tolerance float64,
+) (*EvaluationComparison, error) {
+	query := url.Values{}
+	query.Add("base", baseRunID)
+	query.Add("target", targetRunID)
+	query.Add("tolerance", strconv.FormatFloat(tolerance, 'f', -1, 64))
+
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/evaluation/compare", nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool                 `json:"success"`
+		Data    EvaluationComparison `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// UploadEvaluationDataset uploads a CSV or JSONL dataset, the format is taken from the file name extension.
+// Each question needs gold_chunk_ids, an answer or both.
+func (c *Client) UploadEvaluationDataset(ctx context.Context,
+	filename string, r io.Reader, name, description string,
+) (*EvaluationDataset, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, fmt.Errorf("failed to copy file content: %w", err)
+	}
+	for key, value := range map[string]string{"name": name, "description": description} {
+		if value == "" {
+			continue
+		}
+		if err := writer.WriteField(key, value); err != nil {
+			return nil, fmt.Errorf("failed to write %s field: %w", key, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close writer: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/evaluation/datasets", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if c.token != "" {
+		req.Header.Set("X-API-Key", c.token)
+	}
+	if requestID := ctx.Value("RequestID"); requestID != nil {
+		req.Header.Set("X-Request-ID", requestID.(string))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var response struct {
+		Success bool              `json:"success"`
+		Data    EvaluationDataset `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// ListEvaluationDatasets lists the uploaded datasets of the current tenant, newest first
+func (c *Client) ListEvaluationDatasets(ctx context.Context, page int, pageSize int) (*EvaluationDatasetsPage, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/evaluation/datasets", nil, pageQuery(page, pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool                    `json:"success"`
+		Data    *EvaluationDatasetsPage `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// GetEvaluationDataset gets an uploaded dataset
+func (c *Client) GetEvaluationDataset(ctx context.Context, datasetID string) (*EvaluationDataset, error) {
+	path := fmt.Sprintf("/api/v1/evaluation/datasets/%s", datasetID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool              `json:"success"`
+		Data    EvaluationDataset `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// ListEvaluationDatasetItems lists the questions of an uploaded dataset in order
+func (c *Client) ListEvaluationDatasetItems(ctx context.Context,
+	datasetID string, page int, pageSize int,
+) (*EvaluationDatasetItemsPage, error) {
+	path := fmt.Sprintf("/api/v1/evaluation/datasets/%s/items", datasetID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, pageQuery(page, pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool                        `json:"success"`
+		Data    *EvaluationDatasetItemsPage `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// DeleteEvaluationDataset deletes an uploaded dataset, runs made with it are kept
+func (c *Client) DeleteEvaluationDataset(ctx context.Context, datasetID string) error {
+	path := fmt.Sprintf("/api/v1/evaluation/datasets/%s", datasetID)
+	resp, err := c.doRequest(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Success bool   `json:"success"`
+		Message string `json:"message,omitempty"`
+	}
+	return parseResponse(resp, &response)
+}
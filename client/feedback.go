@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Feedback ratings
+const (
+	FeedbackRatingUp   = "up"
+	FeedbackRatingDown = "down"
+)
+
+// Feedback reasons
+const (
+	FeedbackReasonIncorrect     = "incorrect"
+	FeedbackReasonIncomplete    = "incomplete"
+	FeedbackReasonWrongCitation = "wrong_citation"
+	FeedbackReasonIrrelevant    = "irrelevant"
+	FeedbackReasonOutdated      = "outdated"
+	FeedbackReasonOther         = "other"
+)
+
+// ReferenceFeedback is the relevance of a chunk cited by an answer
+type ReferenceFeedback struct {
+	ChunkID     string `json:"chunk_id"`
+	KnowledgeID string `json:"knowledge_id,omitempty"`
+	Relevant    bool   `json:"relevant"`
+}
+
+// MessageFeedback is the feedback on an assistant message
+type MessageFeedback struct {
+	Rating     string              `json:"rating"`
+	Reason     string              `json:"reason,omitempty"`
+	Comment    string              `json:"comment,omitempty"`
+	References []ReferenceFeedback `json:"references,omitempty"`
+	UserID     string              `json:"user_id,omitempty"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+// SubmitFeedbackRequest is used to submit feedback on an assistant message.
+// Referenced chunks must be among the knowledge references of the message.
+type SubmitFeedbackRequest struct {
+	Rating     string              `json:"rating"`
+	Reason     string              `json:"reason,omitempty"`
+	Comment    string              `json:"comment,omitempty"`
+	References []ReferenceFeedback `json:"references,omitempty"`
+}
+
+// FeedbackFilter filters feedback, empty fields match every feedback
+type FeedbackFilter struct {
+	Rating    string
+	Reason    string
+	SessionID string
+	From      *time.Time
+	To        *time.Time
+}
+
+// query converts the filter to query parameters
+func (f *FeedbackFilter) query() url.Values {
+	query := url.Values{}
+	if f == nil {
+		return query
+	}
+	for key, value := range map[string]string{
+		"rating":     f.Rating,
+		"reason":     f.Reason,
+		"session_id": f.SessionID,
+	} {
+		if value != "" {
+			query.Add(key, value)
+		}
+	}
+	if f.From != nil {
+		query.Add("from", f.From.Format(time.RFC3339))
+	}
+	if f.To != nil {
+		query.Add("to", f.To.Format(time.RFC3339))
+	}
+	return query
+}
+
+// FeedbackRecord is an assistant message with feedback and its question
+type FeedbackRecord struct {
+	MessageID           string           `json:"message_id"`
+	SessionID           string           `json:"session_id"`
+	RequestID           string           `json:"request_id"`
+	Question            string           `json:"question"`
+	Answer              string           `json:"answer"`
+	KnowledgeReferences []*SearchResult  `json:"knowledge_references"`
+	Feedback            *MessageFeedback `json:"feedback"`
+	FeedbackAt          time.Time        `json:"feedback_at"`
+}
+
+// FeedbackPage contains paginated feedback
+type FeedbackPage struct {
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+	Records  []FeedbackRecord `json:"data"`
+}
+
+// ChunkFeedbackSignal is the relevance signal aggregated from the feedback on a chunk,
+// scores range from -1 (demoted) to 1 (boosted)
+type ChunkFeedbackSignal struct {
+	ChunkID     string    `json:"chunk_id"`
+	KnowledgeID string    `json:"knowledge_id"`
+	Positive    float64   `json:"positive"`
+	Negative    float64   `json:"negative"`
+	Score       float64   `json:"score"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ChunkFeedbackSignalsPage contains paginated chunk relevance signals
+type ChunkFeedbackSignalsPage struct {
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+	Signals  []ChunkFeedbackSignal `json:"data"`
+}
+
+// SubmitFeedback sets the feedback on an assistant message, replacing the previous one
+func (c *Client) SubmitFeedback(ctx context.Context,
+	sessionID, messageID string, request *SubmitFeedbackRequest,
+) (*MessageFeedback, error) {
+	path := fmt.Sprintf("/api/v1/messages/%s/%s/feedback", sessionID, messageID)
+	resp, err := c.doRequest(ctx, http.MethodPut, path, request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool            `json:"success"`
+		Data    MessageFeedback `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// DeleteFeedback removes the feedback on an assistant message
+func (c *Client) DeleteFeedback(ctx context.Context, sessionID, messageID string) error {
+	path := fmt.Sprintf("/api/v1/messages/%s/%s/feedback", sessionID, messageID)
+	resp, err := c.doRequest(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Success bool   `json:"success"`
+		Message string `json:"message,omitempty"`
+	}
+	return parseResponse(resp, &response)
+}
+
+// ListFeedback lists the feedback of the current tenant, newest first
+func (c *Client) ListFeedback(ctx context.Context,
+	filter *FeedbackFilter, page int, pageSize int,
+) (*FeedbackPage, error) {
+	query := filter.query()
+	query.Add("page", strconv.Itoa(page))
+	query.Add("page_size", strconv.Itoa(pageSize))
+
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/feedback", nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool          `json:"success"`
+		Data    *FeedbackPage `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// ListChunkFeedbackSignals lists the chunk relevance signals of the current tenant, strongest first
+func (c *Client) ListChunkFeedbackSignals(ctx context.Context,
+	page int, pageSize int,
+) (*ChunkFeedbackSignalsPage, error) {
+	query := url.Values{}
+	query.Add("page", strconv.Itoa(page))
+	query.Add("page_size", strconv.Itoa(pageSize))
+
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/feedback/chunk-signals", nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool                      `json:"success"`
+		Data    *ChunkFeedbackSignalsPage `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// ExportFeedbackDataset writes the evaluation dataset built from the feedback matching the filter to w.
+// Each JSONL line has the question, the helpful answer and the gold_chunk_ids judged relevant.
+func (c *Client) ExportFeedbackDataset(ctx context.Context, filter *FeedbackFilter, w io.Writer) error {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/feedback/dataset", nil, filter.query())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(body))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to write feedback dataset: %w", err)
+	}
+	return nil
+}
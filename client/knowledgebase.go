@@ -163,6 +163,28 @@ type KBCloneProgress struct {
 	UpdatedAt int64  `json:"updated_at"`
 }
 
+// ReembedKnowledgeBaseRequest represents the request to re-embed a knowledge base with a new embedding model
+// An empty EmbeddingModelID reindexes the knowledge base with its current model
+type ReembedKnowledgeBaseRequest struct {
+	EmbeddingModelID string `json:"embedding_model_id,omitempty"`
+}
+
+// KBReembedProgress represents the progress of a knowledge base re-embedding task
+type KBReembedProgress struct {
+	TaskID          string `json:"task_id"`
+	KnowledgeBaseID string `json:"knowledge_base_id"`
+	SourceModelID   string `json:"source_model_id"`
+	TargetModelID   string `json:"target_model_id"`
+	Status          string `json:"status"`    // pending, processing, switching, completed, failed
+	Progress        int    `json:"progress"`  // 0-100
+	Total           int    `json:"total"`     // Total chunks count
+	Processed       int    `json:"processed"` // Processed chunks count
+	Message         string `json:"message"`
+	Error           string `json:"error,omitempty"`
+	CreatedAt       int64  `json:"created_at"`
+	UpdatedAt       int64  `json:"updated_at"`
+}
+
 // CreateKnowledgeBase creates a knowledge base
 func (c *Client) CreateKnowledgeBase(ctx context.Context, knowledgeBase *KnowledgeBase) (*KnowledgeBase, error) {
 	resp, err := c.doRequest(ctx, http.MethodPost, "/api/v1/knowledge-bases", knowledgeBase, nil)
@@ -259,6 +281,12 @@ type SearchParams struct {
 	MatchCount           int     `json:"match_count"`
 	DisableKeywordsMatch bool    `json:"disable_keywords_match"`
 	DisableVectorMatch   bool    `json:"disable_vector_match"`
+	// KnowledgeIDs limits the search to the given knowledge entries
+	KnowledgeIDs []string `json:"knowledge_ids,omitempty"`
+	// AsOf searches the chunks that were valid at the given time, including previous versions
+	AsOf *time.Time `json:"as_of,omitempty"`
+	// AsOfVersion searches the chunks of a version of the only knowledge in KnowledgeIDs
+	AsOfVersion int `json:"as_of_version,omitempty"`
 }
 
 // HybridSearch performs hybrid search
@@ -321,3 +349,46 @@ func (c *Client) GetKBCloneProgress(ctx context.Context, taskID string) (*KBClon
 
 	return &response.Data, nil
 }
+
+// ReembedKnowledgeBase re-embeds a knowledge base with a new embedding model asynchronously
+// Adding, editing or deleting knowledge and chunks of the knowledge base fails with a conflict until the task completes
+func (c *Client) ReembedKnowledgeBase(ctx context.Context, knowledgeBaseID string, request *ReembedKnowledgeBaseRequest) (*KBReembedProgress, error) {
+	path := fmt.Sprintf("/api/v1/knowledge-bases/%s/reembed", knowledgeBaseID)
+
+	resp, err := c.doRequest(ctx, http.MethodPost, path, request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool              `json:"success"`
+		Data    KBReembedProgress `json:"data"`
+	}
+
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.Data, nil
+}
+
+// GetKBReembedProgress gets the progress of a knowledge base re-embedding task
+func (c *Client) GetKBReembedProgress(ctx context.Context, taskID string) (*KBReembedProgress, error) {
+	path := fmt.Sprintf("/api/v1/knowledge-bases/reembed/progress/%s", taskID)
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool              `json:"success"`
+		Data    KBReembedProgress `json:"data"`
+	}
+
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.Data, nil
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// User memory categories
+const (
+	UserMemoryFact       = "fact"
+	UserMemoryPreference = "preference"
+)
+
+// UserMemory is a fact or preference remembered about the signed-in user across sessions
+type UserMemory struct {
+	ID              string    `json:"id"`
+	TenantID        uint64    `json:"tenant_id"`
+	UserID          string    `json:"user_id"`
+	Category        string    `json:"category"`
+	Content         string    `json:"content"`
+	SourceSessionID string    `json:"source_session_id"`
+	SourceMessageID string    `json:"source_message_id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// UserMemoriesPage contains paginated user memories
+type UserMemoriesPage struct {
+	Total    int64        `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+	Memories []UserMemory `json:"data"`
+}
+
+// UpdateUserMemoryRequest is used to edit a memory, an empty Category leaves it unchanged
+type UpdateUserMemoryRequest struct {
+	Content  string `json:"content"`
+	Category string `json:"category,omitempty"`
+}
+
+// ListMemories lists the memories of the signed-in user, most recently updated first.
+// Category and keyword filter the memories when not empty.
+func (c *Client) ListMemories(ctx context.Context,
+	category, keyword string, page int, pageSize int,
+) (*UserMemoriesPage, error) {
+	query := url.Values{}
+	if category != "" {
+		query.Add("category", category)
+	}
+	if keyword != "" {
+		query.Add("keyword", keyword)
+	}
+	query.Add("page", strconv.Itoa(page))
+	query.Add("page_size", strconv.Itoa(pageSize))
+
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/memories", nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool              `json:"success"`
+		Data    *UserMemoriesPage `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// GetMemory gets a memory of the signed-in user
+func (c *Client) GetMemory(ctx context.Context, memoryID string) (*UserMemory, error) {
+	path := fmt.Sprintf("/api/v1/memories/%s", memoryID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool       `json:"success"`
+		Data    UserMemory `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// UpdateMemory edits a memory of the signed-in user
+func (c *Client) UpdateMemory(ctx context.Context,
+	memoryID string, request *UpdateUserMemoryRequest,
+) (*UserMemory, error) {
+	path := fmt.Sprintf("/api/v1/memories/%s", memoryID)
+	resp, err := c.doRequest(ctx, http.MethodPut, path, request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool       `json:"success"`
+		Data    UserMemory `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// DeleteMemory deletes a memory of the signed-in user, it is no longer injected into prompts
+func (c *Client) DeleteMemory(ctx context.Context, memoryID string) error {
+	path := fmt.Sprintf("/api/v1/memories/%s", memoryID)
+	resp, err := c.doRequest(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Success bool   `json:"success"`
+		Message string `json:"message,omitempty"`
+	}
+	return parseResponse(resp, &response)
+}
+
+// DeleteAllMemories deletes every memory of the signed-in user and returns how many were deleted
+func (c *Client) DeleteAllMemories(ctx context.Context) (int64, error) {
+	resp, err := c.doRequest(ctx, http.MethodDelete, "/api/v1/memories", nil, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Deleted int64 `json:"deleted"`
+		} `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return 0, err
+	}
+	return response.Data.Deleted, nil
+}
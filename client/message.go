@@ -40,16 +40,17 @@ type AgentStep struct {
 
 // Message message information
 type Message struct {
-	ID                  string          `json:"id"`
-	SessionID           string          `json:"session_id"`
-	RequestID           string          `json:"request_id"`
-	Content             string          `json:"content"`
-	Role                string          `json:"role"`
-	KnowledgeReferences []*SearchResult `json:"knowledge_references"`
-	AgentSteps          []AgentStep     `json:"agent_steps,omitempty"` // Agent execution steps (only for assistant messages)
-	IsCompleted         bool            `json:"is_completed"`
-	CreatedAt           time.Time       `json:"created_at"`
-	UpdatedAt           time.Time       `json:"updated_at"`
+	ID                  string           `json:"id"`
+	SessionID           string           `json:"session_id"`
+	RequestID           string           `json:"request_id"`
+	Content             string           `json:"content"`
+	Role                string           `json:"role"`
+	KnowledgeReferences []*SearchResult  `json:"knowledge_references"`
+	AgentSteps          []AgentStep      `json:"agent_steps,omitempty"` // Agent execution steps (only for assistant messages)
+	Feedback            *MessageFeedback `json:"feedback,omitempty"`    // Feedback on an assistant message
+	IsCompleted         bool             `json:"is_completed"`
+	CreatedAt           time.Time        `json:"created_at"`
+	UpdatedAt           time.Time        `json:"updated_at"`
 }
 
 // MessageListResponse message list response
@@ -0,0 +1,312 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Tenant roles, from the least to the most privileged
+const (
+	TenantRoleViewer = "viewer"
+	TenantRoleEditor = "editor"
+	TenantRoleAdmin  = "admin"
+	TenantRoleOwner  = "owner"
+)
+
+// Resource permissions, a higher permission includes the lower ones
+const (
+	PermissionRead   = "read"
+	PermissionWrite  = "write"
+	PermissionManage = "manage"
+)
+
+// Resource types that can be granted
+const (
+	ResourceTypeKnowledgeBase = "knowledge_base"
+	ResourceTypeAgent         = "agent"
+	ResourceTypeMCPService    = "mcp_service"
+)
+
+// Grant subject types
+const (
+	GrantSubjectUser  = "user"
+	GrantSubjectGroup = "group"
+)
+
+// Member is a user of the tenant with its role
+type Member struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Avatar    string    `json:"avatar"`
+	TenantID  uint64    `json:"tenant_id"`
+	IsActive  bool      `json:"is_active"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateMemberRequest is used to create a user account in the tenant
+type CreateMemberRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// UserGroup is a group of tenant members that resources can be granted to
+type UserGroup struct {
+	ID          string    `json:"id"`
+	TenantID    uint64    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserGroupRequest is used to create or update a user group
+type UserGroupRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ResourceGrant grants a permission on a resource to a user or a user group.
+// A resource with grants is only accessible to admins and the granted subjects.
+type ResourceGrant struct {
+	ID           string    `json:"id"`
+	TenantID     uint64    `json:"tenant_id"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	SubjectType  string    `json:"subject_type"`
+	SubjectID    string    `json:"subject_id"`
+	Permission   string    `json:"permission"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SetGrantRequest is used to grant a permission on a resource
+type SetGrantRequest struct {
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	SubjectType  string `json:"subject_type"`
+	SubjectID    string `json:"subject_id"`
+	Permission   string `json:"permission"`
+}
+
+// MemberResponse wraps a single member response
+type MemberResponse struct {
+	Success bool   `json:"success"`
+	Data    Member `json:"data"`
+}
+
+// MemberListResponse wraps a member list response
+type MemberListResponse struct {
+	Success bool     `json:"success"`
+	Data    []Member `json:"data"`
+}
+
+// UserGroupResponse wraps a single user group response
+type UserGroupResponse struct {
+	Success bool      `json:"success"`
+	Data    UserGroup `json:"data"`
+}
+
+// UserGroupListResponse wraps a user group list response
+type UserGroupListResponse struct {
+	Success bool        `json:"success"`
+	Data    []UserGroup `json:"data"`
+}
+
+// ResourceGrantResponse wraps a single grant response
+type ResourceGrantResponse struct {
+	Success bool          `json:"success"`
+	Data    ResourceGrant `json:"data"`
+}
+
+// ResourceGrantListResponse wraps a grant list response
+type ResourceGrantListResponse struct {
+	Success bool            `json:"success"`
+	Data    []ResourceGrant `json:"data"`
+}
+
+// ListMembers lists the members of the tenant
+func (c *Client) ListMembers(ctx context.Context) ([]Member, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/tenant/members", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response MemberListResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// CreateMember creates a user account in the tenant with the given role
+func (c *Client) CreateMember(ctx context.Context, request *CreateMemberRequest) (*Member, error) {
+	resp, err := c.doRequest(ctx, http.MethodPost, "/api/v1/tenant/members", request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response MemberResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// UpdateMemberRole changes the role of a tenant member
+func (c *Client) UpdateMemberRole(ctx context.Context, userID string, role string) (*Member, error) {
+	path := fmt.Sprintf("/api/v1/tenant/members/%s/role", userID)
+	resp, err := c.doRequest(ctx, http.MethodPut, path, map[string]string{"role": role}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response MemberResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// RemoveMember deletes a member from the tenant
+func (c *Client) RemoveMember(ctx context.Context, userID string) error {
+	path := fmt.Sprintf("/api/v1/tenant/members/%s", userID)
+	return c.doSuccessRequest(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// ListUserGroups lists the user groups of the tenant
+func (c *Client) ListUserGroups(ctx context.Context) ([]UserGroup, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/groups", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response UserGroupListResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// CreateUserGroup creates a user group
+func (c *Client) CreateUserGroup(ctx context.Context, request *UserGroupRequest) (*UserGroup, error) {
+	resp, err := c.doRequest(ctx, http.MethodPost, "/api/v1/groups", request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response UserGroupResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// UpdateUserGroup updates the name and description of a user group
+func (c *Client) UpdateUserGroup(ctx context.Context, groupID string, request *UserGroupRequest) (*UserGroup, error) {
+	path := fmt.Sprintf("/api/v1/groups/%s", groupID)
+	resp, err := c.doRequest(ctx, http.MethodPut, path, request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response UserGroupResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// DeleteUserGroup deletes a user group with its members and grants
+func (c *Client) DeleteUserGroup(ctx context.Context, groupID string) error {
+	path := fmt.Sprintf("/api/v1/groups/%s", groupID)
+	return c.doSuccessRequest(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// ListUserGroupMembers lists the members of a user group
+func (c *Client) ListUserGroupMembers(ctx context.Context, groupID string) ([]Member, error) {
+	path := fmt.Sprintf("/api/v1/groups/%s/members", groupID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response MemberListResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// AddUserGroupMember adds a tenant member to a user group
+func (c *Client) AddUserGroupMember(ctx context.Context, groupID string, userID string) error {
+	path := fmt.Sprintf("/api/v1/groups/%s/members", groupID)
+	return c.doSuccessRequest(ctx, http.MethodPost, path, map[string]string{"user_id": userID}, nil)
+}
+
+// RemoveUserGroupMember removes a member from a user group
+func (c *Client) RemoveUserGroupMember(ctx context.Context, groupID string, userID string) error {
+	path := fmt.Sprintf("/api/v1/groups/%s/members/%s", groupID, userID)
+	return c.doSuccessRequest(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// ListGrants lists the grants of a resource, which requires the manage permission on it
+func (c *Client) ListGrants(ctx context.Context, resourceType string, resourceID string) ([]ResourceGrant, error) {
+	query := url.Values{}
+	query.Add("resource_type", resourceType)
+	query.Add("resource_id", resourceID)
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/grants", nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ResourceGrantListResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// SetGrant grants a permission on a resource, replacing the permission of an existing grant to the same subject
+func (c *Client) SetGrant(ctx context.Context, request *SetGrantRequest) (*ResourceGrant, error) {
+	resp, err := c.doRequest(ctx, http.MethodPost, "/api/v1/grants", request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ResourceGrantResponse
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// DeleteGrant deletes a grant
+func (c *Client) DeleteGrant(ctx context.Context, grantID string) error {
+	path := fmt.Sprintf("/api/v1/grants/%s", grantID)
+	return c.doSuccessRequest(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// doSuccessRequest sends a request whose response carries no data
+func (c *Client) doSuccessRequest(ctx context.Context,
+	method, path string, body interface{}, query url.Values,
+) error {
+	resp, err := c.doRequest(ctx, method, path, body, query)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Success bool   `json:"success"`
+		Message string `json:"message,omitempty"`
+	}
+	return parseResponse(resp, &response)
+}
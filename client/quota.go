@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Quota subject types
+const (
+	QuotaSubjectTenant = "tenant"
+	QuotaSubjectUser   = "user"
+	QuotaSubjectAPIKey = "api_key"
+)
+
+// QuotaLimits are the limits of a quota subject, zero means unlimited.
+// Daily limits reset at UTC midnight.
+type QuotaLimits struct {
+	RequestsPerMinute   int   `json:"requests_per_minute"`
+	ConcurrentStreams   int   `json:"concurrent_streams"`
+	DailyTokens         int64 `json:"daily_tokens"`
+	DailyIngestionBytes int64 `json:"daily_ingestion_bytes"`
+}
+
+// QuotaUsage is the current usage of a quota subject
+type QuotaUsage struct {
+	ConcurrentStreams   int64 `json:"concurrent_streams"`
+	DailyTokens         int64 `json:"daily_tokens"`
+	DailyIngestionBytes int64 `json:"daily_ingestion_bytes"`
+}
+
+// QuotaStatus is the limits and usage of one subject a request is counted against
+type QuotaStatus struct {
+	Type   string      `json:"type"` // tenant, user or api_key
+	ID     string      `json:"id"`
+	Custom bool        `json:"custom"` // The limits come from a policy instead of the defaults
+	Limits QuotaLimits `json:"limits"`
+	Usage  QuotaUsage  `json:"usage"`
+}
+
+// QuotaPolicy replaces the default limits of a subject
+type QuotaPolicy struct {
+	TenantID    uint64 `json:"tenant_id"`
+	SubjectType string `json:"subject_type"`
+	SubjectID   string `json:"subject_id"` // Empty for tenant policies
+	QuotaLimits
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetQuotaPolicyRequest sets the limits of a subject
+type SetQuotaPolicyRequest struct {
+	SubjectType string `json:"subject_type"`
+	SubjectID   string `json:"subject_id,omitempty"`
+	QuotaLimits
+}
+
+// GetQuotaStatus returns the limits and usage of the tenant and the user or API key of the client
+func (c *Client) GetQuotaStatus(ctx context.Context) ([]QuotaStatus, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/quotas", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool          `json:"success"`
+		Data    []QuotaStatus `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// ListQuotaPolicies lists the quota policies of the current tenant
+func (c *Client) ListQuotaPolicies(ctx context.Context) ([]QuotaPolicy, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/quotas/policies", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool          `json:"success"`
+		Data    []QuotaPolicy `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// SetQuotaPolicy sets the limits of a tenant, user or API key.
+// Tenant policies can only be set by users with cross-tenant access.
+func (c *Client) SetQuotaPolicy(ctx context.Context, request *SetQuotaPolicyRequest) (*QuotaPolicy, error) {
+	resp, err := c.doRequest(ctx, http.MethodPut, "/api/v1/quotas/policies", request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool         `json:"success"`
+		Data    *QuotaPolicy `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// DeleteQuotaPolicy deletes the policy of a subject so that the default limits apply again
+func (c *Client) DeleteQuotaPolicy(ctx context.Context, subjectType string, subjectID string) error {
+	query := url.Values{}
+	query.Add("subject_type", subjectType)
+	if subjectID != "" {
+		query.Add("subject_id", subjectID)
+	}
+	resp, err := c.doRequest(ctx, http.MethodDelete, "/api/v1/quotas/policies", nil, query)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Success bool `json:"success"`
+	}
+	return parseResponse(resp, &response)
+}
@@ -163,6 +163,11 @@ type StopSessionRequest struct {
 	MessageID string `json:"message_id"`
 }
 
+// ResumeSessionRequest resume agent run payload.
+type ResumeSessionRequest struct {
+	MessageID string `json:"message_id"`
+}
+
 // GenerateTitle generates a session title
 func (c *Client) GenerateTitle(ctx context.Context, sessionID string, request *GenerateTitleRequest) (string, error) {
 	path := fmt.Sprintf("/api/v1/sessions/%s/generate_title", sessionID)
@@ -212,11 +217,13 @@ const (
 	ResponseTypeThinking     ResponseType = "thinking"
 	ResponseTypeToolCall     ResponseType = "tool_call"
 	ResponseTypeToolResult   ResponseType = "tool_result"
+	ResponseTypeToolApproval ResponseType = "tool_approval"
 	ResponseTypeError        ResponseType = "error"
 	ResponseTypeReflection   ResponseType = "reflection"
 	ResponseTypeSessionTitle ResponseType = "session_title"
 	ResponseTypeAgentQuery   ResponseType = "agent_query"
 	ResponseTypeComplete     ResponseType = "complete"
+	ResponseTypePaused       ResponseType = "paused"
 )
 
 // StreamResponse streaming response
@@ -379,6 +386,8 @@ func (c *Client) ContinueStream(
 }
 
 // StopSession stops the generation for a specific assistant message under a session.
+// A running agent run is paused instead and can be resumed with ResumeAgentStream,
+// stopping a paused run gives it up.
 func (c *Client) StopSession(ctx context.Context, sessionID string, messageID string) error {
 	if strings.TrimSpace(sessionID) == "" {
 		return fmt.Errorf("sessionID cannot be empty")
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Tool approval statuses
+const (
+	ToolApprovalPending  = "pending"
+	ToolApprovalApproved = "approved"
+	ToolApprovalRejected = "rejected"
+	ToolApprovalExpired  = "expired"
+)
+
+// ToolApproval is an agent tool call held for a human decision.
+// The agent shows it as a tool_approval stream event and waits until it is decided or expires.
+type ToolApproval struct {
+	ID                string                 `json:"id"`
+	SessionID         string                 `json:"session_id"`
+	MessageID         string                 `json:"message_id"`
+	ToolCallID        string                 `json:"tool_call_id"`
+	ToolName          string                 `json:"tool_name"`
+	MCPServiceID      string                 `json:"mcp_service_id,omitempty"`
+	Iteration         int                    `json:"iteration"`
+	Arguments         map[string]interface{} `json:"arguments"`
+	ApprovedArguments map[string]interface{} `json:"approved_arguments"`
+	Status            string                 `json:"status"`
+	Comment           string                 `json:"comment"`
+	DecidedBy         string                 `json:"decided_by"`
+	ExpiresAt         time.Time              `json:"expires_at"`
+	DecidedAt         *time.Time             `json:"decided_at"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
+
+// ToolApprovalDecision is the decision on a tool call.
+// Arguments, when set on approval, replace the arguments the tool runs with.
+type ToolApprovalDecision struct {
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Comment   string                 `json:"comment,omitempty"`
+}
+
+// ListToolApprovals lists the tool approvals of a session, newest first. An empty status lists every approval.
+func (c *Client) ListToolApprovals(ctx context.Context, sessionID string, status string) ([]ToolApproval, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Add("status", status)
+	}
+	path := fmt.Sprintf("/api/v1/tool-approvals/%s", sessionID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool           `json:"success"`
+		Data    []ToolApproval `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// GetToolApproval gets a tool approval of a session
+func (c *Client) GetToolApproval(ctx context.Context, sessionID, approvalID string) (*ToolApproval, error) {
+	path := fmt.Sprintf("/api/v1/tool-approvals/%s/%s", sessionID, approvalID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool         `json:"success"`
+		Data    ToolApproval `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// ApproveToolCall approves a pending tool call, decision may be nil to run it as requested
+func (c *Client) ApproveToolCall(ctx context.Context,
+	sessionID, approvalID string, decision *ToolApprovalDecision,
+) (*ToolApproval, error) {
+	return c.decideToolApproval(ctx, sessionID, approvalID, "approve", decision)
+}
+
+// RejectToolCall rejects a pending tool call, the comment is passed to the agent as the reason
+func (c *Client) RejectToolCall(ctx context.Context,
+	sessionID, approvalID string, decision *ToolApprovalDecision,
+) (*ToolApproval, error) {
+	return c.decideToolApproval(ctx, sessionID, approvalID, "reject", decision)
+}
+
+// decideToolApproval sends a decision on a tool approval
+func (c *Client) decideToolApproval(ctx context.Context,
+	sessionID, approvalID, action string, decision *ToolApprovalDecision,
+) (*ToolApproval, error) {
+	if decision == nil {
+		decision = &ToolApprovalDecision{}
+	}
+	path := fmt.Sprintf("/api/v1/tool-approvals/%s/%s/%s", sessionID, approvalID, action)
+	resp, err := c.doRequest(ctx, http.MethodPost, path, decision, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool         `json:"success"`
+		Data    ToolApproval `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
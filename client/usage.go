@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UsageRecord is the token usage of one model call
+type UsageRecord struct {
+	ID               uint64    `json:"id"`
+	TenantID         uint64    `json:"tenant_id"`
+	ModelID          string    `json:"model_id"`
+	ModelName        string    `json:"model_name"`
+	ModelType        string    `json:"model_type"` // KnowledgeQA, Embedding or Rerank
+	SessionID        string    `json:"session_id"`
+	AgentID          string    `json:"agent_id"`
+	Source           string    `json:"source"` // e.g. knowledge_qa, agent_qa, document:process
+	InputCount       int       `json:"input_count"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Estimated        bool      `json:"estimated"` // The provider reported no usage and tokens were estimated
+	DurationMs       int64     `json:"duration_ms"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// UsageRecordFilter filters usage records, empty fields match every record
+type UsageRecordFilter struct {
+	SessionID string
+	AgentID   string
+	ModelID   string
+	Source    string
+	From      *time.Time
+	To        *time.Time
+}
+
+// query converts the filter to query parameters
+func (f *UsageRecordFilter) query() url.Values {
+	query := url.Values{}
+	if f == nil {
+		return query
+	}
+	for key, value := range map[string]string{
+		"session_id": f.SessionID,
+		"agent_id":   f.AgentID,
+		"model_id":   f.ModelID,
+		"source":     f.Source,
+	} {
+		if value != "" {
+			query.Add(key, value)
+		}
+	}
+	if f.From != nil {
+		query.Add("from", f.From.Format(time.RFC3339))
+	}
+	if f.To != nil {
+		query.Add("to", f.To.Format(time.RFC3339))
+	}
+	return query
+}
+
+// UsageRecordsPage contains paginated usage records
+type UsageRecordsPage struct {
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+	Records  []UsageRecord `json:"data"`
+}
+
+// UsageQuery selects the days and grouping of a usage report, empty fields use the server defaults
+type UsageQuery struct {
+	From      string   // First day, YYYY-MM-DD
+	To        string   // Last day, YYYY-MM-DD
+	GroupBy   []string // day, model, model_type, agent or source
+	ModelID   string
+	ModelType string
+	AgentID   string
+	Source    string
+}
+
+// UsageSummaryRow is one row of a usage report, the fields not grouped by are empty
+type UsageSummaryRow struct {
+	Day              string `json:"day,omitempty"`
+	ModelID          string `json:"model_id,omitempty"`
+	ModelName        string `json:"model_name,omitempty"`
+	ModelType        string `json:"model_type,omitempty"`
+	AgentID          string `json:"agent_id,omitempty"`
+	Source           string `json:"source,omitempty"`
+	Calls            int64  `json:"calls"`
+	InputCount       int64  `json:"input_count"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	TotalTokens      int64  `json:"total_tokens"`
+	EstimatedCalls   int64  `json:"estimated_calls"`
+}
+
+// UsageSummary is a usage report of the current tenant
+type UsageSummary struct {
+	From    string             `json:"from"`
+	To      string             `json:"to"`
+	GroupBy []string           `json:"group_by"`
+	Rows    []*UsageSummaryRow `json:"rows"`
+	Total   *UsageSummaryRow   `json:"total"`
+}
+
+// GetUsageSummary reports the token usage of the current tenant from the daily rollups
+func (c *Client) GetUsageSummary(ctx context.Context, usageQuery *UsageQuery) (*UsageSummary, error) {
+	query := url.Values{}
+	if usageQuery != nil {
+		for key, value := range map[string]string{
+			"from":       usageQuery.From,
+			"to":         usageQuery.To,
+			"model_id":   usageQuery.ModelID,
+			"model_type": usageQuery.ModelType,
+			"agent_id":   usageQuery.AgentID,
+			"source":     usageQuery.Source,
+		} {
+			if value != "" {
+				query.Add(key, value)
+			}
+		}
+		if len(usageQuery.GroupBy) > 0 {
+			query.Add("group_by", strings.Join(usageQuery.GroupBy, ","))
+		}
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/usage", nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool          `json:"success"`
+		Data    *UsageSummary `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// ListUsageRecords lists the per-call usage records of the current tenant, newest first
+func (c *Client) ListUsageRecords(ctx context.Context,
+	filter *UsageRecordFilter, page int, pageSize int,
+) (*UsageRecordsPage, error) {
+	query := filter.query()
+	query.Add("page", strconv.Itoa(page))
+	query.Add("page_size", strconv.Itoa(pageSize))
+
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/usage/records", nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool              `json:"success"`
+		Data    *UsageRecordsPage `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
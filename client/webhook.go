@@ -0,0 +1,302 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook events, WebhookEventAll subscribes to every event
+const (
+	WebhookEventKnowledgeParsed    = "knowledge.parsed"
+	WebhookEventKnowledgeFailed    = "knowledge.failed"
+	WebhookEventFAQImportCompleted = "faq_import.completed"
+	WebhookEventFAQImportFailed    = "faq_import.failed"
+	WebhookEventKBCloneCompleted   = "kb_clone.completed"
+	WebhookEventKBCloneFailed      = "kb_clone.failed"
+	WebhookEventMessageCompleted   = "message.completed"
+	WebhookEventPing               = "ping"
+	WebhookEventAll                = "*"
+)
+
+// Webhook request headers
+const (
+	WebhookSignatureHeader = "X-WeKnora-Signature"
+	WebhookEventHeader     = "X-WeKnora-Event"
+	WebhookDeliveryHeader  = "X-WeKnora-Delivery"
+	WebhookEventIDHeader   = "X-WeKnora-Event-ID"
+)
+
+// Webhook is a tenant webhook subscription, its signing secret is only returned on creation or rotation
+type Webhook struct {
+	ID          string    `json:"id"`
+	TenantID    uint64    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Enabled     bool      `json:"enabled"`
+	Description string    `json:"description"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookWithSecret is a webhook with its signing secret, which cannot be retrieved again
+type WebhookWithSecret struct {
+	Webhook
+	Secret string `json:"secret,omitempty"`
+}
+
+// CreateWebhookRequest is used to create a webhook, a nil Enabled creates an enabled webhook
+type CreateWebhookRequest struct {
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description,omitempty"`
+	Enabled     *bool    `json:"enabled,omitempty"`
+}
+
+// UpdateWebhookRequest is used to update a webhook, nil fields are left unchanged
+type UpdateWebhookRequest struct {
+	Name         *string  `json:"name,omitempty"`
+	URL          *string  `json:"url,omitempty"`
+	Events       []string `json:"events,omitempty"`
+	Description  *string  `json:"description,omitempty"`
+	Enabled      *bool    `json:"enabled,omitempty"`
+	RotateSecret bool     `json:"rotate_secret,omitempty"`
+}
+
+// WebhookDelivery is an attempt to send an event to a webhook
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"` // pending, retrying, success or failed
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status"`
+	Error          string          `json:"error"`
+	DurationMs     int64           `json:"duration_ms"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// WebhookDeliveriesPage contains paginated webhook deliveries
+type WebhookDeliveriesPage struct {
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	Deliveries []WebhookDelivery `json:"data"`
+}
+
+// WebhookEnvelope is the body of a webhook request
+type WebhookEnvelope struct {
+	ID        string          `json:"id"` // Event ID, the same for every delivery of the event
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	TenantID  uint64          `json:"tenant_id"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// CreateWebhook creates a webhook for the current tenant
+func (c *Client) CreateWebhook(ctx context.Context, request *CreateWebhookRequest) (*WebhookWithSecret, error) {
+	resp, err := c.doRequest(ctx, http.MethodPost, "/api/v1/webhooks", request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool              `json:"success"`
+		Data    WebhookWithSecret `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// ListWebhooks lists the webhooks of the current tenant
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/v1/webhooks", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool      `json:"success"`
+		Data    []Webhook `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// GetWebhook gets a webhook
+func (c *Client) GetWebhook(ctx context.Context, webhookID string) (*Webhook, error) {
+	path := fmt.Sprintf("/api/v1/webhooks/%s", webhookID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool    `json:"success"`
+		Data    Webhook `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// UpdateWebhook updates a webhook, the new signing secret is returned when RotateSecret is set
+func (c *Client) UpdateWebhook(ctx context.Context,
+	webhookID string, request *UpdateWebhookRequest,
+) (*WebhookWithSecret, error) {
+	path := fmt.Sprintf("/api/v1/webhooks/%s", webhookID)
+	resp, err := c.doRequest(ctx, http.MethodPut, path, request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool              `json:"success"`
+		Data    WebhookWithSecret `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// DeleteWebhook deletes a webhook and its deliveries
+func (c *Client) DeleteWebhook(ctx context.Context, webhookID string) error {
+	path := fmt.Sprintf("/api/v1/webhooks/%s", webhookID)
+	resp, err := c.doRequest(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Success bool   `json:"success"`
+		Message string `json:"message,omitempty"`
+	}
+	return parseResponse(resp, &response)
+}
+
+// TestWebhook sends a ping event to a webhook
+func (c *Client) TestWebhook(ctx context.Context, webhookID string) (*WebhookDelivery, error) {
+	path := fmt.Sprintf("/api/v1/webhooks/%s/test", webhookID)
+	return c.webhookDelivery(ctx, http.MethodPost, path)
+}
+
+// ListWebhookDeliveries lists the deliveries of a webhook, newest first.
+// Event and status filter the deliveries when not empty.
+func (c *Client) ListWebhookDeliveries(ctx context.Context,
+	webhookID, event, status string, page int, pageSize int,
+) (*WebhookDeliveriesPage, error) {
+	query := url.Values{}
+	if event != "" {
+		query.Add("event", event)
+	}
+	if status != "" {
+		query.Add("status", status)
+	}
+	query.Add("page", strconv.Itoa(page))
+	query.Add("page_size", strconv.Itoa(pageSize))
+
+	path := fmt.Sprintf("/api/v1/webhooks/%s/deliveries", webhookID)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool                   `json:"success"`
+		Data    *WebhookDeliveriesPage `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// GetWebhookDelivery gets a delivery of a webhook
+func (c *Client) GetWebhookDelivery(ctx context.Context, webhookID, deliveryID string) (*WebhookDelivery, error) {
+	path := fmt.Sprintf("/api/v1/webhooks/%s/deliveries/%s", webhookID, deliveryID)
+	return c.webhookDelivery(ctx, http.MethodGet, path)
+}
+
+// RedeliverWebhook sends the event of a delivery again, a new delivery with the same event ID is returned
+func (c *Client) RedeliverWebhook(ctx context.Context, webhookID, deliveryID string) (*WebhookDelivery, error) {
+	path := fmt.Sprintf("/api/v1/webhooks/%s/deliveries/%s/redeliver", webhookID, deliveryID)
+	return c.webhookDelivery(ctx, http.MethodPost, path)
+}
+
+// webhookDelivery requests an endpoint returning a webhook delivery
+func (c *Client) webhookDelivery(ctx context.Context, method, path string) (*WebhookDelivery, error) {
+	resp, err := c.doRequest(ctx, method, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Success bool            `json:"success"`
+		Data    WebhookDelivery `json:"data"`
+	}
+	if err := parseResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+// VerifyWebhookSignature checks the X-WeKnora-Signature header of a webhook request against its raw body.
+// Requests signed more than tolerance ago are rejected to prevent replays, a zero tolerance skips the check.
+func VerifyWebhookSignature(secret, header string, body []byte, tolerance time.Duration) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return errors.New("invalid webhook signature header")
+	}
+	if tolerance > 0 {
+		if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return errors.New("webhook signature timestamp outside tolerance")
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return errors.New("webhook signature mismatch")
+}
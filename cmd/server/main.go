@@ -53,6 +53,14 @@ func main() {
 		gin.SetMode(gin.DebugMode)
 	}
 
+	// Run as a stdio MCP server, e.g. launched by an IDE agent
+	if len(os.Args) > 1 && os.Args[1] == mcpStdioCommand {
+		if err := runMCPStdio(); err != nil {
+			log.Fatalf("Failed to run MCP server: %v", err)
+		}
+		return
+	}
+
 	// Build dependency injection container
 	c := container.BuildContainer(runtime.GetContainer())
 
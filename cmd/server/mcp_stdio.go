@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tencent/WeKnora/internal/container"
+	"github.com/Tencent/WeKnora/internal/mcpserver"
+	"github.com/Tencent/WeKnora/internal/runtime"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// mcpStdioCommand runs a stdio MCP server instead of the HTTP server
+const mcpStdioCommand = "mcp-stdio"
+
+// mcpAPIKeyEnv is the environment variable holding the tenant API key the stdio MCP server authenticates with
+const mcpAPIKeyEnv = "WEKNORA_API_KEY"
+
+// runMCPStdio serves MCP over stdin and stdout.
+// stdout carries the MCP messages only, so all logs are redirected to stderr.
+func runMCPStdio() error {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	log.SetOutput(os.Stderr)
+	gin.DefaultWriter = os.Stderr
+
+	apiKey := os.Getenv(mcpAPIKeyEnv)
+	if apiKey == "" {
+		return errors.New(mcpAPIKeyEnv + " is required")
+	}
+
+	c := container.BuildContainer(runtime.GetContainer())
+	return c.Invoke(func(server *mcpserver.Server, resourceCleaner interfaces.ResourceCleaner) error {
+		defer func() {
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if errs := resourceCleaner.Cleanup(cleanupCtx); len(errs) > 0 {
+				log.Printf("Errors occurred during resource cleanup: %v", errs)
+			}
+		}()
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		ctx, err := server.AuthenticateAPIKey(ctx, apiKey)
+		if err != nil {
+			return fmt.Errorf("failed to authenticate %s: %v", mcpAPIKeyEnv, err)
+		}
+
+		log.Println("MCP server is running on stdio")
+		if err := server.ServeStdio(ctx, os.Stdin, stdout); err != nil && !errors.Is(err, context.Canceled) {
+			return fmt.Errorf("MCP server stopped: %v", err)
+		}
+		return nil
+	})
+}
@@ -9,4 +9,12 @@ const (
 	DefaultAgentReflectionEnabled = false
 	// DefaultUseCustomSystemPrompt is the default whether to use custom system prompt for the agent
 	DefaultUseCustomSystemPrompt = false
+	// DefaultAgentMaxParallelToolCalls is the default maximum number of tool calls of a round running at once
+	DefaultAgentMaxParallelToolCalls = 4
+	// DefaultAgentToolTimeoutSeconds is the default timeout of a single tool call in seconds
+	DefaultAgentToolTimeoutSeconds = 120
+	// DefaultAgentToolApprovalTimeoutSeconds is the default time a tool call waits for approval in seconds
+	DefaultAgentToolApprovalTimeoutSeconds = 600
+	// MaxAgentDelegationDepth is the maximum nesting depth of agents delegating tasks to other agents
+	MaxAgentDelegationDepth = 2
 )
@@ -3,12 +3,14 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/Tencent/WeKnora/internal/agent/tools"
 	"github.com/Tencent/WeKnora/internal/common"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
 	"github.com/Tencent/WeKnora/internal/event"
 	"github.com/Tencent/WeKnora/internal/logger"
 	"github.com/Tencent/WeKnora/internal/models/chat"
@@ -28,11 +30,13 @@ type AgentEngine struct {
 	toolRegistry         *tools.ToolRegistry
 	chatModel            chat.Chat
 	eventBus             *event.EventBus
-	knowledgeBasesInfo   []*KnowledgeBaseInfo      // Detailed knowledge base information for prompt
-	selectedDocs         []*SelectedDocumentInfo   // User-selected documents (via @ mention)
-	contextManager       interfaces.ContextManager // Context manager for writing agent conversation to LLM context
-	sessionID            string                    // Session ID for context management
-	systemPromptTemplate string                    // System prompt template (optional, uses default if empty)
+	knowledgeBasesInfo   []*KnowledgeBaseInfo           // Detailed knowledge base information for prompt
+	selectedDocs         []*SelectedDocumentInfo        // User-selected documents (via @ mention)
+	contextManager       interfaces.ContextManager      // Context manager for writing agent conversation to LLM context
+	sessionID            string                         // Session ID for context management
+	systemPromptTemplate string                         // System prompt template (optional, uses default if empty)
+	toolApprovals        interfaces.ToolApprovalService // Approval of tool calls whose policy is "ask" (optional)
+	checkpointer         interfaces.AgentCheckpointer   // Stores the state after each completed round (optional)
 }
 
 // listToolNames returns tool.function names for logging
@@ -55,6 +59,8 @@ func NewAgentEngine(
 	contextManager interfaces.ContextManager,
 	sessionID string,
 	systemPromptTemplate string,
+	toolApprovals interfaces.ToolApprovalService,
+	checkpointer interfaces.AgentCheckpointer,
 ) *AgentEngine {
 	if eventBus == nil {
 		eventBus = event.NewEventBus()
@@ -69,6 +75,8 @@ func NewAgentEngine(
 		contextManager:       contextManager,
 		sessionID:            sessionID,
 		systemPromptTemplate: systemPromptTemplate,
+		toolApprovals:        toolApprovals,
+		checkpointer:         checkpointer,
 	}
 }
 
@@ -106,6 +114,7 @@ func (e *AgentEngine) Execute(
 		e.knowledgeBasesInfo,
 		e.config.WebSearchEnabled,
 		e.selectedDocs,
+		e.config.UserMemories,
 		e.systemPromptTemplate,
 	)
 	logger.Debugf(ctx, "[Agent] SystemPrompt Length: %d characters", len(systemPrompt))
@@ -116,6 +125,39 @@ func (e *AgentEngine) Execute(
 	logger.Infof(ctx, "[Agent] Total messages for LLM: %d (system: 1, history: %d, user query: 1)",
 		len(messages), len(llmContext))
 
+	return e.run(ctx, state, query, messages, sessionID, messageID)
+}
+
+// Resume continues the agent from a checkpoint saved after a completed round.
+// The messages are the LLM messages of the checkpoint, starting with the system prompt of the original run.
+func (e *AgentEngine) Resume(
+	ctx context.Context,
+	sessionID, messageID, query string,
+	state *types.AgentState,
+	messages []chat.Message,
+) (*types.AgentState, error) {
+	logger.Infof(ctx, "========== Agent Execution Resumed ==========")
+	defer e.toolRegistry.Cleanup(ctx)
+
+	logger.Infof(ctx, "[Agent] SessionID: %s, MessageID: %s, resuming after round %d with %d messages",
+		sessionID, messageID, state.CurrentRound, len(messages))
+	common.PipelineInfo(ctx, "Agent", "resume_start", map[string]interface{}{
+		"session_id": sessionID,
+		"message_id": messageID,
+		"round":      state.CurrentRound,
+		"messages":   len(messages),
+	})
+	return e.run(ctx, state, query, messages, sessionID, messageID)
+}
+
+// run builds the tools and runs the ReAct loop from the given state
+func (e *AgentEngine) run(
+	ctx context.Context,
+	state *types.AgentState,
+	query string,
+	messages []chat.Message,
+	sessionID, messageID string,
+) (*types.AgentState, error) {
 	// Get tool definitions for function calling
 	tools := e.buildToolsForLLM()
 	toolListStr := strings.Join(listToolNames(tools), ", ")
@@ -128,6 +170,11 @@ func (e *AgentEngine) Execute(
 
 	_, err := e.executeLoop(ctx, state, query, messages, tools, sessionID, messageID)
 	if err != nil {
+		// A stopped run is not an error of the agent, the caller reports it
+		if ctx.Err() != nil {
+			logger.Infof(ctx, "[Agent] Execution stopped after round %d: %v", state.CurrentRound, err)
+			return nil, err
+		}
 		logger.Errorf(ctx, "[Agent] Execution failed: %v", err)
 		e.eventBus.Emit(ctx, event.Event{
 			ID:        generateEventID("error"),
@@ -154,6 +201,21 @@ func (e *AgentEngine) Execute(
 	return state, nil
 }
 
+// saveCheckpoint stores the state after a completed round.
+// Only losing the run aborts the loop, other failures cost the round on a later resume.
+func (e *AgentEngine) saveCheckpoint(ctx context.Context, state *types.AgentState, messages []chat.Message) error {
+	if e.checkpointer == nil {
+		return nil
+	}
+	if err := e.checkpointer.SaveCheckpoint(ctx, state, messages); err != nil {
+		if errors.Is(err, werrors.ErrAgentRunSuspended) {
+			return err
+		}
+		logger.Warnf(ctx, "[Agent] Failed to save checkpoint of round %d: %v", state.CurrentRound, err)
+	}
+	return nil
+}
+
 // executeLoop executes the main ReAct loop
 // All events are emitted through EventBus with the given sessionID
 func (e *AgentEngine) executeLoop(
@@ -266,174 +328,11 @@ func (e *AgentEngine) executeLoop(
 				state.CurrentRound+1,
 				len(response.ToolCalls),
 			)
-
-			for i, tc := range response.ToolCalls {
-				logger.Infof(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool: %s, ID: %s",
-					state.CurrentRound+1, i+1, len(response.ToolCalls), tc.Function.Name, tc.ID)
-
-				var args map[string]any
-				if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
-					logger.Errorf(ctx, "[Agent][Round-%d][Tool-%d/%d] Failed to parse tool arguments: %v",
-						state.CurrentRound+1, i+1, len(response.ToolCalls), err)
-					continue
-				}
-
-				// Log the arguments in a readable format
-				argsJSON, _ := json.MarshalIndent(args, "", "  ")
-				logger.Infof(ctx, "[Agent][Round-%d][Tool-%d/%d] Arguments:\n%s",
-					state.CurrentRound+1, i+1, len(response.ToolCalls), string(argsJSON))
-
-				toolCallStartTime := time.Now()
-				e.eventBus.Emit(ctx, event.Event{
-					ID:        tc.ID + "-tool-call",
-					Type:      event.EventAgentToolCall,
-					SessionID: sessionID,
-					Data: event.AgentToolCallData{
-						ToolCallID: tc.ID,
-						ToolName:   tc.Function.Name,
-						Arguments:  args,
-						Iteration:  state.CurrentRound,
-					},
-				})
-				logger.Debugf(ctx, "[Agent] ToolCall -> %s args=%s", tc.Function.Name, tc.Function.Arguments)
-
-				// Execute tool
-				logger.Infof(ctx, "[Agent][Round-%d][Tool-%d/%d] Executing tool: %s...",
-					state.CurrentRound+1, i+1, len(response.ToolCalls), tc.Function.Name)
-				common.PipelineInfo(ctx, "Agent", "tool_call_start", map[string]interface{}{
-					"iteration":    state.CurrentRound,
-					"round":        state.CurrentRound + 1,
-					"tool":         tc.Function.Name,
-					"tool_call_id": tc.ID,
-					"tool_index":   fmt.Sprintf("%d/%d", i+1, len(response.ToolCalls)),
-				})
-				result, err := e.toolRegistry.ExecuteTool(ctx, tc.Function.Name, json.RawMessage(tc.Function.Arguments))
-				duration := time.Since(toolCallStartTime).Milliseconds()
-				logger.Infof(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool execution completed in %dms",
-					state.CurrentRound+1, i+1, len(response.ToolCalls), duration)
-
-				toolCall := types.ToolCall{
-					ID:       tc.ID,
-					Name:     tc.Function.Name,
-					Args:     args,
-					Result:   result,
-					Duration: duration,
-				}
-
-				if err != nil {
-					logger.Errorf(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool call failed: %s, error: %v",
-						state.CurrentRound+1, i+1, len(response.ToolCalls), tc.Function.Name, err)
-					toolCall.Result = &types.ToolResult{
-						Success: false,
-						Error:   err.Error(),
-					}
-				}
-
-				toolSuccess := toolCall.Result != nil && toolCall.Result.Success
-				pipelineFields := map[string]interface{}{
-					"iteration":    state.CurrentRound,
-					"round":        state.CurrentRound + 1,
-					"tool":         tc.Function.Name,
-					"tool_call_id": tc.ID,
-					"duration_ms":  duration,
-					"success":      toolSuccess,
-				}
-				if toolCall.Result != nil && toolCall.Result.Error != "" {
-					pipelineFields["error"] = toolCall.Result.Error
-				}
-				if err != nil {
-					common.PipelineError(ctx, "Agent", "tool_call_result", pipelineFields)
-				} else if toolSuccess {
-					common.PipelineInfo(ctx, "Agent", "tool_call_result", pipelineFields)
-				} else {
-					common.PipelineWarn(ctx, "Agent", "tool_call_result", pipelineFields)
-				}
-
-				if toolCall.Result != nil {
-					logger.Infof(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool result: success=%v, output_length=%d",
-						state.CurrentRound+1, i+1, len(response.ToolCalls),
-						toolCall.Result.Success, len(toolCall.Result.Output))
-					logger.Debugf(ctx, "[Agent] ToolResult <- %s success=%v len(output)=%d",
-						tc.Function.Name, toolCall.Result.Success, len(toolCall.Result.Output))
-
-					// Log the output content for debugging
-					if toolCall.Result.Output != "" {
-						// Truncate if too long for logging
-						outputPreview := toolCall.Result.Output
-						if len(outputPreview) > 500 {
-							outputPreview = outputPreview[:500] + "... (truncated)"
-						}
-						logger.Debugf(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool output preview:\n%s",
-							state.CurrentRound+1, i+1, len(response.ToolCalls), outputPreview)
-					}
-
-					if toolCall.Result.Error != "" {
-						logger.Warnf(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool error: %s",
-							state.CurrentRound+1, i+1, len(response.ToolCalls), toolCall.Result.Error)
-					}
-
-					// Log structured data if present
-					if toolCall.Result.Data != nil {
-						dataJSON, _ := json.MarshalIndent(toolCall.Result.Data, "", "  ")
-						logger.Debugf(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool data:\n%s",
-							state.CurrentRound+1, i+1, len(response.ToolCalls), string(dataJSON))
-					}
-				}
-
-				// Store tool call (Observations are now derived from ToolCall.Result.Output)
-				step.ToolCalls = append(step.ToolCalls, toolCall)
-
-				// Emit tool result event (include structured data from tool result)
-				e.eventBus.Emit(ctx, event.Event{
-					ID:        tc.ID + "-tool-result",
-					Type:      event.EventAgentToolResult,
-					SessionID: sessionID,
-					Data: event.AgentToolResultData{
-						ToolCallID: tc.ID,
-						ToolName:   tc.Function.Name,
-						Output:     result.Output,
-						Error:      result.Error,
-						Success:    result.Success,
-						Duration:   duration,
-						Iteration:  state.CurrentRound,
-						Data:       result.Data, // Pass structured data for frontend rendering
-					},
-				})
-
-				// Emit tool execution event (for internal monitoring)
-				e.eventBus.Emit(ctx, event.Event{
-					ID:        tc.ID + "-tool-exec",
-					Type:      event.EventAgentTool,
-					SessionID: sessionID,
-					Data: event.AgentActionData{
-						Iteration:  state.CurrentRound,
-						ToolName:   tc.Function.Name,
-						ToolInput:  args,
-						ToolOutput: result.Output,
-						Success:    result.Success,
-						Error:      result.Error,
-						Duration:   duration,
-					},
-				})
-
-				// Optional: Reflection after each tool call (streaming)
-				if e.config.ReflectionEnabled && result != nil {
-					reflection, err := e.streamReflectionToEventBus(
-						ctx, tc.ID, tc.Function.Name, result.Output,
-						state.CurrentRound, sessionID,
-					)
-					if err != nil {
-						logger.Warnf(ctx, "Reflection failed: %v", err)
-					} else if reflection != "" {
-						// Store reflection in the corresponding tool call
-						// Find the tool call we just added and update it
-						if len(step.ToolCalls) > 0 {
-							lastIdx := len(step.ToolCalls) - 1
-							step.ToolCalls[lastIdx].Reflection = reflection
-						}
-					}
-				}
-			}
+			e.executeToolCalls(ctx, &step, response.ToolCalls, state.CurrentRound, sessionID, messageID)
+		}
+		// A round interrupted by a stop is dropped, a resumed run repeats it
+		if err := ctx.Err(); err != nil {
+			return state, err
 		}
 
 		state.RoundSteps = append(state.RoundSteps, step)
@@ -447,6 +346,9 @@ func (e *AgentEngine) executeLoop(
 		})
 		// 5. Check if we should continue
 		state.CurrentRound++
+		if err := e.saveCheckpoint(ctx, state, messages); err != nil {
+			return state, err
+		}
 	}
 
 	// If loop finished without final answer, generate one
@@ -782,6 +684,7 @@ func (e *AgentEngine) streamFinalAnswerToEventBus(
 		e.knowledgeBasesInfo,
 		e.config.WebSearchEnabled,
 		e.selectedDocs,
+		e.config.UserMemories,
 		e.systemPromptTemplate,
 	)
 
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Tencent/WeKnora/internal/agent/tools"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/models/chat"
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// scriptedChat answers each call with the next scripted response
+type scriptedChat struct {
+	mu        sync.Mutex
+	responses []types.StreamResponse
+	calls     [][]chat.Message
+}
+
+func (c *scriptedChat) Chat(ctx context.Context, messages []chat.Message, opts *chat.ChatOptions) (*types.ChatResponse, error) {
+	return nil, errors.New("not scripted")
+}
+
+func (c *scriptedChat) ChatStream(ctx context.Context,
+	messages []chat.Message, opts *chat.ChatOptions,
+) (<-chan types.StreamResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.responses) == 0 {
+		return nil, errors.New("no scripted response left")
+	}
+	c.calls = append(c.calls, messages)
+	ch := make(chan types.StreamResponse, 1)
+	ch <- c.responses[0]
+	close(ch)
+	c.responses = c.responses[1:]
+	return ch, nil
+}
+
+func (c *scriptedChat) GetModelName() string { return "scripted" }
+func (c *scriptedChat) GetModelID() string   { return "scripted" }
+
+// suspendingCheckpointer keeps the first checkpoint and then reports the run as paused
+type suspendingCheckpointer struct {
+	state    *types.AgentState
+	messages []chat.Message
+}
+
+func (c *suspendingCheckpointer) SaveCheckpoint(ctx context.Context,
+	state *types.AgentState, messages []chat.Message,
+) error {
+	saved := *state
+	c.state = &saved
+	c.messages = append([]chat.Message(nil), messages...)
+	return werrors.ErrAgentRunSuspended
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	var running, maxRunning int32
+	registry := tools.NewToolRegistry()
+	registry.RegisterTool(&fakeTool{name: "fast", running: &running, maxRunning: &maxRunning})
+
+	model := &scriptedChat{responses: []types.StreamResponse{
+		{ToolCalls: []types.LLMToolCall{{ID: "call", Function: types.FunctionCall{Name: "fast", Arguments: `{}`}}}},
+		{Content: "done", Done: true},
+	}}
+	checkpointer := &suspendingCheckpointer{}
+	config := &types.AgentConfig{MaxIterations: 5}
+
+	engine := NewAgentEngine(config, model, registry, nil, nil, nil, nil, "session", "", nil, checkpointer)
+	if _, err := engine.Execute(context.Background(), "session", "message", "question", nil); !errors.Is(err, werrors.ErrAgentRunSuspended) {
+		t.Fatalf("Execute error = %v, want suspended", err)
+	}
+	if checkpointer.state == nil || checkpointer.state.CurrentRound != 1 || len(checkpointer.state.RoundSteps) != 1 {
+		t.Fatalf("checkpoint state = %+v, want one completed round", checkpointer.state)
+	}
+	// system prompt, question, assistant tool call and tool result
+	if len(checkpointer.messages) != 4 || checkpointer.messages[3].Role != "tool" {
+		t.Fatalf("checkpoint messages = %+v", checkpointer.messages)
+	}
+
+	engine = NewAgentEngine(config, model, registry, nil, nil, nil, nil, "session", "", nil, nil)
+	state, err := engine.Resume(context.Background(), "session", "message", "question",
+		checkpointer.state, checkpointer.messages)
+	if err != nil {
+		t.Fatalf("Resume error = %v", err)
+	}
+	if state.FinalAnswer != "done" || len(state.RoundSteps) != 2 {
+		t.Fatalf("resumed state = %+v", state)
+	}
+	if got := len(model.calls[1]); got != len(checkpointer.messages) {
+		t.Fatalf("resumed LLM call got %d messages, want the %d checkpointed ones", got, len(checkpointer.messages))
+	}
+}
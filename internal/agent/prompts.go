@@ -230,11 +230,13 @@ func formatSelectedDocuments(docs []*SelectedDocumentInfo) string {
 //   - {{knowledge_bases}}
 //   - {{web_search_status}} -> "Enabled" 또는 "Disabled"
 //   - {{current_time}} -> 현재 시간 문자열
+//   - {{user_memories}} -> 질문과 관련된 사용자 기억, 없으면 "None"
 func renderPromptPlaceholdersWithStatus(
 	template string,
 	knowledgeBases []*KnowledgeBaseInfo,
 	webSearchEnabled bool,
 	currentTime string,
+	userMemories string,
 ) string {
 	result := renderPromptPlaceholders(template, knowledgeBases)
 	status := "Disabled"
@@ -247,6 +249,12 @@ func renderPromptPlaceholdersWithStatus(
 	if strings.Contains(result, "{{current_time}}") {
 		result = strings.ReplaceAll(result, "{{current_time}}", currentTime)
 	}
+	if strings.Contains(result, "{{user_memories}}") {
+		if userMemories == "" {
+			userMemories = "None"
+		}
+		result = strings.ReplaceAll(result, "{{user_memories}}", userMemories)
+	}
 	return result
 }
 
@@ -263,7 +271,7 @@ func BuildSystemPromptWithWeb(
 		template = ProgressiveRAGSystemPrompt
 	}
 	currentTime := time.Now().Format(time.RFC3339)
-	return renderPromptPlaceholdersWithStatus(template, knowledgeBases, true, currentTime)
+	return renderPromptPlaceholdersWithStatus(template, knowledgeBases, true, currentTime, "")
 }
 
 // BuildSystemPromptWithoutWeb 웹 검색이 없는 점진적 RAG 시스템 프롬프트 빌드
@@ -279,7 +287,7 @@ func BuildSystemPromptWithoutWeb(
 		template = ProgressiveRAGSystemPrompt
 	}
 	currentTime := time.Now().Format(time.RFC3339)
-	return renderPromptPlaceholdersWithStatus(template, knowledgeBases, false, currentTime)
+	return renderPromptPlaceholdersWithStatus(template, knowledgeBases, false, currentTime, "")
 }
 
 // BuildPureAgentSystemPrompt Pure Agent 모드(KB 없음)를 위한 시스템 프롬프트 빌드
@@ -295,15 +303,17 @@ func BuildPureAgentSystemPrompt(
 	}
 	currentTime := time.Now().Format(time.RFC3339)
 	// 빈 KB 목록 전달
-	return renderPromptPlaceholdersWithStatus(template, []*KnowledgeBaseInfo{}, webSearchEnabled, currentTime)
+	return renderPromptPlaceholdersWithStatus(template, []*KnowledgeBaseInfo{}, webSearchEnabled, currentTime, "")
 }
 
 // BuildSystemPrompt 점진적 RAG 시스템 프롬프트 빌드
 // 이것이 주로 사용해야 할 함수입니다 - {{web_search_status}} 플레이스홀더를 통해 동적으로 적응하는 통합 템플릿을 사용합니다
+// userMemories는 {{user_memories}}에 렌더링되는 사용자 기억입니다
 func BuildSystemPrompt(
 	knowledgeBases []*KnowledgeBaseInfo,
 	webSearchEnabled bool,
 	selectedDocs []*SelectedDocumentInfo,
+	userMemories string,
 	systemPromptTemplate ...string,
 ) string {
 	var basePrompt string
@@ -319,7 +329,7 @@ func BuildSystemPrompt(
 	}
 
 	currentTime := time.Now().Format(time.RFC3339)
-	basePrompt = renderPromptPlaceholdersWithStatus(template, knowledgeBases, webSearchEnabled, currentTime)
+	basePrompt = renderPromptPlaceholdersWithStatus(template, knowledgeBases, webSearchEnabled, currentTime, userMemories)
 
 	// 선택된 문서 섹션이 있으면 추가
 	if len(selectedDocs) > 0 {
@@ -350,6 +360,10 @@ To help users solve problems by planning, thinking, and using available tools (l
 ### System Status
 Current Time: {{current_time}}
 Web Search: {{web_search_status}}
+
+### User Memory
+What you remember about the user from earlier conversations. Use it to tailor the answer, never as evidence for facts:
+{{user_memories}}
 `
 
 // ProgressiveRAGSystemPrompt는 통합 점진적 RAG 시스템 프롬프트 템플릿입니다
@@ -427,6 +441,10 @@ For every retrieval attempt (Phase 1 or Phase 3), follow this exact chain:
 Current Time: {{current_time}}
 Web Search: {{web_search_status}}
 
+### User Memory
+What you remember about the user from earlier conversations. Use it to tailor the answer, never as evidence for facts:
+{{user_memories}}
+
 ### User Selected Knowledge Bases (via @ mention)
 {{knowledge_bases}}
 `
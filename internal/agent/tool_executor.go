@@ -0,0 +1,482 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/agent/tools"
+	"github.com/Tencent/WeKnora/internal/common"
+	"github.com/Tencent/WeKnora/internal/event"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// pendingToolCall is a tool call of the current round whose arguments have been parsed
+type pendingToolCall struct {
+	index    int // 1-based position in the round, for logging
+	call     types.LLMToolCall
+	args     map[string]any
+	policy   types.ToolApprovalPolicy
+	approval *types.ToolApproval // pending approval when the policy is "ask"
+	result   *types.ToolResult
+	err      error
+	duration int64
+	done     chan struct{}
+}
+
+// planToolBatches groups the calls of a round, in order, into batches that are executed one after another.
+// Consecutive parallelizable calls share a batch; a call that is not parallelizable runs in a batch of its own,
+// so it observes the effects of every call before it and none after it.
+func planToolBatches(names []string, parallelizable func(name string) bool) [][]int {
+	batches := make([][]int, 0)
+	var current []int
+	for i, name := range names {
+		if parallelizable(name) {
+			current = append(current, i)
+			continue
+		}
+		if len(current) > 0 {
+			batches = append(batches, current)
+			current = nil
+		}
+		batches = append(batches, []int{i})
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// maxParallelToolCalls returns the maximum number of tool calls running at once
+func (e *AgentEngine) maxParallelToolCalls() int {
+	if e.config.MaxParallelToolCalls > 0 {
+		return e.config.MaxParallelToolCalls
+	}
+	return DefaultAgentMaxParallelToolCalls
+}
+
+// toolTimeout returns the timeout of a single tool call
+func (e *AgentEngine) toolTimeout() time.Duration {
+	if e.config.ToolTimeoutSeconds > 0 {
+		return time.Duration(e.config.ToolTimeoutSeconds) * time.Second
+	}
+	return DefaultAgentToolTimeoutSeconds * time.Second
+}
+
+// toolApprovalTimeout returns how long a tool call waits for approval
+func (e *AgentEngine) toolApprovalTimeout() time.Duration {
+	if e.config.ToolApprovalTimeoutSeconds > 0 {
+		return time.Duration(e.config.ToolApprovalTimeoutSeconds) * time.Second
+	}
+	return DefaultAgentToolApprovalTimeoutSeconds * time.Second
+}
+
+// executeToolCalls executes the tool calls of a round and records them in the step.
+// Independent calls run concurrently, but tool call and tool result events are emitted
+// in the order the LLM requested the calls.
+func (e *AgentEngine) executeToolCalls(
+	ctx context.Context,
+	step *types.AgentStep,
+	toolCalls []types.LLMToolCall,
+	iteration int,
+	sessionID string,
+	messageID string,
+) {
+	total := len(toolCalls)
+	pending := make([]*pendingToolCall, 0, total)
+	names := make([]string, 0, total)
+	for i, tc := range toolCalls {
+		logger.Infof(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool: %s, ID: %s",
+			iteration+1, i+1, total, tc.Function.Name, tc.ID)
+
+		var args map[string]any
+		if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+			logger.Errorf(ctx, "[Agent][Round-%d][Tool-%d/%d] Failed to parse tool arguments: %v",
+				iteration+1, i+1, total, err)
+			continue
+		}
+
+		// Log the arguments in a readable format
+		argsJSON, _ := json.MarshalIndent(args, "", "  ")
+		logger.Infof(ctx, "[Agent][Round-%d][Tool-%d/%d] Arguments:\n%s",
+			iteration+1, i+1, total, string(argsJSON))
+
+		pending = append(pending, &pendingToolCall{
+			index: i + 1,
+			call:  tc,
+			args:  args,
+			done:  make(chan struct{}),
+		})
+		names = append(names, tc.Function.Name)
+	}
+
+	batches := planToolBatches(names, e.toolRegistry.IsParallelizable)
+	logger.Infof(ctx, "[Agent][Round-%d] Executing %d tool calls in %d batches (max parallel: %d)",
+		iteration+1, len(pending), len(batches), e.maxParallelToolCalls())
+	for _, batch := range batches {
+		calls := make([]*pendingToolCall, 0, len(batch))
+		for _, idx := range batch {
+			calls = append(calls, pending[idx])
+		}
+		e.executeToolBatch(ctx, step, calls, iteration, total, sessionID, messageID)
+	}
+}
+
+// executeToolBatch runs a batch of independent tool calls concurrently, bounded by the configured limit.
+// Results are emitted in call order, so a slow call holds back the results of the calls after it
+// but never their execution. Calls waiting for approval do not take up a slot.
+func (e *AgentEngine) executeToolBatch(
+	ctx context.Context,
+	step *types.AgentStep,
+	calls []*pendingToolCall,
+	iteration int,
+	total int,
+	sessionID string,
+	messageID string,
+) {
+	// Announce every call of the batch, and the approvals it needs, before any of them starts
+	for _, pc := range calls {
+		e.eventBus.Emit(ctx, event.Event{
+			ID:        pc.call.ID + "-tool-call",
+			Type:      event.EventAgentToolCall,
+			SessionID: sessionID,
+			Data: event.AgentToolCallData{
+				ToolCallID: pc.call.ID,
+				ToolName:   pc.call.Function.Name,
+				Arguments:  pc.args,
+				Iteration:  iteration,
+			},
+		})
+		logger.Debugf(ctx, "[Agent] ToolCall -> %s args=%s", pc.call.Function.Name, pc.call.Function.Arguments)
+		common.PipelineInfo(ctx, "Agent", "tool_call_start", map[string]interface{}{
+			"iteration":    iteration,
+			"round":        iteration + 1,
+			"tool":         pc.call.Function.Name,
+			"tool_call_id": pc.call.ID,
+			"tool_index":   fmt.Sprintf("%d/%d", pc.index, total),
+		})
+		e.requestApproval(ctx, pc, iteration, sessionID, messageID)
+	}
+
+	slots := make(chan struct{}, e.maxParallelToolCalls())
+	for _, pc := range calls {
+		go func(pc *pendingToolCall) {
+			defer close(pc.done)
+			if !e.awaitApproval(ctx, pc, iteration, sessionID) {
+				return
+			}
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			logger.Infof(ctx, "[Agent][Round-%d][Tool-%d/%d] Executing tool: %s...",
+				iteration+1, pc.index, total, pc.call.Function.Name)
+			startTime := time.Now()
+			toolCtx := tools.WithToolCall(ctx, tools.ToolCallInfo{ID: pc.call.ID, MessageID: messageID})
+			pc.result, pc.err = e.runTool(toolCtx, pc.call.Function.Name, json.RawMessage(pc.call.Function.Arguments))
+			pc.duration = time.Since(startTime).Milliseconds()
+		}(pc)
+	}
+
+	for _, pc := range calls {
+		<-pc.done
+		e.recordToolCall(ctx, step, pc, iteration, total, sessionID)
+	}
+}
+
+// requestApproval resolves the approval policy of a call and, when the policy asks for approval,
+// persists the approval request and announces it
+func (e *AgentEngine) requestApproval(
+	ctx context.Context,
+	pc *pendingToolCall,
+	iteration int,
+	sessionID string,
+	messageID string,
+) {
+	name := pc.call.Function.Name
+	mcpServiceID := e.toolRegistry.MCPServiceID(name)
+	pc.policy = e.config.ApprovalPolicy(name, mcpServiceID)
+	if pc.policy != types.ToolApprovalAsk {
+		return
+	}
+	if e.toolApprovals == nil {
+		pc.err = fmt.Errorf("tool %s requires approval, but approvals are not available", name)
+		return
+	}
+
+	approval := &types.ToolApproval{
+		SessionID:    sessionID,
+		MessageID:    messageID,
+		ToolCallID:   pc.call.ID,
+		ToolName:     name,
+		MCPServiceID: mcpServiceID,
+		Iteration:    iteration,
+		Arguments:    types.JSON(pc.call.Function.Arguments),
+		ExpiresAt:    time.Now().Add(e.toolApprovalTimeout()),
+	}
+	if err := e.toolApprovals.RequestApproval(ctx, approval); err != nil {
+		pc.err = fmt.Errorf("failed to request approval for tool %s: %w", name, err)
+		return
+	}
+	pc.approval = approval
+
+	logger.Infof(ctx, "[Agent][Round-%d][Tool-%d] Waiting for approval %s of tool %s",
+		iteration+1, pc.index, approval.ID, name)
+	common.PipelineInfo(ctx, "Agent", "tool_approval_requested", map[string]interface{}{
+		"iteration":    iteration,
+		"tool":         name,
+		"tool_call_id": pc.call.ID,
+		"approval_id":  approval.ID,
+	})
+	e.emitApproval(ctx, pc, approval, iteration, sessionID)
+}
+
+// awaitApproval blocks until the call may run and reports whether it may.
+// A call that may not run gets the reason as its error, which is returned to the LLM as the tool result.
+func (e *AgentEngine) awaitApproval(ctx context.Context, pc *pendingToolCall, iteration int, sessionID string) bool {
+	if pc.err != nil {
+		return false
+	}
+	name := pc.call.Function.Name
+	switch pc.policy {
+	case types.ToolApprovalDeny:
+		pc.err = fmt.Errorf("tool %s is not allowed by the approval policy of this agent", name)
+		return false
+	case types.ToolApprovalAsk:
+	default:
+		return true
+	}
+
+	approval, err := e.toolApprovals.WaitForDecision(ctx, pc.approval.ID)
+	if err != nil {
+		pc.err = fmt.Errorf("failed to wait for approval of tool %s: %w", name, err)
+		return false
+	}
+	common.PipelineInfo(ctx, "Agent", "tool_approval_decided", map[string]interface{}{
+		"iteration":    iteration,
+		"tool":         name,
+		"tool_call_id": pc.call.ID,
+		"approval_id":  approval.ID,
+		"status":       approval.Status,
+	})
+	e.emitApproval(ctx, pc, approval, iteration, sessionID)
+
+	switch approval.Status {
+	case types.ToolApprovalApproved:
+		args, err := approval.ApprovedArguments.Map()
+		if err != nil {
+			pc.err = fmt.Errorf("invalid approved arguments for tool %s: %w", name, err)
+			return false
+		}
+		// The tool runs with the arguments as approved, which the user may have edited
+		pc.args = args
+		pc.call.Function.Arguments = approval.ApprovedArguments.ToString()
+		return true
+	case types.ToolApprovalRejected:
+		if approval.Comment != "" {
+			pc.err = fmt.Errorf("the user rejected the call to tool %s: %s", name, approval.Comment)
+		} else {
+			pc.err = fmt.Errorf("the user rejected the call to tool %s", name)
+		}
+	default:
+		pc.err = fmt.Errorf("the call to tool %s was not approved in time", name)
+	}
+	return false
+}
+
+// emitApproval emits the state of the approval of a call. The request and its decision share the event ID.
+func (e *AgentEngine) emitApproval(
+	ctx context.Context,
+	pc *pendingToolCall,
+	approval *types.ToolApproval,
+	iteration int,
+	sessionID string,
+) {
+	args, _ := approval.ApprovedArguments.Map()
+	e.eventBus.Emit(ctx, event.Event{
+		ID:        pc.call.ID + "-tool-approval",
+		Type:      event.EventAgentToolApproval,
+		SessionID: sessionID,
+		Data: event.AgentToolApprovalData{
+			ApprovalID: approval.ID,
+			ToolCallID: pc.call.ID,
+			ToolName:   pc.call.Function.Name,
+			Arguments:  args,
+			Status:     string(approval.Status),
+			Comment:    approval.Comment,
+			ExpiresAt:  approval.ExpiresAt,
+			Iteration:  iteration,
+		},
+	})
+}
+
+// runTool executes a tool with the per-call timeout, or the tool's own timeout for long-running tools.
+// A tool that does not return once its context is done is abandoned, and a panic in the tool is
+// reported as a failed call.
+func (e *AgentEngine) runTool(ctx context.Context, name string, args json.RawMessage) (*types.ToolResult, error) {
+	timeout := e.toolTimeout()
+	if t, ok := e.toolRegistry.Timeout(name); ok {
+		timeout = t
+	}
+	toolCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result *types.ToolResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: fmt.Errorf("tool %s panicked: %v", name, r)}
+			}
+		}()
+		result, err := e.toolRegistry.ExecuteTool(toolCtx, name, args)
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-toolCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("tool %s timed out after %s", name, timeout)
+	}
+}
+
+// recordToolCall logs a finished tool call, stores it in the step and emits its result events
+func (e *AgentEngine) recordToolCall(
+	ctx context.Context,
+	step *types.AgentStep,
+	pc *pendingToolCall,
+	iteration int,
+	total int,
+	sessionID string,
+) {
+	tc := pc.call
+	logger.Infof(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool execution completed in %dms",
+		iteration+1, pc.index, total, pc.duration)
+
+	toolCall := types.ToolCall{
+		ID:       tc.ID,
+		Name:     tc.Function.Name,
+		Args:     pc.args,
+		Result:   pc.result,
+		Duration: pc.duration,
+	}
+	if pc.err != nil {
+		logger.Errorf(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool call failed: %s, error: %v",
+			iteration+1, pc.index, total, tc.Function.Name, pc.err)
+		toolCall.Result = &types.ToolResult{
+			Success: false,
+			Error:   pc.err.Error(),
+		}
+	}
+	if toolCall.Result == nil {
+		toolCall.Result = &types.ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("tool %s returned no result", tc.Function.Name),
+		}
+	}
+	result := toolCall.Result
+
+	pipelineFields := map[string]interface{}{
+		"iteration":    iteration,
+		"round":        iteration + 1,
+		"tool":         tc.Function.Name,
+		"tool_call_id": tc.ID,
+		"duration_ms":  pc.duration,
+		"success":      result.Success,
+	}
+	if result.Error != "" {
+		pipelineFields["error"] = result.Error
+	}
+	if pc.err != nil {
+		common.PipelineError(ctx, "Agent", "tool_call_result", pipelineFields)
+	} else if result.Success {
+		common.PipelineInfo(ctx, "Agent", "tool_call_result", pipelineFields)
+	} else {
+		common.PipelineWarn(ctx, "Agent", "tool_call_result", pipelineFields)
+	}
+
+	logger.Infof(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool result: success=%v, output_length=%d",
+		iteration+1, pc.index, total, result.Success, len(result.Output))
+	logger.Debugf(ctx, "[Agent] ToolResult <- %s success=%v len(output)=%d",
+		tc.Function.Name, result.Success, len(result.Output))
+
+	// Log the output content for debugging
+	if result.Output != "" {
+		// Truncate if too long for logging
+		outputPreview := result.Output
+		if len(outputPreview) > 500 {
+			outputPreview = outputPreview[:500] + "... (truncated)"
+		}
+		logger.Debugf(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool output preview:\n%s",
+			iteration+1, pc.index, total, outputPreview)
+	}
+
+	if result.Error != "" {
+		logger.Warnf(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool error: %s",
+			iteration+1, pc.index, total, result.Error)
+	}
+
+	// Log structured data if present
+	if result.Data != nil {
+		dataJSON, _ := json.MarshalIndent(result.Data, "", "  ")
+		logger.Debugf(ctx, "[Agent][Round-%d][Tool-%d/%d] Tool data:\n%s",
+			iteration+1, pc.index, total, string(dataJSON))
+	}
+
+	// Store tool call (Observations are now derived from ToolCall.Result.Output)
+	step.ToolCalls = append(step.ToolCalls, toolCall)
+
+	// Emit tool result event (include structured data from tool result)
+	e.eventBus.Emit(ctx, event.Event{
+		ID:        tc.ID + "-tool-result",
+		Type:      event.EventAgentToolResult,
+		SessionID: sessionID,
+		Data: event.AgentToolResultData{
+			ToolCallID: tc.ID,
+			ToolName:   tc.Function.Name,
+			Output:     result.Output,
+			Error:      result.Error,
+			Success:    result.Success,
+			Duration:   pc.duration,
+			Iteration:  iteration,
+			Data:       result.Data, // Pass structured data for frontend rendering
+		},
+	})
+
+	// Emit tool execution event (for internal monitoring)
+	e.eventBus.Emit(ctx, event.Event{
+		ID:        tc.ID + "-tool-exec",
+		Type:      event.EventAgentTool,
+		SessionID: sessionID,
+		Data: event.AgentActionData{
+			Iteration:  iteration,
+			ToolName:   tc.Function.Name,
+			ToolInput:  pc.args,
+			ToolOutput: result.Output,
+			Success:    result.Success,
+			Error:      result.Error,
+			Duration:   pc.duration,
+		},
+	})
+
+	// Optional: Reflection after each tool call (streaming)
+	if e.config.ReflectionEnabled {
+		reflection, err := e.streamReflectionToEventBus(
+			ctx, tc.ID, tc.Function.Name, result.Output,
+			iteration, sessionID,
+		)
+		if err != nil {
+			logger.Warnf(ctx, "Reflection failed: %v", err)
+		} else if reflection != "" {
+			// Store reflection in the tool call we just added
+			step.ToolCalls[len(step.ToolCalls)-1].Reflection = reflection
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/agent/tools"
+	"github.com/Tencent/WeKnora/internal/event"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+type fakeTool struct {
+	name       string
+	delay      time.Duration
+	sequential bool
+	running    *int32
+	maxRunning *int32
+}
+
+func (t *fakeTool) Name() string                { return t.name }
+func (t *fakeTool) Description() string         { return t.name }
+func (t *fakeTool) Parameters() json.RawMessage { return json.RawMessage(`{}`) }
+func (t *fakeTool) Parallelizable() bool        { return !t.sequential }
+
+func (t *fakeTool) Execute(ctx context.Context, args json.RawMessage) (*types.ToolResult, error) {
+	n := atomic.AddInt32(t.running, 1)
+	defer atomic.AddInt32(t.running, -1)
+	for {
+		m := atomic.LoadInt32(t.maxRunning)
+		if n <= m || atomic.CompareAndSwapInt32(t.maxRunning, m, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(t.delay):
+		return &types.ToolResult{Success: true, Output: t.name}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestPlanToolBatches(t *testing.T) {
+	names := []string{"a", "b", "todo", "c", "todo", "todo", "d", "e"}
+	batches := planToolBatches(names, func(name string) bool { return name != "todo" })
+	want := [][]int{{0, 1}, {2}, {3}, {4}, {5}, {6, 7}}
+	if !reflect.DeepEqual(batches, want) {
+		t.Fatalf("batches = %v, want %v", batches, want)
+	}
+}
+
+func TestMCPToolsAreSequential(t *testing.T) {
+	registry := tools.NewToolRegistry()
+	mcpTool := tools.NewMCPTool(
+		&types.MCPService{ID: "svc-1", Name: "files"},
+		&types.MCPTool{Name: "write_file"},
+		nil,
+	)
+	registry.RegisterTool(mcpTool)
+	var running, maxRunning int32
+	registry.RegisterTool(&fakeTool{name: "search", running: &running, maxRunning: &maxRunning})
+
+	if registry.IsParallelizable(mcpTool.Name()) {
+		t.Fatalf("%s is parallelizable, want sequential", mcpTool.Name())
+	}
+	names := []string{"search", mcpTool.Name(), mcpTool.Name(), "search", "search"}
+	batches := planToolBatches(names, registry.IsParallelizable)
+	want := [][]int{{0}, {1}, {2}, {3, 4}}
+	if !reflect.DeepEqual(batches, want) {
+		t.Fatalf("batches = %v, want %v", batches, want)
+	}
+}
+
+func TestExecuteToolCallsOrdersEvents(t *testing.T) {
+	var running, maxRunning int32
+	registry := tools.NewToolRegistry()
+	for _, tool := range []*fakeTool{
+		{name: "slow", delay: 150 * time.Millisecond},
+		{name: "fast", delay: 10 * time.Millisecond},
+		{name: "plan", delay: 10 * time.Millisecond, sequential: true},
+	} {
+		tool.running, tool.maxRunning = &running, &maxRunning
+		registry.RegisterTool(tool)
+	}
+
+	var mu sync.Mutex
+	var events []string
+	bus := event.NewEventBus()
+	record := func(ctx context.Context, evt event.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, evt.ID)
+		return nil
+	}
+	bus.On(event.EventAgentToolCall, record)
+	bus.On(event.EventAgentToolResult, record)
+
+	engine := NewAgentEngine(&types.AgentConfig{MaxParallelToolCalls: 2},
+		nil, registry, bus, nil, nil, nil, "session", "", nil, nil)
+	calls := make([]types.LLMToolCall, 0)
+	for i, name := range []string{"slow", "fast", "fast", "plan", "fast"} {
+		calls = append(calls, types.LLMToolCall{
+			ID:       name + string(rune('0'+i)),
+			Function: types.FunctionCall{Name: name, Arguments: `{}`},
+		})
+	}
+	step := &types.AgentStep{}
+	engine.executeToolCalls(context.Background(), step, calls, 0, "session", "message")
+
+	want := []string{
+		"slow0-tool-call", "fast1-tool-call", "fast2-tool-call",
+		"slow0-tool-result", "fast1-tool-result", "fast2-tool-result",
+		"plan3-tool-call", "plan3-tool-result",
+		"fast4-tool-call", "fast4-tool-result",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	if maxRunning != 2 {
+		t.Fatalf("max concurrent tool calls = %d, want 2", maxRunning)
+	}
+	if len(step.ToolCalls) != len(calls) {
+		t.Fatalf("recorded %d tool calls, want %d", len(step.ToolCalls), len(calls))
+	}
+	for i, call := range step.ToolCalls {
+		if call.ID != calls[i].ID || !call.Result.Success {
+			t.Fatalf("tool call %d = %s (success %v), want %s", i, call.ID, call.Result.Success, calls[i].ID)
+		}
+	}
+}
+
+func TestExecuteToolCallsTimeout(t *testing.T) {
+	var running, maxRunning int32
+	registry := tools.NewToolRegistry()
+	registry.RegisterTool(&fakeTool{
+		name: "hang", delay: time.Minute, running: &running, maxRunning: &maxRunning,
+	})
+
+	engine := NewAgentEngine(&types.AgentConfig{ToolTimeoutSeconds: 1},
+		nil, registry, nil, nil, nil, nil, "session", "", nil, nil)
+	step := &types.AgentStep{}
+	engine.executeToolCalls(context.Background(), step, []types.LLMToolCall{
+		{ID: "call", Function: types.FunctionCall{Name: "hang", Arguments: `{}`}},
+	}, 0, "session", "message")
+
+	if len(step.ToolCalls) != 1 {
+		t.Fatalf("recorded %d tool calls, want 1", len(step.ToolCalls))
+	}
+	result := step.ToolCalls[0].Result
+	if result.Success || !strings.Contains(result.Error, "timed out") {
+		t.Fatalf("result = %+v, want a timeout error", result)
+	}
+}
+
+// longRunningTool outlives the agent's tool timeout and records the tool call it runs for
+type longRunningTool struct {
+	fakeTool
+	call tools.ToolCallInfo
+}
+
+func (t *longRunningTool) Timeout() time.Duration { return 5 * time.Second }
+
+func (t *longRunningTool) Execute(ctx context.Context, args json.RawMessage) (*types.ToolResult, error) {
+	t.call, _ = tools.ToolCallFromContext(ctx)
+	return t.fakeTool.Execute(ctx, args)
+}
+
+func TestExecuteToolCallsLongRunningTool(t *testing.T) {
+	var running, maxRunning int32
+	tool := &longRunningTool{fakeTool: fakeTool{
+		name: "delegate", delay: 1500 * time.Millisecond, running: &running, maxRunning: &maxRunning,
+	}}
+	registry := tools.NewToolRegistry()
+	registry.RegisterTool(tool)
+
+	engine := NewAgentEngine(&types.AgentConfig{ToolTimeoutSeconds: 1},
+		nil, registry, nil, nil, nil, nil, "session", "", nil, nil)
+	step := &types.AgentStep{}
+	engine.executeToolCalls(context.Background(), step, []types.LLMToolCall{
+		{ID: "call", Function: types.FunctionCall{Name: "delegate", Arguments: `{}`}},
+	}, 0, "session", "message")
+
+	if result := step.ToolCalls[0].Result; !result.Success {
+		t.Fatalf("result = %+v, want the tool to run past the agent's tool timeout", result)
+	}
+	if tool.call != (tools.ToolCallInfo{ID: "call", MessageID: "message"}) {
+		t.Fatalf("tool call in context = %+v", tool.call)
+	}
+}
+
+type fakeApprovals struct {
+	interfaces.ToolApprovalService
+	requested []*types.ToolApproval
+	arguments string
+}
+
+func (f *fakeApprovals) RequestApproval(ctx context.Context, approval *types.ToolApproval) error {
+	approval.ID = "approval-" + approval.ToolCallID
+	approval.Status = types.ToolApprovalPending
+	approval.ApprovedArguments = approval.Arguments
+	f.requested = append(f.requested, approval)
+	return nil
+}
+
+func (f *fakeApprovals) WaitForDecision(ctx context.Context, id string) (*types.ToolApproval, error) {
+	for _, approval := range f.requested {
+		if approval.ID == id {
+			decided := *approval
+			decided.Status = types.ToolApprovalApproved
+			decided.ApprovedArguments = types.JSON(f.arguments)
+			return &decided, nil
+		}
+	}
+	return nil, context.Canceled
+}
+
+func TestExecuteToolCallsApproval(t *testing.T) {
+	var running, maxRunning int32
+	registry := tools.NewToolRegistry()
+	for _, name := range []string{"search", "send_mail", "drop_table"} {
+		registry.RegisterTool(&fakeTool{name: name, running: &running, maxRunning: &maxRunning})
+	}
+
+	approvals := &fakeApprovals{arguments: `{"to":"team@example.com"}`}
+	engine := NewAgentEngine(&types.AgentConfig{
+		ToolApprovalPolicies: map[string]types.ToolApprovalPolicy{
+			"send_mail":  types.ToolApprovalAsk,
+			"drop_table": types.ToolApprovalDeny,
+		},
+	}, nil, registry, nil, nil, nil, nil, "session", "", approvals, nil)
+	step := &types.AgentStep{}
+	engine.executeToolCalls(context.Background(), step, []types.LLMToolCall{
+		{ID: "a", Function: types.FunctionCall{Name: "search", Arguments: `{}`}},
+		{ID: "b", Function: types.FunctionCall{Name: "send_mail", Arguments: `{"to":"all@example.com"}`}},
+		{ID: "c", Function: types.FunctionCall{Name: "drop_table", Arguments: `{}`}},
+	}, 0, "session", "message")
+
+	if len(approvals.requested) != 1 || approvals.requested[0].ToolName != "send_mail" {
+		t.Fatalf("requested approvals = %+v, want one for send_mail", approvals.requested)
+	}
+	if approvals.requested[0].MessageID != "message" {
+		t.Fatalf("approval message id = %q, want message", approvals.requested[0].MessageID)
+	}
+	if len(step.ToolCalls) != 3 {
+		t.Fatalf("recorded %d tool calls, want 3", len(step.ToolCalls))
+	}
+	if !step.ToolCalls[0].Result.Success {
+		t.Fatalf("auto tool call failed: %+v", step.ToolCalls[0].Result)
+	}
+	approved := step.ToolCalls[1]
+	if !approved.Result.Success || approved.Args["to"] != "team@example.com" {
+		t.Fatalf("approved tool call = %+v, want success with the edited arguments", approved)
+	}
+	denied := step.ToolCalls[2].Result
+	if denied.Success || !strings.Contains(denied.Error, "not allowed") {
+		t.Fatalf("denied tool call = %+v, want a policy error", denied)
+	}
+}
@@ -42,6 +42,11 @@ func NewDataAnalysisTool(knowledgeService interfaces.KnowledgeService, db *sql.D
 	}
 }
 
+// Parallelizable reports false because queries may depend on tables loaded by earlier calls
+func (t *DataAnalysisTool) Parallelizable() bool {
+	return false
+}
+
 // recordCreatedTable records a table name for cleanup, ensuring uniqueness
 // Returns true if the table was newly recorded, false if it already existed
 func (t *DataAnalysisTool) recordCreatedTable(tableName string) bool {
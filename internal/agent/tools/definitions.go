@@ -14,6 +14,7 @@ const (
 	ToolDataSchema          = "data_schema"
 	ToolWebSearch           = "web_search"
 	ToolWebFetch            = "web_fetch"
+	ToolDelegateAgent       = "delegate_agent"
 )
 
 // AvailableTool defines a simple tool metadata used by settings APIs.
@@ -37,6 +38,7 @@ func AvailableToolDefinitions() []AvailableTool {
 		{Name: ToolDatabaseQuery, Label: "데이터베이스 쿼리", Description: "데이터베이스에서 정보 쿼리"},
 		{Name: ToolDataAnalysis, Label: "데이터 분석", Description: "데이터 파일을 이해하고 데이터 분석 수행"},
 		{Name: ToolDataSchema, Label: "데이터 메타 정보 보기", Description: "테이블 파일의 메타 정보 가져오기"},
+		{Name: ToolDelegateAgent, Label: "에이전트 위임", Description: "하위 질문을 다른 에이전트에 맡기고 답변과 참조 받기"},
 	}
 }
 
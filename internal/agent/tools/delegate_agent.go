@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/utils"
+)
+
+// delegateAgentTimeout bounds a delegated task, the sub-agent runs several rounds of its own
+const delegateAgentTimeout = 10 * time.Minute
+
+var delegateAgentTool = BaseTool{
+	name: ToolDelegateAgent,
+	description: `Delegate a sub-question to another specialized agent and get back its answer and references.
+
+The agent runs on its own, with its own knowledge bases, tools and instructions. It does NOT see this conversation,
+so the task must be self-contained.
+
+## Available Agents
+%s
+
+## Usage
+
+**Use when**:
+- The question, or part of it, falls into the specialty of one of the agents above
+- The answer needs knowledge bases or tools that only another agent has
+
+**Do NOT use when**:
+- You can answer with your own tools
+- The task is not clear yet, plan it first
+
+**Parameters**:
+- agent_id (required): ID of one of the agents above
+- task (required): Self-contained task for the agent, including every detail it needs from the conversation
+
+**Returns**: The final answer of the agent and the knowledge it referenced
+
+## Tips
+- Split a question that spans several specialties into one task per agent, independent tasks run concurrently
+- Check the answer against the question and delegate a follow-up task if it is incomplete
+- Cite the references of the agent's answer in your final answer`,
+	schema: utils.GenerateSchema[DelegateAgentInput](),
+}
+
+// DelegateAgentInput defines the input parameters for delegate agent tool
+type DelegateAgentInput struct {
+	AgentID string `json:"agent_id" jsonschema:"ID of the agent to delegate the task to"`
+	Task    string `json:"task" jsonschema:"Self-contained task for the agent, including every detail it needs"`
+}
+
+// SubAgentResult is the outcome of a task run by another agent
+type SubAgentResult struct {
+	Answer     string                // Final answer of the agent
+	References []*types.SearchResult // Knowledge the agent retrieved
+	Rounds     int                   // ReAct rounds the agent ran
+}
+
+// SubAgentRunner runs tasks delegated to other custom agents
+type SubAgentRunner interface {
+	// RunSubAgent runs the task on the agent as a nested agent engine and returns its final answer
+	RunSubAgent(ctx context.Context, agent *types.CustomAgent, task string) (*SubAgentResult, error)
+}
+
+// DelegateAgentTool hands sub-questions to other custom agents
+type DelegateAgentTool struct {
+	BaseTool
+	agents map[string]*types.CustomAgent
+	runner SubAgentRunner
+}
+
+// NewDelegateAgentTool creates a new delegate agent tool for the given agents
+func NewDelegateAgentTool(agents []*types.CustomAgent, runner SubAgentRunner) *DelegateAgentTool {
+	tool := delegateAgentTool
+	agentMap := make(map[string]*types.CustomAgent, len(agents))
+	var list strings.Builder
+	for _, agent := range agents {
+		agentMap[agent.ID] = agent
+		fmt.Fprintf(&list, "- agent_id: %s\n  name: %s\n", agent.ID, agent.Name)
+		if agent.Description != "" {
+			fmt.Fprintf(&list, "  description: %s\n", agent.Description)
+		}
+	}
+	tool.description = fmt.Sprintf(tool.description, strings.TrimRight(list.String(), "\n"))
+
+	return &DelegateAgentTool{
+		BaseTool: tool,
+		agents:   agentMap,
+		runner:   runner,
+	}
+}
+
+// Timeout returns the timeout of a delegated task, which is longer than the timeout of other tools
+func (t *DelegateAgentTool) Timeout() time.Duration {
+	return delegateAgentTimeout
+}
+
+// Execute runs the task on the requested agent
+func (t *DelegateAgentTool) Execute(ctx context.Context, args json.RawMessage) (*types.ToolResult, error) {
+	var input DelegateAgentInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		logger.Errorf(ctx, "[Tool][DelegateAgent] Failed to parse args: %v", err)
+		return &types.ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to parse args: %v", err),
+		}, err
+	}
+
+	agent, ok := t.agents[input.AgentID]
+	if !ok {
+		ids := make([]string, 0, len(t.agents))
+		for id := range t.agents {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return &types.ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("unknown agent_id %q, available agents: %s", input.AgentID, strings.Join(ids, ", ")),
+		}, nil
+	}
+	task := strings.TrimSpace(input.Task)
+	if task == "" {
+		return &types.ToolResult{
+			Success: false,
+			Error:   "task is required",
+		}, nil
+	}
+
+	logger.Infof(ctx, "[Tool][DelegateAgent] Delegating task to agent %s (%s)", agent.ID, agent.Name)
+	result, err := t.runner.RunSubAgent(ctx, agent, task)
+	if err != nil {
+		logger.Warnf(ctx, "[Tool][DelegateAgent] Agent %s failed: %v", agent.ID, err)
+		return &types.ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("agent %s failed: %v", agent.Name, err),
+		}, nil
+	}
+	logger.Infof(ctx, "[Tool][DelegateAgent] Agent %s answered in %d rounds with %d references",
+		agent.ID, result.Rounds, len(result.References))
+
+	output := fmt.Sprintf("=== 에이전트 %s 의 답변 ===\n\n%s\n", agent.Name, result.Answer)
+	if len(result.References) > 0 {
+		output += "\n=== 참조 ===\n\n"
+		for i, ref := range result.References {
+			output += fmt.Sprintf("[%d] %s (knowledge_id: %s, chunk_id: %s)\n",
+				i+1, ref.KnowledgeTitle, ref.KnowledgeID, ref.ID)
+		}
+	}
+
+	return &types.ToolResult{
+		Success: true,
+		Output:  output,
+		Data: map[string]interface{}{
+			"agent_id":     agent.ID,
+			"agent_name":   agent.Name,
+			"answer":       result.Answer,
+			"references":   result.References,
+			"rounds":       result.Rounds,
+			"display_type": "sub_agent_result",
+		},
+	}, nil
+}
@@ -72,6 +72,13 @@ Avoid:
 - queries (required): 1–5 semantic questions or conceptual statements.
   These should reflect the meaning or topic you want embeddings to capture.
 - knowledge_base_ids (optional): limit the search scope.
+- filter (optional): metadata filter to scope results, e.g. to a product version or document date.
+  Leaf conditions use "op" eq/in/range/exists on a "field":
+  "knowledge.file_type", "knowledge.created_at" (range only),
+  "knowledge.metadata.<key>" or "chunk.metadata.<key>".
+  Combine conditions with "op" and/or/not and "filters".
+  Example: {"op": "and", "filters": [{"op": "eq", "field": "knowledge.metadata.version", "value": "2.1"},
+  {"op": "range", "field": "knowledge.created_at", "gte": "2024-01-01"}]}
 
 ## Output
 Returns chunks ranked by semantic similarity, reranked when applicable.  
@@ -96,6 +103,37 @@ Results represent conceptual relevance, not literal keyword overlap.`,
       },
       "minItems": 0,
       "maxItems": 10
+    },
+    "filter": {
+      "type": "object",
+      "description": "Optional: metadata filter expression",
+      "properties": {
+        "op": {
+          "type": "string",
+          "enum": ["eq", "in", "range", "exists", "and", "or", "not"]
+        },
+        "field": {
+          "type": "string",
+          "description": "knowledge.file_type, knowledge.created_at, knowledge.metadata.<key> or chunk.metadata.<key>"
+        },
+        "value": {
+          "description": "Value for eq"
+        },
+        "values": {
+          "type": "array",
+          "description": "Values for in"
+        },
+        "gt": {"description": "Range bound, number or RFC3339 timestamp"},
+        "gte": {"description": "Range bound, number or RFC3339 timestamp"},
+        "lt": {"description": "Range bound, number or RFC3339 timestamp"},
+        "lte": {"description": "Range bound, number or RFC3339 timestamp"},
+        "filters": {
+          "type": "array",
+          "description": "Sub filters for and/or/not",
+          "items": {"type": "object"}
+        }
+      },
+      "required": ["op"]
     }
   },
   "required": ["queries"]
@@ -104,8 +142,9 @@ Results represent conceptual relevance, not literal keyword overlap.`,
 
 // KnowledgeSearchInput defines the input parameters for knowledge search tool
 type KnowledgeSearchInput struct {
-	Queries          []string `json:"queries"`
-	KnowledgeBaseIDs []string `json:"knowledge_base_ids,omitempty"`
+	Queries          []string              `json:"queries"`
+	KnowledgeBaseIDs []string              `json:"knowledge_base_ids,omitempty"`
+	Filter           *types.MetadataFilter `json:"filter,omitempty"`
 }
 
 // searchResultWithMeta wraps search result with metadata about which query matched it
@@ -169,6 +208,17 @@ func (t *KnowledgeSearchTool) Execute(ctx context.Context, args json.RawMessage)
 	argsJSON, _ := json.MarshalIndent(input, "", "  ")
 	logger.Debugf(ctx, "[Tool][KnowledgeSearch] Input args:\n%s", string(argsJSON))
 
+	// Validate the metadata filter so the model can correct it
+	if input.Filter != nil {
+		if err := input.Filter.Validate(); err != nil {
+			logger.Errorf(ctx, "[Tool][KnowledgeSearch] Invalid filter: %v", err)
+			return &types.ToolResult{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid filter: %v", err),
+			}, err
+		}
+	}
+
 	// Determine which KBs to search - user can optionally filter to specific KBs
 	var userSpecifiedKBs []string
 	if len(input.KnowledgeBaseIDs) > 0 {
@@ -281,12 +331,12 @@ func (t *KnowledgeSearchTool) Execute(ctx context.Context, args json.RawMessage)
 	kbTypeMap := t.getKnowledgeBaseTypes(ctx, kbIDs)
 
 	allResults := t.concurrentSearchByTargets(ctx, queries, searchTargets,
-		topK, vectorThreshold, keywordThreshold, kbTypeMap)
+		topK, vectorThreshold, keywordThreshold, input.Filter, kbTypeMap)
 	logger.Infof(ctx, "[Tool][KnowledgeSearch] Concurrent search completed: %d raw results", len(allResults))
 
-	// Note: HybridSearch now uses RRF (Reciprocal Rank Fusion) which produces normalized scores
-	// RRF scores are in range [0, ~0.033] (max when rank=1 on both sides: 2/(60+1))
-	// Threshold filtering is already done inside HybridSearch before RRF, so we skip it here
+	// Note: HybridSearch now fuses keyword and vector results (RRF by default, configurable per KB)
+	// With the default RRF, scores are in range [0, ~0.033] (max when rank=1 on both sides: 2/(60+1))
+	// Threshold filtering is already done inside HybridSearch before fusion, so we skip it here
 
 	// Deduplicate before reranking to reduce processing overhead
 	deduplicatedBeforeRerank := t.deduplicateResults(allResults)
@@ -367,8 +417,8 @@ func (t *KnowledgeSearchTool) Execute(ctx context.Context, args json.RawMessage)
 		}
 	}
 
-	// Note: minScore filter is skipped because HybridSearch now uses RRF scores
-	// RRF scores are in range [0, ~0.033], not [0, 1], so old thresholds don't apply
+	// Note: minScore filter is skipped because HybridSearch now returns fused scores
+	// whose range depends on the fusion method (e.g. RRF is [0, ~0.033]), so old thresholds don't apply
 	// Threshold filtering is already done inside HybridSearch before RRF fusion
 
 	// Final deduplication after rerank (in case rerank changed scores/order but duplicates remain)
@@ -438,6 +488,7 @@ func (t *KnowledgeSearchTool) concurrentSearchByTargets(
 	searchTargets types.SearchTargets,
 	topK int,
 	vectorThreshold, keywordThreshold float64,
+	filter *types.MetadataFilter,
 	kbTypeMap map[string]string,
 ) []*searchResultWithMeta {
 	var wg sync.WaitGroup
@@ -457,6 +508,7 @@ func (t *KnowledgeSearchTool) concurrentSearchByTargets(
 					MatchCount:       topK,
 					VectorThreshold:  vectorThreshold,
 					KeywordThreshold: keywordThreshold,
+					Filter:           filter,
 				}
 
 				// If target has specific knowledge IDs, add them to search params
@@ -39,6 +39,11 @@ func (t *MCPTool) Name() string {
 	return fmt.Sprintf("mcp.%s.%s", serviceName, toolName)
 }
 
+// ServiceID returns the ID of the MCP service providing the tool
+func (t *MCPTool) ServiceID() string {
+	return t.service.ID
+}
+
 // Description returns the tool description
 func (t *MCPTool) Description() string {
 	serviceDesc := fmt.Sprintf("[MCP Service: %s] ", t.service.Name)
@@ -60,6 +65,12 @@ func (t *MCPTool) Parameters() json.RawMessage {
 	}`)
 }
 
+// Parallelizable reports false because MCP tools may have side effects the agent cannot see,
+// and stdio services serve one call at a time
+func (t *MCPTool) Parallelizable() bool {
+	return false
+}
+
 // Execute executes the MCP tool
 func (t *MCPTool) Execute(ctx context.Context, args json.RawMessage) (*types.ToolResult, error) {
 	logger.GetLogger(ctx).Infof("Executing MCP tool: %s from service: %s", t.mcpTool.Name, t.service.Name)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/Tencent/WeKnora/internal/common"
 	"github.com/Tencent/WeKnora/internal/types"
@@ -57,6 +58,35 @@ func (r *ToolRegistry) GetFunctionDefinitions() []types.FunctionDefinition {
 	return definitions
 }
 
+// IsParallelizable reports whether calls to the named tool may run concurrently with other calls.
+// Unknown tools are parallelizable since their execution fails without side effects.
+func (r *ToolRegistry) IsParallelizable(name string) bool {
+	tool, exists := r.tools[name]
+	if !exists {
+		return true
+	}
+	if p, ok := tool.(types.ParallelizableTool); ok {
+		return p.Parallelizable()
+	}
+	return true
+}
+
+// Timeout returns the timeout of a call to the named tool if it is a long-running tool
+func (r *ToolRegistry) Timeout(name string) (time.Duration, bool) {
+	if tool, ok := r.tools[name].(types.LongRunningTool); ok {
+		return tool.Timeout(), true
+	}
+	return 0, false
+}
+
+// MCPServiceID returns the ID of the MCP service providing the named tool, empty for built-in tools
+func (r *ToolRegistry) MCPServiceID(name string) string {
+	if tool, ok := r.tools[name].(*MCPTool); ok {
+		return tool.ServiceID()
+	}
+	return ""
+}
+
 // ExecuteTool executes a tool by name with the given arguments
 func (r *ToolRegistry) ExecuteTool(
 	ctx context.Context,
@@ -79,6 +109,8 @@ func (r *ToolRegistry) ExecuteTool(
 		}, err
 	}
 
+	// Model calls made by the tool, such as reranking or query expansion, are reported per tool
+	ctx = types.WithUsageScope(ctx, types.UsageScope{Source: types.UsageSourceAgentTool + name})
 	result, execErr := tool.Execute(ctx, args)
 	fields := map[string]interface{}{
 		"tool": name,
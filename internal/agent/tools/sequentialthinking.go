@@ -158,6 +158,11 @@ func NewSequentialThinkingTool() *SequentialThinkingTool {
 	}
 }
 
+// Parallelizable reports false because thoughts build on the history recorded by earlier calls
+func (t *SequentialThinkingTool) Parallelizable() bool {
+	return false
+}
+
 // Execute executes the sequential thinking tool
 func (t *SequentialThinkingTool) Execute(ctx context.Context, args json.RawMessage) (*types.ToolResult, error) {
 	logger.Infof(ctx, "[Tool][SequentialThinking] Execute started")
@@ -161,6 +161,11 @@ func NewTodoWriteTool() *TodoWriteTool {
 	}
 }
 
+// Parallelizable reports false because each call replaces the plan written by the previous one
+func (t *TodoWriteTool) Parallelizable() bool {
+	return false
+}
+
 // Execute executes the todo_write tool
 func (t *TodoWriteTool) Execute(ctx context.Context, args json.RawMessage) (*types.ToolResult, error) {
 	// Parse args from json.RawMessage
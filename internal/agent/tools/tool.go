@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -38,6 +39,26 @@ func (t *BaseTool) Parameters() json.RawMessage {
 	return t.schema
 }
 
+// ToolCallInfo identifies the tool call being executed
+type ToolCallInfo struct {
+	ID        string // Tool call ID from the LLM
+	MessageID string // Assistant message the agent is generating
+}
+
+// toolCallContextKey is the context key of the tool call being executed
+type toolCallContextKey struct{}
+
+// WithToolCall returns a context carrying the tool call being executed
+func WithToolCall(ctx context.Context, call ToolCallInfo) context.Context {
+	return context.WithValue(ctx, toolCallContextKey{}, call)
+}
+
+// ToolCallFromContext returns the tool call being executed, if the context carries one
+func ToolCallFromContext(ctx context.Context) (ToolCallInfo, bool) {
+	call, ok := ctx.Value(toolCallContextKey{}).(ToolCallInfo)
+	return call, ok
+}
+
 // ToolExecutor is a helper interface for executing tools
 type ToolExecutor interface {
 	types.Tool
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+// ErrAgentRunNotFound is returned when an agent run does not exist
+var ErrAgentRunNotFound = errors.New("agent run not found")
+
+// agentRunRepository implements the AgentRunRepository interface
+type agentRunRepository struct {
+	db *gorm.DB
+}
+
+// NewAgentRunRepository creates a new agent run repository
+func NewAgentRunRepository(db *gorm.DB) interfaces.AgentRunRepository {
+	return &agentRunRepository{db: db}
+}
+
+// Create creates a run
+func (r *agentRunRepository) Create(ctx context.Context, run *types.AgentRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// GetByMessage gets the run generating a message
+func (r *agentRunRepository) GetByMessage(ctx context.Context,
+	tenantID uint64, messageID string,
+) (*types.AgentRun, error) {
+	var run types.AgentRun
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND message_id = ?", tenantID, messageID).
+		First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAgentRunNotFound
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListStale lists running and interrupted runs of every tenant whose heartbeat is older than staleBefore, oldest first
+func (r *agentRunRepository) ListStale(ctx context.Context,
+	staleBefore time.Time, limit int,
+) ([]*types.AgentRun, error) {
+	var runs []*types.AgentRun
+	if err := r.db.WithContext(ctx).
+		Where("status IN ? AND heartbeat_at < ?",
+			[]types.AgentRunStatus{types.AgentRunRunning, types.AgentRunInterrupted}, staleBefore).
+		Order("heartbeat_at ASC").
+		Limit(limit).
+		Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// owned scopes an update to a run still running on its owner.
+// Every write of a holder goes through it, so a holder that lost the run can not overwrite the new one.
+func (r *agentRunRepository) owned(ctx context.Context, run *types.AgentRun) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&types.AgentRun{}).
+		Where("tenant_id = ? AND id = ? AND status = ? AND owner = ?",
+			run.TenantID, run.ID, types.AgentRunRunning, run.Owner)
+}
+
+// SaveCheckpoint stores the checkpoint of a run still running on run.Owner
+func (r *agentRunRepository) SaveCheckpoint(ctx context.Context, run *types.AgentRun) (bool, error) {
+	result := r.owned(ctx, run).Updates(map[string]interface{}{
+		"round":        run.Round,
+		"state":        run.State,
+		"messages":     run.Messages,
+		"heartbeat_at": run.HeartbeatAt,
+		"updated_at":   run.UpdatedAt,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Heartbeat refreshes the heartbeat of a run still running on run.Owner
+func (r *agentRunRepository) Heartbeat(ctx context.Context, run *types.AgentRun) (bool, error) {
+	result := r.owned(ctx, run).Update("heartbeat_at", run.HeartbeatAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Claim sets a run in one of the given statuses running on run.Owner.
+// The status condition makes concurrent claims resolve to the first one.
+func (r *agentRunRepository) Claim(ctx context.Context,
+	run *types.AgentRun, from ...types.AgentRunStatus,
+) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&types.AgentRun{}).
+		Where("tenant_id = ? AND id = ? AND status IN ?", run.TenantID, run.ID, from).
+		Updates(map[string]interface{}{
+			"status":       types.AgentRunRunning,
+			"owner":        run.Owner,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"heartbeat_at": run.HeartbeatAt,
+			"updated_at":   run.UpdatedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Finish stores the final status of a run still running on run.Owner
+func (r *agentRunRepository) Finish(ctx context.Context, run *types.AgentRun) (bool, error) {
+	result := r.owned(ctx, run).Updates(map[string]interface{}{
+		"status":     run.Status,
+		"error":      run.Error,
+		"updated_at": run.UpdatedAt,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetStatus moves a run from one of the given statuses to status
+func (r *agentRunRepository) SetStatus(ctx context.Context,
+	run *types.AgentRun, status types.AgentRunStatus, from ...types.AgentRunStatus,
+) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&types.AgentRun{}).
+		Where("tenant_id = ? AND id = ? AND status IN ?", run.TenantID, run.ID, from).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"error":      run.Error,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Interrupt marks a running run whose heartbeat is older than staleBefore as interrupted.
+// The heartbeat condition keeps a run that came back to life between listing and marking.
+func (r *agentRunRepository) Interrupt(ctx context.Context,
+	run *types.AgentRun, staleBefore time.Time,
+) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&types.AgentRun{}).
+		Where("tenant_id = ? AND id = ? AND status = ? AND heartbeat_at < ?",
+			run.TenantID, run.ID, types.AgentRunRunning, staleBefore).
+		Updates(map[string]interface{}{
+			"status":     types.AgentRunInterrupted,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+// ErrAPIKeyNotFound is returned when a tenant API key is not found
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// apiKeyRepository implements the APIKeyRepository interface
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new tenant API key repository
+func NewAPIKeyRepository(db *gorm.DB) interfaces.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// CreateAPIKey creates an API key
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, key *types.TenantAPIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// GetAPIKey gets an API key of a tenant by ID
+func (r *apiKeyRepository) GetAPIKey(ctx context.Context, tenantID uint64, id string) (*types.TenantAPIKey, error) {
+	var key types.TenantAPIKey
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAPIKeyByHash gets an API key by the hash of the key
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*types.TenantAPIKey, error) {
+	var key types.TenantAPIKey
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListAPIKeys lists the API keys of a tenant, newest first
+func (r *apiKeyRepository) ListAPIKeys(ctx context.Context, tenantID uint64) ([]*types.TenantAPIKey, error) {
+	var keys []*types.TenantAPIKey
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at DESC").
+		Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey marks an API key as revoked
+func (r *apiKeyRepository) RevokeAPIKey(ctx context.Context, tenantID uint64, id string, revokedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&types.TenantAPIKey{}).
+		Where("tenant_id = ? AND id = ? AND revoked_at IS NULL", tenantID, id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey records the last use of an API key.
+// The row is only written when the previous use is older than the given time, to limit writes on busy keys.
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time, olderThan time.Time) error {
+	return r.db.WithContext(ctx).Model(&types.TenantAPIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, olderThan).
+		UpdateColumn("last_used_at", usedAt).Error
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// auditRepository implements the AuditRepository interface
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new audit log repository
+func NewAuditRepository(db *gorm.DB) interfaces.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// lockChainHead locks the chain head of a tenant within a transaction, creating it on first use
+func lockChainHead(tx *gorm.DB, tenantID uint64) (*types.AuditChainHead, error) {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&types.AuditChainHead{TenantID: tenantID, UpdatedAt: time.Now()}).Error; err != nil {
+		return nil, err
+	}
+	var head types.AuditChainHead
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ?", tenantID).
+		First(&head).Error; err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+// AppendAuditLog appends a log to the chain of its tenant.
+// The chain head is locked while seal sets the sequence and hashes of the log, so concurrent appends stay ordered.
+func (r *auditRepository) AppendAuditLog(ctx context.Context,
+	log *types.AuditLog, seal func(head *types.AuditChainHead) error,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		head, err := lockChainHead(tx, log.TenantID)
+		if err != nil {
+			return err
+		}
+		if err := seal(head); err != nil {
+			return err
+		}
+		if err := tx.Create(log).Error; err != nil {
+			return err
+		}
+		return tx.Model(&types.AuditChainHead{}).
+			Where("tenant_id = ?", log.TenantID).
+			Updates(map[string]interface{}{"seq": log.Seq, "hash": log.Hash, "updated_at": time.Now()}).Error
+	})
+}
+
+// applyAuditFilter adds the filter conditions to a query
+func applyAuditFilter(query *gorm.DB, filter *types.AuditLogFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.ActorType != "" {
+		query = query.Where("actor_type = ?", filter.ActorType)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
+
+// ListAuditLogs lists the logs of a tenant matching the filter, newest first
+func (r *auditRepository) ListAuditLogs(ctx context.Context,
+	tenantID uint64, filter *types.AuditLogFilter, page *types.Pagination,
+) ([]*types.AuditLog, int64, error) {
+	query := applyAuditFilter(r.db.WithContext(ctx).Model(&types.AuditLog{}).Where("tenant_id = ?", tenantID), filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var logs []*types.AuditLog
+	if err := query.Order("seq DESC").
+		Offset(page.Offset()).Limit(page.Limit()).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// ScanAuditLogs lists up to limit logs of a tenant matching the filter after the given sequence, oldest first
+func (r *auditRepository) ScanAuditLogs(ctx context.Context,
+	tenantID uint64, filter *types.AuditLogFilter, afterSeq uint64, limit int,
+) ([]*types.AuditLog, error) {
+	query := applyAuditFilter(r.db.WithContext(ctx).Where("tenant_id = ? AND seq > ?", tenantID, afterSeq), filter)
+	var logs []*types.AuditLog
+	if err := query.Order("seq ASC").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// GetAuditChainHead gets the chain head of a tenant, an empty head if the tenant has no logs
+func (r *auditRepository) GetAuditChainHead(ctx context.Context, tenantID uint64) (*types.AuditChainHead, error) {
+	var head types.AuditChainHead
+	if err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).First(&head).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &types.AuditChainHead{TenantID: tenantID}, nil
+		}
+		return nil, err
+	}
+	return &head, nil
+}
+
+// ListAuditTenantIDs lists the tenants that have audit logs
+func (r *auditRepository) ListAuditTenantIDs(ctx context.Context) ([]uint64, error) {
+	var tenantIDs []uint64
+	if err := r.db.WithContext(ctx).Model(&types.AuditChainHead{}).
+		Where("seq > purged_through_seq").
+		Pluck("tenant_id", &tenantIDs).Error; err != nil {
+		return nil, err
+	}
+	return tenantIDs, nil
+}
+
+// PurgeAuditLogs deletes the logs of a tenant created before the given time.
+// The chain head keeps the sequence and hash of the last deleted log, where verification of the remaining logs starts.
+func (r *auditRepository) PurgeAuditLogs(ctx context.Context, tenantID uint64, before time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		head, err := lockChainHead(tx, tenantID)
+		if err != nil {
+			return err
+		}
+		var last types.AuditLog
+		if err := tx.Where("tenant_id = ? AND seq > ? AND created_at < ?", tenantID, head.PurgedThroughSeq, before).
+			Order("seq DESC").
+			First(&last).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		result := tx.Where("tenant_id = ? AND seq <= ?", tenantID, last.Seq).Delete(&types.AuditLog{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return tx.Model(&types.AuditChainHead{}).
+			Where("tenant_id = ?", tenantID).
+			Updates(map[string]interface{}{
+				"purged_through_seq":  last.Seq,
+				"purged_through_hash": last.Hash,
+				"updated_at":          time.Now(),
+			}).Error
+	})
+	return deleted, err
+}
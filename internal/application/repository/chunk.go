@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Tencent/WeKnora/internal/common"
 	"github.com/Tencent/WeKnora/internal/types"
@@ -56,6 +57,60 @@ func (r *chunkRepository) ListChunksByID(
 	return chunks, nil
 }
 
+// ListChunksByIDAsOf retrieves the chunks among the IDs that were valid at the given time
+func (r *chunkRepository) ListChunksByIDAsOf(
+	ctx context.Context, tenantID uint64, ids []string, asOf time.Time,
+) ([]*types.Chunk, error) {
+	var chunks []*types.Chunk
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("tenant_id = ? AND id IN ?", tenantID, ids).
+		Scopes(validAt(asOf)).
+		Find(&chunks).Error; err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// ListChunksByKnowledgeIDAsOf lists the chunks of all types of a knowledge valid at the given time
+func (r *chunkRepository) ListChunksByKnowledgeIDAsOf(
+	ctx context.Context, tenantID uint64, knowledgeID string, asOf *time.Time,
+) ([]*types.Chunk, error) {
+	db := r.db.WithContext(ctx)
+	if asOf != nil {
+		db = db.Unscoped().Scopes(validAt(*asOf))
+	}
+	var chunks []*types.Chunk
+	if err := db.
+		Where("tenant_id = ? AND knowledge_id = ?", tenantID, knowledgeID).
+		Order("chunk_index ASC").
+		Find(&chunks).Error; err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// ListDeletedChunksByKnowledgeID lists the soft deleted chunks of the given types of a knowledge
+func (r *chunkRepository) ListDeletedChunksByKnowledgeID(
+	ctx context.Context, tenantID uint64, knowledgeID string, chunkType []types.ChunkType,
+) ([]*types.Chunk, error) {
+	var chunks []*types.Chunk
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("tenant_id = ? AND knowledge_id = ? AND chunk_type IN (?) AND deleted_at IS NOT NULL",
+			tenantID, knowledgeID, chunkType).
+		Order("deleted_at ASC, chunk_index ASC").
+		Find(&chunks).Error; err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// validAt limits a query to chunks created at or before the time and not deleted by then
+func validAt(asOf time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("created_at <= ? AND (deleted_at IS NULL OR deleted_at > ?)", asOf, asOf)
+	}
+}
+
 // ListChunksByKnowledgeID lists all chunks for a knowledge ID
 func (r *chunkRepository) ListChunksByKnowledgeID(
 	ctx context.Context, tenantID uint64, knowledgeID string,
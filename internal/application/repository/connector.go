@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+// ErrConnectorNotFound is returned when a knowledge connector is not found
+var ErrConnectorNotFound = errors.New("knowledge connector not found")
+
+// connectorRepository implements the ConnectorRepository interface
+type connectorRepository struct {
+	db *gorm.DB
+}
+
+// NewConnectorRepository creates a new knowledge connector repository
+func NewConnectorRepository(db *gorm.DB) interfaces.ConnectorRepository {
+	return &connectorRepository{db: db}
+}
+
+// CreateConnector creates a connector
+func (r *connectorRepository) CreateConnector(ctx context.Context, connector *types.KnowledgeConnector) error {
+	return r.db.WithContext(ctx).Create(connector).Error
+}
+
+// GetConnectorByID gets a connector by ID
+func (r *connectorRepository) GetConnectorByID(
+	ctx context.Context, tenantID uint64, id string,
+) (*types.KnowledgeConnector, error) {
+	var connector types.KnowledgeConnector
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&connector).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConnectorNotFound
+		}
+		return nil, err
+	}
+	return &connector, nil
+}
+
+// ListConnectorsByKnowledgeBaseID lists the connectors of a knowledge base
+func (r *connectorRepository) ListConnectorsByKnowledgeBaseID(
+	ctx context.Context, tenantID uint64, kbID string,
+) ([]*types.KnowledgeConnector, error) {
+	var connectors []*types.KnowledgeConnector
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND knowledge_base_id = ?", tenantID, kbID).
+		Order("created_at DESC").
+		Find(&connectors).Error; err != nil {
+		return nil, err
+	}
+	return connectors, nil
+}
+
+// ListScheduledConnectors lists the enabled connectors of all tenants with a sync interval
+func (r *connectorRepository) ListScheduledConnectors(ctx context.Context) ([]*types.KnowledgeConnector, error) {
+	var connectors []*types.KnowledgeConnector
+	if err := r.db.WithContext(ctx).
+		Where("enabled = ? AND sync_interval_minutes > 0", true).
+		Find(&connectors).Error; err != nil {
+		return nil, err
+	}
+	return connectors, nil
+}
+
+// UpdateConnector updates the name, configuration, schedule and cursor of a connector,
+// the sync state written by a running sync is left untouched
+func (r *connectorRepository) UpdateConnector(ctx context.Context, connector *types.KnowledgeConnector) error {
+	return r.db.WithContext(ctx).Model(connector).
+		Select("name", "config", "enabled", "sync_interval_minutes", "cursor", "updated_at").
+		Updates(connector).Error
+}
+
+// UpdateSyncState updates the sync state of a connector
+func (r *connectorRepository) UpdateSyncState(ctx context.Context, connector *types.KnowledgeConnector) error {
+	return r.db.WithContext(ctx).Model(connector).
+		Select("sync_status", "cursor", "last_error", "last_sync_stats", "item_count", "last_sync_at", "last_success_at").
+		Updates(connector).Error
+}
+
+// UpdateSyncStatus updates the sync status of a connector
+func (r *connectorRepository) UpdateSyncStatus(
+	ctx context.Context, tenantID uint64, id string, status types.ConnectorSyncStatus,
+) error {
+	return r.db.WithContext(ctx).Model(&types.KnowledgeConnector{}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		Update("sync_status", status).Error
+}
+
+// DeleteConnector deletes a connector and its items
+func (r *connectorRepository) DeleteConnector(ctx context.Context, tenantID uint64, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ? AND connector_id = ?", tenantID, id).
+			Delete(&types.ConnectorItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("tenant_id = ? AND id = ?", tenantID, id).
+			Delete(&types.KnowledgeConnector{}).Error
+	})
+}
+
+// ListItems lists the synced items of a connector
+func (r *connectorRepository) ListItems(
+	ctx context.Context, tenantID uint64, connectorID string,
+) ([]*types.ConnectorItem, error) {
+	var items []*types.ConnectorItem
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND connector_id = ?", tenantID, connectorID).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SaveItem creates or updates a synced item
+func (r *connectorRepository) SaveItem(ctx context.Context, item *types.ConnectorItem) error {
+	if item.ID == "" {
+		return r.db.WithContext(ctx).Create(item).Error
+	}
+	return r.db.WithContext(ctx).Save(item).Error
+}
+
+// DeleteItem deletes a synced item
+func (r *connectorRepository) DeleteItem(ctx context.Context, tenantID uint64, id string) error {
+	return r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		Delete(&types.ConnectorItem{}).Error
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrEvaluationDatasetNotFound is returned when an evaluation dataset is not found
+	ErrEvaluationDatasetNotFound = errors.New("evaluation dataset not found")
+	// ErrEvaluationRunNotFound is returned when an evaluation run is not found
+	ErrEvaluationRunNotFound = errors.New("evaluation run not found")
+)
+
+// evaluationRepository implements the EvaluationRepository interface
+type evaluationRepository struct {
+	db *gorm.DB
+}
+
+// NewEvaluationRepository creates a new evaluation repository
+func NewEvaluationRepository(db *gorm.DB) interfaces.EvaluationRepository {
+	return &evaluationRepository{db: db}
+}
+
+// CreateDataset creates a dataset with its questions
+func (r *evaluationRepository) CreateDataset(ctx context.Context,
+	dataset *types.EvaluationDataset, items []*types.EvaluationDatasetItem,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(dataset).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			item.DatasetID = dataset.ID
+			item.TenantID = dataset.TenantID
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(items, 500).Error
+	})
+}
+
+// GetDataset gets a dataset of a tenant by ID
+func (r *evaluationRepository) GetDataset(ctx context.Context,
+	tenantID uint64, id string,
+) (*types.EvaluationDataset, error) {
+	var dataset types.EvaluationDataset
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&dataset).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEvaluationDatasetNotFound
+		}
+		return nil, err
+	}
+	return &dataset, nil
+}
+
+// ListDatasets lists the datasets of a tenant, newest first
+func (r *evaluationRepository) ListDatasets(ctx context.Context,
+	tenantID uint64, page *types.Pagination,
+) ([]*types.EvaluationDataset, int64, error) {
+	query := r.db.WithContext(ctx).Model(&types.EvaluationDataset{}).Where("tenant_id = ?", tenantID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var datasets []*types.EvaluationDataset
+	if err := query.Order("created_at DESC").
+		Offset(page.Offset()).Limit(page.Limit()).
+		Find(&datasets).Error; err != nil {
+		return nil, 0, err
+	}
+	return datasets, total, nil
+}
+
+// ListDatasetItems lists the questions of a dataset in order
+func (r *evaluationRepository) ListDatasetItems(ctx context.Context,
+	datasetID string, page *types.Pagination,
+) ([]*types.EvaluationDatasetItem, int64, error) {
+	query := r.db.WithContext(ctx).Model(&types.EvaluationDatasetItem{}).Where("dataset_id = ?", datasetID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []*types.EvaluationDatasetItem
+	if err := query.Order("seq").
+		Offset(page.Offset()).Limit(page.Limit()).
+		Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// GetDatasetItems gets all questions of a dataset in order
+func (r *evaluationRepository) GetDatasetItems(ctx context.Context,
+	datasetID string,
+) ([]*types.EvaluationDatasetItem, error) {
+	var items []*types.EvaluationDatasetItem
+	if err := r.db.WithContext(ctx).
+		Where("dataset_id = ?", datasetID).
+		Order("seq").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// DeleteDataset deletes a dataset and its questions
+func (r *evaluationRepository) DeleteDataset(ctx context.Context, tenantID uint64, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&types.EvaluationDataset{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrEvaluationDatasetNotFound
+		}
+		return tx.Where("tenant_id = ? AND dataset_id = ?", tenantID, id).
+			Delete(&types.EvaluationDatasetItem{}).Error
+	})
+}
+
+// CreateRun creates an evaluation run
+func (r *evaluationRepository) CreateRun(ctx context.Context, run *types.EvaluationRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// GetRun gets an evaluation run of a tenant by ID
+func (r *evaluationRepository) GetRun(ctx context.Context, tenantID uint64, id string) (*types.EvaluationRun, error) {
+	var run types.EvaluationRun
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEvaluationRunNotFound
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListRuns lists the evaluation runs of a tenant matching the filter, newest first
+func (r *evaluationRepository) ListRuns(ctx context.Context, tenantID uint64,
+	filter *types.EvaluationRunFilter, page *types.Pagination,
+) ([]*types.EvaluationRun, int64, error) {
+	query := r.db.WithContext(ctx).Model(&types.EvaluationRun{}).Where("tenant_id = ?", tenantID)
+	if filter != nil {
+		if filter.DatasetID != "" {
+			query = query.Where("dataset_id = ?", filter.DatasetID)
+		}
+		if filter.AgentID != "" {
+			query = query.Where("agent_id = ?", filter.AgentID)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var runs []*types.EvaluationRun
+	if err := query.Order("created_at DESC").
+		Offset(page.Offset()).Limit(page.Limit()).
+		Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// UpdateRun updates the status, progress and metrics of an evaluation run
+func (r *evaluationRepository) UpdateRun(ctx context.Context, run *types.EvaluationRun) error {
+	return r.db.WithContext(ctx).Model(run).
+		Select("status", "err_msg", "total", "finished", "metric", "started_at", "finished_at", "updated_at").
+		Updates(run).Error
+}
+
+// DeleteRun deletes an evaluation run and its results
+func (r *evaluationRepository) DeleteRun(ctx context.Context, tenantID uint64, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&types.EvaluationRun{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrEvaluationRunNotFound
+		}
+		return tx.Where("tenant_id = ? AND run_id = ?", tenantID, id).Delete(&types.EvaluationRunItem{}).Error
+	})
+}
+
+// CreateRunItem creates the result of a question of an evaluation run
+func (r *evaluationRepository) CreateRunItem(ctx context.Context, item *types.EvaluationRunItem) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+// ListRunItems lists the results of an evaluation run in dataset order
+func (r *evaluationRepository) ListRunItems(ctx context.Context,
+	runID string, page *types.Pagination,
+) ([]*types.EvaluationRunItem, int64, error) {
+	query := r.db.WithContext(ctx).Model(&types.EvaluationRunItem{}).Where("run_id = ?", runID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []*types.EvaluationRunItem
+	if err := query.Order("seq").
+		Offset(page.Offset()).Limit(page.Limit()).
+		Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// GetRunItems gets all results of an evaluation run in dataset order
+func (r *evaluationRepository) GetRunItems(ctx context.Context, runID string) ([]*types.EvaluationRunItem, error) {
+	var items []*types.EvaluationRunItem
+	if err := r.db.WithContext(ctx).
+		Where("run_id = ?", runID).
+		Order("seq").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+// feedbackRepository implements the FeedbackRepository interface
+type feedbackRepository struct {
+	db *gorm.DB
+}
+
+// NewFeedbackRepository creates a new feedback repository
+func NewFeedbackRepository(db *gorm.DB) interfaces.FeedbackRepository {
+	return &feedbackRepository{db: db}
+}
+
+// SetMessageFeedback sets or, when feedback is nil, clears the feedback of a message.
+// The feedback time is kept when clearing so that the removal is aggregated too.
+func (r *feedbackRepository) SetMessageFeedback(ctx context.Context,
+	sessionID, messageID string, feedback *types.MessageFeedback, at time.Time,
+) error {
+	return r.db.WithContext(ctx).Model(&types.Message{}).
+		Where("id = ? AND session_id = ?", messageID, sessionID).
+		Updates(map[string]interface{}{
+			"feedback":    feedback,
+			"feedback_at": at,
+		}).Error
+}
+
+// ListFeedback lists the messages of a tenant with feedback matching the filter, newest first.
+// The question is the user message of the same request.
+func (r *feedbackRepository) ListFeedback(ctx context.Context, tenantID uint64,
+	filter *types.FeedbackFilter, page *types.Pagination,
+) ([]*types.FeedbackRecord, int64, error) {
+	query := r.db.WithContext(ctx).Table("messages AS m").
+		Joins("JOIN sessions AS s ON s.id = m.session_id AND s.deleted_at IS NULL").
+		Where("s.tenant_id = ? AND m.feedback IS NOT NULL AND m.deleted_at IS NULL", tenantID)
+	if filter != nil {
+		if filter.Rating != "" {
+			query = query.Where("m.feedback->>'rating' = ?", filter.Rating)
+		}
+		if filter.Reason != "" {
+			query = query.Where("m.feedback->>'reason' = ?", filter.Reason)
+		}
+		if filter.SessionID != "" {
+			query = query.Where("m.session_id = ?", filter.SessionID)
+		}
+		if filter.From != nil {
+			query = query.Where("m.feedback_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			query = query.Where("m.feedback_at < ?", *filter.To)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var records []*types.FeedbackRecord
+	if err := query.
+		Joins("LEFT JOIN messages AS q ON q.session_id = m.session_id AND q.request_id = m.request_id " +
+			"AND q.role = 'user' AND q.deleted_at IS NULL").
+		Select("m.id AS message_id, m.session_id, m.request_id, COALESCE(q.content, '') AS question, " +
+			"m.content AS answer, m.knowledge_references, m.feedback, m.feedback_at").
+		Order("m.feedback_at DESC, m.id").
+		Offset(page.Offset()).Limit(page.Limit()).
+		Scan(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	return records, total, nil
+}
+
+// ReplaceChunkSignals replaces all chunk relevance signals of a tenant
+func (r *feedbackRepository) ReplaceChunkSignals(ctx context.Context,
+	tenantID uint64, signals []*types.ChunkFeedbackSignal,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ?", tenantID).Delete(&types.ChunkFeedbackSignal{}).Error; err != nil {
+			return err
+		}
+		if len(signals) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(signals, 500).Error
+	})
+}
+
+// GetChunkSignals gets the relevance signals of the given chunks of a tenant
+func (r *feedbackRepository) GetChunkSignals(ctx context.Context,
+	tenantID uint64, chunkIDs []string,
+) ([]*types.ChunkFeedbackSignal, error) {
+	var signals []*types.ChunkFeedbackSignal
+	if len(chunkIDs) == 0 {
+		return signals, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND chunk_id IN ?", tenantID, chunkIDs).
+		Find(&signals).Error; err != nil {
+		return nil, err
+	}
+	return signals, nil
+}
+
+// ListChunkSignals lists the chunk relevance signals of a tenant, strongest first
+func (r *feedbackRepository) ListChunkSignals(ctx context.Context,
+	tenantID uint64, page *types.Pagination,
+) ([]*types.ChunkFeedbackSignal, int64, error) {
+	query := r.db.WithContext(ctx).Model(&types.ChunkFeedbackSignal{}).Where("tenant_id = ?", tenantID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var signals []*types.ChunkFeedbackSignal
+	if err := query.Order("ABS(score) DESC, chunk_id").
+		Offset(page.Offset()).Limit(page.Limit()).
+		Find(&signals).Error; err != nil {
+		return nil, 0, err
+	}
+	return signals, total, nil
+}
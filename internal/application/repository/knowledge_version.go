@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// knowledgeVersionRepository implements the KnowledgeVersionRepository interface
+type knowledgeVersionRepository struct {
+	db *gorm.DB
+}
+
+// NewKnowledgeVersionRepository creates a new knowledge version repository
+func NewKnowledgeVersionRepository(db *gorm.DB) interfaces.KnowledgeVersionRepository {
+	return &knowledgeVersionRepository{db: db}
+}
+
+// CreateVersion creates a version with the next version number of the knowledge and marks the previous
+// current version as superseded in one transaction. The knowledge row is locked while the number is allocated,
+// so concurrent versions of the same knowledge are numbered one after another.
+func (r *knowledgeVersionRepository) CreateVersion(ctx context.Context, version *types.KnowledgeVersion) error {
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now()
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var knowledge types.Knowledge
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			Where("tenant_id = ? AND id = ?", version.TenantID, version.KnowledgeID).
+			First(&knowledge).Error; err != nil {
+			return err
+		}
+		var latest int
+		if err := tx.Model(&types.KnowledgeVersion{}).
+			Where("tenant_id = ? AND knowledge_id = ?", version.TenantID, version.KnowledgeID).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		version.Version = latest + 1
+
+		if err := tx.Model(&types.KnowledgeVersion{}).
+			Where("tenant_id = ? AND knowledge_id = ? AND superseded_at IS NULL", version.TenantID, version.KnowledgeID).
+			Update("superseded_at", version.CreatedAt).Error; err != nil {
+			return err
+		}
+		return tx.Create(version).Error
+	})
+}
+
+// UpdateVersion updates a version
+func (r *knowledgeVersionRepository) UpdateVersion(ctx context.Context, version *types.KnowledgeVersion) error {
+	return r.db.WithContext(ctx).Save(version).Error
+}
+
+// GetVersion gets a version of a knowledge by version number
+func (r *knowledgeVersionRepository) GetVersion(
+	ctx context.Context, tenantID uint64, knowledgeID string, version int,
+) (*types.KnowledgeVersion, error) {
+	var v types.KnowledgeVersion
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND knowledge_id = ? AND version = ?", tenantID, knowledgeID, version).
+		First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// GetLatestVersion gets the latest version of a knowledge, nil if the knowledge has no version
+func (r *knowledgeVersionRepository) GetLatestVersion(
+	ctx context.Context, tenantID uint64, knowledgeID string,
+) (*types.KnowledgeVersion, error) {
+	var v types.KnowledgeVersion
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND knowledge_id = ?", tenantID, knowledgeID).
+		Order("version DESC").
+		First(&v).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ListVersions lists the versions of a knowledge, newest first
+func (r *knowledgeVersionRepository) ListVersions(
+	ctx context.Context, tenantID uint64, knowledgeID string,
+) ([]*types.KnowledgeVersion, error) {
+	var versions []*types.KnowledgeVersion
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND knowledge_id = ?", tenantID, knowledgeID).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// IsFileReferenced reports whether a version of a knowledge references the file path
+func (r *knowledgeVersionRepository) IsFileReferenced(
+	ctx context.Context, tenantID uint64, knowledgeID string, filePath string,
+) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&types.KnowledgeVersion{}).
+		Where("tenant_id = ? AND knowledge_id = ? AND file_path = ?", tenantID, knowledgeID, filePath).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteVersionsByKnowledgeIDs deletes the versions of the knowledge items and returns them,
+// so the caller can remove the files they reference
+func (r *knowledgeVersionRepository) DeleteVersionsByKnowledgeIDs(
+	ctx context.Context, tenantID uint64, knowledgeIDs []string,
+) ([]*types.KnowledgeVersion, error) {
+	if len(knowledgeIDs) == 0 {
+		return nil, nil
+	}
+	var versions []*types.KnowledgeVersion
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ? AND knowledge_id IN ?", tenantID, knowledgeIDs).
+			Find(&versions).Error; err != nil {
+			return err
+		}
+		return tx.Where("tenant_id = ? AND knowledge_id IN ?", tenantID, knowledgeIDs).
+			Delete(&types.KnowledgeVersion{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
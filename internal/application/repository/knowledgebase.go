@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/Tencent/WeKnora/internal/types"
 	"github.com/Tencent/WeKnora/internal/types/interfaces"
@@ -76,6 +77,26 @@ func (r *knowledgeBaseRepository) UpdateKnowledgeBase(ctx context.Context, kb *t
 	return r.db.WithContext(ctx).Save(kb).Error
 }
 
+// SwitchEmbeddingModel sets the embedding model of a knowledge base and all of its knowledge
+func (r *knowledgeBaseRepository) SwitchEmbeddingModel(
+	ctx context.Context, tenantID uint64, id string, modelID string,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&types.KnowledgeBase{}).
+			Where("id = ? AND tenant_id = ?", id, tenantID).
+			Updates(map[string]interface{}{"embedding_model_id": modelID, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrKnowledgeBaseNotFound
+		}
+		return tx.Model(&types.Knowledge{}).
+			Where("tenant_id = ? AND knowledge_base_id = ?", tenantID, id).
+			Update("embedding_model_id", modelID).Error
+	})
+}
+
 // DeleteKnowledgeBase deletes a knowledge base
 func (r *knowledgeBaseRepository) DeleteKnowledgeBase(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&types.KnowledgeBase{}).Error
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+// ErrMemoryNotFound is returned when a user memory is not found
+var ErrMemoryNotFound = errors.New("memory not found")
+
+// memoryRepository implements the MemoryRepository interface
+type memoryRepository struct {
+	db *gorm.DB
+}
+
+// NewMemoryRepository creates a new user memory repository
+func NewMemoryRepository(db *gorm.DB) interfaces.MemoryRepository {
+	return &memoryRepository{db: db}
+}
+
+// Create creates a memory
+func (r *memoryRepository) Create(ctx context.Context, memory *types.UserMemory) error {
+	return r.db.WithContext(ctx).Create(memory).Error
+}
+
+// Get gets a memory of a user
+func (r *memoryRepository) Get(ctx context.Context,
+	tenantID uint64, userID, id string,
+) (*types.UserMemory, error) {
+	var memory types.UserMemory
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ? AND id = ?", tenantID, userID, id).
+		First(&memory).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemoryNotFound
+		}
+		return nil, err
+	}
+	return &memory, nil
+}
+
+// List lists the memories of a user matching the filter, most recently updated first
+func (r *memoryRepository) List(ctx context.Context, tenantID uint64, userID string,
+	filter *types.UserMemoryFilter, page *types.Pagination,
+) ([]*types.UserMemory, int64, error) {
+	query := r.db.WithContext(ctx).Model(&types.UserMemory{}).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID)
+	if filter != nil {
+		if filter.Category != "" {
+			query = query.Where("category = ?", filter.Category)
+		}
+		if filter.Keyword != "" {
+			query = query.Where("content LIKE ?", "%"+filter.Keyword+"%")
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var memories []*types.UserMemory
+	if err := query.
+		Order("updated_at DESC, id").
+		Offset(page.Offset()).Limit(page.Limit()).
+		Find(&memories).Error; err != nil {
+		return nil, 0, err
+	}
+	return memories, total, nil
+}
+
+// ListAll lists every memory of a user, most recently updated first
+func (r *memoryRepository) ListAll(ctx context.Context,
+	tenantID uint64, userID string,
+) ([]*types.UserMemory, error) {
+	var memories []*types.UserMemory
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Order("updated_at DESC, id").
+		Find(&memories).Error; err != nil {
+		return nil, err
+	}
+	return memories, nil
+}
+
+// Update updates the content and embedding of a memory
+func (r *memoryRepository) Update(ctx context.Context, memory *types.UserMemory) error {
+	return r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ?", memory.TenantID, memory.UserID).
+		Select("category", "content", "embedding", "embedding_model_id",
+			"source_session_id", "source_message_id", "updated_at").
+		Updates(memory).Error
+}
+
+// Delete deletes a memory of a user
+func (r *memoryRepository) Delete(ctx context.Context, tenantID uint64, userID, id string) error {
+	result := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ? AND id = ?", tenantID, userID, id).
+		Delete(&types.UserMemory{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMemoryNotFound
+	}
+	return nil
+}
+
+// DeleteAll deletes every memory of a user and returns how many were deleted
+func (r *memoryRepository) DeleteAll(ctx context.Context, tenantID uint64, userID string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Delete(&types.UserMemory{})
+	return result.RowsAffected, result.Error
+}
+
+// Prune deletes the least recently updated memories of a user beyond the given number
+func (r *memoryRepository) Prune(ctx context.Context, tenantID uint64, userID string, keep int) (int64, error) {
+	kept := r.db.Model(&types.UserMemory{}).Select("id").
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Order("updated_at DESC, id").Limit(keep)
+	result := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ? AND id NOT IN (?)", tenantID, userID, kept).
+		Delete(&types.UserMemory{})
+	return result.RowsAffected, result.Error
+}
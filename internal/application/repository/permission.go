@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrGrantNotFound is returned when a resource grant is not found
+	ErrGrantNotFound = errors.New("resource grant not found")
+	// ErrGroupNotFound is returned when a user group is not found
+	ErrGroupNotFound = errors.New("user group not found")
+)
+
+// permissionRepository implements the PermissionRepository interface
+type permissionRepository struct {
+	db *gorm.DB
+}
+
+// NewPermissionRepository creates a new grant and user group repository
+func NewPermissionRepository(db *gorm.DB) interfaces.PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+// ListGrants lists the grants of the given resources
+func (r *permissionRepository) ListGrants(
+	ctx context.Context, tenantID uint64, resourceType types.ResourceType, resourceIDs []string,
+) ([]*types.ResourceGrant, error) {
+	var grants []*types.ResourceGrant
+	if len(resourceIDs) == 0 {
+		return grants, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND resource_type = ? AND resource_id IN ?", tenantID, resourceType, resourceIDs).
+		Order("created_at ASC").
+		Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// GetGrantByID gets a grant by ID
+func (r *permissionRepository) GetGrantByID(
+	ctx context.Context, tenantID uint64, id string,
+) (*types.ResourceGrant, error) {
+	var grant types.ResourceGrant
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&grant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGrantNotFound
+		}
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// GetGrantBySubject gets the grant of a resource to a subject
+func (r *permissionRepository) GetGrantBySubject(ctx context.Context,
+	tenantID uint64, resourceType types.ResourceType, resourceID string,
+	subjectType types.GrantSubjectType, subjectID string,
+) (*types.ResourceGrant, error) {
+	var grant types.ResourceGrant
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND resource_type = ? AND resource_id = ? AND subject_type = ? AND subject_id = ?",
+			tenantID, resourceType, resourceID, subjectType, subjectID).
+		First(&grant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGrantNotFound
+		}
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// SaveGrant creates a grant, or updates the permission of the existing grant of the resource to the same subject
+func (r *permissionRepository) SaveGrant(ctx context.Context, grant *types.ResourceGrant) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "tenant_id"}, {Name: "resource_type"}, {Name: "resource_id"},
+			{Name: "subject_type"}, {Name: "subject_id"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"permission", "created_by", "updated_at"}),
+	}).Create(grant).Error
+}
+
+// DeleteGrant deletes a grant
+func (r *permissionRepository) DeleteGrant(ctx context.Context, tenantID uint64, id string) error {
+	return r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		Delete(&types.ResourceGrant{}).Error
+}
+
+// DeleteResourceGrants deletes the grants of a resource
+func (r *permissionRepository) DeleteResourceGrants(
+	ctx context.Context, tenantID uint64, resourceType types.ResourceType, resourceID string,
+) error {
+	return r.db.WithContext(ctx).
+		Where("tenant_id = ? AND resource_type = ? AND resource_id = ?", tenantID, resourceType, resourceID).
+		Delete(&types.ResourceGrant{}).Error
+}
+
+// DeleteSubjectGrants deletes the grants to a subject
+func (r *permissionRepository) DeleteSubjectGrants(
+	ctx context.Context, tenantID uint64, subjectType types.GrantSubjectType, subjectID string,
+) error {
+	return r.db.WithContext(ctx).
+		Where("tenant_id = ? AND subject_type = ? AND subject_id = ?", tenantID, subjectType, subjectID).
+		Delete(&types.ResourceGrant{}).Error
+}
+
+// CreateGroup creates a user group
+func (r *permissionRepository) CreateGroup(ctx context.Context, group *types.UserGroup) error {
+	return r.db.WithContext(ctx).Create(group).Error
+}
+
+// GetGroupByID gets a user group by ID
+func (r *permissionRepository) GetGroupByID(ctx context.Context, tenantID uint64, id string) (*types.UserGroup, error) {
+	var group types.UserGroup
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// ListGroups lists the user groups of a tenant
+func (r *permissionRepository) ListGroups(ctx context.Context, tenantID uint64) ([]*types.UserGroup, error) {
+	var groups []*types.UserGroup
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at ASC").
+		Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// UpdateGroup updates the name and description of a user group
+func (r *permissionRepository) UpdateGroup(ctx context.Context, group *types.UserGroup) error {
+	return r.db.WithContext(ctx).Model(group).
+		Select("name", "description", "updated_at").
+		Updates(group).Error
+}
+
+// DeleteGroup deletes a user group with its members and grants
+func (r *permissionRepository) DeleteGroup(ctx context.Context, tenantID uint64, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ? AND group_id = ?", tenantID, id).
+			Delete(&types.UserGroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tenant_id = ? AND subject_type = ? AND subject_id = ?",
+			tenantID, types.GrantSubjectGroup, id).
+			Delete(&types.ResourceGrant{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&types.UserGroup{}).Error
+	})
+}
+
+// ListGroupMemberIDs lists the user IDs of the members of a user group
+func (r *permissionRepository) ListGroupMemberIDs(
+	ctx context.Context, tenantID uint64, groupID string,
+) ([]string, error) {
+	var userIDs []string
+	if err := r.db.WithContext(ctx).Model(&types.UserGroupMember{}).
+		Where("tenant_id = ? AND group_id = ?", tenantID, groupID).
+		Order("created_at ASC").
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// AddGroupMember adds a user to a user group, adding an existing member is a no-op
+func (r *permissionRepository) AddGroupMember(ctx context.Context, member *types.UserGroupMember) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(member).Error
+}
+
+// RemoveGroupMember removes a user from a user group
+func (r *permissionRepository) RemoveGroupMember(
+	ctx context.Context, tenantID uint64, groupID string, userID string,
+) error {
+	return r.db.WithContext(ctx).
+		Where("tenant_id = ? AND group_id = ? AND user_id = ?", tenantID, groupID, userID).
+		Delete(&types.UserGroupMember{}).Error
+}
+
+// RemoveUserFromGroups removes a user from all user groups of a tenant
+func (r *permissionRepository) RemoveUserFromGroups(ctx context.Context, tenantID uint64, userID string) error {
+	return r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Delete(&types.UserGroupMember{}).Error
+}
+
+// ListUserGroupIDs lists the IDs of the user groups a user is a member of
+func (r *permissionRepository) ListUserGroupIDs(
+	ctx context.Context, tenantID uint64, userID string,
+) ([]string, error) {
+	var groupIDs []string
+	if err := r.db.WithContext(ctx).Model(&types.UserGroupMember{}).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Pluck("group_id", &groupIDs).Error; err != nil {
+		return nil, err
+	}
+	return groupIDs, nil
+}
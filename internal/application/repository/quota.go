@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrQuotaPolicyNotFound is returned when a subject has no quota policy
+var ErrQuotaPolicyNotFound = errors.New("quota policy not found")
+
+// quotaRepository implements the QuotaRepository interface
+type quotaRepository struct {
+	db *gorm.DB
+}
+
+// NewQuotaRepository creates a new quota policy repository
+func NewQuotaRepository(db *gorm.DB) interfaces.QuotaRepository {
+	return &quotaRepository{db: db}
+}
+
+// GetQuotaPolicy gets the policy of a subject
+func (r *quotaRepository) GetQuotaPolicy(ctx context.Context,
+	tenantID uint64, subjectType types.QuotaSubjectType, subjectID string,
+) (*types.QuotaPolicy, error) {
+	var policy types.QuotaPolicy
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND subject_type = ? AND subject_id = ?", tenantID, subjectType, subjectID).
+		First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuotaPolicyNotFound
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ListQuotaPolicies lists the policies of a tenant
+func (r *quotaRepository) ListQuotaPolicies(ctx context.Context, tenantID uint64) ([]*types.QuotaPolicy, error) {
+	var policies []*types.QuotaPolicy
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("subject_type, subject_id").
+		Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// SaveQuotaPolicy creates the policy of a subject, or replaces the limits of its existing policy
+func (r *quotaRepository) SaveQuotaPolicy(ctx context.Context, policy *types.QuotaPolicy) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}, {Name: "subject_type"}, {Name: "subject_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"requests_per_minute", "concurrent_streams", "daily_tokens", "daily_ingestion_bytes", "updated_at",
+		}),
+	}).Create(policy).Error
+}
+
+// DeleteQuotaPolicy deletes the policy of a subject
+func (r *quotaRepository) DeleteQuotaPolicy(ctx context.Context,
+	tenantID uint64, subjectType types.QuotaSubjectType, subjectID string,
+) error {
+	return r.db.WithContext(ctx).
+		Where("tenant_id = ? AND subject_type = ? AND subject_id = ?", tenantID, subjectType, subjectID).
+		Delete(&types.QuotaPolicy{}).Error
+}
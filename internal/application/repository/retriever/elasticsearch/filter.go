@@ -0,0 +1,104 @@
+package elasticsearch
+
+import (
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// FilterQuery translates a metadata filter into an Elasticsearch query on the attributes
+// denormalized into the documents. String values are matched on the keyword sub field
+// created by dynamic mapping, numbers, booleans and dates on the field itself.
+func FilterQuery(filter *types.MetadataFilter) map[string]interface{} {
+	switch filter.Op {
+	case types.MetadataFilterOpAnd:
+		return boolQuery("must", subQueries(filter))
+	case types.MetadataFilterOpOr:
+		return boolQuery("should", subQueries(filter))
+	case types.MetadataFilterOpNot:
+		return boolQuery("must_not", subQueries(filter))
+	}
+
+	field := filterDocumentField(filter)
+	switch filter.Op {
+	case types.MetadataFilterOpEq:
+		return termsQuery(field, []any{filter.Value})
+	case types.MetadataFilterOpIn:
+		return termsQuery(field, filter.Values)
+	case types.MetadataFilterOpExists:
+		return map[string]interface{}{"exists": map[string]interface{}{"field": field}}
+	case types.MetadataFilterOpRange:
+		bounds := make(map[string]interface{})
+		for _, bound := range filter.Bounds() {
+			if bound.IsTime {
+				bounds[bound.Op] = bound.Time.UTC().Format(time.RFC3339)
+			} else {
+				bounds[bound.Op] = bound.Number
+			}
+		}
+		return map[string]interface{}{"range": map[string]interface{}{field: bounds}}
+	default:
+		return boolQuery("should", nil)
+	}
+}
+
+func subQueries(filter *types.MetadataFilter) []map[string]interface{} {
+	queries := make([]map[string]interface{}, 0, len(filter.Filters))
+	for _, sub := range filter.Filters {
+		queries = append(queries, FilterQuery(sub))
+	}
+	return queries
+}
+
+// boolQuery wraps queries in a bool clause, a should clause requires at least one match
+func boolQuery(occur string, queries []map[string]interface{}) map[string]interface{} {
+	if queries == nil {
+		queries = []map[string]interface{}{}
+	}
+	query := map[string]interface{}{occur: queries}
+	if occur == "should" {
+		query["minimum_should_match"] = 1
+	}
+	return map[string]interface{}{"bool": query}
+}
+
+func termsQuery(field string, values []any) map[string]interface{} {
+	keywords := make([]any, 0, len(values))
+	others := make([]any, 0, len(values))
+	for _, value := range values {
+		if _, ok := value.(string); ok {
+			keywords = append(keywords, value)
+		} else {
+			others = append(others, value)
+		}
+	}
+	queries := make([]map[string]interface{}, 0, 2)
+	if len(keywords) > 0 {
+		queries = append(queries, map[string]interface{}{
+			"terms": map[string]interface{}{field + ".keyword": keywords},
+		})
+	}
+	if len(others) > 0 {
+		queries = append(queries, map[string]interface{}{
+			"terms": map[string]interface{}{field: others},
+		})
+	}
+	if len(queries) == 1 {
+		return queries[0]
+	}
+	return boolQuery("should", queries)
+}
+
+func filterDocumentField(filter *types.MetadataFilter) string {
+	target, key := filter.Target()
+	switch target {
+	case types.MetadataFilterTargetFileType:
+		return "file_type"
+	case types.MetadataFilterTargetCreatedAt:
+		return "knowledge_created_at"
+	case types.MetadataFilterTargetKnowledgeMetadata:
+		return "knowledge_metadata." + key
+	default:
+		return "chunk_metadata." + key
+	}
+}
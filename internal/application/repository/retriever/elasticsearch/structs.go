@@ -3,6 +3,7 @@ package elasticsearch
 import (
 	"maps"
 	"slices"
+	"time"
 
 	"github.com/Tencent/WeKnora/internal/types"
 )
@@ -17,6 +18,38 @@ type VectorEmbedding struct {
 	KnowledgeBaseID string    `json:"knowledge_base_id" gorm:"column:knowledge_base_id"`    // ID of the knowledge base
 	Embedding       []float32 `json:"embedding"         gorm:"column:embedding;not null"`   // Vector embedding of the content
 	IsEnabled       bool      `json:"is_enabled"`                                           // Whether the chunk is enabled
+	// Attributes denormalized for metadata filtering
+	FileType           string         `json:"file_type,omitempty"`            // File type of the knowledge
+	KnowledgeCreatedAt *time.Time     `json:"knowledge_created_at,omitempty"` // Creation time of the knowledge
+	KnowledgeMetadata  map[string]any `json:"knowledge_metadata,omitempty"`   // Filterable knowledge metadata
+	ChunkMetadata      map[string]any `json:"chunk_metadata,omitempty"`       // Filterable chunk metadata
+}
+
+// SetAttributes stores the index attributes used for metadata filtering
+func (v *VectorEmbedding) SetAttributes(attrs *types.IndexAttributes) {
+	if attrs == nil {
+		return
+	}
+	v.FileType = attrs.FileType
+	if !attrs.CreatedAt.IsZero() {
+		createdAt := attrs.CreatedAt.UTC()
+		v.KnowledgeCreatedAt = &createdAt
+	}
+	v.KnowledgeMetadata = attrs.KnowledgeMetadata
+	v.ChunkMetadata = attrs.ChunkMetadata
+}
+
+// Attributes returns the index attributes stored in the document
+func (v *VectorEmbedding) Attributes() *types.IndexAttributes {
+	attrs := &types.IndexAttributes{
+		FileType:          v.FileType,
+		KnowledgeMetadata: v.KnowledgeMetadata,
+		ChunkMetadata:     v.ChunkMetadata,
+	}
+	if v.KnowledgeCreatedAt != nil {
+		attrs.CreatedAt = *v.KnowledgeCreatedAt
+	}
+	return attrs
 }
 
 // VectorEmbeddingWithScore extends VectorEmbedding with similarity score
@@ -36,6 +69,7 @@ func ToDBVectorEmbedding(embedding *types.IndexInfo, additionalParams map[string
 		KnowledgeBaseID: embedding.KnowledgeBaseID,
 		IsEnabled:       true, // Default to enabled
 	}
+	vector.SetAttributes(embedding.Attributes)
 	// Add embedding data if available in additionalParams
 	if additionalParams != nil && slices.Contains(slices.Collect(maps.Keys(additionalParams)), "embedding") {
 		if embeddingMap, ok := additionalParams["embedding"].(map[string][]float32); ok {
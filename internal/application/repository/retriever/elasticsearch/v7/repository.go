@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	elasticsearchRetriever "github.com/Tencent/WeKnora/internal/application/repository/retriever/elasticsearch"
 	"github.com/Tencent/WeKnora/internal/config"
@@ -303,8 +304,148 @@ func (e *elasticsearchRepository) DeleteByKnowledgeIDList(ctx context.Context,
 	return e.deleteByFieldList(ctx, "knowledge_id.keyword", knowledgeIDList)
 }
 
+// DeleteByKnowledgeBaseID Delete all documents of a knowledge base
+func (e *elasticsearchRepository) DeleteByKnowledgeBaseID(ctx context.Context,
+	knowledgeBaseID string, dimension int,
+) error {
+	return e.deleteByFieldList(ctx, "knowledge_base_id.keyword", []string{knowledgeBaseID},
+		e.client.DeleteByQuery.WithRefresh(true),
+	)
+}
+
+// ReplaceKnowledgeBaseIndices Delete the documents of a knowledge base and relabel its shadow documents,
+// both requests refresh the index so the shadow documents never mix with the old ones.
+// Without shadow documents the indices have already been replaced, so a retried switchover keeps them
+func (e *elasticsearchRepository) ReplaceKnowledgeBaseIndices(ctx context.Context,
+	knowledgeBaseID string, shadowKnowledgeBaseID string, oldDimension int, newDimension int,
+) error {
+	log := logger.GetLogger(ctx)
+	shadows, err := e.countByKnowledgeBaseID(ctx, shadowKnowledgeBaseID)
+	if err != nil {
+		log.Errorf("[ElasticsearchV7] Failed to count shadow indices: %v", err)
+		return err
+	}
+	if shadows == 0 {
+		log.Infof("[ElasticsearchV7] No shadow indices of knowledge base %s, already replaced", knowledgeBaseID)
+		return nil
+	}
+
+	if err := e.DeleteByKnowledgeBaseID(ctx, knowledgeBaseID, oldDimension); err != nil {
+		return err
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{
+				"knowledge_base_id.keyword": shadowKnowledgeBaseID,
+			},
+		},
+		"script": map[string]interface{}{
+			"source": "ctx._source.knowledge_base_id = params.knowledge_base_id",
+			"lang":   "painless",
+			"params": map[string]interface{}{
+				"knowledge_base_id": knowledgeBaseID,
+			},
+		},
+	}
+	queryJSON, _ := json.Marshal(query)
+	refresh := true
+	res, err := esapi.UpdateByQueryRequest{
+		Index:   []string{e.index},
+		Body:    strings.NewReader(string(queryJSON)),
+		Refresh: &refresh,
+	}.Do(ctx, e.client)
+	if err != nil {
+		log.Errorf("[ElasticsearchV7] Failed to move shadow indices: %v", err)
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		log.Errorf("[ElasticsearchV7] Error moving shadow indices: %s", res.String())
+		return fmt.Errorf("elasticsearch update_by_query failed with status: %d", res.StatusCode)
+	}
+
+	log.Infof("[ElasticsearchV7] Replaced indices of knowledge base %s", knowledgeBaseID)
+	return nil
+}
+
+// countByKnowledgeBaseID Count the documents of a knowledge base
+func (e *elasticsearchRepository) countByKnowledgeBaseID(ctx context.Context, knowledgeBaseID string) (int64, error) {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{
+				"knowledge_base_id.keyword": knowledgeBaseID,
+			},
+		},
+	}
+	queryJSON, _ := json.Marshal(query)
+	res, err := esapi.CountRequest{
+		Index: []string{e.index},
+		Body:  strings.NewReader(string(queryJSON)),
+	}.Do(ctx, e.client)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, fmt.Errorf("elasticsearch count failed with status: %d", res.StatusCode)
+	}
+	var countResponse struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&countResponse); err != nil {
+		return 0, fmt.Errorf("failed to decode count response: %w", err)
+	}
+	return countResponse.Count, nil
+}
+
+// MoveByChunkIDList moves the documents of chunks to another knowledge base ID
+func (e *elasticsearchRepository) MoveByChunkIDList(ctx context.Context,
+	chunkIDList []string, targetKnowledgeBaseID string, dimension int, knowledgeType string,
+) error {
+	log := logger.GetLogger(ctx)
+	if len(chunkIDList) == 0 {
+		return nil
+	}
+
+	log.Infof("[ElasticsearchV7] Moving indices by chunk IDs to %s, count: %d", targetKnowledgeBaseID, len(chunkIDList))
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"terms": map[string]interface{}{
+				"chunk_id.keyword": chunkIDList,
+			},
+		},
+		"script": map[string]interface{}{
+			"source": "ctx._source.knowledge_base_id = params.knowledge_base_id",
+			"lang":   "painless",
+			"params": map[string]interface{}{
+				"knowledge_base_id": targetKnowledgeBaseID,
+			},
+		},
+	}
+	queryJSON, _ := json.Marshal(query)
+	refresh := true
+	res, err := esapi.UpdateByQueryRequest{
+		Index:   []string{e.index},
+		Body:    strings.NewReader(string(queryJSON)),
+		Refresh: &refresh,
+	}.Do(ctx, e.client)
+	if err != nil {
+		log.Errorf("[ElasticsearchV7] Failed to move indices by chunk IDs: %v", err)
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		log.Errorf("[ElasticsearchV7] Error moving indices by chunk IDs: %s", res.String())
+		return fmt.Errorf("elasticsearch update_by_query failed with status: %d", res.StatusCode)
+	}
+	return nil
+}
+
 // deleteByFieldList Delete documents by field value list
-func (e *elasticsearchRepository) deleteByFieldList(ctx context.Context, field string, valueList []string) error {
+func (e *elasticsearchRepository) deleteByFieldList(ctx context.Context,
+	field string, valueList []string, options ...func(*esapi.DeleteByQueryRequest),
+) error {
 	log := logger.GetLogger(ctx)
 	if len(valueList) == 0 {
 		log.Warnf("[ElasticsearchV7] Empty %s list provided for deletion, skipping", field)
@@ -324,7 +465,7 @@ func (e *elasticsearchRepository) deleteByFieldList(ctx context.Context, field s
 	resp, err := e.client.DeleteByQuery(
 		[]string{e.index},
 		bytes.NewReader([]byte(query)),
-		e.client.DeleteByQuery.WithContext(ctx),
+		append(options, e.client.DeleteByQuery.WithContext(ctx))...,
 	)
 	if err != nil {
 		log.Errorf("[ElasticsearchV7] Failed to execute delete by query: %v", err)
@@ -387,6 +528,10 @@ func (e *elasticsearchRepository) getBaseConds(params typesLocal.RetrieveParams)
 			},
 		})
 	}
+	// Metadata filter is evaluated on the attributes denormalized into the documents
+	if params.Filter != nil {
+		must = append(must, elasticsearchRetriever.FilterQuery(params.Filter))
+	}
 
 	// Build MUST_NOT conditions (negative filters)
 	mustNot := make([]map[string]interface{}, 0)
@@ -1100,11 +1245,24 @@ func (e *elasticsearchRepository) processSingleHit(ctx context.Context,
 		KnowledgeBaseID: targetKnowledgeBaseID,
 		Content:         content,
 		SourceType:      typesLocal.SourceType(sourceType),
+		Attributes:      extractAttributes(sourceObj),
 	}
 
 	return indexInfo, embedding, nil
 }
 
+// extractAttributes reads the attributes used for metadata filtering from a source document
+func extractAttributes(sourceObj map[string]interface{}) *typesLocal.IndexAttributes {
+	attrs := &typesLocal.IndexAttributes{}
+	attrs.FileType, _ = sourceObj["file_type"].(string)
+	if createdAt, ok := sourceObj["knowledge_created_at"].(string); ok {
+		attrs.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	}
+	attrs.KnowledgeMetadata, _ = sourceObj["knowledge_metadata"].(map[string]interface{})
+	attrs.ChunkMetadata, _ = sourceObj["chunk_metadata"].(map[string]interface{})
+	return attrs
+}
+
 // saveCopiedIndices saves the copied indices
 func (e *elasticsearchRepository) saveCopiedIndices(ctx context.Context, indexInfoList []*typesLocal.IndexInfo) error {
 	log := logger.GetLogger(ctx)
@@ -234,6 +234,101 @@ func (e *elasticsearchRepository) DeleteByKnowledgeIDList(ctx context.Context,
 	return nil
 }
 
+// DeleteByKnowledgeBaseID removes all documents of a knowledge base from the index
+func (e *elasticsearchRepository) DeleteByKnowledgeBaseID(ctx context.Context,
+	knowledgeBaseID string, dimension int,
+) error {
+	log := logger.GetLogger(ctx)
+	log.Infof("[Elasticsearch] Deleting indices by knowledge base ID: %s", knowledgeBaseID)
+	_, err := e.client.DeleteByQuery(e.index).Query(knowledgeBaseQuery(knowledgeBaseID)).Refresh(true).Do(ctx)
+	if err != nil {
+		log.Errorf("[Elasticsearch] Failed to delete by knowledge base ID: %v", err)
+		return fmt.Errorf("failed to delete by query: %w", err)
+	}
+	return nil
+}
+
+// ReplaceKnowledgeBaseIndices deletes the documents of a knowledge base and relabels its shadow
+// documents. Both requests refresh the index so the shadow documents never mix with the old ones.
+// Without shadow documents the indices have already been replaced, so a retried switchover keeps them.
+func (e *elasticsearchRepository) ReplaceKnowledgeBaseIndices(ctx context.Context,
+	knowledgeBaseID string, shadowKnowledgeBaseID string, oldDimension int, newDimension int,
+) error {
+	log := logger.GetLogger(ctx)
+	shadows, err := e.client.Count().Index(e.index).Query(knowledgeBaseQuery(shadowKnowledgeBaseID)).Do(ctx)
+	if err != nil {
+		log.Errorf("[Elasticsearch] Failed to count shadow indices: %v", err)
+		return fmt.Errorf("failed to count shadow indices: %w", err)
+	}
+	if shadows.Count == 0 {
+		log.Infof("[Elasticsearch] No shadow indices of knowledge base %s, already replaced", knowledgeBaseID)
+		return nil
+	}
+
+	if err := e.DeleteByKnowledgeBaseID(ctx, knowledgeBaseID, oldDimension); err != nil {
+		return err
+	}
+
+	source := "ctx._source.knowledge_base_id = params.knowledge_base_id"
+	lang := scriptlanguage.Painless
+	knowledgeBaseIDJSON, _ := json.Marshal(knowledgeBaseID)
+	script := types.Script{
+		Source: &source,
+		Lang:   &lang,
+		Params: map[string]json.RawMessage{"knowledge_base_id": knowledgeBaseIDJSON},
+	}
+	_, err = e.client.UpdateByQuery(e.index).
+		Query(knowledgeBaseQuery(shadowKnowledgeBaseID)).
+		Script(&script).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		log.Errorf("[Elasticsearch] Failed to move shadow indices: %v", err)
+		return fmt.Errorf("failed to move shadow indices: %w", err)
+	}
+
+	log.Infof("[Elasticsearch] Replaced indices of knowledge base %s", knowledgeBaseID)
+	return nil
+}
+
+// MoveByChunkIDList moves the documents of chunks to another knowledge base ID
+func (e *elasticsearchRepository) MoveByChunkIDList(ctx context.Context,
+	chunkIDList []string, targetKnowledgeBaseID string, dimension int, knowledgeType string,
+) error {
+	log := logger.GetLogger(ctx)
+	if len(chunkIDList) == 0 {
+		return nil
+	}
+
+	log.Infof("[Elasticsearch] Moving indices by chunk IDs to %s, count: %d", targetKnowledgeBaseID, len(chunkIDList))
+	source := "ctx._source.knowledge_base_id = params.knowledge_base_id"
+	lang := scriptlanguage.Painless
+	knowledgeBaseIDJSON, _ := json.Marshal(targetKnowledgeBaseID)
+	script := types.Script{
+		Source: &source,
+		Lang:   &lang,
+		Params: map[string]json.RawMessage{"knowledge_base_id": knowledgeBaseIDJSON},
+	}
+	_, err := e.client.UpdateByQuery(e.index).
+		Query(&types.Query{
+			Terms: &types.TermsQuery{TermsQuery: map[string]types.TermsQueryField{"chunk_id.keyword": chunkIDList}},
+		}).
+		Script(&script).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		log.Errorf("[Elasticsearch] Failed to move indices by chunk IDs: %v", err)
+		return fmt.Errorf("failed to move indices: %w", err)
+	}
+	return nil
+}
+
+func knowledgeBaseQuery(knowledgeBaseID string) *types.Query {
+	return &types.Query{Term: map[string]types.TermQuery{
+		"knowledge_base_id.keyword": {Value: knowledgeBaseID},
+	}}
+}
+
 // getBaseConds creates the base query conditions for retrieval operations
 // Returns a slice of Query objects with must and must_not conditions
 // KnowledgeBaseIDs and KnowledgeIDs use AND logic (search specific documents within knowledge bases)
@@ -266,6 +361,15 @@ func (e *elasticsearchRepository) getBaseConds(params typesLocal.RetrieveParams)
 			},
 		}})
 	}
+	// Metadata filter is evaluated on the attributes denormalized into the documents
+	if params.Filter != nil {
+		if query, err := metadataFilterQuery(params.Filter); err == nil {
+			must = append(must, query)
+		} else {
+			// Never widen the search when the filter cannot be translated
+			must = append(must, types.Query{Ids: &types.IdsQuery{Values: []string{}}})
+		}
+	}
 
 	mustNot := make([]types.Query, 0)
 	// Exclude disabled chunks (is_enabled = false)
@@ -286,6 +390,18 @@ func (e *elasticsearchRepository) getBaseConds(params typesLocal.RetrieveParams)
 	return []types.Query{{Bool: &types.BoolQuery{Must: must, MustNot: mustNot}}}
 }
 
+// metadataFilterQuery converts the metadata filter query shared with the v7 repository
+// into a typed query
+func metadataFilterQuery(filter *typesLocal.MetadataFilter) (types.Query, error) {
+	var query types.Query
+	data, err := json.Marshal(elasticsearchRetriever.FilterQuery(filter))
+	if err != nil {
+		return query, err
+	}
+	err = json.Unmarshal(data, &query)
+	return query, err
+}
+
 // createIndexIfNotExists checks if the specified index exists and creates it if not
 // Returns an error if the operation fails
 func (e *elasticsearchRepository) createIndexIfNotExists(ctx context.Context) error {
@@ -575,6 +691,7 @@ func (e *elasticsearchRepository) CopyIndices(ctx context.Context,
 				ChunkID:         targetChunkID,
 				KnowledgeID:     targetKnowledgeID,
 				KnowledgeBaseID: targetKnowledgeBaseID,
+				Attributes:      sourceDoc.Attributes(),
 			}
 
 			indexInfoList = append(indexInfoList, indexInfo)
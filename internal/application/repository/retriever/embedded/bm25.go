@@ -0,0 +1,161 @@
+package embedded
+
+import (
+	"maps"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// bm25Index is an in-memory inverted index scored with Okapi BM25.
+// Fields are exported for gob persistence.
+type bm25Index struct {
+	// Postings maps a term to the term frequency of every document containing it
+	Postings map[string]map[string]int
+	// DocLengths maps a document to its number of terms
+	DocLengths map[string]int
+	// TotalLength is the sum of all document lengths
+	TotalLength int
+}
+
+func newBM25Index() *bm25Index {
+	return &bm25Index{
+		Postings:   make(map[string]map[string]int),
+		DocLengths: make(map[string]int),
+	}
+}
+
+// Add indexes the content of a document, replacing any previous content
+func (b *bm25Index) Add(docID string, content string) {
+	b.Delete(docID, content)
+	terms := tokenize(content)
+	if len(terms) == 0 {
+		return
+	}
+	for _, term := range terms {
+		postings, ok := b.Postings[term]
+		if !ok {
+			postings = make(map[string]int)
+			b.Postings[term] = postings
+		}
+		postings[docID]++
+	}
+	b.DocLengths[docID] = len(terms)
+	b.TotalLength += len(terms)
+}
+
+// Delete removes a document, content must be the content the document was indexed with
+func (b *bm25Index) Delete(docID string, content string) {
+	length, ok := b.DocLengths[docID]
+	if !ok {
+		return
+	}
+	for _, term := range tokenize(content) {
+		if postings, ok := b.Postings[term]; ok {
+			delete(postings, docID)
+			if len(postings) == 0 {
+				delete(b.Postings, term)
+			}
+		}
+	}
+	delete(b.DocLengths, docID)
+	b.TotalLength -= length
+}
+
+// clone returns a deep copy of the index
+func (b *bm25Index) clone() *bm25Index {
+	postings := make(map[string]map[string]int, len(b.Postings))
+	for term, docs := range b.Postings {
+		postings[term] = maps.Clone(docs)
+	}
+	return &bm25Index{Postings: postings, DocLengths: maps.Clone(b.DocLengths), TotalLength: b.TotalLength}
+}
+
+// Search scores the documents accepted by the filter against the query
+func (b *bm25Index) Search(query string, filter func(docID string) bool) map[string]float64 {
+	scores := make(map[string]float64)
+	docCount := len(b.DocLengths)
+	if docCount == 0 {
+		return scores
+	}
+	avgLength := float64(b.TotalLength) / float64(docCount)
+
+	seen := make(map[string]struct{})
+	for _, term := range tokenize(query) {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+
+		postings := b.Postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (float64(docCount)-df+0.5)/(df+0.5))
+		for docID, tf := range postings {
+			if filter != nil && !filter(docID) {
+				continue
+			}
+			freq := float64(tf)
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(b.DocLengths[docID])/avgLength)
+			scores[docID] += idf * freq * (bm25K1 + 1) / (freq + norm)
+		}
+	}
+	return scores
+}
+
+// tokenize splits text into lowercase terms. Runs of letters and digits form a term, while
+// CJK text, which has no word delimiters, is indexed as overlapping character bigrams
+// (plus the single character when a run has only one) so no dictionary is required.
+func tokenize(text string) []string {
+	var terms []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			terms = append(terms, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				terms = append(terms, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
@@ -0,0 +1,146 @@
+package embedded
+
+import (
+	"encoding/gob"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+func init() {
+	// Metadata arrays are stored as interface values in the snapshot
+	gob.Register([]any{})
+}
+
+// matchFilter evaluates a metadata filter against the attributes stored with a document
+func matchFilter(filter *types.MetadataFilter, attrs *types.IndexAttributes) bool {
+	switch filter.Op {
+	case types.MetadataFilterOpAnd:
+		for _, sub := range filter.Filters {
+			if !matchFilter(sub, attrs) {
+				return false
+			}
+		}
+		return true
+	case types.MetadataFilterOpOr:
+		for _, sub := range filter.Filters {
+			if matchFilter(sub, attrs) {
+				return true
+			}
+		}
+		return false
+	case types.MetadataFilterOpNot:
+		return !matchFilter(filter.Filters[0], attrs)
+	}
+
+	if attrs == nil {
+		attrs = &types.IndexAttributes{}
+	}
+	var value any
+	target, key := filter.Target()
+	switch target {
+	case types.MetadataFilterTargetFileType:
+		if attrs.FileType != "" {
+			value = attrs.FileType
+		}
+	case types.MetadataFilterTargetCreatedAt:
+		if !attrs.CreatedAt.IsZero() {
+			value = attrs.CreatedAt
+		}
+	case types.MetadataFilterTargetKnowledgeMetadata:
+		value = attrs.KnowledgeMetadata[key]
+	case types.MetadataFilterTargetChunkMetadata:
+		value = attrs.ChunkMetadata[key]
+	default:
+		return false
+	}
+
+	// Array values match when any element matches
+	values := []any{value}
+	if list, ok := value.([]any); ok {
+		values = list
+	}
+	switch filter.Op {
+	case types.MetadataFilterOpExists:
+		return value != nil
+	case types.MetadataFilterOpEq:
+		return anyMatch(values, func(v any) bool { return v == filter.Value })
+	case types.MetadataFilterOpIn:
+		return anyMatch(values, func(v any) bool {
+			for _, candidate := range filter.Values {
+				if v == candidate {
+					return true
+				}
+			}
+			return false
+		})
+	case types.MetadataFilterOpRange:
+		bounds := filter.Bounds()
+		return anyMatch(values, func(v any) bool { return inBounds(v, bounds) })
+	default:
+		return false
+	}
+}
+
+func anyMatch(values []any, match func(v any) bool) bool {
+	for _, v := range values {
+		if v != nil && match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// inBounds compares numbers with number bounds and timestamps with time bounds,
+// values of any other type never match
+func inBounds(value any, bounds []types.MetadataFilterBound) bool {
+	for _, bound := range bounds {
+		var order int
+		if bound.IsTime {
+			var t time.Time
+			switch v := value.(type) {
+			case time.Time:
+				t = v
+			case string:
+				parsed, err := types.ParseFilterTime(v)
+				if err != nil {
+					return false
+				}
+				t = parsed
+			default:
+				return false
+			}
+			order = t.Compare(bound.Time)
+		} else {
+			number, ok := value.(float64)
+			if !ok {
+				return false
+			}
+			switch {
+			case number < bound.Number:
+				order = -1
+			case number > bound.Number:
+				order = 1
+			}
+		}
+		switch bound.Op {
+		case "gt":
+			if order <= 0 {
+				return false
+			}
+		case "gte":
+			if order < 0 {
+				return false
+			}
+		case "lt":
+			if order >= 0 {
+				return false
+			}
+		case "lte":
+			if order > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
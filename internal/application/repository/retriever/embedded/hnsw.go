@@ -0,0 +1,398 @@
+package embedded
+
+import (
+	"cmp"
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// HNSW parameters, see https://arxiv.org/abs/1603.09320
+const (
+	defaultHNSWM              = 16
+	defaultHNSWEfConstruction = 200
+	defaultHNSWEfSearch       = 64
+)
+
+// hnswNode is a vector in the HNSW graph. Fields are exported for gob persistence.
+type hnswNode struct {
+	DocID   string
+	Vector  []float32
+	Level   int
+	Friends [][]uint32
+	Deleted bool
+}
+
+// hnswIndex is an in-memory Hierarchical Navigable Small World graph using cosine similarity.
+// Vectors are normalized on insertion so similarity is a dot product. Deleted nodes are kept
+// as tombstones for graph connectivity until the index is rebuilt.
+type hnswIndex struct {
+	Dimension      int
+	M              int
+	EfConstruction int
+	EfSearch       int
+	Nodes          []*hnswNode
+	EntryPoint     int
+	MaxLevel       int
+	DeletedCount   int
+
+	docToNode map[string]uint32
+	rng       *rand.Rand
+}
+
+func newHNSWIndex(dimension int) *hnswIndex {
+	h := &hnswIndex{
+		Dimension:      dimension,
+		M:              defaultHNSWM,
+		EfConstruction: defaultHNSWEfConstruction,
+		EfSearch:       defaultHNSWEfSearch,
+		EntryPoint:     -1,
+	}
+	h.init()
+	return h
+}
+
+// init rebuilds the transient state after creation or loading from disk
+func (h *hnswIndex) init() {
+	h.rng = rand.New(rand.NewPCG(uint64(h.Dimension), uint64(len(h.Nodes))))
+	h.docToNode = make(map[string]uint32, len(h.Nodes))
+	for i, node := range h.Nodes {
+		if !node.Deleted {
+			h.docToNode[node.DocID] = uint32(i)
+		}
+	}
+}
+
+// Len returns the number of live vectors in the index
+func (h *hnswIndex) Len() int {
+	return len(h.docToNode)
+}
+
+// Get returns the normalized vector of a document
+func (h *hnswIndex) Get(docID string) ([]float32, bool) {
+	id, ok := h.docToNode[docID]
+	if !ok {
+		return nil, false
+	}
+	return h.Nodes[id].Vector, true
+}
+
+// Add inserts or replaces the vector of a document
+func (h *hnswIndex) Add(docID string, vector []float32) {
+	h.Delete(docID)
+
+	node := &hnswNode{
+		DocID:  docID,
+		Vector: normalize(vector),
+		Level:  h.randomLevel(),
+	}
+	node.Friends = make([][]uint32, node.Level+1)
+	id := uint32(len(h.Nodes))
+	h.Nodes = append(h.Nodes, node)
+	h.docToNode[docID] = id
+
+	if h.EntryPoint < 0 {
+		h.EntryPoint = int(id)
+		h.MaxLevel = node.Level
+		return
+	}
+
+	entry := uint32(h.EntryPoint)
+	// Greedy descent through the layers above the node level
+	for level := h.MaxLevel; level > node.Level; level-- {
+		entry = h.searchLayer(node.Vector, []uint32{entry}, 1, level)[0].id
+	}
+	entries := []uint32{entry}
+	for level := min(node.Level, h.MaxLevel); level >= 0; level-- {
+		candidates := h.searchLayer(node.Vector, entries, h.EfConstruction, level)
+		neighbors := h.selectNeighbors(candidates, h.M)
+		node.Friends[level] = neighbors
+		for _, neighbor := range neighbors {
+			h.connect(neighbor, id, level)
+		}
+		entries = make([]uint32, 0, len(candidates))
+		for _, c := range candidates {
+			entries = append(entries, c.id)
+		}
+	}
+
+	if node.Level > h.MaxLevel {
+		h.MaxLevel = node.Level
+		h.EntryPoint = int(id)
+	}
+}
+
+// Delete marks the vector of a document as deleted
+func (h *hnswIndex) Delete(docID string) bool {
+	id, ok := h.docToNode[docID]
+	if !ok {
+		return false
+	}
+	h.Nodes[id].Deleted = true
+	h.DeletedCount++
+	delete(h.docToNode, docID)
+	return true
+}
+
+// NeedsRebuild reports whether tombstones make up a large part of the graph
+func (h *hnswIndex) NeedsRebuild() bool {
+	return h.DeletedCount > 1000 && h.DeletedCount*3 > len(h.Nodes)
+}
+
+// Rebuild returns a new index containing only the live vectors
+func (h *hnswIndex) Rebuild() *hnswIndex {
+	rebuilt := newHNSWIndex(h.Dimension)
+	for _, node := range h.Nodes {
+		if !node.Deleted {
+			rebuilt.Add(node.DocID, node.Vector)
+		}
+	}
+	return rebuilt
+}
+
+// clone returns a copy of the graph that later changes to the index do not affect.
+// Vectors are never modified after insertion and are shared, the transient state is not copied.
+func (h *hnswIndex) clone() *hnswIndex {
+	c := &hnswIndex{
+		Dimension:      h.Dimension,
+		M:              h.M,
+		EfConstruction: h.EfConstruction,
+		EfSearch:       h.EfSearch,
+		Nodes:          make([]*hnswNode, len(h.Nodes)),
+		EntryPoint:     h.EntryPoint,
+		MaxLevel:       h.MaxLevel,
+		DeletedCount:   h.DeletedCount,
+	}
+	for i, node := range h.Nodes {
+		friends := make([][]uint32, len(node.Friends))
+		for level, ids := range node.Friends {
+			friends[level] = slices.Clone(ids)
+		}
+		c.Nodes[i] = &hnswNode{DocID: node.DocID, Vector: node.Vector, Level: node.Level, Friends: friends,
+			Deleted: node.Deleted}
+	}
+	return c
+}
+
+// Search returns the k most similar live vectors accepted by the filter.
+// When the filter rejects most candidates, ef is enlarged until enough results are found,
+// falling back to an exhaustive scan when ef reaches the size of the graph.
+func (h *hnswIndex) Search(query []float32, k int, filter func(docID string) bool) []scoredNode {
+	if h.EntryPoint < 0 || k <= 0 || len(query) != h.Dimension {
+		return nil
+	}
+	query = normalize(query)
+	ef := max(h.EfSearch, k)
+	for {
+		if ef >= len(h.Nodes) {
+			return h.exhaustiveSearch(query, k, filter)
+		}
+		entry := uint32(h.EntryPoint)
+		for level := h.MaxLevel; level > 0; level-- {
+			entry = h.searchLayer(query, []uint32{entry}, 1, level)[0].id
+		}
+		candidates := h.searchLayer(query, []uint32{entry}, ef, 0)
+		results := make([]scoredNode, 0, k)
+		for _, c := range candidates {
+			node := h.Nodes[c.id]
+			if node.Deleted || (filter != nil && !filter(node.DocID)) {
+				continue
+			}
+			results = append(results, c)
+			if len(results) == k {
+				return results
+			}
+		}
+		if filter == nil && h.DeletedCount == 0 {
+			return results
+		}
+		ef *= 4
+	}
+}
+
+// exhaustiveSearch scans every live vector, used for small or heavily filtered searches
+func (h *hnswIndex) exhaustiveSearch(query []float32, k int, filter func(docID string) bool) []scoredNode {
+	results := &minScoreHeap{}
+	for i, node := range h.Nodes {
+		if node.Deleted || (filter != nil && !filter(node.DocID)) {
+			continue
+		}
+		heap.Push(results, scoredNode{id: uint32(i), score: dot(query, node.Vector)})
+		if results.Len() > k {
+			heap.Pop(results)
+		}
+	}
+	sorted := make([]scoredNode, results.Len())
+	for i := len(sorted) - 1; i >= 0; i-- {
+		sorted[i] = heap.Pop(results).(scoredNode)
+	}
+	return sorted
+}
+
+// searchLayer performs a best-first search on one layer and returns up to ef nodes by descending score
+func (h *hnswIndex) searchLayer(query []float32, entries []uint32, ef int, level int) []scoredNode {
+	visited := make(map[uint32]struct{}, ef*4)
+	candidates := &maxScoreHeap{}
+	results := &minScoreHeap{}
+	for _, entry := range entries {
+		visited[entry] = struct{}{}
+		s := scoredNode{id: entry, score: dot(query, h.Nodes[entry].Vector)}
+		heap.Push(candidates, s)
+		heap.Push(results, s)
+	}
+	for results.Len() > ef {
+		heap.Pop(results)
+	}
+
+	for candidates.Len() > 0 {
+		current := heap.Pop(candidates).(scoredNode)
+		if results.Len() >= ef && current.score < (*results)[0].score {
+			break
+		}
+		node := h.Nodes[current.id]
+		if level >= len(node.Friends) {
+			continue
+		}
+		for _, friend := range node.Friends[level] {
+			if _, ok := visited[friend]; ok {
+				continue
+			}
+			visited[friend] = struct{}{}
+			s := scoredNode{id: friend, score: dot(query, h.Nodes[friend].Vector)}
+			if results.Len() < ef || s.score > (*results)[0].score {
+				heap.Push(candidates, s)
+				heap.Push(results, s)
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	sorted := make([]scoredNode, results.Len())
+	for i := len(sorted) - 1; i >= 0; i-- {
+		sorted[i] = heap.Pop(results).(scoredNode)
+	}
+	return sorted
+}
+
+// selectNeighbors picks up to m diverse neighbors from candidates sorted by descending score,
+// using the heuristic of the HNSW paper: a candidate is kept only if it is closer to the base
+// than to every neighbor already selected
+func (h *hnswIndex) selectNeighbors(candidates []scoredNode, m int) []uint32 {
+	selected := make([]uint32, 0, m)
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		keep := true
+		for _, s := range selected {
+			if dot(h.Nodes[c.id].Vector, h.Nodes[s].Vector) > c.score {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, c.id)
+		}
+	}
+	// Fill up with the closest remaining candidates to keep the graph well connected
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		if !slices.Contains(selected, c.id) {
+			selected = append(selected, c.id)
+		}
+	}
+	return selected
+}
+
+// connect adds a link from node to friend on the level, pruning the links if needed
+func (h *hnswIndex) connect(node, friend uint32, level int) {
+	n := h.Nodes[node]
+	maxFriends := h.M
+	if level == 0 {
+		maxFriends = h.M * 2
+	}
+	n.Friends[level] = append(n.Friends[level], friend)
+	if len(n.Friends[level]) <= maxFriends {
+		return
+	}
+	candidates := make([]scoredNode, 0, len(n.Friends[level]))
+	for _, f := range n.Friends[level] {
+		candidates = append(candidates, scoredNode{id: f, score: dot(n.Vector, h.Nodes[f].Vector)})
+	}
+	sortByScoreDesc(candidates)
+	n.Friends[level] = h.selectNeighbors(candidates, maxFriends)
+}
+
+func (h *hnswIndex) randomLevel() int {
+	levelMult := 1 / math.Log(float64(h.M))
+	return int(math.Floor(-math.Log(1-h.rng.Float64()) * levelMult))
+}
+
+// scoredNode is a node with its similarity to the query
+type scoredNode struct {
+	id    uint32
+	score float32
+}
+
+// minScoreHeap keeps the lowest score on top
+type minScoreHeap []scoredNode
+
+func (h minScoreHeap) Len() int           { return len(h) }
+func (h minScoreHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h minScoreHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minScoreHeap) Push(x any)        { *h = append(*h, x.(scoredNode)) }
+func (h *minScoreHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// maxScoreHeap keeps the highest score on top
+type maxScoreHeap []scoredNode
+
+func (h maxScoreHeap) Len() int           { return len(h) }
+func (h maxScoreHeap) Less(i, j int) bool { return h[i].score > h[j].score }
+func (h maxScoreHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxScoreHeap) Push(x any)        { *h = append(*h, x.(scoredNode)) }
+func (h *maxScoreHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func sortByScoreDesc(nodes []scoredNode) {
+	slices.SortFunc(nodes, func(a, b scoredNode) int {
+		return cmp.Compare(b.score, a.score)
+	})
+}
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func normalize(vector []float32) []float32 {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	normalized := make([]float32, len(vector))
+	if norm == 0 {
+		return normalized
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i, v := range vector {
+		normalized[i] = v * scale
+	}
+	return normalized
+}
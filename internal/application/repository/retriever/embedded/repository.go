@@ -0,0 +1,694 @@
+package embedded
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"github.com/google/uuid"
+)
+
+const (
+	snapshotFileName = "embedded_index.gob"
+	snapshotVersion  = 1
+	fieldEmbedding   = "embedding"
+	// Interval at which pending changes are persisted to disk
+	flushInterval = 5 * time.Second
+)
+
+// NewEmbeddedRetrieveEngineRepository creates an in-process retriever engine repository that keeps
+// an HNSW vector index and a BM25 inverted index in memory and persists them to dir.
+// Changes are flushed to disk periodically and on Close. The index directory must not be shared
+// between processes.
+func NewEmbeddedRetrieveEngineRepository(dir string) (interfaces.RetrieveEngineRepository, error) {
+	log := logger.GetLogger(context.Background())
+	log.Infof("[Embedded] Initializing embedded retriever engine repository in %s", dir)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	r := &embeddedRepository{
+		dir:       dir,
+		documents: make(map[string]*embeddedDocument),
+		vectors:   make(map[int]*hnswIndex),
+		keywords:  newBM25Index(),
+		stopCh:    make(chan struct{}),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	r.flushWg.Add(1)
+	go r.flushLoop()
+
+	log.Infof("[Embedded] Successfully initialized repository with %d documents", len(r.documents))
+	return r, nil
+}
+
+// load restores the index from the snapshot file if it exists
+func (r *embeddedRepository) load() error {
+	data, err := os.ReadFile(filepath.Join(r.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read index snapshot: %w", err)
+	}
+
+	var snapshot embeddedSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to decode index snapshot: %w", err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return fmt.Errorf("unsupported index snapshot version: %d", snapshot.Version)
+	}
+
+	if snapshot.Documents != nil {
+		r.documents = snapshot.Documents
+	}
+	if snapshot.Vectors != nil {
+		r.vectors = snapshot.Vectors
+	}
+	if snapshot.Keywords != nil {
+		r.keywords = snapshot.Keywords
+	}
+	for _, index := range r.vectors {
+		index.init()
+	}
+	return nil
+}
+
+// flushLoop persists pending changes until the repository is closed
+func (r *embeddedRepository) flushLoop() {
+	defer r.flushWg.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Flush(); err != nil {
+				logger.GetLogger(context.Background()).Errorf("[Embedded] Failed to flush index: %v", err)
+			}
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// Flush writes the index snapshot to disk if there are changes since the last flush.
+// The indexes are copied under the read lock and encoded without holding any lock, so searches and
+// writes are not blocked while the snapshot is written. The snapshot is written to a temporary file
+// and renamed so a crash never leaves a partial file.
+func (r *embeddedRepository) Flush() error {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	r.mu.RLock()
+	if r.changes == r.flushed {
+		r.mu.RUnlock()
+		return nil
+	}
+	changes := r.changes
+	snapshot := r.snapshot()
+	r.mu.RUnlock()
+
+	// Graphs with many tombstones are compacted in the copy, the compacted graph replaces the live one
+	// once it is persisted unless the index changed in the meantime
+	rebuilt := make(map[int]*hnswIndex)
+	for dimension, index := range snapshot.Vectors {
+		if index.NeedsRebuild() {
+			rebuilt[dimension] = index.Rebuild()
+			snapshot.Vectors[dimension] = rebuilt[dimension]
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(snapshot); err != nil {
+		return fmt.Errorf("failed to encode index snapshot: %w", err)
+	}
+	if err := r.writeSnapshot(buf.Bytes()); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.flushed = changes
+	if r.changes == changes {
+		for dimension, index := range rebuilt {
+			r.vectors[dimension] = index
+		}
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// snapshot copies the persisted state of the repository so later changes do not affect it.
+// Caller must hold the read lock.
+func (r *embeddedRepository) snapshot() *embeddedSnapshot {
+	documents := make(map[string]*embeddedDocument, len(r.documents))
+	for id, doc := range r.documents {
+		copied := *doc
+		documents[id] = &copied
+	}
+	vectors := make(map[int]*hnswIndex, len(r.vectors))
+	for dimension, index := range r.vectors {
+		vectors[dimension] = index.clone()
+	}
+	return &embeddedSnapshot{
+		Version:   snapshotVersion,
+		Documents: documents,
+		Vectors:   vectors,
+		Keywords:  r.keywords.clone(),
+	}
+}
+
+// writeSnapshot atomically replaces the snapshot file with data
+func (r *embeddedRepository) writeSnapshot(data []byte) error {
+	tmpFile, err := os.CreateTemp(r.dir, snapshotFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary snapshot: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write index snapshot: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to sync index snapshot: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close index snapshot: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), filepath.Join(r.dir, snapshotFileName)); err != nil {
+		return fmt.Errorf("failed to replace index snapshot: %w", err)
+	}
+	return nil
+}
+
+// Close stops the background flush and persists pending changes
+func (r *embeddedRepository) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.flushWg.Wait()
+	return r.Flush()
+}
+
+func (r *embeddedRepository) EngineType() types.RetrieverEngineType {
+	return types.EmbeddedRetrieverEngineType
+}
+
+func (r *embeddedRepository) Support() []types.RetrieverType {
+	return []types.RetrieverType{types.KeywordsRetrieverType, types.VectorRetrieverType}
+}
+
+// documentID returns the ID of a document, a source is indexed once per embedding dimension.
+// Shadow documents written while re-embedding a knowledge base get IDs of their own.
+func documentID(dimension int, knowledgeBaseID string, sourceID string) string {
+	if types.IsShadowKnowledgeBaseID(knowledgeBaseID) {
+		return fmt.Sprintf("%d/%s/%s", dimension, knowledgeBaseID, sourceID)
+	}
+	return fmt.Sprintf("%d/%s", dimension, sourceID)
+}
+
+// addDocument indexes a document, replacing any document with the same ID. Caller must hold the write lock.
+func (r *embeddedRepository) addDocument(doc *embeddedDocument, vector []float32) {
+	if existing, ok := r.documents[doc.ID]; ok {
+		r.keywords.Delete(existing.ID, existing.Content)
+	}
+	r.documents[doc.ID] = doc
+	r.keywords.Add(doc.ID, doc.Content)
+	if doc.Dimension > 0 {
+		index, ok := r.vectors[doc.Dimension]
+		if !ok {
+			index = newHNSWIndex(doc.Dimension)
+			r.vectors[doc.Dimension] = index
+		}
+		index.Add(doc.ID, vector)
+	}
+	r.changes++
+}
+
+// removeDocument removes a document from all indexes. Caller must hold the write lock.
+func (r *embeddedRepository) removeDocument(doc *embeddedDocument) {
+	r.keywords.Delete(doc.ID, doc.Content)
+	if index, ok := r.vectors[doc.Dimension]; ok {
+		index.Delete(doc.ID)
+	}
+	delete(r.documents, doc.ID)
+	r.changes++
+}
+
+// EstimateStorageSize calculates the estimated storage size for a list of indices
+func (r *embeddedRepository) EstimateStorageSize(ctx context.Context,
+	indexInfoList []*types.IndexInfo, params map[string]any,
+) int64 {
+	embeddingMap, _ := params[fieldEmbedding].(map[string][]float32)
+	var totalStorageSize int64
+	for _, indexInfo := range indexInfoList {
+		// Document fields, plus roughly the same size again for the inverted index postings
+		size := int64(2*len(indexInfo.Content) + len(indexInfo.SourceID) + len(indexInfo.ChunkID) +
+			len(indexInfo.KnowledgeID) + len(indexInfo.KnowledgeBaseID) + len(indexInfo.TagID) + 16)
+		if dimension := int64(len(embeddingMap[indexInfo.SourceID])); dimension > 0 {
+			// Vector plus HNSW links on layer 0 (2*M links of 4 bytes)
+			size += dimension*4 + defaultHNSWM*2*4
+		}
+		totalStorageSize += size
+	}
+	logger.GetLogger(ctx).Infof(
+		"[Embedded] Storage size for %d indices: %d bytes", len(indexInfoList), totalStorageSize,
+	)
+	return totalStorageSize
+}
+
+// Save stores a single index
+func (r *embeddedRepository) Save(ctx context.Context,
+	indexInfo *types.IndexInfo, additionalParams map[string]any,
+) error {
+	return r.BatchSave(ctx, []*types.IndexInfo{indexInfo}, additionalParams)
+}
+
+// BatchSave stores multiple indices
+func (r *embeddedRepository) BatchSave(ctx context.Context,
+	indexInfoList []*types.IndexInfo, additionalParams map[string]any,
+) error {
+	log := logger.GetLogger(ctx)
+	if len(indexInfoList) == 0 {
+		log.Warn("[Embedded] Empty list provided to BatchSave, skipping")
+		return nil
+	}
+
+	embeddingMap, _ := additionalParams[fieldEmbedding].(map[string][]float32)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, indexInfo := range indexInfoList {
+		vector := embeddingMap[indexInfo.SourceID]
+		r.addDocument(&embeddedDocument{
+			ID:              documentID(len(vector), indexInfo.KnowledgeBaseID, indexInfo.SourceID),
+			Content:         indexInfo.Content,
+			SourceID:        indexInfo.SourceID,
+			SourceType:      int(indexInfo.SourceType),
+			ChunkID:         indexInfo.ChunkID,
+			KnowledgeID:     indexInfo.KnowledgeID,
+			KnowledgeBaseID: indexInfo.KnowledgeBaseID,
+			TagID:           indexInfo.TagID,
+			IsEnabled:       true,
+			Dimension:       len(vector),
+			Attributes:      indexInfo.Attributes,
+		}, vector)
+	}
+
+	log.Infof("[Embedded] Successfully batch saved %d indices", len(indexInfoList))
+	return nil
+}
+
+// deleteWhere removes the documents of the dimension (and keyword-only documents) matching the predicate
+func (r *embeddedRepository) deleteWhere(ctx context.Context, dimension int, match func(doc *embeddedDocument) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deleted := 0
+	for _, doc := range r.documents {
+		if (doc.Dimension == dimension || doc.Dimension == 0) && match(doc) {
+			r.removeDocument(doc)
+			deleted++
+		}
+	}
+	logger.GetLogger(ctx).Infof("[Embedded] Deleted %d documents", deleted)
+}
+
+// DeleteByChunkIDList removes indices by chunk IDs
+func (r *embeddedRepository) DeleteByChunkIDList(ctx context.Context,
+	chunkIDList []string, dimension int, knowledgeType string,
+) error {
+	ids := toSet(chunkIDList)
+	r.deleteWhere(ctx, dimension, func(doc *embeddedDocument) bool {
+		_, ok := ids[doc.ChunkID]
+		return ok
+	})
+	return nil
+}
+
+// DeleteByKnowledgeIDList removes indices by knowledge IDs
+func (r *embeddedRepository) DeleteByKnowledgeIDList(ctx context.Context,
+	knowledgeIDList []string, dimension int, knowledgeType string,
+) error {
+	ids := toSet(knowledgeIDList)
+	r.deleteWhere(ctx, dimension, func(doc *embeddedDocument) bool {
+		_, ok := ids[doc.KnowledgeID]
+		return ok
+	})
+	return nil
+}
+
+// DeleteBySourceIDList removes indices by source IDs
+func (r *embeddedRepository) DeleteBySourceIDList(ctx context.Context,
+	sourceIDList []string, dimension int, knowledgeType string,
+) error {
+	ids := toSet(sourceIDList)
+	r.deleteWhere(ctx, dimension, func(doc *embeddedDocument) bool {
+		_, ok := ids[doc.SourceID]
+		return ok
+	})
+	return nil
+}
+
+// DeleteByKnowledgeBaseID removes all indices of a knowledge base
+func (r *embeddedRepository) DeleteByKnowledgeBaseID(ctx context.Context,
+	knowledgeBaseID string, dimension int,
+) error {
+	r.deleteWhere(ctx, dimension, func(doc *embeddedDocument) bool {
+		return doc.KnowledgeBaseID == knowledgeBaseID
+	})
+	return nil
+}
+
+// ReplaceKnowledgeBaseIndices removes the indices of a knowledge base and moves its shadow indices
+// into it while holding the write lock, so searches see either the old or the new indices
+func (r *embeddedRepository) ReplaceKnowledgeBaseIndices(ctx context.Context,
+	knowledgeBaseID string, shadowKnowledgeBaseID string, oldDimension int, newDimension int,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var current, shadows []*embeddedDocument
+	for _, doc := range r.documents {
+		switch doc.KnowledgeBaseID {
+		case knowledgeBaseID:
+			current = append(current, doc)
+		case shadowKnowledgeBaseID:
+			shadows = append(shadows, doc)
+		}
+	}
+	// Without shadow documents the indices have already been replaced, a retried switchover keeps them
+	if len(shadows) == 0 {
+		return nil
+	}
+	for _, doc := range current {
+		r.removeDocument(doc)
+	}
+
+	for _, shadow := range shadows {
+		var vector []float32
+		if shadow.Dimension > 0 {
+			var ok bool
+			if vector, ok = r.vectors[shadow.Dimension].Get(shadow.ID); !ok {
+				continue
+			}
+		}
+		r.removeDocument(shadow)
+		doc := *shadow
+		doc.ID = documentID(doc.Dimension, knowledgeBaseID, doc.SourceID)
+		doc.KnowledgeBaseID = knowledgeBaseID
+		r.addDocument(&doc, vector)
+	}
+
+	logger.GetLogger(ctx).Infof("[Embedded] Replaced %d documents of knowledge base %s with %d shadow documents",
+		len(current), knowledgeBaseID, len(shadows))
+	return nil
+}
+
+// MoveByChunkIDList moves the indices of chunks to another knowledge base ID.
+// Document IDs of regular knowledge bases do not contain the knowledge base ID, so documents are relabeled in place.
+func (r *embeddedRepository) MoveByChunkIDList(ctx context.Context,
+	chunkIDList []string, targetKnowledgeBaseID string, dimension int, knowledgeType string,
+) error {
+	ids := toSet(chunkIDList)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	moved := 0
+	for _, doc := range r.documents {
+		if _, ok := ids[doc.ChunkID]; ok {
+			doc.KnowledgeBaseID = targetKnowledgeBaseID
+			moved++
+		}
+	}
+	if moved > 0 {
+		r.changes++
+	}
+	logger.GetLogger(ctx).Infof("[Embedded] Moved %d documents to knowledge base %s", moved, targetKnowledgeBaseID)
+	return nil
+}
+
+// BatchUpdateChunkEnabledStatus updates the enabled status of chunks in batch
+func (r *embeddedRepository) BatchUpdateChunkEnabledStatus(ctx context.Context, chunkStatusMap map[string]bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, doc := range r.documents {
+		if enabled, ok := chunkStatusMap[doc.ChunkID]; ok && doc.IsEnabled != enabled {
+			doc.IsEnabled = enabled
+			r.changes++
+		}
+	}
+	return nil
+}
+
+// BatchUpdateChunkTagID updates the tag ID of chunks in batch
+func (r *embeddedRepository) BatchUpdateChunkTagID(ctx context.Context, chunkTagMap map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, doc := range r.documents {
+		if tagID, ok := chunkTagMap[doc.ChunkID]; ok && doc.TagID != tagID {
+			doc.TagID = tagID
+			r.changes++
+		}
+	}
+	return nil
+}
+
+// newFilter builds the document filter shared by all retrieval types. Caller must hold the read lock.
+func (r *embeddedRepository) newFilter(params types.RetrieveParams) func(docID string) bool {
+	knowledgeBaseIDs := toSet(params.KnowledgeBaseIDs)
+	knowledgeIDs := toSet(params.KnowledgeIDs)
+	tagIDs := toSet(params.TagIDs)
+	excludeKnowledgeIDs := toSet(params.ExcludeKnowledgeIDs)
+	excludeChunkIDs := toSet(params.ExcludeChunkIDs)
+
+	return func(docID string) bool {
+		doc, ok := r.documents[docID]
+		if !ok || !doc.IsEnabled {
+			return false
+		}
+		if len(knowledgeBaseIDs) > 0 && !inSet(knowledgeBaseIDs, doc.KnowledgeBaseID) {
+			return false
+		}
+		if len(knowledgeIDs) > 0 && !inSet(knowledgeIDs, doc.KnowledgeID) {
+			return false
+		}
+		if len(tagIDs) > 0 && !inSet(tagIDs, doc.TagID) {
+			return false
+		}
+		if inSet(excludeKnowledgeIDs, doc.KnowledgeID) || inSet(excludeChunkIDs, doc.ChunkID) {
+			return false
+		}
+		return params.Filter == nil || matchFilter(params.Filter, doc.Attributes)
+	}
+}
+
+// Retrieve dispatches the retrieval operation to the appropriate method based on retriever type
+func (r *embeddedRepository) Retrieve(ctx context.Context,
+	params types.RetrieveParams,
+) ([]*types.RetrieveResult, error) {
+	switch params.RetrieverType {
+	case types.VectorRetrieverType:
+		return r.VectorRetrieve(ctx, params)
+	case types.KeywordsRetrieverType:
+		return r.KeywordsRetrieve(ctx, params)
+	}
+
+	err := fmt.Errorf("invalid retriever type: %v", params.RetrieverType)
+	logger.GetLogger(ctx).Errorf("[Embedded] %v", err)
+	return nil, err
+}
+
+// VectorRetrieve performs approximate nearest neighbour search on the HNSW index
+func (r *embeddedRepository) VectorRetrieve(ctx context.Context,
+	params types.RetrieveParams,
+) ([]*types.RetrieveResult, error) {
+	log := logger.GetLogger(ctx)
+	dimension := len(params.Embedding)
+	log.Infof("[Embedded] Vector retrieval: dim=%d, topK=%d, threshold=%.4f",
+		dimension, params.TopK, params.Threshold)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	index, ok := r.vectors[dimension]
+	if !ok {
+		log.Warnf("[Embedded] No vector index for dimension %d, returning empty results", dimension)
+		return buildRetrieveResult(nil, types.VectorRetrieverType), nil
+	}
+
+	var results []*types.IndexWithScore
+	for _, node := range index.Search(params.Embedding, params.TopK, r.newFilter(params)) {
+		score := float64(node.score)
+		if score < params.Threshold {
+			break
+		}
+		doc := r.documents[index.Nodes[node.id].DocID]
+		results = append(results, fromEmbeddedDocument(doc, score, types.MatchTypeEmbedding))
+	}
+
+	log.Infof("[Embedded] Vector retrieval found %d results", len(results))
+	return buildRetrieveResult(results, types.VectorRetrieverType), nil
+}
+
+// KeywordsRetrieve performs BM25 search on the inverted index
+func (r *embeddedRepository) KeywordsRetrieve(ctx context.Context,
+	params types.RetrieveParams,
+) ([]*types.RetrieveResult, error) {
+	log := logger.GetLogger(ctx)
+	log.Infof("[Embedded] Keywords retrieval: query=%s, topK=%d", params.Query, params.TopK)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scores := r.keywords.Search(params.Query, r.newFilter(params))
+	results := make([]*types.IndexWithScore, 0, len(scores))
+	for docID, score := range scores {
+		results = append(results, fromEmbeddedDocument(r.documents[docID], score, types.MatchTypeKeywords))
+	}
+	slices.SortFunc(results, func(a, b *types.IndexWithScore) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if len(results) > params.TopK {
+		results = results[:params.TopK]
+	}
+
+	log.Infof("[Embedded] Keywords retrieval found %d results", len(results))
+	return buildRetrieveResult(results, types.KeywordsRetrieverType), nil
+}
+
+// CopyIndices copies index data from source knowledge base to target knowledge base
+// without recomputing embeddings
+func (r *embeddedRepository) CopyIndices(ctx context.Context,
+	sourceKnowledgeBaseID string,
+	sourceToTargetKBIDMap map[string]string,
+	sourceToTargetChunkIDMap map[string]string,
+	targetKnowledgeBaseID string,
+	dimension int,
+	knowledgeType string,
+) error {
+	log := logger.GetLogger(ctx)
+	if len(sourceToTargetChunkIDMap) == 0 {
+		log.Warn("[Embedded] Empty mapping, skipping copy")
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sources []*embeddedDocument
+	for _, doc := range r.documents {
+		if doc.KnowledgeBaseID == sourceKnowledgeBaseID && (doc.Dimension == dimension || doc.Dimension == 0) {
+			sources = append(sources, doc)
+		}
+	}
+
+	copied := 0
+	for _, source := range sources {
+		targetChunkID, ok := sourceToTargetChunkIDMap[source.ChunkID]
+		if !ok {
+			continue
+		}
+		targetKnowledgeID, ok := sourceToTargetKBIDMap[source.KnowledgeID]
+		if !ok {
+			continue
+		}
+
+		var vector []float32
+		if source.Dimension > 0 {
+			if vector, ok = r.vectors[source.Dimension].Get(source.ID); !ok {
+				log.Warnf("[Embedded] No vector found for chunk %s, skipping", source.ChunkID)
+				continue
+			}
+		}
+
+		sourceID := targetSourceID(source.SourceID, source.ChunkID, targetChunkID)
+		r.addDocument(&embeddedDocument{
+			ID:              documentID(source.Dimension, targetKnowledgeBaseID, sourceID),
+			Content:         source.Content,
+			SourceID:        sourceID,
+			SourceType:      source.SourceType,
+			ChunkID:         targetChunkID,
+			KnowledgeID:     targetKnowledgeID,
+			KnowledgeBaseID: targetKnowledgeBaseID,
+			TagID:           source.TagID,
+			IsEnabled:       true,
+			Dimension:       source.Dimension,
+			Attributes:      source.Attributes,
+		}, vector)
+		copied++
+	}
+
+	log.Infof("[Embedded] Index copy completed, total copied: %d", copied)
+	return nil
+}
+
+// targetSourceID maps the SourceID of a copied document to the target chunk.
+// Generated questions have SourceID format: {chunkID}-{questionID}, regular chunks have SourceID == ChunkID
+func targetSourceID(originalSourceID, sourceChunkID, targetChunkID string) string {
+	if originalSourceID == sourceChunkID {
+		return targetChunkID
+	}
+	if questionID, ok := strings.CutPrefix(originalSourceID, sourceChunkID+"-"); ok {
+		return fmt.Sprintf("%s-%s", targetChunkID, questionID)
+	}
+	return uuid.New().String()
+}
+
+func fromEmbeddedDocument(doc *embeddedDocument, score float64, matchType types.MatchType) *types.IndexWithScore {
+	return &types.IndexWithScore{
+		ID:              doc.ID,
+		Content:         doc.Content,
+		SourceID:        doc.SourceID,
+		SourceType:      types.SourceType(doc.SourceType),
+		ChunkID:         doc.ChunkID,
+		KnowledgeID:     doc.KnowledgeID,
+		KnowledgeBaseID: doc.KnowledgeBaseID,
+		TagID:           doc.TagID,
+		Score:           score,
+		MatchType:       matchType,
+		IsEnabled:       doc.IsEnabled,
+	}
+}
+
+func buildRetrieveResult(results []*types.IndexWithScore, retrieverType types.RetrieverType) []*types.RetrieveResult {
+	return []*types.RetrieveResult{
+		{
+			Results:             results,
+			RetrieverEngineType: types.EmbeddedRetrieverEngineType,
+			RetrieverType:       retrieverType,
+		},
+	}
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+func inSet(set map[string]struct{}, value string) bool {
+	_, ok := set[value]
+	return ok
+}
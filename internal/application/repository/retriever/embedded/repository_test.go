@@ -0,0 +1,419 @@
+package embedded
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+func newTestRepository(t *testing.T, dir string) *embeddedRepository {
+	t.Helper()
+	repo, err := NewEmbeddedRetrieveEngineRepository(dir)
+	if err != nil {
+		t.Fatalf("NewEmbeddedRetrieveEngineRepository() error = %v", err)
+	}
+	return repo.(*embeddedRepository)
+}
+
+func saveTestDocuments(t *testing.T, repo *embeddedRepository) {
+	t.Helper()
+	k1 := &types.IndexAttributes{
+		FileType:          "pdf",
+		CreatedAt:         time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		KnowledgeMetadata: map[string]any{"version": "2.1", "revision": float64(3)},
+	}
+	k2 := &types.IndexAttributes{
+		FileType:          "md",
+		CreatedAt:         time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		KnowledgeMetadata: map[string]any{"version": []any{"1.0", "2.0"}},
+	}
+	indexInfoList := []*types.IndexInfo{
+		{SourceID: "c1", ChunkID: "c1", KnowledgeID: "k1", KnowledgeBaseID: "kb1", Content: "Reset the pump controller",
+			Attributes: k1},
+		{SourceID: "c2", ChunkID: "c2", KnowledgeID: "k1", KnowledgeBaseID: "kb1", Content: "Replace the pressure valve",
+			Attributes: k1},
+		{SourceID: "c3", ChunkID: "c3", KnowledgeID: "k2", KnowledgeBaseID: "kb2", Content: "펌프 컨트롤러 초기화 방법",
+			Attributes: k2},
+	}
+	params := map[string]any{
+		"embedding": map[string][]float32{
+			"c1": {1, 0, 0},
+			"c2": {0, 1, 0},
+			"c3": {0.9, 0.1, 0},
+		},
+	}
+	if err := repo.BatchSave(context.Background(), indexInfoList, params); err != nil {
+		t.Fatalf("BatchSave() error = %v", err)
+	}
+}
+
+func retrieveChunkIDs(t *testing.T, repo *embeddedRepository, params types.RetrieveParams) []string {
+	t.Helper()
+	results, err := repo.Retrieve(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	var ids []string
+	for _, r := range results[0].Results {
+		ids = append(ids, r.ChunkID)
+	}
+	return ids
+}
+
+func TestEmbeddedRepository_Retrieve(t *testing.T) {
+	repo := newTestRepository(t, t.TempDir())
+	defer repo.Close()
+	saveTestDocuments(t, repo)
+
+	got := retrieveChunkIDs(t, repo, types.RetrieveParams{
+		RetrieverType: types.VectorRetrieverType, Embedding: []float32{1, 0, 0}, TopK: 2,
+	})
+	if len(got) != 2 || got[0] != "c1" || got[1] != "c3" {
+		t.Errorf("vector retrieve = %v, want [c1 c3]", got)
+	}
+
+	got = retrieveChunkIDs(t, repo, types.RetrieveParams{
+		RetrieverType: types.VectorRetrieverType, Embedding: []float32{1, 0, 0}, TopK: 5,
+		KnowledgeBaseIDs: []string{"kb1"}, Threshold: 0.5,
+	})
+	if len(got) != 1 || got[0] != "c1" {
+		t.Errorf("filtered vector retrieve = %v, want [c1]", got)
+	}
+
+	got = retrieveChunkIDs(t, repo, types.RetrieveParams{
+		RetrieverType: types.KeywordsRetrieverType, Query: "pump valve", TopK: 5,
+	})
+	if len(got) != 2 {
+		t.Errorf("keyword retrieve = %v, want 2 results", got)
+	}
+
+	got = retrieveChunkIDs(t, repo, types.RetrieveParams{
+		RetrieverType: types.KeywordsRetrieverType, Query: "컨트롤러", TopK: 5,
+	})
+	if len(got) != 1 || got[0] != "c3" {
+		t.Errorf("CJK keyword retrieve = %v, want [c3]", got)
+	}
+
+	if err := repo.BatchUpdateChunkEnabledStatus(context.Background(), map[string]bool{"c1": false}); err != nil {
+		t.Fatalf("BatchUpdateChunkEnabledStatus() error = %v", err)
+	}
+	if err := repo.DeleteByKnowledgeIDList(context.Background(), []string{"k2"}, 3, ""); err != nil {
+		t.Fatalf("DeleteByKnowledgeIDList() error = %v", err)
+	}
+	got = retrieveChunkIDs(t, repo, types.RetrieveParams{
+		RetrieverType: types.VectorRetrieverType, Embedding: []float32{1, 0, 0}, TopK: 5,
+	})
+	if len(got) != 1 || got[0] != "c2" {
+		t.Errorf("vector retrieve after disable and delete = %v, want [c2]", got)
+	}
+}
+
+func TestEmbeddedRepository_ReplaceKnowledgeBaseIndices(t *testing.T) {
+	repo := newTestRepository(t, t.TempDir())
+	defer repo.Close()
+	saveTestDocuments(t, repo)
+
+	// Shadow documents of the same sources and dimension must not replace the live documents
+	shadowID := types.ShadowKnowledgeBaseID("kb1")
+	shadowList := []*types.IndexInfo{
+		{SourceID: "c1", ChunkID: "c1", KnowledgeID: "k1", KnowledgeBaseID: shadowID, Content: "Reset the pump controller"},
+		{SourceID: "c2", ChunkID: "c2", KnowledgeID: "k1", KnowledgeBaseID: shadowID, Content: "Replace the pressure valve"},
+	}
+	params := map[string]any{
+		"embedding": map[string][]float32{
+			"c1": {0, 0, 1},
+			"c2": {0, 1, 0},
+		},
+	}
+	if err := repo.BatchSave(context.Background(), shadowList, params); err != nil {
+		t.Fatalf("BatchSave() error = %v", err)
+	}
+
+	vectorRetrieve := func(knowledgeBaseID string) []string {
+		return retrieveChunkIDs(t, repo, types.RetrieveParams{
+			RetrieverType: types.VectorRetrieverType, Embedding: []float32{0, 0, 1}, TopK: 5,
+			KnowledgeBaseIDs: []string{knowledgeBaseID}, Threshold: 0.5,
+		})
+	}
+	if got := vectorRetrieve("kb1"); len(got) != 0 {
+		t.Errorf("vector retrieve before replace = %v, want []", got)
+	}
+
+	if err := repo.ReplaceKnowledgeBaseIndices(context.Background(), "kb1", shadowID, 3, 3); err != nil {
+		t.Fatalf("ReplaceKnowledgeBaseIndices() error = %v", err)
+	}
+	if got := vectorRetrieve("kb1"); len(got) != 1 || got[0] != "c1" {
+		t.Errorf("vector retrieve after replace = %v, want [c1]", got)
+	}
+	if got := vectorRetrieve(shadowID); len(got) != 0 {
+		t.Errorf("shadow retrieve after replace = %v, want []", got)
+	}
+	got := retrieveChunkIDs(t, repo, types.RetrieveParams{
+		RetrieverType: types.KeywordsRetrieverType, Query: "pump valve", TopK: 5, KnowledgeBaseIDs: []string{"kb1"},
+	})
+	if len(got) != 2 {
+		t.Errorf("keyword retrieve after replace = %v, want 2 results", got)
+	}
+	if len(repo.documents) != 3 {
+		t.Errorf("documents after replace = %d, want 3", len(repo.documents))
+	}
+
+	// A retried switchover finds no shadow documents and keeps the replaced indices
+	if err := repo.ReplaceKnowledgeBaseIndices(context.Background(), "kb1", shadowID, 3, 3); err != nil {
+		t.Fatalf("retried ReplaceKnowledgeBaseIndices() error = %v", err)
+	}
+	if got := vectorRetrieve("kb1"); len(got) != 1 || got[0] != "c1" {
+		t.Errorf("vector retrieve after retried replace = %v, want [c1]", got)
+	}
+	if len(repo.documents) != 3 {
+		t.Errorf("documents after retried replace = %d, want 3", len(repo.documents))
+	}
+}
+
+func TestEmbeddedRepository_MoveByChunkIDList(t *testing.T) {
+	repo := newTestRepository(t, t.TempDir())
+	defer repo.Close()
+	saveTestDocuments(t, repo)
+
+	archiveID := types.ArchiveKnowledgeBaseID("kb1")
+	if err := repo.MoveByChunkIDList(context.Background(), []string{"c1"}, archiveID, 3, ""); err != nil {
+		t.Fatalf("MoveByChunkIDList() error = %v", err)
+	}
+
+	keywordRetrieve := func(knowledgeBaseIDs ...string) []string {
+		return retrieveChunkIDs(t, repo, types.RetrieveParams{
+			RetrieverType: types.KeywordsRetrieverType, Query: "pump valve", TopK: 5, KnowledgeBaseIDs: knowledgeBaseIDs,
+		})
+	}
+	if got := keywordRetrieve("kb1"); len(got) != 1 || got[0] != "c2" {
+		t.Errorf("keyword retrieve after move = %v, want [c2]", got)
+	}
+	if got := keywordRetrieve(archiveID); len(got) != 1 || got[0] != "c1" {
+		t.Errorf("archive retrieve after move = %v, want [c1]", got)
+	}
+
+	asOf := time.Now()
+	params := types.RetrieveParams{KnowledgeBaseIDs: []string{"kb1"}, AsOf: &asOf}
+	if got := keywordRetrieve(params.IndexKnowledgeBaseIDs()...); len(got) != 2 {
+		t.Errorf("point-in-time retrieve = %v, want 2 results", got)
+	}
+}
+
+func TestEmbeddedRepository_EstimateStorageSize(t *testing.T) {
+	repo := newTestRepository(t, t.TempDir())
+	// A generated question is indexed under its own source ID and points at the chunk it was generated from
+	indexInfoList := []*types.IndexInfo{
+		{SourceID: "c1-q1", ChunkID: "c1", KnowledgeID: "k1", KnowledgeBaseID: "kb1", Content: "How to reset the pump?"},
+	}
+	withoutVector := repo.EstimateStorageSize(context.Background(), indexInfoList, nil)
+	withVector := repo.EstimateStorageSize(context.Background(), indexInfoList, map[string]any{
+		"embedding": map[string][]float32{"c1-q1": {1, 0, 0}},
+	})
+	if want := withoutVector + 3*4 + defaultHNSWM*2*4; withVector != want {
+		t.Fatalf("EstimateStorageSize() = %d, want %d", withVector, want)
+	}
+}
+
+func TestEmbeddedRepository_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	repo := newTestRepository(t, dir)
+	saveTestDocuments(t, repo)
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened := newTestRepository(t, dir)
+	defer reopened.Close()
+	got := retrieveChunkIDs(t, reopened, types.RetrieveParams{
+		RetrieverType: types.VectorRetrieverType, Embedding: []float32{0, 1, 0}, TopK: 1,
+	})
+	if len(got) != 1 || got[0] != "c2" {
+		t.Errorf("vector retrieve after reopen = %v, want [c2]", got)
+	}
+	got = retrieveChunkIDs(t, reopened, types.RetrieveParams{
+		RetrieverType: types.KeywordsRetrieverType, Query: "pressure", TopK: 5,
+	})
+	if len(got) != 1 || got[0] != "c2" {
+		t.Errorf("keyword retrieve after reopen = %v, want [c2]", got)
+	}
+}
+
+func TestEmbeddedRepository_Flush(t *testing.T) {
+	dir := t.TempDir()
+	repo := newTestRepository(t, dir)
+	defer repo.Close()
+	saveTestDocuments(t, repo)
+	snapshotPath := filepath.Join(dir, snapshotFileName)
+
+	if err := repo.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if err := os.Remove(snapshotPath); err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+
+	// Updates that change nothing do not cause a flush
+	ctx := context.Background()
+	if err := repo.BatchUpdateChunkEnabledStatus(ctx, map[string]bool{"c1": true}); err != nil {
+		t.Fatalf("BatchUpdateChunkEnabledStatus() error = %v", err)
+	}
+	if err := repo.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if _, err := os.Stat(snapshotPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("snapshot written without changes, stat error = %v", err)
+	}
+
+	// Writes while a flush encodes the snapshot are persisted by the next flush
+	done := make(chan error, 1)
+	go func() {
+		done <- repo.Flush()
+	}()
+	if err := repo.BatchUpdateChunkEnabledStatus(ctx, map[string]bool{"c1": false}); err != nil {
+		t.Fatalf("BatchUpdateChunkEnabledStatus() error = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened := newTestRepository(t, dir)
+	defer reopened.Close()
+	if doc := reopened.documents[documentID(3, "kb1", "c1")]; doc == nil || doc.IsEnabled {
+		t.Errorf("document after reopen = %+v, want disabled c1", doc)
+	}
+}
+
+func TestEmbeddedRepository_MetadataFilter(t *testing.T) {
+	dir := t.TempDir()
+	repo := newTestRepository(t, dir)
+	saveTestDocuments(t, repo)
+	// Attributes must survive a reload from disk
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	repo = newTestRepository(t, dir)
+	defer repo.Close()
+
+	tests := []struct {
+		name   string
+		filter string
+		want   []string
+	}{
+		{"eq", `{"op":"eq","field":"knowledge.metadata.version","value":"2.1"}`, []string{"c1", "c2"}},
+		{"eq on array", `{"op":"eq","field":"knowledge.metadata.version","value":"2.0"}`, []string{"c3"}},
+		{"in", `{"op":"in","field":"knowledge.file_type","values":["md","txt"]}`, []string{"c3"}},
+		{"number range", `{"op":"range","field":"knowledge.metadata.revision","gte":2,"lt":4}`, []string{"c1", "c2"}},
+		{"date range", `{"op":"range","field":"knowledge.created_at","lt":"2024-01-01"}`, []string{"c3"}},
+		{"exists", `{"op":"exists","field":"knowledge.metadata.revision"}`, []string{"c1", "c2"}},
+		{"not", `{"op":"not","filters":[{"op":"eq","field":"knowledge.file_type","value":"pdf"}]}`, []string{"c3"}},
+		{"or", `{"op":"or","filters":[
+			{"op":"eq","field":"knowledge.metadata.version","value":"1.0"},
+			{"op":"range","field":"knowledge.metadata.revision","gt":3}
+		]}`, []string{"c3"}},
+		{"and", `{"op":"and","filters":[
+			{"op":"eq","field":"knowledge.file_type","value":"pdf"},
+			{"op":"range","field":"knowledge.created_at","gte":"2024-03-01T00:00:00Z"}
+		]}`, []string{"c1", "c2"}},
+		{"type mismatch", `{"op":"range","field":"knowledge.metadata.version","gte":1}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter types.MetadataFilter
+			if err := json.Unmarshal([]byte(tt.filter), &filter); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if err := filter.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			got := retrieveChunkIDs(t, repo, types.RetrieveParams{
+				RetrieverType: types.KeywordsRetrieverType, Query: "pump valve 펌프", TopK: 5, Filter: &filter,
+			})
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("retrieve with filter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataFilter_ValidateRejects(t *testing.T) {
+	for _, filter := range []string{
+		`{"op":"like","field":"knowledge.file_type","value":"pdf"}`,
+		`{"op":"eq","field":"knowledge.title","value":"x"}`,
+		`{"op":"eq","field":"knowledge.created_at","value":"2024-01-01"}`,
+		`{"op":"range","field":"knowledge.created_at","gte":5}`,
+		`{"op":"range","field":"knowledge.metadata.v","gte":1,"lt":"2024-01-01"}`,
+		`{"op":"in","field":"knowledge.metadata.v","values":[]}`,
+		`{"op":"eq","field":"knowledge.metadata.v","value":{"nested":true}}`,
+		`{"op":"not","filters":[]}`,
+		`{"op":"and","field":"knowledge.file_type","filters":[{"op":"exists","field":"chunk.metadata.a"}]}`,
+	} {
+		var f types.MetadataFilter
+		if err := json.Unmarshal([]byte(filter), &f); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", filter, err)
+		}
+		if err := f.Validate(); err == nil {
+			t.Errorf("Validate(%s) = nil, want error", filter)
+		}
+	}
+}
+
+func TestHNSWIndex_Recall(t *testing.T) {
+	const dimension, count, k = 32, 2000, 10
+	rng := rand.New(rand.NewPCG(1, 2))
+	randomVector := func() []float32 {
+		v := make([]float32, dimension)
+		for i := range v {
+			v[i] = rng.Float32()*2 - 1
+		}
+		return v
+	}
+
+	index := newHNSWIndex(dimension)
+	for i := range count {
+		index.Add(fmt.Sprintf("doc-%d", i), randomVector())
+	}
+
+	hits, total := 0, 0
+	for range 20 {
+		query := randomVector()
+		expected := make(map[uint32]struct{})
+		for _, node := range index.exhaustiveSearch(normalize(query), k, nil) {
+			expected[node.id] = struct{}{}
+		}
+		for _, node := range index.Search(query, k, nil) {
+			if _, ok := expected[node.id]; ok {
+				hits++
+			}
+		}
+		total += k
+	}
+	if recall := float64(hits) / float64(total); recall < 0.9 {
+		t.Errorf("HNSW recall = %.2f, want >= 0.9", recall)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("Pump-42 控制器")
+	want := []string{"pump", "42", "控制", "制器"}
+	if len(got) != len(want) {
+		t.Fatalf("tokenize() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("tokenize() = %v, want %v", got, want)
+		}
+	}
+}
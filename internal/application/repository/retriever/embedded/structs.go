@@ -0,0 +1,52 @@
+package embedded
+
+import (
+	"sync"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// embeddedDocument is an indexed chunk or generated question. Fields are exported for gob persistence.
+type embeddedDocument struct {
+	ID              string
+	Content         string
+	SourceID        string
+	SourceType      int
+	ChunkID         string
+	KnowledgeID     string
+	KnowledgeBaseID string
+	TagID           string
+	IsEnabled       bool
+	// Dimension of the embedding, 0 for documents indexed for keyword retrieval only
+	Dimension int
+	// Knowledge and chunk attributes used for metadata filtering
+	Attributes *types.IndexAttributes
+}
+
+// embeddedSnapshot is the on-disk representation of the repository
+type embeddedSnapshot struct {
+	Version   int
+	Documents map[string]*embeddedDocument
+	Vectors   map[int]*hnswIndex
+	Keywords  *bm25Index
+}
+
+type embeddedRepository struct {
+	mu sync.RWMutex
+	// Directory the index snapshot is persisted to
+	dir       string
+	documents map[string]*embeddedDocument
+	// HNSW index per embedding dimension
+	vectors  map[int]*hnswIndex
+	keywords *bm25Index
+	// changes counts the modifications of the indexes, flushed is the count persisted by the last flush
+	changes uint64
+	flushed uint64
+
+	// flushMu serializes flushes
+	flushMu sync.Mutex
+
+	stopCh    chan struct{}
+	closeOnce sync.Once
+	flushWg   sync.WaitGroup
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// filterBuilder translates a metadata filter into a SQL condition on the embeddings table.
+// Knowledge and chunk attributes are read from their own tables in the same database,
+// so the filter always reflects the current metadata without denormalizing it.
+type filterBuilder struct {
+	// bind appends a query variable and returns its placeholder
+	bind func(value any) string
+}
+
+// gormFilterExpr builds a condition using gorm "?" placeholders
+func gormFilterExpr(filter *types.MetadataFilter) (string, []interface{}) {
+	vars := make([]interface{}, 0)
+	b := filterBuilder{bind: func(value any) string {
+		vars = append(vars, value)
+		return "?"
+	}}
+	return b.build(filter), vars
+}
+
+// rawFilterExpr builds a condition using numbered "$N" placeholders, appending its variables to vars
+func rawFilterExpr(filter *types.MetadataFilter, vars *[]interface{}) string {
+	b := filterBuilder{bind: func(value any) string {
+		*vars = append(*vars, value)
+		return fmt.Sprintf("$%d", len(*vars))
+	}}
+	return b.build(filter)
+}
+
+func (b filterBuilder) build(filter *types.MetadataFilter) string {
+	switch filter.Op {
+	case types.MetadataFilterOpAnd, types.MetadataFilterOpOr:
+		parts := make([]string, 0, len(filter.Filters))
+		for _, sub := range filter.Filters {
+			parts = append(parts, b.build(sub))
+		}
+		return "(" + strings.Join(parts, " "+strings.ToUpper(string(filter.Op))+" ") + ")"
+	case types.MetadataFilterOpNot:
+		return "NOT " + b.build(filter.Filters[0])
+	}
+
+	target, key := filter.Target()
+	switch target {
+	case types.MetadataFilterTargetFileType:
+		return b.knowledgeCond(b.scalarCond("file_type", filter))
+	case types.MetadataFilterTargetCreatedAt:
+		return b.knowledgeCond(b.rangeCond(func() string { return "created_at" }, filter.Bounds()))
+	case types.MetadataFilterTargetKnowledgeMetadata:
+		return b.knowledgeCond(b.metadataCond(key, filter))
+	case types.MetadataFilterTargetChunkMetadata:
+		return b.chunkCond(b.metadataCond(key, filter))
+	default:
+		return "FALSE"
+	}
+}
+
+func (b filterBuilder) knowledgeCond(cond string) string {
+	return fmt.Sprintf("knowledge_id IN (SELECT id FROM knowledges WHERE %s)", cond)
+}
+
+func (b filterBuilder) chunkCond(cond string) string {
+	return fmt.Sprintf("chunk_id IN (SELECT id FROM chunks WHERE %s)", cond)
+}
+
+// scalarCond compares a plain column with the eq/in values
+func (b filterBuilder) scalarCond(column string, filter *types.MetadataFilter) string {
+	values := filter.Values
+	if filter.Op == types.MetadataFilterOpEq {
+		values = []any{filter.Value}
+	}
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = b.bind(v)
+	}
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", "))
+}
+
+// rangeCond compares an expression with the range bounds, the expression is rebuilt
+// for every bound so each of its placeholders is bound once
+func (b filterBuilder) rangeCond(expr func() string, bounds []types.MetadataFilterBound) string {
+	parts := make([]string, 0, len(bounds))
+	for _, bound := range bounds {
+		column := expr()
+		if bound.IsTime {
+			parts = append(parts, fmt.Sprintf("%s %s %s", column, sqlOperator(bound.Op), b.bind(bound.Time)))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s %s %s", column, sqlOperator(bound.Op), b.bind(bound.Number)))
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// metadataCond evaluates a leaf filter on a key of the json metadata column
+func (b filterBuilder) metadataCond(key string, filter *types.MetadataFilter) string {
+	switch filter.Op {
+	case types.MetadataFilterOpEq, types.MetadataFilterOpIn:
+		values := filter.Values
+		if filter.Op == types.MetadataFilterOpEq {
+			values = []any{filter.Value}
+		}
+		// Containment matches both scalar values and arrays containing the value
+		parts := make([]string, 0, len(values)*2)
+		for _, v := range values {
+			scalar, _ := json.Marshal(map[string]any{key: v})
+			array, _ := json.Marshal(map[string]any{key: []any{v}})
+			parts = append(parts,
+				fmt.Sprintf("metadata::jsonb @> %s::jsonb", b.bind(string(scalar))),
+				fmt.Sprintf("metadata::jsonb @> %s::jsonb", b.bind(string(array))),
+			)
+		}
+		return "(" + strings.Join(parts, " OR ") + ")"
+	case types.MetadataFilterOpExists:
+		return fmt.Sprintf("COALESCE(jsonb_typeof(metadata::jsonb -> %s::text), 'null') <> 'null'", b.bind(key))
+	case types.MetadataFilterOpRange:
+		bounds := filter.Bounds()
+		if len(bounds) == 0 {
+			return "FALSE"
+		}
+		// CASE guards the cast so values of another type never match instead of failing the query
+		column := func() string {
+			if bounds[0].IsTime {
+				return fmt.Sprintf(
+					"(CASE WHEN metadata::jsonb ->> %s::text ~ '^\\d{4}-\\d{2}-\\d{2}' THEN (metadata::jsonb ->> %s::text)::timestamptz END)",
+					b.bind(key), b.bind(key),
+				)
+			}
+			return fmt.Sprintf(
+				"(CASE WHEN jsonb_typeof(metadata::jsonb -> %s::text) = 'number' THEN (metadata::jsonb ->> %s::text)::numeric END)",
+				b.bind(key), b.bind(key),
+			)
+		}
+		return b.rangeCond(column, bounds)
+	default:
+		return "FALSE"
+	}
+}
+
+func sqlOperator(op string) string {
+	switch op {
+	case "gt":
+		return ">"
+	case "gte":
+		return ">="
+	case "lt":
+		return "<"
+	default:
+		return "<="
+	}
+}
@@ -119,6 +119,26 @@ func (g *pgRepository) DeleteByChunkIDList(ctx context.Context, chunkIDList []st
 	return nil
 }
 
+// MoveByChunkIDList moves the indices of chunks to another knowledge base ID
+func (g *pgRepository) MoveByChunkIDList(ctx context.Context,
+	chunkIDList []string, targetKnowledgeBaseID string, dimension int, knowledgeType string,
+) error {
+	if len(chunkIDList) == 0 {
+		return nil
+	}
+	logger.GetLogger(ctx).Infof("[Postgres] Moving indices by chunk IDs to %s, count: %d",
+		targetKnowledgeBaseID, len(chunkIDList))
+	result := g.db.WithContext(ctx).Model(&pgVector{}).
+		Where("chunk_id IN ?", chunkIDList).
+		Update("knowledge_base_id", targetKnowledgeBaseID)
+	if result.Error != nil {
+		logger.GetLogger(ctx).Errorf("[Postgres] Failed to move indices by chunk IDs: %v", result.Error)
+		return result.Error
+	}
+	logger.GetLogger(ctx).Infof("[Postgres] Successfully moved %d indices by chunk IDs", result.RowsAffected)
+	return nil
+}
+
 // DeleteBySourceIDList deletes indices by source IDs
 func (g *pgRepository) DeleteBySourceIDList(ctx context.Context, sourceIDList []string, dimension int, knowledgeType string) error {
 	if len(sourceIDList) == 0 {
@@ -146,6 +166,56 @@ func (g *pgRepository) DeleteByKnowledgeIDList(ctx context.Context, knowledgeIDL
 	return nil
 }
 
+// DeleteByKnowledgeBaseID deletes all indices of a knowledge base
+func (g *pgRepository) DeleteByKnowledgeBaseID(ctx context.Context, knowledgeBaseID string, dimension int) error {
+	logger.GetLogger(ctx).Infof("[Postgres] Deleting indices by knowledge base ID: %s", knowledgeBaseID)
+	result := g.db.WithContext(ctx).Where("knowledge_base_id = ?", knowledgeBaseID).Delete(&pgVector{})
+	if result.Error != nil {
+		logger.GetLogger(ctx).Errorf("[Postgres] Failed to delete indices by knowledge base ID: %v", result.Error)
+		return result.Error
+	}
+	logger.GetLogger(ctx).Infof("[Postgres] Successfully deleted %d indices by knowledge base ID", result.RowsAffected)
+	return nil
+}
+
+// ReplaceKnowledgeBaseIndices deletes the indices of a knowledge base and moves the shadow indices
+// into it in one transaction, so searches see either the old or the new indices.
+// Without shadow indices the indices have already been replaced, so a retried switchover keeps them
+func (g *pgRepository) ReplaceKnowledgeBaseIndices(ctx context.Context,
+	knowledgeBaseID string, shadowKnowledgeBaseID string, oldDimension int, newDimension int,
+) error {
+	log := logger.GetLogger(ctx)
+	log.Infof("[Postgres] Replacing indices of knowledge base %s with %s", knowledgeBaseID, shadowKnowledgeBaseID)
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var shadows int64
+		if err := tx.Model(&pgVector{}).Where("knowledge_base_id = ?", shadowKnowledgeBaseID).
+			Count(&shadows).Error; err != nil {
+			return err
+		}
+		if shadows == 0 {
+			log.Infof("[Postgres] No shadow indices of knowledge base %s, already replaced", knowledgeBaseID)
+			return nil
+		}
+		deleted := tx.Where("knowledge_base_id = ?", knowledgeBaseID).Delete(&pgVector{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		moved := tx.Model(&pgVector{}).
+			Where("knowledge_base_id = ?", shadowKnowledgeBaseID).
+			Update("knowledge_base_id", knowledgeBaseID)
+		if moved.Error != nil {
+			return moved.Error
+		}
+		log.Infof("[Postgres] Deleted %d indices, moved %d shadow indices", deleted.RowsAffected, moved.RowsAffected)
+		return nil
+	})
+	if err != nil {
+		log.Errorf("[Postgres] Failed to replace knowledge base indices: %v", err)
+		return err
+	}
+	return nil
+}
+
 // Retrieve handles retrieval requests and routes to appropriate method
 func (g *pgRepository) Retrieve(ctx context.Context, params types.RetrieveParams) ([]*types.RetrieveResult, error) {
 	logger.GetLogger(ctx).Debugf("[Postgres] Processing retrieval request of type: %s", params.RetrieverType)
@@ -193,6 +263,12 @@ func (g *pgRepository) KeywordsRetrieve(ctx context.Context,
 			Values: common.ToInterfaceSlice(params.TagIDs),
 		})
 	}
+	// Metadata filter is evaluated against the knowledges and chunks tables
+	if params.Filter != nil {
+		filterSQL, filterVars := gormFilterExpr(params.Filter)
+		logger.GetLogger(ctx).Debugf("[Postgres] Filtering by metadata: %s", filterSQL)
+		conds = append(conds, clause.Expr{SQL: filterSQL, Vars: filterVars})
+	}
 	conds = append(conds, clause.Expr{
 		SQL:  "id @@@ paradedb.match(field => 'content', value => ?, distance => 1)",
 		Vars: []interface{}{params.Query},
@@ -318,6 +394,13 @@ func (g *pgRepository) VectorRetrieve(ctx context.Context,
 			strings.Join(placeholders, ", ")))
 	}
 
+	// Metadata filter is evaluated against the knowledges and chunks tables
+	if params.Filter != nil {
+		filterSQL := rawFilterExpr(params.Filter, &allVars)
+		logger.GetLogger(ctx).Debugf("[Postgres] Filtering vector search by metadata: %s", filterSQL)
+		whereParts = append(whereParts, filterSQL)
+	}
+
 	// is_enabled filter
 	whereParts = append(whereParts, fmt.Sprintf("(is_enabled IS NULL OR is_enabled = $%d)", len(allVars)+1))
 	allVars = append(allVars, true)
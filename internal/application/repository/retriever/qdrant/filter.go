@@ -0,0 +1,171 @@
+package qdrant
+
+import (
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Payload fields holding the attributes denormalized for metadata filtering
+const (
+	fieldFileType           = "file_type"
+	fieldKnowledgeCreatedAt = "knowledge_created_at"
+	fieldKnowledgeMetadata  = "knowledge_metadata"
+	fieldChunkMetadata      = "chunk_metadata"
+)
+
+// attributePayload returns the payload entries of the index attributes
+func attributePayload(attrs *types.IndexAttributes) map[string]any {
+	payload := make(map[string]any)
+	if attrs == nil {
+		return payload
+	}
+	if attrs.FileType != "" {
+		payload[fieldFileType] = attrs.FileType
+	}
+	if !attrs.CreatedAt.IsZero() {
+		// Qdrant parses RFC3339 strings for datetime range conditions
+		payload[fieldKnowledgeCreatedAt] = attrs.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if len(attrs.KnowledgeMetadata) > 0 {
+		payload[fieldKnowledgeMetadata] = attrs.KnowledgeMetadata
+	}
+	if len(attrs.ChunkMetadata) > 0 {
+		payload[fieldChunkMetadata] = attrs.ChunkMetadata
+	}
+	return payload
+}
+
+// copyAttributePayload copies the attribute fields of a source point payload into a new payload
+func copyAttributePayload(dst, src map[string]*qdrant.Value) {
+	for _, field := range []string{fieldFileType, fieldKnowledgeCreatedAt, fieldKnowledgeMetadata, fieldChunkMetadata} {
+		if value, ok := src[field]; ok {
+			dst[field] = value
+		}
+	}
+}
+
+// filterCondition translates a metadata filter into a condition on the denormalized payload
+func filterCondition(filter *types.MetadataFilter) *qdrant.Condition {
+	switch filter.Op {
+	case types.MetadataFilterOpAnd, types.MetadataFilterOpOr, types.MetadataFilterOpNot:
+		conditions := make([]*qdrant.Condition, 0, len(filter.Filters))
+		for _, sub := range filter.Filters {
+			conditions = append(conditions, filterCondition(sub))
+		}
+		switch filter.Op {
+		case types.MetadataFilterOpAnd:
+			return qdrant.NewFilterAsCondition(&qdrant.Filter{Must: conditions})
+		case types.MetadataFilterOpOr:
+			return qdrant.NewFilterAsCondition(&qdrant.Filter{Should: conditions})
+		default:
+			return qdrant.NewFilterAsCondition(&qdrant.Filter{MustNot: conditions})
+		}
+	}
+
+	field := filterPayloadField(filter)
+	switch filter.Op {
+	case types.MetadataFilterOpEq:
+		return matchCondition(field, []any{filter.Value})
+	case types.MetadataFilterOpIn:
+		return matchCondition(field, filter.Values)
+	case types.MetadataFilterOpExists:
+		return qdrant.NewFilterAsCondition(&qdrant.Filter{
+			MustNot: []*qdrant.Condition{qdrant.NewIsEmpty(field)},
+		})
+	case types.MetadataFilterOpRange:
+		return rangeCondition(field, filter.Bounds())
+	default:
+		return matchNothing()
+	}
+}
+
+func filterPayloadField(filter *types.MetadataFilter) string {
+	target, key := filter.Target()
+	switch target {
+	case types.MetadataFilterTargetFileType:
+		return fieldFileType
+	case types.MetadataFilterTargetCreatedAt:
+		return fieldKnowledgeCreatedAt
+	case types.MetadataFilterTargetKnowledgeMetadata:
+		return fieldKnowledgeMetadata + "." + key
+	default:
+		return fieldChunkMetadata + "." + key
+	}
+}
+
+// matchCondition matches any of the values. Numbers are matched with a closed range so
+// both integer and float payload values match.
+func matchCondition(field string, values []any) *qdrant.Condition {
+	conditions := make([]*qdrant.Condition, 0, len(values))
+	keywords := make([]string, 0, len(values))
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			keywords = append(keywords, v)
+		case bool:
+			conditions = append(conditions, qdrant.NewMatchBool(field, v))
+		case float64:
+			conditions = append(conditions, qdrant.NewRange(field, &qdrant.Range{Gte: &v, Lte: &v}))
+		}
+	}
+	if len(keywords) > 0 {
+		conditions = append(conditions, qdrant.NewMatchKeywords(field, keywords...))
+	}
+	switch len(conditions) {
+	case 0:
+		return matchNothing()
+	case 1:
+		return conditions[0]
+	default:
+		return qdrant.NewFilterAsCondition(&qdrant.Filter{Should: conditions})
+	}
+}
+
+func rangeCondition(field string, bounds []types.MetadataFilterBound) *qdrant.Condition {
+	if len(bounds) == 0 {
+		return matchNothing()
+	}
+	if bounds[0].IsTime {
+		r := &qdrant.DatetimeRange{}
+		for _, bound := range bounds {
+			ts := timestamppb.New(bound.Time)
+			switch bound.Op {
+			case "gt":
+				r.Gt = ts
+			case "gte":
+				r.Gte = ts
+			case "lt":
+				r.Lt = ts
+			case "lte":
+				r.Lte = ts
+			}
+		}
+		return qdrant.NewDatetimeRange(field, r)
+	}
+	r := &qdrant.Range{}
+	for _, bound := range bounds {
+		number := bound.Number
+		switch bound.Op {
+		case "gt":
+			r.Gt = &number
+		case "gte":
+			r.Gte = &number
+		case "lt":
+			r.Lt = &number
+		case "lte":
+			r.Lte = &number
+		}
+	}
+	return qdrant.NewRange(field, r)
+}
+
+// matchNothing returns a contradictory condition, used for filters that cannot match
+func matchNothing() *qdrant.Condition {
+	return qdrant.NewFilterAsCondition(&qdrant.Filter{
+		Must:    []*qdrant.Condition{qdrant.NewIsEmpty(fieldChunkID)},
+		MustNot: []*qdrant.Condition{qdrant.NewIsEmpty(fieldChunkID)},
+	})
+}
@@ -0,0 +1,674 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"github.com/google/uuid"
+	"github.com/qdrant/go-client/qdrant"
+)
+
+const (
+	envQdrantMultiVectorCollection   = "QDRANT_MULTIVECTOR_COLLECTION"
+	defaultMultiVectorCollectionName = "weknora_multivector"
+	fieldTokenEmbedding              = "token_embedding"
+	// Average number of content bytes per token, used to estimate storage before embedding
+	estimatedBytesPerToken = 4
+)
+
+// qdrantMultiVectorRepository stores per-token (late-interaction) embeddings of each chunk
+// as a Qdrant multi-vector and scores them with MaxSim
+type qdrantMultiVectorRepository struct {
+	client             *qdrant.Client
+	collectionBaseName string
+	// Cache for initialized collections (dimension -> true)
+	initializedCollections sync.Map
+}
+
+// NewQdrantMultiVectorRetrieveEngineRepository creates and initializes a new Qdrant multi-vector repository
+func NewQdrantMultiVectorRetrieveEngineRepository(client *qdrant.Client) interfaces.RetrieveEngineRepository {
+	log := logger.GetLogger(context.Background())
+	log.Info("[QdrantMultiVector] Initializing Qdrant multi-vector retriever engine repository")
+
+	collectionBaseName := os.Getenv(envQdrantMultiVectorCollection)
+	if collectionBaseName == "" {
+		log.Warn("[QdrantMultiVector] QDRANT_MULTIVECTOR_COLLECTION environment variable not set, " +
+			"using default collection name")
+		collectionBaseName = defaultMultiVectorCollectionName
+	}
+
+	log.Info("[QdrantMultiVector] Successfully initialized repository")
+	return &qdrantMultiVectorRepository{
+		client:             client,
+		collectionBaseName: collectionBaseName,
+	}
+}
+
+// getCollectionName returns the collection name for a specific token dimension
+func (q *qdrantMultiVectorRepository) getCollectionName(dimension int) string {
+	return fmt.Sprintf("%s_%d", q.collectionBaseName, dimension)
+}
+
+// isOwnCollection reports whether the collection was created by this repository
+func (q *qdrantMultiVectorRepository) isOwnCollection(collectionName string) bool {
+	suffix, ok := strings.CutPrefix(collectionName, q.collectionBaseName+"_")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
+
+// listCollections returns all collections created by this repository
+func (q *qdrantMultiVectorRepository) listCollections(ctx context.Context) ([]string, error) {
+	collections, err := q.client.ListCollections(ctx)
+	if err != nil {
+		logger.GetLogger(ctx).Errorf("[QdrantMultiVector] Failed to list collections: %v", err)
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	result := make([]string, 0, len(collections))
+	for _, collectionName := range collections {
+		if q.isOwnCollection(collectionName) {
+			result = append(result, collectionName)
+		}
+	}
+	return result, nil
+}
+
+// ensureCollection ensures the multi-vector collection exists for the given token dimension
+func (q *qdrantMultiVectorRepository) ensureCollection(ctx context.Context, dimension int) error {
+	collectionName := q.getCollectionName(dimension)
+
+	// Check cache first
+	if _, ok := q.initializedCollections.Load(dimension); ok {
+		return nil
+	}
+
+	log := logger.GetLogger(ctx)
+
+	exists, err := q.client.CollectionExists(ctx, collectionName)
+	if err != nil {
+		log.Errorf("[QdrantMultiVector] Failed to check collection existence: %v", err)
+		return fmt.Errorf("failed to check collection existence: %w", err)
+	}
+
+	if !exists {
+		log.Infof("[QdrantMultiVector] Creating collection %s with token dimension %d", collectionName, dimension)
+
+		err = q.client.CreateCollection(ctx, &qdrant.CreateCollection{
+			CollectionName: collectionName,
+			VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
+				Size:     uint64(dimension),
+				Distance: qdrant.Distance_Cosine,
+				MultivectorConfig: &qdrant.MultiVectorConfig{
+					Comparator: qdrant.MultiVectorComparator_MaxSim,
+				},
+			}),
+		})
+		if err != nil {
+			log.Errorf("[QdrantMultiVector] Failed to create collection: %v", err)
+			return fmt.Errorf("failed to create collection: %w", err)
+		}
+
+		// Create payload indexes for filtering
+		indexFields := []string{fieldChunkID, fieldKnowledgeID, fieldKnowledgeBaseID, fieldSourceID, fieldTagID}
+		for _, field := range indexFields {
+			_, err = q.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
+				CollectionName: collectionName,
+				FieldName:      field,
+				FieldType:      qdrant.FieldType_FieldTypeKeyword.Enum(),
+			})
+			if err != nil {
+				log.Warnf("[QdrantMultiVector] Failed to create index for field %s: %v", field, err)
+			}
+		}
+
+		_, err = q.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
+			CollectionName: collectionName,
+			FieldName:      fieldIsEnabled,
+			FieldType:      qdrant.FieldType_FieldTypeBool.Enum(),
+		})
+		if err != nil {
+			log.Warnf("[QdrantMultiVector] Failed to create index for field %s: %v", fieldIsEnabled, err)
+		}
+
+		log.Infof("[QdrantMultiVector] Successfully created collection %s", collectionName)
+	}
+
+	q.initializedCollections.Store(dimension, true)
+	return nil
+}
+
+func (q *qdrantMultiVectorRepository) EngineType() types.RetrieverEngineType {
+	return types.QdrantMultiVectorRetrieverEngineType
+}
+
+func (q *qdrantMultiVectorRepository) Support() []types.RetrieverType {
+	return []types.RetrieverType{types.MultiVectorRetrieverType}
+}
+
+// EstimateStorageSize calculates the estimated storage size for a list of indices.
+// The number of tokens is estimated from the content length since token embeddings are not computed yet.
+func (q *qdrantMultiVectorRepository) EstimateStorageSize(ctx context.Context,
+	indexInfoList []*types.IndexInfo, params map[string]any,
+) int64 {
+	dimension := 0
+	if embeddingMap, ok := params[fieldEmbedding].(map[string][]float32); ok {
+		for _, embedding := range embeddingMap {
+			dimension = len(embedding)
+			break
+		}
+	}
+
+	var totalStorageSize int64
+	for _, indexInfo := range indexInfoList {
+		payloadSizeBytes := int64(len(indexInfo.Content) + len(indexInfo.SourceID) + len(indexInfo.ChunkID) +
+			len(indexInfo.KnowledgeID) + len(indexInfo.KnowledgeBaseID) + 8)
+		tokenCount := int64(len(indexInfo.Content)/estimatedBytesPerToken + 1)
+		// Token vectors plus the HNSW graph links of every token vector (M=16)
+		vectorSizeBytes := tokenCount * int64(dimension) * 4
+		hnswIndexBytes := tokenCount * 16 * 2 * 4
+		totalStorageSize += payloadSizeBytes + vectorSizeBytes + hnswIndexBytes + 24
+	}
+	logger.GetLogger(ctx).Infof(
+		"[QdrantMultiVector] Storage size for %d indices: %d bytes", len(indexInfoList), totalStorageSize,
+	)
+	return totalStorageSize
+}
+
+// Save stores a single multi-vector point
+func (q *qdrantMultiVectorRepository) Save(ctx context.Context,
+	embedding *types.IndexInfo,
+	additionalParams map[string]any,
+) error {
+	return q.BatchSave(ctx, []*types.IndexInfo{embedding}, additionalParams)
+}
+
+// BatchSave stores multiple multi-vector points, grouped by token dimension
+func (q *qdrantMultiVectorRepository) BatchSave(ctx context.Context,
+	embeddingList []*types.IndexInfo, additionalParams map[string]any,
+) error {
+	log := logger.GetLogger(ctx)
+	if len(embeddingList) == 0 {
+		log.Warn("[QdrantMultiVector] Empty list provided to BatchSave, skipping")
+		return nil
+	}
+
+	tokenEmbeddingMap, _ := additionalParams[fieldTokenEmbedding].(map[string][][]float32)
+	pointsByDimension := make(map[int][]*qdrant.PointStruct)
+	for _, embedding := range embeddingList {
+		tokenEmbeddings := tokenEmbeddingMap[embedding.SourceID]
+		if len(tokenEmbeddings) == 0 {
+			log.Warnf("[QdrantMultiVector] Skipping empty token embeddings for chunk ID: %s", embedding.ChunkID)
+			continue
+		}
+
+		dimension := len(tokenEmbeddings[0])
+		point := &qdrant.PointStruct{
+			Id:      qdrant.NewID(uuid.New().String()),
+			Vectors: qdrant.NewVectorsMulti(tokenEmbeddings),
+			Payload: createPayload(toQdrantVectorEmbedding(embedding, nil)),
+		}
+		pointsByDimension[dimension] = append(pointsByDimension[dimension], point)
+	}
+
+	if len(pointsByDimension) == 0 {
+		log.Warn("[QdrantMultiVector] No valid points to save after filtering")
+		return nil
+	}
+
+	totalSaved := 0
+	for dimension, points := range pointsByDimension {
+		if err := q.ensureCollection(ctx, dimension); err != nil {
+			return err
+		}
+
+		collectionName := q.getCollectionName(dimension)
+		_, err := q.client.Upsert(ctx, &qdrant.UpsertPoints{
+			CollectionName: collectionName,
+			Points:         points,
+		})
+		if err != nil {
+			log.Errorf("[QdrantMultiVector] Failed to execute batch operation for dimension %d: %v", dimension, err)
+			return fmt.Errorf("failed to batch save (dimension %d): %w", dimension, err)
+		}
+		totalSaved += len(points)
+	}
+
+	log.Infof("[QdrantMultiVector] Successfully batch saved %d indices", totalSaved)
+	return nil
+}
+
+// deleteByField removes points matching any of the values from all multi-vector collections.
+// The token dimension may differ from the single-vector dimension, so all collections are searched.
+func (q *qdrantMultiVectorRepository) deleteByField(ctx context.Context, field string, values []string) error {
+	log := logger.GetLogger(ctx)
+	if len(values) == 0 {
+		log.Warnf("[QdrantMultiVector] Empty %s list provided for deletion, skipping", field)
+		return nil
+	}
+
+	collections, err := q.listCollections(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, collectionName := range collections {
+		_, err := q.client.Delete(ctx, &qdrant.DeletePoints{
+			CollectionName: collectionName,
+			Points: qdrant.NewPointsSelectorFilter(&qdrant.Filter{
+				Must: []*qdrant.Condition{
+					qdrant.NewMatchKeywords(field, values...),
+				},
+			}),
+		})
+		if err != nil {
+			log.Errorf("[QdrantMultiVector] Failed to delete by %s from %s: %v", field, collectionName, err)
+			return fmt.Errorf("failed to delete by %s: %w", field, err)
+		}
+	}
+
+	log.Infof("[QdrantMultiVector] Successfully deleted documents by %s, count: %d", field, len(values))
+	return nil
+}
+
+// DeleteByChunkIDList removes points based on chunk IDs
+func (q *qdrantMultiVectorRepository) DeleteByChunkIDList(ctx context.Context,
+	chunkIDList []string, dimension int, knowledgeType string,
+) error {
+	return q.deleteByField(ctx, fieldChunkID, chunkIDList)
+}
+
+// MoveByChunkIDList moves the points of chunks in all multi-vector collections to another knowledge base ID
+func (q *qdrantMultiVectorRepository) MoveByChunkIDList(ctx context.Context,
+	chunkIDList []string, targetKnowledgeBaseID string, dimension int, knowledgeType string,
+) error {
+	if len(chunkIDList) == 0 {
+		return nil
+	}
+	collections, err := q.listCollections(ctx)
+	if err != nil {
+		return err
+	}
+	for _, collectionName := range collections {
+		_, err := q.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+			CollectionName: collectionName,
+			Wait:           qdrant.PtrOf(true),
+			Payload:        qdrant.NewValueMap(map[string]any{fieldKnowledgeBaseID: targetKnowledgeBaseID}),
+			PointsSelector: qdrant.NewPointsSelectorFilter(&qdrant.Filter{
+				Must: []*qdrant.Condition{
+					qdrant.NewMatchKeywords(fieldChunkID, chunkIDList...),
+				},
+			}),
+		})
+		if err != nil {
+			logger.GetLogger(ctx).Errorf("[QdrantMultiVector] Failed to move indices in %s: %v", collectionName, err)
+			return fmt.Errorf("failed to move by chunk IDs: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteByKnowledgeIDList removes points based on knowledge IDs
+func (q *qdrantMultiVectorRepository) DeleteByKnowledgeIDList(ctx context.Context,
+	knowledgeIDList []string, dimension int, knowledgeType string,
+) error {
+	return q.deleteByField(ctx, fieldKnowledgeID, knowledgeIDList)
+}
+
+// DeleteBySourceIDList removes points based on source IDs
+func (q *qdrantMultiVectorRepository) DeleteBySourceIDList(ctx context.Context,
+	sourceIDList []string, dimension int, knowledgeType string,
+) error {
+	return q.deleteByField(ctx, fieldSourceID, sourceIDList)
+}
+
+// DeleteByKnowledgeBaseID removes all points of a knowledge base
+func (q *qdrantMultiVectorRepository) DeleteByKnowledgeBaseID(ctx context.Context,
+	knowledgeBaseID string, dimension int,
+) error {
+	return q.deleteByField(ctx, fieldKnowledgeBaseID, []string{knowledgeBaseID})
+}
+
+// ReplaceKnowledgeBaseIndices deletes the points of a knowledge base and relabels its shadow points
+// in all multi-vector collections, the token dimension does not follow the single-vector dimension.
+// Without shadow points the indices have already been replaced, so a retried switchover keeps them.
+func (q *qdrantMultiVectorRepository) ReplaceKnowledgeBaseIndices(ctx context.Context,
+	knowledgeBaseID string, shadowKnowledgeBaseID string, oldDimension int, newDimension int,
+) error {
+	log := logger.GetLogger(ctx)
+	collections, err := q.listCollections(ctx)
+	if err != nil {
+		return err
+	}
+
+	shadowFilter := &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewMatchKeyword(fieldKnowledgeBaseID, shadowKnowledgeBaseID),
+		},
+	}
+	var shadows uint64
+	for _, collectionName := range collections {
+		count, err := q.client.Count(ctx, &qdrant.CountPoints{
+			CollectionName: collectionName,
+			Filter:         shadowFilter,
+			Exact:          qdrant.PtrOf(true),
+		})
+		if err != nil {
+			log.Errorf("[QdrantMultiVector] Failed to count shadow indices in %s: %v", collectionName, err)
+			return fmt.Errorf("failed to count shadow indices: %w", err)
+		}
+		shadows += count
+	}
+	if shadows == 0 {
+		log.Infof("[QdrantMultiVector] No shadow indices of knowledge base %s, already replaced", knowledgeBaseID)
+		return nil
+	}
+
+	for _, collectionName := range collections {
+		_, err := q.client.Delete(ctx, &qdrant.DeletePoints{
+			CollectionName: collectionName,
+			Wait:           qdrant.PtrOf(true),
+			Points: qdrant.NewPointsSelectorFilter(&qdrant.Filter{
+				Must: []*qdrant.Condition{
+					qdrant.NewMatchKeyword(fieldKnowledgeBaseID, knowledgeBaseID),
+				},
+			}),
+		})
+		if err != nil {
+			log.Errorf("[QdrantMultiVector] Failed to delete indices from %s: %v", collectionName, err)
+			return fmt.Errorf("failed to delete by knowledge base ID: %w", err)
+		}
+	}
+	for _, collectionName := range collections {
+		_, err := q.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+			CollectionName: collectionName,
+			Wait:           qdrant.PtrOf(true),
+			Payload:        qdrant.NewValueMap(map[string]any{fieldKnowledgeBaseID: knowledgeBaseID}),
+			PointsSelector: qdrant.NewPointsSelectorFilter(shadowFilter),
+		})
+		if err != nil {
+			log.Errorf("[QdrantMultiVector] Failed to move shadow indices in %s: %v", collectionName, err)
+			return fmt.Errorf("failed to move shadow indices: %w", err)
+		}
+	}
+
+	log.Infof("[QdrantMultiVector] Replaced indices of knowledge base %s", knowledgeBaseID)
+	return nil
+}
+
+// setPayloadByChunkIDs sets the payload of the given chunks in all multi-vector collections
+func (q *qdrantMultiVectorRepository) setPayloadByChunkIDs(ctx context.Context,
+	payload map[string]any, chunkIDs []string,
+) error {
+	collections, err := q.listCollections(ctx)
+	if err != nil {
+		return err
+	}
+	for _, collectionName := range collections {
+		_, err := q.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+			CollectionName: collectionName,
+			Payload:        qdrant.NewValueMap(payload),
+			PointsSelector: qdrant.NewPointsSelectorFilter(&qdrant.Filter{
+				Must: []*qdrant.Condition{
+					qdrant.NewMatchKeywords(fieldChunkID, chunkIDs...),
+				},
+			}),
+		})
+		if err != nil {
+			logger.GetLogger(ctx).Warnf("[QdrantMultiVector] Failed to update payload in %s: %v", collectionName, err)
+		}
+	}
+	return nil
+}
+
+// BatchUpdateChunkEnabledStatus updates the enabled status of chunks in batch
+func (q *qdrantMultiVectorRepository) BatchUpdateChunkEnabledStatus(ctx context.Context,
+	chunkStatusMap map[string]bool,
+) error {
+	if len(chunkStatusMap) == 0 {
+		logger.GetLogger(ctx).Warn("[QdrantMultiVector] Empty chunk status map provided, skipping")
+		return nil
+	}
+
+	statusGroups := make(map[bool][]string)
+	for chunkID, enabled := range chunkStatusMap {
+		statusGroups[enabled] = append(statusGroups[enabled], chunkID)
+	}
+	for enabled, chunkIDs := range statusGroups {
+		if err := q.setPayloadByChunkIDs(ctx, map[string]any{fieldIsEnabled: enabled}, chunkIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchUpdateChunkTagID updates the tag ID of chunks in batch
+func (q *qdrantMultiVectorRepository) BatchUpdateChunkTagID(ctx context.Context,
+	chunkTagMap map[string]string,
+) error {
+	if len(chunkTagMap) == 0 {
+		logger.GetLogger(ctx).Warn("[QdrantMultiVector] Empty chunk tag map provided, skipping")
+		return nil
+	}
+
+	tagGroups := make(map[string][]string)
+	for chunkID, tagID := range chunkTagMap {
+		tagGroups[tagID] = append(tagGroups[tagID], chunkID)
+	}
+	for tagID, chunkIDs := range tagGroups {
+		if err := q.setPayloadByChunkIDs(ctx, map[string]any{fieldTagID: tagID}, chunkIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Retrieve performs multi-vector retrieval scored by MaxSim.
+// The raw MaxSim score is the sum of the best cosine similarity of every query token, it is
+// divided by the number of query tokens so that scores and thresholds stay in the cosine range.
+func (q *qdrantMultiVectorRepository) Retrieve(ctx context.Context,
+	params types.RetrieveParams,
+) ([]*types.RetrieveResult, error) {
+	log := logger.GetLogger(ctx)
+	if params.RetrieverType != types.MultiVectorRetrieverType {
+		err := fmt.Errorf("invalid retriever type: %v", params.RetrieverType)
+		log.Errorf("[QdrantMultiVector] %v", err)
+		return nil, err
+	}
+	if len(params.TokenEmbeddings) == 0 {
+		return nil, fmt.Errorf("empty query token embeddings")
+	}
+
+	tokenCount := len(params.TokenEmbeddings)
+	dimension := len(params.TokenEmbeddings[0])
+	log.Infof("[QdrantMultiVector] MaxSim retrieval: tokens=%d, dim=%d, topK=%d, threshold=%.4f",
+		tokenCount, dimension, params.TopK, params.Threshold)
+
+	collectionName := q.getCollectionName(dimension)
+	exists, err := q.client.CollectionExists(ctx, collectionName)
+	if err != nil {
+		log.Errorf("[QdrantMultiVector] Failed to check collection existence: %v", err)
+		return nil, fmt.Errorf("failed to check collection: %w", err)
+	}
+	if !exists {
+		log.Warnf("[QdrantMultiVector] Collection %s does not exist, returning empty results", collectionName)
+		return q.buildRetrieveResult(nil), nil
+	}
+
+	limit := uint64(params.TopK)
+	scoreThreshold := float32(params.Threshold * float64(tokenCount))
+	searchResult, err := q.client.Query(ctx, &qdrant.QueryPoints{
+		CollectionName: collectionName,
+		Query:          qdrant.NewQueryMulti(params.TokenEmbeddings),
+		Filter:         getBaseFilter(params),
+		Limit:          &limit,
+		ScoreThreshold: &scoreThreshold,
+		WithPayload:    qdrant.NewWithPayload(true),
+	})
+	if err != nil {
+		log.Errorf("[QdrantMultiVector] MaxSim search failed: %v", err)
+		return nil, fmt.Errorf("%s: %w", collectionName, err)
+	}
+
+	results := make([]*types.IndexWithScore, 0, len(searchResult))
+	for _, point := range searchResult {
+		payload := point.Payload
+		embedding := &QdrantVectorEmbeddingWithScore{
+			QdrantVectorEmbedding: QdrantVectorEmbedding{
+				Content:         payload[fieldContent].GetStringValue(),
+				SourceID:        payload[fieldSourceID].GetStringValue(),
+				SourceType:      int(payload[fieldSourceType].GetIntegerValue()),
+				ChunkID:         payload[fieldChunkID].GetStringValue(),
+				KnowledgeID:     payload[fieldKnowledgeID].GetStringValue(),
+				KnowledgeBaseID: payload[fieldKnowledgeBaseID].GetStringValue(),
+				TagID:           payload[fieldTagID].GetStringValue(),
+			},
+			Score: float64(point.Score) / float64(tokenCount),
+		}
+		results = append(results, fromQdrantVectorEmbedding(point.Id.GetUuid(), embedding, types.MatchTypeEmbedding))
+	}
+
+	log.Infof("[QdrantMultiVector] MaxSim retrieval found %d results", len(results))
+	return q.buildRetrieveResult(results), nil
+}
+
+// CopyIndices copies index data from source knowledge base to target knowledge base
+func (q *qdrantMultiVectorRepository) CopyIndices(ctx context.Context,
+	sourceKnowledgeBaseID string,
+	sourceToTargetKBIDMap map[string]string,
+	sourceToTargetChunkIDMap map[string]string,
+	targetKnowledgeBaseID string,
+	dimension int,
+	knowledgeType string,
+) error {
+	log := logger.GetLogger(ctx)
+	if len(sourceToTargetChunkIDMap) == 0 {
+		log.Warn("[QdrantMultiVector] Empty mapping, skipping copy")
+		return nil
+	}
+
+	collections, err := q.listCollections(ctx)
+	if err != nil {
+		return err
+	}
+
+	totalCopied := 0
+	batchSize := uint32(64)
+	for _, collectionName := range collections {
+		var offset *qdrant.PointId
+		for {
+			scrollResult, err := q.client.Scroll(ctx, &qdrant.ScrollPoints{
+				CollectionName: collectionName,
+				Filter: &qdrant.Filter{
+					Must: []*qdrant.Condition{
+						qdrant.NewMatch(fieldKnowledgeBaseID, sourceKnowledgeBaseID),
+					},
+				},
+				Limit:       &batchSize,
+				Offset:      offset,
+				WithPayload: qdrant.NewWithPayload(true),
+				WithVectors: qdrant.NewWithVectors(true),
+			})
+			if err != nil {
+				log.Errorf("[QdrantMultiVector] Failed to query source points: %v", err)
+				return err
+			}
+			pointsCount := len(scrollResult)
+			if pointsCount == 0 {
+				break
+			}
+
+			targetPoints := make([]*qdrant.PointStruct, 0, pointsCount)
+			for _, sourcePoint := range scrollResult {
+				payload := sourcePoint.Payload
+				sourceChunkID := payload[fieldChunkID].GetStringValue()
+				targetChunkID, ok := sourceToTargetChunkIDMap[sourceChunkID]
+				if !ok {
+					continue
+				}
+				targetKnowledgeID, ok := sourceToTargetKBIDMap[payload[fieldKnowledgeID].GetStringValue()]
+				if !ok {
+					continue
+				}
+
+				multiDense := sourcePoint.Vectors.GetVector().GetMultiDense()
+				if multiDense == nil || len(multiDense.GetVectors()) == 0 {
+					log.Warnf("[QdrantMultiVector] No vectors found for source point with chunk %s, skipping",
+						sourceChunkID)
+					continue
+				}
+				tokenEmbeddings := make([][]float32, 0, len(multiDense.GetVectors()))
+				for _, vector := range multiDense.GetVectors() {
+					tokenEmbeddings = append(tokenEmbeddings, vector.GetData())
+				}
+
+				targetPoints = append(targetPoints, &qdrant.PointStruct{
+					Id:      qdrant.NewID(uuid.New().String()),
+					Vectors: qdrant.NewVectorsMulti(tokenEmbeddings),
+					Payload: copiedPointPayload(payload, targetChunkID, targetKnowledgeID, targetKnowledgeBaseID),
+				})
+			}
+
+			if len(targetPoints) > 0 {
+				if _, err := q.client.Upsert(ctx, &qdrant.UpsertPoints{
+					CollectionName: collectionName,
+					Points:         targetPoints,
+				}); err != nil {
+					log.Errorf("[QdrantMultiVector] Failed to batch upsert target points: %v", err)
+					return err
+				}
+				totalCopied += len(targetPoints)
+			}
+
+			offset = scrollResult[pointsCount-1].Id
+			if pointsCount < int(batchSize) {
+				break
+			}
+		}
+	}
+
+	log.Infof("[QdrantMultiVector] Index copy completed, total copied: %d", totalCopied)
+	return nil
+}
+
+// copiedPointPayload builds the payload of a point copied to a target chunk, keeping the tag,
+// the enabled status and the attributes of the source point
+func copiedPointPayload(payload map[string]*qdrant.Value,
+	targetChunkID string, targetKnowledgeID string, targetKnowledgeBaseID string,
+) map[string]*qdrant.Value {
+	sourceChunkID := payload[fieldChunkID].GetStringValue()
+	isEnabled := true
+	if value, ok := payload[fieldIsEnabled]; ok {
+		isEnabled = value.GetBoolValue()
+	}
+	newPayload := qdrant.NewValueMap(map[string]any{
+		fieldContent:         payload[fieldContent].GetStringValue(),
+		fieldSourceID:        targetSourceID(payload[fieldSourceID].GetStringValue(), sourceChunkID, targetChunkID),
+		fieldSourceType:      payload[fieldSourceType].GetIntegerValue(),
+		fieldChunkID:         targetChunkID,
+		fieldKnowledgeID:     targetKnowledgeID,
+		fieldKnowledgeBaseID: targetKnowledgeBaseID,
+		fieldTagID:           payload[fieldTagID].GetStringValue(),
+		fieldIsEnabled:       isEnabled,
+	})
+	copyAttributePayload(newPayload, payload)
+	return newPayload
+}
+
+func (q *qdrantMultiVectorRepository) buildRetrieveResult(results []*types.IndexWithScore) []*types.RetrieveResult {
+	return []*types.RetrieveResult{
+		{
+			Results:             results,
+			RetrieverEngineType: types.QdrantMultiVectorRetrieverEngineType,
+			RetrieverType:       types.MultiVectorRetrieverType,
+		},
+	}
+}
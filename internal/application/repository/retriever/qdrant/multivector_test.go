@@ -0,0 +1,49 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+func TestCopiedPointPayload(t *testing.T) {
+	source := qdrant.NewValueMap(map[string]any{
+		fieldContent:         "Reset the pump controller",
+		fieldSourceID:        "c1-q1",
+		fieldSourceType:      int64(1),
+		fieldChunkID:         "c1",
+		fieldKnowledgeID:     "k1",
+		fieldKnowledgeBaseID: "kb1",
+		fieldTagID:           "tag1",
+		fieldIsEnabled:       false,
+		fieldFileType:        "pdf",
+	})
+
+	payload := copiedPointPayload(source, "c2", "k2", "kb2")
+	wantStrings := map[string]string{
+		fieldContent:         "Reset the pump controller",
+		fieldSourceID:        "c2-q1",
+		fieldChunkID:         "c2",
+		fieldKnowledgeID:     "k2",
+		fieldKnowledgeBaseID: "kb2",
+		fieldTagID:           "tag1",
+		fieldFileType:        "pdf",
+	}
+	for field, want := range wantStrings {
+		if got := payload[field].GetStringValue(); got != want {
+			t.Errorf("payload[%s] = %q, want %q", field, got, want)
+		}
+	}
+	if got := payload[fieldSourceType].GetIntegerValue(); got != 1 {
+		t.Errorf("payload[%s] = %d, want 1", fieldSourceType, got)
+	}
+	if value, ok := payload[fieldIsEnabled]; !ok || value.GetBoolValue() {
+		t.Errorf("payload[%s] = %v, want false", fieldIsEnabled, value)
+	}
+
+	// Points saved without the enabled status are enabled
+	delete(source, fieldIsEnabled)
+	if !copiedPointPayload(source, "c2", "k2", "kb2")[fieldIsEnabled].GetBoolValue() {
+		t.Errorf("payload[%s] of a point without status = false, want true", fieldIsEnabled)
+	}
+}
@@ -292,6 +292,35 @@ func (q *qdrantRepository) DeleteByChunkIDList(ctx context.Context, chunkIDList
 	return nil
 }
 
+// MoveByChunkIDList moves the points of chunks in the collection of the dimension to another knowledge base ID
+func (q *qdrantRepository) MoveByChunkIDList(ctx context.Context,
+	chunkIDList []string, targetKnowledgeBaseID string, dimension int, knowledgeType string,
+) error {
+	log := logger.GetLogger(ctx)
+	if len(chunkIDList) == 0 {
+		return nil
+	}
+
+	collectionName := q.getCollectionName(dimension)
+	log.Infof("[Qdrant] Moving indices by chunk IDs in %s to %s, count: %d",
+		collectionName, targetKnowledgeBaseID, len(chunkIDList))
+	_, err := q.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Payload:        qdrant.NewValueMap(map[string]any{fieldKnowledgeBaseID: targetKnowledgeBaseID}),
+		PointsSelector: qdrant.NewPointsSelectorFilter(&qdrant.Filter{
+			Must: []*qdrant.Condition{
+				qdrant.NewMatchKeywords(fieldChunkID, chunkIDList...),
+			},
+		}),
+	})
+	if err != nil {
+		log.Errorf("[Qdrant] Failed to move by chunk IDs: %v", err)
+		return fmt.Errorf("failed to move by chunk IDs: %w", err)
+	}
+	return nil
+}
+
 // DeleteByKnowledgeIDList removes points from the collection based on knowledge IDs
 func (q *qdrantRepository) DeleteByKnowledgeIDList(ctx context.Context,
 	knowledgeIDList []string, dimension int, knowledgeType string,
@@ -352,6 +381,94 @@ func (q *qdrantRepository) DeleteBySourceIDList(ctx context.Context,
 	return nil
 }
 
+// DeleteByKnowledgeBaseID removes all points of a knowledge base from the collection of the dimension
+func (q *qdrantRepository) DeleteByKnowledgeBaseID(ctx context.Context, knowledgeBaseID string, dimension int) error {
+	log := logger.GetLogger(ctx)
+	collectionName := q.getCollectionName(dimension)
+	exists, err := q.client.CollectionExists(ctx, collectionName)
+	if err != nil {
+		log.Errorf("[Qdrant] Failed to check collection existence: %v", err)
+		return fmt.Errorf("failed to check collection existence: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	log.Infof("[Qdrant] Deleting indices by knowledge base ID from %s: %s", collectionName, knowledgeBaseID)
+	_, err = q.client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Points: qdrant.NewPointsSelectorFilter(&qdrant.Filter{
+			Must: []*qdrant.Condition{
+				qdrant.NewMatchKeyword(fieldKnowledgeBaseID, knowledgeBaseID),
+			},
+		}),
+	})
+	if err != nil {
+		log.Errorf("[Qdrant] Failed to delete by knowledge base ID: %v", err)
+		return fmt.Errorf("failed to delete by knowledge base ID: %w", err)
+	}
+	return nil
+}
+
+// ReplaceKnowledgeBaseIndices deletes the points of a knowledge base from the collection of the old
+// dimension, then relabels the shadow points in the collection of the new dimension.
+// Both operations wait for completion so the shadow points never mix with the old ones.
+// Without shadow points the indices have already been replaced, so a retried switchover keeps them.
+func (q *qdrantRepository) ReplaceKnowledgeBaseIndices(ctx context.Context,
+	knowledgeBaseID string, shadowKnowledgeBaseID string, oldDimension int, newDimension int,
+) error {
+	log := logger.GetLogger(ctx)
+	collectionName := q.getCollectionName(newDimension)
+	exists, err := q.client.CollectionExists(ctx, collectionName)
+	if err != nil {
+		log.Errorf("[Qdrant] Failed to check collection existence: %v", err)
+		return fmt.Errorf("failed to check collection existence: %w", err)
+	}
+	if !exists {
+		log.Warnf("[Qdrant] Collection %s does not exist, no shadow indices to move", collectionName)
+		return nil
+	}
+
+	shadowFilter := &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewMatchKeyword(fieldKnowledgeBaseID, shadowKnowledgeBaseID),
+		},
+	}
+	shadows, err := q.client.Count(ctx, &qdrant.CountPoints{
+		CollectionName: collectionName,
+		Filter:         shadowFilter,
+		Exact:          qdrant.PtrOf(true),
+	})
+	if err != nil {
+		log.Errorf("[Qdrant] Failed to count shadow indices in %s: %v", collectionName, err)
+		return fmt.Errorf("failed to count shadow indices: %w", err)
+	}
+	if shadows == 0 {
+		log.Infof("[Qdrant] No shadow indices of knowledge base %s in %s, already replaced", knowledgeBaseID, collectionName)
+		return nil
+	}
+
+	if err := q.DeleteByKnowledgeBaseID(ctx, knowledgeBaseID, oldDimension); err != nil {
+		return err
+	}
+
+	_, err = q.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Payload:        qdrant.NewValueMap(map[string]any{fieldKnowledgeBaseID: knowledgeBaseID}),
+		PointsSelector: qdrant.NewPointsSelectorFilter(shadowFilter),
+	})
+	if err != nil {
+		log.Errorf("[Qdrant] Failed to move shadow indices in %s: %v", collectionName, err)
+		return fmt.Errorf("failed to move shadow indices: %w", err)
+	}
+
+	log.Infof("[Qdrant] Replaced indices of knowledge base %s in %s with %d shadow points",
+		knowledgeBaseID, collectionName, shadows)
+	return nil
+}
+
 // BatchUpdateChunkEnabledStatus updates the enabled status of chunks in batch
 // This method operates on all collections since dimension is not provided
 func (q *qdrantRepository) BatchUpdateChunkEnabledStatus(ctx context.Context, chunkStatusMap map[string]bool) error {
@@ -479,7 +596,8 @@ func (q *qdrantRepository) BatchUpdateChunkTagID(ctx context.Context, chunkTagMa
 	return nil
 }
 
-func (q *qdrantRepository) getBaseFilter(params types.RetrieveParams) *qdrant.Filter {
+// getBaseFilter builds the filter shared by all retrieval types from the retrieve params
+func getBaseFilter(params types.RetrieveParams) *qdrant.Filter {
 	must := make([]*qdrant.Condition, 0)
 	mustNot := make([]*qdrant.Condition, 0)
 
@@ -509,6 +627,11 @@ func (q *qdrantRepository) getBaseFilter(params types.RetrieveParams) *qdrant.Fi
 		mustNot = append(mustNot, qdrant.NewMatchKeywords(fieldChunkID, params.ExcludeChunkIDs...))
 	}
 
+	// Metadata filter is evaluated on the attributes denormalized into the payload
+	if params.Filter != nil {
+		must = append(must, filterCondition(params.Filter))
+	}
+
 	filter := &qdrant.Filter{
 		Must:    must,
 		MustNot: mustNot,
@@ -559,7 +682,7 @@ func (q *qdrantRepository) VectorRetrieve(ctx context.Context,
 		return buildRetrieveResult(nil, types.VectorRetrieverType), nil
 	}
 
-	filter := q.getBaseFilter(params)
+	filter := getBaseFilter(params)
 
 	limit := uint64(params.TopK)
 	scoreThreshold := float32(params.Threshold)
@@ -641,7 +764,7 @@ func (q *qdrantRepository) KeywordsRetrieve(ctx context.Context,
 			continue
 		}
 
-		filter := q.getBaseFilter(params)
+		filter := getBaseFilter(params)
 
 		// Build should conditions for each token (OR logic)
 		// This allows matching documents that contain any of the query tokens
@@ -781,31 +904,16 @@ func (q *qdrantRepository) CopyIndices(ctx context.Context,
 				continue
 			}
 
-			// Handle SourceID transformation for generated questions
-			// Generated questions have SourceID format: {chunkID}-{questionID}
-			// Regular chunks have SourceID == ChunkID
-			var targetSourceID string
-			if originalSourceID == sourceChunkID {
-				// Regular chunk, use targetChunkID as SourceID
-				targetSourceID = targetChunkID
-			} else if strings.HasPrefix(originalSourceID, sourceChunkID+"-") {
-				// This is a generated question, preserve the questionID part
-				questionID := strings.TrimPrefix(originalSourceID, sourceChunkID+"-")
-				targetSourceID = fmt.Sprintf("%s-%s", targetChunkID, questionID)
-			} else {
-				// For other complex scenarios, generate new unique SourceID
-				targetSourceID = uuid.New().String()
-			}
-
 			newPayload := qdrant.NewValueMap(map[string]any{
 				fieldContent:         payload[fieldContent].GetStringValue(),
-				fieldSourceID:        targetSourceID,
+				fieldSourceID:        targetSourceID(originalSourceID, sourceChunkID, targetChunkID),
 				fieldSourceType:      payload[fieldSourceType].GetIntegerValue(),
 				fieldChunkID:         targetChunkID,
 				fieldKnowledgeID:     targetKnowledgeID,
 				fieldKnowledgeBaseID: targetKnowledgeBaseID,
 				fieldIsEnabled:       true,
 			})
+			copyAttributePayload(newPayload, payload)
 
 			var vectors *qdrant.Vectors
 			if vectorOutput := sourcePoint.Vectors.GetVector(); vectorOutput != nil {
@@ -856,6 +964,22 @@ func (q *qdrantRepository) CopyIndices(ctx context.Context,
 	return nil
 }
 
+// targetSourceID maps the SourceID of a copied point to the target chunk.
+// Generated questions have SourceID format: {chunkID}-{questionID}, regular chunks have SourceID == ChunkID
+func targetSourceID(originalSourceID, sourceChunkID, targetChunkID string) string {
+	if originalSourceID == sourceChunkID {
+		// Regular chunk, use targetChunkID as SourceID
+		return targetChunkID
+	}
+	if strings.HasPrefix(originalSourceID, sourceChunkID+"-") {
+		// This is a generated question, preserve the questionID part
+		questionID := strings.TrimPrefix(originalSourceID, sourceChunkID+"-")
+		return fmt.Sprintf("%s-%s", targetChunkID, questionID)
+	}
+	// For other complex scenarios, generate new unique SourceID
+	return uuid.New().String()
+}
+
 func createPayload(embedding *QdrantVectorEmbedding) map[string]*qdrant.Value {
 	payload := map[string]any{
 		fieldContent:         embedding.Content,
@@ -867,6 +991,7 @@ func createPayload(embedding *QdrantVectorEmbedding) map[string]*qdrant.Value {
 		fieldTagID:           embedding.TagID,
 		fieldIsEnabled:       embedding.IsEnabled,
 	}
+	maps.Copy(payload, attributePayload(embedding.Attributes))
 	return qdrant.NewValueMap(payload)
 }
 
@@ -924,6 +1049,7 @@ func toQdrantVectorEmbedding(embedding *types.IndexInfo, additionalParams map[st
 		KnowledgeBaseID: embedding.KnowledgeBaseID,
 		TagID:           embedding.TagID,
 		IsEnabled:       true, // Default to enabled
+		Attributes:      embedding.Attributes,
 	}
 	if additionalParams != nil && slices.Contains(slices.Collect(maps.Keys(additionalParams)), fieldEmbedding) {
 		if embeddingMap, ok := additionalParams[fieldEmbedding].(map[string][]float32); ok {
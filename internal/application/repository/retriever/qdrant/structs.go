@@ -3,6 +3,7 @@ package qdrant
 import (
 	"sync"
 
+	"github.com/Tencent/WeKnora/internal/types"
 	"github.com/qdrant/go-client/qdrant"
 )
 
@@ -23,6 +24,8 @@ type QdrantVectorEmbedding struct {
 	TagID           string    `json:"tag_id"`
 	Embedding       []float32 `json:"embedding"`
 	IsEnabled       bool      `json:"is_enabled"`
+	// Attributes denormalized for metadata filtering
+	Attributes *types.IndexAttributes `json:"-"`
 }
 
 type QdrantVectorEmbeddingWithScore struct {
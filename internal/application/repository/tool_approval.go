@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+// ErrToolApprovalNotFound is returned when a tool approval does not exist
+var ErrToolApprovalNotFound = errors.New("tool approval not found")
+
+// toolApprovalRepository implements the ToolApprovalRepository interface
+type toolApprovalRepository struct {
+	db *gorm.DB
+}
+
+// NewToolApprovalRepository creates a new tool approval repository
+func NewToolApprovalRepository(db *gorm.DB) interfaces.ToolApprovalRepository {
+	return &toolApprovalRepository{db: db}
+}
+
+// Create creates an approval
+func (r *toolApprovalRepository) Create(ctx context.Context, approval *types.ToolApproval) error {
+	return r.db.WithContext(ctx).Create(approval).Error
+}
+
+// Get gets an approval of a tenant
+func (r *toolApprovalRepository) Get(ctx context.Context, tenantID uint64, id string) (*types.ToolApproval, error) {
+	var approval types.ToolApproval
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&approval).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrToolApprovalNotFound
+		}
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// ListBySession lists the approvals of a session, newest first
+func (r *toolApprovalRepository) ListBySession(ctx context.Context,
+	tenantID uint64, sessionID string, status types.ToolApprovalStatus,
+) ([]*types.ToolApproval, error) {
+	query := r.db.WithContext(ctx).Where("tenant_id = ? AND session_id = ?", tenantID, sessionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var approvals []*types.ToolApproval
+	if err := query.Order("created_at DESC").Find(&approvals).Error; err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+// Decide stores the decision of an approval if it is still pending.
+// The status condition makes concurrent decisions, including expiry, resolve to the first one.
+func (r *toolApprovalRepository) Decide(ctx context.Context, approval *types.ToolApproval) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&types.ToolApproval{}).
+		Where("tenant_id = ? AND id = ? AND status = ?", approval.TenantID, approval.ID, types.ToolApprovalPending).
+		Updates(map[string]interface{}{
+			"status":             approval.Status,
+			"approved_arguments": approval.ApprovedArguments,
+			"comment":            approval.Comment,
+			"decided_by":         approval.DecidedBy,
+			"decided_at":         approval.DecidedAt,
+			"updated_at":         approval.UpdatedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+// usageGroupColumns maps the group by options of usage reports to the selected and grouped columns of usage_daily
+var usageGroupColumns = map[string]struct {
+	selects []string
+	groups  []string
+}{
+	types.UsageGroupByDay: {
+		selects: []string{"to_char(day, 'YYYY-MM-DD') AS day"},
+		groups:  []string{"day"},
+	},
+	types.UsageGroupByModel: {
+		selects: []string{"model_id", "MAX(model_name) AS model_name"},
+		groups:  []string{"model_id"},
+	},
+	types.UsageGroupByModelType: {
+		selects: []string{"model_type"},
+		groups:  []string{"model_type"},
+	},
+	types.UsageGroupByAgent: {
+		selects: []string{"agent_id"},
+		groups:  []string{"agent_id"},
+	},
+	types.UsageGroupBySource: {
+		selects: []string{"source"},
+		groups:  []string{"source"},
+	},
+}
+
+// usageSumColumns are the summed columns of usage reports
+const usageSumColumns = "COALESCE(SUM(calls), 0) AS calls, COALESCE(SUM(input_count), 0) AS input_count, " +
+	"COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, COALESCE(SUM(completion_tokens), 0) AS completion_tokens, " +
+	"COALESCE(SUM(total_tokens), 0) AS total_tokens, COALESCE(SUM(estimated_calls), 0) AS estimated_calls"
+
+// usageRollupSQL recomputes the daily rollups of every day with records since the given time.
+// Whole days are recomputed from the raw records, so running it again is harmless.
+const usageRollupSQL = `
+INSERT INTO usage_daily (tenant_id, day, model_id, model_type, agent_id, source, model_name,
+	calls, input_count, prompt_tokens, completion_tokens, total_tokens, estimated_calls, updated_at)
+SELECT tenant_id, (created_at AT TIME ZONE 'UTC')::date, model_id, model_type, agent_id, source, MAX(model_name),
+	COUNT(*), SUM(input_count), SUM(prompt_tokens), SUM(completion_tokens), SUM(total_tokens),
+	COUNT(*) FILTER (WHERE estimated), NOW()
+FROM usage_records
+WHERE created_at >= ?
+GROUP BY tenant_id, (created_at AT TIME ZONE 'UTC')::date, model_id, model_type, agent_id, source
+ON CONFLICT (tenant_id, day, model_id, model_type, agent_id, source) DO UPDATE SET
+	model_name = EXCLUDED.model_name,
+	calls = EXCLUDED.calls,
+	input_count = EXCLUDED.input_count,
+	prompt_tokens = EXCLUDED.prompt_tokens,
+	completion_tokens = EXCLUDED.completion_tokens,
+	total_tokens = EXCLUDED.total_tokens,
+	estimated_calls = EXCLUDED.estimated_calls,
+	updated_at = EXCLUDED.updated_at`
+
+// usageRepository implements the UsageRepository interface
+type usageRepository struct {
+	db *gorm.DB
+}
+
+// NewUsageRepository creates a new usage repository
+func NewUsageRepository(db *gorm.DB) interfaces.UsageRepository {
+	return &usageRepository{db: db}
+}
+
+// CreateUsageRecord stores the usage of a model call
+func (r *usageRepository) CreateUsageRecord(ctx context.Context, record *types.UsageRecord) error {
+	return r.db.WithContext(ctx).Create(record).Error
+}
+
+// ListUsageRecords lists the usage records of a tenant matching the filter, newest first
+func (r *usageRepository) ListUsageRecords(ctx context.Context,
+	tenantID uint64, filter *types.UsageRecordFilter, page *types.Pagination,
+) ([]*types.UsageRecord, int64, error) {
+	query := r.db.WithContext(ctx).Model(&types.UsageRecord{}).Where("tenant_id = ?", tenantID)
+	if filter != nil {
+		if filter.SessionID != "" {
+			query = query.Where("session_id = ?", filter.SessionID)
+		}
+		if filter.AgentID != "" {
+			query = query.Where("agent_id = ?", filter.AgentID)
+		}
+		if filter.ModelID != "" {
+			query = query.Where("model_id = ?", filter.ModelID)
+		}
+		if filter.Source != "" {
+			query = query.Where("source = ?", filter.Source)
+		}
+		if filter.From != nil {
+			query = query.Where("created_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			query = query.Where("created_at < ?", *filter.To)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var records []*types.UsageRecord
+	if err := query.Order("id DESC").
+		Offset(page.Offset()).Limit(page.Limit()).
+		Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	return records, total, nil
+}
+
+// SummarizeUsage sums the daily rollups of a tenant between two days (inclusive), grouped by the given options
+func (r *usageRepository) SummarizeUsage(ctx context.Context,
+	tenantID uint64, from, to time.Time, groupBy []string, query *types.UsageQuery,
+) ([]*types.UsageSummaryRow, error) {
+	selects := make([]string, 0, len(groupBy)+1)
+	groups := make([]string, 0, len(groupBy))
+	for _, option := range groupBy {
+		columns := usageGroupColumns[option]
+		selects = append(selects, columns.selects...)
+		groups = append(groups, columns.groups...)
+	}
+	selects = append(selects, usageSumColumns)
+
+	db := r.db.WithContext(ctx).Model(&types.UsageDaily{}).
+		Select(strings.Join(selects, ", ")).
+		Where("tenant_id = ? AND day >= ? AND day <= ?", tenantID, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if query != nil {
+		if query.ModelID != "" {
+			db = db.Where("model_id = ?", query.ModelID)
+		}
+		if query.ModelType != "" {
+			db = db.Where("model_type = ?", query.ModelType)
+		}
+		if query.AgentID != "" {
+			db = db.Where("agent_id = ?", query.AgentID)
+		}
+		if query.Source != "" {
+			db = db.Where("source = ?", query.Source)
+		}
+	}
+	if len(groups) > 0 {
+		db = db.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", "))
+	}
+
+	var rows []*types.UsageSummaryRow
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// RollupUsage recomputes the daily rollups of every day with records since the given time
+func (r *usageRepository) RollupUsage(ctx context.Context, since time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(usageRollupSQL, since)
+	return result.RowsAffected, result.Error
+}
+
+// PurgeUsageRecords deletes the usage records created before the given time, the rollups are kept
+func (r *usageRepository) PurgeUsageRecords(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&types.UsageRecord{})
+	return result.RowsAffected, result.Error
+}
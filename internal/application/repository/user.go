@@ -95,6 +95,18 @@ func (r *userRepository) ListUsers(ctx context.Context, offset, limit int) ([]*t
 	return users, nil
 }
 
+// ListUsersByTenantID lists the users of a tenant
+func (r *userRepository) ListUsersByTenantID(ctx context.Context, tenantID uint64) ([]*types.User, error) {
+	var users []*types.User
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at ASC").
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // authTokenRepository implements auth token repository interface
 type authTokenRepository struct {
 	db *gorm.DB
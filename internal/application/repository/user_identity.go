@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+// ErrUserIdentityNotFound is returned when a user identity is not found
+var ErrUserIdentityNotFound = errors.New("user identity not found")
+
+// userIdentityRepository implements the UserIdentityRepository interface
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository creates a new user identity repository
+func NewUserIdentityRepository(db *gorm.DB) interfaces.UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+// GetIdentity gets the identity of a provider account
+func (r *userIdentityRepository) GetIdentity(
+	ctx context.Context, provider string, subject string,
+) (*types.UserIdentity, error) {
+	var identity types.UserIdentity
+	if err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserIdentityNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateIdentity creates an identity
+func (r *userIdentityRepository) CreateIdentity(ctx context.Context, identity *types.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// UpdateIdentity updates an identity
+func (r *userIdentityRepository) UpdateIdentity(ctx context.Context, identity *types.UserIdentity) error {
+	return r.db.WithContext(ctx).Save(identity).Error
+}
+
+// DeleteIdentity deletes an identity
+func (r *userIdentityRepository) DeleteIdentity(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&types.UserIdentity{}).Error
+}
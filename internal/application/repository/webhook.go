@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrWebhookNotFound is returned when a webhook is not found
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookDeliveryNotFound is returned when a webhook delivery is not found
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// webhookRepository implements the WebhookRepository interface
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB) interfaces.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// CreateWebhook creates a webhook
+func (r *webhookRepository) CreateWebhook(ctx context.Context, webhook *types.Webhook) error {
+	return r.db.WithContext(ctx).Create(webhook).Error
+}
+
+// GetWebhook gets a webhook of a tenant by ID
+func (r *webhookRepository) GetWebhook(ctx context.Context, tenantID uint64, id string) (*types.Webhook, error) {
+	var webhook types.Webhook
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&webhook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ListWebhooks lists the webhooks of a tenant, newest first
+func (r *webhookRepository) ListWebhooks(ctx context.Context, tenantID uint64) ([]*types.Webhook, error) {
+	var webhooks []*types.Webhook
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at DESC").
+		Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// ListEnabledWebhooks lists the enabled webhooks of a tenant
+func (r *webhookRepository) ListEnabledWebhooks(ctx context.Context, tenantID uint64) ([]*types.Webhook, error) {
+	var webhooks []*types.Webhook
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND enabled = ?", tenantID, true).
+		Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook updates a webhook
+func (r *webhookRepository) UpdateWebhook(ctx context.Context, webhook *types.Webhook) error {
+	return r.db.WithContext(ctx).
+		Where("tenant_id = ?", webhook.TenantID).
+		Select("name", "url", "secret", "events", "enabled", "description", "updated_at").
+		Updates(webhook).Error
+}
+
+// DeleteWebhook deletes a webhook and its deliveries
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, tenantID uint64, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&types.Webhook{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+		return tx.Where("tenant_id = ? AND webhook_id = ?", tenantID, id).Delete(&types.WebhookDelivery{}).Error
+	})
+}
+
+// CreateDelivery creates a delivery
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *types.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Create(delivery).Error
+}
+
+// GetDelivery gets a delivery of a tenant by ID
+func (r *webhookRepository) GetDelivery(ctx context.Context,
+	tenantID uint64, id string,
+) (*types.WebhookDelivery, error) {
+	var delivery types.WebhookDelivery
+	if err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries lists the deliveries of a webhook matching the filter, newest first
+func (r *webhookRepository) ListDeliveries(ctx context.Context, tenantID uint64, webhookID string,
+	filter *types.WebhookDeliveryFilter, page *types.Pagination,
+) ([]*types.WebhookDelivery, int64, error) {
+	query := r.db.WithContext(ctx).Model(&types.WebhookDelivery{}).
+		Where("tenant_id = ? AND webhook_id = ?", tenantID, webhookID)
+	if filter != nil {
+		if filter.Event != "" {
+			query = query.Where("event = ?", filter.Event)
+		}
+		if filter.Status != "" {
+			query = query.Where("status = ?", filter.Status)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deliveries []*types.WebhookDelivery
+	if err := query.Order("created_at DESC").
+		Offset(page.Offset()).Limit(page.Limit()).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// UpdateDelivery updates the result of a delivery attempt
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *types.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Model(delivery).
+		Select("status", "attempts", "response_status", "error",
+			"duration_ms", "delivered_at", "updated_at").
+		Updates(delivery).Error
+}
+
+// PurgeDeliveries deletes the deliveries created before the given time
+func (r *webhookRepository) PurgeDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&types.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
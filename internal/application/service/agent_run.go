@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"github.com/Tencent/WeKnora/internal/application/repository"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/models/chat"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+const (
+	// agentRunHeartbeatInterval is how often the holder of a run refreshes its heartbeat and checks it still holds the run
+	agentRunHeartbeatInterval = 5 * time.Second
+	// agentRunStaleAfter is how long a run may miss heartbeats before it counts as interrupted
+	agentRunStaleAfter = 30 * time.Second
+	// agentRunMaxAttempts limits how often a run is started or taken over, so that a run crashing its instance is given up
+	agentRunMaxAttempts = 5
+	// agentRunRecoveryBatch is the number of stale runs handled by one recovery
+	agentRunRecoveryBatch = 100
+)
+
+// agentRunService implements the AgentRunService interface
+type agentRunService struct {
+	repo          interfaces.AgentRunRepository
+	tenantService interfaces.TenantService
+	task          *asynq.Client
+	// instanceID identifies this instance as the holder of runs
+	instanceID string
+
+	mu     sync.Mutex
+	active map[string]*activeAgentRun
+}
+
+// activeAgentRun is a run executing on this instance
+type activeAgentRun struct {
+	run    *types.AgentRun
+	cancel context.CancelFunc
+}
+
+// NewAgentRunService creates a new agent run service.
+// The runs still executing on shutdown are released as interrupted so that another instance resumes them right away.
+func NewAgentRunService(
+	repo interfaces.AgentRunRepository,
+	tenantService interfaces.TenantService,
+	task *asynq.Client,
+	cleaner interfaces.ResourceCleaner,
+) interfaces.AgentRunService {
+	hostname, _ := os.Hostname()
+	s := &agentRunService{
+		repo:          repo,
+		tenantService: tenantService,
+		task:          task,
+		instanceID:    fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8]),
+		active:        make(map[string]*activeAgentRun),
+	}
+	cleaner.RegisterWithName("AgentRuns", s.release)
+	return s
+}
+
+// Start records a new run held by this instance
+func (s *agentRunService) Start(ctx context.Context, run *types.AgentRun) error {
+	now := time.Now()
+	run.TenantID = ctx.Value(types.TenantIDContextKey).(uint64)
+	run.Status = types.AgentRunRunning
+	run.Owner = s.instanceID
+	run.Attempts = 1
+	run.HeartbeatAt = now
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil {
+		run.UserID = subject.UserID
+		run.UserRole = subject.Role
+	}
+	return s.repo.Create(ctx, run)
+}
+
+// GetRun gets the run generating a message of a session
+func (s *agentRunService) GetRun(ctx context.Context, sessionID, messageID string) (*types.AgentRun, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	run, err := s.repo.GetByMessage(ctx, tenantID, messageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAgentRunNotFound) {
+			return nil, werrors.NewNotFoundError("에이전트 실행을 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	if run.SessionID != sessionID {
+		return nil, werrors.NewNotFoundError("에이전트 실행을 찾을 수 없습니다")
+	}
+	return run, nil
+}
+
+// Claim takes over a run in one of the given statuses for this instance
+func (s *agentRunService) Claim(ctx context.Context,
+	sessionID, messageID string, from ...types.AgentRunStatus,
+) (*types.AgentRun, error) {
+	run, err := s.GetRun(ctx, sessionID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	claimed := *run
+	claimed.Owner = s.instanceID
+	claimed.HeartbeatAt = now
+	claimed.UpdatedAt = now
+	stored, err := s.repo.Claim(ctx, &claimed, from...)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		return nil, werrors.NewConflictError("재개할 수 없는 에이전트 실행입니다").WithDetails(string(run.Status))
+	}
+	claimed.Status = types.AgentRunRunning
+	claimed.Attempts++
+	logger.Infof(ctx, "Agent run %s of message %s claimed after round %d, attempt %d",
+		claimed.ID, messageID, claimed.Round, claimed.Attempts)
+	return &claimed, nil
+}
+
+// Pause pauses a running run. The holder notices it at its next checkpoint or heartbeat and stops.
+func (s *agentRunService) Pause(ctx context.Context, sessionID, messageID string) (*types.AgentRun, error) {
+	run, err := s.GetRun(ctx, sessionID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.repo.SetStatus(ctx, run, types.AgentRunPaused, types.AgentRunRunning)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		return nil, werrors.NewConflictError("실행 중인 에이전트가 아닙니다").WithDetails(string(run.Status))
+	}
+	run.Status = types.AgentRunPaused
+
+	// Stop right away when the run executes on this instance
+	s.mu.Lock()
+	if active, ok := s.active[run.ID]; ok {
+		active.cancel()
+	}
+	s.mu.Unlock()
+	logger.Infof(ctx, "Agent run %s of message %s paused after round %d", run.ID, messageID, run.Round)
+	return run, nil
+}
+
+// Cancel gives up a paused or interrupted run
+func (s *agentRunService) Cancel(ctx context.Context, sessionID, messageID string) (*types.AgentRun, error) {
+	run, err := s.GetRun(ctx, sessionID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	run.Error = "사용자가 실행을 중지했습니다"
+	stored, err := s.repo.SetStatus(ctx, run, types.AgentRunFailed, types.AgentRunPaused, types.AgentRunInterrupted)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		return nil, werrors.NewConflictError("재개를 기다리는 에이전트 실행이 아닙니다").WithDetails(string(run.Status))
+	}
+	run.Status = types.AgentRunFailed
+	logger.Infof(ctx, "Agent run %s of message %s cancelled after round %d", run.ID, messageID, run.Round)
+	return run, nil
+}
+
+// Execute runs fn while this instance holds the run and records the outcome
+func (s *agentRunService) Execute(ctx context.Context, run *types.AgentRun,
+	fn func(ctx context.Context, checkpointer interfaces.AgentCheckpointer) error,
+) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	s.active[run.ID] = &activeAgentRun{run: run, cancel: cancel}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.active, run.ID)
+		s.mu.Unlock()
+	}()
+
+	checkpointer := &agentRunCheckpointer{repo: s.repo, run: run}
+	lost := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if !s.keepAlive(runCtx, run) {
+			close(lost)
+			cancel()
+		}
+	}()
+
+	err := fn(runCtx, checkpointer)
+	cancel()
+	<-done
+
+	// The run was paused or taken over, its new holder decides what happens next
+	select {
+	case <-lost:
+		return werrors.ErrAgentRunSuspended
+	default:
+	}
+	if errors.Is(err, werrors.ErrAgentRunSuspended) {
+		return err
+	}
+
+	finishCtx := context.WithoutCancel(ctx)
+	finished := *run
+	finished.Status = types.AgentRunCompleted
+	if err != nil {
+		finished.Status = types.AgentRunFailed
+		finished.Error = err.Error()
+	}
+	finished.UpdatedAt = time.Now()
+	stored, ferr := s.repo.Finish(finishCtx, &finished)
+	if ferr != nil {
+		logger.Warnf(finishCtx, "Failed to finish agent run %s: %v", run.ID, ferr)
+		return err
+	}
+	if !stored {
+		// Paused between the last checkpoint and the end
+		return werrors.ErrAgentRunSuspended
+	}
+	return err
+}
+
+// keepAlive refreshes the heartbeat of a run until ctx is done.
+// It returns false once the run is no longer held by this instance.
+func (s *agentRunService) keepAlive(ctx context.Context, run *types.AgentRun) bool {
+	ticker := time.NewTicker(agentRunHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case <-ticker.C:
+		}
+		heartbeat := *run
+		heartbeat.HeartbeatAt = time.Now()
+		held, err := s.repo.Heartbeat(ctx, &heartbeat)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warnf(ctx, "Failed to refresh heartbeat of agent run %s: %v", run.ID, err)
+			}
+			continue
+		}
+		if !held {
+			logger.Infof(ctx, "Agent run %s is no longer held by instance %s, stopping", run.ID, s.instanceID)
+			return false
+		}
+	}
+}
+
+// RestoreContext rebuilds the tenant and user context of a run
+func (s *agentRunService) RestoreContext(ctx context.Context, run *types.AgentRun) (context.Context, error) {
+	tenant, err := s.tenantService.GetTenantByID(ctx, run.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, types.TenantIDContextKey, run.TenantID)
+	ctx = context.WithValue(ctx, types.TenantInfoContextKey, tenant)
+	// A run started with the tenant API key has no user and keeps the full access of the key
+	if run.UserID != "" {
+		ctx = context.WithValue(ctx, types.AccessSubjectContextKey,
+			&types.AccessSubject{UserID: run.UserID, Role: run.UserRole})
+	}
+	return types.WithUsageScope(ctx, types.UsageScope{
+		SessionID: run.SessionID, AgentID: run.AgentID, Source: types.UsageSourceAgentQA,
+	}), nil
+}
+
+// ProcessAgentRunRecovery marks runs whose instance stopped sending heartbeats as interrupted
+// and enqueues their resumption, runs started too often are given up
+func (s *agentRunService) ProcessAgentRunRecovery(ctx context.Context, t *asynq.Task) error {
+	staleBefore := time.Now().Add(-agentRunStaleAfter)
+	runs, err := s.repo.ListStale(ctx, staleBefore, agentRunRecoveryBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		if run.Attempts >= agentRunMaxAttempts {
+			run.Error = fmt.Sprintf("에이전트 실행이 %d번 중단되어 재개하지 않습니다", run.Attempts)
+			if _, err := s.repo.SetStatus(ctx, run, types.AgentRunFailed,
+				types.AgentRunRunning, types.AgentRunInterrupted); err != nil {
+				logger.Warnf(ctx, "Failed to give up agent run %s: %v", run.ID, err)
+			}
+			continue
+		}
+		if run.Status == types.AgentRunRunning {
+			interrupted, err := s.repo.Interrupt(ctx, run, staleBefore)
+			if err != nil {
+				logger.Warnf(ctx, "Failed to interrupt agent run %s: %v", run.ID, err)
+				continue
+			}
+			if !interrupted {
+				continue
+			}
+			logger.Infof(ctx, "Agent run %s of instance %s interrupted after round %d", run.ID, run.Owner, run.Round)
+		}
+		s.enqueueResume(ctx, run)
+	}
+	return nil
+}
+
+// enqueueResume enqueues the resumption of an interrupted run.
+// Interrupted runs are enqueued again on each recovery until one claims them, the task is unique per message.
+func (s *agentRunService) enqueueResume(ctx context.Context, run *types.AgentRun) {
+	payload, err := json.Marshal(types.AgentRunResumePayload{
+		TenantID:  run.TenantID,
+		SessionID: run.SessionID,
+		MessageID: run.MessageID,
+	})
+	if err != nil {
+		return
+	}
+	task := asynq.NewTask(types.TypeAgentRunResume, payload,
+		asynq.Queue("default"), asynq.Unique(agentRunStaleAfter), asynq.MaxRetry(0))
+	if _, err := s.task.Enqueue(task); err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+		logger.Warnf(ctx, "Failed to enqueue resumption of agent run %s: %v", run.ID, err)
+	}
+}
+
+// release hands the runs executing on this instance over to the other instances
+func (s *agentRunService) release() error {
+	ctx := context.Background()
+	s.mu.Lock()
+	runs := make([]*types.AgentRun, 0, len(s.active))
+	for _, active := range s.active {
+		runs = append(runs, active.run)
+	}
+	s.mu.Unlock()
+
+	for _, run := range runs {
+		released := *run
+		released.Status = types.AgentRunInterrupted
+		released.UpdatedAt = time.Now()
+		stored, err := s.repo.Finish(ctx, &released)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to release agent run %s: %v", run.ID, err)
+			continue
+		}
+		if stored {
+			logger.Infof(ctx, "Agent run %s released to other instances", run.ID)
+			s.enqueueResume(ctx, run)
+		}
+	}
+	return nil
+}
+
+// agentRunCheckpointer stores the checkpoints of a run held by this instance.
+// The run is shared with the heartbeat and is never modified.
+type agentRunCheckpointer struct {
+	repo interfaces.AgentRunRepository
+	run  *types.AgentRun
+}
+
+// SaveCheckpoint stores the state and messages after a completed round
+func (c *agentRunCheckpointer) SaveCheckpoint(ctx context.Context,
+	state *types.AgentState, messages []chat.Message,
+) error {
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	messagesJSON, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	checkpoint := *c.run
+	checkpoint.Round = state.CurrentRound
+	checkpoint.State = types.JSON(stateJSON)
+	checkpoint.Messages = types.JSON(messagesJSON)
+	checkpoint.HeartbeatAt = now
+	checkpoint.UpdatedAt = now
+	stored, err := c.repo.SaveCheckpoint(ctx, &checkpoint)
+	if err != nil {
+		return err
+	}
+	if !stored {
+		return werrors.ErrAgentRunSuspended
+	}
+	logger.Debugf(ctx, "Agent run %s checkpointed after round %d", c.run.ID, state.CurrentRound)
+	return nil
+}
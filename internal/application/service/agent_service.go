@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"slices"
 
 	"github.com/Tencent/WeKnora/internal/agent"
 	"github.com/Tencent/WeKnora/internal/agent/tools"
+	"github.com/Tencent/WeKnora/internal/application/service/audit"
 	"github.com/Tencent/WeKnora/internal/config"
 	"github.com/Tencent/WeKnora/internal/event"
 	"github.com/Tencent/WeKnora/internal/logger"
@@ -35,6 +38,10 @@ type agentService struct {
 	chunkService          interfaces.ChunkService
 	duckdb                *sql.DB
 	webSearchStateService interfaces.WebSearchStateService
+	permissionService     interfaces.PermissionService
+	auditService          interfaces.AuditService
+	toolApprovalService   interfaces.ToolApprovalService
+	customAgentService    interfaces.CustomAgentService
 }
 
 // NewAgentService creates a new agent service
@@ -51,6 +58,10 @@ func NewAgentService(
 	webSearchService interfaces.WebSearchService,
 	duckdb *sql.DB,
 	webSearchStateService interfaces.WebSearchStateService,
+	permissionService interfaces.PermissionService,
+	auditService interfaces.AuditService,
+	toolApprovalService interfaces.ToolApprovalService,
+	customAgentService interfaces.CustomAgentService,
 ) interfaces.AgentService {
 	return &agentService{
 		cfg:                   cfg,
@@ -65,6 +76,10 @@ func NewAgentService(
 		webSearchService:      webSearchService,
 		duckdb:                duckdb,
 		webSearchStateService: webSearchStateService,
+		permissionService:     permissionService,
+		auditService:          auditService,
+		toolApprovalService:   toolApprovalService,
+		customAgentService:    customAgentService,
 	}
 }
 
@@ -77,6 +92,7 @@ func (s *agentService) CreateAgentEngine(
 	eventBus *event.EventBus,
 	contextManager interfaces.ContextManager,
 	sessionID string,
+	checkpointer interfaces.AgentCheckpointer,
 ) (interfaces.AgentEngine, error) {
 	logger.Infof(ctx, "Creating agent engine with custom EventBus")
 
@@ -96,7 +112,7 @@ func (s *agentService) CreateAgentEngine(
 	toolRegistry := tools.NewToolRegistry()
 
 	// Register tools
-	if err := s.registerTools(ctx, toolRegistry, config, rerankModel, chatModel, sessionID); err != nil {
+	if err := s.registerTools(ctx, toolRegistry, config, rerankModel, chatModel, eventBus, sessionID); err != nil {
 		return nil, fmt.Errorf("failed to register tools: %w", err)
 	}
 
@@ -136,10 +152,25 @@ func (s *agentService) CreateAgentEngine(
 			}
 
 			if err == nil && len(mcpServices) > 0 {
-				// Filter enabled services
+				// Filter enabled services the user may use
+				ids := make([]string, 0, len(mcpServices))
+				for _, svc := range mcpServices {
+					if svc != nil {
+						ids = append(ids, svc.ID)
+					}
+				}
+				allowed, err := s.permissionService.FilterAccessible(ctx, types.ResourceTypeMCPService, ids, types.PermissionRead)
+				if err != nil {
+					logger.Warnf(ctx, "Failed to check MCP service access: %v", err)
+					allowed = nil
+				}
+				allowedSet := make(map[string]bool, len(allowed))
+				for _, id := range allowed {
+					allowedSet[id] = true
+				}
 				enabledServices := make([]*types.MCPService, 0)
 				for _, svc := range mcpServices {
-					if svc != nil && svc.Enabled {
+					if svc != nil && svc.Enabled && allowedSet[svc.ID] {
 						enabledServices = append(enabledServices, svc)
 					}
 				}
@@ -195,6 +226,8 @@ func (s *agentService) CreateAgentEngine(
 		contextManager,
 		sessionID,
 		systemPromptTemplate,
+		s.toolApprovalService,
+		checkpointer,
 	)
 
 	return engine, nil
@@ -207,6 +240,7 @@ func (s *agentService) registerTools(
 	config *types.AgentConfig,
 	rerankModel rerank.Reranker,
 	chatModel chat.Chat,
+	eventBus *event.EventBus,
 	sessionID string,
 ) error {
 	// Use config's allowed tools if specified, otherwise use defaults
@@ -254,6 +288,11 @@ func (s *agentService) registerTools(
 		allowedTools = append(allowedTools, tools.ToolWebSearch)
 		allowedTools = append(allowedTools, tools.ToolWebFetch)
 	}
+
+	// If sub-agents are configured, add delegate_agent to allowedTools
+	if len(config.SubAgents) > 0 && !slices.Contains(allowedTools, tools.ToolDelegateAgent) {
+		allowedTools = append(allowedTools, tools.ToolDelegateAgent)
+	}
 	logger.Infof(ctx, "Registering tools: %v, webSearchEnabled: %v", allowedTools, config.WebSearchEnabled)
 
 	// Register each allowed tool
@@ -285,7 +324,7 @@ func (s *agentService) registerTools(
 		case tools.ToolGetDocumentInfo:
 			toolToRegister = tools.NewGetDocumentInfoTool(s.knowledgeService, s.chunkService)
 		case tools.ToolDatabaseQuery:
-			toolToRegister = tools.NewDatabaseQueryTool(s.db)
+			toolToRegister = s.auditedTool(tools.NewDatabaseQueryTool(s.db), sessionID)
 		case tools.ToolWebSearch:
 			toolToRegister = tools.NewWebSearchTool(
 				s.webSearchService,
@@ -309,6 +348,9 @@ func (s *agentService) registerTools(
 			toolToRegister = tools.NewDataSchemaTool(s.knowledgeService, s.chunkService.GetRepository())
 			logger.Infof(ctx, "Registered data_schema tool")
 
+		case tools.ToolDelegateAgent:
+			toolToRegister = s.newDelegateAgentTool(ctx, config, chatModel, rerankModel, eventBus, sessionID)
+
 		default:
 			logger.Warnf(ctx, "Unknown tool: %s", toolName)
 		}
@@ -339,9 +381,57 @@ func (s *agentService) ValidateConfig(config *types.AgentConfig) error {
 		return fmt.Errorf("max iterations too high: %d (max %d)", config.MaxIterations, MAX_ITERATIONS)
 	}
 
+	if config.MaxParallelToolCalls <= 0 {
+		config.MaxParallelToolCalls = agent.DefaultAgentMaxParallelToolCalls
+	}
+
+	if config.ToolTimeoutSeconds <= 0 {
+		config.ToolTimeoutSeconds = agent.DefaultAgentToolTimeoutSeconds
+	}
+
+	if config.ToolApprovalTimeoutSeconds <= 0 {
+		config.ToolApprovalTimeoutSeconds = agent.DefaultAgentToolApprovalTimeoutSeconds
+	}
+
 	return nil
 }
 
+// newAgentConfig creates the runtime agent configuration of a custom agent.
+// Knowledge bases and search targets depend on the request and are resolved by the caller.
+func newAgentConfig(customAgent *types.CustomAgent) *types.AgentConfig {
+	agentConfig := &types.AgentConfig{
+		MaxIterations:              customAgent.Config.MaxIterations,
+		ReflectionEnabled:          customAgent.Config.ReflectionEnabled,
+		Temperature:                customAgent.Config.Temperature,
+		WebSearchEnabled:           customAgent.Config.WebSearchEnabled,
+		WebSearchMaxResults:        customAgent.Config.WebSearchMaxResults,
+		MultiTurnEnabled:           customAgent.Config.MultiTurnEnabled,
+		HistoryTurns:               customAgent.Config.HistoryTurns,
+		MCPSelectionMode:           customAgent.Config.MCPSelectionMode,
+		MCPServices:                customAgent.Config.MCPServices,
+		MaxParallelToolCalls:       customAgent.Config.MaxParallelToolCalls,
+		ToolTimeoutSeconds:         customAgent.Config.ToolTimeoutSeconds,
+		ToolApprovalPolicies:       customAgent.Config.ToolApprovalPolicies,
+		MCPApprovalPolicies:        customAgent.Config.MCPApprovalPolicies,
+		ToolApprovalTimeoutSeconds: customAgent.Config.ToolApprovalTimeoutSeconds,
+		SubAgents:                  customAgent.Config.SubAgents,
+	}
+
+	// Use custom agent's allowed tools if specified, otherwise use defaults
+	if len(customAgent.Config.AllowedTools) > 0 {
+		agentConfig.AllowedTools = customAgent.Config.AllowedTools
+	} else {
+		agentConfig.AllowedTools = tools.DefaultAllowedTools()
+	}
+
+	// Use custom agent's system prompt if specified
+	if customAgent.Config.SystemPrompt != "" {
+		agentConfig.UseCustomSystemPrompt = true
+		agentConfig.SystemPrompt = customAgent.Config.SystemPrompt
+	}
+	return agentConfig
+}
+
 // getKnowledgeBaseInfos retrieves detailed information for knowledge bases
 func (s *agentService) getKnowledgeBaseInfos(ctx context.Context, kbIDs []string) ([]*agent.KnowledgeBaseInfo, error) {
 	if len(kbIDs) == 0 {
@@ -498,3 +588,46 @@ func (s *agentService) getSelectedDocumentInfos(ctx context.Context, knowledgeID
 	logger.Infof(ctx, "Loaded %d selected documents metadata for prompt", len(selectedDocs))
 	return selectedDocs, nil
 }
+
+// auditedTool wraps a tool that queries tenant data directly, so every call is recorded in the audit log
+func (s *agentService) auditedTool(tool types.Tool, sessionID string) types.Tool {
+	if s.auditService == nil {
+		return tool
+	}
+	return &auditedTool{Tool: tool, auditService: s.auditService, sessionID: sessionID}
+}
+
+// auditedTool records each execution of the wrapped tool with its arguments and outcome
+type auditedTool struct {
+	types.Tool
+	auditService interfaces.AuditService
+	sessionID    string
+}
+
+// Execute runs the wrapped tool and records the call, a failed record does not fail the tool
+func (t *auditedTool) Execute(ctx context.Context, args json.RawMessage) (*types.ToolResult, error) {
+	result, err := t.Tool.Execute(ctx, args)
+
+	detail := map[string]interface{}{
+		"tool":    t.Name(),
+		"success": err == nil && result != nil && result.Success,
+	}
+	if redacted, rerr := audit.Redact(args); rerr == nil {
+		detail["args"] = redacted
+	}
+	if err != nil {
+		detail["error"] = err.Error()
+	} else if result != nil && result.Error != "" {
+		detail["error"] = result.Error
+	}
+	entry := &types.AuditLog{
+		Action:       "agent.tool." + t.Name(),
+		ResourceType: types.AuditResourceSession,
+		ResourceID:   t.sessionID,
+		Detail:       audit.Encode(detail),
+	}
+	if rerr := t.auditService.Record(context.WithoutCancel(ctx), entry); rerr != nil {
+		logger.Warnf(ctx, "Failed to record audit log for tool %s: %v", t.Name(), rerr)
+	}
+	return result, err
+}
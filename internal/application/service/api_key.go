@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/application/repository"
+	"github.com/Tencent/WeKnora/internal/application/service/sso"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+const (
+	// apiKeyPrefix marks tenant API keys, the legacy tenant key starts with "sk-"
+	apiKeyPrefix = "wk_"
+	// apiKeyRandomBytes is the number of random bytes of a key
+	apiKeyRandomBytes = 32
+	// apiKeyDisplayLength is the number of leading characters kept to tell keys apart in listings
+	apiKeyDisplayLength = 11
+	// apiKeyTouchInterval bounds how often the last use of a key is written
+	apiKeyTouchInterval = time.Minute
+)
+
+// apiKeyService implements the APIKeyService interface
+type apiKeyService struct {
+	repo      interfaces.APIKeyRepository
+	kbService interfaces.KnowledgeBaseService
+}
+
+// NewAPIKeyService creates a new tenant API key service
+func NewAPIKeyService(
+	repo interfaces.APIKeyRepository,
+	kbService interfaces.KnowledgeBaseService,
+) interfaces.APIKeyService {
+	return &apiKeyService{repo: repo, kbService: kbService}
+}
+
+// hashAPIKey returns the hex SHA-256 hash stored for a key.
+// Keys carry 256 random bits, so a fast unsalted hash is enough and keeps the lookup indexable.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether the given key has the format of a tenant API key
+func (s *apiKeyService) IsAPIKey(key string) bool {
+	return strings.HasPrefix(key, apiKeyPrefix)
+}
+
+// CreateAPIKey creates an API key for the tenant in the context
+func (s *apiKeyService) CreateAPIKey(ctx context.Context,
+	req *types.CreateAPIKeyRequest,
+) (*types.CreateAPIKeyResponse, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, werrors.NewBadRequestError("API 키 이름은 필수입니다")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, werrors.NewBadRequestError("API 키 범위를 하나 이상 지정해야 합니다")
+	}
+	scopes := make(types.StringArray, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !scope.IsValid() {
+			return nil, werrors.NewBadRequestError("올바르지 않은 API 키 범위입니다: " + string(scope))
+		}
+		if !slices.Contains(scopes, string(scope)) {
+			scopes = append(scopes, string(scope))
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, werrors.NewBadRequestError("만료 시간은 현재 이후여야 합니다")
+	}
+
+	kbIDs, err := s.validateKnowledgeBases(ctx, tenantID, req.KnowledgeBaseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// A key may only create keys within its own reach
+	if caller := types.APIKeyFromContext(ctx); caller != nil {
+		if len(caller.KnowledgeBaseIDs) > 0 {
+			if len(kbIDs) == 0 {
+				return nil, werrors.NewForbiddenError("이 API 키보다 넓은 지식베이스 범위의 키는 생성할 수 없습니다")
+			}
+			for _, id := range kbIDs {
+				if !caller.AllowsKnowledgeBase(id) {
+					return nil, werrors.NewForbiddenError("이 API 키로 접근할 수 없는 지식베이스입니다").WithDetails(id)
+				}
+			}
+		}
+		if caller.ExpiresAt != nil && (req.ExpiresAt == nil || req.ExpiresAt.After(*caller.ExpiresAt)) {
+			req.ExpiresAt = caller.ExpiresAt
+		}
+	}
+
+	random, err := sso.RandomToken(apiKeyRandomBytes)
+	if err != nil {
+		return nil, err
+	}
+	raw := apiKeyPrefix + random
+
+	key := &types.TenantAPIKey{
+		TenantID:         tenantID,
+		Name:             name,
+		Prefix:           raw[:apiKeyDisplayLength],
+		KeyHash:          hashAPIKey(raw),
+		Scopes:           scopes,
+		KnowledgeBaseIDs: kbIDs,
+		ExpiresAt:        req.ExpiresAt,
+	}
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil {
+		key.CreatedBy = subject.UserID
+	}
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		return nil, err
+	}
+
+	logger.Infof(ctx, "API key created, ID: %s, tenant: %d, scopes: %v", key.ID, tenantID, scopes)
+	return &types.CreateAPIKeyResponse{TenantAPIKey: key, Key: raw}, nil
+}
+
+// validateKnowledgeBases checks that the allowlisted knowledge bases belong to the tenant
+func (s *apiKeyService) validateKnowledgeBases(ctx context.Context,
+	tenantID uint64, ids []string,
+) (types.StringArray, error) {
+	kbIDs := make(types.StringArray, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || slices.Contains(kbIDs, id) {
+			continue
+		}
+		kb, err := s.kbService.GetKnowledgeBaseByID(ctx, id)
+		if err != nil || kb.TenantID != tenantID {
+			return nil, werrors.NewNotFoundError("지식베이스를 찾을 수 없습니다").WithDetails(id)
+		}
+		kbIDs = append(kbIDs, id)
+	}
+	return kbIDs, nil
+}
+
+// ListAPIKeys lists the API keys of the tenant in the context
+func (s *apiKeyService) ListAPIKeys(ctx context.Context) ([]*types.TenantAPIKey, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	return s.repo.ListAPIKeys(ctx, tenantID)
+}
+
+// RevokeAPIKey revokes an API key of the tenant in the context
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	if err := s.repo.RevokeAPIKey(ctx, tenantID, id, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return werrors.NewNotFoundError("API 키를 찾을 수 없거나 이미 취소되었습니다")
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"api_key_id": id})
+		return err
+	}
+	logger.Infof(ctx, "API key revoked, ID: %s, tenant: %d", id, tenantID)
+	return nil
+}
+
+// Authenticate returns the active API key matching the given key and records its use
+func (s *apiKeyService) Authenticate(ctx context.Context, raw string) (*types.TenantAPIKey, error) {
+	if !s.IsAPIKey(raw) {
+		return nil, werrors.NewUnauthorizedError("올바르지 않은 API 키입니다")
+	}
+	key, err := s.repo.GetAPIKeyByHash(ctx, hashAPIKey(raw))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, werrors.NewUnauthorizedError("올바르지 않은 API 키입니다")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, werrors.NewUnauthorizedError("취소되었거나 만료된 API 키입니다")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.repo.TouchAPIKey(ctx, key.ID, now, now.Add(-apiKeyTouchInterval)); err != nil {
+			logger.Warnf(ctx, "Failed to record use of API key %s: %v", key.ID, err)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Tencent/WeKnora/internal/application/service/audit"
+	"github.com/Tencent/WeKnora/internal/config"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// auditScanBatchSize is the number of logs read at a time by export and verification
+const auditScanBatchSize = 500
+
+// auditService implements the AuditService interface
+type auditService struct {
+	repo interfaces.AuditRepository
+	cfg  *config.Config
+}
+
+// NewAuditService creates a new audit log service
+func NewAuditService(repo interfaces.AuditRepository, cfg *config.Config) interfaces.AuditService {
+	return &auditService{repo: repo, cfg: cfg}
+}
+
+// hashKey returns the configured HMAC key of the hash chain
+func (s *auditService) hashKey() []byte {
+	if s.cfg == nil || s.cfg.Audit == nil {
+		return nil
+	}
+	return []byte(s.cfg.Audit.HashKey)
+}
+
+// Record appends a log for the tenant and actor in the context, the chain fields are filled in
+func (s *auditService) Record(ctx context.Context, log *types.AuditLog) error {
+	if log.TenantID == 0 {
+		tenantID, ok := ctx.Value(types.TenantIDContextKey).(uint64)
+		if !ok || tenantID == 0 {
+			return errors.New("audit log without tenant")
+		}
+		log.TenantID = tenantID
+	}
+	if log.ActorType == "" {
+		fillAuditActor(ctx, log)
+	}
+	if log.RequestID == "" {
+		log.RequestID, _ = ctx.Value(types.RequestIDContextKey).(string)
+	}
+	if len(log.Changes) == 0 {
+		log.Changes = types.JSON("[]")
+	}
+	if len(log.Detail) == 0 {
+		log.Detail = types.JSON("{}")
+	}
+
+	key := s.hashKey()
+	return s.repo.AppendAuditLog(ctx, log, func(head *types.AuditChainHead) error {
+		log.Seq = head.Seq + 1
+		log.PrevHash = head.Hash
+		log.CreatedAt = audit.Timestamp(time.Now())
+		if log.ID == "" {
+			if err := log.BeforeCreate(nil); err != nil {
+				return err
+			}
+		}
+		hash, err := audit.Hash(key, log)
+		if err != nil {
+			return err
+		}
+		log.Hash = hash
+		return nil
+	})
+}
+
+// fillAuditActor sets the actor of a log from the API key or user in the context
+func fillAuditActor(ctx context.Context, log *types.AuditLog) {
+	if key := types.APIKeyFromContext(ctx); key != nil {
+		log.ActorType = types.AuditActorAPIKey
+		log.ActorID = key.ID
+		log.ActorName = key.Name
+		return
+	}
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil {
+		log.ActorType = types.AuditActorUser
+		log.ActorID = subject.UserID
+		if user, ok := ctx.Value("user").(*types.User); ok && user != nil {
+			log.ActorName = user.Username
+		}
+		return
+	}
+	log.ActorType = types.AuditActorSystem
+}
+
+// ListAuditLogs lists the logs of the tenant in the context, newest first
+func (s *auditService) ListAuditLogs(ctx context.Context,
+	filter *types.AuditLogFilter, page *types.Pagination,
+) (*types.PageResult, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	logs, total, err := s.repo.ListAuditLogs(ctx, tenantID, filter, page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		return nil, err
+	}
+	return types.NewPageResult(total, page, logs), nil
+}
+
+// ExportAuditLogs passes the logs of the tenant in the context to fn, oldest first
+func (s *auditService) ExportAuditLogs(ctx context.Context,
+	filter *types.AuditLogFilter, fn func(log *types.AuditLog) error,
+) error {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	var afterSeq uint64
+	for {
+		logs, err := s.repo.ScanAuditLogs(ctx, tenantID, filter, afterSeq, auditScanBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, log := range logs {
+			if err := fn(log); err != nil {
+				return err
+			}
+			afterSeq = log.Seq
+		}
+		if len(logs) < auditScanBatchSize {
+			return nil
+		}
+	}
+}
+
+// VerifyAuditChain verifies the hash chain of the tenant in the context
+func (s *auditService) VerifyAuditChain(ctx context.Context) (*types.AuditChainVerification, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	head, err := s.repo.GetAuditChainHead(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier := audit.NewVerifier(s.hashKey(), head)
+	afterSeq := head.PurgedThroughSeq
+	for {
+		logs, err := s.repo.ScanAuditLogs(ctx, tenantID, nil, afterSeq, auditScanBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			if !verifier.Add(log) {
+				break
+			}
+			afterSeq = log.Seq
+		}
+		if len(logs) < auditScanBatchSize || afterSeq != logs[len(logs)-1].Seq {
+			break
+		}
+	}
+
+	result := verifier.Result()
+	if !result.Valid {
+		logger.Warnf(ctx, "Audit chain of tenant %d is broken at log %d: %s", tenantID, result.BrokenSeq, result.Reason)
+	}
+	return result, nil
+}
+
+// ProcessAuditRetention deletes the logs older than the retention period, it runs periodically
+func (s *auditService) ProcessAuditRetention(ctx context.Context, t *asynq.Task) error {
+	if s.cfg == nil || s.cfg.Audit == nil || s.cfg.Audit.RetentionDays <= 0 {
+		return nil
+	}
+	before := time.Now().AddDate(0, 0, -s.cfg.Audit.RetentionDays)
+
+	tenantIDs, err := s.repo.ListAuditTenantIDs(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list audit tenants: %v", err)
+		return err
+	}
+	for _, tenantID := range tenantIDs {
+		deleted, err := s.repo.PurgeAuditLogs(ctx, tenantID, before)
+		if err != nil {
+			logger.Errorf(ctx, "Failed to purge audit logs of tenant %d: %v", tenantID, err)
+			continue
+		}
+		if deleted > 0 {
+			logger.Infof(ctx, "Purged %d audit logs of tenant %d older than %s",
+				deleted, tenantID, before.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+func TestDiff(t *testing.T) {
+	before := map[string]interface{}{
+		"name":       "old",
+		"updated_at": "2024-01-01",
+		"parameters": map[string]interface{}{"base_url": "http://a", "api_key": "sk-old"},
+		"max_tokens": 100,
+	}
+	after := map[string]interface{}{
+		"name":       "new",
+		"updated_at": "2024-01-02",
+		"parameters": map[string]interface{}{"base_url": "http://a", "api_key": "sk-new"},
+		"max_tokens": 200,
+	}
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %+v", changes)
+	}
+	if changes[0].Field != "max_tokens" || changes[0].After != json.Number("200") {
+		t.Errorf("max_tokens should not be redacted: %+v", changes[0])
+	}
+	if changes[1].Field != "name" || changes[1].Before != "old" || changes[1].After != "new" {
+		t.Errorf("unexpected name change: %+v", changes[1])
+	}
+	if changes[2].Field != "parameters.api_key" || changes[2].Before != Redacted || changes[2].After != Redacted {
+		t.Errorf("api key should be redacted: %+v", changes[2])
+	}
+}
+
+func TestDiffCreateAndDelete(t *testing.T) {
+	kb := map[string]interface{}{"id": "kb-1", "name": "docs"}
+	created, err := Diff(nil, kb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 || created[0].Before != nil || created[0].After != "kb-1" {
+		t.Errorf("unexpected create diff: %+v", created)
+	}
+	deleted, err := Diff(kb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 2 || deleted[1].Before != "docs" || deleted[1].After != nil {
+		t.Errorf("unexpected delete diff: %+v", deleted)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	redacted, err := Redact(map[string]interface{}{
+		"username": "alice",
+		"password": "hunter2",
+		"items":    []interface{}{map[string]interface{}{"clientSecret": "s"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(redacted)
+	want := `{"items":[{"clientSecret":"[REDACTED]"}],"password":"[REDACTED]","username":"alice"}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+// buildChain appends n logs to a chain and returns the logs and the head
+func buildChain(t *testing.T, key []byte, n int) ([]*types.AuditLog, *types.AuditChainHead) {
+	t.Helper()
+	head := &types.AuditChainHead{TenantID: 1}
+	var logs []*types.AuditLog
+	for i := 0; i < n; i++ {
+		log := &types.AuditLog{
+			ID:        "log-" + string(rune('a'+i)),
+			TenantID:  1,
+			Seq:       head.Seq + 1,
+			ActorType: types.AuditActorUser,
+			ActorID:   "user-1",
+			Action:    "knowledge_base.update",
+			Changes:   types.JSON(`[{"field": "name", "before": "a", "after": "b"}]`),
+			Detail:    types.JSON(`{}`),
+			PrevHash:  head.Hash,
+			CreatedAt: time.Date(2025, 1, 1, 0, 0, i, 123456789, time.UTC),
+		}
+		hash, err := Hash(key, log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		log.Hash = hash
+		head.Seq, head.Hash = log.Seq, log.Hash
+		logs = append(logs, log)
+	}
+	return logs, head
+}
+
+func verify(key []byte, head *types.AuditChainHead, logs []*types.AuditLog) *types.AuditChainVerification {
+	v := NewVerifier(key, head)
+	for _, log := range logs {
+		if !v.Add(log) {
+			break
+		}
+	}
+	return v.Result()
+}
+
+func TestVerifier(t *testing.T) {
+	key := []byte("secret")
+	logs, head := buildChain(t, key, 5)
+
+	if result := verify(key, head, logs); !result.Valid || result.Checked != 5 {
+		t.Fatalf("intact chain should verify: %+v", result)
+	}
+
+	// Loading the log back from the database keeps microseconds and may reformat the JSON
+	reloaded := *logs[2]
+	reloaded.CreatedAt = logs[2].CreatedAt.Truncate(time.Microsecond).In(time.FixedZone("KST", 9*3600))
+	reloaded.Changes = types.JSON(`[{"field":"name","before":"a","after":"b"}]`)
+	chain := []*types.AuditLog{logs[0], logs[1], &reloaded, logs[3], logs[4]}
+	if result := verify(key, head, chain); !result.Valid {
+		t.Errorf("reloaded chain should verify: %+v", result)
+	}
+
+	tampered := *logs[2]
+	tampered.ActorID = "user-2"
+	chain = []*types.AuditLog{logs[0], logs[1], &tampered, logs[3], logs[4]}
+	if result := verify(key, head, chain); result.Valid || result.BrokenSeq != 3 {
+		t.Errorf("tampered log should break the chain at 3: %+v", result)
+	}
+
+	chain = []*types.AuditLog{logs[0], logs[1], logs[3], logs[4]}
+	if result := verify(key, head, chain); result.Valid || result.BrokenSeq != 3 {
+		t.Errorf("deleted log should break the chain at 3: %+v", result)
+	}
+
+	if result := verify(key, head, logs[:4]); result.Valid {
+		t.Errorf("deleted last log should break the chain: %+v", result)
+	}
+
+	if result := verify([]byte("other"), head, logs); result.Valid {
+		t.Errorf("chain should not verify with another key: %+v", result)
+	}
+}
+
+func TestVerifierAfterPurge(t *testing.T) {
+	logs, head := buildChain(t, nil, 5)
+	head.PurgedThroughSeq = 2
+	head.PurgedThroughHash = logs[1].Hash
+	if result := verify(nil, head, logs[2:]); !result.Valid || result.FirstSeq != 3 {
+		t.Errorf("chain after purge should verify: %+v", result)
+	}
+	if result := verify(nil, head, logs[3:]); result.Valid {
+		t.Errorf("log deleted after the purge point should break the chain: %+v", result)
+	}
+}
@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// hashedLog lists the fields of an audit log covered by its hash, in a fixed order
+type hashedLog struct {
+	PrevHash     string               `json:"prev_hash"`
+	ID           string               `json:"id"`
+	TenantID     uint64               `json:"tenant_id"`
+	Seq          uint64               `json:"seq"`
+	ActorType    types.AuditActorType `json:"actor_type"`
+	ActorID      string               `json:"actor_id"`
+	ActorName    string               `json:"actor_name"`
+	Action       string               `json:"action"`
+	ResourceType string               `json:"resource_type"`
+	ResourceID   string               `json:"resource_id"`
+	Method       string               `json:"method"`
+	Path         string               `json:"path"`
+	StatusCode   int                  `json:"status_code"`
+	ClientIP     string               `json:"client_ip"`
+	RequestID    string               `json:"request_id"`
+	Changes      json.RawMessage      `json:"changes"`
+	Detail       json.RawMessage      `json:"detail"`
+	CreatedAt    string               `json:"created_at"`
+}
+
+// Timestamp returns the creation time of a log as stored by the database, which keeps microseconds
+func Timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// Hash computes the hash of an audit log, which covers the hash of the previous log.
+// With a key the hash is an HMAC, so the chain cannot be recomputed by someone with only database access.
+func Hash(key []byte, log *types.AuditLog) (string, error) {
+	changes, err := compact(log.Changes)
+	if err != nil {
+		return "", fmt.Errorf("compact changes: %w", err)
+	}
+	detail, err := compact(log.Detail)
+	if err != nil {
+		return "", fmt.Errorf("compact detail: %w", err)
+	}
+	data, err := json.Marshal(hashedLog{
+		PrevHash:     log.PrevHash,
+		ID:           log.ID,
+		TenantID:     log.TenantID,
+		Seq:          log.Seq,
+		ActorType:    log.ActorType,
+		ActorID:      log.ActorID,
+		ActorName:    log.ActorName,
+		Action:       log.Action,
+		ResourceType: log.ResourceType,
+		ResourceID:   log.ResourceID,
+		Method:       log.Method,
+		Path:         log.Path,
+		StatusCode:   log.StatusCode,
+		ClientIP:     log.ClientIP,
+		RequestID:    log.RequestID,
+		Changes:      changes,
+		Detail:       detail,
+		CreatedAt:    Timestamp(log.CreatedAt).Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(key) == 0 {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// compact removes insignificant whitespace, an empty value is hashed as null
+func compact(data types.JSON) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Verifier checks the audit logs of a tenant in sequence order against the chain head
+type Verifier struct {
+	key      []byte
+	head     *types.AuditChainHead
+	nextSeq  uint64
+	prevHash string
+	result   types.AuditChainVerification
+}
+
+// NewVerifier creates a verifier for the chain ending at the given head.
+// Logs purged by the retention period are skipped, the chain starts after the last purged log.
+func NewVerifier(key []byte, head *types.AuditChainHead) *Verifier {
+	return &Verifier{
+		key:      key,
+		head:     head,
+		nextSeq:  head.PurgedThroughSeq + 1,
+		prevHash: head.PurgedThroughHash,
+		result:   types.AuditChainVerification{Valid: true},
+	}
+}
+
+// Add checks the next log of the chain and returns false once the chain is broken
+func (v *Verifier) Add(log *types.AuditLog) bool {
+	if !v.result.Valid {
+		return false
+	}
+	if v.result.Checked == 0 {
+		v.result.FirstSeq = log.Seq
+	}
+	switch {
+	case log.Seq != v.nextSeq:
+		return v.fail(v.nextSeq, fmt.Sprintf("expected log %d, found log %d", v.nextSeq, log.Seq))
+	case log.PrevHash != v.prevHash:
+		return v.fail(log.Seq, "previous hash does not match the previous log")
+	}
+	hash, err := Hash(v.key, log)
+	if err != nil {
+		return v.fail(log.Seq, err.Error())
+	}
+	if !hmac.Equal([]byte(hash), []byte(log.Hash)) {
+		return v.fail(log.Seq, "hash does not match the log content")
+	}
+
+	v.result.Checked++
+	v.result.LastSeq = log.Seq
+	v.nextSeq = log.Seq + 1
+	v.prevHash = log.Hash
+	return true
+}
+
+// Result completes the verification by comparing the last log with the chain head
+func (v *Verifier) Result() *types.AuditChainVerification {
+	if v.result.Valid {
+		switch {
+		case v.nextSeq-1 != v.head.Seq:
+			v.fail(v.nextSeq, fmt.Sprintf("chain ends at log %d, head is at log %d", v.nextSeq-1, v.head.Seq))
+		case v.prevHash != v.head.Hash:
+			v.fail(v.head.Seq, "last log does not match the chain head")
+		}
+	}
+	result := v.result
+	return &result
+}
+
+func (v *Verifier) fail(seq uint64, reason string) bool {
+	v.result.Valid = false
+	v.result.BrokenSeq = seq
+	v.result.Reason = reason
+	return false
+}
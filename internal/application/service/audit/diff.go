@@ -0,0 +1,217 @@
+// Package audit implements the field diffs and the hash chain of audit logs
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+const (
+	// Redacted replaces the values of secret fields
+	Redacted = "[REDACTED]"
+	// maxDepth bounds how deep nested objects are flattened, deeper values are compared as a whole
+	maxDepth = 6
+)
+
+// ignoredFields are bookkeeping fields that change on every update
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+	"deleted_at": true,
+}
+
+var (
+	// secretFieldWords are words of a field name marking a secret, "max_tokens" is not a secret
+	secretFieldWords = map[string]bool{
+		"password": true, "passwd": true, "secret": true, "token": true, "credential": true, "credentials": true,
+	}
+	// secretFieldParts are parts of a field name marking a secret
+	secretFieldParts = []string{"api_key", "apikey", "access_key", "accesskey", "private_key", "key_hash"}
+	// secretFieldSuffixes catch camel case names such as "accessToken"
+	secretFieldSuffixes = []string{"password", "secret", "token"}
+)
+
+// IsSecretField reports whether a field name holds a secret
+func IsSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		if secretFieldWords[word] {
+			return true
+		}
+	}
+	for _, part := range secretFieldParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	for _, suffix := range secretFieldSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns the changed fields between two snapshots of a resource, sorted by field path.
+// A nil before lists every field of after as created, a nil after lists every field of before as deleted.
+// Values of secret fields are redacted, so a changed secret only shows that it changed.
+func Diff(before, after interface{}) ([]types.AuditChange, error) {
+	beforeFields, err := flatten(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := flatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool, len(beforeFields)+len(afterFields))
+	for path := range beforeFields {
+		paths[path] = true
+	}
+	for path := range afterFields {
+		paths[path] = true
+	}
+
+	changes := make([]types.AuditChange, 0)
+	for path := range paths {
+		b, inBefore := beforeFields[path]
+		a, inAfter := afterFields[path]
+		if inBefore && inAfter && reflect.DeepEqual(a, b) {
+			continue
+		}
+		change := types.AuditChange{Field: path, Before: redactValue(b), After: redactValue(a)}
+		if isSecretPath(path) {
+			if inBefore && !isEmpty(b) {
+				change.Before = Redacted
+			}
+			if inAfter && !isEmpty(a) {
+				change.After = Redacted
+			}
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// Encode encodes a value as the JSON of an audit log field
+func Encode(v interface{}) types.JSON {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return types.JSON(data)
+}
+
+// Redact returns a JSON copy of the value with the values of secret fields replaced
+func Redact(v interface{}) (interface{}, error) {
+	decoded, err := normalize(v)
+	if err != nil {
+		return nil, err
+	}
+	return redactValue(decoded), nil
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if IsSecretField(k) && !isEmpty(child) {
+				val[k] = Redacted
+				continue
+			}
+			val[k] = redactValue(child)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = redactValue(child)
+		}
+	}
+	return v
+}
+
+// normalize converts a value into its generic JSON form, keeping numbers exact
+func normalize(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var data []byte
+	switch val := v.(type) {
+	case json.RawMessage:
+		data = val
+	case types.JSON:
+		data = val
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// flatten maps the dotted paths of the leaf values of a snapshot to the values
+func flatten(v interface{}) (map[string]interface{}, error) {
+	decoded, err := normalize(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if decoded == nil {
+		return fields, nil
+	}
+	if _, ok := decoded.(map[string]interface{}); !ok {
+		fields["value"] = decoded
+		return fields, nil
+	}
+	flattenInto(fields, "", decoded, 0)
+	return fields, nil
+}
+
+func flattenInto(fields map[string]interface{}, prefix string, v interface{}, depth int) {
+	obj, ok := v.(map[string]interface{})
+	if !ok || depth >= maxDepth || len(obj) == 0 {
+		if prefix != "" {
+			fields[prefix] = v
+		}
+		return
+	}
+	for k, child := range obj {
+		if depth == 0 && ignoredFields[k] {
+			continue
+		}
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		flattenInto(fields, path, child, depth+1)
+	}
+}
+
+// isSecretPath reports whether any segment of a field path names a secret
+func isSecretPath(path string) bool {
+	for _, segment := range strings.Split(path, ".") {
+		if IsSecretField(segment) {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmpty(v interface{}) bool {
+	return v == nil || v == ""
+}
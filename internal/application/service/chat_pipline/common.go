@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Tencent/WeKnora/internal/application/service/memory"
 	"github.com/Tencent/WeKnora/internal/common"
 	"github.com/Tencent/WeKnora/internal/logger"
 	"github.com/Tencent/WeKnora/internal/models/chat"
@@ -55,7 +56,7 @@ func prepareChatModel(ctx context.Context, modelService interfaces.ModelService,
 // prepareMessagesWithHistory prepare complete messages including history
 func prepareMessagesWithHistory(chatManage *types.ChatManage) []chat.Message {
 	// Replace placeholders in system prompt
-	systemPrompt := renderSystemPromptPlaceholders(chatManage.SummaryConfig.Prompt)
+	systemPrompt := renderSystemPromptPlaceholders(chatManage.SummaryConfig.Prompt, chatManage.UserMemories)
 	
 	chatMessages := []chat.Message{
 		{Role: "system", Content: systemPrompt},
@@ -76,7 +77,8 @@ func prepareMessagesWithHistory(chatManage *types.ChatManage) []chat.Message {
 // renderSystemPromptPlaceholders replaces placeholders in system prompt
 // Supported placeholders:
 //   - {{current_time}} -> current time in RFC3339 format
-func renderSystemPromptPlaceholders(prompt string) string {
+//   - {{user_memories}} -> recalled memories of the user, or None
+func renderSystemPromptPlaceholders(prompt string, userMemories string) string {
 	result := prompt
 	
 	// Replace {{current_time}} placeholder
@@ -84,6 +86,9 @@ func renderSystemPromptPlaceholders(prompt string) string {
 		currentTime := time.Now().Format(time.RFC3339)
 		result = strings.ReplaceAll(result, "{{current_time}}", currentTime)
 	}
+
+	// Replace {{user_memories}} placeholder
+	result = memory.Render(result, userMemories)
 	
 	return result
 }
@@ -15,13 +15,17 @@ import (
 
 // PluginRerank implements reranking functionality for chat pipeline
 type PluginRerank struct {
-	modelService interfaces.ModelService // Service to access rerank models
+	modelService    interfaces.ModelService    // Service to access rerank models
+	feedbackService interfaces.FeedbackService // Service providing chunk relevance signals from user feedback
 }
 
 // NewPluginRerank creates a new rerank plugin instance
-func NewPluginRerank(eventManager *EventManager, modelService interfaces.ModelService) *PluginRerank {
+func NewPluginRerank(eventManager *EventManager,
+	modelService interfaces.ModelService, feedbackService interfaces.FeedbackService,
+) *PluginRerank {
 	res := &PluginRerank{
-		modelService: modelService,
+		modelService:    modelService,
+		feedbackService: feedbackService,
 	}
 	eventManager.Register(res)
 	return res
@@ -187,6 +191,7 @@ func (p *PluginRerank) OnEvent(ctx context.Context,
 		})
 		reranked = append(reranked, sr)
 	}
+	p.applyFeedbackSignals(ctx, chatManage, reranked)
 	final := applyMMR(ctx, reranked, chatManage, min(len(reranked), max(1, chatManage.RerankTopK)), 0.7)
 	chatManage.RerankResult = final
 
@@ -267,6 +272,45 @@ func (p *PluginRerank) rerank(ctx context.Context,
 	return rankFilter
 }
 
+// applyFeedbackSignals boosts or demotes the results whose chunks users judged relevant or irrelevant
+func (p *PluginRerank) applyFeedbackSignals(ctx context.Context,
+	chatManage *types.ChatManage, results []*types.SearchResult,
+) {
+	if p.feedbackService == nil || len(results) == 0 {
+		return
+	}
+	tenantID := chatManage.TenantID
+	if tenantID == 0 {
+		tenantID, _ = ctx.Value(types.TenantIDContextKey).(uint64)
+	}
+	chunkIDs := make([]string, 0, len(results))
+	for _, sr := range results {
+		chunkIDs = append(chunkIDs, sr.ID)
+	}
+	multipliers, err := p.feedbackService.ChunkMultipliers(ctx, tenantID, chunkIDs)
+	if err != nil {
+		pipelineWarn(ctx, "Rerank", "feedback_signal", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	for _, sr := range results {
+		multiplier, ok := multipliers[sr.ID]
+		if !ok {
+			continue
+		}
+		originalScore := sr.Score
+		sr.Score = math.Min(sr.Score*multiplier, 1.0)
+		sr.Metadata["feedback_multiplier"] = fmt.Sprintf("%.4f", multiplier)
+		pipelineInfo(ctx, "Rerank", "feedback_signal", map[string]interface{}{
+			"chunk_id":       sr.ID,
+			"original_score": fmt.Sprintf("%.4f", originalScore),
+			"adjusted_score": fmt.Sprintf("%.4f", sr.Score),
+			"multiplier":     multiplier,
+		})
+	}
+}
+
 // ensureMetadata ensures the metadata is not nil
 func ensureMetadata(m map[string]string) map[string]string {
 	if m == nil {
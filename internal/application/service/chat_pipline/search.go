@@ -169,6 +169,7 @@ func (p *PluginSearch) OnEvent(ctx context.Context,
 							MatchCount:           expTopK,
 							DisableVectorMatch:   true,
 							DisableKeywordsMatch: false,
+							Filter:               chatManage.Filter,
 						}
 						// Apply knowledge ID filter if this is a partial KB search
 						if t.Type == types.SearchTargetTypeKnowledge {
@@ -368,6 +369,7 @@ func (p *PluginSearch) searchByTargets(
 				VectorThreshold:  chatManage.VectorThreshold,
 				KeywordThreshold: chatManage.KeywordThreshold,
 				MatchCount:       chatManage.EmbeddingTopK,
+				Filter:           chatManage.Filter,
 			}
 			// Apply knowledge ID filter if this is a partial KB search
 			if t.Type == types.SearchTargetTypeKnowledge {
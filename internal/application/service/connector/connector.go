@@ -0,0 +1,81 @@
+// Package connector implements the external sources knowledge connectors sync from
+package connector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// New creates the source connector of a knowledge connector
+func New(kc *types.KnowledgeConnector) (interfaces.Connector, error) {
+	switch kc.Type {
+	case types.ConnectorTypeLocal:
+		return NewLocalConnector(kc.Config)
+	case types.ConnectorTypeGit:
+		return NewGitConnector(kc.ID, kc.Config)
+	case types.ConnectorTypeS3:
+		return NewS3Connector(kc.Config)
+	case types.ConnectorTypeWeb:
+		return NewWebConnector(kc.Config)
+	default:
+		return nil, fmt.Errorf("unsupported connector type: %s", kc.Type)
+	}
+}
+
+// Validate checks the configuration of a connector without accessing the source
+func Validate(connectorType types.ConnectorType, cfg types.ConnectorConfig) error {
+	switch connectorType {
+	case types.ConnectorTypeLocal:
+		_, err := resolveLocalPath(cfg.Path)
+		return err
+	case types.ConnectorTypeGit:
+		return validateGitConfig(cfg)
+	case types.ConnectorTypeS3:
+		return validateS3Config(cfg)
+	case types.ConnectorTypeWeb:
+		return validateWebConfig(cfg, nil)
+	default:
+		return fmt.Errorf("unsupported connector type: %s", connectorType)
+	}
+}
+
+// Cleanup removes the local state a connector keeps between syncs
+func Cleanup(kc *types.KnowledgeConnector) error {
+	if kc.Type == types.ConnectorTypeGit {
+		return removeGitWorkDir(kc.ID)
+	}
+	return nil
+}
+
+// listingCursor digests the keys and versions of the listed items,
+// the digest is unchanged as long as no item is added, changed or removed
+func listingCursor(items []*types.ConnectorSourceItem) string {
+	sorted := make([]*types.ConnectorSourceItem, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+
+	h := sha256.New()
+	for _, item := range sorted {
+		h.Write([]byte(item.Key))
+		h.Write([]byte{0})
+		h.Write([]byte(item.Version))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isHiddenPath reports whether a slash separated key is or is inside a hidden file or directory
+func isHiddenPath(key string) bool {
+	for _, part := range strings.Split(key, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// gitWorkDirEnv is the directory the checkouts of git connectors are kept in between syncs
+const gitWorkDirEnv = "CONNECTOR_GIT_WORKDIR"
+
+// gitConnector lists the files of a shallow checkout of a git repository branch
+type gitConnector struct {
+	dir string
+	cfg types.ConnectorConfig
+}
+
+// NewGitConnector creates a connector for a git repository
+func NewGitConnector(connectorID string, cfg types.ConnectorConfig) (interfaces.Connector, error) {
+	if err := validateGitConfig(cfg); err != nil {
+		return nil, err
+	}
+	return &gitConnector{dir: gitWorkDir(connectorID), cfg: cfg}, nil
+}
+
+// validateGitConfig checks the repository URL and branch of a git connector
+func validateGitConfig(cfg types.ConnectorConfig) error {
+	if cfg.RepoURL == "" {
+		return errors.New("repo_url is required for git connectors")
+	}
+	u, err := url.Parse(cfg.RepoURL)
+	if err != nil {
+		return fmt.Errorf("invalid repo_url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("repo_url must be an http or https URL")
+	}
+	if u.User != nil {
+		return errors.New("repo_url must not contain credentials, use username and token instead")
+	}
+	if strings.HasPrefix(cfg.Branch, "-") || strings.ContainsAny(cfg.Branch, " \t\n") {
+		return fmt.Errorf("invalid branch: %s", cfg.Branch)
+	}
+	return nil
+}
+
+// gitWorkDir returns the checkout directory of a git connector
+func gitWorkDir(connectorID string) string {
+	base := os.Getenv(gitWorkDirEnv)
+	if base == "" {
+		base = filepath.Join(os.TempDir(), "weknora-connectors", "git")
+	}
+	return filepath.Join(base, filepath.Base(connectorID))
+}
+
+// removeGitWorkDir removes the checkout of a git connector
+func removeGitWorkDir(connectorID string) error {
+	return os.RemoveAll(gitWorkDir(connectorID))
+}
+
+// git runs a git command. Configuration is passed through the environment so that
+// the access token does not show up in the process list, and only http transports are allowed.
+func (c *gitConnector) git(ctx context.Context, dir string, args ...string) (string, error) {
+	configs := [][2]string{
+		{"protocol.allow", "never"},
+		{"protocol.http.allow", "always"},
+		{"protocol.https.allow", "always"},
+	}
+	if c.cfg.Token != "" {
+		username := c.cfg.Username
+		if username == "" {
+			username = "git"
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + c.cfg.Token))
+		configs = append(configs, [2]string{"http.extraHeader", "Authorization: Basic " + credentials})
+	}
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_CONFIG_COUNT="+strconv.Itoa(len(configs)))
+	for i, kv := range configs {
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, kv[0]),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, kv[1]),
+		)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// checkout clones the repository or fetches the latest commit of the branch into the existing checkout
+func (c *gitConnector) checkout(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(c.dir, ".git")); err == nil {
+		remote, err := c.git(ctx, c.dir, "remote", "get-url", "origin")
+		if err == nil && strings.TrimSpace(remote) == c.cfg.RepoURL {
+			ref := c.cfg.Branch
+			if ref == "" {
+				ref = "HEAD"
+			}
+			if _, err := c.git(ctx, c.dir, "fetch", "--depth", "1", "--no-tags", "origin", ref); err != nil {
+				return err
+			}
+			_, err := c.git(ctx, c.dir, "reset", "--hard", "FETCH_HEAD")
+			return err
+		}
+	}
+
+	// Clone again when there is no checkout or the repository has changed
+	if err := os.RemoveAll(c.dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.dir), 0o755); err != nil {
+		return err
+	}
+	args := []string{"clone", "--depth", "1", "--no-tags", "--single-branch"}
+	if c.cfg.Branch != "" {
+		args = append(args, "--branch", c.cfg.Branch)
+	}
+	args = append(args, "--", c.cfg.RepoURL, c.dir)
+	_, err := c.git(ctx, "", args...)
+	return err
+}
+
+// List updates the checkout and lists the files of the latest commit, the cursor is the commit hash.
+// Hidden files, symbolic links and submodules are skipped.
+func (c *gitConnector) List(ctx context.Context) (*types.ConnectorListing, error) {
+	if err := c.checkout(ctx); err != nil {
+		return nil, err
+	}
+	head, err := c.git(ctx, c.dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	tree, err := c.git(ctx, c.dir, "ls-tree", "-r", "-l", "-z", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	return &types.ConnectorListing{Items: parseGitTree(tree), Cursor: strings.TrimSpace(head)}, nil
+}
+
+// parseGitTree parses the output of git ls-tree -r -l -z,
+// entries have the form "<mode> <type> <object> <size>\t<path>"
+func parseGitTree(tree string) []*types.ConnectorSourceItem {
+	var items []*types.ConnectorSourceItem
+	for _, entry := range strings.Split(tree, "\x00") {
+		meta, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 4 || fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+		if isHiddenPath(path) {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[3], 10, 64)
+		items = append(items, &types.ConnectorSourceItem{
+			Key:     path,
+			Version: fields[2],
+			Size:    size,
+		})
+	}
+	return items
+}
+
+// Open opens a listed file of the checkout
+func (c *gitConnector) Open(ctx context.Context, item *types.ConnectorSourceItem) (io.ReadCloser, error) {
+	path := filepath.Join(c.dir, filepath.FromSlash(item.Key))
+	if !isWithin(path, c.dir) {
+		return nil, fmt.Errorf("invalid item key: %s", item.Key)
+	}
+	return os.Open(path)
+}
+
+// Close releases the resources held by the connector, the checkout is kept for the next sync
+func (c *gitConnector) Close() error {
+	return nil
+}
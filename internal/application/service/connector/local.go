@@ -0,0 +1,144 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// localRootsEnv lists the directories local connectors may read, separated by the OS path list separator.
+// Local connectors are disabled when it is not set.
+const localRootsEnv = "CONNECTOR_LOCAL_ROOTS"
+
+// localConnector lists the files of a local or NFS mounted directory
+type localConnector struct {
+	root string
+}
+
+// NewLocalConnector creates a connector for a local directory
+func NewLocalConnector(cfg types.ConnectorConfig) (interfaces.Connector, error) {
+	root, err := resolveLocalPath(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &localConnector{root: root}, nil
+}
+
+// resolveLocalPath resolves a directory path and checks that it is inside an allowed root
+func resolveLocalPath(path string) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		return "", errors.New("path is required for local connectors")
+	}
+	resolved, err := resolveDir(path)
+	if err != nil {
+		return "", err
+	}
+	for _, root := range filepath.SplitList(os.Getenv(localRootsEnv)) {
+		if root == "" {
+			continue
+		}
+		allowed, err := resolveDir(root)
+		if err != nil {
+			continue
+		}
+		if isWithin(resolved, allowed) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("path %s is not inside a directory allowed by %s", path, localRootsEnv)
+}
+
+// resolveDir returns the absolute path of a directory with symbolic links resolved
+func resolveDir(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", path)
+	}
+	return resolved, nil
+}
+
+// isWithin reports whether path is root or inside root
+func isWithin(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// List walks the directory, hidden files and symbolic links are skipped
+func (c *localConnector) List(ctx context.Context) (*types.ConnectorListing, error) {
+	var items []*types.ConnectorSourceItem
+	err := filepath.WalkDir(c.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if path == c.root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(c.root, path)
+		if err != nil {
+			return err
+		}
+		items = append(items, &types.ConnectorSourceItem{
+			Key:        filepath.ToSlash(rel),
+			Version:    fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()),
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory %s: %w", c.root, err)
+	}
+	return &types.ConnectorListing{Items: items, Cursor: listingCursor(items)}, nil
+}
+
+// Open opens a listed file
+func (c *localConnector) Open(ctx context.Context, item *types.ConnectorSourceItem) (io.ReadCloser, error) {
+	path := filepath.Join(c.root, filepath.FromSlash(item.Key))
+	if !isWithin(path, c.root) {
+		return nil, fmt.Errorf("invalid item key: %s", item.Key)
+	}
+	return os.Open(path)
+}
+
+// Close releases the resources held by the connector
+func (c *localConnector) Close() error {
+	return nil
+}
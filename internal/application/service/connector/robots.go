@@ -0,0 +1,130 @@
+package connector
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robotsRule is an allow or disallow rule of robots.txt
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// robotsRules are the robots.txt rules that apply to the crawler
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsGroup collects the rules of the robots.txt groups matching one user agent
+type robotsGroup struct {
+	matched    bool
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// parseRobots parses a robots.txt file and returns the rules for the given user agent token.
+// The groups naming the token take precedence over the * group, as in RFC 9309.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+	var specific, generic robotsGroup
+	var groupAgents []string
+	inRules := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			// A user-agent line after rules starts a new group
+			if inRules {
+				groupAgents = nil
+				inRules = false
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+			continue
+		}
+		if key != "allow" && key != "disallow" && key != "crawl-delay" {
+			continue
+		}
+		inRules = true
+		for _, groupAgent := range groupAgents {
+			var group *robotsGroup
+			switch {
+			case groupAgent == "*":
+				group = &generic
+			case groupAgent == agent:
+				group = &specific
+			default:
+				continue
+			}
+			group.matched = true
+			switch key {
+			case "crawl-delay":
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					group.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			default:
+				// An empty disallow allows everything
+				if value == "" {
+					continue
+				}
+				group.rules = append(group.rules, robotsRule{
+					allow:   key == "allow",
+					length:  len(value),
+					pattern: compileRobotsPattern(value),
+				})
+			}
+		}
+	}
+
+	group := generic
+	if specific.matched {
+		group = specific
+	}
+	return &robotsRules{rules: group.rules, crawlDelay: group.crawlDelay}
+}
+
+// compileRobotsPattern compiles a robots.txt path pattern, * matches any characters and a trailing $ anchors the end
+func compileRobotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed reports whether a path with query may be crawled. The longest matching rule wins,
+// an allow rule wins over a disallow rule of the same length.
+func (r *robotsRules) allowed(requestURI string) bool {
+	if r == nil || requestURI == "/robots.txt" {
+		return true
+	}
+	allow, length := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(requestURI) {
+			continue
+		}
+		if rule.length > length || (rule.length == length && rule.allow) {
+			allow, length = rule.allow, rule.length
+		}
+	}
+	return allow
+}
@@ -0,0 +1,94 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/application/service/file"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"github.com/minio/minio-go/v7"
+)
+
+// s3Connector lists the objects under a prefix of an S3 or MinIO bucket
+type s3Connector struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Connector creates a connector for a prefix of an S3 compatible bucket
+func NewS3Connector(cfg types.ConnectorConfig) (interfaces.Connector, error) {
+	if err := validateS3Config(cfg); err != nil {
+		return nil, err
+	}
+	client, err := file.NewMinioClient(cfg.Endpoint, cfg.AccessKeyID, cfg.SecretAccessKey, cfg.UseSSL)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Connector{client: client, bucket: cfg.Bucket, prefix: normalizeS3Prefix(cfg.Prefix)}, nil
+}
+
+// validateS3Config checks the endpoint and bucket of an S3 connector
+func validateS3Config(cfg types.ConnectorConfig) error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint is required for s3 connectors")
+	}
+	if strings.Contains(cfg.Endpoint, "://") {
+		return errors.New("endpoint must be a host without scheme, use use_ssl for https")
+	}
+	if cfg.Bucket == "" {
+		return errors.New("bucket is required for s3 connectors")
+	}
+	return nil
+}
+
+// normalizeS3Prefix makes a non-empty prefix end with a slash so that it only matches a folder
+func normalizeS3Prefix(prefix string) string {
+	prefix = strings.TrimLeft(prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// List lists the objects under the prefix, keys are relative to the prefix and versions are ETags
+func (c *s3Connector) List(ctx context.Context) (*types.ConnectorListing, error) {
+	var items []*types.ConnectorSourceItem
+	for obj := range c.client.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{
+		Prefix:    c.prefix,
+		Recursive: true,
+	}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("failed to list objects of bucket %s: %w", c.bucket, obj.Err)
+		}
+		key := strings.TrimPrefix(obj.Key, c.prefix)
+		if key == "" || strings.HasSuffix(key, "/") || isHiddenPath(key) {
+			continue
+		}
+		items = append(items, &types.ConnectorSourceItem{
+			Key:        key,
+			Version:    obj.ETag,
+			Size:       obj.Size,
+			ModifiedAt: obj.LastModified,
+		})
+	}
+	return &types.ConnectorListing{Items: items, Cursor: listingCursor(items)}, nil
+}
+
+// Open gets a listed object
+func (c *s3Connector) Open(ctx context.Context, item *types.ConnectorSourceItem) (io.ReadCloser, error) {
+	obj, err := c.client.GetObject(ctx, c.bucket, c.prefix+item.Key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", item.Key, err)
+	}
+	return obj, nil
+}
+
+// Close releases the resources held by the connector
+func (c *s3Connector) Close() error {
+	return nil
+}
@@ -0,0 +1,44 @@
+package connector
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// sitemapDocument is a sitemap urlset or a sitemap index
+type sitemapDocument struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// parseSitemap parses a sitemap and returns the page URLs it lists and the sitemaps a sitemap index refers to
+func parseSitemap(r io.Reader) (pages []string, sitemaps []string, err error) {
+	var doc sitemapDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+	for _, u := range doc.URLs {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			pages = append(pages, loc)
+		}
+	}
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			sitemaps = append(sitemaps, loc)
+		}
+	}
+	return pages, sitemaps, nil
+}
+
+// isSitemapURL reports whether a seed URL points to a sitemap instead of a page
+func isSitemapURL(u *url.URL) bool {
+	name := strings.ToLower(path.Base(u.Path))
+	return strings.HasSuffix(name, ".xml") || strings.HasSuffix(name, ".xml.gz")
+}
@@ -0,0 +1,424 @@
+package connector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/Tencent/WeKnora/internal/application/service/webhook"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+const (
+	// webUserAgent identifies the crawler, webRobotsAgent is the token it looks for in robots.txt
+	webUserAgent   = "Mozilla/5.0 (compatible; WeKnoraBot/1.0; +https://github.com/Tencent/WeKnora)"
+	webRobotsAgent = "weknorabot"
+
+	webDefaultMaxDepth = 3
+	webDefaultMaxPages = 500
+	webMaxDepthLimit   = 20
+	webMaxPagesLimit   = 10000
+	// webMaxSitemaps bounds the sitemaps read through sitemap indexes
+	webMaxSitemaps = 100
+	// webMaxPageBytes bounds the size of a fetched page or sitemap
+	webMaxPageBytes = 10 << 20
+	// webMaxCrawlDelay caps the crawl delay requested by robots.txt
+	webMaxCrawlDelay  = 10 * time.Second
+	webRequestTimeout = 30 * time.Second
+	// webMaxRedirects bounds the redirects followed for one request
+	webMaxRedirects = 10
+)
+
+// webConnector crawls the pages of a website from a seed page or a sitemap.
+// Only links on the host of the seed are followed and robots.txt is honored.
+// Pages are listed with their URL and ingested by URL rather than opened as files.
+type webConnector struct {
+	seed     *url.URL
+	maxDepth int
+	maxPages int
+	client   *http.Client
+}
+
+// webPage is a fetched HTML page
+type webPage struct {
+	url      *url.URL
+	version  string
+	title    string
+	links    []*url.URL
+	noindex  bool
+	nofollow bool
+}
+
+// errPageGone is returned for pages that no longer exist or may not be crawled
+var errPageGone = errors.New("page is gone")
+
+// NewWebConnector creates a connector crawling a website
+func NewWebConnector(cfg types.ConnectorConfig) (interfaces.Connector, error) {
+	return newWebConnector(cfg, nil)
+}
+
+// newWebConnector creates a web connector that connects only to public addresses,
+// except for the hosts in allowedHosts
+func newWebConnector(cfg types.ConnectorConfig, allowedHosts []string) (*webConnector, error) {
+	if err := validateWebConfig(cfg, allowedHosts); err != nil {
+		return nil, err
+	}
+	seed, _ := url.Parse(cfg.SeedURL)
+	c := &webConnector{
+		seed:     normalizePageURL(seed),
+		maxDepth: cfg.MaxDepth,
+		maxPages: cfg.MaxPages,
+	}
+	// Host names are resolved by the dialer, so a seed or a redirect cannot reach an internal address
+	c.client = &http.Client{
+		Timeout: webRequestTimeout,
+		Transport: &http.Transport{
+			DialContext:         webhook.DialContext(&net.Dialer{Timeout: webRequestTimeout}, allowedHosts),
+			TLSHandshakeTimeout: webRequestTimeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: c.checkRedirect,
+	}
+	if c.maxDepth == 0 {
+		c.maxDepth = webDefaultMaxDepth
+	}
+	if c.maxPages == 0 {
+		c.maxPages = webDefaultMaxPages
+	}
+	return c, nil
+}
+
+// validateWebConfig checks the seed URL and the crawl limits of a web connector
+func validateWebConfig(cfg types.ConnectorConfig, allowedHosts []string) error {
+	if cfg.SeedURL == "" {
+		return errors.New("seed_url is required for web connectors")
+	}
+	u, err := url.Parse(cfg.SeedURL)
+	if err != nil {
+		return fmt.Errorf("invalid seed_url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("seed_url must be an http or https URL")
+	}
+	if u.User != nil {
+		return errors.New("seed_url must not contain credentials")
+	}
+	if err := webhook.CheckHost(allowedHosts, u.Hostname()); err != nil {
+		return errors.New("seed_url must not point to an internal address")
+	}
+	if cfg.MaxDepth < 0 || cfg.MaxDepth > webMaxDepthLimit {
+		return fmt.Errorf("max_depth must be between 0 and %d", webMaxDepthLimit)
+	}
+	if cfg.MaxPages < 0 || cfg.MaxPages > webMaxPagesLimit {
+		return fmt.Errorf("max_pages must be between 0 and %d", webMaxPagesLimit)
+	}
+	return nil
+}
+
+// List crawls the website breadth first. Keys are the paths with query of the pages,
+// versions are the ETag or Last-Modified of a page, or the hash of its content if the server sends neither.
+func (c *webConnector) List(ctx context.Context) (*types.ConnectorListing, error) {
+	robots, err := c.fetchRobots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	delay := min(robots.crawlDelay, webMaxCrawlDelay)
+
+	type crawlEntry struct {
+		url   *url.URL
+		depth int
+	}
+	var queue []crawlEntry
+	visited := make(map[string]bool)
+	enqueue := func(u *url.URL, depth int) {
+		key := u.RequestURI()
+		if !c.inDomain(u) || visited[key] {
+			return
+		}
+		visited[key] = true
+		queue = append(queue, crawlEntry{url: u, depth: depth})
+	}
+
+	if isSitemapURL(c.seed) {
+		pages, err := c.sitemapPages(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, page := range pages {
+			enqueue(page, 0)
+		}
+	} else {
+		enqueue(c.seed, 0)
+	}
+
+	var items []*types.ConnectorSourceItem
+	fetched := 0
+	for len(queue) > 0 && fetched < c.maxPages {
+		entry := queue[0]
+		queue = queue[1:]
+		if !robots.allowed(entry.url.RequestURI()) {
+			continue
+		}
+		if fetched > 0 && delay > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		fetched++
+
+		page, err := c.fetchPage(ctx, entry.url)
+		if errors.Is(err, errPageGone) {
+			continue
+		}
+		if err != nil {
+			// A page that could not be fetched would otherwise be deleted from the knowledge base
+			return nil, err
+		}
+		// Redirects may lead off the site or to a page already crawled
+		if !c.inDomain(page.url) {
+			continue
+		}
+		if page.url.RequestURI() != entry.url.RequestURI() {
+			if visited[page.url.RequestURI()] {
+				continue
+			}
+			visited[page.url.RequestURI()] = true
+		}
+		if !page.noindex {
+			items = append(items, &types.ConnectorSourceItem{
+				Key:     page.url.RequestURI(),
+				Version: page.version,
+				URL:     page.url.String(),
+				Title:   page.title,
+			})
+		}
+		if !page.nofollow && entry.depth < c.maxDepth {
+			for _, link := range page.links {
+				enqueue(link, entry.depth+1)
+			}
+		}
+	}
+	return &types.ConnectorListing{Items: items, Cursor: listingCursor(items)}, nil
+}
+
+// Open is not supported, web pages are ingested by URL
+func (c *webConnector) Open(ctx context.Context, item *types.ConnectorSourceItem) (io.ReadCloser, error) {
+	return nil, errors.New("web pages are ingested by URL and cannot be opened")
+}
+
+// Close releases the idle connections of the crawler
+func (c *webConnector) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// inDomain reports whether a URL is on the host of the seed
+func (c *webConnector) inDomain(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && strings.EqualFold(u.Host, c.seed.Host)
+}
+
+// checkRedirect stops redirects that leave the host of the seed, the page is then skipped as gone
+func (c *webConnector) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= webMaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", webMaxRedirects)
+	}
+	if !c.inDomain(req.URL) {
+		return fmt.Errorf("%w: redirect to %s leaves the site", errPageGone, req.URL.Host)
+	}
+	return nil
+}
+
+// get sends a GET request with the crawler user agent
+func (c *webConnector) get(ctx context.Context, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", webUserAgent)
+	return c.client.Do(req)
+}
+
+// fetchRobots fetches the robots.txt of the site. A missing robots.txt allows everything,
+// a robots.txt that cannot be fetched fails the crawl instead of crawling without rules.
+func (c *webConnector) fetchRobots(ctx context.Context) (*robotsRules, error) {
+	robotsURL := &url.URL{Scheme: c.seed.Scheme, Host: c.seed.Host, Path: "/robots.txt"}
+	resp, err := c.get(ctx, robotsURL)
+	if errors.Is(err, errPageGone) {
+		// A robots.txt redirected off the site is treated as missing
+		return &robotsRules{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch robots.txt: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return parseRobots(io.LimitReader(resp.Body, 512<<10), webRobotsAgent), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return &robotsRules{}, nil
+	default:
+		return nil, fmt.Errorf("failed to fetch robots.txt: status %d", resp.StatusCode)
+	}
+}
+
+// sitemapPages reads the sitemap seed, following sitemap indexes, and returns the page URLs it lists
+func (c *webConnector) sitemapPages(ctx context.Context) ([]*url.URL, error) {
+	var pages []*url.URL
+	queue := []*url.URL{c.seed}
+	seen := map[string]bool{c.seed.String(): true}
+	for read := 0; len(queue) > 0 && read < webMaxSitemaps; read++ {
+		sitemap := queue[0]
+		queue = queue[1:]
+		locs, children, err := c.fetchSitemap(ctx, sitemap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sitemap %s: %w", sitemap, err)
+		}
+		for _, loc := range locs {
+			if u, err := sitemap.Parse(loc); err == nil {
+				pages = append(pages, normalizePageURL(u))
+			}
+		}
+		for _, loc := range children {
+			u, err := sitemap.Parse(loc)
+			if err != nil || !c.inDomain(u) || seen[u.String()] {
+				continue
+			}
+			seen[u.String()] = true
+			queue = append(queue, u)
+		}
+	}
+	return pages, nil
+}
+
+// fetchSitemap fetches and parses a sitemap, gzip compressed sitemaps are decompressed
+func (c *webConnector) fetchSitemap(ctx context.Context, u *url.URL) ([]string, []string, error) {
+	resp, err := c.get(ctx, u)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var body io.Reader = io.LimitReader(resp.Body, webMaxPageBytes)
+	if strings.HasSuffix(strings.ToLower(u.Path), ".gz") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer gz.Close()
+		body = io.LimitReader(gz, webMaxPageBytes)
+	}
+	return parseSitemap(body)
+}
+
+// fetchPage fetches an HTML page and extracts its title and links.
+// Missing pages, other client errors and non-HTML content return errPageGone.
+func (c *webConnector) fetchPage(ctx context.Context, u *url.URL) (*webPage, error) {
+	resp, err := c.get(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return nil, errPageGone
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: status %d", u, resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, errPageGone
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webMaxPageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", u, err)
+	}
+
+	page, err := parsePage(normalizePageURL(resp.Request.URL), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", u, err)
+	}
+	switch {
+	case resp.Header.Get("ETag") != "":
+		page.version = resp.Header.Get("ETag")
+	case resp.Header.Get("Last-Modified") != "":
+		page.version = resp.Header.Get("Last-Modified")
+	default:
+		sum := sha256.Sum256(body)
+		page.version = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	if robotsTag := strings.ToLower(resp.Header.Get("X-Robots-Tag")); robotsTag != "" {
+		page.noindex = page.noindex || strings.Contains(robotsTag, "noindex") || strings.Contains(robotsTag, "none")
+		page.nofollow = page.nofollow || strings.Contains(robotsTag, "nofollow") || strings.Contains(robotsTag, "none")
+	}
+	return page, nil
+}
+
+// parsePage extracts the title, the robots meta directives and the links of an HTML page
+func parsePage(pageURL *url.URL, body []byte) (*webPage, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	page := &webPage{url: pageURL, title: strings.TrimSpace(doc.Find("title").First().Text())}
+
+	doc.Find("meta[name]").Each(func(_ int, s *goquery.Selection) {
+		name := strings.ToLower(s.AttrOr("name", ""))
+		if name != "robots" && name != webRobotsAgent {
+			return
+		}
+		content := strings.ToLower(s.AttrOr("content", ""))
+		page.noindex = page.noindex || strings.Contains(content, "noindex") || strings.Contains(content, "none")
+		page.nofollow = page.nofollow || strings.Contains(content, "nofollow") || strings.Contains(content, "none")
+	})
+
+	base := pageURL
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := pageURL.Parse(strings.TrimSpace(href)); err == nil {
+			base = u
+		}
+	}
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		if strings.Contains(strings.ToLower(s.AttrOr("rel", "")), "nofollow") {
+			return
+		}
+		u, err := base.Parse(strings.TrimSpace(s.AttrOr("href", "")))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return
+		}
+		page.links = append(page.links, normalizePageURL(u))
+	})
+	return page, nil
+}
+
+// normalizePageURL drops the fragment and credentials of a URL and lowercases its scheme and host
+func normalizePageURL(u *url.URL) *url.URL {
+	n := *u
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Host = strings.ToLower(n.Host)
+	n.User = nil
+	n.Fragment = ""
+	n.RawFragment = ""
+	if n.Path == "" {
+		n.Path = "/"
+		n.RawPath = ""
+	}
+	return &n
+}
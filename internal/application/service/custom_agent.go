@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,6 +20,8 @@ var (
 	ErrCannotModifyBuiltin = errors.New("기본 에이전트의 기본 정보는 수정할 수 없습니다")
 	ErrCannotDeleteBuiltin = errors.New("기본 에이전트는 삭제할 수 없습니다")
 	ErrAgentNameRequired   = errors.New("에이전트 이름은 필수입니다")
+	ErrInvalidToolApproval = errors.New("도구 승인 정책은 auto, ask, deny 중 하나여야 합니다")
+	ErrInvalidSubAgent     = errors.New("에이전트는 자기 자신에게 위임할 수 없습니다")
 )
 
 // customAgentService implements the CustomAgentService interface
@@ -39,6 +42,9 @@ func (s *customAgentService) CreateAgent(ctx context.Context, agent *types.Custo
 	if strings.TrimSpace(agent.Name) == "" {
 		return nil, ErrAgentNameRequired
 	}
+	if !validToolApprovalConfig(&agent.Config) {
+		return nil, ErrInvalidToolApproval
+	}
 
 	// Generate UUID and set creation timestamps
 	if agent.ID == "" {
@@ -193,6 +199,13 @@ func (s *customAgentService) UpdateAgent(ctx context.Context, agent *types.Custo
 		return nil, ErrInvalidTenantID
 	}
 
+	if !validToolApprovalConfig(&agent.Config) {
+		return nil, ErrInvalidToolApproval
+	}
+	if slices.Contains(agent.Config.SubAgents, agent.ID) {
+		return nil, ErrInvalidSubAgent
+	}
+
 	// Handle built-in agents specially using registry
 	if types.IsBuiltinAgentID(agent.ID) {
 		return s.updateBuiltinAgent(ctx, agent, tenantID)
@@ -394,3 +407,9 @@ func (s *customAgentService) CopyAgent(ctx context.Context, id string) (*types.C
 	logger.Infof(ctx, "Agent copied successfully, source ID: %s, new ID: %s", id, newAgent.ID)
 	return newAgent, nil
 }
+
+// validToolApprovalConfig checks that every tool and MCP service approval policy is supported
+func validToolApprovalConfig(config *types.CustomAgentConfig) bool {
+	return types.ValidToolApprovalPolicies(config.ToolApprovalPolicies) &&
+		types.ValidToolApprovalPolicies(config.MCPApprovalPolicies)
+}
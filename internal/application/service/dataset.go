@@ -4,19 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
 
+	"github.com/Tencent/WeKnora/internal/application/repository"
+	"github.com/Tencent/WeKnora/internal/application/service/evaluation"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
 	"github.com/Tencent/WeKnora/internal/logger"
 	"github.com/Tencent/WeKnora/internal/types"
 	"github.com/Tencent/WeKnora/internal/types/interfaces"
 	"github.com/parquet-go/parquet-go"
 )
 
-// DatasetService provides operations for working with datasets
-type DatasetService struct{}
+// maxDatasetItems is the maximum number of questions of an uploaded dataset
+const maxDatasetItems = 5000
+
+// DatasetService provides operations for working with datasets.
+// The built-in sample dataset is read from parquet files, uploaded datasets are stored in the database.
+type DatasetService struct {
+	repo interfaces.EvaluationRepository
+}
 
 // NewDatasetService creates a new DatasetService instance
-func NewDatasetService() interfaces.DatasetService {
-	return &DatasetService{}
+func NewDatasetService(repo interfaces.EvaluationRepository) interfaces.DatasetService {
+	return &DatasetService{repo: repo}
 }
 
 // TextInfo represents text data with ID in parquet format
@@ -50,6 +62,103 @@ func (d *DatasetService) GetDatasetByID(ctx context.Context, datasetID string) (
 	return qaPairs, nil
 }
 
+// CreateDataset parses an uploaded CSV or JSONL file into a dataset of the tenant in the context
+func (d *DatasetService) CreateDataset(ctx context.Context,
+	name, description, filename string, r io.Reader,
+) (*types.EvaluationDataset, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	format := evaluation.DetectFormat(filename)
+	if format == "" {
+		return nil, werrors.NewBadRequestError("데이터셋 파일은 CSV 또는 JSONL 형식이어야 합니다")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+
+	items, err := evaluation.ParseDataset(r, format, maxDatasetItems)
+	if err != nil {
+		return nil, werrors.NewBadRequestError("데이터셋 파일을 해석할 수 없습니다").WithDetails(err.Error())
+	}
+
+	dataset := &types.EvaluationDataset{
+		TenantID:    tenantID,
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		Format:      format,
+		ItemCount:   len(items),
+	}
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil {
+		dataset.CreatedBy = subject.UserID
+	}
+	if err := d.repo.CreateDataset(ctx, dataset, items); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"filename": filename})
+		return nil, err
+	}
+	logger.Infof(ctx, "Created evaluation dataset %s with %d questions", dataset.ID, len(items))
+	return dataset, nil
+}
+
+// ListDatasets lists the uploaded datasets of the tenant in the context, newest first
+func (d *DatasetService) ListDatasets(ctx context.Context, page *types.Pagination) (*types.PageResult, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	datasets, total, err := d.repo.ListDatasets(ctx, tenantID, page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		return nil, err
+	}
+	return types.NewPageResult(total, page, datasets), nil
+}
+
+// GetDataset gets an uploaded dataset of the tenant in the context
+func (d *DatasetService) GetDataset(ctx context.Context, id string) (*types.EvaluationDataset, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	dataset, err := d.repo.GetDataset(ctx, tenantID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEvaluationDatasetNotFound) {
+			return nil, werrors.NewNotFoundError("평가 데이터셋을 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	return dataset, nil
+}
+
+// ListDatasetItems lists the questions of an uploaded dataset in order
+func (d *DatasetService) ListDatasetItems(ctx context.Context,
+	id string, page *types.Pagination,
+) (*types.PageResult, error) {
+	if _, err := d.GetDataset(ctx, id); err != nil {
+		return nil, err
+	}
+	items, total, err := d.repo.ListDatasetItems(ctx, id, page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"dataset_id": id})
+		return nil, err
+	}
+	return types.NewPageResult(total, page, items), nil
+}
+
+// GetDatasetItems gets all questions of an uploaded dataset in order
+func (d *DatasetService) GetDatasetItems(ctx context.Context, id string) ([]*types.EvaluationDatasetItem, error) {
+	if _, err := d.GetDataset(ctx, id); err != nil {
+		return nil, err
+	}
+	return d.repo.GetDatasetItems(ctx, id)
+}
+
+// DeleteDataset deletes an uploaded dataset, runs made with it are kept
+func (d *DatasetService) DeleteDataset(ctx context.Context, id string) error {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	if err := d.repo.DeleteDataset(ctx, tenantID, id); err != nil {
+		if errors.Is(err, repository.ErrEvaluationDatasetNotFound) {
+			return werrors.NewNotFoundError("평가 데이터셋을 찾을 수 없습니다")
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"dataset_id": id})
+		return err
+	}
+	return nil
+}
+
 // DefaultDataset loads and initializes the default dataset from parquet files
 func DefaultDataset() dataset {
 	datasetDir := "./dataset/samples"
@@ -5,14 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/Tencent/WeKnora/internal/application/repository"
+	"github.com/Tencent/WeKnora/internal/application/service/evaluation"
 	"github.com/Tencent/WeKnora/internal/config"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
 	"github.com/Tencent/WeKnora/internal/logger"
 	"github.com/Tencent/WeKnora/internal/types"
 	"github.com/Tencent/WeKnora/internal/types/interfaces"
-	"github.com/google/uuid"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -24,7 +27,11 @@ qrels: qid -> pid
 arels: qid -> aid
 */
 
+// evaluationStaleTimeout 진행 상황 갱신 없이 이 시간이 지난 실행은 서버 재시작 등으로 중단된 것으로 간주
+const evaluationStaleTimeout = 30 * time.Minute
+
 // EvaluationService 지식베이스 및 채팅 모델 평가 작업 처리
+// 평가 실행과 질문별 결과는 데이터베이스에 저장되어 재시작 후에도 조회하고 비교할 수 있음
 type EvaluationService struct {
 	config               *config.Config                  // 애플리케이션 구성
 	dataset              interfaces.DatasetService       // 데이터셋 작업 서비스
@@ -32,8 +39,9 @@ type EvaluationService struct {
 	knowledgeService     interfaces.KnowledgeService     // 지식 작업 서비스
 	sessionService       interfaces.SessionService       // 채팅 세션 서비스
 	modelService         interfaces.ModelService         // 모델 작업 서비스
-
-	evaluationMemoryStorage *evaluationMemoryStorage // 평가 작업을 위한 인메모리 저장소
+	customAgentService   interfaces.CustomAgentService   // 평가할 에이전트 구성 조회
+	permissionService    interfaces.PermissionService    // 평가 대상 리소스 권한 검사
+	repo                 interfaces.EvaluationRepository // 평가 실행 저장소
 }
 
 func NewEvaluationService(
@@ -43,414 +51,639 @@ func NewEvaluationService(
 	knowledgeService interfaces.KnowledgeService,
 	sessionService interfaces.SessionService,
 	modelService interfaces.ModelService,
+	customAgentService interfaces.CustomAgentService,
+	permissionService interfaces.PermissionService,
+	repo interfaces.EvaluationRepository,
 ) interfaces.EvaluationService {
-	evaluationMemoryStorage := newEvaluationMemoryStorage()
 	return &EvaluationService{
-		config:                  config,
-		dataset:                 dataset,
-		knowledgeBaseService:    knowledgeBaseService,
-		knowledgeService:        knowledgeService,
-		sessionService:          sessionService,
-		modelService:            modelService,
-		evaluationMemoryStorage: evaluationMemoryStorage,
-	}
-}
-
-// evaluationMemoryStorage 평가 작업을 메모리에 저장하고 스레드로부터 안전한 액세스 제공
-type evaluationMemoryStorage struct {
-	store map[string]*types.EvaluationDetail // 작업 ID와 평가 세부 정보 매핑
-	mu    *sync.RWMutex                      // 동시 액세스를 위한 읽기-쓰기 잠금
-}
-
-func newEvaluationMemoryStorage() *evaluationMemoryStorage {
-	res := &evaluationMemoryStorage{
-		store: make(map[string]*types.EvaluationDetail),
-		mu:    &sync.RWMutex{},
+		config:               config,
+		dataset:              dataset,
+		knowledgeBaseService: knowledgeBaseService,
+		knowledgeService:     knowledgeService,
+		sessionService:       sessionService,
+		modelService:         modelService,
+		customAgentService:   customAgentService,
+		permissionService:    permissionService,
+		repo:                 repo,
 	}
-	return res
 }
 
-func (e *evaluationMemoryStorage) register(params *types.EvaluationDetail) {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-	logger.Infof(context.Background(), "Registering evaluation task: %s", params.Task.ID)
-	e.store[params.Task.ID] = params
-}
-
-func (e *evaluationMemoryStorage) get(taskID string) (*types.EvaluationDetail, error) {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
-	logger.Infof(context.Background(), "Getting evaluation task: %s", taskID)
-	res, ok := e.store[taskID]
-	if !ok {
-		return nil, errors.New("task not found")
-	}
-	return res, nil
-}
-
-func (e *evaluationMemoryStorage) update(taskID string, fn func(params *types.EvaluationDetail)) error {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-	params, ok := e.store[taskID]
-	if !ok {
-		return errors.New("task not found")
-	}
-	fn(params)
-	return nil
+// evalQuestion 평가할 질문 하나
+type evalQuestion struct {
+	seq      int
+	question string
+	answer   string   // 정답, 없으면 생성 메트릭을 계산하지 않음
+	gold     []string // 정답 청크 ID, 없으면 검색 메트릭을 계산하지 않음
 }
 
+// EvaluationResult 작업 ID(실행 ID)로 평가 결과 조회
 func (e *EvaluationService) EvaluationResult(ctx context.Context, taskID string) (*types.EvaluationDetail, error) {
-	logger.Info(ctx, "Start getting evaluation result")
-	logger.Infof(ctx, "Task ID: %s", taskID)
+	logger.Infof(ctx, "Getting evaluation result, task ID: %s", taskID)
 
-	detail, err := e.evaluationMemoryStorage.get(taskID)
+	run, err := e.GetRun(ctx, taskID)
 	if err != nil {
-		logger.Errorf(ctx, "Failed to get evaluation task: %v", err)
 		return nil, err
 	}
-
-	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
-	logger.Infof(
-		ctx,
-		"Checking tenant ID match, task tenant ID: %d, current tenant ID: %d",
-		detail.Task.TenantID, tenantID,
-	)
-
-	if tenantID != detail.Task.TenantID {
-		logger.Error(ctx, "Tenant ID mismatch")
-		return nil, errors.New("tenant ID does not match")
-	}
-
-	logger.Info(ctx, "Evaluation result retrieved successfully")
-	return detail, nil
+	return e.toDetail(run), nil
 }
 
-// Evaluation 지정된 매개변수로 새로운 평가 작업 시작
-// datasetID: 평가할 데이터셋 ID
-// knowledgeBaseID: 사용할 지식베이스 ID (비어 있으면 새로 생성)
-// chatModelID: 평가할 채팅 모델 ID
-// rerankModelID: 평가할 재순위 모델 ID
+// Evaluation 지정된 매개변수로 새로운 평가 실행 시작
+// 기본 데이터셋은 지식베이스의 모델을 복사한 임시 지식베이스에 패시지를 넣어 평가하고,
+// 업로드한 데이터셋은 지정한 지식베이스(없으면 에이전트의 지식베이스)를 그대로 검색하여 평가함
+// 검색 및 생성 설정은 대화 기본값, 에이전트 구성, 요청의 모델 지정 순으로 덮어씀
 func (e *EvaluationService) Evaluation(ctx context.Context,
-	datasetID string, knowledgeBaseID string, chatModelID string, rerankModelID string,
+	req *types.EvaluationRequest,
 ) (*types.EvaluationDetail, error) {
-	logger.Info(ctx, "Start evaluation")
-	logger.Infof(ctx, "Dataset ID: %s, Knowledge Base ID: %s, Chat Model ID: %s, Rerank Model ID: %s",
-		datasetID, knowledgeBaseID, chatModelID, rerankModelID)
+	logger.Infof(ctx, "Start evaluation, dataset ID: %s, knowledge base ID: %s, agent ID: %s",
+		req.DatasetID, req.KnowledgeBaseID, req.AgentID)
 
 	// 멀티 테넌트 지원을 위해 컨텍스트에서 테넌트 ID 가져오기
 	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
-	logger.Infof(ctx, "Tenant ID: %d", tenantID)
-
-	// 지식베이스 ID가 제공되지 않은 경우 생성 처리
-	if knowledgeBaseID == "" {
-		logger.Info(ctx, "No knowledge base ID provided, creating new knowledge base")
-		// 기본 평가 설정으로 새 지식베이스 생성
-		// 기본 임베딩 모델과 LLM 모델 가져오기
-		models, err := e.modelService.ListModels(ctx)
-		if err != nil {
-			logger.Errorf(ctx, "Failed to list models: %v", err)
-			return nil, err
-		}
 
-		var embeddingModelID, llmModelID string
-		for _, model := range models {
-			if model == nil {
-				continue
-			}
-			if model.Type == types.ModelTypeEmbedding {
-				embeddingModelID = model.ID
-			}
-			if model.Type == types.ModelTypeKnowledgeQA {
-				llmModelID = model.ID
+	datasetID := req.DatasetID
+	if datasetID == "" {
+		datasetID = types.DefaultEvaluationDatasetID
+		logger.Info(ctx, "Using default dataset")
+	}
+
+	// 평가할 에이전트 구성 가져오기
+	var agent *types.CustomAgent
+	if req.AgentID != "" {
+		var err error
+		agent, err = e.customAgentService.GetAgentByID(ctx, req.AgentID)
+		if err != nil {
+			if errors.Is(err, ErrAgentNotFound) {
+				return nil, werrors.NewNotFoundError("에이전트를 찾을 수 없습니다")
 			}
+			return nil, err
 		}
-
-		if embeddingModelID == "" || llmModelID == "" {
-			return nil, fmt.Errorf("no default models found for evaluation")
+		// 읽기 권한이 있는 에이전트와 지식베이스만 평가할 수 있음
+		if err := evaluation.CheckAccess(ctx, e.permissionService, agent.ID); err != nil {
+			return nil, err
 		}
+		agent.EnsureDefaults()
+	}
+	runConfig := e.runConfig(agent, req)
 
-		kb, err := e.knowledgeBaseService.CreateKnowledgeBase(ctx, &types.KnowledgeBase{
-			Name:             "evaluation",
-			Description:      "evaluation",
-			EmbeddingModelID: embeddingModelID,
-			SummaryModelID:   llmModelID,
-		})
+	// 평가 대상 질문과 지식베이스 결정
+	var (
+		items            []*types.EvaluationDatasetItem
+		knowledgeBaseIDs []string
+	)
+	if datasetID == types.DefaultEvaluationDatasetID {
+		kb, err := e.createTemporaryKnowledgeBase(ctx, req.KnowledgeBaseID)
 		if err != nil {
-			logger.Errorf(ctx, "Failed to create knowledge base: %v", err)
 			return nil, err
 		}
-		knowledgeBaseID = kb.ID
-		logger.Infof(ctx, "Created new knowledge base with ID: %s", knowledgeBaseID)
+		knowledgeBaseIDs = []string{kb.ID}
 	} else {
-		logger.Infof(ctx, "Using existing knowledge base ID: %s", knowledgeBaseID)
-		// 기존 지식베이스를 기반으로 평가 전용 지식베이스 생성
-		kb, err := e.knowledgeBaseService.GetKnowledgeBaseByID(ctx, knowledgeBaseID)
+		var err error
+		items, err = e.dataset.GetDatasetItems(ctx, datasetID)
 		if err != nil {
-			logger.Errorf(ctx, "Failed to get knowledge base: %v", err)
 			return nil, err
 		}
-
-		kb, err = e.knowledgeBaseService.CreateKnowledgeBase(ctx, &types.KnowledgeBase{
-			Name:             "evaluation",
-			Description:      "evaluation",
-			EmbeddingModelID: kb.EmbeddingModelID,
-			SummaryModelID:   kb.SummaryModelID,
-		})
-		if err != nil {
-			logger.Errorf(ctx, "Failed to create knowledge base: %v", err)
+		if req.KnowledgeBaseID != "" {
+			knowledgeBaseIDs = []string{req.KnowledgeBaseID}
+		} else if agent != nil {
+			knowledgeBaseIDs = e.agentKnowledgeBases(ctx, agent)
+		}
+		if len(knowledgeBaseIDs) == 0 {
+			return nil, werrors.NewBadRequestError("평가할 지식베이스 또는 지식베이스가 연결된 에이전트를 지정해야 합니다")
+		}
+		for _, id := range knowledgeBaseIDs {
+			kb, err := e.knowledgeBaseService.GetKnowledgeBaseByID(ctx, id)
+			if err != nil && !errors.Is(err, repository.ErrKnowledgeBaseNotFound) {
+				return nil, err
+			}
+			if err != nil || kb.TenantID != tenantID {
+				return nil, werrors.NewNotFoundError("지식베이스를 찾을 수 없습니다: " + id)
+			}
+		}
+		if err := evaluation.CheckAccess(ctx, e.permissionService, "", knowledgeBaseIDs...); err != nil {
 			return nil, err
 		}
-		knowledgeBaseID = kb.ID
-		logger.Infof(ctx, "Created new knowledge base with ID: %s based on existing one", knowledgeBaseID)
 	}
 
-	// 선택적 매개변수의 기본값 설정
-	if datasetID == "" {
-		datasetID = "default"
-		logger.Info(ctx, "Using default dataset")
-	}
-
-	if rerankModelID == "" {
-		// 기본 재순위 모델 가져오기
-		models, err := e.modelService.ListModels(ctx)
-		if err == nil {
-			for _, model := range models {
-				if model == nil {
-					continue
-				}
-				if model.Type == types.ModelTypeRerank {
-					rerankModelID = model.ID
-					break
-				}
-			}
-		}
-		if rerankModelID == "" {
+	// 모델이 지정되지 않은 경우 기본 모델 사용
+	if runConfig.RerankModelID == "" {
+		runConfig.RerankModelID = e.defaultModelID(ctx, types.ModelTypeRerank)
+		if runConfig.RerankModelID == "" {
 			logger.Warnf(ctx, "No rerank model found, skipping rerank")
-		} else {
-			logger.Infof(ctx, "Using default rerank model: %s", rerankModelID)
-		}
-	}
-
-	if chatModelID == "" {
-		// 기본 LLM 모델 가져오기
-		models, err := e.modelService.ListModels(ctx)
-		if err == nil {
-			for _, model := range models {
-				if model == nil {
-					continue
-				}
-				if model.Type == types.ModelTypeKnowledgeQA {
-					chatModelID = model.ID
-					break
-				}
-			}
 		}
-		if chatModelID == "" {
-			return nil, fmt.Errorf("no default chat model found")
+	}
+	if runConfig.ChatModelID == "" {
+		runConfig.ChatModelID = e.defaultModelID(ctx, types.ModelTypeKnowledgeQA)
+		if runConfig.ChatModelID == "" {
+			return nil, werrors.NewBadRequestError("평가에 사용할 채팅 모델이 없습니다")
 		}
-		logger.Infof(ctx, "Using default chat model: %s", chatModelID)
 	}
 
-	// 고유 ID로 평가 작업 생성
-	logger.Info(ctx, "Creating evaluation task")
-	taskID := uuid.New().String()
-	logger.Infof(ctx, "Generated task ID: %s", taskID)
-
-	// 모든 매개변수를 포함한 평가 세부 정보 준비
-	detail := &types.EvaluationDetail{
-		Task: &types.EvaluationTask{
-			ID:        taskID,
-			TenantID:  tenantID,
-			DatasetID: datasetID,
-			Status:    types.EvaluationStatuePending,
-			StartTime: time.Now(),
-		},
-		Params: &types.ChatManage{
-			VectorThreshold:  e.config.Conversation.VectorThreshold,
-			KeywordThreshold: e.config.Conversation.KeywordThreshold,
-			EmbeddingTopK:    e.config.Conversation.EmbeddingTopK,
-			MaxRounds:        e.config.Conversation.MaxRounds,
-			RerankModelID:    rerankModelID,
-			RerankTopK:       e.config.Conversation.RerankTopK,
-			RerankThreshold:  e.config.Conversation.RerankThreshold,
-			ChatModelID:      chatModelID,
-			SummaryConfig: types.SummaryConfig{
-				MaxTokens:           e.config.Conversation.Summary.MaxTokens,
-				RepeatPenalty:       e.config.Conversation.Summary.RepeatPenalty,
-				TopK:                e.config.Conversation.Summary.TopK,
-				TopP:                e.config.Conversation.Summary.TopP,
-				Prompt:              e.config.Conversation.Summary.Prompt,
-				ContextTemplate:     e.config.Conversation.Summary.ContextTemplate,
-				FrequencyPenalty:    e.config.Conversation.Summary.FrequencyPenalty,
-				PresencePenalty:     e.config.Conversation.Summary.PresencePenalty,
-				NoMatchPrefix:       e.config.Conversation.Summary.NoMatchPrefix,
-				Temperature:         e.config.Conversation.Summary.Temperature,
-				Seed:                e.config.Conversation.Summary.Seed,
-				MaxCompletionTokens: e.config.Conversation.Summary.MaxCompletionTokens,
-			},
-			FallbackResponse:    e.config.Conversation.FallbackResponse,
-			RewritePromptSystem: e.config.Conversation.RewritePromptSystem,
-			RewritePromptUser:   e.config.Conversation.RewritePromptUser,
-		},
+	run := &types.EvaluationRun{
+		TenantID:         tenantID,
+		Name:             req.Name,
+		DatasetID:        datasetID,
+		KnowledgeBaseIDs: knowledgeBaseIDs,
+		AgentID:          req.AgentID,
+		Config:           runConfig,
+		Status:           types.EvaluationStatuePending,
+		Total:            len(items),
+		StartedAt:        time.Now(),
 	}
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil {
+		run.CreatedBy = subject.UserID
+	}
+	if err := e.repo.CreateRun(ctx, run); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"dataset_id": datasetID})
+		return nil, err
+	}
+	logger.Infof(ctx, "Evaluation run created, run ID: %s", run.ID)
 
-	// 메모리 저장소에 평가 작업 저장
-	logger.Info(ctx, "Registering evaluation task")
-	e.evaluationMemoryStorage.register(detail)
-
-	// 백그라운드 고루틴에서 평가 시작
-	logger.Info(ctx, "Starting evaluation in background")
-	go func() {
-		// 백그라운드 작업을 위한 로거가 포함된 새 컨텍스트 생성
-		newCtx := logger.CloneContext(ctx)
-		logger.Infof(newCtx, "Background evaluation started for task ID: %s", taskID)
+	// 백그라운드 고루틴에서 평가 시작, 응답에는 시작 시점의 상태를 반환
+	detail := e.toDetail(run)
+	go e.execute(logger.CloneContext(ctx), run, items)
+	return detail, nil
+}
 
-		// 작업 상태를 실행 중으로 업데이트
-		detail.Task.Status = types.EvaluationStatueRunning
-		logger.Info(newCtx, "Evaluation task status set to running")
+// execute 평가 실행을 끝까지 수행하고 최종 상태를 저장
+func (e *EvaluationService) execute(ctx context.Context, run *types.EvaluationRun, items []*types.EvaluationDatasetItem) {
+	logger.Infof(ctx, "Background evaluation started, run ID: %s", run.ID)
+	run.Status = types.EvaluationStatueRunning
+	if err := e.repo.UpdateRun(ctx, run); err != nil {
+		logger.Errorf(ctx, "Failed to update evaluation run: %v, run ID: %s", err, run.ID)
+	}
 
-		// 실제 평가 실행
-		if err := e.EvalDataset(newCtx, detail, knowledgeBaseID); err != nil {
-			detail.Task.Status = types.EvaluationStatueFailed
-			detail.Task.ErrMsg = err.Error()
-			logger.Errorf(newCtx, "Evaluation task failed: %v, task ID: %s", err, taskID)
-			return
+	var err error
+	if run.DatasetID == types.DefaultEvaluationDatasetID {
+		err = e.evalDefaultDataset(ctx, run)
+	} else {
+		questions := make([]*evalQuestion, 0, len(items))
+		for _, item := range items {
+			questions = append(questions, &evalQuestion{
+				seq:      item.Seq,
+				question: item.Question,
+				answer:   item.Answer,
+				gold:     item.GoldChunkIDs,
+			})
 		}
+		err = e.evalQuestions(ctx, run, questions, func(r *types.SearchResult) string { return r.ID })
+	}
 
-		// 작업을 성공적으로 완료된 것으로 표시
-		logger.Infof(newCtx, "Evaluation task completed successfully, task ID: %s", taskID)
-		detail.Task.Status = types.EvaluationStatueSuccess
-	}()
-
-	logger.Infof(ctx, "Evaluation task created successfully, task ID: %s", taskID)
-	return detail, nil
+	now := time.Now()
+	run.FinishedAt = &now
+	if err != nil {
+		run.Status = types.EvaluationStatueFailed
+		run.ErrMsg = err.Error()
+		logger.Errorf(ctx, "Evaluation run failed: %v, run ID: %s", err, run.ID)
+	} else {
+		run.Status = types.EvaluationStatueSuccess
+		logger.Infof(ctx, "Evaluation run completed successfully, run ID: %s", run.ID)
+	}
+	if err := e.repo.UpdateRun(ctx, run); err != nil {
+		logger.Errorf(ctx, "Failed to update evaluation run: %v, run ID: %s", err, run.ID)
+	}
 }
 
-// EvalDataset 실제 데이터셋 평가 수행
-// 각 QA 쌍을 병렬로 처리하고 메트릭 기록
-func (e *EvaluationService) EvalDataset(ctx context.Context, detail *types.EvaluationDetail, knowledgeBaseID string) error {
-	logger.Info(ctx, "Start evaluating dataset")
-	logger.Infof(ctx, "Task ID: %s, Dataset ID: %s", detail.Task.ID, detail.Task.DatasetID)
+// evalDefaultDataset 기본 데이터셋의 패시지를 임시 지식베이스에 넣어 평가하고 임시 리소스를 정리
+// 정답 청크는 패시지 ID이며 검색된 청크의 인덱스와 비교함
+func (e *EvaluationService) evalDefaultDataset(ctx context.Context, run *types.EvaluationRun) error {
+	knowledgeBaseID := run.KnowledgeBaseIDs[0]
+	defer func() {
+		logger.Infof(ctx, "Cleaning up resources - deleting knowledge base: %s", knowledgeBaseID)
+		if err := e.knowledgeBaseService.DeleteKnowledgeBase(ctx, knowledgeBaseID); err != nil {
+			logger.Errorf(ctx, "Failed to delete knowledge base: %v, knowledge base ID: %s", err, knowledgeBaseID)
+		}
+	}()
 
 	// 저장소에서 데이터셋 검색
-	dataset, err := e.dataset.GetDatasetByID(ctx, detail.Task.DatasetID)
+	dataset, err := e.dataset.GetDatasetByID(ctx, run.DatasetID)
 	if err != nil {
 		logger.Errorf(ctx, "Failed to get dataset: %v", err)
 		return err
 	}
 	logger.Infof(ctx, "Dataset retrieved successfully with %d QA pairs", len(dataset))
 
-	// 작업 세부 정보의 총 QA 쌍 수 업데이트
-	e.evaluationMemoryStorage.update(detail.Task.ID, func(params *types.EvaluationDetail) {
-		params.Task.Total = len(dataset)
-		logger.Infof(ctx, "Updated task total to %d QA pairs", params.Task.Total)
-	})
-
-	// 데이터셋에서 패시지 추출 및 정리
+	// 데이터셋에서 패시지 추출 후 지식 생성
 	passages := getPassageList(dataset)
-	logger.Infof(ctx, "Creating knowledge from %d passages", len(passages))
-
-	// 패시지에서 지식베이스 생성
 	knowledge, err := e.knowledgeService.CreateKnowledgeFromPassage(ctx, knowledgeBaseID, passages)
 	if err != nil {
 		logger.Errorf(ctx, "Failed to create knowledge from passages: %v", err)
 		return err
 	}
-	logger.Infof(ctx, "Knowledge created successfully, ID: %s", knowledge.ID)
-
-	// 임시 리소스 정리 설정
 	defer func() {
 		logger.Infof(ctx, "Cleaning up resources - deleting knowledge: %s", knowledge.ID)
 		if err := e.knowledgeService.DeleteKnowledge(ctx, knowledge.ID); err != nil {
 			logger.Errorf(ctx, "Failed to delete knowledge: %v, knowledge ID: %s", err, knowledge.ID)
 		}
+	}()
 
-		logger.Infof(ctx, "Cleaning up resources - deleting knowledge base: %s", knowledgeBaseID)
-		if err := e.knowledgeBaseService.DeleteKnowledgeBase(ctx, knowledgeBaseID); err != nil {
-			logger.Errorf(
-				ctx,
-				"Failed to delete knowledge base: %v, knowledge base ID: %s",
-				err, knowledgeBaseID,
-			)
+	questions := make([]*evalQuestion, 0, len(dataset))
+	for i, qaPair := range dataset {
+		gold := make([]string, 0, len(qaPair.PIDs))
+		for _, pid := range qaPair.PIDs {
+			gold = append(gold, strconv.Itoa(pid))
 		}
-	}()
+		questions = append(questions, &evalQuestion{
+			seq:      i,
+			question: qaPair.Question,
+			answer:   qaPair.Answer,
+			gold:     gold,
+		})
+	}
+	return e.evalQuestions(ctx, run, questions, func(r *types.SearchResult) string {
+		return strconv.Itoa(r.ChunkIndex)
+	})
+}
 
-	// 병렬 평가 메트릭 초기화
-	var finished int
-	var mu sync.Mutex
-	var g errgroup.Group
-	metricHook := NewHookMetric(len(dataset))
+// evalQuestions 질문을 병렬로 평가하고 질문마다 결과와 진행 상황을 저장
+// 질문 하나의 실패는 결과에 기록되며, 모든 질문이 실패한 경우에만 실행이 실패함
+func (e *EvaluationService) evalQuestions(ctx context.Context, run *types.EvaluationRun,
+	questions []*evalQuestion, chunkID func(*types.SearchResult) string,
+) error {
+	var (
+		mu       sync.Mutex
+		g        errgroup.Group
+		results  = make([]*types.EvaluationRunItem, 0, len(questions))
+		failures int
+		lastErr  error
+	)
+	run.Total = len(questions)
+	base := e.chatManage(run)
 
 	// 사용 가능한 CPU에 따라 워커 제한 설정
-	g.SetLimit(max(runtime.GOMAXPROCS(0)-1, 1))
-	logger.Infof(ctx, "Starting evaluation with %d parallel workers", max(runtime.GOMAXPROCS(0)-1, 1))
+	workers := max(runtime.GOMAXPROCS(0)-1, 1)
+	g.SetLimit(workers)
+	logger.Infof(ctx, "Evaluating %d questions with %d parallel workers", len(questions), workers)
 
-	// 각 QA 쌍을 병렬로 처리
-	for i, qaPair := range dataset {
-		qaPair := qaPair
-		i := i
+	for _, q := range questions {
 		g.Go(func() error {
-			logger.Infof(ctx, "Processing QA pair %d, question: %s", i, qaPair.Question)
-
-			// 이 QA 쌍에 대한 채팅 관리 매개변수 준비
-			chatManage := detail.Params.Clone()
-			chatManage.Query = qaPair.Question
-			chatManage.RewriteQuery = qaPair.Question
-			// 이 평가를 위한 지식베이스 ID 및 검색 대상 설정
-			chatManage.KnowledgeBaseIDs = []string{knowledgeBaseID}
-			chatManage.SearchTargets = types.SearchTargets{
-				&types.SearchTarget{
-					Type:            types.SearchTargetTypeKnowledgeBase,
-					KnowledgeBaseID: knowledgeBaseID,
-				},
+			item, err := e.evalQuestion(ctx, run, base, q, chunkID)
+			if err := e.repo.CreateRunItem(ctx, item); err != nil {
+				logger.Errorf(ctx, "Failed to save evaluation result of question %d: %v", q.seq, err)
 			}
 
-			// 지식 QA 파이프라인 실행
-			logger.Infof(ctx, "Running knowledge QA for question: %s", qaPair.Question)
-			err = e.sessionService.KnowledgeQAByEvent(ctx, chatManage, types.Pipline["rag"])
+			// 진행 상황과 평균 메트릭 갱신
+			mu.Lock()
+			defer mu.Unlock()
 			if err != nil {
-				logger.Errorf(ctx, "Failed to process question %d: %v", i, err)
-				return err
+				failures++
+				lastErr = err
+			}
+			results = append(results, item)
+			run.Finished = len(results)
+			run.Metric = evaluation.Average(results)
+			if err := e.repo.UpdateRun(ctx, run); err != nil {
+				logger.Errorf(ctx, "Failed to update evaluation run: %v, run ID: %s", err, run.ID)
 			}
-
-			// 평가 메트릭 기록
-			logger.Infof(ctx, "Recording metrics for QA pair %d", i)
-			metricHook.recordInit(i)
-			metricHook.recordQaPair(i, qaPair)
-			metricHook.recordSearchResult(i, chatManage.SearchResult)
-			metricHook.recordRerankResult(i, chatManage.RerankResult)
-			metricHook.recordChatResponse(i, chatManage.ChatResponse)
-			metricHook.recordFinish(i)
-
-			// 진행 상황 메트릭 업데이트
-			mu.Lock()
-			finished += 1
-			metricResult := metricHook.MetricResult()
-			mu.Unlock()
-			e.evaluationMemoryStorage.update(detail.Task.ID, func(params *types.EvaluationDetail) {
-				params.Metric = metricResult
-				params.Task.Finished = finished
-				logger.Infof(ctx, "Updated task progress: %d/%d completed", finished, params.Task.Total)
-			})
 			return nil
 		})
 	}
+	_ = g.Wait()
+
+	if len(questions) > 0 && failures == len(questions) {
+		return fmt.Errorf("all questions failed, last error: %w", lastErr)
+	}
+	return nil
+}
+
+// evalQuestion 질문 하나에 대해 지식 QA 파이프라인을 실행하고 메트릭 계산
+func (e *EvaluationService) evalQuestion(ctx context.Context, run *types.EvaluationRun,
+	base *types.ChatManage, q *evalQuestion, chunkID func(*types.SearchResult) string,
+) (*types.EvaluationRunItem, error) {
+	item := &types.EvaluationRunItem{
+		RunID:          run.ID,
+		TenantID:       run.TenantID,
+		Seq:            q.seq,
+		Question:       q.question,
+		ExpectedAnswer: q.answer,
+		GoldChunkIDs:   q.gold,
+	}
+
+	chatManage := base.Clone()
+	chatManage.Query = q.question
+	chatManage.RewriteQuery = q.question
+
+	start := time.Now()
+	err := e.sessionService.KnowledgeQAByEvent(ctx, chatManage, types.Pipline["rag"])
+	item.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		logger.Errorf(ctx, "Failed to process question %d: %v", q.seq, err)
+		item.Error = err.Error()
+		return item, err
+	}
+
+	// 재순위 결과를 우선 사용하고, 재순위 모델이 없으면 검색 결과 사용
+	retrieved := chatManage.RerankResult
+	if len(retrieved) == 0 && chatManage.RerankModelID == "" {
+		retrieved = chatManage.SearchResult
+	}
+	item.RetrievedChunkIDs = make(types.StringArray, 0, len(retrieved))
+	for _, r := range retrieved {
+		item.RetrievedChunkIDs = append(item.RetrievedChunkIDs, chunkID(r))
+	}
+	if chatManage.ChatResponse != nil {
+		item.Answer = chatManage.ChatResponse.Content
+	}
+	item.Metric = computeMetric(evaluation.MetricInput(item.GoldChunkIDs, item.RetrievedChunkIDs,
+		item.Answer, item.ExpectedAnswer))
+	return item, nil
+}
+
+// ListRuns 현재 테넌트의 평가 실행 목록을 최신순으로 조회
+func (e *EvaluationService) ListRuns(ctx context.Context,
+	filter *types.EvaluationRunFilter, page *types.Pagination,
+) (*types.PageResult, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	runs, total, err := e.repo.ListRuns(ctx, tenantID, filter, page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		return nil, err
+	}
+	for _, run := range runs {
+		e.markInterrupted(ctx, run)
+	}
+	return types.NewPageResult(total, page, runs), nil
+}
+
+// GetRun 현재 테넌트의 평가 실행 조회
+func (e *EvaluationService) GetRun(ctx context.Context, id string) (*types.EvaluationRun, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	run, err := e.repo.GetRun(ctx, tenantID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEvaluationRunNotFound) {
+			return nil, werrors.NewNotFoundError("평가 실행을 찾을 수 없습니다")
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"run_id": id})
+		return nil, err
+	}
+	e.markInterrupted(ctx, run)
+	return run, nil
+}
+
+// ListRunItems 평가 실행의 질문별 결과를 데이터셋 순서로 조회
+func (e *EvaluationService) ListRunItems(ctx context.Context,
+	id string, page *types.Pagination,
+) (*types.PageResult, error) {
+	if _, err := e.GetRun(ctx, id); err != nil {
+		return nil, err
+	}
+	items, total, err := e.repo.ListRunItems(ctx, id, page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"run_id": id})
+		return nil, err
+	}
+	return types.NewPageResult(total, page, items), nil
+}
 
-	// 모든 병렬 평가가 완료될 때까지 대기
-	logger.Info(ctx, "Waiting for all evaluation tasks to complete")
-	if err := g.Wait(); err != nil {
-		logger.Errorf(ctx, "Evaluation error: %v", err)
+// DeleteRun 완료된 평가 실행과 질문별 결과 삭제
+func (e *EvaluationService) DeleteRun(ctx context.Context, id string) error {
+	run, err := e.GetRun(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !run.Status.IsFinished() {
+		return werrors.NewBadRequestError("진행 중인 평가 실행은 삭제할 수 없습니다")
+	}
+	if err := e.repo.DeleteRun(ctx, run.TenantID, id); err != nil {
+		if errors.Is(err, repository.ErrEvaluationRunNotFound) {
+			return werrors.NewNotFoundError("평가 실행을 찾을 수 없습니다")
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"run_id": id})
 		return err
 	}
+	return nil
+}
+
+// CompareRuns 기준 실행과 대상 실행을 질문별로 비교
+// 검색 메트릭이 허용 오차보다 많이 떨어지면 비교는 실패하며, 구성 변경을 검증하는 데 사용할 수 있음
+func (e *EvaluationService) CompareRuns(ctx context.Context,
+	baseID, targetID string, tolerance float64,
+) (*types.EvaluationComparison, error) {
+	if tolerance < 0 {
+		return nil, werrors.NewBadRequestError("허용 오차는 0 이상이어야 합니다")
+	}
+	runs := make([]*types.EvaluationRun, 0, 2)
+	items := make([][]*types.EvaluationRunItem, 0, 2)
+	for _, id := range []string{baseID, targetID} {
+		run, err := e.GetRun(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if run.Status != types.EvaluationStatueSuccess {
+			return nil, werrors.NewBadRequestError("성공적으로 완료된 평가 실행만 비교할 수 있습니다: " + id)
+		}
+		runItems, err := e.repo.GetRunItems(ctx, id)
+		if err != nil {
+			logger.ErrorWithFields(ctx, err, map[string]interface{}{"run_id": id})
+			return nil, err
+		}
+		runs = append(runs, run)
+		items = append(items, runItems)
+	}
+	return evaluation.Compare(runs[0], runs[1], items[0], items[1], tolerance), nil
+}
+
+// markInterrupted 오랫동안 진행 상황이 갱신되지 않은 실행을 실패로 표시
+func (e *EvaluationService) markInterrupted(ctx context.Context, run *types.EvaluationRun) {
+	if run.Status.IsFinished() || time.Since(run.UpdatedAt) < evaluationStaleTimeout {
+		return
+	}
+	now := time.Now()
+	run.Status = types.EvaluationStatueFailed
+	run.ErrMsg = "evaluation interrupted"
+	run.FinishedAt = &now
+	if err := e.repo.UpdateRun(ctx, run); err != nil {
+		logger.Errorf(ctx, "Failed to mark evaluation run as interrupted: %v, run ID: %s", err, run.ID)
+	}
+}
 
-	// 평가 메트릭 최종 업데이트
-	e.evaluationMemoryStorage.update(detail.Task.ID, func(params *types.EvaluationDetail) {
-		params.Metric = metricHook.MetricResult()
-		params.Task.Finished = finished
+// runConfig 대화 기본값에 에이전트 구성과 요청의 모델 지정을 차례로 덮어써 실행 구성을 만듦
+func (e *EvaluationService) runConfig(agent *types.CustomAgent, req *types.EvaluationRequest) *types.EvaluationRunConfig {
+	conversation := e.config.Conversation
+	cfg := &types.EvaluationRunConfig{
+		VectorThreshold:  conversation.VectorThreshold,
+		KeywordThreshold: conversation.KeywordThreshold,
+		EmbeddingTopK:    conversation.EmbeddingTopK,
+		RerankTopK:       conversation.RerankTopK,
+		RerankThreshold:  conversation.RerankThreshold,
+		Temperature:      conversation.Summary.Temperature,
+	}
+	if agent != nil {
+		c := agent.Config
+		cfg.ChatModelID = c.ModelID
+		cfg.RerankModelID = c.RerankModelID
+		if c.VectorThreshold > 0 {
+			cfg.VectorThreshold = c.VectorThreshold
+		}
+		if c.KeywordThreshold > 0 {
+			cfg.KeywordThreshold = c.KeywordThreshold
+		}
+		if c.EmbeddingTopK > 0 {
+			cfg.EmbeddingTopK = c.EmbeddingTopK
+		}
+		if c.RerankTopK > 0 {
+			cfg.RerankTopK = c.RerankTopK
+		}
+		if c.RerankThreshold > 0 {
+			cfg.RerankThreshold = c.RerankThreshold
+		}
+		if c.Temperature > 0 {
+			cfg.Temperature = c.Temperature
+		}
+		cfg.FAQPriorityEnabled = c.FAQPriorityEnabled
+		cfg.FAQDirectAnswerThreshold = c.FAQDirectAnswerThreshold
+		cfg.FAQScoreBoost = c.FAQScoreBoost
+	}
+	if req.ChatModelID != "" {
+		cfg.ChatModelID = req.ChatModelID
+	}
+	if req.RerankModelID != "" {
+		cfg.RerankModelID = req.RerankModelID
+	}
+	return cfg
+}
+
+// chatManage 실행 구성으로 질문마다 복제할 채팅 관리 매개변수 생성
+func (e *EvaluationService) chatManage(run *types.EvaluationRun) *types.ChatManage {
+	cfg := run.Config
+	if cfg == nil {
+		cfg = &types.EvaluationRunConfig{}
+	}
+	summary := e.config.Conversation.Summary
+	searchTargets := make(types.SearchTargets, 0, len(run.KnowledgeBaseIDs))
+	for _, id := range run.KnowledgeBaseIDs {
+		searchTargets = append(searchTargets, &types.SearchTarget{
+			Type:            types.SearchTargetTypeKnowledgeBase,
+			KnowledgeBaseID: id,
+		})
+	}
+	return &types.ChatManage{
+		KnowledgeBaseIDs: run.KnowledgeBaseIDs,
+		SearchTargets:    searchTargets,
+		TenantID:         run.TenantID,
+		VectorThreshold:  cfg.VectorThreshold,
+		KeywordThreshold: cfg.KeywordThreshold,
+		EmbeddingTopK:    cfg.EmbeddingTopK,
+		MaxRounds:        e.config.Conversation.MaxRounds,
+		RerankModelID:    cfg.RerankModelID,
+		RerankTopK:       cfg.RerankTopK,
+		RerankThreshold:  cfg.RerankThreshold,
+		ChatModelID:      cfg.ChatModelID,
+		SummaryConfig: types.SummaryConfig{
+			MaxTokens:           summary.MaxTokens,
+			RepeatPenalty:       summary.RepeatPenalty,
+			TopK:                summary.TopK,
+			TopP:                summary.TopP,
+			Prompt:              summary.Prompt,
+			ContextTemplate:     summary.ContextTemplate,
+			FrequencyPenalty:    summary.FrequencyPenalty,
+			PresencePenalty:     summary.PresencePenalty,
+			NoMatchPrefix:       summary.NoMatchPrefix,
+			Temperature:         cfg.Temperature,
+			Seed:                summary.Seed,
+			MaxCompletionTokens: summary.MaxCompletionTokens,
+		},
+		FallbackResponse:         e.config.Conversation.FallbackResponse,
+		RewritePromptSystem:      e.config.Conversation.RewritePromptSystem,
+		RewritePromptUser:        e.config.Conversation.RewritePromptUser,
+		FAQPriorityEnabled:       cfg.FAQPriorityEnabled,
+		FAQDirectAnswerThreshold: cfg.FAQDirectAnswerThreshold,
+		FAQScoreBoost:            cfg.FAQScoreBoost,
+	}
+}
+
+// toDetail 평가 실행을 기존 평가 작업 응답 형식으로 변환
+func (e *EvaluationService) toDetail(run *types.EvaluationRun) *types.EvaluationDetail {
+	return &types.EvaluationDetail{
+		Task: &types.EvaluationTask{
+			ID:        run.ID,
+			TenantID:  run.TenantID,
+			DatasetID: run.DatasetID,
+			StartTime: run.StartedAt,
+			Status:    run.Status,
+			ErrMsg:    run.ErrMsg,
+			Total:     run.Total,
+			Finished:  run.Finished,
+		},
+		Params: e.chatManage(run),
+		Metric: run.Metric,
+	}
+}
+
+// agentKnowledgeBases 에이전트의 지식베이스 선택 방식에 따라 평가할 지식베이스 결정
+func (e *EvaluationService) agentKnowledgeBases(ctx context.Context, agent *types.CustomAgent) []string {
+	switch agent.Config.KBSelectionMode {
+	case "all":
+		kbs, err := e.knowledgeBaseService.ListKnowledgeBases(ctx)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to list all knowledge bases: %v", err)
+			return nil
+		}
+		ids := make([]string, 0, len(kbs))
+		for _, kb := range kbs {
+			ids = append(ids, kb.ID)
+		}
+		return ids
+	case "none":
+		return nil
+	default:
+		return agent.Config.KnowledgeBases
+	}
+}
+
+// createTemporaryKnowledgeBase 기본 데이터셋 평가용 임시 지식베이스 생성
+// 지식베이스가 지정되면 그 모델을, 아니면 기본 모델을 사용
+func (e *EvaluationService) createTemporaryKnowledgeBase(ctx context.Context,
+	knowledgeBaseID string,
+) (*types.KnowledgeBase, error) {
+	var embeddingModelID, llmModelID string
+	if knowledgeBaseID != "" {
+		kb, err := e.knowledgeBaseService.GetKnowledgeBaseByID(ctx, knowledgeBaseID)
+		if err != nil {
+			logger.Errorf(ctx, "Failed to get knowledge base: %v", err)
+			return nil, err
+		}
+		if kb.TenantID != ctx.Value(types.TenantIDContextKey).(uint64) {
+			return nil, werrors.NewNotFoundError("지식베이스를 찾을 수 없습니다: " + knowledgeBaseID)
+		}
+		if err := evaluation.CheckAccess(ctx, e.permissionService, "", knowledgeBaseID); err != nil {
+			return nil, err
+		}
+		embeddingModelID, llmModelID = kb.EmbeddingModelID, kb.SummaryModelID
+	} else {
+		embeddingModelID = e.defaultModelID(ctx, types.ModelTypeEmbedding)
+		llmModelID = e.defaultModelID(ctx, types.ModelTypeKnowledgeQA)
+		if embeddingModelID == "" || llmModelID == "" {
+			return nil, werrors.NewBadRequestError("평가에 사용할 기본 모델이 없습니다")
+		}
+	}
+
+	kb, err := e.knowledgeBaseService.CreateKnowledgeBase(ctx, &types.KnowledgeBase{
+		Name:             "evaluation",
+		Description:      "evaluation",
+		EmbeddingModelID: embeddingModelID,
+		SummaryModelID:   llmModelID,
 	})
+	if err != nil {
+		logger.Errorf(ctx, "Failed to create knowledge base: %v", err)
+		return nil, err
+	}
+	logger.Infof(ctx, "Created temporary knowledge base with ID: %s", kb.ID)
+	return kb, nil
+}
 
-	logger.Infof(ctx, "Dataset evaluation completed successfully, task ID: %s", detail.Task.ID)
-	return nil
+// defaultModelID 지정한 유형의 첫 번째 모델 ID 반환, 없으면 빈 문자열
+func (e *EvaluationService) defaultModelID(ctx context.Context, modelType types.ModelType) string {
+	models, err := e.modelService.ListModels(ctx)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to list models: %v", err)
+		return ""
+	}
+	for _, model := range models {
+		if model != nil && model.Type == modelType {
+			return model.ID
+		}
+	}
+	return ""
 }
 
 // getPassageList QA 쌍에서 패시지를 추출하고 정리
@@ -0,0 +1,26 @@
+package evaluation
+
+import (
+	"context"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// CheckAccess returns the error of the permission service unless the caller may read the agent
+// and every knowledge base of an evaluation, an empty agent ID is not checked
+func CheckAccess(ctx context.Context, permissions interfaces.PermissionService,
+	agentID string, knowledgeBaseIDs ...string,
+) error {
+	if agentID != "" {
+		if err := permissions.CheckPermission(ctx, types.ResourceTypeAgent, agentID, types.PermissionRead); err != nil {
+			return err
+		}
+	}
+	for _, id := range knowledgeBaseIDs {
+		if err := permissions.CheckPermission(ctx, types.ResourceTypeKnowledgeBase, id, types.PermissionRead); err != nil {
+			return err
+		}
+	}
+	return nil
+}
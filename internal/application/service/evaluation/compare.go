@@ -0,0 +1,169 @@
+package evaluation
+
+import (
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// DefaultTolerance is how much a retrieval metric may drop before a comparison fails
+const DefaultTolerance = 0.01
+
+// Field is a metric of a MetricResult
+type Field struct {
+	Name      string
+	Retrieval bool // Retrieval metrics gate comparisons, generation metrics are informational
+	Get       func(*types.MetricResult) *float64
+}
+
+// Fields lists every metric reported by an evaluation
+var Fields = []Field{
+	{"precision", true, func(r *types.MetricResult) *float64 { return &r.RetrievalMetrics.Precision }},
+	{"recall", true, func(r *types.MetricResult) *float64 { return &r.RetrievalMetrics.Recall }},
+	{"ndcg3", true, func(r *types.MetricResult) *float64 { return &r.RetrievalMetrics.NDCG3 }},
+	{"ndcg10", true, func(r *types.MetricResult) *float64 { return &r.RetrievalMetrics.NDCG10 }},
+	{"mrr", true, func(r *types.MetricResult) *float64 { return &r.RetrievalMetrics.MRR }},
+	{"map", true, func(r *types.MetricResult) *float64 { return &r.RetrievalMetrics.MAP }},
+	{"bleu1", false, func(r *types.MetricResult) *float64 { return &r.GenerationMetrics.BLEU1 }},
+	{"bleu2", false, func(r *types.MetricResult) *float64 { return &r.GenerationMetrics.BLEU2 }},
+	{"bleu4", false, func(r *types.MetricResult) *float64 { return &r.GenerationMetrics.BLEU4 }},
+	{"rouge1", false, func(r *types.MetricResult) *float64 { return &r.GenerationMetrics.ROUGE1 }},
+	{"rouge2", false, func(r *types.MetricResult) *float64 { return &r.GenerationMetrics.ROUGE2 }},
+	{"rougel", false, func(r *types.MetricResult) *float64 { return &r.GenerationMetrics.ROUGEL }},
+}
+
+// MetricInput builds the metric input of a question. The metric calculators work on integer IDs,
+// so chunk IDs are numbered in order of first appearance, gold chunks first.
+func MetricInput(gold, retrieved []string, answer, expected string) *types.MetricInput {
+	ids := make(map[string]int, len(gold)+len(retrieved))
+	number := func(id string) int {
+		n, ok := ids[id]
+		if !ok {
+			n = len(ids)
+			ids[id] = n
+		}
+		return n
+	}
+	goldIDs := make([]int, 0, len(gold))
+	for _, id := range gold {
+		goldIDs = append(goldIDs, number(id))
+	}
+	retrievedIDs := make([]int, 0, len(retrieved))
+	for _, id := range retrieved {
+		retrievedIDs = append(retrievedIDs, number(id))
+	}
+	return &types.MetricInput{
+		RetrievalGT:    [][]int{goldIDs},
+		RetrievalIDs:   retrievedIDs,
+		GeneratedTexts: answer,
+		GeneratedGT:    expected,
+	}
+}
+
+// Average averages the metrics of the items of a run. Retrieval metrics are averaged over the
+// questions with gold chunks and generation metrics over the questions with a gold answer;
+// failed questions count as zero.
+func Average(items []*types.EvaluationRunItem) *types.MetricResult {
+	result := &types.MetricResult{}
+	var retrievalCount, generationCount float64
+	for _, item := range items {
+		if len(item.GoldChunkIDs) > 0 {
+			retrievalCount++
+		}
+		if item.ExpectedAnswer != "" {
+			generationCount++
+		}
+		if item.Metric == nil {
+			continue
+		}
+		for _, f := range Fields {
+			if f.Retrieval && len(item.GoldChunkIDs) > 0 || !f.Retrieval && item.ExpectedAnswer != "" {
+				*f.Get(result) += *f.Get(item.Metric)
+			}
+		}
+	}
+	for _, f := range Fields {
+		count := generationCount
+		if f.Retrieval {
+			count = retrievalCount
+		}
+		if count > 0 {
+			*f.Get(result) /= count
+		}
+	}
+	return result
+}
+
+// Delta returns target minus base for every metric, nil when either side is missing
+func Delta(base, target *types.MetricResult) *types.MetricResult {
+	if base == nil || target == nil {
+		return nil
+	}
+	delta := &types.MetricResult{}
+	for _, f := range Fields {
+		*f.Get(delta) = *f.Get(target) - *f.Get(base)
+	}
+	return delta
+}
+
+// Regressions lists the retrieval metrics that dropped more than the tolerance
+func Regressions(base, target *types.MetricResult, tolerance float64) []types.MetricRegression {
+	regressions := []types.MetricRegression{}
+	if base == nil || target == nil {
+		return regressions
+	}
+	for _, f := range Fields {
+		if !f.Retrieval {
+			continue
+		}
+		b, t := *f.Get(base), *f.Get(target)
+		if t-b < -tolerance {
+			regressions = append(regressions, types.MetricRegression{Metric: f.Name, Base: b, Target: t, Delta: t - b})
+		}
+	}
+	return regressions
+}
+
+// Compare compares a target run with a base run. Questions are matched by their text;
+// a question regresses when one of its retrieval metrics dropped more than the tolerance.
+func Compare(base, target *types.EvaluationRun,
+	baseItems, targetItems []*types.EvaluationRunItem, tolerance float64,
+) *types.EvaluationComparison {
+	regressions := Regressions(base.Metric, target.Metric, tolerance)
+	comparison := &types.EvaluationComparison{
+		Base:        base,
+		Target:      target,
+		Tolerance:   tolerance,
+		Delta:       Delta(base.Metric, target.Metric),
+		Regressions: regressions,
+		Passed:      len(regressions) == 0,
+		Questions:   make([]types.QuestionComparison, 0, len(baseItems)),
+	}
+
+	targets := make(map[string]*types.EvaluationRunItem, len(targetItems))
+	for _, item := range targetItems {
+		key := strings.TrimSpace(item.Question)
+		if _, ok := targets[key]; !ok {
+			targets[key] = item
+		}
+	}
+	matched := 0
+	for _, b := range baseItems {
+		key := strings.TrimSpace(b.Question)
+		t, ok := targets[key]
+		if !ok {
+			continue
+		}
+		delete(targets, key)
+		matched++
+		comparison.Questions = append(comparison.Questions, types.QuestionComparison{
+			Question:  b.Question,
+			Base:      b,
+			Target:    t,
+			Delta:     Delta(b.Metric, t.Metric),
+			Regressed: len(b.GoldChunkIDs) > 0 && len(Regressions(b.Metric, t.Metric, tolerance)) > 0,
+		})
+	}
+	comparison.Unmatched = len(baseItems) + len(targetItems) - 2*matched
+	return comparison
+}
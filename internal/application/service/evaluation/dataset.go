@@ -0,0 +1,192 @@
+// Package evaluation parses uploaded evaluation datasets and compares evaluation runs.
+//
+// A dataset is a CSV file with a header row or a JSONL file with one object per line. Each question
+// needs gold chunk IDs, a gold answer or both: chunk IDs feed the retrieval metrics and answers the
+// generation metrics. The JSONL layout matches the feedback dataset export, so exported feedback can be
+// uploaded as is.
+package evaluation
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// Dataset formats
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// ErrUnsupportedFormat is returned for dataset files that are neither CSV nor JSONL
+var ErrUnsupportedFormat = errors.New("unsupported dataset format, expected csv or jsonl")
+
+// csvColumns maps the accepted CSV header names to dataset fields
+var csvColumns = map[string]string{
+	"question":       "question",
+	"query":          "question",
+	"answer":         "answer",
+	"gold_answer":    "answer",
+	"gold_chunk_ids": "gold_chunk_ids",
+	"chunk_ids":      "gold_chunk_ids",
+}
+
+// jsonlItem is a line of a JSONL dataset, other fields are ignored
+type jsonlItem struct {
+	Question     string   `json:"question"`
+	Answer       string   `json:"answer"`
+	GoldChunkIDs []string `json:"gold_chunk_ids"`
+}
+
+// DetectFormat returns the dataset format of a file name, or an empty string when it is not recognized
+func DetectFormat(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	}
+	return ""
+}
+
+// ParseDataset reads the questions of a dataset file, at most limit of them when limit is positive.
+// Errors name the offending line.
+func ParseDataset(r io.Reader, format string, limit int) ([]*types.EvaluationDatasetItem, error) {
+	var items []*types.EvaluationDatasetItem
+	add := func(line int, question, answer string, chunkIDs []string) error {
+		item, err := newItem(len(items), question, answer, chunkIDs)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if limit > 0 && len(items) >= limit {
+			return fmt.Errorf("dataset has more than %d questions", limit)
+		}
+		items = append(items, item)
+		return nil
+	}
+
+	switch format {
+	case FormatCSV:
+		if err := parseCSV(r, add); err != nil {
+			return nil, err
+		}
+	case FormatJSONL:
+		if err := parseJSONL(r, add); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if len(items) == 0 {
+		return nil, errors.New("dataset has no questions")
+	}
+	return items, nil
+}
+
+// newItem validates and normalizes a question of a dataset
+func newItem(seq int, question, answer string, chunkIDs []string) (*types.EvaluationDatasetItem, error) {
+	question = strings.TrimSpace(question)
+	answer = strings.TrimSpace(answer)
+	if question == "" {
+		return nil, errors.New("question is empty")
+	}
+	gold := make(types.StringArray, 0, len(chunkIDs))
+	seen := make(map[string]bool, len(chunkIDs))
+	for _, id := range chunkIDs {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			gold = append(gold, id)
+		}
+	}
+	if answer == "" && len(gold) == 0 {
+		return nil, errors.New("question needs gold chunk IDs or a gold answer")
+	}
+	return &types.EvaluationDatasetItem{Seq: seq, Question: question, Answer: answer, GoldChunkIDs: gold}, nil
+}
+
+// splitChunkIDs splits a CSV cell of chunk IDs separated by commas, semicolons, pipes or spaces
+func splitChunkIDs(cell string) []string {
+	return strings.FieldsFunc(cell, func(r rune) bool {
+		return r == ',' || r == ';' || r == '|' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// parseCSV reads a CSV dataset with a header row
+func parseCSV(r io.Reader, add func(line int, question, answer string, chunkIDs []string) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read csv header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		if field, ok := csvColumns[name]; ok {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["question"]; !ok {
+		return errors.New("csv header has no question column")
+	}
+	cell := func(record []string, field string) string {
+		if i, ok := columns[field]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return fmt.Errorf("failed to read csv: %w", err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if err := add(line, cell(record, "question"), cell(record, "answer"),
+			splitChunkIDs(cell(record, "gold_chunk_ids"))); err != nil {
+			return err
+		}
+	}
+}
+
+// parseJSONL reads a JSONL dataset, blank lines are skipped
+func parseJSONL(r io.Reader, add func(line int, question, answer string, chunkIDs []string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF")
+		}
+		if text == "" {
+			continue
+		}
+		var item jsonlItem
+		if err := json.Unmarshal([]byte(text), &item); err != nil {
+			return fmt.Errorf("line %d: invalid json: %w", line, err)
+		}
+		if err := add(line, item.Question, item.Answer, item.GoldChunkIDs); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read jsonl: %w", err)
+	}
+	return nil
+}
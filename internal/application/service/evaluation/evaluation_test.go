@@ -0,0 +1,166 @@
+package evaluation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+func TestParseDatasetCSV(t *testing.T) {
+	data := "\uFEFFQuestion,answer,gold_chunk_ids\n" +
+		"what is a?,a is a letter,\"c1, c2;c1\"\n" +
+		"\n" +
+		"what is b?,,c3|c4\n"
+	items, err := ParseDataset(strings.NewReader(data), FormatCSV, 0)
+	if err != nil {
+		t.Fatalf("ParseDataset() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("len(items) = %d, want 2", len(items))
+	}
+	if got := strings.Join(items[0].GoldChunkIDs, ","); got != "c1,c2" {
+		t.Errorf("items[0].GoldChunkIDs = %s, want c1,c2", got)
+	}
+	if items[1].Seq != 1 || items[1].Answer != "" || len(items[1].GoldChunkIDs) != 2 {
+		t.Errorf("items[1] = %+v", items[1])
+	}
+}
+
+func TestParseDatasetJSONL(t *testing.T) {
+	data := `{"question":"q1","answer":"a1","source_message_id":"m1"}` + "\n\n" +
+		`{"question":"q2","gold_chunk_ids":["c1"]}` + "\n"
+	items, err := ParseDataset(strings.NewReader(data), FormatJSONL, 0)
+	if err != nil {
+		t.Fatalf("ParseDataset() error = %v", err)
+	}
+	if len(items) != 2 || items[0].Answer != "a1" || items[1].GoldChunkIDs[0] != "c1" {
+		t.Errorf("items = %+v, %+v", items[0], items[1])
+	}
+
+	_, err = ParseDataset(strings.NewReader(data+`{"question":"q3"}`+"\n"), FormatJSONL, 0)
+	if err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("error = %v, want line 4", err)
+	}
+	if _, err := ParseDataset(strings.NewReader(data), FormatJSONL, 1); err == nil {
+		t.Error("expected limit error")
+	}
+}
+
+func TestMetricInput(t *testing.T) {
+	input := MetricInput([]string{"a", "b"}, []string{"c", "b", "a"}, "x", "y")
+	if got := input.RetrievalGT[0]; len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Errorf("RetrievalGT = %v", got)
+	}
+	if got := input.RetrievalIDs; len(got) != 3 || got[0] != 2 || got[1] != 1 || got[2] != 0 {
+		t.Errorf("RetrievalIDs = %v", got)
+	}
+}
+
+func TestAverage(t *testing.T) {
+	items := []*types.EvaluationRunItem{
+		{GoldChunkIDs: types.StringArray{"a"}, Metric: &types.MetricResult{
+			RetrievalMetrics: types.RetrievalMetrics{Recall: 1}}},
+		{GoldChunkIDs: types.StringArray{"b"}, Error: "failed"},
+		{ExpectedAnswer: "x", Metric: &types.MetricResult{
+			RetrievalMetrics: types.RetrievalMetrics{Recall: 1}, GenerationMetrics: types.GenerationMetrics{BLEU1: 0.8}}},
+	}
+	avg := Average(items)
+	if avg.RetrievalMetrics.Recall != 0.5 {
+		t.Errorf("Recall = %v, want 0.5", avg.RetrievalMetrics.Recall)
+	}
+	if avg.GenerationMetrics.BLEU1 != 0.8 {
+		t.Errorf("BLEU1 = %v, want 0.8", avg.GenerationMetrics.BLEU1)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	metric := func(recall, bleu float64) *types.MetricResult {
+		return &types.MetricResult{
+			RetrievalMetrics:  types.RetrievalMetrics{Recall: recall},
+			GenerationMetrics: types.GenerationMetrics{BLEU1: bleu},
+		}
+	}
+	gold := types.StringArray{"c1"}
+	base := &types.EvaluationRun{Metric: metric(0.8, 0.5)}
+	target := &types.EvaluationRun{Metric: metric(0.7, 0.1)}
+	baseItems := []*types.EvaluationRunItem{
+		{Question: "q1", GoldChunkIDs: gold, Metric: metric(1, 0)},
+		{Question: "q2", GoldChunkIDs: gold, Metric: metric(0.6, 0)},
+		{Question: "q3", GoldChunkIDs: gold, Metric: metric(1, 0)},
+	}
+	targetItems := []*types.EvaluationRunItem{
+		{Question: " q1 ", GoldChunkIDs: gold, Metric: metric(0.4, 0)},
+		{Question: "q2", GoldChunkIDs: gold, Metric: metric(0.6, 0)},
+	}
+
+	c := Compare(base, target, baseItems, targetItems, DefaultTolerance)
+	if c.Passed || len(c.Regressions) != 1 || c.Regressions[0].Metric != "recall" {
+		t.Errorf("Passed = %v, Regressions = %+v", c.Passed, c.Regressions)
+	}
+	if len(c.Questions) != 2 || !c.Questions[0].Regressed || c.Questions[1].Regressed {
+		t.Errorf("Questions = %+v", c.Questions)
+	}
+	if c.Unmatched != 1 {
+		t.Errorf("Unmatched = %d, want 1", c.Unmatched)
+	}
+
+	// Generation metrics alone never fail the comparison
+	target.Metric = metric(0.8, 0)
+	if c := Compare(base, target, nil, nil, DefaultTolerance); !c.Passed {
+		t.Errorf("Regressions = %+v, want none", c.Regressions)
+	}
+}
+
+// readablePermissionService grants read access to the listed resources only
+type readablePermissionService struct {
+	interfaces.PermissionService
+	readable map[string]bool
+}
+
+func (s *readablePermissionService) CheckPermission(ctx context.Context,
+	resourceType types.ResourceType, resourceID string, permission types.Permission,
+) error {
+	if permission != types.PermissionRead || !s.readable[string(resourceType)+":"+resourceID] {
+		return errors.NewForbiddenError("no permission")
+	}
+	return nil
+}
+
+func TestCheckAccess(t *testing.T) {
+	permissions := &readablePermissionService{readable: map[string]bool{
+		string(types.ResourceTypeAgent) + ":agent-a":             true,
+		string(types.ResourceTypeKnowledgeBase) + ":kb-a":        true,
+		string(types.ResourceTypeKnowledgeBase) + ":kb-a-shared": true,
+	}}
+
+	tests := []struct {
+		name    string
+		agentID string
+		kbIDs   []string
+		denied  bool
+	}{
+		{"readable agent and knowledge bases", "agent-a", []string{"kb-a", "kb-a-shared"}, false},
+		{"knowledge bases only", "", []string{"kb-a"}, false},
+		{"agent denied", "agent-b", []string{"kb-a"}, true},
+		{"one knowledge base denied", "agent-a", []string{"kb-a", "kb-b"}, true},
+		{"knowledge base denied without agent", "", []string{"kb-b"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAccess(context.Background(), permissions, tt.agentID, tt.kbIDs...)
+			if !tt.denied {
+				if err != nil {
+					t.Errorf("CheckAccess() error = %v, want nil", err)
+				}
+				return
+			}
+			if appErr, ok := errors.IsAppError(err); !ok || appErr.Code != errors.ErrForbidden {
+				t.Errorf("CheckAccess() error = %v, want forbidden", err)
+			}
+		})
+	}
+}
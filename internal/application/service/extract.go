@@ -299,7 +299,7 @@ func (s *DataTableSummaryService) Handle(ctx context.Context, t *asynq.Task) err
 	}
 
 	// 4. 벡터 데이터베이스에 인덱싱
-	if err := s.indexToVectorDB(ctx, chunks, resources.retrieveEngine, resources.embeddingModel); err != nil {
+	if err := s.indexToVectorDB(ctx, resources.knowledge, chunks, resources.retrieveEngine, resources.embeddingModel); err != nil {
 		s.cleanupOnFailure(ctx, resources, chunks, err)
 		return err
 	}
@@ -470,6 +470,7 @@ func (s *DataTableSummaryService) buildChunks(resources *extractionResources, ta
 // 아이디어: 인덱스 정보를 일괄 구성하고, 통합 인덱싱 후 상태 업데이트
 func (s *DataTableSummaryService) indexToVectorDB(
 	ctx context.Context,
+	knowledge *types.Knowledge,
 	chunks []*types.Chunk,
 	engine *retriever.CompositeRetrieveEngine,
 	embedder embedding.Embedder,
@@ -484,6 +485,7 @@ func (s *DataTableSummaryService) indexToVectorDB(
 			ChunkID:         chunk.ID,
 			KnowledgeID:     chunk.KnowledgeID,
 			KnowledgeBaseID: chunk.KnowledgeBaseID,
+			Attributes:      types.NewIndexAttributes(knowledge, chunk),
 		})
 	}
 
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+
+	"github.com/Tencent/WeKnora/internal/application/service/feedback"
+	"github.com/Tencent/WeKnora/internal/config"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+const (
+	// defaultFeedbackBoostWeight is the largest relative change of a retrieval score when none is configured
+	defaultFeedbackBoostWeight = 0.2
+	// defaultFeedbackAggregateDelay is the time feedback is batched before aggregation when none is configured
+	defaultFeedbackAggregateDelay = time.Minute
+	// feedbackCommentLimit is the maximum length of a feedback comment in characters
+	feedbackCommentLimit = 2000
+)
+
+// feedbackService implements the FeedbackService interface
+type feedbackService struct {
+	repo        interfaces.FeedbackRepository
+	messageRepo interfaces.MessageRepository
+	sessionRepo interfaces.SessionRepository
+	task        *asynq.Client
+	cfg         *config.Config
+}
+
+// NewFeedbackService creates a new feedback service
+func NewFeedbackService(
+	repo interfaces.FeedbackRepository,
+	messageRepo interfaces.MessageRepository,
+	sessionRepo interfaces.SessionRepository,
+	task *asynq.Client,
+	cfg *config.Config,
+) interfaces.FeedbackService {
+	return &feedbackService{
+		repo:        repo,
+		messageRepo: messageRepo,
+		sessionRepo: sessionRepo,
+		task:        task,
+		cfg:         cfg,
+	}
+}
+
+// boostWeight returns the configured largest relative change of a retrieval score
+func (s *feedbackService) boostWeight() float64 {
+	if s.cfg != nil && s.cfg.Feedback != nil && s.cfg.Feedback.BoostWeight != nil {
+		return *s.cfg.Feedback.BoostWeight
+	}
+	return defaultFeedbackBoostWeight
+}
+
+// prior returns the configured number of neutral votes scores are smoothed with
+func (s *feedbackService) prior() float64 {
+	if s.cfg != nil && s.cfg.Feedback != nil {
+		return s.cfg.Feedback.Prior
+	}
+	return 0
+}
+
+// aggregateDelay returns the configured time feedback is batched before aggregation
+func (s *feedbackService) aggregateDelay() time.Duration {
+	if s.cfg != nil && s.cfg.Feedback != nil && s.cfg.Feedback.AggregateDelay > 0 {
+		return time.Duration(s.cfg.Feedback.AggregateDelay) * time.Second
+	}
+	return defaultFeedbackAggregateDelay
+}
+
+// getAssistantMessage gets a completed assistant message of a session of the tenant in the context
+func (s *feedbackService) getAssistantMessage(ctx context.Context, sessionID, messageID string) (*types.Message, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	if _, err := s.sessionRepo.Get(ctx, tenantID, sessionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, werrors.NewNotFoundError("세션을 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	message, err := s.messageRepo.GetMessage(ctx, sessionID, messageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, werrors.NewNotFoundError("메시지를 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	if message.Role != "assistant" {
+		return nil, werrors.NewBadRequestError("어시스턴트 메시지에만 피드백을 남길 수 있습니다")
+	}
+	if !message.IsCompleted {
+		return nil, werrors.NewBadRequestError("생성이 끝나지 않은 메시지입니다")
+	}
+	return message, nil
+}
+
+// SubmitFeedback sets the feedback on an assistant message, replacing the previous one.
+// Rated references must be among the knowledge references of the message.
+func (s *feedbackService) SubmitFeedback(ctx context.Context,
+	sessionID, messageID string, req *types.SubmitFeedbackRequest,
+) (*types.MessageFeedback, error) {
+	if !req.Rating.IsValid() {
+		return nil, werrors.NewBadRequestError("올바르지 않은 평가입니다").WithDetails(string(req.Rating))
+	}
+	if req.Reason != "" && !req.Reason.IsValid() {
+		return nil, werrors.NewBadRequestError("올바르지 않은 피드백 이유입니다").WithDetails(string(req.Reason))
+	}
+	comment := strings.TrimSpace(req.Comment)
+	if len([]rune(comment)) > feedbackCommentLimit {
+		return nil, werrors.NewBadRequestError("피드백 의견이 너무 깁니다")
+	}
+	message, err := s.getAssistantMessage(ctx, sessionID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	cited := make(map[string]*types.SearchResult, len(message.KnowledgeReferences))
+	for _, ref := range message.KnowledgeReferences {
+		if ref != nil {
+			cited[ref.ID] = ref
+		}
+	}
+	references := make([]types.ReferenceFeedback, 0, len(req.References))
+	seen := make(map[string]bool, len(req.References))
+	for _, ref := range req.References {
+		source, ok := cited[ref.ChunkID]
+		if !ok {
+			return nil, werrors.NewBadRequestError("메시지가 인용하지 않은 청크입니다").WithDetails(ref.ChunkID)
+		}
+		if seen[ref.ChunkID] {
+			continue
+		}
+		seen[ref.ChunkID] = true
+		references = append(references, types.ReferenceFeedback{
+			ChunkID:     ref.ChunkID,
+			KnowledgeID: source.KnowledgeID,
+			Relevant:    ref.Relevant,
+		})
+	}
+
+	now := time.Now()
+	fb := &types.MessageFeedback{
+		Rating:     req.Rating,
+		Reason:     req.Reason,
+		Comment:    comment,
+		References: references,
+		UpdatedAt:  now,
+	}
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil {
+		fb.UserID = subject.UserID
+	}
+	if err := s.repo.SetMessageFeedback(ctx, sessionID, messageID, fb, now); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"message_id": messageID})
+		return nil, err
+	}
+
+	logger.Infof(ctx, "Feedback submitted, message: %s, rating: %s, reason: %s, references: %d",
+		messageID, fb.Rating, fb.Reason, len(references))
+	s.scheduleAggregate(ctx)
+	return fb, nil
+}
+
+// DeleteFeedback removes the feedback on an assistant message
+func (s *feedbackService) DeleteFeedback(ctx context.Context, sessionID, messageID string) error {
+	message, err := s.getAssistantMessage(ctx, sessionID, messageID)
+	if err != nil {
+		return err
+	}
+	if message.Feedback == nil {
+		return nil
+	}
+	if err := s.repo.SetMessageFeedback(ctx, sessionID, messageID, nil, time.Now()); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"message_id": messageID})
+		return err
+	}
+	logger.Infof(ctx, "Feedback deleted, message: %s", messageID)
+	s.scheduleAggregate(ctx)
+	return nil
+}
+
+// scheduleAggregate enqueues the aggregation of the feedback of the tenant in the context.
+// Feedback submitted within the delay is aggregated by the same task.
+func (s *feedbackService) scheduleAggregate(ctx context.Context) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	payload, err := json.Marshal(types.FeedbackAggregatePayload{TenantID: tenantID})
+	if err != nil {
+		return
+	}
+	delay := s.aggregateDelay()
+	task := asynq.NewTask(types.TypeFeedbackAggregate, payload,
+		asynq.Queue("low"), asynq.ProcessIn(delay), asynq.Unique(delay), asynq.MaxRetry(3))
+	if _, err := s.task.Enqueue(task); err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+		logger.Warnf(ctx, "Failed to enqueue feedback aggregation of tenant %d: %v", tenantID, err)
+	}
+}
+
+// ListFeedback lists the feedback of the tenant in the context, newest first
+func (s *feedbackService) ListFeedback(ctx context.Context,
+	filter *types.FeedbackFilter, page *types.Pagination,
+) (*types.PageResult, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	records, total, err := s.repo.ListFeedback(ctx, tenantID, filter, page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		return nil, err
+	}
+	return types.NewPageResult(total, page, records), nil
+}
+
+// ListChunkSignals lists the chunk relevance signals of the tenant in the context, strongest first
+func (s *feedbackService) ListChunkSignals(ctx context.Context, page *types.Pagination) (*types.PageResult, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	signals, total, err := s.repo.ListChunkSignals(ctx, tenantID, page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		return nil, err
+	}
+	return types.NewPageResult(total, page, signals), nil
+}
+
+// eachFeedback calls fn for every feedback of a tenant matching the filter, newest first
+func (s *feedbackService) eachFeedback(ctx context.Context, tenantID uint64,
+	filter *types.FeedbackFilter, fn func(record *types.FeedbackRecord) error,
+) error {
+	page := &types.Pagination{Page: 1, PageSize: 100}
+	for {
+		records, _, err := s.repo.ListFeedback(ctx, tenantID, filter, page)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if len(records) < page.GetPageSize() {
+			return nil
+		}
+		page.Page++
+	}
+}
+
+// ExportDataset writes the evaluation samples built from the feedback of the tenant in the context.
+// Answers without a question or a reference judged relevant are skipped.
+func (s *feedbackService) ExportDataset(ctx context.Context,
+	filter *types.FeedbackFilter, write func(item *types.FeedbackDatasetItem) error,
+) error {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	return s.eachFeedback(ctx, tenantID, filter, func(record *types.FeedbackRecord) error {
+		if item := feedback.DatasetItem(record); item != nil {
+			return write(item)
+		}
+		return nil
+	})
+}
+
+// ChunkMultipliers returns the retrieval score multipliers of the chunks with a relevance signal,
+// chunks without a signal are left out
+func (s *feedbackService) ChunkMultipliers(ctx context.Context,
+	tenantID uint64, chunkIDs []string,
+) (map[string]float64, error) {
+	weight := s.boostWeight()
+	if weight <= 0 || len(chunkIDs) == 0 {
+		return nil, nil
+	}
+	signals, err := s.repo.GetChunkSignals(ctx, tenantID, chunkIDs)
+	if err != nil {
+		return nil, err
+	}
+	multipliers := make(map[string]float64, len(signals))
+	for _, signal := range signals {
+		multipliers[signal.ChunkID] = feedback.Multiplier(signal.Score, weight)
+	}
+	return multipliers, nil
+}
+
+// ProcessFeedbackAggregate recomputes the chunk relevance signals of a tenant from all of its feedback
+func (s *feedbackService) ProcessFeedbackAggregate(ctx context.Context, t *asynq.Task) error {
+	var payload types.FeedbackAggregatePayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		logger.Errorf(ctx, "failed to unmarshal feedback aggregate task payload: %v", err)
+		return nil
+	}
+	ctx = context.WithValue(ctx, types.TenantIDContextKey, payload.TenantID)
+
+	tallies := make(map[string]*feedback.Tally)
+	count := 0
+	if err := s.eachFeedback(ctx, payload.TenantID, nil, func(record *types.FeedbackRecord) error {
+		feedback.Accumulate(tallies, record.KnowledgeReferences, record.Feedback)
+		count++
+		return nil
+	}); err != nil {
+		logger.Errorf(ctx, "Failed to list feedback of tenant %d: %v", payload.TenantID, err)
+		return err
+	}
+
+	now := time.Now()
+	prior := s.prior()
+	signals := make([]*types.ChunkFeedbackSignal, 0, len(tallies))
+	for chunkID, tally := range tallies {
+		signals = append(signals, &types.ChunkFeedbackSignal{
+			TenantID:    payload.TenantID,
+			ChunkID:     chunkID,
+			KnowledgeID: tally.KnowledgeID,
+			Positive:    tally.Positive,
+			Negative:    tally.Negative,
+			Score:       feedback.Score(tally.Positive, tally.Negative, prior),
+			UpdatedAt:   now,
+		})
+	}
+	if err := s.repo.ReplaceChunkSignals(ctx, payload.TenantID, signals); err != nil {
+		logger.Errorf(ctx, "Failed to save chunk feedback signals of tenant %d: %v", payload.TenantID, err)
+		return err
+	}
+	logger.Infof(ctx, "Aggregated %d feedback of tenant %d into %d chunk signals",
+		count, payload.TenantID, len(signals))
+	return nil
+}
@@ -0,0 +1,94 @@
+package feedback
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+func references(ids ...string) types.References {
+	refs := make(types.References, 0, len(ids))
+	for _, id := range ids {
+		refs = append(refs, &types.SearchResult{ID: id, KnowledgeID: "k-" + id})
+	}
+	return refs
+}
+
+func TestAccumulate(t *testing.T) {
+	tallies := map[string]*Tally{}
+	refs := references("a", "b", "c")
+
+	// Helpful answer with one citation flagged wrong
+	Accumulate(tallies, refs, &types.MessageFeedback{
+		Rating:     types.FeedbackRatingUp,
+		References: []types.ReferenceFeedback{{ChunkID: "c", Relevant: false}},
+	})
+	// Wrong answer that does not blame the citations
+	Accumulate(tallies, refs, &types.MessageFeedback{
+		Rating: types.FeedbackRatingDown,
+		Reason: types.FeedbackReasonIncomplete,
+	})
+	// Wrong citations
+	Accumulate(tallies, references("b"), &types.MessageFeedback{
+		Rating: types.FeedbackRatingDown,
+		Reason: types.FeedbackReasonWrongCitation,
+	})
+
+	if got := tallies["a"]; got.Positive != ImplicitWeight || got.Negative != 0 || got.KnowledgeID != "k-a" {
+		t.Errorf("a: unexpected tally %+v", got)
+	}
+	if got := tallies["b"]; got.Positive != ImplicitWeight || got.Negative != ImplicitWeight {
+		t.Errorf("b: unexpected tally %+v", got)
+	}
+	if got := tallies["c"]; got.Positive != 0 || got.Negative != ExplicitWeight {
+		t.Errorf("c: unexpected tally %+v", got)
+	}
+}
+
+func TestScoreAndMultiplier(t *testing.T) {
+	if got := Score(0, 0, 0); got != 0 {
+		t.Errorf("no votes should be neutral, got %v", got)
+	}
+	if got := Score(1, 0, 0); math.Abs(got-1.0/3) > 1e-9 {
+		t.Errorf("one vote should be smoothed by the prior, got %v", got)
+	}
+	if got := Score(0, 100, 0); got >= -0.9 || got <= -1 {
+		t.Errorf("many negative votes should approach -1, got %v", got)
+	}
+	if got := Multiplier(-1, 0.2); math.Abs(got-0.8) > 1e-9 {
+		t.Errorf("unexpected multiplier %v", got)
+	}
+	if got := Multiplier(1, 5); got != 2 {
+		t.Errorf("the weight should be clamped, got %v", got)
+	}
+}
+
+func TestDatasetItem(t *testing.T) {
+	record := &types.FeedbackRecord{
+		MessageID:           "m",
+		Question:            "q",
+		Answer:              "answer",
+		KnowledgeReferences: references("a", "b"),
+		Feedback: &types.MessageFeedback{
+			Rating:     types.FeedbackRatingUp,
+			References: []types.ReferenceFeedback{{ChunkID: "b", Relevant: false}},
+		},
+	}
+	item := DatasetItem(record)
+	if item == nil || item.Answer != "answer" || !slices.Equal(item.GoldChunkIDs, []string{"a"}) ||
+		!slices.Equal(item.NegativeChunkIDs, []string{"b"}) {
+		t.Fatalf("unexpected item %+v", item)
+	}
+
+	record.Feedback = &types.MessageFeedback{Rating: types.FeedbackRatingDown, Reason: types.FeedbackReasonIncorrect}
+	if item := DatasetItem(record); item != nil {
+		t.Errorf("an answer without relevant references should be skipped, got %+v", item)
+	}
+
+	record.Feedback.References = []types.ReferenceFeedback{{ChunkID: "a", Relevant: true}}
+	if item := DatasetItem(record); item == nil || item.Answer != "" {
+		t.Errorf("a wrong answer should keep its relevant references without the answer, got %+v", item)
+	}
+}
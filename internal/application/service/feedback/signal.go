@@ -0,0 +1,121 @@
+// Package feedback turns the feedback on answers into per-chunk relevance signals and evaluation samples.
+//
+// A reference explicitly flagged by the user counts with ExplicitWeight. The unflagged references of a
+// rated answer count with ImplicitWeight: positively when the answer was helpful, negatively only when
+// the reason blames the citations, since a wrong answer can still cite the right chunks.
+package feedback
+
+import (
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+const (
+	// ExplicitWeight is the weight of a reference flagged by the user
+	ExplicitWeight = 1.0
+	// ImplicitWeight is the weight of an unflagged reference of a rated answer
+	ImplicitWeight = 0.5
+	// DefaultPrior is the number of neutral votes a score is smoothed with, so that one vote cannot max it out
+	DefaultPrior = 2.0
+)
+
+// Tally is the weighted feedback collected for a chunk
+type Tally struct {
+	KnowledgeID string
+	Positive    float64
+	Negative    float64
+}
+
+// Accumulate adds the signals of the feedback on an answer to the tallies, keyed by chunk ID
+func Accumulate(tallies map[string]*Tally, refs types.References, fb *types.MessageFeedback) {
+	if fb == nil {
+		return
+	}
+	tally := func(chunkID, knowledgeID string) *Tally {
+		t, ok := tallies[chunkID]
+		if !ok {
+			t = &Tally{KnowledgeID: knowledgeID}
+			tallies[chunkID] = t
+		}
+		return t
+	}
+
+	flagged := make(map[string]bool, len(fb.References))
+	for _, ref := range fb.References {
+		flagged[ref.ChunkID] = true
+		if ref.Relevant {
+			tally(ref.ChunkID, ref.KnowledgeID).Positive += ExplicitWeight
+		} else {
+			tally(ref.ChunkID, ref.KnowledgeID).Negative += ExplicitWeight
+		}
+	}
+	for _, ref := range refs {
+		if ref == nil || flagged[ref.ID] {
+			continue
+		}
+		switch {
+		case fb.Rating == types.FeedbackRatingUp:
+			tally(ref.ID, ref.KnowledgeID).Positive += ImplicitWeight
+		case fb.Reason.BlamesReferences():
+			tally(ref.ID, ref.KnowledgeID).Negative += ImplicitWeight
+		}
+	}
+}
+
+// Score returns the relevance signal of a tally between -1 (demote) and 1 (boost),
+// smoothed with prior neutral votes. A non-positive prior uses DefaultPrior.
+func Score(positive, negative, prior float64) float64 {
+	if prior <= 0 {
+		prior = DefaultPrior
+	}
+	return (positive - negative) / (positive + negative + prior)
+}
+
+// Multiplier returns the factor applied to the retrieval score of a chunk with the given signal,
+// the weight is the largest relative change and is clamped to [0, 1]
+func Multiplier(score, weight float64) float64 {
+	weight = min(max(weight, 0), 1)
+	score = min(max(score, -1), 1)
+	return 1 + weight*score
+}
+
+// DatasetItem turns a rated answer into an evaluation sample whose gold chunks are the references judged
+// relevant. It returns nil when the question is unknown or no reference was judged relevant.
+func DatasetItem(record *types.FeedbackRecord) *types.FeedbackDatasetItem {
+	fb := record.Feedback
+	if fb == nil || record.Question == "" {
+		return nil
+	}
+	item := &types.FeedbackDatasetItem{
+		Question:        record.Question,
+		GoldChunkIDs:    []string{},
+		SourceMessageID: record.MessageID,
+	}
+	if fb.Rating == types.FeedbackRatingUp {
+		item.Answer = record.Answer
+	}
+
+	flagged := make(map[string]bool, len(fb.References))
+	for _, ref := range fb.References {
+		flagged[ref.ChunkID] = true
+		if ref.Relevant {
+			item.GoldChunkIDs = append(item.GoldChunkIDs, ref.ChunkID)
+		} else {
+			item.NegativeChunkIDs = append(item.NegativeChunkIDs, ref.ChunkID)
+		}
+	}
+	for _, ref := range record.KnowledgeReferences {
+		if ref == nil || flagged[ref.ID] {
+			continue
+		}
+		switch {
+		case fb.Rating == types.FeedbackRatingUp:
+			item.GoldChunkIDs = append(item.GoldChunkIDs, ref.ID)
+		case fb.Reason.BlamesReferences():
+			item.NegativeChunkIDs = append(item.NegativeChunkIDs, ref.ID)
+		}
+	}
+	if len(item.GoldChunkIDs) == 0 {
+		return nil
+	}
+	return item
+}
@@ -24,12 +24,9 @@ func NewMinioFileService(endpoint,
 	accessKeyID, secretAccessKey, bucketName string, useSSL bool,
 ) (interfaces.FileService, error) {
 	// Initialize MinIO client
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
-		Secure: useSSL,
-	})
+	client, err := NewMinioClient(endpoint, accessKeyID, secretAccessKey, useSSL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize MinIO client: %w", err)
+		return nil, err
 	}
 
 	// Check if bucket exists, create if not
@@ -51,6 +48,18 @@ func NewMinioFileService(endpoint,
 	}, nil
 }
 
+// NewMinioClient creates a MinIO client for an S3 compatible endpoint
+func NewMinioClient(endpoint, accessKeyID, secretAccessKey string, useSSL bool) (*minio.Client, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Secure: useSSL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize MinIO client: %w", err)
+	}
+	return client, nil
+}
+
 // SaveFile saves a file to MinIO
 func (s *minioFileService) SaveFile(ctx context.Context,
 	file *multipart.FileHeader, tenantID uint64, knowledgeID string,
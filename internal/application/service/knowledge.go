@@ -63,6 +63,7 @@ type knowledgeService struct {
 	docReaderClient *client.Client
 	chunkService    interfaces.ChunkService
 	chunkRepo       interfaces.ChunkRepository
+	versionRepo     interfaces.KnowledgeVersionRepository
 	tagRepo         interfaces.KnowledgeTagRepository
 	tagService      interfaces.KnowledgeTagService
 	fileSvc         interfaces.FileService
@@ -70,6 +71,7 @@ type knowledgeService struct {
 	task            *asynq.Client
 	graphEngine     interfaces.RetrieveGraphRepository
 	redisClient     *redis.Client
+	webhookService  interfaces.WebhookService
 }
 
 const (
@@ -87,6 +89,7 @@ func NewKnowledgeService(
 	tenantRepo interfaces.TenantRepository,
 	chunkService interfaces.ChunkService,
 	chunkRepo interfaces.ChunkRepository,
+	versionRepo interfaces.KnowledgeVersionRepository,
 	tagRepo interfaces.KnowledgeTagRepository,
 	tagService interfaces.KnowledgeTagService,
 	fileSvc interfaces.FileService,
@@ -95,6 +98,7 @@ func NewKnowledgeService(
 	graphEngine interfaces.RetrieveGraphRepository,
 	retrieveEngine interfaces.RetrieveEngineRegistry,
 	redisClient *redis.Client,
+	webhookService interfaces.WebhookService,
 ) (interfaces.KnowledgeService, error) {
 	return &knowledgeService{
 		config:          config,
@@ -104,6 +108,7 @@ func NewKnowledgeService(
 		docReaderClient: docReaderClient,
 		chunkService:    chunkService,
 		chunkRepo:       chunkRepo,
+		versionRepo:     versionRepo,
 		tagRepo:         tagRepo,
 		tagService:      tagService,
 		fileSvc:         fileSvc,
@@ -112,6 +117,7 @@ func NewKnowledgeService(
 		graphEngine:     graphEngine,
 		retrieveEngine:  retrieveEngine,
 		redisClient:     redisClient,
+		webhookService:  webhookService,
 	}, nil
 }
 
@@ -162,6 +168,9 @@ func (s *knowledgeService) CreateKnowledgeFromFile(ctx context.Context,
 		logger.Errorf(ctx, "Failed to get knowledge base: %v", err)
 		return nil, err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		return nil, err
+	}
 
 	// 检查多模态配置完整性 - 只在图片文件时校验
 	// 检查是否为图片文件
@@ -371,6 +380,9 @@ func (s *knowledgeService) CreateKnowledgeFromURL(ctx context.Context,
 		logger.Errorf(ctx, "Failed to get knowledge base: %v", err)
 		return nil, err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		return nil, err
+	}
 
 	// Validate URL format and security
 	logger.Info(ctx, "Validating URL")
@@ -532,6 +544,9 @@ func (s *knowledgeService) CreateKnowledgeFromManual(ctx context.Context,
 		logger.Errorf(ctx, "Failed to get knowledge base: %v", err)
 		return nil, err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		return nil, err
+	}
 
 	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
 	now := time.Now()
@@ -611,6 +626,9 @@ func (s *knowledgeService) createKnowledgeFromPassageInternal(ctx context.Contex
 		logger.Errorf(ctx, "Failed to get knowledge base: %v", err)
 		return nil, err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		return nil, err
+	}
 
 	// Create knowledge record
 	if syncMode {
@@ -730,6 +748,9 @@ func (s *knowledgeService) DeleteKnowledge(ctx context.Context, id string) error
 	if err != nil {
 		return err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, knowledge.KnowledgeBaseID); err != nil {
+		return err
+	}
 
 	// Mark as deleting first to prevent async task conflicts
 	// This ensures that any running async tasks will detect the deletion and abort
@@ -783,6 +804,7 @@ func (s *knowledgeService) DeleteKnowledge(ctx context.Context, id string) error
 				logger.GetLogger(ctx).WithField("error", err).Errorf("DeleteKnowledge delete file failed")
 			}
 		}
+		deleteKnowledgeVersions(ctx, s.versionRepo, s.fileSvc, knowledge.TenantID, []*types.Knowledge{knowledge})
 		tenantInfo := ctx.Value(types.TenantInfoContextKey).(*types.Tenant)
 		tenantInfo.StorageUsed -= knowledge.StorageSize
 		if err := s.tenantRepo.AdjustStorageUsed(ctx, tenantInfo.ID, -knowledge.StorageSize); err != nil {
@@ -819,6 +841,11 @@ func (s *knowledgeService) DeleteKnowledgeList(ctx context.Context, ids []string
 	if err != nil {
 		return err
 	}
+	for _, knowledge := range knowledgeList {
+		if err := s.EnsureKnowledgeBaseWritable(ctx, knowledge.KnowledgeBaseID); err != nil {
+			return err
+		}
+	}
 
 	// Mark all as deleting first to prevent async task conflicts
 	for _, knowledge := range knowledgeList {
@@ -890,6 +917,7 @@ func (s *knowledgeService) DeleteKnowledgeList(ctx context.Context, ids []string
 			}
 			storageAdjust -= knowledge.StorageSize
 		}
+		deleteKnowledgeVersions(ctx, s.versionRepo, s.fileSvc, tenantInfo.ID, knowledgeList)
 		tenantInfo.StorageUsed += storageAdjust
 		if err := s.tenantRepo.AdjustStorageUsed(ctx, tenantInfo.ID, storageAdjust); err != nil {
 			logger.GetLogger(ctx).WithField("error", err).Errorf("DeleteKnowledge update tenant storage used failed")
@@ -1016,6 +1044,8 @@ func (s *knowledgeService) processDocumentFromPassage(ctx context.Context,
 type ProcessChunksOptions struct {
 	EnableQuestionGeneration bool
 	QuestionCount            int
+	// Incremental matches the chunks with the existing chunks of the knowledge and only processes the changes
+	Incremental bool
 }
 
 // processChunks processes chunks and creates embeddings for knowledge content
@@ -1028,6 +1058,10 @@ func (s *knowledgeService) processChunks(ctx context.Context,
 	if len(opts) > 0 {
 		options = opts[0]
 	}
+	if options.Incremental {
+		s.processChunksIncremental(ctx, kb, knowledge, chunks, options)
+		return
+	}
 
 	ctx, span := tracing.ContextWithSpan(ctx, "knowledgeService.processChunks")
 	defer span.End()
@@ -1057,24 +1091,30 @@ func (s *knowledgeService) processChunks(ctx context.Context,
 	// 幂等性处理：清理旧的chunks和索引数据，避免重复数据
 	logger.Infof(ctx, "Cleaning up existing chunks and index data for knowledge: %s", knowledge.ID)
 
-	// 删除旧的chunks
-	if err := s.chunkService.DeleteChunksByKnowledgeID(ctx, knowledge.ID); err != nil {
-		logger.Warnf(ctx, "Failed to delete existing chunks (may not exist): %v", err)
-		// 不返回错误，继续处理（可能没有旧数据）
-	}
-
-	// 删除旧的索引数据
+	// Index entries of the previous chunks are archived for point-in-time retrieval
 	tenantInfo := ctx.Value(types.TenantInfoContextKey).(*types.Tenant)
 	retrieveEngine, err := retriever.NewCompositeRetrieveEngine(s.retrieveEngine, tenantInfo.GetEffectiveEngines())
 	if err == nil {
-		if err := retrieveEngine.DeleteByKnowledgeIDList(ctx, []string{knowledge.ID}, embeddingModel.GetDimensions(), knowledge.Type); err != nil {
-			logger.Warnf(ctx, "Failed to delete existing index data (may not exist): %v", err)
+		previousChunks, err := s.chunkRepo.ListChunksByKnowledgeIDAsOf(ctx, knowledge.TenantID, knowledge.ID, nil)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to list existing chunks (may not exist): %v", err)
+		}
+		previousIDs := make([]string, 0, len(previousChunks))
+		for _, chunk := range previousChunks {
+			previousIDs = append(previousIDs, chunk.ID)
+		}
+		if err := s.archiveChunkIndices(ctx, retrieveEngine, knowledge, previousIDs, embeddingModel.GetDimensions()); err != nil {
+			logger.Warnf(ctx, "Failed to archive existing index data (may not exist): %v", err)
 			// 不返回错误，继续处理（可能没有旧数据）
-		} else {
-			logger.Infof(ctx, "Successfully deleted existing index data for knowledge: %s", knowledge.ID)
 		}
 	}
 
+	// 删除旧的chunks
+	if err := s.chunkService.DeleteChunksByKnowledgeID(ctx, knowledge.ID); err != nil {
+		logger.Warnf(ctx, "Failed to delete existing chunks (may not exist): %v", err)
+		// 不返回错误，继续处理（可能没有旧数据）
+	}
+
 	// 删除知识图谱数据（如果存在）
 	namespace := types.NameSpace{KnowledgeBase: knowledge.KnowledgeBaseID, Knowledge: knowledge.ID}
 	if err := s.graphEngine.DelGraph(ctx, []types.NameSpace{namespace}); err != nil {
@@ -1134,145 +1174,8 @@ func (s *knowledgeService) processChunks(ctx context.Context,
 	logger.Infof(ctx, "[DocReader] ========== 解析结果概览结束 ==========")
 
 	// Create chunk objects from proto chunks
-	maxSeq := 0
-
-	// 统计图片相关的子Chunk数量，用于扩展insertChunks的容量
-	imageChunkCount := 0
-	for _, chunkData := range chunks {
-		if len(chunkData.Images) > 0 {
-			// 为每个图片的OCR和Caption分别创建一个Chunk
-			imageChunkCount += len(chunkData.Images) * 2
-		}
-		if int(chunkData.Seq) > maxSeq {
-			maxSeq = int(chunkData.Seq)
-		}
-	}
-
-	// 重新分配容量，考虑图片相关的Chunk
-	insertChunks := make([]*types.Chunk, 0, len(chunks)+imageChunkCount)
-
-	for _, chunkData := range chunks {
-		if strings.TrimSpace(chunkData.Content) == "" {
-			continue
-		}
-
-		// 创建主文本Chunk
-		textChunk := &types.Chunk{
-			ID:              uuid.New().String(),
-			TenantID:        knowledge.TenantID,
-			KnowledgeID:     knowledge.ID,
-			KnowledgeBaseID: knowledge.KnowledgeBaseID,
-			Content:         chunkData.Content,
-			ChunkIndex:      int(chunkData.Seq),
-			IsEnabled:       true,
-			CreatedAt:       time.Now(),
-			UpdatedAt:       time.Now(),
-			StartAt:         int(chunkData.Start),
-			EndAt:           int(chunkData.End),
-			ChunkType:       types.ChunkTypeText,
-		}
-		var chunkImages []types.ImageInfo
-		insertChunks = append(insertChunks, textChunk)
-
-		// 处理图片信息
-		if len(chunkData.Images) > 0 {
-			logger.GetLogger(ctx).Infof("Processing %d images in chunk #%d", len(chunkData.Images), chunkData.Seq)
-
-			for i, img := range chunkData.Images {
-				// 保存图片信息到文本Chunk
-				imageInfo := types.ImageInfo{
-					URL:         img.Url,
-					OriginalURL: img.OriginalUrl,
-					StartPos:    int(img.Start),
-					EndPos:      int(img.End),
-					OCRText:     img.OcrText,
-					Caption:     img.Caption,
-				}
-				chunkImages = append(chunkImages, imageInfo)
-
-				// 将ImageInfo序列化为JSON
-				imageInfoJSON, err := json.Marshal([]types.ImageInfo{imageInfo})
-				if err != nil {
-					logger.GetLogger(ctx).WithField("error", err).Errorf("Failed to marshal image info to JSON")
-					continue
-				}
-
-				// 如果有OCR文本，创建OCR Chunk
-				if img.OcrText != "" {
-					ocrChunk := &types.Chunk{
-						ID:              uuid.New().String(),
-						TenantID:        knowledge.TenantID,
-						KnowledgeID:     knowledge.ID,
-						KnowledgeBaseID: knowledge.KnowledgeBaseID,
-						Content:         img.OcrText,
-						ChunkIndex:      maxSeq + i*100 + 1, // 使用不冲突的索引方式
-						IsEnabled:       true,
-						CreatedAt:       time.Now(),
-						UpdatedAt:       time.Now(),
-						StartAt:         int(img.Start),
-						EndAt:           int(img.End),
-						ChunkType:       types.ChunkTypeImageOCR,
-						ParentChunkID:   textChunk.ID,
-						ImageInfo:       string(imageInfoJSON),
-					}
-					insertChunks = append(insertChunks, ocrChunk)
-					logger.GetLogger(ctx).Infof("Created OCR chunk for image %d in chunk #%d", i, chunkData.Seq)
-				}
-
-				// 如果有图片描述，创建Caption Chunk
-				if img.Caption != "" {
-					captionChunk := &types.Chunk{
-						ID:              uuid.New().String(),
-						TenantID:        knowledge.TenantID,
-						KnowledgeID:     knowledge.ID,
-						KnowledgeBaseID: knowledge.KnowledgeBaseID,
-						Content:         img.Caption,
-						ChunkIndex:      maxSeq + i*100 + 2, // 使用不冲突的索引方式
-						IsEnabled:       true,
-						CreatedAt:       time.Now(),
-						UpdatedAt:       time.Now(),
-						StartAt:         int(img.Start),
-						EndAt:           int(img.End),
-						ChunkType:       types.ChunkTypeImageCaption,
-						ParentChunkID:   textChunk.ID,
-						ImageInfo:       string(imageInfoJSON),
-					}
-					insertChunks = append(insertChunks, captionChunk)
-					logger.GetLogger(ctx).Infof("Created caption chunk for image %d in chunk #%d", i, chunkData.Seq)
-				}
-			}
-
-			imageInfoJSON, err := json.Marshal(chunkImages)
-			if err != nil {
-				logger.GetLogger(ctx).WithField("error", err).Errorf("Failed to marshal image info to JSON")
-				continue
-			}
-			textChunk.ImageInfo = string(imageInfoJSON)
-		}
-	}
-
-	// Sort chunks by index for proper ordering
-	sort.Slice(insertChunks, func(i, j int) bool {
-		return insertChunks[i].ChunkIndex < insertChunks[j].ChunkIndex
-	})
-
-	// 仅为文本类型的Chunk设置前后关系
-	textChunks := make([]*types.Chunk, 0, len(chunks))
-	for _, chunk := range insertChunks {
-		if chunk.ChunkType == types.ChunkTypeText {
-			textChunks = append(textChunks, chunk)
-		}
-	}
-
-	// 设置文本Chunk之间的前后关系
-	for i, chunk := range textChunks {
-		if i > 0 {
-			textChunks[i-1].NextChunkID = chunk.ID
-		}
-		if i < len(textChunks)-1 {
-			textChunks[i+1].PreChunkID = chunk.ID
-		}
-	}
+	insertChunks := s.buildDocumentChunks(ctx, knowledge, chunks)
+	textChunks := linkTextChunks(insertChunks)
 
 	// Create index information for each chunk (without generated questions for now)
 	indexInfoList := make([]*types.IndexInfo, 0, len(insertChunks))
@@ -1285,6 +1188,7 @@ func (s *knowledgeService) processChunks(ctx context.Context,
 			ChunkID:         chunk.ID,
 			KnowledgeID:     knowledge.ID,
 			KnowledgeBaseID: knowledge.KnowledgeBaseID,
+			Attributes:      types.NewIndexAttributes(knowledge, chunk),
 		})
 	}
 
@@ -1357,9 +1261,13 @@ func (s *knowledgeService) processChunks(ctx context.Context,
 			logger.Errorf(ctx, "Delete chunks failed: %v", err)
 		}
 
-		// delete index
-		if err := retrieveEngine.DeleteByKnowledgeIDList(
-			ctx, []string{knowledge.ID}, embeddingModel.GetDimensions(), kb.Type,
+		// delete index of the failed chunks, archived index entries of previous versions are kept
+		insertIDs := make([]string, 0, len(insertChunks))
+		for _, chunk := range insertChunks {
+			insertIDs = append(insertIDs, chunk.ID)
+		}
+		if err := retrieveEngine.DeleteByChunkIDList(
+			ctx, insertIDs, embeddingModel.GetDimensions(), kb.Type,
 		); err != nil {
 			logger.Errorf(ctx, "Delete index failed: %v", err)
 		}
@@ -1411,6 +1319,7 @@ func (s *knowledgeService) processChunks(ctx context.Context,
 	if err := s.repo.UpdateKnowledge(ctx, knowledge); err != nil {
 		logger.GetLogger(ctx).WithField("error", err).Errorf("processChunks update knowledge failed")
 	}
+	s.recordKnowledgeVersion(ctx, knowledge, textChunks)
 
 	// Enqueue question generation task if enabled (async, non-blocking)
 	if options.EnableQuestionGeneration && len(textChunks) > 0 {
@@ -1437,6 +1346,160 @@ func (s *knowledgeService) processChunks(ctx context.Context,
 	logger.GetLogger(ctx).Infof("processChunks successfully")
 }
 
+// buildDocumentChunks creates chunk objects from the chunks parsed by docreader,
+// including the OCR and caption chunks of their images, sorted by chunk index
+func (s *knowledgeService) buildDocumentChunks(ctx context.Context,
+	knowledge *types.Knowledge, chunks []*proto.Chunk,
+) []*types.Chunk {
+	maxSeq := 0
+
+	// 统计图片相关的子Chunk数量，用于扩展insertChunks的容量
+	imageChunkCount := 0
+	for _, chunkData := range chunks {
+		if len(chunkData.Images) > 0 {
+			// 为每个图片的OCR和Caption分别创建一个Chunk
+			imageChunkCount += len(chunkData.Images) * 2
+		}
+		if int(chunkData.Seq) > maxSeq {
+			maxSeq = int(chunkData.Seq)
+		}
+	}
+
+	// 重新分配容量，考虑图片相关的Chunk
+	insertChunks := make([]*types.Chunk, 0, len(chunks)+imageChunkCount)
+
+	for _, chunkData := range chunks {
+		if strings.TrimSpace(chunkData.Content) == "" {
+			continue
+		}
+
+		// 创建主文本Chunk
+		textChunk := &types.Chunk{
+			ID:              uuid.New().String(),
+			TenantID:        knowledge.TenantID,
+			KnowledgeID:     knowledge.ID,
+			KnowledgeBaseID: knowledge.KnowledgeBaseID,
+			Content:         chunkData.Content,
+			ContentHash:     types.CalculateChunkContentHash(chunkData.Content),
+			ChunkIndex:      int(chunkData.Seq),
+			IsEnabled:       true,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+			StartAt:         int(chunkData.Start),
+			EndAt:           int(chunkData.End),
+			ChunkType:       types.ChunkTypeText,
+		}
+		var chunkImages []types.ImageInfo
+		insertChunks = append(insertChunks, textChunk)
+
+		// 处理图片信息
+		if len(chunkData.Images) > 0 {
+			logger.GetLogger(ctx).Infof("Processing %d images in chunk #%d", len(chunkData.Images), chunkData.Seq)
+
+			for i, img := range chunkData.Images {
+				// 保存图片信息到文本Chunk
+				imageInfo := types.ImageInfo{
+					URL:         img.Url,
+					OriginalURL: img.OriginalUrl,
+					StartPos:    int(img.Start),
+					EndPos:      int(img.End),
+					OCRText:     img.OcrText,
+					Caption:     img.Caption,
+				}
+				chunkImages = append(chunkImages, imageInfo)
+
+				// 将ImageInfo序列化为JSON
+				imageInfoJSON, err := json.Marshal([]types.ImageInfo{imageInfo})
+				if err != nil {
+					logger.GetLogger(ctx).WithField("error", err).Errorf("Failed to marshal image info to JSON")
+					continue
+				}
+
+				// 如果有OCR文本，创建OCR Chunk
+				if img.OcrText != "" {
+					ocrChunk := &types.Chunk{
+						ID:              uuid.New().String(),
+						TenantID:        knowledge.TenantID,
+						KnowledgeID:     knowledge.ID,
+						KnowledgeBaseID: knowledge.KnowledgeBaseID,
+						Content:         img.OcrText,
+						ContentHash:     types.CalculateChunkContentHash(img.OcrText),
+						ChunkIndex:      maxSeq + i*100 + 1, // 使用不冲突的索引方式
+						IsEnabled:       true,
+						CreatedAt:       time.Now(),
+						UpdatedAt:       time.Now(),
+						StartAt:         int(img.Start),
+						EndAt:           int(img.End),
+						ChunkType:       types.ChunkTypeImageOCR,
+						ParentChunkID:   textChunk.ID,
+						ImageInfo:       string(imageInfoJSON),
+					}
+					insertChunks = append(insertChunks, ocrChunk)
+					logger.GetLogger(ctx).Infof("Created OCR chunk for image %d in chunk #%d", i, chunkData.Seq)
+				}
+
+				// 如果有图片描述，创建Caption Chunk
+				if img.Caption != "" {
+					captionChunk := &types.Chunk{
+						ID:              uuid.New().String(),
+						TenantID:        knowledge.TenantID,
+						KnowledgeID:     knowledge.ID,
+						KnowledgeBaseID: knowledge.KnowledgeBaseID,
+						Content:         img.Caption,
+						ContentHash:     types.CalculateChunkContentHash(img.Caption),
+						ChunkIndex:      maxSeq + i*100 + 2, // 使用不冲突的索引方式
+						IsEnabled:       true,
+						CreatedAt:       time.Now(),
+						UpdatedAt:       time.Now(),
+						StartAt:         int(img.Start),
+						EndAt:           int(img.End),
+						ChunkType:       types.ChunkTypeImageCaption,
+						ParentChunkID:   textChunk.ID,
+						ImageInfo:       string(imageInfoJSON),
+					}
+					insertChunks = append(insertChunks, captionChunk)
+					logger.GetLogger(ctx).Infof("Created caption chunk for image %d in chunk #%d", i, chunkData.Seq)
+				}
+			}
+
+			imageInfoJSON, err := json.Marshal(chunkImages)
+			if err != nil {
+				logger.GetLogger(ctx).WithField("error", err).Errorf("Failed to marshal image info to JSON")
+				continue
+			}
+			textChunk.ImageInfo = string(imageInfoJSON)
+		}
+	}
+
+	// Sort chunks by index for proper ordering
+	sort.Slice(insertChunks, func(i, j int) bool {
+		return insertChunks[i].ChunkIndex < insertChunks[j].ChunkIndex
+	})
+	return insertChunks
+}
+
+// linkTextChunks sets the previous and next chunk IDs of the text chunks and returns them in order
+func linkTextChunks(insertChunks []*types.Chunk) []*types.Chunk {
+	// 仅为文本类型的Chunk设置前后关系
+	textChunks := make([]*types.Chunk, 0, len(insertChunks))
+	for _, chunk := range insertChunks {
+		if chunk.ChunkType == types.ChunkTypeText {
+			textChunks = append(textChunks, chunk)
+		}
+	}
+
+	// 设置文本Chunk之间的前后关系
+	for i, chunk := range textChunks {
+		if i > 0 {
+			textChunks[i-1].NextChunkID = chunk.ID
+		}
+		if i < len(textChunks)-1 {
+			textChunks[i+1].PreChunkID = chunk.ID
+		}
+	}
+	return textChunks
+}
+
 // GetSummary generates a summary for knowledge content using an AI model
 func (s *knowledgeService) getSummary(ctx context.Context,
 	summaryModel chat.Chat, knowledge *types.Knowledge, chunks []*types.Chunk,
@@ -1535,8 +1598,9 @@ func (s *knowledgeService) getSummary(ctx context.Context,
 }
 
 // enqueueQuestionGenerationTask enqueues an async task for question generation
+// chunkIDs restricts the generation to the given text chunks, all text chunks are used when empty
 func (s *knowledgeService) enqueueQuestionGenerationTask(ctx context.Context,
-	kbID, knowledgeID string, questionCount int,
+	kbID, knowledgeID string, questionCount int, chunkIDs ...string,
 ) {
 	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
 	payload := types.QuestionGenerationPayload{
@@ -1544,6 +1608,7 @@ func (s *knowledgeService) enqueueQuestionGenerationTask(ctx context.Context,
 		KnowledgeBaseID: kbID,
 		KnowledgeID:     knowledgeID,
 		QuestionCount:   questionCount,
+		ChunkIDs:        chunkIDs,
 	}
 
 	payloadBytes, err := json.Marshal(payload)
@@ -1749,6 +1814,7 @@ func (s *knowledgeService) ProcessSummaryGeneration(ctx context.Context, t *asyn
 			ChunkID:         summaryChunk.ID,
 			KnowledgeID:     knowledge.ID,
 			KnowledgeBaseID: knowledge.KnowledgeBaseID,
+			Attributes:      types.NewIndexAttributes(knowledge, summaryChunk),
 		}}
 
 		if err := retrieveEngine.BatchIndex(ctx, embeddingModel, indexInfo); err != nil {
@@ -1856,6 +1922,10 @@ func (s *knowledgeService) ProcessQuestionGeneration(ctx context.Context, t *asy
 	// Generate questions for each chunk with context
 	var indexInfoList []*types.IndexInfo
 	for i, chunk := range textChunks {
+		// Unchanged chunks of an updated document keep their questions
+		if len(payload.ChunkIDs) > 0 && !slices.Contains(payload.ChunkIDs, chunk.ID) {
+			continue
+		}
 		// Build context from adjacent chunks
 		var prevContent, nextContent string
 		if i > 0 {
@@ -1916,6 +1986,7 @@ func (s *knowledgeService) ProcessQuestionGeneration(ctx context.Context, t *asy
 				ChunkID:         chunk.ID,
 				KnowledgeID:     knowledge.ID,
 				KnowledgeBaseID: knowledge.KnowledgeBaseID,
+				Attributes:      types.NewIndexAttributes(knowledge, chunk),
 			})
 		}
 		logger.Debugf(ctx, "Generated %d questions for chunk %s", len(questions), chunk.ID)
@@ -2113,6 +2184,9 @@ func (s *knowledgeService) UpdateManualKnowledge(ctx context.Context,
 		logger.Errorf(ctx, "Failed to get knowledge base for manual update: %v", err)
 		return nil, err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		return nil, err
+	}
 
 	var version int
 	if meta, err := existing.ManualMetadata(); err == nil && meta != nil {
@@ -2139,6 +2213,8 @@ func (s *knowledgeService) UpdateManualKnowledge(ctx context.Context,
 	existing.EnableStatus = "disabled"
 	existing.UpdatedAt = time.Now()
 
+	// Knowledge processed before versioning keeps its previous content as the first version
+	s.ensureKnowledgeVersion(ctx, existing)
 	if err := s.cleanupKnowledgeResources(ctx, existing); err != nil {
 		logger.ErrorWithFields(ctx, err, map[string]interface{}{
 			"knowledge_id": knowledgeID,
@@ -2315,6 +2391,24 @@ func (s *knowledgeService) updateChunkVector(ctx context.Context, kbID string, c
 		return err
 	}
 
+	tenantInfo := ctx.Value(types.TenantInfoContextKey).(*types.Tenant)
+
+	// Load the knowledge of the chunks for the attributes used by metadata filters
+	knowledgeIDs := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		if !slices.Contains(knowledgeIDs, chunk.KnowledgeID) {
+			knowledgeIDs = append(knowledgeIDs, chunk.KnowledgeID)
+		}
+	}
+	knowledgeList, err := s.repo.GetKnowledgeBatch(ctx, tenantInfo.ID, knowledgeIDs)
+	if err != nil {
+		return err
+	}
+	knowledgeMap := make(map[string]*types.Knowledge, len(knowledgeList))
+	for _, knowledge := range knowledgeList {
+		knowledgeMap[knowledge.ID] = knowledge
+	}
+
 	// Initialize composite retrieve engine from tenant configuration
 	indexInfo := make([]*types.IndexInfo, 0, len(chunks))
 	ids := make([]string, 0, len(chunks))
@@ -2330,11 +2424,11 @@ func (s *knowledgeService) updateChunkVector(ctx context.Context, kbID string, c
 			ChunkID:         chunk.ID,
 			KnowledgeID:     chunk.KnowledgeID,
 			KnowledgeBaseID: chunk.KnowledgeBaseID,
+			Attributes:      types.NewIndexAttributes(knowledgeMap[chunk.KnowledgeID], chunk),
 		})
 		ids = append(ids, chunk.ID)
 	}
 
-	tenantInfo := ctx.Value(types.TenantInfoContextKey).(*types.Tenant)
 	retrieveEngine, err := retriever.NewCompositeRetrieveEngine(s.retrieveEngine, tenantInfo.GetEffectiveEngines())
 	if err != nil {
 		return err
@@ -2377,6 +2471,9 @@ func (s *knowledgeService) UpdateImageInfo(
 		logger.Errorf(ctx, "Failed to get chunk: %v", err)
 		return err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, chunk.KnowledgeBaseID); err != nil {
+		return err
+	}
 	chunk.ImageInfo = imageInfo
 	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
 	chunkChildren, err := s.chunkService.ListChunkByParentID(ctx, tenantID, chunkID)
@@ -2733,6 +2830,9 @@ func (s *knowledgeService) UpsertFAQEntries(ctx context.Context,
 	if err != nil {
 		return "", err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		return "", err
+	}
 
 	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
 
@@ -3258,6 +3358,9 @@ func (s *knowledgeService) CreateFAQEntry(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		return nil, err
+	}
 	kb.EnsureDefaults()
 
 	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
@@ -3420,6 +3523,9 @@ func (s *knowledgeService) UpdateFAQEntry(ctx context.Context,
 	if err != nil {
 		return err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		return err
+	}
 	kb.EnsureDefaults()
 	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
 	chunk, err := s.chunkRepo.GetChunkByID(ctx, tenantID, entryID)
@@ -4180,6 +4286,9 @@ func (s *knowledgeService) DeleteFAQEntries(ctx context.Context,
 	if err != nil {
 		return err
 	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		return err
+	}
 
 	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
 	var faqKnowledge *types.Knowledge
@@ -4439,7 +4548,11 @@ func (s *knowledgeService) updateFAQImportProgressStatus(
 		}
 	}
 
-	return s.saveFAQImportProgress(ctx, existingProgress)
+	if err := s.saveFAQImportProgress(ctx, existingProgress); err != nil {
+		return err
+	}
+	s.notifyFAQImportFinished(ctx, existingProgress)
+	return nil
 }
 
 // getRunningFAQImportTaskID checks if there's a running FAQ import task for the given KB
@@ -4651,6 +4764,7 @@ func buildFAQIndexContent(meta *types.FAQChunkMetadata, mode types.FAQIndexMode)
 func (s *knowledgeService) buildFAQIndexInfoList(
 	ctx context.Context,
 	kb *types.KnowledgeBase,
+	knowledge *types.Knowledge,
 	chunk *types.Chunk,
 ) ([]*types.IndexInfo, error) {
 	indexMode := types.FAQIndexModeQuestionAnswer
@@ -4671,6 +4785,7 @@ func (s *knowledgeService) buildFAQIndexInfoList(
 	if meta == nil {
 		meta = &types.FAQChunkMetadata{StandardQuestion: chunk.Content}
 	}
+	attributes := types.NewIndexAttributes(knowledge, chunk)
 
 	// 如果是一起索引模式，使用原有逻辑
 	if questionIndexMode == types.FAQQuestionIndexModeCombined {
@@ -4686,6 +4801,7 @@ func (s *knowledgeService) buildFAQIndexInfoList(
 				KnowledgeType:   types.KnowledgeTypeFAQ,
 				TagID:           chunk.TagID,
 				IsEnabled:       chunk.IsEnabled,
+				Attributes:      attributes,
 			},
 		}, nil
 	}
@@ -4714,6 +4830,7 @@ func (s *knowledgeService) buildFAQIndexInfoList(
 		KnowledgeType:   types.KnowledgeTypeFAQ,
 		TagID:           chunk.TagID,
 		IsEnabled:       chunk.IsEnabled,
+		Attributes:      attributes,
 	})
 
 	// 每个相似问创建一个索引项
@@ -4739,6 +4856,7 @@ func (s *knowledgeService) buildFAQIndexInfoList(
 			KnowledgeType:   types.KnowledgeTypeFAQ,
 			TagID:           chunk.TagID,
 			IsEnabled:       chunk.IsEnabled,
+			Attributes:      attributes,
 		})
 	}
 
@@ -4767,7 +4885,7 @@ func (s *knowledgeService) indexFAQChunks(ctx context.Context,
 	indexInfo := make([]*types.IndexInfo, 0)
 	chunkIDs := make([]string, 0, len(chunks))
 	for _, chunk := range chunks {
-		infoList, err := s.buildFAQIndexInfoList(ctx, kb, chunk)
+		infoList, err := s.buildFAQIndexInfoList(ctx, kb, knowledge, chunk)
 		if err != nil {
 			return err
 		}
@@ -4872,7 +4990,7 @@ func (s *knowledgeService) deleteFAQChunkVectors(ctx context.Context,
 	indexInfo := make([]*types.IndexInfo, 0)
 	chunkIDs := make([]string, 0, len(chunks))
 	for _, chunk := range chunks {
-		infoList, err := s.buildFAQIndexInfoList(ctx, kb, chunk)
+		infoList, err := s.buildFAQIndexInfoList(ctx, kb, knowledge, chunk)
 		if err != nil {
 			return err
 		}
@@ -4938,6 +5056,7 @@ func (s *knowledgeService) triggerManualProcessing(ctx context.Context,
 			knowledge.ErrorMessage = cfgErr.Error()
 			knowledge.UpdatedAt = time.Now()
 			s.repo.UpdateKnowledge(ctx, knowledge)
+			s.notifyKnowledgeProcessed(ctx, knowledge)
 			return
 		}
 		if cfg == nil {
@@ -4979,16 +5098,21 @@ func (s *knowledgeService) triggerManualProcessing(ctx context.Context,
 		knowledge.ErrorMessage = err.Error()
 		knowledge.UpdatedAt = time.Now()
 		s.repo.UpdateKnowledge(ctx, knowledge)
+		s.notifyKnowledgeProcessed(ctx, knowledge)
 		return
 	}
 
 	if sync {
 		s.processChunks(ctx, kb, knowledge, resp.Chunks)
+		s.notifyKnowledgeProcessed(ctx, knowledge)
 		return
 	}
 
 	newCtx := logger.CloneContext(ctx)
-	go s.processChunks(newCtx, kb, knowledge, resp.Chunks)
+	go func() {
+		s.processChunks(newCtx, kb, knowledge, resp.Chunks)
+		s.notifyKnowledgeProcessed(newCtx, knowledge)
+	}()
 }
 
 func (s *knowledgeService) cleanupKnowledgeResources(ctx context.Context, knowledge *types.Knowledge) error {
@@ -5016,8 +5140,18 @@ func (s *knowledgeService) cleanupKnowledgeResources(ctx context.Context, knowle
 				logger.GetLogger(ctx).WithField("error", modelErr).Error("Failed to get embedding model during cleanup")
 				cleanupErr = errors.Join(cleanupErr, modelErr)
 			} else {
-				if err := retrieveEngine.DeleteByKnowledgeIDList(ctx, []string{knowledge.ID}, embeddingModel.GetDimensions(), knowledge.Type); err != nil {
-					logger.GetLogger(ctx).WithField("error", err).Error("Failed to delete manual knowledge index")
+				chunks, err := s.chunkRepo.ListChunksByKnowledgeIDAsOf(ctx, knowledge.TenantID, knowledge.ID, nil)
+				if err != nil {
+					logger.GetLogger(ctx).WithField("error", err).Error("Failed to list manual knowledge chunks")
+					cleanupErr = errors.Join(cleanupErr, err)
+				}
+				chunkIDs := make([]string, 0, len(chunks))
+				for _, chunk := range chunks {
+					chunkIDs = append(chunkIDs, chunk.ID)
+				}
+				// The index of the previous content is archived for point-in-time retrieval
+				if err := s.archiveChunkIndices(ctx, retrieveEngine, knowledge, chunkIDs, embeddingModel.GetDimensions()); err != nil {
+					logger.GetLogger(ctx).WithField("error", err).Error("Failed to archive manual knowledge index")
 					cleanupErr = errors.Join(cleanupErr, err)
 				}
 			}
@@ -5158,6 +5292,9 @@ func (s *knowledgeService) ProcessDocument(ctx context.Context, t *asynq.Task) e
 		// 这里可以根据错误类型判断是否可恢复，暂时允许重试
 	}
 
+	// 处理结束时通知 webhook（完成或最终失败时）
+	defer s.notifyKnowledgeProcessed(ctx, knowledge)
+
 	// 检查是否有部分处理（有chunks但状态不是completed）
 	if knowledge.ParseStatus != "completed" && knowledge.ParseStatus != "pending" &&
 		knowledge.ParseStatus != "processing" {
@@ -5339,6 +5476,7 @@ func (s *knowledgeService) ProcessDocument(ctx context.Context, t *asynq.Task) e
 	s.processChunks(ctx, kb, knowledge, chunks, ProcessChunksOptions{
 		EnableQuestionGeneration: payload.EnableQuestionGeneration,
 		QuestionCount:            payload.QuestionCount,
+		Incremental:              payload.Incremental,
 	})
 
 	return nil
@@ -5966,7 +6104,11 @@ func (s *knowledgeService) saveKBCloneProgress(ctx context.Context, progress *ty
 	if err != nil {
 		return fmt.Errorf("failed to marshal progress: %w", err)
 	}
-	return s.redisClient.Set(ctx, key, data, kbCloneProgressTTL).Err()
+	if err := s.redisClient.Set(ctx, key, data, kbCloneProgressTTL).Err(); err != nil {
+		return err
+	}
+	s.notifyKBCloneFinished(ctx, progress)
+	return nil
 }
 
 // SaveKBCloneProgress saves the KB clone progress to Redis (public method for handler use)
@@ -0,0 +1,606 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/application/repository"
+	"github.com/Tencent/WeKnora/internal/application/service/connector"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// connectorSyncTimeout bounds a single sync of a connector
+	connectorSyncTimeout = 2 * time.Hour
+	// connectorSyncMaxErrors is the number of item errors kept in the sync stats
+	connectorSyncMaxErrors = 20
+)
+
+// connectorService implements the ConnectorService interface.
+// A sync lists the items of the source and applies the changes since the previous sync
+// through the create, update and delete paths of the knowledge service.
+type connectorService struct {
+	repo             interfaces.ConnectorRepository
+	kbService        interfaces.KnowledgeBaseService
+	knowledgeService interfaces.KnowledgeService
+	tenantRepo       interfaces.TenantRepository
+	task             *asynq.Client
+}
+
+// NewConnectorService creates a new knowledge connector service
+func NewConnectorService(
+	repo interfaces.ConnectorRepository,
+	kbService interfaces.KnowledgeBaseService,
+	knowledgeService interfaces.KnowledgeService,
+	tenantRepo interfaces.TenantRepository,
+	task *asynq.Client,
+) interfaces.ConnectorService {
+	return &connectorService{
+		repo:             repo,
+		kbService:        kbService,
+		knowledgeService: knowledgeService,
+		tenantRepo:       tenantRepo,
+		task:             task,
+	}
+}
+
+// CreateConnector creates a connector for a document knowledge base
+func (s *connectorService) CreateConnector(ctx context.Context,
+	kc *types.KnowledgeConnector,
+) (*types.KnowledgeConnector, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	if strings.TrimSpace(kc.Name) == "" {
+		return nil, werrors.NewBadRequestError("커넥터 이름은 필수입니다")
+	}
+	if err := s.validateConnector(kc); err != nil {
+		return nil, err
+	}
+	kb, err := s.kbService.GetKnowledgeBaseByID(ctx, kc.KnowledgeBaseID)
+	if err != nil || kb.TenantID != tenantID {
+		return nil, werrors.NewNotFoundError("지식베이스를 찾을 수 없습니다")
+	}
+	if kb.Type == types.KnowledgeBaseTypeFAQ {
+		return nil, werrors.NewBadRequestError("FAQ 지식베이스에는 커넥터를 추가할 수 없습니다")
+	}
+
+	kc.ID = ""
+	kc.TenantID = tenantID
+	kc.SyncStatus = types.ConnectorSyncStatusIdle
+	kc.Cursor = ""
+	kc.LastError = ""
+	kc.LastSyncStats = types.ConnectorSyncStats{}
+	kc.ItemCount = 0
+	kc.LastSyncAt = nil
+	kc.LastSuccessAt = nil
+	kc.CreatedAt = time.Now()
+	kc.UpdatedAt = kc.CreatedAt
+	if err := s.repo.CreateConnector(ctx, kc); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"knowledge_base_id": kc.KnowledgeBaseID})
+		return nil, err
+	}
+	logger.Infof(ctx, "Knowledge connector created, ID: %s, type: %s, knowledge base: %s",
+		kc.ID, kc.Type, kc.KnowledgeBaseID)
+	return kc, nil
+}
+
+// validateConnector checks the type, configuration and schedule of a connector
+func (s *connectorService) validateConnector(kc *types.KnowledgeConnector) error {
+	if kc.SyncIntervalMinutes < 0 {
+		return werrors.NewBadRequestError("동기화 간격은 0 이상이어야 합니다")
+	}
+	if err := connector.Validate(kc.Type, kc.Config); err != nil {
+		return werrors.NewBadRequestError("커넥터 구성이 올바르지 않습니다: " + err.Error())
+	}
+	return nil
+}
+
+// GetConnector gets a connector by ID
+func (s *connectorService) GetConnector(ctx context.Context, id string) (*types.KnowledgeConnector, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	kc, err := s.repo.GetConnectorByID(ctx, tenantID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrConnectorNotFound) {
+			return nil, werrors.NewNotFoundError("커넥터를 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	return kc, nil
+}
+
+// ListConnectors lists the connectors of a knowledge base
+func (s *connectorService) ListConnectors(ctx context.Context, kbID string) ([]*types.KnowledgeConnector, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	return s.repo.ListConnectorsByKnowledgeBaseID(ctx, tenantID, kbID)
+}
+
+// UpdateConnector updates the name, configuration and schedule of a connector.
+// A configuration change resets the cursor so the next sync compares all items.
+func (s *connectorService) UpdateConnector(ctx context.Context,
+	update *types.KnowledgeConnector,
+) (*types.KnowledgeConnector, error) {
+	kc, err := s.GetConnector(ctx, update.ID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(update.Name) != "" {
+		kc.Name = update.Name
+	}
+	configChanged := !connectorConfigEqual(kc.Config, update.Config)
+	kc.Config = update.Config
+	kc.Enabled = update.Enabled
+	kc.SyncIntervalMinutes = update.SyncIntervalMinutes
+	if err := s.validateConnector(kc); err != nil {
+		return nil, err
+	}
+	if configChanged {
+		kc.Cursor = ""
+	}
+	kc.UpdatedAt = time.Now()
+	if err := s.repo.UpdateConnector(ctx, kc); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"connector_id": kc.ID})
+		return nil, err
+	}
+	return kc, nil
+}
+
+// connectorConfigEqual compares two connector configurations
+func connectorConfigEqual(a, b types.ConnectorConfig) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// DeleteConnector deletes a connector. The synced knowledge is kept unless deleteKnowledge is set.
+func (s *connectorService) DeleteConnector(ctx context.Context, id string, deleteKnowledge bool) error {
+	kc, err := s.GetConnector(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deleteKnowledge {
+		items, err := s.repo.ListItems(ctx, kc.TenantID, kc.ID)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := s.deleteItemKnowledge(ctx, item); err != nil {
+				logger.Warnf(ctx, "Failed to delete knowledge %s of connector %s: %v", item.KnowledgeID, kc.ID, err)
+			}
+		}
+	}
+	if err := s.repo.DeleteConnector(ctx, kc.TenantID, kc.ID); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"connector_id": kc.ID})
+		return err
+	}
+	if err := connector.Cleanup(kc); err != nil {
+		logger.Warnf(ctx, "Failed to clean up connector %s: %v", kc.ID, err)
+	}
+	return nil
+}
+
+// TriggerSync enqueues a sync of a connector
+func (s *connectorService) TriggerSync(ctx context.Context, id string, force bool) (*types.ConnectorSyncState, error) {
+	kc, err := s.GetConnector(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.enqueueSync(ctx, kc, force); err != nil {
+		if errors.Is(err, asynq.ErrTaskIDConflict) {
+			return nil, werrors.NewConflictError("커넥터 동기화가 이미 대기 중이거나 진행 중입니다")
+		}
+		return nil, err
+	}
+	kc.SyncStatus = types.ConnectorSyncStatusPending
+	return connectorSyncState(kc), nil
+}
+
+// enqueueSync enqueues a sync task. The task ID is derived from the connector
+// so that a connector has at most one queued or running sync.
+func (s *connectorService) enqueueSync(ctx context.Context, kc *types.KnowledgeConnector, force bool) error {
+	payload, err := json.Marshal(types.ConnectorSyncPayload{
+		TenantID:    kc.TenantID,
+		ConnectorID: kc.ID,
+		Force:       force,
+	})
+	if err != nil {
+		return err
+	}
+	task := asynq.NewTask(types.TypeConnectorSync, payload,
+		asynq.Queue("low"),
+		asynq.TaskID("connector-sync:"+kc.ID),
+		asynq.MaxRetry(0),
+		asynq.Timeout(connectorSyncTimeout),
+	)
+	info, err := s.task.Enqueue(task)
+	if err != nil {
+		return err
+	}
+	logger.Infof(ctx, "Enqueued connector sync task: id=%s connector_id=%s force=%v", info.ID, kc.ID, force)
+	if err := s.repo.UpdateSyncStatus(ctx, kc.TenantID, kc.ID, types.ConnectorSyncStatusPending); err != nil {
+		logger.Warnf(ctx, "Failed to update sync status of connector %s: %v", kc.ID, err)
+	}
+	return nil
+}
+
+// GetSyncState gets the sync state of a connector
+func (s *connectorService) GetSyncState(ctx context.Context, id string) (*types.ConnectorSyncState, error) {
+	kc, err := s.GetConnector(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return connectorSyncState(kc), nil
+}
+
+// connectorSyncState returns the sync state of a connector
+func connectorSyncState(kc *types.KnowledgeConnector) *types.ConnectorSyncState {
+	return &types.ConnectorSyncState{
+		ConnectorID:     kc.ID,
+		KnowledgeBaseID: kc.KnowledgeBaseID,
+		Type:            kc.Type,
+		Status:          kc.SyncStatus,
+		Cursor:          kc.Cursor,
+		LastError:       kc.LastError,
+		LastSyncStats:   kc.LastSyncStats,
+		ItemCount:       kc.ItemCount,
+		LastSyncAt:      kc.LastSyncAt,
+		LastSuccessAt:   kc.LastSuccessAt,
+		NextSyncAt:      kc.NextSyncAt(),
+	}
+}
+
+// ProcessConnectorSchedule enqueues the syncs of the connectors whose sync interval has elapsed.
+// It runs periodically on the asynq scheduler.
+func (s *connectorService) ProcessConnectorSchedule(ctx context.Context, t *asynq.Task) error {
+	connectors, err := s.repo.ListScheduledConnectors(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list scheduled connectors: %v", err)
+		return err
+	}
+	now := time.Now()
+	for _, kc := range connectors {
+		if !kc.IsSyncDue(now) {
+			continue
+		}
+		if err := s.enqueueSync(ctx, kc, false); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+			logger.Errorf(ctx, "Failed to enqueue sync of connector %s: %v", kc.ID, err)
+		}
+	}
+	return nil
+}
+
+// ProcessConnectorSync handles Asynq connector sync tasks.
+// Sync failures are recorded in the sync state of the connector instead of being retried,
+// the next scheduled sync picks up the items that failed.
+func (s *connectorService) ProcessConnectorSync(ctx context.Context, t *asynq.Task) error {
+	var payload types.ConnectorSyncPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal connector sync payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	ctx = context.WithValue(ctx, types.TenantIDContextKey, payload.TenantID)
+	tenantInfo, err := s.tenantRepo.GetTenantByID(ctx, payload.TenantID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get tenant info: %v", err)
+		return fmt.Errorf("failed to get tenant info: %w", err)
+	}
+	ctx = context.WithValue(ctx, types.TenantInfoContextKey, tenantInfo)
+
+	kc, err := s.repo.GetConnectorByID(ctx, payload.TenantID, payload.ConnectorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrConnectorNotFound) {
+			logger.Infof(ctx, "Connector %s was deleted, skipping sync", payload.ConnectorID)
+			return nil
+		}
+		return err
+	}
+
+	start := time.Now()
+	kc.SyncStatus = types.ConnectorSyncStatusSyncing
+	kc.LastSyncAt = &start
+	if err := s.repo.UpdateSyncState(ctx, kc); err != nil {
+		logger.Warnf(ctx, "Failed to update sync state of connector %s: %v", kc.ID, err)
+	}
+	logger.Infof(ctx, "Start syncing connector %s (%s) into knowledge base %s", kc.ID, kc.Type, kc.KnowledgeBaseID)
+
+	stats, cursor, itemCount, err := s.syncConnector(ctx, kc, payload.Force)
+	kc.LastSyncStats = *stats
+	kc.ItemCount = itemCount
+	if err != nil {
+		logger.Errorf(ctx, "Failed to sync connector %s: %v", kc.ID, err)
+		kc.SyncStatus = types.ConnectorSyncStatusFailed
+		kc.LastError = err.Error()
+	} else {
+		now := time.Now()
+		kc.SyncStatus = types.ConnectorSyncStatusCompleted
+		kc.LastSuccessAt = &now
+		kc.LastError = strings.Join(stats.Errors, "\n")
+		// Items that failed are compared again on the next sync
+		if stats.Failed == 0 {
+			kc.Cursor = cursor
+		}
+	}
+	if err := s.repo.UpdateSyncState(ctx, kc); err != nil {
+		logger.Errorf(ctx, "Failed to update sync state of connector %s: %v", kc.ID, err)
+	}
+	logger.Infof(ctx, "Connector %s synced in %s: %d added, %d updated, %d deleted, %d unchanged, %d skipped, %d failed",
+		kc.ID, time.Since(start), stats.Added, stats.Updated, stats.Deleted, stats.Unchanged, stats.Skipped, stats.Failed)
+	return nil
+}
+
+// syncConnector lists the source of a connector and applies its changes to the knowledge base.
+// It returns the stats of the sync, the cursor of the listing and the number of synced items.
+func (s *connectorService) syncConnector(ctx context.Context,
+	kc *types.KnowledgeConnector, force bool,
+) (*types.ConnectorSyncStats, string, int, error) {
+	stats := &types.ConnectorSyncStats{}
+	items, err := s.repo.ListItems(ctx, kc.TenantID, kc.ID)
+	if err != nil {
+		return stats, "", kc.ItemCount, err
+	}
+	existing := make(map[string]*types.ConnectorItem, len(items))
+	for _, item := range items {
+		existing[item.ItemKey] = item
+	}
+
+	kb, err := s.kbService.GetKnowledgeBaseByID(ctx, kc.KnowledgeBaseID)
+	if err != nil {
+		return stats, "", len(existing), fmt.Errorf("failed to get knowledge base: %w", err)
+	}
+	source, err := connector.New(kc)
+	if err != nil {
+		return stats, "", len(existing), err
+	}
+	defer source.Close()
+
+	listing, err := source.List(ctx)
+	if err != nil {
+		return stats, "", len(existing), err
+	}
+	stats.Listed = len(listing.Items)
+	if !force && kc.Cursor != "" && listing.Cursor == kc.Cursor {
+		stats.Unchanged = len(existing)
+		return stats, listing.Cursor, len(existing), nil
+	}
+	// An empty listing more likely means an unmounted directory or a wrong prefix than a deleted source
+	if len(listing.Items) == 0 && len(existing) > 0 && !force {
+		return stats, "", len(existing), fmt.Errorf(
+			"source listed no items, keeping %d synced items; sync with force to delete them", len(existing))
+	}
+
+	maxSize := secutils.GetMaxFileSize()
+	seen := make(map[string]bool, len(listing.Items))
+	for _, item := range listing.Items {
+		if err := ctx.Err(); err != nil {
+			return stats, "", len(existing), err
+		}
+		if !matchConnectorPatterns(kc.Config, item.Key) {
+			continue
+		}
+		seen[item.Key] = true
+		// Web pages are ingested by URL, the file checks only apply to files
+		if item.URL == "" && (!isValidFileType(item.Key) || item.Size > maxSize) {
+			stats.Skipped++
+			continue
+		}
+		prev := existing[item.Key]
+		if prev != nil && prev.Version == item.Version {
+			stats.Unchanged++
+			continue
+		}
+		synced, err := s.syncItem(ctx, kb, kc, source, item, prev, stats)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to sync item %s of connector %s: %v", item.Key, kc.ID, err)
+			stats.Failed++
+			if len(stats.Errors) < connectorSyncMaxErrors {
+				stats.Errors = append(stats.Errors, fmt.Sprintf("%s: %v", item.Key, err))
+			}
+			continue
+		}
+		existing[item.Key] = synced
+	}
+
+	// Items no longer in the source, or excluded by the patterns, are deleted
+	for key, item := range existing {
+		if seen[key] {
+			continue
+		}
+		if err := s.deleteItemKnowledge(ctx, item); err != nil {
+			stats.Failed++
+			if len(stats.Errors) < connectorSyncMaxErrors {
+				stats.Errors = append(stats.Errors, fmt.Sprintf("%s: %v", key, err))
+			}
+			continue
+		}
+		if err := s.repo.DeleteItem(ctx, item.TenantID, item.ID); err != nil {
+			return stats, "", len(existing), err
+		}
+		delete(existing, key)
+		stats.Deleted++
+	}
+	return stats, listing.Cursor, len(existing), nil
+}
+
+// syncItem creates the knowledge of a new item or updates the knowledge of a changed item
+func (s *connectorService) syncItem(ctx context.Context,
+	kb *types.KnowledgeBase, kc *types.KnowledgeConnector, source interfaces.Connector,
+	item *types.ConnectorSourceItem, prev *types.ConnectorItem, stats *types.ConnectorSyncStats,
+) (*types.ConnectorItem, error) {
+	var knowledgeID string
+	var err error
+	if item.URL != "" {
+		knowledgeID, err = s.syncPageKnowledge(ctx, kb, item, prev, stats)
+	} else {
+		knowledgeID, err = s.syncFileKnowledge(ctx, kb, kc, source, item, prev, stats)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	synced := &types.ConnectorItem{
+		TenantID:    kc.TenantID,
+		ConnectorID: kc.ID,
+		ItemKey:     item.Key,
+		KnowledgeID: knowledgeID,
+		Version:     item.Version,
+		Size:        item.Size,
+		SyncedAt:    time.Now(),
+	}
+	if prev != nil {
+		synced.ID = prev.ID
+		synced.CreatedAt = prev.CreatedAt
+	}
+	if err := s.repo.SaveItem(ctx, synced); err != nil {
+		return nil, err
+	}
+	return synced, nil
+}
+
+// syncFileKnowledge creates the knowledge of a new file or updates the file of a changed one
+func (s *connectorService) syncFileKnowledge(ctx context.Context,
+	kb *types.KnowledgeBase, kc *types.KnowledgeConnector, source interfaces.Connector,
+	item *types.ConnectorSourceItem, prev *types.ConnectorItem, stats *types.ConnectorSyncStats,
+) (string, error) {
+	file, cleanup, err := readConnectorItem(ctx, source, item)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	if prev != nil {
+		_, err := s.knowledgeService.UpdateKnowledgeFile(ctx, prev.KnowledgeID, file, nil)
+		switch {
+		case err == nil:
+			stats.Updated++
+			return prev.KnowledgeID, nil
+		case errors.Is(err, repository.ErrKnowledgeNotFound):
+			// The knowledge was deleted by a user, it is created again
+		default:
+			return "", err
+		}
+	}
+	metadata := map[string]string{
+		"connector_id": kc.ID,
+		"source_key":   item.Key,
+	}
+	knowledge, err := s.knowledgeService.CreateKnowledgeFromFile(ctx, kb.ID, file, metadata, nil, item.Key)
+	var duplicate *types.DuplicateKnowledgeError
+	if errors.As(err, &duplicate) {
+		knowledge, err = duplicate.Knowledge, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	stats.Added++
+	return knowledge.ID, nil
+}
+
+// syncPageKnowledge creates the knowledge of a new web page or fetches a changed page again
+func (s *connectorService) syncPageKnowledge(ctx context.Context,
+	kb *types.KnowledgeBase, item *types.ConnectorSourceItem, prev *types.ConnectorItem,
+	stats *types.ConnectorSyncStats,
+) (string, error) {
+	if prev != nil {
+		_, err := s.knowledgeService.RefreshKnowledgeURL(ctx, prev.KnowledgeID)
+		switch {
+		case err == nil:
+			stats.Updated++
+			return prev.KnowledgeID, nil
+		case errors.Is(err, repository.ErrKnowledgeNotFound):
+			// The knowledge was deleted by a user, it is created again
+		default:
+			return "", err
+		}
+	}
+	knowledge, err := s.knowledgeService.CreateKnowledgeFromURL(ctx, kb.ID, item.URL, nil, item.Title)
+	var duplicate *types.DuplicateKnowledgeError
+	if errors.As(err, &duplicate) {
+		knowledge, err = duplicate.Knowledge, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	stats.Added++
+	return knowledge.ID, nil
+}
+
+// deleteItemKnowledge deletes the knowledge of a synced item, knowledge already deleted is ignored
+func (s *connectorService) deleteItemKnowledge(ctx context.Context, item *types.ConnectorItem) error {
+	err := s.knowledgeService.DeleteKnowledge(ctx, item.KnowledgeID)
+	if err != nil && !errors.Is(err, repository.ErrKnowledgeNotFound) {
+		return err
+	}
+	return nil
+}
+
+// readConnectorItem reads an item into a multipart file header, the form the knowledge service accepts files in.
+// The returned cleanup function removes the temporary files of large items.
+func readConnectorItem(ctx context.Context,
+	source interfaces.Connector, item *types.ConnectorSourceItem,
+) (*multipart.FileHeader, func(), error) {
+	reader, err := source.Open(ctx, item)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	maxSize := secutils.GetMaxFileSize()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", path.Base(item.Key))
+	if err != nil {
+		return nil, nil, err
+	}
+	n, err := io.Copy(part, io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if n > maxSize {
+		return nil, nil, fmt.Errorf("file exceeds the maximum size of %dMB", secutils.GetMaxFileSizeMB())
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(32 << 20)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := form.File["file"]
+	if len(files) == 0 {
+		form.RemoveAll()
+		return nil, nil, errors.New("failed to read item")
+	}
+	return files[0], func() { form.RemoveAll() }, nil
+}
+
+// matchConnectorPatterns reports whether an item key passes the include and exclude patterns of a connector.
+// Patterns are matched against the whole key and against the file name.
+func matchConnectorPatterns(cfg types.ConnectorConfig, key string) bool {
+	match := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, key); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, path.Base(key)); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(cfg.IncludePatterns) > 0 && !match(cfg.IncludePatterns) {
+		return false
+	}
+	return !match(cfg.ExcludePatterns)
+}
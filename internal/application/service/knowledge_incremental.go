@@ -0,0 +1,505 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Tencent/WeKnora/docreader/proto"
+	"github.com/Tencent/WeKnora/internal/application/service/retriever"
+	"github.com/Tencent/WeKnora/internal/application/service/versioning"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/tracing"
+	"github.com/Tencent/WeKnora/internal/types"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// UpdateKnowledgeFile replaces the file of a file knowledge with a new version.
+// The new version is re-parsed and matched with the existing chunks by content hash,
+// so only the added or changed chunks are embedded, indexed and enriched again.
+func (s *knowledgeService) UpdateKnowledgeFile(ctx context.Context,
+	knowledgeID string, file *multipart.FileHeader, enableMultimodel *bool,
+) (*types.Knowledge, error) {
+	logger.Infof(ctx, "Start updating knowledge file, knowledge ID: %s", knowledgeID)
+
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	knowledge, err := s.repo.GetKnowledgeByID(ctx, tenantID, knowledgeID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get knowledge: %v", err)
+		return nil, err
+	}
+	if knowledge.Type != "file" {
+		return nil, werrors.NewBadRequestError("파일 지식만 파일을 업데이트할 수 있습니다")
+	}
+	switch knowledge.ParseStatus {
+	case types.ParseStatusPending, types.ParseStatusProcessing, types.ParseStatusDeleting:
+		return nil, werrors.NewConflictError("지식이 처리 중이므로 파일을 업데이트할 수 없습니다")
+	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, knowledge.KnowledgeBaseID); err != nil {
+		return nil, err
+	}
+
+	fileType := getFileType(file.Filename)
+	if !isValidFileType(file.Filename) {
+		logger.Error(ctx, "Invalid file type")
+		return nil, ErrInvalidFileType
+	}
+	if fileType != knowledge.FileType {
+		return nil, werrors.NewBadRequestError("기존 파일과 같은 형식의 파일만 업데이트할 수 있습니다")
+	}
+
+	safeFilename, isValid := secutils.ValidateInput(file.Filename)
+	if !isValid {
+		logger.Errorf(ctx, "Invalid filename: %s", file.Filename)
+		return nil, werrors.NewValidationError("파일 이름에 유효하지 않은 문자가 포함되어 있습니다")
+	}
+
+	hash, err := calculateFileHash(file)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to calculate file hash: %v", err)
+		return nil, err
+	}
+	if hash == knowledge.FileHash {
+		logger.Infof(ctx, "File is not changed, skipping update: %s", knowledge.ID)
+		return knowledge, nil
+	}
+
+	// Check storage quota
+	tenantInfo := ctx.Value(types.TenantInfoContextKey).(*types.Tenant)
+	if tenantInfo.StorageQuota > 0 && tenantInfo.StorageUsed >= tenantInfo.StorageQuota {
+		logger.Error(ctx, "Storage quota exceeded")
+		return nil, types.NewStorageQuotaExceededError()
+	}
+
+	kb, err := s.kbService.GetKnowledgeBaseByID(ctx, knowledge.KnowledgeBaseID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get knowledge base: %v", err)
+		return nil, err
+	}
+
+	filePath, err := s.fileSvc.SaveFile(ctx, file, knowledge.TenantID, knowledge.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to save file, knowledge ID: %s, error: %v", knowledge.ID, err)
+		return nil, err
+	}
+	// Knowledge processed before versioning keeps its previous file as the first version
+	s.ensureKnowledgeVersion(ctx, knowledge)
+
+	return s.replaceKnowledgeFile(ctx, kb, knowledge, knowledgeFileUpdate{
+		fileName:         safeFilename,
+		filePath:         filePath,
+		fileSize:         file.Size,
+		fileHash:         hash,
+		enableMultimodel: enableMultimodel,
+	})
+}
+
+// knowledgeFileUpdate is the file a file knowledge is switched to
+type knowledgeFileUpdate struct {
+	fileName         string
+	filePath         string
+	fileSize         int64
+	fileHash         string
+	enableMultimodel *bool
+}
+
+// replaceKnowledgeFile switches a file knowledge to a stored file and enqueues its processing.
+// The previous file is deleted unless a version of the knowledge still references it.
+func (s *knowledgeService) replaceKnowledgeFile(ctx context.Context,
+	kb *types.KnowledgeBase, knowledge *types.Knowledge, update knowledgeFileUpdate,
+) (*types.Knowledge, error) {
+	oldFilePath := knowledge.FilePath
+	if knowledge.Title == knowledge.FileName {
+		knowledge.Title = update.fileName
+	}
+	knowledge.FileName = update.fileName
+	knowledge.FileSize = update.fileSize
+	knowledge.FileHash = update.fileHash
+	knowledge.FilePath = update.filePath
+	knowledge.ParseStatus = types.ParseStatusPending
+	knowledge.ErrorMessage = ""
+	knowledge.UpdatedAt = time.Now()
+	if err := s.repo.UpdateKnowledge(ctx, knowledge); err != nil {
+		logger.Errorf(ctx, "Failed to update knowledge with new file, ID: %s, error: %v", knowledge.ID, err)
+		return nil, err
+	}
+	if oldFilePath != "" && oldFilePath != update.filePath {
+		referenced, err := s.versionRepo.IsFileReferenced(ctx, knowledge.TenantID, knowledge.ID, oldFilePath)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to check versions of previous file %s: %v", oldFilePath, err)
+		} else if !referenced {
+			if err := s.fileSvc.DeleteFile(ctx, oldFilePath); err != nil {
+				logger.Warnf(ctx, "Failed to delete previous file %s: %v", oldFilePath, err)
+			}
+		}
+	}
+
+	enableMultimodelValue := kb.IsMultimodalEnabled()
+	if update.enableMultimodel != nil {
+		enableMultimodelValue = *update.enableMultimodel
+	}
+	enableQuestionGeneration, questionCount := questionGenerationOptions(kb)
+	// Data tables are summarized as a whole, they are always processed again
+	isDataTable := slices.Contains([]string{"csv", "xlsx", "xls"}, knowledge.FileType)
+
+	taskPayload := types.DocumentProcessPayload{
+		TenantID:                 knowledge.TenantID,
+		KnowledgeID:              knowledge.ID,
+		KnowledgeBaseID:          knowledge.KnowledgeBaseID,
+		FilePath:                 update.filePath,
+		FileName:                 update.fileName,
+		FileType:                 knowledge.FileType,
+		EnableMultimodel:         enableMultimodelValue,
+		EnableQuestionGeneration: enableQuestionGeneration,
+		QuestionCount:            questionCount,
+		Incremental:              !isDataTable,
+	}
+	payloadBytes, err := json.Marshal(taskPayload)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to marshal document process task payload: %v", err)
+		return knowledge, nil
+	}
+
+	task := asynq.NewTask(types.TypeDocumentProcess, payloadBytes, asynq.Queue("default"))
+	info, err := s.task.Enqueue(task)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to enqueue document process task: %v", err)
+		return knowledge, nil
+	}
+	logger.Infof(ctx, "Enqueued document update task: id=%s queue=%s knowledge_id=%s incremental=%v",
+		info.ID, info.Queue, knowledge.ID, taskPayload.Incremental)
+
+	if isDataTable {
+		NewDataTableSummaryTask(ctx, s.task, knowledge.TenantID, knowledge.ID, kb.SummaryModelID, kb.EmbeddingModelID)
+	}
+	return knowledge, nil
+}
+
+// RefreshKnowledgeURL fetches the page of a URL knowledge again.
+// The page is re-parsed and matched with the existing chunks by content hash,
+// so only the added or changed chunks are embedded, indexed and enriched again.
+func (s *knowledgeService) RefreshKnowledgeURL(ctx context.Context, knowledgeID string) (*types.Knowledge, error) {
+	logger.Infof(ctx, "Start refreshing URL knowledge, knowledge ID: %s", knowledgeID)
+
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	knowledge, err := s.repo.GetKnowledgeByID(ctx, tenantID, knowledgeID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get knowledge: %v", err)
+		return nil, err
+	}
+	if knowledge.Type != "url" {
+		return nil, werrors.NewBadRequestError("URL 지식만 새로 고칠 수 있습니다")
+	}
+	switch knowledge.ParseStatus {
+	case types.ParseStatusPending, types.ParseStatusProcessing, types.ParseStatusDeleting:
+		return nil, werrors.NewConflictError("지식이 처리 중이므로 새로 고칠 수 없습니다")
+	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, knowledge.KnowledgeBaseID); err != nil {
+		return nil, err
+	}
+
+	kb, err := s.kbService.GetKnowledgeBaseByID(ctx, knowledge.KnowledgeBaseID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get knowledge base: %v", err)
+		return nil, err
+	}
+	// Knowledge processed before versioning keeps its current content as the first version
+	s.ensureKnowledgeVersion(ctx, knowledge)
+
+	knowledge.ParseStatus = types.ParseStatusPending
+	knowledge.ErrorMessage = ""
+	knowledge.UpdatedAt = time.Now()
+	if err := s.repo.UpdateKnowledge(ctx, knowledge); err != nil {
+		logger.Errorf(ctx, "Failed to update knowledge status, ID: %s, error: %v", knowledge.ID, err)
+		return nil, err
+	}
+
+	enableQuestionGeneration, questionCount := questionGenerationOptions(kb)
+	taskPayload := types.DocumentProcessPayload{
+		TenantID:                 knowledge.TenantID,
+		KnowledgeID:              knowledge.ID,
+		KnowledgeBaseID:          knowledge.KnowledgeBaseID,
+		URL:                      knowledge.Source,
+		EnableMultimodel:         kb.IsMultimodalEnabled(),
+		EnableQuestionGeneration: enableQuestionGeneration,
+		QuestionCount:            questionCount,
+		Incremental:              true,
+	}
+	payloadBytes, err := json.Marshal(taskPayload)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to marshal URL process task payload: %v", err)
+		return knowledge, nil
+	}
+
+	task := asynq.NewTask(types.TypeDocumentProcess, payloadBytes, asynq.Queue("default"))
+	info, err := s.task.Enqueue(task)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to enqueue URL process task: %v", err)
+		return knowledge, nil
+	}
+	logger.Infof(ctx, "Enqueued URL refresh task: id=%s queue=%s knowledge_id=%s", info.ID, info.Queue, knowledge.ID)
+	return knowledge, nil
+}
+
+// questionGenerationOptions returns whether questions are generated for the chunks of a knowledge base and how many
+func questionGenerationOptions(kb *types.KnowledgeBase) (bool, int) {
+	questionCount := 3 // default
+	if kb.QuestionGenerationConfig == nil || !kb.QuestionGenerationConfig.Enabled {
+		return false, questionCount
+	}
+	if kb.QuestionGenerationConfig.QuestionCount > 0 {
+		questionCount = kb.QuestionGenerationConfig.QuestionCount
+	}
+	return true, questionCount
+}
+
+// pruneRelationChunks removes the given chunk IDs from the relations of a chunk
+func pruneRelationChunks(chunk *types.Chunk, removed map[string]bool) {
+	prune := func(relations types.JSON) types.JSON {
+		if len(relations) == 0 {
+			return relations
+		}
+		var ids []string
+		if err := json.Unmarshal(relations, &ids); err != nil {
+			return relations
+		}
+		ids = slices.DeleteFunc(ids, func(id string) bool { return removed[id] })
+		data, err := json.Marshal(ids)
+		if err != nil {
+			return relations
+		}
+		return types.JSON(data)
+	}
+	chunk.RelationChunks = prune(chunk.RelationChunks)
+	chunk.IndirectRelationChunks = prune(chunk.IndirectRelationChunks)
+}
+
+// processChunksIncremental applies the chunks of a re-parsed document to the existing chunks.
+// Unchanged chunks keep their IDs, index entries, generated questions and relations,
+// only added chunks are embedded and enriched and removed chunks are deleted with their index entries archived.
+func (s *knowledgeService) processChunksIncremental(ctx context.Context,
+	kb *types.KnowledgeBase, knowledge *types.Knowledge, chunks []*proto.Chunk,
+	options ProcessChunksOptions,
+) {
+	ctx, span := tracing.ContextWithSpan(ctx, "knowledgeService.processChunksIncremental")
+	defer span.End()
+
+	if s.isKnowledgeDeleting(ctx, knowledge.TenantID, knowledge.ID) {
+		logger.Infof(ctx, "Knowledge is being deleted, aborting chunk processing: %s", knowledge.ID)
+		span.AddEvent("aborted: knowledge is being deleted")
+		return
+	}
+
+	markFailed := func(err error) {
+		knowledge.ParseStatus = types.ParseStatusFailed
+		knowledge.ErrorMessage = err.Error()
+		knowledge.UpdatedAt = time.Now()
+		s.repo.UpdateKnowledge(ctx, knowledge)
+		span.RecordError(err)
+	}
+
+	// Chunks of all types are listed so that image and summary chunks follow their text chunks
+	existingChunks, err := s.chunkRepo.ListChunksByKnowledgeIDAsOf(ctx, knowledge.TenantID, knowledge.ID, nil)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list existing chunks: %v", err)
+		markFailed(err)
+		return
+	}
+	// Vectors of another embedding model cannot be reused, and there is nothing to reuse without text chunks
+	hasText := slices.ContainsFunc(existingChunks, func(c *types.Chunk) bool { return c.ChunkType == types.ChunkTypeText })
+	if !hasText || knowledge.EmbeddingModelID != kb.EmbeddingModelID {
+		logger.Infof(ctx, "Nothing to reuse for knowledge %s, processing all chunks", knowledge.ID)
+		options.Incremental = false
+		knowledge.EmbeddingModelID = kb.EmbeddingModelID
+		s.processChunks(ctx, kb, knowledge, chunks, options)
+		return
+	}
+
+	embeddingModel, err := s.modelService.GetEmbeddingModel(ctx, kb.EmbeddingModelID)
+	if err != nil {
+		logger.GetLogger(ctx).WithField("error", err).Errorf("processChunksIncremental get embedding model failed")
+		markFailed(err)
+		return
+	}
+	tenantInfo := ctx.Value(types.TenantInfoContextKey).(*types.Tenant)
+	retrieveEngine, err := retriever.NewCompositeRetrieveEngine(s.retrieveEngine, tenantInfo.GetEffectiveEngines())
+	if err != nil {
+		markFailed(err)
+		return
+	}
+
+	diff := versioning.DiffDocumentChunks(existingChunks, s.buildDocumentChunks(ctx, knowledge, chunks))
+	removed := diff.Removed
+	if diff.Changed() {
+		removed = append(removed, diff.Summaries...)
+	}
+	logger.Infof(ctx, "Incremental update of knowledge %s: %d unchanged, %d added, %d removed",
+		knowledge.ID, len(diff.Kept), len(diff.Added), len(removed))
+	span.SetAttributes(
+		attribute.Int("kept_count", len(diff.Kept)),
+		attribute.Int("added_count", len(diff.Added)),
+		attribute.Int("removed_count", len(removed)),
+	)
+
+	// Relink the text chunks in their new order and drop relations to removed chunks
+	sortedChunks := slices.Concat(diff.Kept, diff.Added)
+	sort.SliceStable(sortedChunks, func(i, j int) bool {
+		return sortedChunks[i].ChunkIndex < sortedChunks[j].ChunkIndex
+	})
+	for _, chunk := range sortedChunks {
+		chunk.PreChunkID = ""
+		chunk.NextChunkID = ""
+	}
+	textChunks := linkTextChunks(sortedChunks)
+
+	removedIDs := make([]string, 0, len(removed))
+	removedSet := make(map[string]bool, len(removed))
+	for _, chunk := range removed {
+		removedIDs = append(removedIDs, chunk.ID)
+		removedSet[chunk.ID] = true
+	}
+	for _, chunk := range diff.Kept {
+		pruneRelationChunks(chunk, removedSet)
+		chunk.UpdatedAt = time.Now()
+	}
+
+	buildIndexInfo := func(chunk *types.Chunk) *types.IndexInfo {
+		return &types.IndexInfo{
+			Content:         chunk.Content,
+			SourceID:        chunk.ID,
+			SourceType:      types.ChunkSourceType,
+			ChunkID:         chunk.ID,
+			KnowledgeID:     knowledge.ID,
+			KnowledgeBaseID: knowledge.KnowledgeBaseID,
+			Attributes:      types.NewIndexAttributes(knowledge, chunk),
+		}
+	}
+	addedIndexInfo := make([]*types.IndexInfo, 0, len(diff.Added))
+	addedIDs := make([]string, 0, len(diff.Added))
+	for _, chunk := range diff.Added {
+		addedIndexInfo = append(addedIndexInfo, buildIndexInfo(chunk))
+		addedIDs = append(addedIDs, chunk.ID)
+	}
+
+	// Check the storage quota for the growth of the index, index entries of removed chunks are archived
+	storageDelta := retrieveEngine.EstimateStorageSize(ctx, embeddingModel, addedIndexInfo)
+	if tenantInfo.StorageQuota > 0 && storageDelta > 0 {
+		tenantInfo, err = s.tenantRepo.GetTenantByID(ctx, tenantInfo.ID)
+		if err != nil {
+			markFailed(err)
+			return
+		}
+		if tenantInfo.StorageUsed+storageDelta > tenantInfo.StorageQuota {
+			markFailed(errors.New("저장 공간이 부족합니다"))
+			return
+		}
+	}
+
+	if s.isKnowledgeDeleting(ctx, knowledge.TenantID, knowledge.ID) {
+		logger.Infof(ctx, "Knowledge is being deleted, aborting before saving chunks: %s", knowledge.ID)
+		span.AddEvent("aborted: knowledge is being deleted before saving")
+		return
+	}
+
+	// Add and index new chunks first so a failure leaves the previous version searchable
+	if len(diff.Added) > 0 {
+		if err := s.chunkService.CreateChunks(ctx, diff.Added); err != nil {
+			markFailed(err)
+			return
+		}
+		if err := retrieveEngine.BatchIndex(ctx, embeddingModel, addedIndexInfo); err != nil {
+			if err := s.chunkService.DeleteChunks(ctx, addedIDs); err != nil {
+				logger.Errorf(ctx, "Delete chunks failed: %v", err)
+			}
+			if err := retrieveEngine.DeleteByChunkIDList(
+				ctx, addedIDs, embeddingModel.GetDimensions(), knowledge.Type,
+			); err != nil {
+				logger.Errorf(ctx, "Delete index failed: %v", err)
+			}
+			markFailed(err)
+			return
+		}
+	}
+	if len(diff.Kept) > 0 {
+		if err := s.chunkService.UpdateChunks(ctx, diff.Kept); err != nil {
+			markFailed(err)
+			return
+		}
+	}
+	// Index entries of removed chunks are archived for point-in-time retrieval, index entries of
+	// generated questions share the chunk ID and are archived alike.
+	// Graph data of removed chunks stays in the knowledge graph until the knowledge is processed from scratch.
+	if len(removedIDs) > 0 {
+		if err := s.archiveChunkIndices(
+			ctx, retrieveEngine, knowledge, removedIDs, embeddingModel.GetDimensions(),
+		); err != nil {
+			markFailed(err)
+			return
+		}
+		if err := s.chunkService.DeleteChunks(ctx, removedIDs); err != nil {
+			markFailed(err)
+			return
+		}
+	}
+
+	addedTextIDs := make([]string, 0, len(diff.Added))
+	for _, chunk := range diff.Added {
+		if chunk.ChunkType == types.ChunkTypeText {
+			addedTextIDs = append(addedTextIDs, chunk.ID)
+		}
+	}
+	if kb.ExtractConfig != nil && kb.ExtractConfig.Enabled {
+		for _, chunkID := range addedTextIDs {
+			if err := NewChunkExtractTask(ctx, s.task, knowledge.TenantID, chunkID, kb.SummaryModelID); err != nil {
+				logger.GetLogger(ctx).WithField("error", err).Errorf("processChunksIncremental create chunk extract task failed")
+				span.RecordError(err)
+			}
+		}
+	}
+
+	knowledge.ParseStatus = types.ParseStatusCompleted
+	knowledge.EnableStatus = "enabled"
+	knowledge.StorageSize = max(0, knowledge.StorageSize+storageDelta)
+	now := time.Now()
+	knowledge.ProcessedAt = &now
+	knowledge.UpdatedAt = now
+	if diff.Changed() {
+		if len(textChunks) > 0 {
+			knowledge.SummaryStatus = types.SummaryStatusPending
+		} else {
+			knowledge.SummaryStatus = types.SummaryStatusNone
+		}
+	}
+	if err := s.repo.UpdateKnowledge(ctx, knowledge); err != nil {
+		logger.GetLogger(ctx).WithField("error", err).Errorf("processChunksIncremental update knowledge failed")
+	}
+	s.recordKnowledgeVersion(ctx, knowledge, textChunks)
+
+	if options.EnableQuestionGeneration && len(addedTextIDs) > 0 {
+		questionCount := options.QuestionCount
+		if questionCount <= 0 {
+			questionCount = 3
+		}
+		if questionCount > 10 {
+			questionCount = 10
+		}
+		s.enqueueQuestionGenerationTask(ctx, knowledge.KnowledgeBaseID, knowledge.ID, questionCount, addedTextIDs...)
+	}
+	if diff.Changed() && len(textChunks) > 0 {
+		s.enqueueSummaryGenerationTask(ctx, knowledge.KnowledgeBaseID, knowledge.ID)
+	}
+
+	if storageDelta != 0 {
+		if err := s.tenantRepo.AdjustStorageUsed(ctx, tenantInfo.ID, storageDelta); err != nil {
+			logger.GetLogger(ctx).WithField("error", err).Errorf("processChunksIncremental update tenant storage used failed")
+		}
+	}
+	logger.GetLogger(ctx).Infof("processChunksIncremental successfully")
+}
@@ -0,0 +1,596 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/application/service/retriever"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/models/embedding"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	kbReembedProgressKeyPrefix = "kb_reembed_progress:"
+	kbReembedRunningKeyPrefix  = "kb_reembed_running:"
+	kbReembedProgressTTL       = 24 * time.Hour
+
+	// kbReembedBatchSize is the number of chunks embedded per batch
+	kbReembedBatchSize = 50
+	// kbReembedWaitTimeout bounds how long the switchover waits for knowledge still being parsed
+	kbReembedWaitTimeout = 10 * time.Minute
+	// kbReembedWaitInterval is the polling interval while waiting for knowledge being parsed
+	kbReembedWaitInterval = 5 * time.Second
+)
+
+// reembedChunkTypes are the chunk types written to the retrieve engines at ingestion
+var reembedChunkTypes = []types.ChunkType{
+	types.ChunkTypeText,
+	types.ChunkTypeSummary,
+	types.ChunkTypeImageOCR,
+	types.ChunkTypeImageCaption,
+	types.ChunkTypeTableSummary,
+	types.ChunkTypeTableColumn,
+	types.ChunkTypeFAQ,
+}
+
+// getKBReembedProgressKey returns the Redis key for storing KB re-embedding progress
+func getKBReembedProgressKey(taskID string) string {
+	return kbReembedProgressKeyPrefix + taskID
+}
+
+// getKBReembedRunningKey returns the Redis key for storing the running re-embedding task ID by KB ID
+func getKBReembedRunningKey(kbID string) string {
+	return kbReembedRunningKeyPrefix + kbID
+}
+
+// saveKBReembedProgress saves the KB re-embedding progress to Redis
+func (s *knowledgeService) saveKBReembedProgress(ctx context.Context, progress *types.KBReembedProgress) error {
+	key := getKBReembedProgressKey(progress.TaskID)
+	progress.UpdatedAt = time.Now().Unix()
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal KB reembed progress: %w", err)
+	}
+	return s.redisClient.Set(ctx, key, data, kbReembedProgressTTL).Err()
+}
+
+// GetKBReembedProgress retrieves the progress of a knowledge base re-embedding task
+func (s *knowledgeService) GetKBReembedProgress(ctx context.Context, taskID string) (*types.KBReembedProgress, error) {
+	key := getKBReembedProgressKey(taskID)
+	data, err := s.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, werrors.NewNotFoundError("KB reembed task not found")
+		}
+		return nil, fmt.Errorf("failed to get KB reembed progress from Redis: %w", err)
+	}
+
+	var progress types.KBReembedProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal KB reembed progress: %w", err)
+	}
+	return &progress, nil
+}
+
+// EnsureKnowledgeBaseWritable rejects changes to the content of a knowledge base while it is re-embedded.
+// The job reads every knowledge once, so knowledge or chunks changed afterwards would be missing from the new index.
+func (s *knowledgeService) EnsureKnowledgeBaseWritable(ctx context.Context, kbID string) error {
+	taskID, err := s.redisClient.Get(ctx, getKBReembedRunningKey(kbID)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return fmt.Errorf("failed to get running KB reembed task: %w", err)
+	}
+	return werrors.NewConflictError(
+		fmt.Sprintf("Knowledge base is being re-embedded (task ID: %s), try again after it completes", taskID))
+}
+
+// ReembedKnowledgeBase starts re-embedding all chunks of a knowledge base with a new embedding model.
+// The knowledge base keeps serving queries with its current model until the new index is complete.
+// An empty or unchanged model reindexes the knowledge base with its current model, which rewrites
+// the filter attributes of indices written before they were denormalized into the engines.
+func (s *knowledgeService) ReembedKnowledgeBase(
+	ctx context.Context,
+	kbID string,
+	embeddingModelID string,
+) (*types.KBReembedProgress, error) {
+	tenantInfo := ctx.Value(types.TenantInfoContextKey).(*types.Tenant)
+
+	kb, err := s.kbService.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	if embeddingModelID == "" {
+		embeddingModelID = kb.EmbeddingModelID
+	}
+	if embeddingModelID == "" {
+		return nil, werrors.NewBadRequestError("Embedding model is not configured")
+	}
+
+	model, err := s.modelService.GetModelByID(ctx, embeddingModelID)
+	if err != nil {
+		return nil, werrors.NewBadRequestError("Embedding model not found")
+	}
+	if model.Type != types.ModelTypeEmbedding {
+		return nil, werrors.NewBadRequestError("Model is not an embedding model")
+	}
+
+	parsing, err := s.repo.CountKnowledgeByStatus(ctx, tenantInfo.ID, kbID,
+		[]string{types.ParseStatusPending, types.ParseStatusProcessing})
+	if err != nil {
+		return nil, err
+	}
+	if parsing > 0 {
+		return nil, werrors.NewConflictError("Knowledge base has knowledge being processed")
+	}
+
+	taskID := uuid.New().String()
+	acquired, err := s.redisClient.SetNX(ctx, getKBReembedRunningKey(kbID), taskID, kbReembedProgressTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire KB reembed lock: %w", err)
+	}
+	if !acquired {
+		return nil, werrors.NewConflictError("Knowledge base is already being re-embedded")
+	}
+
+	payload := types.KBReembedPayload{
+		TenantID:         tenantInfo.ID,
+		TaskID:           taskID,
+		KnowledgeBaseID:  kbID,
+		EmbeddingModelID: embeddingModelID,
+		EffectiveEngines: tenantInfo.GetEffectiveEngines(),
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		_ = s.redisClient.Del(ctx, getKBReembedRunningKey(kbID)).Err()
+		return nil, fmt.Errorf("failed to marshal KB reembed payload: %w", err)
+	}
+
+	now := time.Now().Unix()
+	progress := &types.KBReembedProgress{
+		TaskID:          taskID,
+		KnowledgeBaseID: kbID,
+		SourceModelID:   kb.EmbeddingModelID,
+		TargetModelID:   embeddingModelID,
+		Status:          types.KBReembedStatusPending,
+		Message:         "Task queued",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.saveKBReembedProgress(ctx, progress); err != nil {
+		logger.Errorf(ctx, "Failed to save KB reembed progress: %v", err)
+	}
+
+	task := asynq.NewTask(types.TypeKBReembed, payloadBytes, asynq.Queue("default"), asynq.MaxRetry(3))
+	info, err := s.task.Enqueue(task)
+	if err != nil {
+		_ = s.redisClient.Del(ctx, getKBReembedRunningKey(kbID)).Err()
+		return nil, fmt.Errorf("failed to enqueue KB reembed task: %w", err)
+	}
+	logger.Infof(ctx, "Enqueued KB reembed task: id=%s queue=%s kb=%s model=%s -> %s",
+		info.ID, info.Queue, kbID, kb.EmbeddingModelID, embeddingModelID)
+	return progress, nil
+}
+
+// ProcessKBReembed handles Asynq knowledge base re-embedding tasks.
+// Chunks are embedded with the new model into a shadow index which replaces the live index
+// once every chunk has been embedded, so retrieval keeps using the old vectors until then.
+// Writes to the knowledge base are rejected while the task runs, and a retry of a task
+// interrupted during the switchover resumes the switchover instead of embedding again.
+func (s *knowledgeService) ProcessKBReembed(ctx context.Context, t *asynq.Task) error {
+	var payload types.KBReembedPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal KB reembed payload: %w", err)
+	}
+
+	// Add tenant ID to context
+	ctx = context.WithValue(ctx, types.TenantIDContextKey, payload.TenantID)
+
+	// Get tenant info and add to context
+	tenantInfo, err := s.tenantRepo.GetTenantByID(ctx, payload.TenantID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get tenant info: %v", err)
+		return fmt.Errorf("failed to get tenant info: %w", err)
+	}
+	ctx = context.WithValue(ctx, types.TenantInfoContextKey, tenantInfo)
+
+	// Check if this is the last retry
+	retryCount, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	isLastRetry := retryCount >= maxRetry
+
+	logger.Infof(ctx, "Processing KB reembed task: %s, kb: %s, model: %s, retry: %d/%d",
+		payload.TaskID, payload.KnowledgeBaseID, payload.EmbeddingModelID, retryCount, maxRetry)
+
+	progress, err := s.GetKBReembedProgress(ctx, payload.TaskID)
+	if err != nil {
+		progress = &types.KBReembedProgress{
+			TaskID:          payload.TaskID,
+			KnowledgeBaseID: payload.KnowledgeBaseID,
+			TargetModelID:   payload.EmbeddingModelID,
+			CreatedAt:       time.Now().Unix(),
+		}
+	}
+	// The shadow indices are complete once the switchover started, and may already be partly switched
+	resume := progress.Status == types.KBReembedStatusSwitching
+	if !resume {
+		progress.Status = types.KBReembedStatusProcessing
+		progress.Progress = 0
+		progress.Processed = 0
+		progress.Error = ""
+		progress.Message = "Starting knowledge base re-embedding..."
+		if err := s.saveKBReembedProgress(ctx, progress); err != nil {
+			logger.Errorf(ctx, "Failed to update KB reembed progress: %v", err)
+		}
+	}
+
+	// Helper function to handle errors - only mark as failed and release the KB on last retry
+	handleError := func(err error, message string) {
+		if isLastRetry {
+			progress.Status = types.KBReembedStatusFailed
+			progress.Error = err.Error()
+			progress.Message = message
+			_ = s.saveKBReembedProgress(ctx, progress)
+			_ = s.redisClient.Del(ctx, getKBReembedRunningKey(payload.KnowledgeBaseID)).Err()
+		}
+	}
+
+	kb, err := s.kbService.GetKnowledgeBaseByID(ctx, payload.KnowledgeBaseID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get knowledge base: %v", err)
+		handleError(err, "Failed to get knowledge base")
+		return err
+	}
+	if !resume {
+		progress.SourceModelID = kb.EmbeddingModelID
+	}
+
+	newEmbedder, err := s.modelService.GetEmbeddingModel(ctx, payload.EmbeddingModelID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get new embedding model: %v", err)
+		handleError(err, "Failed to get new embedding model")
+		return err
+	}
+	// The old dimension is only needed to locate the live index, it is unknown if the KB has never been indexed
+	oldDimension := 0
+	if kb.EmbeddingModelID != "" {
+		oldEmbedder, err := s.modelService.GetEmbeddingModel(ctx, kb.EmbeddingModelID)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to get old embedding model %s: %v", kb.EmbeddingModelID, err)
+		} else {
+			oldDimension = oldEmbedder.GetDimensions()
+		}
+	}
+
+	engines := payload.EffectiveEngines
+	if len(engines) == 0 {
+		engines = tenantInfo.GetEffectiveEngines()
+	}
+	retrieveEngine, err := retriever.NewCompositeRetrieveEngine(s.retrieveEngine, engines)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to init retrieve engine: %v", err)
+		handleError(err, "Failed to init retrieve engine")
+		return err
+	}
+
+	shadowKBID := types.ShadowKnowledgeBaseID(kb.ID)
+	archiveKBID := types.ArchiveKnowledgeBaseID(kb.ID)
+	shadowArchiveKBID := types.ShadowKnowledgeBaseID(archiveKBID)
+	if resume {
+		logger.Infof(ctx, "Resuming switchover of KB reembed task %s", payload.TaskID)
+	} else if message, err := s.buildReembedShadowIndices(
+		ctx, kb, retrieveEngine, newEmbedder, progress,
+	); err != nil {
+		handleError(err, message)
+		return err
+	}
+
+	// Switch the live index to the shadow index, then point the KB at the new model.
+	// The switching status is saved first so an interrupted switchover is resumed by the retry,
+	// replacing indices that have no shadow indices left does nothing.
+	progress.Status = types.KBReembedStatusSwitching
+	progress.Message = "Switching to the new index..."
+	if err := s.saveKBReembedProgress(ctx, progress); err != nil {
+		logger.Errorf(ctx, "Failed to save KB reembed progress: %v", err)
+		handleError(err, "Failed to switch to the new index")
+		return err
+	}
+
+	if err := retrieveEngine.ReplaceKnowledgeBaseIndices(
+		ctx, kb.ID, shadowKBID, oldDimension, newEmbedder.GetDimensions(),
+	); err != nil {
+		logger.Errorf(ctx, "Failed to replace indices: %v", err)
+		handleError(err, "Failed to switch to the new index")
+		return err
+	}
+	if err := retrieveEngine.ReplaceKnowledgeBaseIndices(
+		ctx, archiveKBID, shadowArchiveKBID, oldDimension, newEmbedder.GetDimensions(),
+	); err != nil {
+		logger.Errorf(ctx, "Failed to replace archived indices: %v", err)
+		handleError(err, "Failed to switch to the new index")
+		return err
+	}
+	if err := s.kbService.GetRepository().SwitchEmbeddingModel(
+		ctx, tenantInfo.ID, kb.ID, payload.EmbeddingModelID,
+	); err != nil {
+		logger.Errorf(ctx, "Failed to switch embedding model: %v", err)
+		handleError(err, "Failed to switch embedding model")
+		return err
+	}
+
+	progress.Status = types.KBReembedStatusCompleted
+	progress.Progress = 100
+	progress.Message = "Knowledge base re-embedding completed"
+	if err := s.saveKBReembedProgress(ctx, progress); err != nil {
+		logger.Errorf(ctx, "Failed to update KB reembed progress: %v", err)
+	}
+	if err := s.redisClient.Del(ctx, getKBReembedRunningKey(kb.ID)).Err(); err != nil {
+		logger.Warnf(ctx, "Failed to clear KB reembed running key: %v", err)
+	}
+
+	logger.Infof(ctx, "KB reembed task completed: %s, %d chunks", payload.TaskID, progress.Processed)
+	return nil
+}
+
+// buildReembedShadowIndices embeds the chunks of every completed knowledge of a knowledge base into
+// the shadow indices, it returns the progress message on failure
+func (s *knowledgeService) buildReembedShadowIndices(
+	ctx context.Context,
+	kb *types.KnowledgeBase,
+	retrieveEngine *retriever.CompositeRetrieveEngine,
+	newEmbedder embedding.Embedder,
+	progress *types.KBReembedProgress,
+) (string, error) {
+	// Remove leftovers of a previous attempt so every chunk is written exactly once.
+	// Archived index entries of previous knowledge versions are re-embedded into a shadow archive alike.
+	shadowKBID := types.ShadowKnowledgeBaseID(kb.ID)
+	shadowArchiveKBID := types.ShadowKnowledgeBaseID(types.ArchiveKnowledgeBaseID(kb.ID))
+	for _, id := range []string{shadowKBID, shadowArchiveKBID} {
+		if err := retrieveEngine.DeleteByKnowledgeBaseID(ctx, id, newEmbedder.GetDimensions()); err != nil {
+			logger.Errorf(ctx, "Failed to clean shadow index: %v", err)
+			return "Failed to clean shadow index", err
+		}
+	}
+
+	// Knowledge completed while the job runs is picked up by the following passes
+	processed := make(map[string]bool)
+	deadline := time.Now().Add(kbReembedWaitTimeout)
+	for {
+		pending, parsing, err := s.listReembedKnowledge(ctx, kb.TenantID, kb.ID, processed)
+		if err != nil {
+			logger.Errorf(ctx, "Failed to list knowledge: %v", err)
+			return "Failed to list knowledge", err
+		}
+		// The last pass right before the switchover finds nothing new, writes are rejected from then on
+		if len(pending) == 0 && parsing == 0 {
+			return "", nil
+		}
+
+		if err := s.countReembedChunks(ctx, kb.TenantID, pending, progress); err != nil {
+			logger.Errorf(ctx, "Failed to count chunks: %v", err)
+			return "Failed to count chunks", err
+		}
+		for _, knowledge := range pending {
+			if err := s.reembedKnowledge(ctx, kb, knowledge, shadowKBID, retrieveEngine, newEmbedder, progress); err != nil {
+				logger.Errorf(ctx, "Failed to re-embed knowledge %s: %v", knowledge.ID, err)
+				return fmt.Sprintf("Failed to re-embed knowledge %s", knowledge.ID), err
+			}
+			if err := s.reembedArchivedChunks(ctx, kb, knowledge, shadowArchiveKBID, retrieveEngine, newEmbedder); err != nil {
+				logger.Errorf(ctx, "Failed to re-embed archived chunks of knowledge %s: %v", knowledge.ID, err)
+				return fmt.Sprintf("Failed to re-embed knowledge %s", knowledge.ID), err
+			}
+			processed[knowledge.ID] = true
+		}
+
+		if len(pending) == 0 {
+			if time.Now().After(deadline) {
+				return "Timed out waiting for knowledge being processed",
+					fmt.Errorf("%d knowledge still being processed", parsing)
+			}
+			progress.Message = fmt.Sprintf("Waiting for %d knowledge being processed", parsing)
+			_ = s.saveKBReembedProgress(ctx, progress)
+			select {
+			case <-ctx.Done():
+				return "Re-embedding was cancelled", ctx.Err()
+			case <-time.After(kbReembedWaitInterval):
+			}
+		}
+	}
+}
+
+// listReembedKnowledge returns the completed knowledge not re-embedded yet and
+// the number of knowledge still being parsed
+func (s *knowledgeService) listReembedKnowledge(
+	ctx context.Context,
+	tenantID uint64,
+	kbID string,
+	processed map[string]bool,
+) ([]*types.Knowledge, int, error) {
+	knowledgeList, err := s.repo.ListKnowledgeByKnowledgeBaseID(ctx, tenantID, kbID)
+	if err != nil {
+		return nil, 0, err
+	}
+	pending := make([]*types.Knowledge, 0, len(knowledgeList))
+	parsing := 0
+	for _, knowledge := range knowledgeList {
+		switch knowledge.ParseStatus {
+		case types.ParseStatusCompleted:
+			if !processed[knowledge.ID] {
+				pending = append(pending, knowledge)
+			}
+		case types.ParseStatusPending, types.ParseStatusProcessing:
+			parsing++
+		}
+	}
+	return pending, parsing, nil
+}
+
+// countReembedChunks adds the number of indexable chunks of the knowledge to the progress total
+func (s *knowledgeService) countReembedChunks(
+	ctx context.Context,
+	tenantID uint64,
+	knowledgeList []*types.Knowledge,
+	progress *types.KBReembedProgress,
+) error {
+	for _, knowledge := range knowledgeList {
+		_, total, err := s.chunkRepo.ListPagedChunksByKnowledgeID(ctx, tenantID, knowledge.ID,
+			&types.Pagination{Page: 1, PageSize: 1}, reembedChunkTypes, "", "", "", "", knowledge.Type)
+		if err != nil {
+			return err
+		}
+		progress.Total += int(total)
+	}
+	progress.Message = fmt.Sprintf("Re-embedding %d chunks", progress.Total)
+	return s.saveKBReembedProgress(ctx, progress)
+}
+
+// reembedKnowledge writes the chunks of a knowledge to the shadow index with the new embedder
+func (s *knowledgeService) reembedKnowledge(
+	ctx context.Context,
+	kb *types.KnowledgeBase,
+	knowledge *types.Knowledge,
+	shadowKBID string,
+	retrieveEngine *retriever.CompositeRetrieveEngine,
+	embedder embedding.Embedder,
+	progress *types.KBReembedProgress,
+) error {
+	for page := 1; ; page++ {
+		chunks, total, err := s.chunkRepo.ListPagedChunksByKnowledgeID(ctx, kb.TenantID, knowledge.ID,
+			&types.Pagination{Page: page, PageSize: kbReembedBatchSize}, reembedChunkTypes, "", "", "", "asc", knowledge.Type)
+		if err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+
+		indexInfoList := make([]*types.IndexInfo, 0, len(chunks))
+		disabled := make(map[string]bool)
+		for _, chunk := range chunks {
+			infos, err := s.buildReembedIndexInfoList(ctx, kb, knowledge, chunk)
+			if err != nil {
+				return err
+			}
+			for _, info := range infos {
+				info.KnowledgeBaseID = shadowKBID
+			}
+			indexInfoList = append(indexInfoList, infos...)
+			if !chunk.IsEnabled {
+				disabled[chunk.ID] = false
+			}
+		}
+
+		if err := retrieveEngine.BatchIndex(ctx, embedder, indexInfoList); err != nil {
+			return err
+		}
+		// Chunk status updates match by chunk ID, so the live index is updated alike
+		if len(disabled) > 0 {
+			if err := retrieveEngine.BatchUpdateChunkEnabledStatus(ctx, disabled); err != nil {
+				return err
+			}
+		}
+
+		progress.Processed += len(chunks)
+		if progress.Total > 0 {
+			progress.Progress = min(99, progress.Processed*100/progress.Total)
+		}
+		_ = s.saveKBReembedProgress(ctx, progress)
+
+		if int64(page*kbReembedBatchSize) >= total {
+			return nil
+		}
+	}
+}
+
+// reembedArchivedChunks writes the deleted chunks of previous versions of a knowledge to the shadow archive,
+// so that point-in-time retrieval keeps working with the new embedder
+func (s *knowledgeService) reembedArchivedChunks(
+	ctx context.Context,
+	kb *types.KnowledgeBase,
+	knowledge *types.Knowledge,
+	shadowArchiveKBID string,
+	retrieveEngine *retriever.CompositeRetrieveEngine,
+	embedder embedding.Embedder,
+) error {
+	if knowledge.Type == types.KnowledgeTypeFAQ {
+		return nil
+	}
+	chunks, err := s.chunkRepo.ListDeletedChunksByKnowledgeID(ctx, kb.TenantID, knowledge.ID, reembedChunkTypes)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(chunks); start += kbReembedBatchSize {
+		batch := chunks[start:min(start+kbReembedBatchSize, len(chunks))]
+		indexInfoList := make([]*types.IndexInfo, 0, len(batch))
+		for _, chunk := range batch {
+			infos, err := s.buildReembedIndexInfoList(ctx, kb, knowledge, chunk)
+			if err != nil {
+				return err
+			}
+			for _, info := range infos {
+				info.KnowledgeBaseID = shadowArchiveKBID
+			}
+			indexInfoList = append(indexInfoList, infos...)
+		}
+		if err := retrieveEngine.BatchIndex(ctx, embedder, indexInfoList); err != nil {
+			return err
+		}
+	}
+	if len(chunks) > 0 {
+		logger.Infof(ctx, "Re-embedded %d archived chunks of knowledge %s", len(chunks), knowledge.ID)
+	}
+	return nil
+}
+
+// buildReembedIndexInfoList builds the index info of a chunk the same way as at ingestion
+func (s *knowledgeService) buildReembedIndexInfoList(
+	ctx context.Context,
+	kb *types.KnowledgeBase,
+	knowledge *types.Knowledge,
+	chunk *types.Chunk,
+) ([]*types.IndexInfo, error) {
+	if chunk.ChunkType == types.ChunkTypeFAQ {
+		return s.buildFAQIndexInfoList(ctx, kb, knowledge, chunk)
+	}
+
+	indexInfoList := []*types.IndexInfo{{
+		Content:         chunk.Content,
+		SourceID:        chunk.ID,
+		SourceType:      types.ChunkSourceType,
+		ChunkID:         chunk.ID,
+		KnowledgeID:     knowledge.ID,
+		KnowledgeBaseID: knowledge.KnowledgeBaseID,
+		Attributes:      types.NewIndexAttributes(knowledge, chunk),
+	}}
+
+	// Generated questions are indexed as separate entries of the chunk
+	meta, err := chunk.DocumentMetadata()
+	if err != nil {
+		logger.Warnf(ctx, "Failed to parse document metadata of chunk %s: %v", chunk.ID, err)
+		return indexInfoList, nil
+	}
+	if meta == nil {
+		return indexInfoList, nil
+	}
+	for _, gq := range meta.GeneratedQuestions {
+		indexInfoList = append(indexInfoList, &types.IndexInfo{
+			Content:         gq.Question,
+			SourceID:        fmt.Sprintf("%s-%s", chunk.ID, gq.ID),
+			SourceType:      types.ChunkSourceType,
+			ChunkID:         chunk.ID,
+			KnowledgeID:     knowledge.ID,
+			KnowledgeBaseID: knowledge.KnowledgeBaseID,
+			Attributes:      types.NewIndexAttributes(knowledge, chunk),
+		})
+	}
+	return indexInfoList, nil
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/application/service/retriever"
+	"github.com/Tencent/WeKnora/internal/application/service/versioning"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+// recordKnowledgeVersion records the processed content of a knowledge as its newest version.
+// Processing the same content again, such as a reparse, only refreshes the chunk count of the latest version.
+func (s *knowledgeService) recordKnowledgeVersion(ctx context.Context,
+	knowledge *types.Knowledge, textChunks []*types.Chunk,
+) {
+	contentHash := knowledgeContentHash(knowledge, textChunks)
+	latest, err := s.versionRepo.GetLatestVersion(ctx, knowledge.TenantID, knowledge.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get latest version of knowledge %s: %v", knowledge.ID, err)
+		return
+	}
+	if latest != nil && latest.ContentHash == contentHash {
+		if latest.ChunkCount != len(textChunks) {
+			latest.ChunkCount = len(textChunks)
+			if err := s.versionRepo.UpdateVersion(ctx, latest); err != nil {
+				logger.Errorf(ctx, "Failed to update version %d of knowledge %s: %v", latest.Version, knowledge.ID, err)
+			}
+		}
+		return
+	}
+
+	version := &types.KnowledgeVersion{
+		TenantID:        knowledge.TenantID,
+		KnowledgeID:     knowledge.ID,
+		KnowledgeBaseID: knowledge.KnowledgeBaseID,
+		Title:           knowledge.Title,
+		FileName:        knowledge.FileName,
+		FileType:        knowledge.FileType,
+		FileSize:        knowledge.FileSize,
+		FilePath:        knowledge.FilePath,
+		ContentHash:     contentHash,
+		Metadata:        knowledge.Metadata,
+		ChunkCount:      len(textChunks),
+	}
+	// The version number is allocated by the repository, concurrent updates may record versions in between
+	if err := s.versionRepo.CreateVersion(ctx, version); err != nil {
+		logger.Errorf(ctx, "Failed to record version of knowledge %s: %v", knowledge.ID, err)
+		return
+	}
+	logger.Infof(ctx, "Recorded version %d of knowledge %s", version.Version, knowledge.ID)
+}
+
+// ensureKnowledgeVersion records the current content of a knowledge processed before versioning was
+// introduced, so that its file and chunks stay restorable once the knowledge is updated
+func (s *knowledgeService) ensureKnowledgeVersion(ctx context.Context, knowledge *types.Knowledge) {
+	if knowledge.ParseStatus != types.ParseStatusCompleted {
+		return
+	}
+	latest, err := s.versionRepo.GetLatestVersion(ctx, knowledge.TenantID, knowledge.ID)
+	if err != nil || latest != nil {
+		return
+	}
+	chunks, err := s.chunkRepo.ListChunksByKnowledgeIDAsOf(ctx, knowledge.TenantID, knowledge.ID, nil)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list chunks of knowledge %s: %v", knowledge.ID, err)
+		return
+	}
+	s.recordKnowledgeVersion(ctx, knowledge, filterTextChunks(chunks))
+}
+
+// knowledgeContentHash identifies the content of a knowledge version: the file hash for files,
+// otherwise the hash of the text chunk contents in order
+func knowledgeContentHash(knowledge *types.Knowledge, textChunks []*types.Chunk) string {
+	if knowledge.Type == "file" && knowledge.FileHash != "" {
+		return knowledge.FileHash
+	}
+	h := sha256.New()
+	for _, chunk := range textChunks {
+		h.Write([]byte(versioning.ChunkContentHash(chunk)))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// filterTextChunks returns the text chunks among the chunks
+func filterTextChunks(chunks []*types.Chunk) []*types.Chunk {
+	textChunks := make([]*types.Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.ChunkType == types.ChunkTypeText {
+			textChunks = append(textChunks, chunk)
+		}
+	}
+	return textChunks
+}
+
+// archiveChunkIndices moves the index entries of superseded chunks to the archive of the knowledge base.
+// Archived entries are only searched by point-in-time retrieval and are removed with the knowledge.
+func (s *knowledgeService) archiveChunkIndices(ctx context.Context,
+	retrieveEngine *retriever.CompositeRetrieveEngine, knowledge *types.Knowledge, chunkIDs []string, dimension int,
+) error {
+	if len(chunkIDs) == 0 {
+		return nil
+	}
+	archiveID := types.ArchiveKnowledgeBaseID(knowledge.KnowledgeBaseID)
+	if err := retrieveEngine.MoveByChunkIDList(ctx, chunkIDs, archiveID, dimension, knowledge.Type); err != nil {
+		return err
+	}
+	logger.Infof(ctx, "Archived index entries of %d chunks of knowledge %s", len(chunkIDs), knowledge.ID)
+	return nil
+}
+
+// ListKnowledgeVersions lists the versions of a knowledge, newest first
+func (s *knowledgeService) ListKnowledgeVersions(ctx context.Context,
+	knowledgeID string,
+) ([]*types.KnowledgeVersion, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	if _, err := s.repo.GetKnowledgeByID(ctx, tenantID, knowledgeID); err != nil {
+		return nil, err
+	}
+	return s.versionRepo.ListVersions(ctx, tenantID, knowledgeID)
+}
+
+// getKnowledgeVersion gets a version of a knowledge, a missing version is a not found error
+func (s *knowledgeService) getKnowledgeVersion(ctx context.Context,
+	tenantID uint64, knowledgeID string, version int,
+) (*types.KnowledgeVersion, error) {
+	v, err := s.versionRepo.GetVersion(ctx, tenantID, knowledgeID, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, werrors.NewNotFoundError("지식 버전을 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// DiffKnowledgeVersions compares the text chunks valid at two versions of a knowledge by content
+func (s *knowledgeService) DiffKnowledgeVersions(ctx context.Context,
+	knowledgeID string, fromVersion int, toVersion int,
+) (*types.KnowledgeVersionDiff, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	if _, err := s.repo.GetKnowledgeByID(ctx, tenantID, knowledgeID); err != nil {
+		return nil, err
+	}
+	from, err := s.getKnowledgeVersion(ctx, tenantID, knowledgeID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.getKnowledgeVersion(ctx, tenantID, knowledgeID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	fromChunks, err := s.chunkRepo.ListChunksByKnowledgeIDAsOf(ctx, tenantID, knowledgeID, &from.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	toChunks, err := s.chunkRepo.ListChunksByKnowledgeIDAsOf(ctx, tenantID, knowledgeID, &to.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := versioning.DiffChunks(filterTextChunks(fromChunks), filterTextChunks(toChunks))
+	diff.KnowledgeID = knowledgeID
+	diff.FromVersion = fromVersion
+	diff.ToVersion = toVersion
+	return diff, nil
+}
+
+// RestoreKnowledgeVersion restores the content of a previous version, which is processed again as a new version.
+// Files are re-parsed from the preserved file of the version, manual knowledge from its stored content.
+func (s *knowledgeService) RestoreKnowledgeVersion(ctx context.Context,
+	knowledgeID string, version int,
+) (*types.Knowledge, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	knowledge, err := s.repo.GetKnowledgeByID(ctx, tenantID, knowledgeID)
+	if err != nil {
+		return nil, err
+	}
+	switch knowledge.ParseStatus {
+	case types.ParseStatusPending, types.ParseStatusProcessing, types.ParseStatusDeleting:
+		return nil, werrors.NewConflictError("지식이 처리 중이므로 버전을 복원할 수 없습니다")
+	}
+	if err := s.EnsureKnowledgeBaseWritable(ctx, knowledge.KnowledgeBaseID); err != nil {
+		return nil, err
+	}
+	v, err := s.getKnowledgeVersion(ctx, tenantID, knowledgeID, version)
+	if err != nil {
+		return nil, err
+	}
+	if v.IsCurrent() {
+		return nil, werrors.NewBadRequestError("이미 현재 버전입니다")
+	}
+	logger.Infof(ctx, "Restoring version %d of knowledge %s", version, knowledgeID)
+
+	switch {
+	case knowledge.IsManual():
+		var meta types.ManualKnowledgeMetadata
+		if err := json.Unmarshal(v.Metadata, &meta); err != nil || strings.TrimSpace(meta.Content) == "" {
+			return nil, werrors.NewBadRequestError("버전에 복원할 내용이 없습니다")
+		}
+		return s.UpdateManualKnowledge(ctx, knowledgeID, &types.ManualKnowledgePayload{
+			Title:   v.Title,
+			Content: meta.Content,
+			Status:  types.ManualKnowledgeStatusPublish,
+		})
+	case knowledge.Type == "file":
+		if v.FilePath == "" {
+			return nil, werrors.NewBadRequestError("버전에 복원할 파일이 없습니다")
+		}
+		if v.ContentHash == knowledge.FileHash {
+			logger.Infof(ctx, "File of version %d is the current file, skipping restore", version)
+			return knowledge, nil
+		}
+		kb, err := s.kbService.GetKnowledgeBaseByID(ctx, knowledge.KnowledgeBaseID)
+		if err != nil {
+			return nil, err
+		}
+		return s.replaceKnowledgeFile(ctx, kb, knowledge, knowledgeFileUpdate{
+			fileName: v.FileName,
+			filePath: v.FilePath,
+			fileSize: v.FileSize,
+			fileHash: v.ContentHash,
+		})
+	default:
+		return nil, werrors.NewBadRequestError("파일 또는 수동 지식의 버전만 복원할 수 있습니다")
+	}
+}
+
+// deleteKnowledgeVersions deletes the versions of deleted knowledge items and the files preserved for them.
+// The current files of the knowledge items are deleted by the caller.
+func deleteKnowledgeVersions(ctx context.Context,
+	versionRepo interfaces.KnowledgeVersionRepository, fileSvc interfaces.FileService,
+	tenantID uint64, knowledgeList []*types.Knowledge,
+) {
+	if len(knowledgeList) == 0 {
+		return
+	}
+	knowledgeIDs := make([]string, 0, len(knowledgeList))
+	deletedFiles := make(map[string]bool, len(knowledgeList))
+	for _, knowledge := range knowledgeList {
+		knowledgeIDs = append(knowledgeIDs, knowledge.ID)
+		deletedFiles[knowledge.FilePath] = true
+	}
+	versions, err := versionRepo.DeleteVersionsByKnowledgeIDs(ctx, tenantID, knowledgeIDs)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to delete knowledge versions: %v", err)
+		return
+	}
+	for _, version := range versions {
+		if version.FilePath == "" || deletedFiles[version.FilePath] {
+			continue
+		}
+		deletedFiles[version.FilePath] = true
+		if err := fileSvc.DeleteFile(ctx, version.FilePath); err != nil {
+			logger.Warnf(ctx, "Failed to delete file %s of version %d: %v", version.FilePath, version.Version, err)
+		}
+	}
+}
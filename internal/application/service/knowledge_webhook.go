@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// notifyKnowledgeProcessed publishes the outcome of processing a document to the tenant webhooks.
+// It runs when processing returns, runs that end in neither state (a retry is pending or the
+// document is being deleted) publish nothing.
+func (s *knowledgeService) notifyKnowledgeProcessed(ctx context.Context, knowledge *types.Knowledge) {
+	var event types.WebhookEvent
+	switch knowledge.ParseStatus {
+	case types.ParseStatusCompleted:
+		event = types.WebhookEventKnowledgeParsed
+	case types.ParseStatusFailed:
+		event = types.WebhookEventKnowledgeFailed
+	default:
+		return
+	}
+	s.webhookService.Publish(ctx, event, types.WebhookKnowledgeData{
+		KnowledgeID:     knowledge.ID,
+		KnowledgeBaseID: knowledge.KnowledgeBaseID,
+		Title:           knowledge.Title,
+		FileName:        knowledge.FileName,
+		Source:          knowledge.Source,
+		ParseStatus:     knowledge.ParseStatus,
+		Error:           knowledge.ErrorMessage,
+	})
+}
+
+// notifyFAQImportFinished publishes the outcome of an FAQ import to the tenant webhooks
+func (s *knowledgeService) notifyFAQImportFinished(ctx context.Context, progress *types.FAQImportProgress) {
+	switch progress.Status {
+	case types.FAQImportStatusCompleted:
+		s.webhookService.Publish(ctx, types.WebhookEventFAQImportCompleted, progress)
+	case types.FAQImportStatusFailed:
+		s.webhookService.Publish(ctx, types.WebhookEventFAQImportFailed, progress)
+	}
+}
+
+// notifyKBCloneFinished publishes the outcome of a knowledge base clone to the tenant webhooks
+func (s *knowledgeService) notifyKBCloneFinished(ctx context.Context, progress *types.KBCloneProgress) {
+	switch progress.Status {
+	case types.KBCloneStatusCompleted:
+		s.webhookService.Publish(ctx, types.WebhookEventKBCloneCompleted, progress)
+	case types.KBCloneStatusFailed:
+		s.webhookService.Publish(ctx, types.WebhookEventKBCloneFailed, progress)
+	}
+}
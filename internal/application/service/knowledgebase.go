@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/Tencent/WeKnora/internal/application/service/retriever"
+	"github.com/Tencent/WeKnora/internal/application/service/versioning"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
 	"github.com/Tencent/WeKnora/internal/logger"
 	"github.com/Tencent/WeKnora/internal/models/embedding"
 	"github.com/Tencent/WeKnora/internal/types"
@@ -25,6 +27,7 @@ type knowledgeBaseService struct {
 	repo           interfaces.KnowledgeBaseRepository
 	kgRepo         interfaces.KnowledgeRepository
 	chunkRepo      interfaces.ChunkRepository
+	versionRepo    interfaces.KnowledgeVersionRepository
 	modelService   interfaces.ModelService
 	retrieveEngine interfaces.RetrieveEngineRegistry
 	tenantRepo     interfaces.TenantRepository
@@ -37,6 +40,7 @@ type knowledgeBaseService struct {
 func NewKnowledgeBaseService(repo interfaces.KnowledgeBaseRepository,
 	kgRepo interfaces.KnowledgeRepository,
 	chunkRepo interfaces.ChunkRepository,
+	versionRepo interfaces.KnowledgeVersionRepository,
 	modelService interfaces.ModelService,
 	retrieveEngine interfaces.RetrieveEngineRegistry,
 	tenantRepo interfaces.TenantRepository,
@@ -48,6 +52,7 @@ func NewKnowledgeBaseService(repo interfaces.KnowledgeBaseRepository,
 		repo:           repo,
 		kgRepo:         kgRepo,
 		chunkRepo:      chunkRepo,
+		versionRepo:    versionRepo,
 		modelService:   modelService,
 		retrieveEngine: retrieveEngine,
 		tenantRepo:     tenantRepo,
@@ -75,6 +80,10 @@ func (s *knowledgeBaseService) CreateKnowledgeBase(ctx context.Context,
 	if kb.ID == "" {
 		kb.ID = uuid.New().String()
 	}
+	// 저장된 융합 구성은 모든 검색에 사용되므로 저장 전에 검증
+	if err := retriever.ValidateFusionConfig(kb.FusionConfig); err != nil {
+		return nil, err
+	}
 	kb.CreatedAt = time.Now()
 	kb.TenantID = ctx.Value(types.TenantIDContextKey).(uint64)
 	kb.UpdatedAt = time.Now()
@@ -180,6 +189,11 @@ func (s *knowledgeBaseService) UpdateKnowledgeBase(ctx context.Context,
 		logger.Error(ctx, "Knowledge base ID is empty")
 		return nil, errors.New("knowledge base ID cannot be empty")
 	}
+	if config != nil {
+		if err := retriever.ValidateFusionConfig(config.FusionConfig); err != nil {
+			return nil, err
+		}
+	}
 
 	logger.Infof(ctx, "Updating knowledge base, ID: %s, name: %s", id, name)
 
@@ -201,6 +215,10 @@ func (s *knowledgeBaseService) UpdateKnowledgeBase(ctx context.Context,
 	if config.FAQConfig != nil {
 		kb.FAQConfig = config.FAQConfig
 	}
+	// 융합 구성이 제공된 경우 업데이트
+	if config.FusionConfig != nil {
+		kb.FusionConfig = config.FusionConfig
+	}
 	kb.UpdatedAt = time.Now()
 	kb.EnsureDefaults()
 
@@ -356,6 +374,8 @@ func (s *knowledgeBaseService) ProcessKBDelete(ctx context.Context, t *asynq.Tas
 			}
 			storageAdjust -= knowledge.StorageSize
 		}
+		// 버전 기록 및 이전 버전의 파일 삭제
+		deleteKnowledgeVersions(ctx, s.versionRepo, s.fileSvc, tenantID, knowledgeList)
 		if storageAdjust != 0 {
 			if err := s.tenantRepo.AdjustStorageUsed(ctx, tenantID, storageAdjust); err != nil {
 				logger.Warnf(ctx, "Failed to adjust tenant storage: %v", err)
@@ -391,52 +411,6 @@ func (s *knowledgeBaseService) ProcessKBDelete(ctx context.Context, t *asynq.Tas
 	return nil
 }
 
-// SetEmbeddingModel 지식베이스의 임베딩 모델 설정
-func (s *knowledgeBaseService) SetEmbeddingModel(ctx context.Context, id string, modelID string) error {
-	if id == "" {
-		logger.Error(ctx, "Knowledge base ID is empty")
-		return errors.New("knowledge base ID cannot be empty")
-	}
-
-	if modelID == "" {
-		logger.Error(ctx, "Model ID is empty")
-		return errors.New("model ID cannot be empty")
-	}
-
-	logger.Infof(ctx, "Setting embedding model for knowledge base, knowledge base ID: %s, model ID: %s", id, modelID)
-
-	// 지식베이스 가져오기
-	kb, err := s.repo.GetKnowledgeBaseByID(ctx, id)
-	if err != nil {
-		logger.ErrorWithFields(ctx, err, map[string]interface{}{
-			"knowledge_base_id": id,
-		})
-		return err
-	}
-
-	// 지식베이스의 임베딩 모델 업데이트
-	kb.EmbeddingModelID = modelID
-	kb.UpdatedAt = time.Now()
-
-	logger.Info(ctx, "Saving knowledge base embedding model update")
-	err = s.repo.UpdateKnowledgeBase(ctx, kb)
-	if err != nil {
-		logger.ErrorWithFields(ctx, err, map[string]interface{}{
-			"knowledge_base_id":  id,
-			"embedding_model_id": modelID,
-		})
-		return err
-	}
-
-	logger.Infof(
-		ctx,
-		"Knowledge base embedding model set successfully, knowledge base ID: %s, model ID: %s",
-		id,
-		modelID,
-	)
-	return nil
-}
-
 // CopyKnowledgeBase 지식베이스를 새 지식베이스로 복사
 // 얕은 복사
 func (s *knowledgeBaseService) CopyKnowledgeBase(ctx context.Context,
@@ -474,6 +448,7 @@ func (s *knowledgeBaseService) CopyKnowledgeBase(ctx context.Context,
 			VLMConfig:             sourceKB.VLMConfig,
 			StorageConfig:         sourceKB.StorageConfig,
 			FAQConfig:             faqConfig,
+			FusionConfig:          sourceKB.FusionConfig,
 		}
 		targetKB.EnsureDefaults()
 		if err := s.repo.CreateKnowledgeBase(ctx, targetKB); err != nil {
@@ -490,8 +465,21 @@ func (s *knowledgeBaseService) HybridSearch(ctx context.Context,
 ) ([]*types.SearchResult, error) {
 	logger.Infof(ctx, "Hybrid search parameters, knowledge base ID: %s, query text: %s", id, params.QueryText)
 
+	// 메타데이터 필터 검증 (값 정규화 포함)
+	if params.Filter != nil {
+		if err := params.Filter.Validate(); err != nil {
+			return nil, werrors.NewBadRequestError("유효하지 않은 메타데이터 필터").WithDetails(err.Error())
+		}
+	}
+
 	tenantInfo := ctx.Value(types.TenantInfoContextKey).(*types.Tenant)
 
+	// 특정 시점 검색의 기준 시각 결정 (버전이 지정되면 해당 버전의 생성 시각)
+	asOf, err := versioning.AsOf(ctx, s.versionRepo, tenantInfo.ID, params)
+	if err != nil {
+		return nil, err
+	}
+
 	// 테넌트의 구성된 리트리버로 복합 검색 엔진 생성
 	retrieveEngine, err := retriever.NewCompositeRetrieveEngine(s.retrieveEngine, tenantInfo.GetEffectiveEngines())
 	if err != nil {
@@ -543,6 +531,8 @@ func (s *knowledgeBaseService) HybridSearch(ctx context.Context,
 			RetrieverType:    types.VectorRetrieverType,
 			KnowledgeIDs:     params.KnowledgeIDs,
 			TagIDs:           params.TagIDs,
+			Filter:           params.Filter,
+			AsOf:             asOf,
 		}
 
 		// FAQ 지식베이스의 경우 FAQ 인덱스 사용
@@ -554,6 +544,47 @@ func (s *knowledgeBaseService) HybridSearch(ctx context.Context,
 		logger.Info(ctx, "Vector retrieval parameters setup completed")
 	}
 
+	// 멀티 벡터 검색이 지원되고 벡터 검색이 비활성화되지 않은 경우 매개변수 추가
+	// 임베딩 모델이 토큰 단위 임베딩을 지원하지 않으면 건너뜀
+	if retrieveEngine.SupportRetriever(types.MultiVectorRetrieverType) && !params.DisableVectorMatch {
+		if embeddingModel == nil {
+			embeddingModel, err = s.modelService.GetEmbeddingModel(ctx, kb.EmbeddingModelID)
+			if err != nil {
+				logger.Errorf(ctx, "Failed to get embedding model, model ID: %s, error: %v", kb.EmbeddingModelID, err)
+				return nil, err
+			}
+		}
+		if tokenEmbedder, ok := embeddingModel.(embedding.TokenEmbedder); ok {
+			queryTokenEmbeddings, err := tokenEmbedder.EmbedQueryTokens(ctx, params.QueryText)
+			if err != nil {
+				logger.Errorf(ctx, "Failed to embed query tokens, query text: %s, error: %v", params.QueryText, err)
+				return nil, err
+			}
+			logger.Infof(ctx, "Query token embeddings generated successfully, token count: %d",
+				len(queryTokenEmbeddings))
+
+			multiVectorParams := types.RetrieveParams{
+				Query:            params.QueryText,
+				TokenEmbeddings:  queryTokenEmbeddings,
+				KnowledgeBaseIDs: []string{id},
+				TopK:             matchCount,
+				Threshold:        params.VectorThreshold,
+				RetrieverType:    types.MultiVectorRetrieverType,
+				KnowledgeIDs:     params.KnowledgeIDs,
+				TagIDs:           params.TagIDs,
+				Filter:           params.Filter,
+				AsOf:             asOf,
+			}
+			if kb.Type == types.KnowledgeBaseTypeFAQ {
+				multiVectorParams.KnowledgeType = types.KnowledgeTypeFAQ
+			}
+			retrieveParams = append(retrieveParams, multiVectorParams)
+		} else {
+			logger.Warnf(ctx, "Embedding model %s does not support token embeddings, skipping multi-vector retrieval",
+				embeddingModel.GetModelName())
+		}
+	}
+
 	// 키워드 검색이 지원되고 비활성화되지 않았으며 FAQ가 아닌 경우 매개변수 추가
 	if retrieveEngine.SupportRetriever(types.KeywordsRetrieverType) && !params.DisableKeywordsMatch &&
 		kb.Type != types.KnowledgeBaseTypeFAQ {
@@ -566,6 +597,8 @@ func (s *knowledgeBaseService) HybridSearch(ctx context.Context,
 			RetrieverType:    types.KeywordsRetrieverType,
 			KnowledgeIDs:     params.KnowledgeIDs,
 			TagIDs:           params.TagIDs,
+			Filter:           params.Filter,
+			AsOf:             asOf,
 		})
 		logger.Info(ctx, "Keyword retrieval parameters setup completed")
 	}
@@ -589,132 +622,54 @@ func (s *knowledgeBaseService) HybridSearch(ctx context.Context,
 	// 다른 리트리버의 모든 결과를 수집하고 청크 ID로 중복 제거
 	logger.Infof(ctx, "Processing retrieval results")
 
-	// RRF 퓨전을 위해 리트리버 유형별로 결과 분리
-	var vectorResults []*types.IndexWithScore
-	var keywordResults []*types.IndexWithScore
+	// 로깅 및 반복 검색 판단을 위해 리트리버 유형별 결과 수 집계
+	vectorCount, multiVectorCount, keywordCount := 0, 0, 0
 	for _, retrieveResult := range retrieveResults {
 		logger.Infof(ctx, "Retrieval results, engine: %v, retriever: %v, count: %v",
 			retrieveResult.RetrieverEngineType,
 			retrieveResult.RetrieverType,
 			len(retrieveResult.Results),
 		)
-		if retrieveResult.RetrieverType == types.VectorRetrieverType {
-			vectorResults = append(vectorResults, retrieveResult.Results...)
-		} else {
-			keywordResults = append(keywordResults, retrieveResult.Results...)
+		switch retrieveResult.RetrieverType {
+		case types.VectorRetrieverType:
+			vectorCount += len(retrieveResult.Results)
+		case types.MultiVectorRetrieverType:
+			multiVectorCount += len(retrieveResult.Results)
+		default:
+			keywordCount += len(retrieveResult.Results)
 		}
 	}
 
 	// 결과가 없으면 조기 반환
-	if len(vectorResults) == 0 && len(keywordResults) == 0 {
+	if vectorCount == 0 && multiVectorCount == 0 && keywordCount == 0 {
 		logger.Info(ctx, "No search results found")
 		return nil, nil
 	}
-	logger.Infof(ctx, "Result count before fusion: vector=%d, keyword=%d", len(vectorResults), len(keywordResults))
-
-	var deduplicatedChunks []*types.IndexWithScore
+	logger.Infof(ctx, "Result count before fusion: vector=%d, multi_vector=%d, keyword=%d",
+		vectorCount, multiVectorCount, keywordCount)
 
-	// 벡터 결과만 있는 경우(키워드 결과 없음), 원본 임베딩 점수 유지
+	// 요청 단위 융합 구성이 지식베이스 구성보다 우선
+	fusionConfig := kb.FusionConfig
+	if params.FusionConfig != nil {
+		fusionConfig = params.FusionConfig
+	}
+	// 여러 리트리버의 결과를 융합 (벡터 결과만 있는 경우 원본 임베딩 점수 유지)
 	// 이는 벡터 검색만 사용하는 FAQ 검색에 중요합니다
-	if len(keywordResults) == 0 {
-		logger.Info(ctx, "Only vector results, keeping original embedding scores")
-		chunkInfoMap := make(map[string]*types.IndexWithScore)
-		for _, r := range vectorResults {
-			// 각 청크에 대해 가장 높은 점수 유지 (FAQ에는 여러 유사한 질문이 있을 수 있음)
-			if existing, exists := chunkInfoMap[r.ChunkID]; !exists || r.Score > existing.Score {
-				chunkInfoMap[r.ChunkID] = r
-			}
-		}
-		deduplicatedChunks = make([]*types.IndexWithScore, 0, len(chunkInfoMap))
-		for _, info := range chunkInfoMap {
-			deduplicatedChunks = append(deduplicatedChunks, info)
-		}
-		slices.SortFunc(deduplicatedChunks, func(a, b *types.IndexWithScore) int {
-			if a.Score > b.Score {
-				return -1
-			} else if a.Score < b.Score {
-				return 1
-			}
-			return 0
-		})
-		logger.Infof(ctx, "Result count after deduplication: %d", len(deduplicatedChunks))
-	} else {
-		// RRF(Reciprocal Rank Fusion)를 사용하여 여러 리트리버의 결과 병합
-		// RRF 점수 = 청크가 나타나는 각 리트리버에 대해 sum(1 / (k + rank))
-		// k=60은 실제로 잘 작동하는 일반적인 선택입니다
-		const rrfK = 60
-
-		// 각 리트리버에 대한 순위 맵 구축 (이미 리트리버에서 점수순으로 정렬됨)
-		vectorRanks := make(map[string]int)
-		for i, r := range vectorResults {
-			if _, exists := vectorRanks[r.ChunkID]; !exists {
-				vectorRanks[r.ChunkID] = i + 1 // 1부터 시작하는 순위
-			}
-		}
-		keywordRanks := make(map[string]int)
-		for i, r := range keywordResults {
-			if _, exists := keywordRanks[r.ChunkID]; !exists {
-				keywordRanks[r.ChunkID] = i + 1 // 1부터 시작하는 순위
-			}
-		}
-
-		// 모든 고유 청크 수집 및 RRF 점수 계산
-		// 각 리트리버에서 각 청크에 대해 가장 높은 점수 유지
-		chunkInfoMap := make(map[string]*types.IndexWithScore)
-		rrfScores := make(map[string]float64)
-
-		// 벡터 결과 처리 - 청크당 최고 점수 유지
-		for _, r := range vectorResults {
-			if existing, exists := chunkInfoMap[r.ChunkID]; !exists || r.Score > existing.Score {
-				chunkInfoMap[r.ChunkID] = r
-			}
-		}
-		// 키워드 결과 처리 - 벡터에 아직 없는 경우에만 추가
-		for _, r := range keywordResults {
-			if _, exists := chunkInfoMap[r.ChunkID]; !exists {
-				chunkInfoMap[r.ChunkID] = r
-			}
-		}
-
-		// RRF 점수 계산
-		for chunkID := range chunkInfoMap {
-			rrfScore := 0.0
-			if rank, ok := vectorRanks[chunkID]; ok {
-				rrfScore += 1.0 / float64(rrfK+rank)
-			}
-			if rank, ok := keywordRanks[chunkID]; ok {
-				rrfScore += 1.0 / float64(rrfK+rank)
-			}
-			rrfScores[chunkID] = rrfScore
-		}
-
-		// 슬라이스로 변환하고 RRF 점수순으로 정렬
-		deduplicatedChunks = make([]*types.IndexWithScore, 0, len(chunkInfoMap))
-		for chunkID, info := range chunkInfoMap {
-			// 다운스트림 처리를 위해 Score 필드에 RRF 점수 저장
-			info.Score = rrfScores[chunkID]
-			deduplicatedChunks = append(deduplicatedChunks, info)
-		}
-		slices.SortFunc(deduplicatedChunks, func(a, b *types.IndexWithScore) int {
-			if a.Score > b.Score {
-				return -1
-			} else if a.Score < b.Score {
-				return 1
-			}
-			return 0
-		})
-
-		logger.Infof(ctx, "Result count after RRF fusion: %d", len(deduplicatedChunks))
+	deduplicatedChunks, err := retriever.FuseResults(retrieveResults, fusionConfig)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to fuse retrieval results: %v", err)
+		return nil, err
+	}
+	logger.Infof(ctx, "Result count after %s fusion: %d",
+		fusionConfig.WithDefaults().Method, len(deduplicatedChunks))
 
-		// 디버깅을 위해 RRF 퓨전 후 상위 결과 로깅
-		for i, chunk := range deduplicatedChunks {
-			if i < 15 {
-				vRank, vOk := vectorRanks[chunk.ChunkID]
-				kRank, kOk := keywordRanks[chunk.ChunkID]
-				logger.Debugf(ctx, "RRF rank %d: chunk_id=%s, rrf_score=%.6f, vector_rank=%v(%v), keyword_rank=%v(%v)",
-					i, chunk.ChunkID, chunk.Score, vRank, vOk, kRank, kOk)
-			}
+	// 디버깅을 위해 융합 후 상위 결과 로깅
+	for i, chunk := range deduplicatedChunks {
+		if i >= 15 {
+			break
 		}
+		logger.Debugf(ctx, "Fusion rank %d: chunk_id=%s, score=%.6f, match_type=%v",
+			i, chunk.ChunkID, chunk.Score, chunk.MatchType)
 	}
 
 	kb.EnsureDefaults()
@@ -722,7 +677,7 @@ func (s *knowledgeBaseService) HybridSearch(ctx context.Context,
 	// 개별 인덱싱이 있는 FAQ에 대해 반복 검색이 필요한지 확인
 	// 첫 번째 중복 제거 후 고유 청크가 충분하지 않은 경우에만 반복 검색 사용
 	needsIterativeRetrieval := len(deduplicatedChunks) < params.MatchCount &&
-		kb.Type == types.KnowledgeBaseTypeFAQ && len(vectorResults) == matchCount
+		kb.Type == types.KnowledgeBaseTypeFAQ && vectorCount == matchCount
 	if needsIterativeRetrieval {
 		logger.Info(ctx, "Not enough unique chunks, using iterative retrieval for FAQ")
 		// 반복 검색을 사용하여 더 많은 고유 청크 확보 (내부에서 부정 질문 필터링 수행)
@@ -739,12 +694,55 @@ func (s *knowledgeBaseService) HybridSearch(ctx context.Context,
 		logger.Infof(ctx, "Result count after negative question filtering: %d", len(deduplicatedChunks))
 	}
 
+	// 특정 시점 검색에서는 해당 시점에 유효하지 않았던 청크 제외
+	if asOf != nil {
+		deduplicatedChunks, err = s.filterChunksValidAt(ctx, tenantInfo.ID, deduplicatedChunks, *asOf)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof(ctx, "Result count after point-in-time filtering: %d", len(deduplicatedChunks))
+	}
+
 	// MatchCount로 제한
 	if len(deduplicatedChunks) > params.MatchCount {
 		deduplicatedChunks = deduplicatedChunks[:params.MatchCount]
 	}
 
-	return s.processSearchResults(ctx, deduplicatedChunks)
+	return s.processSearchResults(ctx, deduplicatedChunks, asOf)
+}
+
+// filterChunksValidAt 지정된 시점에 유효했던 청크의 검색 결과만 남김
+func (s *knowledgeBaseService) filterChunksValidAt(ctx context.Context,
+	tenantID uint64, chunks []*types.IndexWithScore, asOf time.Time,
+) ([]*types.IndexWithScore, error) {
+	if len(chunks) == 0 {
+		return chunks, nil
+	}
+	chunkIDs := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		chunkIDs = append(chunkIDs, chunk.ChunkID)
+	}
+	validChunks, err := s.chunkRepo.ListChunksByIDAsOf(ctx, tenantID, chunkIDs, asOf)
+	if err != nil {
+		return nil, err
+	}
+	valid := make(map[string]bool, len(validChunks))
+	for _, chunk := range validChunks {
+		valid[chunk.ID] = true
+	}
+	return slices.DeleteFunc(chunks, func(chunk *types.IndexWithScore) bool {
+		return !valid[chunk.ChunkID]
+	}), nil
+}
+
+// listChunksAsOf 청크 일괄 조회, 시점이 지정되면 해당 시점에 유효했던 청크를 조회
+func (s *knowledgeBaseService) listChunksAsOf(ctx context.Context,
+	tenantID uint64, chunkIDs []string, asOf *time.Time,
+) ([]*types.Chunk, error) {
+	if asOf == nil {
+		return s.chunkRepo.ListChunksByID(ctx, tenantID, chunkIDs)
+	}
+	return s.chunkRepo.ListChunksByIDAsOf(ctx, tenantID, chunkIDs, *asOf)
 }
 
 // iterativeRetrieveWithDeduplication 충분한 고유 청크가 발견될 때까지 반복 검색 수행
@@ -985,7 +983,7 @@ func (s *knowledgeBaseService) matchesNegativeQuestions(queryTextLower string, n
 
 // processSearchResults 검색 결과 처리, 데이터베이스 쿼리 최적화
 func (s *knowledgeBaseService) processSearchResults(ctx context.Context,
-	chunks []*types.IndexWithScore,
+	chunks []*types.IndexWithScore, asOf *time.Time,
 ) ([]*types.SearchResult, error) {
 	if len(chunks) == 0 {
 		return nil, nil
@@ -1021,7 +1019,7 @@ func (s *knowledgeBaseService) processSearchResults(ctx context.Context,
 
 	// 모든 청크 일괄 가져오기
 	logger.Infof(ctx, "Fetching chunk data for %d IDs", len(chunkIDs))
-	allChunks, err := s.chunkRepo.ListChunksByID(ctx, tenantID, chunkIDs)
+	allChunks, err := s.listChunksAsOf(ctx, tenantID, chunkIDs, asOf)
 	if err != nil {
 		logger.ErrorWithFields(ctx, err, map[string]interface{}{
 			"tenant_id": tenantID,
@@ -1076,7 +1074,7 @@ func (s *knowledgeBaseService) processSearchResults(ctx context.Context,
 	// 필요한 경우 모든 추가 청크 일괄 가져오기
 	if len(additionalChunkIDs) > 0 {
 		logger.Infof(ctx, "Fetching %d additional chunks", len(additionalChunkIDs))
-		additionalChunks, err := s.chunkRepo.ListChunksByID(ctx, tenantID, additionalChunkIDs)
+		additionalChunks, err := s.listChunksAsOf(ctx, tenantID, additionalChunkIDs, asOf)
 		if err != nil {
 			logger.Warnf(ctx, "Failed to fetch some additional chunks: %v", err)
 			// 있는 것으로 계속 진행
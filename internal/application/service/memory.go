@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+
+	"github.com/Tencent/WeKnora/internal/application/repository"
+	"github.com/Tencent/WeKnora/internal/application/service/memory"
+	"github.com/Tencent/WeKnora/internal/config"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/models/chat"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// memoryService implements the MemoryService interface
+type memoryService struct {
+	repo         interfaces.MemoryRepository
+	messageRepo  interfaces.MessageRepository
+	modelService interfaces.ModelService
+	task         *asynq.Client
+	cfg          *config.Config
+}
+
+// NewMemoryService creates a new long-term user memory service
+func NewMemoryService(
+	repo interfaces.MemoryRepository,
+	messageRepo interfaces.MessageRepository,
+	modelService interfaces.ModelService,
+	task *asynq.Client,
+	cfg *config.Config,
+) interfaces.MemoryService {
+	return &memoryService{
+		repo:         repo,
+		messageRepo:  messageRepo,
+		modelService: modelService,
+		task:         task,
+		cfg:          cfg,
+	}
+}
+
+// enabled reports whether memories are extracted and injected
+func (s *memoryService) enabled() bool {
+	return s.cfg != nil && s.cfg.Memory != nil && s.cfg.Memory.Enabled
+}
+
+// topK returns the configured number of memories injected into a prompt
+func (s *memoryService) topK() int {
+	if s.cfg != nil && s.cfg.Memory != nil && s.cfg.Memory.TopK > 0 {
+		return s.cfg.Memory.TopK
+	}
+	return memory.DefaultTopK
+}
+
+// minScore returns the configured lowest similarity of an injected memory
+func (s *memoryService) minScore() float64 {
+	if s.cfg != nil && s.cfg.Memory != nil && s.cfg.Memory.MinScore > 0 {
+		return s.cfg.Memory.MinScore
+	}
+	return memory.DefaultMinScore
+}
+
+// maxPerUser returns the configured number of memories kept per user
+func (s *memoryService) maxPerUser() int {
+	if s.cfg != nil && s.cfg.Memory != nil && s.cfg.Memory.MaxPerUser > 0 {
+		return s.cfg.Memory.MaxPerUser
+	}
+	return memory.DefaultMaxPerUser
+}
+
+// owner returns the tenant and the user in the context, or an empty user ID for API key requests
+func (s *memoryService) owner(ctx context.Context) (uint64, string) {
+	tenantID, _ := ctx.Value(types.TenantIDContextKey).(uint64)
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil {
+		return tenantID, subject.UserID
+	}
+	return tenantID, ""
+}
+
+// requireOwner returns the tenant and the user in the context, memories belong to signed-in users only
+func (s *memoryService) requireOwner(ctx context.Context) (uint64, string, error) {
+	tenantID, userID := s.owner(ctx)
+	if userID == "" {
+		return 0, "", werrors.NewForbiddenError("사용자 기억은 로그인한 사용자만 사용할 수 있습니다")
+	}
+	return tenantID, userID, nil
+}
+
+// ListMemories lists the memories of the user in the context, most recently updated first
+func (s *memoryService) ListMemories(ctx context.Context,
+	filter *types.UserMemoryFilter, page *types.Pagination,
+) (*types.PageResult, error) {
+	tenantID, userID, err := s.requireOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil && filter.Category != "" && !filter.Category.IsValid() {
+		return nil, werrors.NewValidationError("지원하지 않는 기억 유형입니다")
+	}
+	memories, total, err := s.repo.List(ctx, tenantID, userID, filter, page)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewPageResult(total, page, memories), nil
+}
+
+// GetMemory gets a memory of the user in the context
+func (s *memoryService) GetMemory(ctx context.Context, id string) (*types.UserMemory, error) {
+	tenantID, userID, err := s.requireOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.repo.Get(ctx, tenantID, userID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrMemoryNotFound) {
+			return nil, werrors.NewNotFoundError("기억을 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// UpdateMemory edits a memory of the user in the context and embeds the new content
+func (s *memoryService) UpdateMemory(ctx context.Context,
+	id string, req *types.UpdateUserMemoryRequest,
+) (*types.UserMemory, error) {
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, werrors.NewValidationError("기억 내용은 비어 있을 수 없습니다")
+	}
+	if len([]rune(content)) > memory.MaxContentLength {
+		return nil, werrors.NewValidationError(
+			fmt.Sprintf("기억 내용은 %d자를 넘을 수 없습니다", memory.MaxContentLength))
+	}
+	if req.Category != "" && !req.Category.IsValid() {
+		return nil, werrors.NewValidationError("지원하지 않는 기억 유형입니다")
+	}
+
+	m, err := s.GetMemory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	m.Content = content
+	if req.Category != "" {
+		m.Category = req.Category
+	}
+	if embedding, modelID, err := s.embed(ctx, content); err != nil {
+		// The edit is kept, the memory is not recalled until it is embedded again by a later edit or extraction
+		logger.Warnf(ctx, "Failed to embed memory %s: %v", id, err)
+		m.Embedding, m.EmbeddingModelID = nil, ""
+	} else {
+		m.Embedding, m.EmbeddingModelID = embedding, modelID
+	}
+	m.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DeleteMemory deletes a memory of the user in the context
+func (s *memoryService) DeleteMemory(ctx context.Context, id string) error {
+	tenantID, userID, err := s.requireOwner(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, tenantID, userID, id); err != nil {
+		if errors.Is(err, repository.ErrMemoryNotFound) {
+			return werrors.NewNotFoundError("기억을 찾을 수 없습니다")
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteAllMemories deletes every memory of the user in the context and returns how many were deleted
+func (s *memoryService) DeleteAllMemories(ctx context.Context) (int64, error) {
+	tenantID, userID, err := s.requireOwner(ctx)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := s.repo.DeleteAll(ctx, tenantID, userID)
+	if err != nil {
+		return 0, err
+	}
+	logger.Infof(ctx, "Deleted %d memories of user %s", deleted, userID)
+	return deleted, nil
+}
+
+// RecallMemories renders the memories of the user in the context that are relevant to the query.
+// Recall never fails the caller, errors leave the placeholder empty.
+func (s *memoryService) RecallMemories(ctx context.Context, query string) string {
+	if !s.enabled() {
+		return ""
+	}
+	tenantID, userID := s.owner(ctx)
+	if userID == "" {
+		return ""
+	}
+	memories, err := s.repo.ListAll(ctx, tenantID, userID)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to list memories of user %s: %v", userID, err)
+		return ""
+	}
+	if len(memories) == 0 {
+		return ""
+	}
+	embedding, modelID, err := s.embed(ctx, query)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to embed query for memory recall: %v", err)
+		return ""
+	}
+	recalled := memory.Rank(memories, embedding, modelID, s.topK(), s.minScore())
+	logger.Infof(ctx, "Recalled %d of %d memories of user %s", len(recalled), len(memories), userID)
+	return memory.Format(recalled)
+}
+
+// ScheduleExtraction enqueues the extraction of memories from a completed answer of the user in the context
+func (s *memoryService) ScheduleExtraction(ctx context.Context, message *types.Message) {
+	if !s.enabled() || message == nil || message.Role != "assistant" || strings.TrimSpace(message.Content) == "" {
+		return
+	}
+	// API key requests have no user to remember anything about
+	tenantID, userID := s.owner(ctx)
+	if userID == "" {
+		return
+	}
+	payload, err := json.Marshal(types.UserMemoryExtractPayload{
+		TenantID:  tenantID,
+		UserID:    userID,
+		SessionID: message.SessionID,
+		MessageID: message.ID,
+	})
+	if err != nil {
+		logger.Warnf(ctx, "Failed to marshal memory extract payload: %v", err)
+		return
+	}
+	task := asynq.NewTask(types.TypeMemoryExtract, payload, asynq.Queue("low"), asynq.MaxRetry(2))
+	if _, err := s.task.Enqueue(task); err != nil {
+		logger.Warnf(ctx, "Failed to enqueue memory extraction of message %s: %v", message.ID, err)
+	}
+}
+
+// ProcessMemoryExtract extracts memories from an answer and the question it answers
+func (s *memoryService) ProcessMemoryExtract(ctx context.Context, t *asynq.Task) error {
+	var payload types.UserMemoryExtractPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		logger.Errorf(ctx, "failed to unmarshal memory extract task payload: %v", err)
+		return nil
+	}
+	ctx = context.WithValue(ctx, types.TenantIDContextKey, payload.TenantID)
+	ctx = types.WithUsageScope(ctx, types.UsageScope{SessionID: payload.SessionID})
+	if !s.enabled() {
+		return nil
+	}
+
+	answer, err := s.messageRepo.GetMessage(ctx, payload.SessionID, payload.MessageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	question, err := s.findQuestion(ctx, answer)
+	if err != nil {
+		return err
+	}
+	if question == "" {
+		logger.Infof(ctx, "No question found for message %s, skipping memory extraction", answer.ID)
+		return nil
+	}
+
+	chatModelID := s.defaultModelID(ctx, types.ModelTypeKnowledgeQA)
+	if chatModelID == "" {
+		logger.Warnf(ctx, "No chat model found, skipping memory extraction")
+		return nil
+	}
+	chatModel, err := s.modelService.GetChatModel(ctx, chatModelID)
+	if err != nil {
+		return err
+	}
+	known, err := s.repo.ListAll(ctx, payload.TenantID, payload.UserID)
+	if err != nil {
+		return err
+	}
+
+	thinking := false
+	resp, err := chatModel.Chat(ctx, []chat.Message{
+		{Role: "system", Content: memory.ExtractPrompt},
+		{Role: "user", Content: memory.ExtractMessage(known, question, answer.Content)},
+	}, &chat.ChatOptions{Temperature: 0.1, Thinking: &thinking})
+	if err != nil {
+		return fmt.Errorf("failed to extract memories: %w", err)
+	}
+	ops, err := memory.ParseOperations(resp.Content, memory.KnownCount(known))
+	if err != nil {
+		logger.Warnf(ctx, "Discarding memory extraction of message %s: %v", answer.ID, err)
+		return nil
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := s.applyOperations(ctx, &payload, known, ops); err != nil {
+		return err
+	}
+
+	pruned, err := s.repo.Prune(ctx, payload.TenantID, payload.UserID, s.maxPerUser())
+	if err != nil {
+		logger.Warnf(ctx, "Failed to prune memories of user %s: %v", payload.UserID, err)
+	} else if pruned > 0 {
+		logger.Infof(ctx, "Pruned %d memories of user %s", pruned, payload.UserID)
+	}
+	return nil
+}
+
+// findQuestion returns the user message the answer replies to, an empty string when there is none
+func (s *memoryService) findQuestion(ctx context.Context, answer *types.Message) (string, error) {
+	// The question is stored right before the answer and shares its request ID
+	messages, err := s.messageRepo.GetMessagesBySessionBeforeTime(
+		ctx, answer.SessionID, answer.CreatedAt.Add(time.Second), 4)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range messages {
+		if m.Role == "user" && m.RequestID == answer.RequestID {
+			return m.Content, nil
+		}
+	}
+	return "", nil
+}
+
+// applyOperations applies the extracted operations to the known memories of a user.
+// An added memory close enough to an existing one replaces it instead of being duplicated.
+func (s *memoryService) applyOperations(ctx context.Context,
+	payload *types.UserMemoryExtractPayload, known []*types.UserMemory, ops []memory.Operation,
+) error {
+	deleted := make(map[string]bool)
+	live := func() []*types.UserMemory {
+		result := make([]*types.UserMemory, 0, len(known))
+		for _, m := range known {
+			if !deleted[m.ID] {
+				result = append(result, m)
+			}
+		}
+		return result
+	}
+
+	var added, updated, removed int
+	for _, op := range ops {
+		if op.Action == memory.ActionDelete {
+			target := known[op.Index-1]
+			if deleted[target.ID] {
+				continue
+			}
+			if err := s.repo.Delete(ctx, payload.TenantID, payload.UserID, target.ID); err != nil &&
+				!errors.Is(err, repository.ErrMemoryNotFound) {
+				return err
+			}
+			deleted[target.ID] = true
+			removed++
+			continue
+		}
+
+		embedding, modelID, err := s.embed(ctx, op.Content)
+		if err != nil {
+			return err
+		}
+		var target *types.UserMemory
+		if op.Action == memory.ActionUpdate && !deleted[known[op.Index-1].ID] {
+			target = known[op.Index-1]
+		} else {
+			target = memory.FindDuplicate(live(), embedding, modelID)
+		}
+
+		if target == nil {
+			m := &types.UserMemory{
+				TenantID:         payload.TenantID,
+				UserID:           payload.UserID,
+				Category:         op.Category,
+				Content:          op.Content,
+				Embedding:        embedding,
+				EmbeddingModelID: modelID,
+				SourceSessionID:  payload.SessionID,
+				SourceMessageID:  payload.MessageID,
+			}
+			if err := s.repo.Create(ctx, m); err != nil {
+				return err
+			}
+			// Appended after the ones shown to the model, so indexes of later operations stay valid
+			known = append(known, m)
+			added++
+			continue
+		}
+		target.Category = op.Category
+		target.Content = op.Content
+		target.Embedding, target.EmbeddingModelID = embedding, modelID
+		target.SourceSessionID, target.SourceMessageID = payload.SessionID, payload.MessageID
+		target.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, target); err != nil {
+			return err
+		}
+		updated++
+	}
+	logger.Infof(ctx, "Memories of user %s extracted from message %s: %d added, %d updated, %d deleted",
+		payload.UserID, payload.MessageID, added, updated, removed)
+	return nil
+}
+
+// embed embeds text with the default embedding model and returns the embedding and the model ID
+func (s *memoryService) embed(ctx context.Context, text string) ([]float32, string, error) {
+	modelID := s.defaultModelID(ctx, types.ModelTypeEmbedding)
+	if modelID == "" {
+		return nil, "", errors.New("no embedding model found")
+	}
+	embedder, err := s.modelService.GetEmbeddingModel(ctx, modelID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get embedding model: %w", err)
+	}
+	embedding, err := embedder.Embed(ctx, text)
+	if err != nil {
+		return nil, "", err
+	}
+	return embedding, modelID, nil
+}
+
+// defaultModelID returns the ID of the first model of the given type, or an empty string if there is none
+func (s *memoryService) defaultModelID(ctx context.Context, modelType types.ModelType) string {
+	models, err := s.modelService.ListModels(ctx)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to list models: %v", err)
+		return ""
+	}
+	for _, model := range models {
+		if model != nil && model.Type == modelType {
+			return model.ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,243 @@
+// Package memory extracts long-term user memories from conversations and selects the ones injected into prompts.
+//
+// After an answer completes, the chat model reads the exchange together with the memories already known and
+// returns operations adding, updating or deleting memories. Memories are embedded, and the ones most similar
+// to a new question are rendered into the {{user_memories}} placeholder of the system prompt.
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+const (
+	// DefaultTopK is the number of memories injected into a prompt when none is configured
+	DefaultTopK = 5
+	// DefaultMinScore is the lowest similarity of an injected memory when none is configured
+	DefaultMinScore = 0.3
+	// DefaultMaxPerUser is the number of memories kept per user when none is configured
+	DefaultMaxPerUser = 200
+	// DuplicateScore is the similarity above which a new memory replaces an existing one instead of being added
+	DuplicateScore = 0.92
+	// MaxContentLength is the maximum length of a memory in characters
+	MaxContentLength = 1000
+	// maxKnownMemories is the number of known memories shown to the model during extraction
+	maxKnownMemories = 50
+	// maxExchangeLength is the length in characters the question and the answer are truncated to during extraction
+	maxExchangeLength = 4000
+)
+
+const (
+	// Placeholder is the prompt placeholder the memories are rendered into
+	Placeholder = "{{user_memories}}"
+	// None is rendered into the placeholder when there is no memory to inject
+	None = "None"
+)
+
+// Action is the change an extraction operation makes
+type Action string
+
+const (
+	ActionAdd    Action = "add"    // Remember a new fact or preference
+	ActionUpdate Action = "update" // Replace a known memory that changed
+	ActionDelete Action = "delete" // Forget a known memory the user withdrew or contradicted
+)
+
+// Operation is a change to the memories of a user returned by the extraction
+type Operation struct {
+	Action   Action                   `json:"action"`
+	Index    int                      `json:"index,omitempty"` // 1-based index into the known memories
+	Category types.UserMemoryCategory `json:"category,omitempty"`
+	Content  string                   `json:"content,omitempty"`
+}
+
+// ExtractPrompt is the system prompt of the extraction
+const ExtractPrompt = `You maintain the long-term memory of an assistant about one user. Read the latest exchange between the user and the assistant and decide what is worth remembering in future, unrelated conversations.
+
+Remember only durable information the USER stated about themselves:
+- fact: who they are and what they work on, e.g. role, team, product, region, tech stack, constraints
+- preference: how they want answers, e.g. language, level of detail, format, units
+
+Do NOT remember:
+- The question itself, the topic of this conversation or anything only relevant to it
+- Information from the assistant's answer or retrieved documents
+- Guesses, one-off requests and temporary states
+- Secrets such as passwords, keys or tokens
+
+Compare with the known memories:
+- Skip information that is already known
+- Use "update" when the user changed a known memory, "delete" when the user withdrew or contradicted it
+
+Write each memory as one short, self-contained sentence in the language the user writes in, e.g. "Works on product X in the EU region".
+
+Output ONLY a JSON array, without explanations, e.g.:
+[{"action": "add", "category": "fact", "content": "..."},
+ {"action": "update", "index": 2, "category": "preference", "content": "..."},
+ {"action": "delete", "index": 3}]
+Output [] when there is nothing to change.`
+
+// thinkPattern matches the reasoning of thinking models, which may contain brackets
+var thinkPattern = regexp.MustCompile(`(?s)<think>.*?</think>`)
+
+// ExtractMessage builds the user message of the extraction from the known memories and the exchange
+func ExtractMessage(known []*types.UserMemory, question, answer string) string {
+	var b strings.Builder
+	b.WriteString("## Known memories\n")
+	if len(known) == 0 {
+		b.WriteString(None + "\n")
+	}
+	for i, m := range known {
+		if i >= maxKnownMemories {
+			break
+		}
+		fmt.Fprintf(&b, "%d. [%s] %s\n", i+1, m.Category, m.Content)
+	}
+	b.WriteString("\n## Latest exchange\n")
+	fmt.Fprintf(&b, "User: %s\n\n", truncate(question, maxExchangeLength))
+	fmt.Fprintf(&b, "Assistant: %s\n", truncate(answer, maxExchangeLength))
+	return b.String()
+}
+
+// KnownCount returns the number of known memories ExtractMessage shows to the model
+func KnownCount(known []*types.UserMemory) int {
+	return min(len(known), maxKnownMemories)
+}
+
+// ParseOperations parses the extraction output. Operations that are malformed or refer to a memory
+// outside the known ones are dropped, known is the number of known memories shown to the model.
+func ParseOperations(output string, known int) ([]Operation, error) {
+	output = thinkPattern.ReplaceAllString(output, "")
+	start, end := strings.Index(output, "["), strings.LastIndex(output, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON array in extraction output")
+	}
+	var raw []Operation
+	if err := json.Unmarshal([]byte(output[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("invalid extraction output: %w", err)
+	}
+
+	ops := make([]Operation, 0, len(raw))
+	for _, op := range raw {
+		op.Action = Action(strings.ToLower(strings.TrimSpace(string(op.Action))))
+		op.Content = strings.TrimSpace(op.Content)
+		if !op.Category.IsValid() {
+			op.Category = types.UserMemoryFact
+		}
+		switch op.Action {
+		case ActionAdd:
+			op.Index = 0
+		case ActionUpdate, ActionDelete:
+			if op.Index < 1 || op.Index > known {
+				continue
+			}
+		default:
+			continue
+		}
+		if op.Action != ActionDelete && (op.Content == "" || len([]rune(op.Content)) > MaxContentLength) {
+			continue
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// Similarity returns the cosine similarity of two embeddings, 0 when they cannot be compared
+func Similarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// score returns the similarity of a memory to an embedding made by the given model
+func score(m *types.UserMemory, embedding []float32, modelID string) float64 {
+	if m.EmbeddingModelID != modelID {
+		return 0
+	}
+	return Similarity(m.Embedding, embedding)
+}
+
+// Rank returns at most topK memories whose similarity to the query embedding is at least minScore,
+// most similar first. Memories embedded by another model are never selected.
+func Rank(memories []*types.UserMemory,
+	query []float32, modelID string, topK int, minScore float64,
+) []*types.UserMemory {
+	type scored struct {
+		memory *types.UserMemory
+		score  float64
+	}
+	candidates := make([]scored, 0, len(memories))
+	for _, m := range memories {
+		if s := score(m, query, modelID); s >= minScore && s > 0 {
+			candidates = append(candidates, scored{memory: m, score: s})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].memory.UpdatedAt.After(candidates[j].memory.UpdatedAt)
+	})
+
+	ranked := make([]*types.UserMemory, 0, min(topK, len(candidates)))
+	for i := 0; i < len(candidates) && i < topK; i++ {
+		ranked = append(ranked, candidates[i].memory)
+	}
+	return ranked
+}
+
+// FindDuplicate returns the memory most similar to the embedding if it is similar enough to be the same memory
+func FindDuplicate(memories []*types.UserMemory, embedding []float32, modelID string) *types.UserMemory {
+	var best *types.UserMemory
+	bestScore := DuplicateScore
+	for _, m := range memories {
+		if s := score(m, embedding, modelID); s >= bestScore {
+			best, bestScore = m, s
+		}
+	}
+	return best
+}
+
+// Format renders memories into the placeholder, one per line. It returns an empty string for no memories.
+func Format(memories []*types.UserMemory) string {
+	lines := make([]string, 0, len(memories))
+	for _, m := range memories {
+		lines = append(lines, fmt.Sprintf("- [%s] %s", m.Category, m.Content))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Render replaces the placeholder in a prompt with the formatted memories, or with None when there are none
+func Render(prompt, memories string) string {
+	if !strings.Contains(prompt, Placeholder) {
+		return prompt
+	}
+	if memories == "" {
+		memories = None
+	}
+	return strings.ReplaceAll(prompt, Placeholder, memories)
+}
+
+// truncate shortens text to at most limit characters
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "..."
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+func TestParseOperations(t *testing.T) {
+	output := "<think>maybe [1] is outdated</think>\n```json\n[" +
+		`{"action": "add", "category": "preference", "content": " Prefers answers in English "},` +
+		`{"action": "ADD", "category": "unknown", "content": "Works on product X"},` +
+		`{"action": "update", "index": 2, "content": "Works in the EU region"},` +
+		`{"action": "delete", "index": 1},` +
+		`{"action": "update", "index": 3, "content": "out of range"},` +
+		`{"action": "add", "content": ""},` +
+		`{"action": "merge", "index": 1}` +
+		"]\n```"
+
+	ops, err := ParseOperations(output, 2)
+	if err != nil {
+		t.Fatalf("ParseOperations error = %v", err)
+	}
+	want := []Operation{
+		{Action: ActionAdd, Category: types.UserMemoryPreference, Content: "Prefers answers in English"},
+		{Action: ActionAdd, Category: types.UserMemoryFact, Content: "Works on product X"},
+		{Action: ActionUpdate, Index: 2, Category: types.UserMemoryFact, Content: "Works in the EU region"},
+		{Action: ActionDelete, Index: 1, Category: types.UserMemoryFact},
+	}
+	if len(ops) != len(want) {
+		t.Fatalf("ParseOperations = %+v, want %+v", ops, want)
+	}
+	for i := range want {
+		if ops[i] != want[i] {
+			t.Errorf("operation %d = %+v, want %+v", i, ops[i], want[i])
+		}
+	}
+
+	if ops, err := ParseOperations("[]", 0); err != nil || len(ops) != 0 {
+		t.Errorf("empty output = %+v, %v", ops, err)
+	}
+	if _, err := ParseOperations("nothing to remember", 0); err == nil {
+		t.Error("expected an error for output without a JSON array")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	if got := Similarity([]float32{1, 0}, []float32{1, 0}); math.Abs(got-1) > 1e-9 {
+		t.Errorf("identical vectors = %v, want 1", got)
+	}
+	if got := Similarity([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Errorf("orthogonal vectors = %v, want 0", got)
+	}
+	if got := Similarity([]float32{1, 0}, []float32{1, 0, 0}); got != 0 {
+		t.Errorf("different dimensions = %v, want 0", got)
+	}
+	if got := Similarity([]float32{0, 0}, []float32{1, 0}); got != 0 {
+		t.Errorf("zero vector = %v, want 0", got)
+	}
+}
+
+func TestRank(t *testing.T) {
+	now := time.Now()
+	memories := []*types.UserMemory{
+		{ID: "far", Embedding: []float32{0, 1}, EmbeddingModelID: "m", UpdatedAt: now},
+		{ID: "near", Embedding: []float32{1, 0.1}, EmbeddingModelID: "m", UpdatedAt: now},
+		{ID: "other-model", Embedding: []float32{1, 0}, EmbeddingModelID: "other", UpdatedAt: now},
+		{ID: "same-old", Embedding: []float32{1, 0}, EmbeddingModelID: "m", UpdatedAt: now.Add(-time.Hour)},
+		{ID: "same-new", Embedding: []float32{1, 0}, EmbeddingModelID: "m", UpdatedAt: now},
+	}
+
+	ranked := Rank(memories, []float32{1, 0}, "m", 2, 0.5)
+	if len(ranked) != 2 || ranked[0].ID != "same-new" || ranked[1].ID != "same-old" {
+		t.Fatalf("Rank = %v, want same-new and same-old", ids(ranked))
+	}
+	ranked = Rank(memories, []float32{1, 0}, "m", 10, 0.5)
+	if len(ranked) != 3 || ranked[2].ID != "near" {
+		t.Fatalf("Rank = %v, want the far and other-model memories left out", ids(ranked))
+	}
+
+	if dup := FindDuplicate(memories, []float32{1, 0.05}, "m"); dup == nil || dup.ID == "far" {
+		t.Errorf("FindDuplicate = %v, want a memory pointing the same way", dup)
+	}
+	if dup := FindDuplicate(memories, []float32{1, 1}, "m"); dup != nil {
+		t.Errorf("FindDuplicate = %s, want none", dup.ID)
+	}
+}
+
+func TestRender(t *testing.T) {
+	memories := Format([]*types.UserMemory{
+		{Category: types.UserMemoryFact, Content: "Works on product X"},
+		{Category: types.UserMemoryPreference, Content: "Prefers short answers"},
+	})
+	got := Render("Memory:\n{{user_memories}}", memories)
+	want := "Memory:\n- [fact] Works on product X\n- [preference] Prefers short answers"
+	if got != want {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+	if got := Render("Memory: {{user_memories}}", ""); got != "Memory: None" {
+		t.Errorf("Render without memories = %q", got)
+	}
+}
+
+func ids(memories []*types.UserMemory) []string {
+	result := make([]string, 0, len(memories))
+	for _, m := range memories {
+		result = append(result, m.ID)
+	}
+	return result
+}
@@ -12,22 +12,30 @@ import (
 // messageService implements the MessageService interface for managing messaging operations
 // It handles creating, retrieving, updating, and deleting messages within sessions
 type messageService struct {
-	messageRepo interfaces.MessageRepository // Repository for message storage operations
-	sessionRepo interfaces.SessionRepository // Repository for session validation
+	messageRepo    interfaces.MessageRepository // Repository for message storage operations
+	sessionRepo    interfaces.SessionRepository // Repository for session validation
+	webhookService interfaces.WebhookService    // Service notifying tenant webhooks of completed answers
+	memoryService  interfaces.MemoryService     // Service extracting user memories from completed answers
 }
 
 // NewMessageService creates a new message service instance with the required repositories
 // Parameters:
 //   - messageRepo: Repository for persisting and retrieving messages
 //   - sessionRepo: Repository for validating session existence
+//   - webhookService: Service publishing message.completed events
+//   - memoryService: Service scheduling memory extraction from completed answers
 //
 // Returns an implementation of the MessageService interface
 func NewMessageService(messageRepo interfaces.MessageRepository,
 	sessionRepo interfaces.SessionRepository,
+	webhookService interfaces.WebhookService,
+	memoryService interfaces.MemoryService,
 ) interfaces.MessageService {
 	return &messageService{
-		messageRepo: messageRepo,
-		sessionRepo: sessionRepo,
+		messageRepo:    messageRepo,
+		sessionRepo:    sessionRepo,
+		webhookService: webhookService,
+		memoryService:  memoryService,
 	}
 }
 
@@ -236,6 +244,13 @@ func (s *messageService) UpdateMessage(ctx context.Context, message *types.Messa
 		return err
 	}
 
+	// An answer is reported once, when it is first stored as completed
+	completing := false
+	if message.Role == "assistant" && message.IsCompleted {
+		stored, err := s.messageRepo.GetMessage(ctx, message.SessionID, message.ID)
+		completing = err == nil && !stored.IsCompleted
+	}
+
 	// Update the message in the repository
 	logger.Info(ctx, "Session exists, updating message")
 	err = s.messageRepo.UpdateMessage(ctx, message)
@@ -247,6 +262,16 @@ func (s *messageService) UpdateMessage(ctx context.Context, message *types.Messa
 		return err
 	}
 
+	if completing {
+		s.webhookService.Publish(ctx, types.WebhookEventMessageCompleted, types.WebhookMessageData{
+			SessionID: message.SessionID,
+			MessageID: message.ID,
+			RequestID: message.RequestID,
+			Content:   message.Content,
+		})
+		s.memoryService.ScheduleExtraction(ctx, message)
+	}
+
 	logger.Info(ctx, "Message updated successfully")
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/Tencent/WeKnora/internal/application/service/usage"
 	"github.com/Tencent/WeKnora/internal/logger"
 	"github.com/Tencent/WeKnora/internal/models/chat"
 	"github.com/Tencent/WeKnora/internal/models/embedding"
@@ -20,16 +21,35 @@ var ErrModelNotFound = errors.New("model not found")
 type modelService struct {
 	repo          interfaces.ModelRepository
 	ollamaService *ollama.OllamaService
+	usageService  interfaces.UsageService
+	quotaService  interfaces.QuotaService
 }
 
-// NewModelService creates a new model service instance
-func NewModelService(repo interfaces.ModelRepository, ollamaService *ollama.OllamaService) interfaces.ModelService {
+// NewModelService creates a new model service instance.
+// The models it creates record the usage of each call through the usage service,
+// and count its tokens against the daily token quotas.
+func NewModelService(repo interfaces.ModelRepository,
+	ollamaService *ollama.OllamaService, usageService interfaces.UsageService, quotaService interfaces.QuotaService,
+) interfaces.ModelService {
 	return &modelService{
 		repo:          repo,
 		ollamaService: ollamaService,
+		usageService:  usageService,
+		quotaService:  quotaService,
 	}
 }
 
+// usageModelInfo identifies a model in its usage records
+func usageModelInfo(model *types.Model) usage.ModelInfo {
+	return usage.ModelInfo{ID: model.ID, Name: model.Name, Type: model.Type}
+}
+
+// recordUsage records the usage of a model call and adds its tokens to the daily token quotas
+func (s *modelService) recordUsage(ctx context.Context, record *types.UsageRecord) {
+	s.usageService.RecordUsage(ctx, record)
+	s.quotaService.ConsumeTokens(ctx, int64(record.TotalTokens))
+}
+
 // CreateModel creates a new model in the repository
 // For local models, it initiates an asynchronous download process
 // Remote models are immediately set to active status
@@ -263,7 +283,7 @@ func (s *modelService) GetEmbeddingModel(ctx context.Context, modelId string) (e
 	}
 
 	logger.Info(ctx, "Embedding model initialized successfully")
-	return embedder, nil
+	return usage.MeterEmbedder(embedder, usageModelInfo(model), s.recordUsage), nil
 }
 
 // GetRerankModel retrieves and initializes a reranking model instance
@@ -297,7 +317,7 @@ func (s *modelService) GetRerankModel(ctx context.Context, modelId string) (rera
 	}
 
 	logger.Info(ctx, "Rerank model initialized successfully")
-	return reranker, nil
+	return usage.MeterReranker(reranker, usageModelInfo(model), s.recordUsage), nil
 }
 
 // GetChatModel retrieves and initializes a chat model instance
@@ -344,7 +364,7 @@ func (s *modelService) GetChatModel(ctx context.Context, modelId string) (chat.C
 		return nil, err
 	}
 
-	return chatModel, nil
+	return usage.MeterChat(chatModel, usageModelInfo(model), s.recordUsage), nil
 }
 
 // Note: default model selection logic has been removed; models no longer
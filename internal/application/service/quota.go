@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Tencent/WeKnora/internal/application/repository"
+	"github.com/Tencent/WeKnora/internal/application/service/quota"
+	"github.com/Tencent/WeKnora/internal/config"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+const (
+	// quotaKeyPrefix prefixes the Redis keys of quota counters
+	quotaKeyPrefix = "quota:"
+	// quotaPolicyCacheTTL bounds how long a policy change takes to reach other server instances
+	quotaPolicyCacheTTL = 30 * time.Second
+)
+
+// cachedQuotaLimits is a cached policy lookup, custom is false if the subject has no policy
+type cachedQuotaLimits struct {
+	limits  types.QuotaLimits
+	custom  bool
+	expires time.Time
+}
+
+// quotaService implements the QuotaService interface
+type quotaService struct {
+	repo       interfaces.QuotaRepository
+	apiKeyRepo interfaces.APIKeyRepository
+	userRepo   interfaces.UserRepository
+	store      quota.Store
+	defaults   config.QuotaConfig
+
+	// policies caches policy lookups by tenant and subject, they are read on every request
+	policies sync.Map
+}
+
+// NewQuotaService creates a new quota service.
+// Counters are kept in Redis with an in-memory fallback, or only in memory if configured.
+func NewQuotaService(
+	repo interfaces.QuotaRepository,
+	apiKeyRepo interfaces.APIKeyRepository,
+	userRepo interfaces.UserRepository,
+	redisClient *redis.Client,
+	cfg *config.Config,
+) interfaces.QuotaService {
+	s := &quotaService{repo: repo, apiKeyRepo: apiKeyRepo, userRepo: userRepo}
+	if cfg.Quota != nil {
+		s.defaults = *cfg.Quota
+	}
+	if s.defaults.Store == "memory" || redisClient == nil {
+		s.store = quota.NewMemoryStore()
+	} else {
+		s.store = quota.NewFallbackStore(quota.NewRedisStore(redisClient, quotaKeyPrefix), quota.NewMemoryStore())
+	}
+	return s
+}
+
+// defaultLimits returns the configured limits of a subject type
+func (s *quotaService) defaultLimits(subjectType types.QuotaSubjectType) types.QuotaLimits {
+	switch subjectType {
+	case types.QuotaSubjectUser:
+		return s.defaults.User
+	case types.QuotaSubjectAPIKey:
+		return s.defaults.APIKey
+	default:
+		return s.defaults.Tenant
+	}
+}
+
+// policyCacheKey is the key of a subject in the policy cache
+func policyCacheKey(tenantID uint64, subjectType types.QuotaSubjectType, subjectID string) string {
+	return fmt.Sprintf("%d:%s:%s", tenantID, subjectType, subjectID)
+}
+
+// policySubjectID is the subject ID stored in a policy, tenant policies have none
+func policySubjectID(subject types.QuotaSubject) string {
+	if subject.Type == types.QuotaSubjectTenant {
+		return ""
+	}
+	return subject.ID
+}
+
+// limits returns the limits of a subject, its policy if it has one and the configured default otherwise.
+// A failed lookup falls back to the default, quotas must not make every request fail.
+func (s *quotaService) limits(ctx context.Context,
+	tenantID uint64, subject types.QuotaSubject,
+) (types.QuotaLimits, bool) {
+	subjectID := policySubjectID(subject)
+	key := policyCacheKey(tenantID, subject.Type, subjectID)
+	if cached, ok := s.policies.Load(key); ok {
+		entry := cached.(*cachedQuotaLimits)
+		if time.Now().Before(entry.expires) {
+			return entry.limits, entry.custom
+		}
+	}
+
+	entry := &cachedQuotaLimits{limits: s.defaultLimits(subject.Type), expires: time.Now().Add(quotaPolicyCacheTTL)}
+	policy, err := s.repo.GetQuotaPolicy(ctx, tenantID, subject.Type, subjectID)
+	switch {
+	case err == nil:
+		entry.limits = policy.QuotaLimits
+		entry.custom = true
+	case !errors.Is(err, repository.ErrQuotaPolicyNotFound):
+		logger.Warnf(ctx, "Failed to get quota policy of %s %s: %v", subject.Type, subject.ID, err)
+		return entry.limits, false
+	}
+	s.policies.Store(key, entry)
+	return entry.limits, entry.custom
+}
+
+// quotaTenantID returns the tenant in the context, zero if there is none
+func quotaTenantID(ctx context.Context) uint64 {
+	tenantID, _ := ctx.Value(types.TenantIDContextKey).(uint64)
+	return tenantID
+}
+
+// CheckRequest takes a request from the per-minute budgets of the subjects in the context
+func (s *quotaService) CheckRequest(ctx context.Context) error {
+	tenantID := quotaTenantID(ctx)
+	for _, subject := range quota.Subjects(ctx) {
+		limits, _ := s.limits(ctx, tenantID, subject)
+		if limits.RequestsPerMinute <= 0 {
+			continue
+		}
+		allowed, wait, err := s.store.Take(ctx, quota.RequestKey(tenantID, subject), limits.RequestsPerMinute)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to check request rate of %s %s: %v", subject.Type, subject.ID, err)
+			continue
+		}
+		if !allowed {
+			return &types.QuotaExceededError{
+				Subject: subject, Limit: types.QuotaLimitRequestsPerMinute, RetryAfter: wait,
+			}
+		}
+	}
+	return nil
+}
+
+// AcquireStream checks the daily token budgets and takes a concurrent stream slot of the subjects in the context
+func (s *quotaService) AcquireStream(ctx context.Context) (func(), error) {
+	tenantID := quotaTenantID(ctx)
+	subjects := quota.Subjects(ctx)
+	now := time.Now()
+
+	for _, subject := range subjects {
+		limits, _ := s.limits(ctx, tenantID, subject)
+		if limits.DailyTokens <= 0 {
+			continue
+		}
+		used, err := s.store.Get(ctx, quota.TokensKey(tenantID, subject, now))
+		if err != nil {
+			logger.Warnf(ctx, "Failed to get token usage of %s %s: %v", subject.Type, subject.ID, err)
+			continue
+		}
+		if used >= limits.DailyTokens {
+			return nil, &types.QuotaExceededError{
+				Subject: subject, Limit: types.QuotaLimitDailyTokens, RetryAfter: quota.UntilNextDay(now),
+			}
+		}
+	}
+
+	var acquired []string
+	release := func() {
+		// the stream may end because its request was cancelled, the slots are still released
+		releaseCtx := context.WithoutCancel(ctx)
+		for _, key := range acquired {
+			if err := s.store.Release(releaseCtx, key); err != nil {
+				logger.Warnf(releaseCtx, "Failed to release stream slot %s: %v", key, err)
+			}
+		}
+	}
+	for _, subject := range subjects {
+		limits, _ := s.limits(ctx, tenantID, subject)
+		if limits.ConcurrentStreams <= 0 {
+			continue
+		}
+		key := quota.StreamKey(tenantID, subject)
+		ok, err := s.store.Acquire(ctx, key, int64(limits.ConcurrentStreams), quota.StreamTTL)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to acquire stream slot of %s %s: %v", subject.Type, subject.ID, err)
+			continue
+		}
+		if !ok {
+			release()
+			return nil, &types.QuotaExceededError{
+				Subject: subject, Limit: types.QuotaLimitConcurrentStreams, RetryAfter: quota.StreamRetryAfter,
+			}
+		}
+		acquired = append(acquired, key)
+	}
+	return release, nil
+}
+
+// ConsumeIngestion adds uploaded bytes to the daily ingestion budgets of the subjects in the context.
+// The bytes are only added if every budget can take them.
+func (s *quotaService) ConsumeIngestion(ctx context.Context, bytes int64) error {
+	if bytes <= 0 {
+		return nil
+	}
+	tenantID := quotaTenantID(ctx)
+	subjects := quota.Subjects(ctx)
+	now := time.Now()
+
+	for _, subject := range subjects {
+		limits, _ := s.limits(ctx, tenantID, subject)
+		if limits.DailyIngestionBytes <= 0 {
+			continue
+		}
+		used, err := s.store.Get(ctx, quota.IngestionKey(tenantID, subject, now))
+		if err != nil {
+			logger.Warnf(ctx, "Failed to get ingestion usage of %s %s: %v", subject.Type, subject.ID, err)
+			continue
+		}
+		if used+bytes > limits.DailyIngestionBytes {
+			return &types.QuotaExceededError{
+				Subject: subject, Limit: types.QuotaLimitDailyIngestionBytes, RetryAfter: quota.UntilNextDay(now),
+			}
+		}
+	}
+	for _, subject := range subjects {
+		if _, err := s.store.Add(ctx, quota.IngestionKey(tenantID, subject, now), bytes, quota.DailyTTL); err != nil {
+			logger.Warnf(ctx, "Failed to add ingestion usage of %s %s: %v", subject.Type, subject.ID, err)
+		}
+	}
+	return nil
+}
+
+// ConsumeTokens adds model tokens to the daily token budgets of the subjects in the context
+func (s *quotaService) ConsumeTokens(ctx context.Context, tokens int64) {
+	if tokens <= 0 {
+		return
+	}
+	tenantID := quotaTenantID(ctx)
+	now := time.Now()
+	for _, subject := range quota.Subjects(ctx) {
+		if _, err := s.store.Add(ctx, quota.TokensKey(tenantID, subject, now), tokens, quota.DailyTTL); err != nil {
+			logger.Warnf(ctx, "Failed to add token usage of %s %s: %v", subject.Type, subject.ID, err)
+		}
+	}
+}
+
+// GetQuotaStatus returns the limits and usage of the subjects in the context
+func (s *quotaService) GetQuotaStatus(ctx context.Context) ([]*types.QuotaStatus, error) {
+	tenantID := quotaTenantID(ctx)
+	now := time.Now()
+	subjects := quota.Subjects(ctx)
+	statuses := make([]*types.QuotaStatus, 0, len(subjects))
+	for _, subject := range subjects {
+		limits, custom := s.limits(ctx, tenantID, subject)
+		status := &types.QuotaStatus{QuotaSubject: subject, Custom: custom, Limits: limits}
+		var err error
+		if status.Usage.ConcurrentStreams, err = s.store.Get(ctx, quota.StreamKey(tenantID, subject)); err != nil {
+			return nil, err
+		}
+		if status.Usage.DailyTokens, err = s.store.Get(ctx, quota.TokensKey(tenantID, subject, now)); err != nil {
+			return nil, err
+		}
+		if status.Usage.DailyIngestionBytes, err = s.store.Get(ctx, quota.IngestionKey(tenantID, subject, now)); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// ListQuotaPolicies lists the quota policies of the tenant in the context
+func (s *quotaService) ListQuotaPolicies(ctx context.Context) ([]*types.QuotaPolicy, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	policies, err := s.repo.ListQuotaPolicies(ctx, tenantID)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		return nil, err
+	}
+	return policies, nil
+}
+
+// validateQuotaSubject checks that the subject of a policy exists in the tenant and returns its policy subject ID
+func (s *quotaService) validateQuotaSubject(ctx context.Context,
+	tenantID uint64, subjectType types.QuotaSubjectType, subjectID string,
+) (string, error) {
+	switch subjectType {
+	case types.QuotaSubjectTenant:
+		return "", nil
+	case types.QuotaSubjectUser:
+		if subjectID == "" {
+			return "", werrors.NewBadRequestError("사용자 ID는 필수입니다")
+		}
+		if _, err := s.userRepo.GetUserByID(ctx, subjectID); err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return "", werrors.NewNotFoundError("사용자를 찾을 수 없습니다")
+			}
+			return "", err
+		}
+	case types.QuotaSubjectAPIKey:
+		if subjectID == "" {
+			return "", werrors.NewBadRequestError("API 키 ID는 필수입니다")
+		}
+		if _, err := s.apiKeyRepo.GetAPIKey(ctx, tenantID, subjectID); err != nil {
+			if errors.Is(err, repository.ErrAPIKeyNotFound) {
+				return "", werrors.NewNotFoundError("API 키를 찾을 수 없습니다")
+			}
+			return "", err
+		}
+	default:
+		return "", werrors.NewBadRequestError("올바르지 않은 할당량 대상 유형입니다: " + string(subjectType))
+	}
+	return subjectID, nil
+}
+
+// SetQuotaPolicy sets the limits of a subject of the tenant in the context
+func (s *quotaService) SetQuotaPolicy(ctx context.Context,
+	req *types.SetQuotaPolicyRequest,
+) (*types.QuotaPolicy, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	limits := req.QuotaLimits
+	if limits.RequestsPerMinute < 0 || limits.ConcurrentStreams < 0 ||
+		limits.DailyTokens < 0 || limits.DailyIngestionBytes < 0 {
+		return nil, werrors.NewBadRequestError("할당량은 0 이상이어야 합니다")
+	}
+	subjectID, err := s.validateQuotaSubject(ctx, tenantID, req.SubjectType, req.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &types.QuotaPolicy{
+		TenantID:    tenantID,
+		SubjectType: req.SubjectType,
+		SubjectID:   subjectID,
+		QuotaLimits: limits,
+	}
+	if err := s.repo.SaveQuotaPolicy(ctx, policy); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		return nil, err
+	}
+	s.policies.Delete(policyCacheKey(tenantID, req.SubjectType, subjectID))
+	logger.Infof(ctx, "Quota policy of %s %s in tenant %d set to %+v", req.SubjectType, subjectID, tenantID, limits)
+	return policy, nil
+}
+
+// DeleteQuotaPolicy deletes the policy of a subject, the default limits apply again
+func (s *quotaService) DeleteQuotaPolicy(ctx context.Context,
+	subjectType types.QuotaSubjectType, subjectID string,
+) error {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	if !subjectType.IsValid() {
+		return werrors.NewBadRequestError("올바르지 않은 할당량 대상 유형입니다: " + string(subjectType))
+	}
+	if subjectType == types.QuotaSubjectTenant {
+		subjectID = ""
+	}
+	if err := s.repo.DeleteQuotaPolicy(ctx, tenantID, subjectType, subjectID); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		return err
+	}
+	s.policies.Delete(policyCacheKey(tenantID, subjectType, subjectID))
+	logger.Infof(ctx, "Quota policy of %s %s in tenant %d deleted", subjectType, subjectID, tenantID)
+	return nil
+}
@@ -0,0 +1,73 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+const (
+	// StreamTTL bounds how long a concurrent stream slot is held if its release is lost, e.g. when a server crashes
+	StreamTTL = time.Hour
+	// StreamRetryAfter is suggested to clients waiting for a concurrent stream slot
+	StreamRetryAfter = 5 * time.Second
+	// DailyTTL keeps daily counters a day longer than their day, so that late additions to yesterday are kept
+	DailyTTL = 48 * time.Hour
+)
+
+// Subjects returns the subjects of a request in the context, the tenant first.
+// Requests made with a user token add the user, and requests made with a tenant API key add the key.
+func Subjects(ctx context.Context) []types.QuotaSubject {
+	tenantID, ok := ctx.Value(types.TenantIDContextKey).(uint64)
+	if !ok || tenantID == 0 {
+		return nil
+	}
+	subjects := []types.QuotaSubject{{Type: types.QuotaSubjectTenant, ID: strconv.FormatUint(tenantID, 10)}}
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil && subject.UserID != "" {
+		subjects = append(subjects, types.QuotaSubject{Type: types.QuotaSubjectUser, ID: subject.UserID})
+	}
+	if key, ok := ctx.Value(types.APIKeyContextKey).(*types.TenantAPIKey); ok && key != nil {
+		subjects = append(subjects, types.QuotaSubject{Type: types.QuotaSubjectAPIKey, ID: key.ID})
+	}
+	return subjects
+}
+
+// subjectKey identifies the counters of a subject in a tenant, users working in several tenants are counted per tenant
+func subjectKey(tenantID uint64, subject types.QuotaSubject) string {
+	return fmt.Sprintf("%d:%s:%s", tenantID, subject.Type, subject.ID)
+}
+
+// RequestKey is the key of the per-minute request bucket of a subject
+func RequestKey(tenantID uint64, subject types.QuotaSubject) string {
+	return "rpm:" + subjectKey(tenantID, subject)
+}
+
+// StreamKey is the key of the concurrent stream counter of a subject
+func StreamKey(tenantID uint64, subject types.QuotaSubject) string {
+	return "streams:" + subjectKey(tenantID, subject)
+}
+
+// TokensKey is the key of the daily token counter of a subject
+func TokensKey(tenantID uint64, subject types.QuotaSubject, now time.Time) string {
+	return fmt.Sprintf("tokens:%s:%s", subjectKey(tenantID, subject), now.UTC().Format("20060102"))
+}
+
+// IngestionKey is the key of the daily ingestion counter of a subject
+func IngestionKey(tenantID uint64, subject types.QuotaSubject, now time.Time) string {
+	return fmt.Sprintf("ingestion:%s:%s", subjectKey(tenantID, subject), now.UTC().Format("20060102"))
+}
+
+// UntilNextDay returns the time until daily budgets reset at the next UTC midnight
+func UntilNextDay(now time.Time) time.Duration {
+	now = now.UTC()
+	return now.Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+}
+
+// RetryAfterSeconds converts a wait to the value of a Retry-After header, at least one second
+func RetryAfterSeconds(wait time.Duration) int {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	return max(seconds, 1)
+}
@@ -0,0 +1,159 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// fakeClock returns a memory store whose time is controlled by the returned pointer
+func fakeClock() (*MemoryStore, *time.Time) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	ctx := context.Background()
+	store, now := fakeClock()
+
+	for i := 0; i < 3; i++ {
+		if ok, _, _ := store.Take(ctx, "k", 3); !ok {
+			t.Fatalf("request %d within the burst should be allowed", i)
+		}
+	}
+	ok, wait, _ := store.Take(ctx, "k", 3)
+	if ok {
+		t.Fatal("request beyond the burst should be rejected")
+	}
+	if wait != 20*time.Second {
+		t.Errorf("expected to wait 20s for a token at 3 per minute, got %s", wait)
+	}
+
+	*now = now.Add(20 * time.Second)
+	if ok, _, _ := store.Take(ctx, "k", 3); !ok {
+		t.Error("a token should be refilled after 20s")
+	}
+	if ok, _, _ := store.Take(ctx, "other", 3); !ok {
+		t.Error("buckets of other keys should be independent")
+	}
+}
+
+func TestMemoryStoreAcquire(t *testing.T) {
+	ctx := context.Background()
+	store, now := fakeClock()
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := store.Acquire(ctx, "s", 2, time.Hour); !ok {
+			t.Fatalf("slot %d should be acquired", i)
+		}
+	}
+	if ok, _ := store.Acquire(ctx, "s", 2, time.Hour); ok {
+		t.Fatal("no slot should be left")
+	}
+	_ = store.Release(ctx, "s")
+	if ok, _ := store.Acquire(ctx, "s", 2, time.Hour); !ok {
+		t.Fatal("a released slot should be acquired again")
+	}
+
+	// slots whose release was lost expire
+	*now = now.Add(2 * time.Hour)
+	if value, _ := store.Get(ctx, "s"); value != 0 {
+		t.Errorf("expired slots should be dropped, got %d", value)
+	}
+}
+
+func TestMemoryStoreAdd(t *testing.T) {
+	ctx := context.Background()
+	store, now := fakeClock()
+
+	if value, _ := store.Add(ctx, "c", 5, time.Minute); value != 5 {
+		t.Fatalf("expected 5, got %d", value)
+	}
+	if value, _ := store.Add(ctx, "c", 7, time.Minute); value != 12 {
+		t.Fatalf("expected 12, got %d", value)
+	}
+	*now = now.Add(time.Minute)
+	if value, _ := store.Get(ctx, "c"); value != 0 {
+		t.Errorf("counter should expire, got %d", value)
+	}
+}
+
+// failingStore fails every call
+type failingStore struct{}
+
+var errUnavailable = errors.New("unavailable")
+
+func (failingStore) Take(context.Context, string, int) (bool, time.Duration, error) {
+	return false, 0, errUnavailable
+}
+
+func (failingStore) Acquire(context.Context, string, int64, time.Duration) (bool, error) {
+	return false, errUnavailable
+}
+
+func (failingStore) Release(context.Context, string) error { return errUnavailable }
+
+func (failingStore) Add(context.Context, string, int64, time.Duration) (int64, error) {
+	return 0, errUnavailable
+}
+
+func (failingStore) Get(context.Context, string) (int64, error) { return 0, errUnavailable }
+
+func TestFallbackStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewFallbackStore(failingStore{}, NewMemoryStore())
+
+	if ok, _, err := store.Take(ctx, "k", 1); !ok || err != nil {
+		t.Fatalf("first request should be allowed by the fallback: %v", err)
+	}
+	if ok, _, _ := store.Take(ctx, "k", 1); ok {
+		t.Error("the fallback should keep limiting")
+	}
+	if ok, err := store.Acquire(ctx, "s", 1, time.Hour); !ok || err != nil {
+		t.Fatalf("slot should be acquired from the fallback: %v", err)
+	}
+	if err := store.Release(ctx, "s"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Add(ctx, "c", 3, time.Hour); value != 3 || err != nil {
+		t.Errorf("expected 3 from the fallback, got %d %v", value, err)
+	}
+}
+
+func TestSubjects(t *testing.T) {
+	ctx := context.WithValue(context.Background(), types.TenantIDContextKey, uint64(9))
+	ctx = context.WithValue(ctx, types.AccessSubjectContextKey, &types.AccessSubject{UserID: "u1"})
+	subjects := Subjects(ctx)
+	if len(subjects) != 2 || subjects[0].ID != "9" || subjects[1].Type != types.QuotaSubjectUser {
+		t.Errorf("unexpected subjects: %+v", subjects)
+	}
+
+	keyCtx := context.WithValue(context.Background(), types.TenantIDContextKey, uint64(9))
+	keyCtx = context.WithValue(keyCtx, types.APIKeyContextKey, &types.TenantAPIKey{ID: "k1"})
+	subjects = Subjects(keyCtx)
+	if len(subjects) != 2 || subjects[1].Type != types.QuotaSubjectAPIKey || subjects[1].ID != "k1" {
+		t.Errorf("unexpected subjects: %+v", subjects)
+	}
+
+	if subjects := Subjects(context.Background()); len(subjects) != 0 {
+		t.Errorf("requests without a tenant have no subjects: %+v", subjects)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 59, 30, 0, time.UTC)
+	if wait := UntilNextDay(now); wait != 30*time.Second {
+		t.Errorf("expected 30s until midnight, got %s", wait)
+	}
+	if seconds := RetryAfterSeconds(1500 * time.Millisecond); seconds != 2 {
+		t.Errorf("expected 2, got %d", seconds)
+	}
+	if seconds := RetryAfterSeconds(0); seconds != 1 {
+		t.Errorf("expected at least 1, got %d", seconds)
+	}
+}
@@ -0,0 +1,367 @@
+// Package quota keeps the counters behind tenant, user and API key limits.
+// Request rates use token buckets, concurrent streams use counters released when a stream ends,
+// and daily token and ingestion budgets use counters that expire with their day.
+package quota
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store keeps quota counters, it must be safe for concurrent use
+type Store interface {
+	// Take takes a token from a bucket holding up to perMinute tokens and refilled at perMinute tokens per minute.
+	// When the bucket is empty it returns false and the wait until a token is available.
+	Take(ctx context.Context, key string, perMinute int) (bool, time.Duration, error)
+	// Acquire increments a counter unless it has reached limit, the counter expires after ttl without acquisitions
+	Acquire(ctx context.Context, key string, limit int64, ttl time.Duration) (bool, error)
+	// Release decrements a counter incremented by Acquire
+	Release(ctx context.Context, key string) error
+	// Add adds n to a counter expiring after ttl and returns its new value
+	Add(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error)
+	// Get returns the value of a counter, missing counters are zero
+	Get(ctx context.Context, key string) (int64, error)
+}
+
+// bucket is the state of an in-memory token bucket
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds the tokens earned since the last update, it returns the refill rate per second
+func (b *bucket) refill(now time.Time, perMinute int) float64 {
+	rate := float64(perMinute) / 60
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(perMinute), b.tokens+elapsed*rate)
+		b.updated = now
+	}
+	return rate
+}
+
+// counter is an in-memory counter with an expiry
+type counter struct {
+	value   int64
+	expires time.Time
+}
+
+// memorySweepInterval is how often expired in-memory buckets and counters are removed
+const memorySweepInterval = time.Minute
+
+// MemoryStore keeps counters in process memory, each server instance counts separately
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	counters  map[string]*counter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  map[string]*bucket{},
+		counters: map[string]*counter{},
+		now:      time.Now,
+	}
+}
+
+// sweep removes full buckets and expired counters, the caller holds the lock
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		// a bucket untouched for a minute has refilled completely and is the same as a new one
+		if now.Sub(b.updated) >= time.Minute {
+			delete(s.buckets, key)
+		}
+	}
+	for key, c := range s.counters {
+		if !now.Before(c.expires) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// counter returns the live counter of a key, the caller holds the lock
+func (s *MemoryStore) counter(key string, now time.Time) *counter {
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.expires) {
+		return nil
+	}
+	return c
+}
+
+// Take takes a token from the bucket of a key
+func (s *MemoryStore) Take(ctx context.Context, key string, perMinute int) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(perMinute), updated: now}
+		s.buckets[key] = b
+	}
+	rate := b.refill(now, perMinute)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait, nil
+}
+
+// Acquire increments the counter of a key unless it has reached limit
+func (s *MemoryStore) Acquire(ctx context.Context, key string, limit int64, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+
+	c := s.counter(key, now)
+	if c == nil {
+		c = &counter{}
+		s.counters[key] = c
+	}
+	if c.value >= limit {
+		return false, nil
+	}
+	c.value++
+	c.expires = now.Add(ttl)
+	return true, nil
+}
+
+// Release decrements the counter of a key
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.counter(key, s.now()); c != nil {
+		c.value--
+		if c.value <= 0 {
+			delete(s.counters, key)
+		}
+	}
+	return nil
+}
+
+// Add adds n to the counter of a key
+func (s *MemoryStore) Add(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+
+	c := s.counter(key, now)
+	if c == nil {
+		c = &counter{expires: now.Add(ttl)}
+		s.counters[key] = c
+	}
+	c.value += n
+	return c.value, nil
+}
+
+// Get returns the counter of a key
+func (s *MemoryStore) Get(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.counter(key, s.now()); c != nil {
+		return c.value, nil
+	}
+	return 0, nil
+}
+
+// takeScript takes a token from a bucket stored as a hash of tokens and last update in milliseconds.
+// The server time is used so that instances with skewed clocks share buckets correctly.
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = burst / 60000
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+if now > updated then
+	tokens = math.min(burst, tokens + (now - updated) * rate)
+	updated = now
+end
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', updated)
+redis.call('PEXPIRE', KEYS[1], 60000)
+return {allowed, wait}
+`)
+
+// acquireScript increments a counter unless it has reached the limit
+var acquireScript = redis.NewScript(`
+local value = tonumber(redis.call('GET', KEYS[1]) or '0')
+if value >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// releaseScript decrements a counter and removes it when it reaches zero
+var releaseScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+local value = redis.call('DECR', KEYS[1])
+if value <= 0 then
+	redis.call('DEL', KEYS[1])
+end
+return value
+`)
+
+// addScript adds to a counter and sets its expiry when it is created
+var addScript = redis.NewScript(`
+local value = redis.call('INCRBY', KEYS[1], ARGV[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return value
+`)
+
+// RedisStore keeps counters in Redis so that every server instance shares them
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore creates a Redis store, keys are prefixed with prefix
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Take takes a token from the bucket of a key
+func (s *RedisStore) Take(ctx context.Context, key string, perMinute int) (bool, time.Duration, error) {
+	result, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, perMinute).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// Acquire increments the counter of a key unless it has reached limit
+func (s *RedisStore) Acquire(ctx context.Context, key string, limit int64, ttl time.Duration) (bool, error) {
+	acquired, err := acquireScript.Run(ctx, s.client, []string{s.prefix + key}, limit, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return acquired == 1, nil
+}
+
+// Release decrements the counter of a key
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	return releaseScript.Run(ctx, s.client, []string{s.prefix + key}).Err()
+}
+
+// Add adds n to the counter of a key
+func (s *RedisStore) Add(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	return addScript.Run(ctx, s.client, []string{s.prefix + key}, n, ttl.Milliseconds()).Int64()
+}
+
+// Get returns the counter of a key
+func (s *RedisStore) Get(ctx context.Context, key string) (int64, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return value, err
+}
+
+// fallbackLogInterval bounds how often failures of the primary store are logged
+const fallbackLogInterval = time.Minute
+
+// FallbackStore uses a primary store and switches to a fallback store for calls that fail,
+// so that limits keep working per instance while Redis is unavailable
+type FallbackStore struct {
+	primary  Store
+	fallback Store
+
+	mu         sync.Mutex
+	lastLogged time.Time
+}
+
+// NewFallbackStore creates a store using fallback when primary fails
+func NewFallbackStore(primary Store, fallback Store) *FallbackStore {
+	return &FallbackStore{primary: primary, fallback: fallback}
+}
+
+// failed logs a failure of the primary store at most once per fallbackLogInterval
+func (s *FallbackStore) failed(ctx context.Context, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.lastLogged) < fallbackLogInterval {
+		return
+	}
+	s.lastLogged = time.Now()
+	logger.Warnf(ctx, "Quota store unavailable, using in-memory counters: %v", err)
+}
+
+// Take takes a token from the bucket of a key
+func (s *FallbackStore) Take(ctx context.Context, key string, perMinute int) (bool, time.Duration, error) {
+	allowed, wait, err := s.primary.Take(ctx, key, perMinute)
+	if err != nil {
+		s.failed(ctx, err)
+		return s.fallback.Take(ctx, key, perMinute)
+	}
+	return allowed, wait, nil
+}
+
+// Acquire increments the counter of a key unless it has reached limit
+func (s *FallbackStore) Acquire(ctx context.Context, key string, limit int64, ttl time.Duration) (bool, error) {
+	acquired, err := s.primary.Acquire(ctx, key, limit, ttl)
+	if err != nil {
+		s.failed(ctx, err)
+		return s.fallback.Acquire(ctx, key, limit, ttl)
+	}
+	return acquired, nil
+}
+
+// Release decrements the counter of a key.
+// A slot acquired from the fallback store while the primary was down expires with its ttl.
+func (s *FallbackStore) Release(ctx context.Context, key string) error {
+	if err := s.primary.Release(ctx, key); err != nil {
+		s.failed(ctx, err)
+		return s.fallback.Release(ctx, key)
+	}
+	return nil
+}
+
+// Add adds n to the counter of a key
+func (s *FallbackStore) Add(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	value, err := s.primary.Add(ctx, key, n, ttl)
+	if err != nil {
+		s.failed(ctx, err)
+		return s.fallback.Add(ctx, key, n, ttl)
+	}
+	return value, nil
+}
+
+// Get returns the counter of a key
+func (s *FallbackStore) Get(ctx context.Context, key string) (int64, error) {
+	value, err := s.primary.Get(ctx, key)
+	if err != nil {
+		s.failed(ctx, err)
+		return s.fallback.Get(ctx, key)
+	}
+	return value, nil
+}
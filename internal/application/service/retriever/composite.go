@@ -36,6 +36,8 @@ func (c *CompositeRetrieveEngine) Retrieve(ctx context.Context,
 ) ([]*types.RetrieveResult, error) {
 	return concurrentRetrieve(ctx, retrieveParams,
 		func(ctx context.Context, param types.RetrieveParams, results *[]*types.RetrieveResult, mu *sync.Mutex) error {
+			// Point-in-time retrieval also searches the archived indices of the knowledge bases
+			param.KnowledgeBaseIDs = param.IndexKnowledgeBaseIDs()
 			found := false
 			for _, engineInfo := range c.engineInfos {
 				if engineInfo == nil {
@@ -314,6 +316,52 @@ func (c *CompositeRetrieveEngine) DeleteByKnowledgeIDList(ctx context.Context,
 	})
 }
 
+// DeleteByKnowledgeBaseID deletes all vector embeddings of a knowledge base from all registered repositories
+func (c *CompositeRetrieveEngine) DeleteByKnowledgeBaseID(ctx context.Context,
+	knowledgeBaseID string, dimension int,
+) error {
+	return c.concurrentExecWithError(ctx, func(ctx context.Context, engineInfo *engineInfo) error {
+		if err := engineInfo.retrieveEngine.DeleteByKnowledgeBaseID(ctx, knowledgeBaseID, dimension); err != nil {
+			logger.GetLogger(ctx).Errorf("Repository %s failed to delete knowledge base indices: %v",
+				engineInfo.retrieveEngine.EngineType(), err)
+			return err
+		}
+		return nil
+	})
+}
+
+// MoveByChunkIDList moves the indices of chunks to another knowledge base ID in all registered repositories
+func (c *CompositeRetrieveEngine) MoveByChunkIDList(ctx context.Context,
+	chunkIDList []string, targetKnowledgeBaseID string, dimension int, knowledgeType string,
+) error {
+	return c.concurrentExecWithError(ctx, func(ctx context.Context, engineInfo *engineInfo) error {
+		if err := engineInfo.retrieveEngine.MoveByChunkIDList(
+			ctx, chunkIDList, targetKnowledgeBaseID, dimension, knowledgeType,
+		); err != nil {
+			logger.GetLogger(ctx).Errorf("Repository %s failed to move chunk indices: %v",
+				engineInfo.retrieveEngine.EngineType(), err)
+			return err
+		}
+		return nil
+	})
+}
+
+// ReplaceKnowledgeBaseIndices switches every registered repository of a knowledge base over to its
+// shadow indices. Each repository replaces the indices as atomically as the engine allows.
+func (c *CompositeRetrieveEngine) ReplaceKnowledgeBaseIndices(ctx context.Context,
+	knowledgeBaseID string, shadowKnowledgeBaseID string, oldDimension int, newDimension int,
+) error {
+	return c.concurrentExecWithError(ctx, func(ctx context.Context, engineInfo *engineInfo) error {
+		if err := engineInfo.retrieveEngine.ReplaceKnowledgeBaseIndices(
+			ctx, knowledgeBaseID, shadowKnowledgeBaseID, oldDimension, newDimension,
+		); err != nil {
+			logger.Errorf(ctx, "Repository %s failed to replace indices: %v", engineInfo.retrieveEngine.EngineType(), err)
+			return err
+		}
+		return nil
+	})
+}
+
 // EstimateStorageSize estimates the storage size required for the provided index information
 func (c *CompositeRetrieveEngine) EstimateStorageSize(ctx context.Context,
 	embedder embedding.Embedder, indexInfoList []*types.IndexInfo,
@@ -0,0 +1,57 @@
+package retriever
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// recordingRetrieveEngine records the knowledge base IDs it is asked to search
+type recordingRetrieveEngine struct {
+	interfaces.RetrieveEngineService
+	mu               sync.Mutex
+	knowledgeBaseIDs [][]string
+}
+
+func (e *recordingRetrieveEngine) Retrieve(ctx context.Context,
+	params types.RetrieveParams,
+) ([]*types.RetrieveResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.knowledgeBaseIDs = append(e.knowledgeBaseIDs, params.KnowledgeBaseIDs)
+	return []*types.RetrieveResult{{RetrieverType: params.RetrieverType}}, nil
+}
+
+func TestCompositeRetrieveEngine_RetrieveArchive(t *testing.T) {
+	engine := &recordingRetrieveEngine{}
+	composite := &CompositeRetrieveEngine{engineInfos: []*engineInfo{
+		{retrieveEngine: engine, retrieverType: []types.RetrieverType{types.KeywordsRetrieverType}},
+	}}
+
+	asOf := time.Now()
+	_, err := composite.Retrieve(context.Background(), []types.RetrieveParams{
+		{RetrieverType: types.KeywordsRetrieverType, KnowledgeBaseIDs: []string{"kb1", "kb2"}},
+	})
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	_, err = composite.Retrieve(context.Background(), []types.RetrieveParams{
+		{RetrieverType: types.KeywordsRetrieverType, KnowledgeBaseIDs: []string{"kb1", "kb2"}, AsOf: &asOf},
+	})
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+
+	want := [][]string{
+		{"kb1", "kb2"},
+		{"kb1", "archive:kb1", "kb2", "archive:kb2"},
+	}
+	if !slices.EqualFunc(engine.knowledgeBaseIDs, want, slices.Equal[[]string]) {
+		t.Errorf("searched knowledge base IDs = %v, want %v", engine.knowledgeBaseIDs, want)
+	}
+}
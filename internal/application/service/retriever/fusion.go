@@ -0,0 +1,264 @@
+package retriever
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// Fuser merges the ranked lists produced by different retrievers into a single ranked list.
+// Each input list contains the results of one retriever type, deduplicated by chunk ID and
+// sorted by descending raw score.
+type Fuser interface {
+	// Method returns the fusion method implemented by the fuser
+	Method() types.FusionMethod
+	// Fuse computes the fused score of every chunk, keyed by chunk ID
+	Fuse(lists map[types.RetrieverType][]*types.IndexWithScore, cfg types.FusionConfig) map[string]float64
+}
+
+var (
+	fusersMu sync.RWMutex
+	fusers   = map[types.FusionMethod]Fuser{
+		types.RRFFusionMethod:      rrfFuser{},
+		types.WeightedFusionMethod: weightedFuser{},
+		types.DBSFFusionMethod:     dbsfFuser{},
+	}
+)
+
+// RegisterFuser registers a fuser, replacing any fuser registered for the same method
+func RegisterFuser(fuser Fuser) {
+	fusersMu.Lock()
+	defer fusersMu.Unlock()
+	fusers[fuser.Method()] = fuser
+}
+
+// GetFuser returns the fuser registered for the given method
+func GetFuser(method types.FusionMethod) (Fuser, error) {
+	fusersMu.RLock()
+	defer fusersMu.RUnlock()
+	fuser, ok := fusers[method]
+	if !ok {
+		return nil, fmt.Errorf("fusion method %s not supported", method)
+	}
+	return fuser, nil
+}
+
+// ValidateFusionConfig checks that the fusion method is registered and the parameters are not negative.
+// An invalid config is a bad request error, a nil config selects the defaults.
+func ValidateFusionConfig(cfg *types.FusionConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.RRFK < 0 || cfg.VectorWeight < 0 || cfg.KeywordWeight < 0 ||
+		math.IsNaN(cfg.VectorWeight) || math.IsNaN(cfg.KeywordWeight) {
+		return werrors.NewBadRequestError("Invalid fusion config").
+			WithDetails("rrf_k, vector_weight and keyword_weight must not be negative")
+	}
+	if _, err := GetFuser(cfg.WithDefaults().Method); err != nil {
+		return werrors.NewBadRequestError("Invalid fusion config").WithDetails(err.Error())
+	}
+	return nil
+}
+
+// FuseResults merges retrieval results from several retrievers into one list ordered by fused score.
+// Results are grouped by retriever type and deduplicated by chunk ID. When only vector results are
+// present the raw similarity scores are kept, so vector-only searches (e.g. FAQ) are unaffected;
+// keyword-only results are still fused so raw BM25 scores never leak to callers.
+func FuseResults(results []*types.RetrieveResult, cfg *types.FusionConfig) ([]*types.IndexWithScore, error) {
+	if err := ValidateFusionConfig(cfg); err != nil {
+		return nil, err
+	}
+	fusionConfig := cfg.WithDefaults()
+	fuser, err := GetFuser(fusionConfig.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := groupByRetrieverType(results)
+	chunkInfoMap := make(map[string]*types.IndexWithScore)
+	// Vector results take precedence when the same chunk is returned by several retrievers
+	for _, retrieverType := range orderedRetrieverTypes(lists) {
+		for _, r := range lists[retrieverType] {
+			if _, exists := chunkInfoMap[r.ChunkID]; !exists {
+				chunkInfoMap[r.ChunkID] = r
+			}
+		}
+	}
+
+	fused := make([]*types.IndexWithScore, 0, len(chunkInfoMap))
+	if _, hasVector := lists[types.VectorRetrieverType]; !hasVector || len(lists) > 1 {
+		scores := fuser.Fuse(lists, fusionConfig)
+		for chunkID, info := range chunkInfoMap {
+			info.Score = scores[chunkID]
+			fused = append(fused, info)
+		}
+	} else {
+		for _, info := range chunkInfoMap {
+			fused = append(fused, info)
+		}
+	}
+	sortByScore(fused)
+	return fused, nil
+}
+
+// groupByRetrieverType splits results per retriever type, keeping the best score of each chunk
+func groupByRetrieverType(results []*types.RetrieveResult) map[types.RetrieverType][]*types.IndexWithScore {
+	best := make(map[types.RetrieverType]map[string]*types.IndexWithScore)
+	for _, result := range results {
+		if result == nil || len(result.Results) == 0 {
+			continue
+		}
+		if _, ok := best[result.RetrieverType]; !ok {
+			best[result.RetrieverType] = make(map[string]*types.IndexWithScore)
+		}
+		for _, r := range result.Results {
+			if existing, ok := best[result.RetrieverType][r.ChunkID]; !ok || r.Score > existing.Score {
+				best[result.RetrieverType][r.ChunkID] = r
+			}
+		}
+	}
+
+	lists := make(map[types.RetrieverType][]*types.IndexWithScore, len(best))
+	for retrieverType, chunks := range best {
+		list := make([]*types.IndexWithScore, 0, len(chunks))
+		for _, r := range chunks {
+			list = append(list, r)
+		}
+		sortByScore(list)
+		lists[retrieverType] = list
+	}
+	return lists
+}
+
+// orderedRetrieverTypes returns the retriever types of the lists, vector first, for stable iteration
+func orderedRetrieverTypes(lists map[types.RetrieverType][]*types.IndexWithScore) []types.RetrieverType {
+	retrieverTypes := make([]types.RetrieverType, 0, len(lists))
+	for retrieverType := range lists {
+		retrieverTypes = append(retrieverTypes, retrieverType)
+	}
+	slices.SortFunc(retrieverTypes, func(a, b types.RetrieverType) int {
+		if a == types.VectorRetrieverType {
+			return -1
+		}
+		if b == types.VectorRetrieverType {
+			return 1
+		}
+		return strings.Compare(string(a), string(b))
+	})
+	return retrieverTypes
+}
+
+// sortByScore sorts results by descending score, breaking ties by chunk ID
+func sortByScore(results []*types.IndexWithScore) {
+	slices.SortFunc(results, func(a, b *types.IndexWithScore) int {
+		if a.Score > b.Score {
+			return -1
+		} else if a.Score < b.Score {
+			return 1
+		}
+		return strings.Compare(a.ChunkID, b.ChunkID)
+	})
+}
+
+// normalizedWeights returns the weight of each list scaled so that the weights sum to total
+func normalizedWeights(
+	lists map[types.RetrieverType][]*types.IndexWithScore,
+	cfg types.FusionConfig,
+	total float64,
+) map[types.RetrieverType]float64 {
+	sum := 0.0
+	for retrieverType := range lists {
+		sum += cfg.Weight(retrieverType)
+	}
+	weights := make(map[types.RetrieverType]float64, len(lists))
+	for retrieverType := range lists {
+		if sum <= 0 {
+			// Unknown retriever types or zero weights: fall back to equal weights
+			weights[retrieverType] = total / float64(len(lists))
+			continue
+		}
+		weights[retrieverType] = cfg.Weight(retrieverType) / sum * total
+	}
+	return weights
+}
+
+// rrfFuser implements Reciprocal Rank Fusion: score = sum(w / (k + rank)).
+// Weights are relative, equal weights give the classic unweighted RRF.
+type rrfFuser struct{}
+
+func (rrfFuser) Method() types.FusionMethod { return types.RRFFusionMethod }
+
+func (rrfFuser) Fuse(lists map[types.RetrieverType][]*types.IndexWithScore, cfg types.FusionConfig) map[string]float64 {
+	weights := normalizedWeights(lists, cfg, float64(len(lists)))
+	scores := make(map[string]float64)
+	for retrieverType, list := range lists {
+		for i, r := range list {
+			scores[r.ChunkID] += weights[retrieverType] / float64(cfg.RRFK+i+1)
+		}
+	}
+	return scores
+}
+
+// weightedFuser min-max normalizes each list to [0, 1] and sums the normalized scores with weights
+type weightedFuser struct{}
+
+func (weightedFuser) Method() types.FusionMethod { return types.WeightedFusionMethod }
+
+func (weightedFuser) Fuse(lists map[types.RetrieverType][]*types.IndexWithScore, cfg types.FusionConfig) map[string]float64 {
+	return linearFuse(lists, cfg, func(list []*types.IndexWithScore) (float64, float64) {
+		minS, maxS := math.Inf(1), math.Inf(-1)
+		for _, r := range list {
+			minS = math.Min(minS, r.Score)
+			maxS = math.Max(maxS, r.Score)
+		}
+		return minS, maxS
+	})
+}
+
+// dbsfFuser implements Distribution-Based Score Fusion: each list is normalized using
+// mean ± 3 standard deviations as bounds, which is robust to outliers in BM25 scores
+type dbsfFuser struct{}
+
+func (dbsfFuser) Method() types.FusionMethod { return types.DBSFFusionMethod }
+
+func (dbsfFuser) Fuse(lists map[types.RetrieverType][]*types.IndexWithScore, cfg types.FusionConfig) map[string]float64 {
+	return linearFuse(lists, cfg, func(list []*types.IndexWithScore) (float64, float64) {
+		mean := 0.0
+		for _, r := range list {
+			mean += r.Score
+		}
+		mean /= float64(len(list))
+		variance := 0.0
+		for _, r := range list {
+			variance += (r.Score - mean) * (r.Score - mean)
+		}
+		std := math.Sqrt(variance / float64(len(list)))
+		return mean - 3*std, mean + 3*std
+	})
+}
+
+// linearFuse normalizes each list with the bounds returned by boundsFn and sums them with weights
+func linearFuse(
+	lists map[types.RetrieverType][]*types.IndexWithScore,
+	cfg types.FusionConfig,
+	boundsFn func(list []*types.IndexWithScore) (float64, float64),
+) map[string]float64 {
+	weights := normalizedWeights(lists, cfg, 1)
+	scores := make(map[string]float64)
+	for retrieverType, list := range lists {
+		lower, upper := boundsFn(list)
+		for _, r := range list {
+			normalized := 1.0
+			if upper > lower {
+				normalized = math.Min(math.Max((r.Score-lower)/(upper-lower), 0), 1)
+			}
+			scores[r.ChunkID] += weights[retrieverType] * normalized
+		}
+	}
+	return scores
+}
@@ -0,0 +1,151 @@
+package retriever
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+func buildResult(retrieverType types.RetrieverType, scores map[string]float64) *types.RetrieveResult {
+	result := &types.RetrieveResult{RetrieverType: retrieverType}
+	for chunkID, score := range scores {
+		result.Results = append(result.Results, &types.IndexWithScore{ChunkID: chunkID, Score: score})
+	}
+	return result
+}
+
+func chunkIDs(results []*types.IndexWithScore) []string {
+	ids := make([]string, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.ChunkID)
+	}
+	return ids
+}
+
+func TestFuseResults(t *testing.T) {
+	// BM25 scores are an order of magnitude larger than cosine similarities
+	vector := map[string]float64{"a": 0.90, "b": 0.80, "c": 0.30}
+	keyword := map[string]float64{"c": 20.0, "d": 19.0, "b": 1.0}
+
+	tests := []struct {
+		name     string
+		cfg      *types.FusionConfig
+		expected []string
+	}{
+		{
+			name:     "default rrf",
+			cfg:      nil,
+			expected: []string{"c", "b", "a", "d"},
+		},
+		{
+			name:     "rrf favouring vector",
+			cfg:      &types.FusionConfig{Method: types.RRFFusionMethod, VectorWeight: 0.9, KeywordWeight: 0.1},
+			expected: []string{"b", "c", "a", "d"},
+		},
+		{
+			name:     "weighted",
+			cfg:      &types.FusionConfig{Method: types.WeightedFusionMethod},
+			expected: []string{"a", "c", "d", "b"},
+		},
+		{
+			name:     "weighted favouring keyword",
+			cfg:      &types.FusionConfig{Method: types.WeightedFusionMethod, VectorWeight: 0.1, KeywordWeight: 0.9},
+			expected: []string{"c", "d", "a", "b"},
+		},
+		{
+			name:     "dbsf",
+			cfg:      &types.FusionConfig{Method: types.DBSFFusionMethod},
+			expected: []string{"c", "b", "a", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := []*types.RetrieveResult{
+				buildResult(types.VectorRetrieverType, vector),
+				buildResult(types.KeywordsRetrieverType, keyword),
+			}
+			fused, err := FuseResults(results, tt.cfg)
+			if err != nil {
+				t.Fatalf("FuseResults() error = %v", err)
+			}
+			got := chunkIDs(fused)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("FuseResults() = %v, want %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("FuseResults() = %v, want %v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestFuseResults_RRFScore(t *testing.T) {
+	results := []*types.RetrieveResult{
+		buildResult(types.VectorRetrieverType, map[string]float64{"a": 0.9}),
+		buildResult(types.KeywordsRetrieverType, map[string]float64{"a": 5}),
+	}
+	fused, err := FuseResults(results, nil)
+	if err != nil {
+		t.Fatalf("FuseResults() error = %v", err)
+	}
+	// Equal weights give the classic unweighted RRF: 1/(60+1) + 1/(60+1)
+	expected := 2.0 / 61
+	if math.Abs(fused[0].Score-expected) > 1e-9 {
+		t.Errorf("RRF score = %v, want %v", fused[0].Score, expected)
+	}
+}
+
+func TestFuseResults_VectorOnlyKeepsRawScores(t *testing.T) {
+	results := []*types.RetrieveResult{
+		buildResult(types.VectorRetrieverType, map[string]float64{"a": 0.7, "b": 0.9}),
+		{
+			RetrieverType: types.VectorRetrieverType,
+			Results:       []*types.IndexWithScore{{ChunkID: "a", Score: 0.8}},
+		},
+	}
+	fused, err := FuseResults(results, &types.FusionConfig{Method: types.WeightedFusionMethod})
+	if err != nil {
+		t.Fatalf("FuseResults() error = %v", err)
+	}
+	if len(fused) != 2 || fused[0].ChunkID != "b" || fused[0].Score != 0.9 ||
+		fused[1].ChunkID != "a" || fused[1].Score != 0.8 {
+		t.Errorf("FuseResults() = %v, want raw deduplicated vector scores", chunkIDs(fused))
+	}
+}
+
+func TestFuseResults_KeywordOnlyIsNormalized(t *testing.T) {
+	results := []*types.RetrieveResult{
+		buildResult(types.KeywordsRetrieverType, map[string]float64{"a": 20, "b": 10}),
+	}
+	fused, err := FuseResults(results, &types.FusionConfig{Method: types.WeightedFusionMethod})
+	if err != nil {
+		t.Fatalf("FuseResults() error = %v", err)
+	}
+	if fused[0].Score != 1 || fused[1].Score != 0 {
+		t.Errorf("keyword-only scores = [%v %v], want [1 0]", fused[0].Score, fused[1].Score)
+	}
+}
+
+func TestFuseResults_UnknownMethod(t *testing.T) {
+	if _, err := FuseResults(nil, &types.FusionConfig{Method: "unknown"}); err == nil {
+		t.Error("expected error for unknown fusion method")
+	}
+}
+
+func TestFuseResults_MultiVectorUsesVectorWeight(t *testing.T) {
+	results := []*types.RetrieveResult{
+		buildResult(types.MultiVectorRetrieverType, map[string]float64{"a": 0.9, "b": 0.5}),
+		buildResult(types.KeywordsRetrieverType, map[string]float64{"b": 20, "a": 10}),
+	}
+	fused, err := FuseResults(results, &types.FusionConfig{Method: types.RRFFusionMethod, VectorWeight: 0.9, KeywordWeight: 0.1})
+	if err != nil {
+		t.Fatalf("FuseResults() error = %v", err)
+	}
+	if got := chunkIDs(fused); got[0] != "a" {
+		t.Errorf("FuseResults() = %v, want multi-vector ranking to dominate", got)
+	}
+}
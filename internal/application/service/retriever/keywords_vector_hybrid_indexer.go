@@ -54,9 +54,49 @@ func (v *KeywordsVectorHybridRetrieveEngineService) Index(ctx context.Context,
 		embeddingMap[indexInfo.SourceID] = embedding
 	}
 	params["embedding"] = embeddingMap
+	if slices.Contains(retrieverTypes, types.MultiVectorRetrieverType) {
+		tokenEmbeddings, err := v.embedTokens(ctx, embedder, []string{indexInfo.Content})
+		if err != nil {
+			return err
+		}
+		if tokenEmbeddings != nil {
+			params["token_embedding"] = map[string][][]float32{indexInfo.SourceID: tokenEmbeddings[0]}
+		}
+	}
 	return v.indexRepository.Save(ctx, indexInfo, params)
 }
 
+// embedTokens creates per-token embeddings for multi-vector retrieval.
+// It returns nil if the embedder is not a token-level (late-interaction) embedder.
+func (v *KeywordsVectorHybridRetrieveEngineService) embedTokens(ctx context.Context,
+	embedder embedding.Embedder, contentList []string,
+) ([][][]float32, error) {
+	tokenEmbedder, ok := embedder.(embedding.TokenEmbedder)
+	if !ok {
+		logger.Warnf(ctx, "Embedder %s does not support token embeddings, skipping multi-vector indexing",
+			embedder.GetModelName())
+		return nil, nil
+	}
+	tokenEmbeddings := make([][][]float32, 0, len(contentList))
+	for _, contentChunk := range utils.ChunkSlice(contentList, 20) {
+		var batch [][][]float32
+		var err error
+		for range 5 {
+			batch, err = tokenEmbedder.BatchEmbedDocumentTokens(ctx, contentChunk)
+			if err == nil {
+				break
+			}
+			logger.Errorf(ctx, "BatchEmbedDocumentTokens failed: %v", err)
+			time.Sleep(100 * time.Millisecond)
+		}
+		if err != nil {
+			return nil, err
+		}
+		tokenEmbeddings = append(tokenEmbeddings, batch...)
+	}
+	return tokenEmbeddings, nil
+}
+
 // BatchIndex creates embeddings for multiple content items and saves them to the repository
 // in batches for efficiency
 func (v *KeywordsVectorHybridRetrieveEngineService) BatchIndex(ctx context.Context,
@@ -66,12 +106,13 @@ func (v *KeywordsVectorHybridRetrieveEngineService) BatchIndex(ctx context.Conte
 		return nil
 	}
 
+	var contentList []string
+	for _, indexInfo := range indexInfoList {
+		contentList = append(contentList, indexInfo.Content)
+	}
+
+	var embeddings [][]float32
 	if slices.Contains(retrieverTypes, types.VectorRetrieverType) {
-		var contentList []string
-		for _, indexInfo := range indexInfoList {
-			contentList = append(contentList, indexInfo.Content)
-		}
-		var embeddings [][]float32
 		var err error
 		for range 5 {
 			embeddings, err = embedder.BatchEmbedWithPool(ctx, embedder, contentList)
@@ -85,16 +126,34 @@ func (v *KeywordsVectorHybridRetrieveEngineService) BatchIndex(ctx context.Conte
 		if err != nil {
 			return err
 		}
+	}
+
+	var tokenEmbeddings [][][]float32
+	if slices.Contains(retrieverTypes, types.MultiVectorRetrieverType) {
+		var err error
+		tokenEmbeddings, err = v.embedTokens(ctx, embedder, contentList)
+		if err != nil {
+			return err
+		}
+	}
+
+	if embeddings != nil || tokenEmbeddings != nil {
 		batchSize := 40
 		for i, indexChunk := range utils.ChunkSlice(indexInfoList, batchSize) {
 			params := make(map[string]any)
 			embeddingMap := make(map[string][]float32)
+			tokenEmbeddingMap := make(map[string][][]float32)
 			for j, indexInfo := range indexChunk {
-				embeddingMap[indexInfo.SourceID] = embeddings[i*batchSize+j]
+				if embeddings != nil {
+					embeddingMap[indexInfo.SourceID] = embeddings[i*batchSize+j]
+				}
+				if tokenEmbeddings != nil {
+					tokenEmbeddingMap[indexInfo.SourceID] = tokenEmbeddings[i*batchSize+j]
+				}
 			}
 			params["embedding"] = embeddingMap
-			err = v.indexRepository.BatchSave(ctx, indexChunk, params)
-			if err != nil {
+			params["token_embedding"] = tokenEmbeddingMap
+			if err := v.indexRepository.BatchSave(ctx, indexChunk, params); err != nil {
 				return err
 			}
 		}
@@ -132,6 +191,32 @@ func (v *KeywordsVectorHybridRetrieveEngineService) DeleteByKnowledgeIDList(ctx
 	return v.indexRepository.DeleteByKnowledgeIDList(ctx, knowledgeIDList, dimension, knowledgeType)
 }
 
+// DeleteByKnowledgeBaseID deletes all vectors of a knowledge base
+func (v *KeywordsVectorHybridRetrieveEngineService) DeleteByKnowledgeBaseID(ctx context.Context,
+	knowledgeBaseID string, dimension int,
+) error {
+	return v.indexRepository.DeleteByKnowledgeBaseID(ctx, knowledgeBaseID, dimension)
+}
+
+// MoveByChunkIDList moves vectors of chunks to another knowledge base ID
+func (v *KeywordsVectorHybridRetrieveEngineService) MoveByChunkIDList(ctx context.Context,
+	chunkIDList []string, targetKnowledgeBaseID string, dimension int, knowledgeType string,
+) error {
+	return v.indexRepository.MoveByChunkIDList(ctx, chunkIDList, targetKnowledgeBaseID, dimension, knowledgeType)
+}
+
+// ReplaceKnowledgeBaseIndices replaces the vectors of a knowledge base with its shadow vectors
+func (v *KeywordsVectorHybridRetrieveEngineService) ReplaceKnowledgeBaseIndices(ctx context.Context,
+	knowledgeBaseID string, shadowKnowledgeBaseID string, oldDimension int, newDimension int,
+) error {
+	logger.Infof(ctx, "Replace indices of knowledge base %s with %s, dimension: %d -> %d",
+		knowledgeBaseID, shadowKnowledgeBaseID, oldDimension, newDimension,
+	)
+	return v.indexRepository.ReplaceKnowledgeBaseIndices(
+		ctx, knowledgeBaseID, shadowKnowledgeBaseID, oldDimension, newDimension,
+	)
+}
+
 // Support returns the retriever types supported by this engine
 func (v *KeywordsVectorHybridRetrieveEngineService) Support() []types.RetrieverType {
 	return v.indexRepository.Support()
@@ -145,7 +230,8 @@ func (v *KeywordsVectorHybridRetrieveEngineService) EstimateStorageSize(
 	retrieverTypes []types.RetrieverType,
 ) int64 {
 	params := make(map[string]any)
-	if slices.Contains(retrieverTypes, types.VectorRetrieverType) {
+	if slices.Contains(retrieverTypes, types.VectorRetrieverType) ||
+		slices.Contains(retrieverTypes, types.MultiVectorRetrieverType) {
 		embeddingMap := make(map[string][]float32)
 		// just for estimate storage size
 		for _, indexInfo := range indexInfoList {
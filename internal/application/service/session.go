@@ -6,11 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/Tencent/WeKnora/internal/agent/tools"
 	chatpipline "github.com/Tencent/WeKnora/internal/application/service/chat_pipline"
 	llmcontext "github.com/Tencent/WeKnora/internal/application/service/llmcontext"
+	"github.com/Tencent/WeKnora/internal/application/service/memory"
 	"github.com/Tencent/WeKnora/internal/config"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
 	"github.com/Tencent/WeKnora/internal/event"
 	"github.com/Tencent/WeKnora/internal/logger"
 	"github.com/Tencent/WeKnora/internal/models/chat"
@@ -42,6 +44,9 @@ type sessionService struct {
 	knowledgeService     interfaces.KnowledgeService      // Service for knowledge operations
 	chunkService         interfaces.ChunkService          // Service for chunk operations
 	webSearchStateRepo   interfaces.WebSearchStateService // Service for web search state
+	permissionService    interfaces.PermissionService     // Service for resource access checks
+	agentRunService      interfaces.AgentRunService       // Service for checkpointed agent runs
+	memoryService        interfaces.MemoryService         // Service recalling long-term user memories
 }
 
 // NewSessionService creates a new session service instance with all required dependencies
@@ -57,6 +62,9 @@ func NewSessionService(cfg *config.Config,
 	agentService interfaces.AgentService,
 	sessionStorage llmcontext.ContextStorage,
 	webSearchStateRepo interfaces.WebSearchStateService,
+	permissionService interfaces.PermissionService,
+	agentRunService interfaces.AgentRunService,
+	memoryService interfaces.MemoryService,
 ) interfaces.SessionService {
 	return &sessionService{
 		cfg:                  cfg,
@@ -71,6 +79,9 @@ func NewSessionService(cfg *config.Config,
 		agentService:         agentService,
 		sessionStorage:       sessionStorage,
 		webSearchStateRepo:   webSearchStateRepo,
+		permissionService:    permissionService,
+		agentRunService:      agentRunService,
+		memoryService:        memoryService,
 	}
 }
 
@@ -230,6 +241,7 @@ func (s *sessionService) GenerateTitle(ctx context.Context,
 	if session.Title != "" {
 		return session.Title, nil
 	}
+	ctx = types.WithUsageScope(ctx, types.UsageScope{SessionID: session.ID, Source: types.UsageSourceSessionTitle})
 	var err error
 	// Get the first user message, either from provided messages or repository
 	var message *types.Message
@@ -411,10 +423,17 @@ func (s *sessionService) KnowledgeQA(
 		webSearchEnabled,
 	)
 
+	// Attribute model usage of this answer to the session and agent
+	scope := types.UsageScope{SessionID: session.ID, Source: types.UsageSourceKnowledgeQA}
+	if customAgent != nil {
+		scope.AgentID = customAgent.ID
+	}
+	ctx = types.WithUsageScope(ctx, scope)
+
 	// Use custom agent's knowledge bases only if request didn't specify any
 	// When user explicitly @mentions a knowledge base or document, only search those
 	if len(knowledgeBaseIDs) == 0 && len(knowledgeIDs) == 0 {
-		knowledgeBaseIDs = s.resolveKnowledgeBasesFromAgent(ctx, customAgent)
+		knowledgeBaseIDs = resolveKnowledgeBasesFromAgent(ctx, s.knowledgeBaseService, customAgent)
 	} else {
 		logger.Infof(ctx, "Using request-specified targets (ignoring agent config): kbs=%v, docs=%v", knowledgeBaseIDs, knowledgeIDs)
 	}
@@ -566,6 +585,12 @@ func (s *sessionService) KnowledgeQA(
 		chatModelID,
 		len(searchTargets),
 	)
+	// Recall the memories of the user only when the prompt has a place for them
+	userMemories := ""
+	if strings.Contains(summaryConfig.Prompt, memory.Placeholder) {
+		userMemories = s.memoryService.RecallMemories(ctx, query)
+	}
+
 	chatManage := &types.ChatManage{
 		Query:                query,
 		RewriteQuery:         query,
@@ -586,6 +611,7 @@ func (s *sessionService) KnowledgeQA(
 		FallbackStrategy:     fallbackStrategy,
 		FallbackResponse:     fallbackResponse,
 		FallbackPrompt:       fallbackPrompt,
+		UserMemories:         userMemories,
 		EventBus:             eventBus.AsEventBusInterface(), // NEW: For pipeline to emit events directly
 		WebSearchEnabled:     webSearchEnabled,
 		TenantID:             session.TenantID,
@@ -779,14 +805,16 @@ func (s *sessionService) selectChatModelID(
 	return "", errors.New("no chat model ID available: no knowledge bases configured and no available models")
 }
 
-// resolveKnowledgeBasesFromAgent resolves knowledge base IDs based on agent's KBSelectionMode
+// resolveKnowledgeBasesFromAgent resolves knowledge base IDs based on agent's KBSelectionMode,
+// for agents answering the user as well as sub-agents running delegated tasks
 // Returns the resolved knowledge base IDs based on the selection mode:
 //   - "all": fetches all knowledge bases for the tenant
 //   - "selected": uses the explicitly configured knowledge bases
 //   - "none": returns empty slice
 //   - default: falls back to configured knowledge bases for backward compatibility
-func (s *sessionService) resolveKnowledgeBasesFromAgent(
+func resolveKnowledgeBasesFromAgent(
 	ctx context.Context,
+	knowledgeBaseService interfaces.KnowledgeBaseService,
 	customAgent *types.CustomAgent,
 ) []string {
 	if customAgent == nil {
@@ -795,7 +823,7 @@ func (s *sessionService) resolveKnowledgeBasesFromAgent(
 
 	switch customAgent.Config.KBSelectionMode {
 	case "all":
-		allKBs, err := s.knowledgeBaseService.ListKnowledgeBases(ctx)
+		allKBs, err := knowledgeBaseService.ListKnowledgeBases(ctx)
 		if err != nil {
 			logger.Warnf(ctx, "Failed to list all knowledge bases: %v", err)
 			return nil
@@ -827,6 +855,7 @@ func (s *sessionService) resolveKnowledgeBasesFromAgent(
 //   - For each knowledgeBaseID: create a SearchTargetTypeKnowledgeBase target
 //   - For each knowledgeID: find its knowledgeBaseID, if the KB is already in the list, skip (covered by full KB search)
 //     otherwise create a SearchTargetTypeKnowledge target grouped by KB
+//   - Knowledge bases the requesting user cannot read are dropped, so retrieval never returns their chunks
 func (s *sessionService) buildSearchTargets(
 	ctx context.Context,
 	tenantID uint64,
@@ -835,6 +864,11 @@ func (s *sessionService) buildSearchTargets(
 ) (types.SearchTargets, error) {
 	var targets types.SearchTargets
 
+	knowledgeBaseIDs, err := s.readableKnowledgeBases(ctx, knowledgeBaseIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Track which KBs are fully searched
 	fullKBSet := make(map[string]bool)
 	for _, kbID := range knowledgeBaseIDs {
@@ -866,8 +900,18 @@ func (s *sessionService) buildSearchTargets(
 			kbToKnowledgeIDs[k.KnowledgeBaseID] = append(kbToKnowledgeIDs[k.KnowledgeBaseID], k.ID)
 		}
 
+		partialKBIDs := make([]string, 0, len(kbToKnowledgeIDs))
+		for kbID := range kbToKnowledgeIDs {
+			partialKBIDs = append(partialKBIDs, kbID)
+		}
+		partialKBIDs, err = s.readableKnowledgeBases(ctx, partialKBIDs)
+		if err != nil {
+			return nil, err
+		}
+
 		// Create SearchTargetTypeKnowledge targets for each KB with specific files
-		for kbID, kidList := range kbToKnowledgeIDs {
+		for _, kbID := range partialKBIDs {
+			kidList := kbToKnowledgeIDs[kbID]
 			targets = append(targets, &types.SearchTarget{
 				Type:            types.SearchTargetTypeKnowledge,
 				KnowledgeBaseID: kbID,
@@ -882,6 +926,19 @@ func (s *sessionService) buildSearchTargets(
 	return targets, nil
 }
 
+// readableKnowledgeBases filters knowledge base IDs down to those the requesting user may read
+func (s *sessionService) readableKnowledgeBases(ctx context.Context, kbIDs []string) ([]string, error) {
+	allowed, err := s.permissionService.FilterAccessible(ctx, types.ResourceTypeKnowledgeBase, kbIDs, types.PermissionRead)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to check knowledge base access: %v", err)
+		return nil, err
+	}
+	if len(allowed) < len(kbIDs) {
+		logger.Infof(ctx, "Dropped %d knowledge base(s) the user cannot read", len(kbIDs)-len(allowed))
+	}
+	return allowed, nil
+}
+
 // KnowledgeQAByEvent processes knowledge QA through a series of events in the pipeline
 func (s *sessionService) KnowledgeQAByEvent(ctx context.Context,
 	chatManage *types.ChatManage, eventList []types.EventType,
@@ -943,8 +1000,9 @@ func (s *sessionService) KnowledgeQAByEvent(ctx context.Context,
 // SearchKnowledge performs knowledge base search without LLM summarization
 // knowledgeBaseIDs: list of knowledge base IDs to search (supports multi-KB)
 // knowledgeIDs: list of specific knowledge (file) IDs to search
+// filter: optional metadata filter pushed down to the retrieve engines
 func (s *sessionService) SearchKnowledge(ctx context.Context,
-	knowledgeBaseIDs []string, knowledgeIDs []string, query string,
+	knowledgeBaseIDs []string, knowledgeIDs []string, query string, filter *types.MetadataFilter,
 ) ([]*types.SearchResult, error) {
 	logger.Info(ctx, "Start knowledge base search without LLM summary")
 	logger.Infof(ctx, "Knowledge base search parameters, knowledge base IDs: %v, knowledge IDs: %v, query: %s",
@@ -974,6 +1032,7 @@ func (s *sessionService) SearchKnowledge(ctx context.Context,
 		RewriteQuery:     query,
 		KnowledgeBaseIDs: knowledgeBaseIDs,
 		KnowledgeIDs:     knowledgeIDs,
+		Filter:           filter,
 		SearchTargets:    searchTargets,
 		VectorThreshold:  s.cfg.Conversation.VectorThreshold,  // Use default configuration
 		KeywordThreshold: s.cfg.Conversation.KeywordThreshold, // Use default configuration
@@ -1088,23 +1147,16 @@ func (s *sessionService) AgentQA(
 		logger.Warnf(ctx, "Custom agent not provided for session: %s", sessionID)
 		return errors.New("custom agent configuration is required for agent QA")
 	}
+	ctx = types.WithUsageScope(ctx, types.UsageScope{
+		SessionID: sessionID, AgentID: customAgent.ID, Source: types.UsageSourceAgentQA,
+	})
 
 	// Ensure defaults are set
 	customAgent.EnsureDefaults()
 
 	// Create runtime AgentConfig from customAgent
 	// Note: tenantInfo.AgentConfig is deprecated, all config comes from customAgent now
-	agentConfig := &types.AgentConfig{
-		MaxIterations:       customAgent.Config.MaxIterations,
-		ReflectionEnabled:   customAgent.Config.ReflectionEnabled,
-		Temperature:         customAgent.Config.Temperature,
-		WebSearchEnabled:    customAgent.Config.WebSearchEnabled,
-		WebSearchMaxResults: customAgent.Config.WebSearchMaxResults,
-		MultiTurnEnabled:    customAgent.Config.MultiTurnEnabled,
-		HistoryTurns:        customAgent.Config.HistoryTurns,
-		MCPSelectionMode:    customAgent.Config.MCPSelectionMode,
-		MCPServices:         customAgent.Config.MCPServices,
-	}
+	agentConfig := newAgentConfig(customAgent)
 
 	// Resolve knowledge bases: request-level @ mentions take priority over agent config
 	if len(knowledgeBaseIDs) > 0 || len(knowledgeIDs) > 0 {
@@ -1119,20 +1171,7 @@ func (s *sessionService) AgentQA(
 		}
 	} else {
 		// Use agent's configured knowledge bases based on KBSelectionMode
-		agentConfig.KnowledgeBases = s.resolveKnowledgeBasesFromAgent(ctx, customAgent)
-	}
-
-	// Use custom agent's allowed tools if specified, otherwise use defaults
-	if len(customAgent.Config.AllowedTools) > 0 {
-		agentConfig.AllowedTools = customAgent.Config.AllowedTools
-	} else {
-		agentConfig.AllowedTools = tools.DefaultAllowedTools()
-	}
-
-	// Use custom agent's system prompt if specified
-	if customAgent.Config.SystemPrompt != "" {
-		agentConfig.UseCustomSystemPrompt = true
-		agentConfig.SystemPrompt = customAgent.Config.SystemPrompt
+		agentConfig.KnowledgeBases = resolveKnowledgeBasesFromAgent(ctx, s.knowledgeBaseService, customAgent)
 	}
 
 	logger.Infof(ctx, "Custom agent config applied: MaxIterations=%d, Temperature=%.2f, AllowedTools=%v, WebSearchEnabled=%v",
@@ -1148,6 +1187,13 @@ func (s *sessionService) AgentQA(
 
 	logger.Infof(ctx, "Merged agent config from tenant %d and session %s", tenantInfo.ID, sessionID)
 
+	// Keep only the knowledge bases the user may read, the agent tools search within them
+	readableKBs, err := s.readableKnowledgeBases(ctx, agentConfig.KnowledgeBases)
+	if err != nil {
+		return err
+	}
+	agentConfig.KnowledgeBases = readableKBs
+
 	// Log knowledge bases if present
 	if len(agentConfig.KnowledgeBases) > 0 {
 		logger.Infof(ctx, "Agent configured with %d knowledge base(s): %v",
@@ -1166,6 +1212,12 @@ func (s *sessionService) AgentQA(
 	agentConfig.SearchTargets = searchTargets
 	logger.Infof(ctx, "Agent search targets built: %d targets", len(searchTargets))
 
+	// The default prompts have a place for the memories of the user, custom prompts only when they use the placeholder
+	if !agentConfig.UseCustomSystemPrompt || strings.Contains(
+		agentConfig.ResolveSystemPrompt(agentConfig.WebSearchEnabled), memory.Placeholder) {
+		agentConfig.UserMemories = s.memoryService.RecallMemories(ctx, query)
+	}
+
 	// Get summary model: prioritize request's summaryModelID, then custom agent config
 	// Note: tenantInfo.ConversationConfig is deprecated, all config comes from customAgent now
 	effectiveModelID := summaryModelID
@@ -1190,9 +1242,10 @@ func (s *sessionService) AgentQA(
 
 	// Get rerank model from custom agent config (only required when knowledge bases are configured)
 	var rerankModel rerank.Reranker
+	var rerankModelID string
 	hasKnowledge := len(agentConfig.KnowledgeBases) > 0 || len(agentConfig.KnowledgeIDs) > 0
 	if hasKnowledge {
-		rerankModelID := customAgent.Config.RerankModelID
+		rerankModelID = customAgent.Config.RerankModelID
 		if rerankModelID == "" {
 			logger.Warnf(ctx, "No rerank model configured for custom agent %s, but knowledge bases are specified", customAgent.ID)
 			return errors.New("rerank model (rerank_model_id) is not configured in custom agent settings")
@@ -1238,38 +1291,139 @@ func (s *sessionService) AgentQA(
 		llmContext = []chat.Message{}
 	}
 
-	// Create agent engine with EventBus and ContextManager
-	logger.Info(ctx, "Creating agent engine")
-	engine, err := s.agentService.CreateAgentEngine(
-		ctx,
-		agentConfig,
-		summaryModel,
-		rerankModel,
-		eventBus,
-		contextManager,
-		session.ID,
-	)
-	if err != nil {
-		logger.Errorf(ctx, "Failed to create agent engine: %v", err)
+	// Record the run so that it is checkpointed after each round and can be resumed
+	run := &types.AgentRun{
+		SessionID:     sessionID,
+		MessageID:     assistantMessageID,
+		AgentID:       customAgent.ID,
+		Query:         query,
+		Config:        *agentConfig,
+		ModelID:       effectiveModelID,
+		RerankModelID: rerankModelID,
+	}
+	if err := s.agentRunService.Start(ctx, run); err != nil {
+		logger.Errorf(ctx, "Failed to record agent run: %v", err)
 		return err
 	}
 
-	// Execute agent with streaming (asynchronously)
-	// Events will be emitted to EventBus and handled by the Handler layer
-	logger.Info(ctx, "Executing agent with streaming")
-	if _, err := engine.Execute(ctx, sessionID, assistantMessageID, query, llmContext); err != nil {
-		logger.Errorf(ctx, "Agent execution failed: %v", err)
-		// Emit error event to the EventBus used by this agent
-		eventBus.Emit(ctx, event.Event{
-			Type:      event.EventError,
-			SessionID: sessionID,
-			Data: event.ErrorData{
-				Error:     err.Error(),
-				Stage:     "agent_execution",
-				SessionID: sessionID,
-			},
+	return s.executeAgentRun(ctx, run, agentConfig, summaryModel, rerankModel, eventBus, contextManager,
+		func(ctx context.Context, engine interfaces.AgentEngine) (*types.AgentState, error) {
+			return engine.Execute(ctx, sessionID, assistantMessageID, query, llmContext)
 		})
+}
+
+// ResumeAgentQA continues an interrupted or paused agent run from its last checkpoint.
+// The run must be claimed by this instance, the events of the resumed rounds are emitted to eventBus.
+func (s *sessionService) ResumeAgentQA(
+	ctx context.Context,
+	session *types.Session,
+	run *types.AgentRun,
+	eventBus *event.EventBus,
+) error {
+	logger.Infof(ctx, "Resume agent run %s of session %s after round %d", run.ID, session.ID, run.Round)
+	ctx = types.WithUsageScope(ctx, types.UsageScope{
+		SessionID: session.ID, AgentID: run.AgentID, Source: types.UsageSourceAgentQA,
+	})
+
+	state := &types.AgentState{}
+	var messages []chat.Message
+	if len(run.State) > 0 {
+		if err := json.Unmarshal(run.State, state); err != nil {
+			return fmt.Errorf("failed to unmarshal agent state: %w", err)
+		}
+		if err := json.Unmarshal(run.Messages, &messages); err != nil {
+			return fmt.Errorf("failed to unmarshal agent messages: %w", err)
+		}
 	}
+
+	agentConfig := run.Config
+	// Search targets are not stored with the run, the knowledge bases may have changed meanwhile
+	searchTargets, err := s.buildSearchTargets(ctx, run.TenantID, agentConfig.KnowledgeBases, agentConfig.KnowledgeIDs)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to build search targets for agent: %v", err)
+	}
+	agentConfig.SearchTargets = searchTargets
+
+	summaryModel, err := s.modelService.GetChatModel(ctx, run.ModelID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat model: %w", err)
+	}
+	var rerankModel rerank.Reranker
+	if run.RerankModelID != "" {
+		rerankModel, err = s.modelService.GetRerankModel(ctx, run.RerankModelID)
+		if err != nil {
+			return fmt.Errorf("failed to get rerank model: %w", err)
+		}
+	}
+	contextManager := s.getContextManagerForSession(ctx, session, summaryModel)
+
+	return s.executeAgentRun(ctx, run, &agentConfig, summaryModel, rerankModel, eventBus, contextManager,
+		func(ctx context.Context, engine interfaces.AgentEngine) (*types.AgentState, error) {
+			// A run interrupted before its first checkpoint starts over
+			if len(messages) == 0 {
+				return engine.Execute(ctx, session.ID, run.MessageID, run.Query, nil)
+			}
+			return engine.Resume(ctx, session.ID, run.MessageID, run.Query, state, messages)
+		})
+}
+
+// executeAgentRun creates the agent engine and runs it while this instance holds the run.
+// Agent failures are reported through eventBus, only a suspended run is returned as an error.
+func (s *sessionService) executeAgentRun(
+	ctx context.Context,
+	run *types.AgentRun,
+	agentConfig *types.AgentConfig,
+	summaryModel chat.Chat,
+	rerankModel rerank.Reranker,
+	eventBus *event.EventBus,
+	contextManager interfaces.ContextManager,
+	execute func(ctx context.Context, engine interfaces.AgentEngine) (*types.AgentState, error),
+) error {
+	sessionID := run.SessionID
+	var agentErr error
+	err := s.agentRunService.Execute(ctx, run,
+		func(ctx context.Context, checkpointer interfaces.AgentCheckpointer) error {
+			// Create agent engine with EventBus and ContextManager
+			logger.Info(ctx, "Creating agent engine")
+			engine, err := s.agentService.CreateAgentEngine(
+				ctx,
+				agentConfig,
+				summaryModel,
+				rerankModel,
+				eventBus,
+				contextManager,
+				sessionID,
+				checkpointer,
+			)
+			if err != nil {
+				logger.Errorf(ctx, "Failed to create agent engine: %v", err)
+				return err
+			}
+
+			// Execute agent with streaming (asynchronously)
+			// Events will be emitted to EventBus and handled by the Handler layer
+			logger.Info(ctx, "Executing agent with streaming")
+			if _, err := execute(ctx, engine); err != nil {
+				agentErr = err
+				return err
+			}
+			return nil
+		})
+	if agentErr == nil || errors.Is(err, werrors.ErrAgentRunSuspended) {
+		return err
+	}
+
+	logger.Errorf(ctx, "Agent execution failed: %v", agentErr)
+	// Emit error event to the EventBus used by this agent
+	eventBus.Emit(ctx, event.Event{
+		Type:      event.EventError,
+		SessionID: sessionID,
+		Data: event.ErrorData{
+			Error:     agentErr.Error(),
+			Stage:     "agent_execution",
+			SessionID: sessionID,
+		},
+	})
 	// Return empty - events will be handled by Handler via EventBus subscription
 	return nil
 }
@@ -1330,6 +1484,48 @@ func (s *sessionService) ClearContext(ctx context.Context, sessionID string) err
 	return s.sessionStorage.Delete(ctx, sessionID)
 }
 
+// ImportHistory appends earlier conversation turns to a session
+// Each turn is stored as a user and assistant message pair sharing a request ID,
+// and the LLM context is extended so agent QA sees the same history
+func (s *sessionService) ImportHistory(ctx context.Context, sessionID string, history []*types.History) error {
+	if len(history) == 0 {
+		return nil
+	}
+	logger.Infof(ctx, "Importing %d history turns into session: %s", len(history), sessionID)
+
+	// Space the turns out before now so they sort ahead of the current question
+	createdAt := time.Now().Add(-time.Duration(len(history)*2) * time.Millisecond)
+	llmMessages := make([]chat.Message, 0, len(history)*2)
+	for _, turn := range history {
+		requestID := uuid.New().String()
+		for _, message := range []*types.Message{
+			{Role: "user", Content: turn.Query},
+			{Role: "assistant", Content: turn.Answer, KnowledgeReferences: turn.KnowledgeReferences},
+		} {
+			message.SessionID = sessionID
+			message.RequestID = requestID
+			message.IsCompleted = true
+			message.CreatedAt = createdAt
+			message.UpdatedAt = createdAt
+			createdAt = createdAt.Add(time.Millisecond)
+			if _, err := s.messageRepo.CreateMessage(ctx, message); err != nil {
+				logger.Errorf(ctx, "Failed to import history message, session ID: %s, error: %v", sessionID, err)
+				return err
+			}
+			llmMessages = append(llmMessages, chat.Message{Role: message.Role, Content: message.Content})
+		}
+	}
+
+	stored, err := s.sessionStorage.Load(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to load context: %w", err)
+	}
+	if err := s.sessionStorage.Save(ctx, sessionID, append(stored, llmMessages...)); err != nil {
+		return fmt.Errorf("failed to save context: %w", err)
+	}
+	return nil
+}
+
 // handleFallbackResponse handles fallback response based on strategy
 func (s *sessionService) handleFallbackResponse(ctx context.Context, chatManage *types.ChatManage) {
 	if chatManage.FallbackStrategy == types.FallbackStrategyModel {
@@ -1449,6 +1645,11 @@ func (s *sessionService) consumeFallbackStream(
 			}
 		}
 	}
+	// Drain the rest of the stream, the usage of the call is reported after the first done chunk
+	go func() {
+		for range responseChan {
+		}
+	}()
 
 	// If channel closed without Done=true, emit final event with fixed response
 	if !streamCompleted {
@@ -0,0 +1,499 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Tencent/WeKnora/internal/application/repository"
+	"github.com/Tencent/WeKnora/internal/application/service/sso"
+	"github.com/Tencent/WeKnora/internal/config"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+)
+
+const (
+	// ssoStateTTL is how long a started login can be completed
+	ssoStateTTL = 10 * time.Minute
+	// ssoStateKeyPrefix prefixes the Redis keys of started logins
+	ssoStateKeyPrefix = "sso:state:"
+	// ssoAssertionKeyPrefix prefixes the Redis keys of consumed SAML assertions
+	ssoAssertionKeyPrefix = "sso:assertion:"
+)
+
+// usernameUnsafeChars matches the characters not kept when deriving a username
+var usernameUnsafeChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// ssoLoginState is kept in Redis between the start of a login and the identity provider callback
+type ssoLoginState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce,omitempty"`
+	CodeVerifier string `json:"code_verifier,omitempty"`
+	RequestID    string `json:"request_id,omitempty"`
+}
+
+// ssoProvider is a configured identity provider
+type ssoProvider struct {
+	cfg  *config.SSOProviderConfig
+	oidc *sso.OIDCProvider
+	saml *sso.SAMLProvider
+}
+
+// ssoService implements the SSOService interface.
+//
+// Users are created without a password on their first login and linked to the provider account.
+// The tenant of a new user comes from the first tenant mapping matching the email domain or the
+// groups asserted by the provider. A successful login issues the same access and refresh tokens as
+// a password login, so token refresh works unchanged.
+type ssoService struct {
+	providers     map[string]*ssoProvider
+	order         []string
+	userRepo      interfaces.UserRepository
+	identityRepo  interfaces.UserIdentityRepository
+	userService   interfaces.UserService
+	tenantService interfaces.TenantService
+	redisClient   *redis.Client
+}
+
+// NewSSOService creates a new single sign-on service from the configured providers
+func NewSSOService(
+	cfg *config.Config,
+	userRepo interfaces.UserRepository,
+	identityRepo interfaces.UserIdentityRepository,
+	userService interfaces.UserService,
+	tenantService interfaces.TenantService,
+	redisClient *redis.Client,
+) (interfaces.SSOService, error) {
+	s := &ssoService{
+		providers:     map[string]*ssoProvider{},
+		userRepo:      userRepo,
+		identityRepo:  identityRepo,
+		userService:   userService,
+		tenantService: tenantService,
+		redisClient:   redisClient,
+	}
+	if cfg.SSO == nil {
+		return s, nil
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	for i := range cfg.SSO.Providers {
+		pc := &cfg.SSO.Providers[i]
+		if pc.Name == "" || s.providers[pc.Name] != nil {
+			return nil, fmt.Errorf("sso provider name %q is empty or duplicated", pc.Name)
+		}
+		if pc.DefaultRole != "" && !types.TenantRole(pc.DefaultRole).IsValid() {
+			return nil, fmt.Errorf("sso provider %s: invalid default_role %q", pc.Name, pc.DefaultRole)
+		}
+		for _, m := range pc.TenantMappings {
+			if m.Role != "" && !types.TenantRole(m.Role).IsValid() {
+				return nil, fmt.Errorf("sso provider %s: invalid tenant mapping role %q", pc.Name, m.Role)
+			}
+		}
+
+		p := &ssoProvider{cfg: pc}
+		var err error
+		switch pc.Type {
+		case sso.TypeOIDC:
+			p.oidc, err = sso.NewOIDCProvider(pc, client)
+		case sso.TypeSAML:
+			p.saml, err = sso.NewSAMLProvider(pc)
+		default:
+			err = fmt.Errorf("sso provider %s: unsupported type %q", pc.Name, pc.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.providers[pc.Name] = p
+		s.order = append(s.order, pc.Name)
+	}
+	return s, nil
+}
+
+// ListProviders lists the configured identity providers
+func (s *ssoService) ListProviders(ctx context.Context) []*types.SSOProviderInfo {
+	providers := make([]*types.SSOProviderInfo, 0, len(s.order))
+	for _, name := range s.order {
+		p := s.providers[name]
+		displayName := p.cfg.DisplayName
+		if displayName == "" {
+			displayName = name
+		}
+		providers = append(providers, &types.SSOProviderInfo{
+			Name:        name,
+			Type:        p.cfg.Type,
+			DisplayName: displayName,
+			LoginURL:    fmt.Sprintf("/api/v1/auth/sso/%s/login", name),
+		})
+	}
+	return providers
+}
+
+// BeginLogin starts a login and returns the identity provider URL to redirect the browser to
+func (s *ssoService) BeginLogin(ctx context.Context, name string) (*types.SSOLoginRedirect, error) {
+	p, err := s.getProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	stateKey, err := sso.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &ssoLoginState{Provider: name}
+	var authURL string
+	if p.oidc != nil {
+		if state.Nonce, err = sso.RandomToken(32); err != nil {
+			return nil, err
+		}
+		if state.CodeVerifier, err = sso.RandomToken(48); err != nil {
+			return nil, err
+		}
+		authURL, err = p.oidc.AuthCodeURL(ctx, stateKey, state.Nonce, state.CodeVerifier)
+	} else {
+		if state.RequestID, err = sso.NewSAMLRequestID(); err != nil {
+			return nil, err
+		}
+		authURL, err = p.saml.AuthnRequestURL(state.RequestID, stateKey, time.Now())
+	}
+	if err != nil {
+		logger.Errorf(ctx, "Failed to build SSO login URL for provider %s: %v", name, err)
+		return nil, err
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redisClient.Set(ctx, ssoStateKeyPrefix+stateKey, data, ssoStateTTL).Err(); err != nil {
+		return nil, fmt.Errorf("save sso login state: %w", err)
+	}
+	return &types.SSOLoginRedirect{URL: authURL, State: stateKey}, nil
+}
+
+// CompleteOIDCLogin completes an OpenID Connect login with the authorization code of the callback
+func (s *ssoService) CompleteOIDCLogin(ctx context.Context,
+	name string, code string, stateKey string,
+) (*types.LoginResponse, error) {
+	p, err := s.getProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	if p.oidc == nil {
+		return nil, werrors.NewBadRequestError("OIDC 공급자가 아닙니다")
+	}
+	if code == "" {
+		return nil, werrors.NewBadRequestError("인증 코드가 없습니다")
+	}
+	state, err := s.takeState(ctx, name, stateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := p.oidc.Exchange(ctx, code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		logger.Warnf(ctx, "OIDC login with provider %s failed: %v", name, err)
+		return nil, werrors.NewUnauthorizedError("ID 공급자 인증에 실패했습니다").WithDetails(err.Error())
+	}
+	return s.login(ctx, p, identity)
+}
+
+// CompleteSAMLLogin completes a SAML login with the response posted to the assertion consumer service
+func (s *ssoService) CompleteSAMLLogin(ctx context.Context,
+	name string, samlResponse string, relayState string,
+) (*types.LoginResponse, error) {
+	p, err := s.getProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	if p.saml == nil {
+		return nil, werrors.NewBadRequestError("SAML 공급자가 아닙니다")
+	}
+	if samlResponse == "" {
+		return nil, werrors.NewBadRequestError("SAML 응답이 없습니다")
+	}
+	state, err := s.takeState(ctx, name, relayState)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, err := p.saml.ParseResponse(samlResponse, state.RequestID, time.Now())
+	if err != nil {
+		logger.Warnf(ctx, "SAML login with provider %s failed: %v", name, err)
+		return nil, werrors.NewUnauthorizedError("ID 공급자 인증에 실패했습니다").WithDetails(err.Error())
+	}
+
+	// An assertion can only be used once while it is valid
+	ttl := time.Until(assertion.ExpiresAt)
+	if ttl <= 0 {
+		ttl = ssoStateTTL
+	}
+	fresh, err := s.redisClient.SetNX(ctx, ssoAssertionKeyPrefix+name+":"+assertion.ID, 1, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("record saml assertion: %w", err)
+	}
+	if !fresh {
+		return nil, werrors.NewUnauthorizedError("이미 사용된 SAML 어설션입니다")
+	}
+	return s.login(ctx, p, assertion.Identity)
+}
+
+// SAMLMetadata returns the service provider metadata of a SAML provider
+func (s *ssoService) SAMLMetadata(ctx context.Context, name string) ([]byte, error) {
+	p, err := s.getProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	if p.saml == nil {
+		return nil, werrors.NewBadRequestError("SAML 공급자가 아닙니다")
+	}
+	return p.saml.Metadata()
+}
+
+func (s *ssoService) getProvider(name string) (*ssoProvider, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, werrors.NewNotFoundError("SSO 공급자를 찾을 수 없습니다")
+	}
+	return p, nil
+}
+
+// takeState loads and deletes the state of a started login, so each login completes at most once
+func (s *ssoService) takeState(ctx context.Context, name string, stateKey string) (*ssoLoginState, error) {
+	if stateKey == "" {
+		return nil, werrors.NewBadRequestError("로그인 상태가 없습니다")
+	}
+	data, err := s.redisClient.GetDel(ctx, ssoStateKeyPrefix+stateKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, werrors.NewBadRequestError("로그인 상태가 만료되었거나 유효하지 않습니다")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load sso login state: %w", err)
+	}
+	var state ssoLoginState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decode sso login state: %w", err)
+	}
+	if state.Provider != name {
+		return nil, werrors.NewBadRequestError("로그인 상태가 만료되었거나 유효하지 않습니다")
+	}
+	return &state, nil
+}
+
+// login resolves the user of a verified identity and issues tokens
+func (s *ssoService) login(ctx context.Context,
+	p *ssoProvider, identity *sso.Identity,
+) (*types.LoginResponse, error) {
+	if identity.Subject == "" {
+		return nil, werrors.NewUnauthorizedError("ID 공급자가 사용자 식별자를 제공하지 않았습니다")
+	}
+	user, err := s.resolveUser(ctx, p, identity)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, werrors.NewForbiddenError("비활성화된 계정입니다")
+	}
+
+	accessToken, refreshToken, err := s.userService.GenerateTokens(ctx, user)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to generate tokens: %v", err)
+		return nil, err
+	}
+	tenant, err := s.tenantService.GetTenantByID(ctx, user.TenantID)
+	if err != nil {
+		logger.Warn(ctx, "Failed to get tenant info")
+	}
+
+	logger.Infof(ctx, "User %s logged in with SSO provider %s", user.ID, p.cfg.Name)
+	return &types.LoginResponse{
+		Success:      true,
+		Message:      "Login successful",
+		User:         user,
+		Tenant:       tenant,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// resolveUser finds the user linked to the provider account. An unlinked account is linked to the
+// user with the same verified email when the tenant mapping of the provider covers that user,
+// or a new user is provisioned.
+func (s *ssoService) resolveUser(ctx context.Context,
+	p *ssoProvider, identity *sso.Identity,
+) (*types.User, error) {
+	now := time.Now()
+	link, err := s.identityRepo.GetIdentity(ctx, p.cfg.Name, identity.Subject)
+	switch {
+	case err == nil:
+		user, err := s.userRepo.GetUserByID(ctx, link.UserID)
+		if err == nil {
+			s.syncRole(ctx, p, identity, user)
+			link.Email = identity.Email
+			link.LastLoginAt = &now
+			if err := s.identityRepo.UpdateIdentity(ctx, link); err != nil {
+				logger.Warnf(ctx, "Failed to update user identity %s: %v", link.ID, err)
+			}
+			return user, nil
+		}
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, err
+		}
+		// The linked user was deleted, provision the account again
+		if err := s.identityRepo.DeleteIdentity(ctx, link.ID); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, repository.ErrUserIdentityNotFound):
+		return nil, err
+	}
+
+	var user *types.User
+	if identity.Email != "" && identity.EmailVerified {
+		existing, err := s.userRepo.GetUserByEmail(ctx, identity.Email)
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, err
+		}
+		if existing != nil {
+			if sso.CanLinkUser(p.cfg.TenantMappings, identity, existing.TenantID) {
+				user = existing
+			} else {
+				logger.Warnf(ctx, "SSO provider %s may not link user %s of tenant %d",
+					p.cfg.Name, existing.ID, existing.TenantID)
+			}
+		}
+	}
+	if user == nil {
+		if user, err = s.provisionUser(ctx, p, identity); err != nil {
+			return nil, err
+		}
+	}
+
+	link = &types.UserIdentity{
+		UserID:      user.ID,
+		Provider:    p.cfg.Name,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+	}
+	if err := s.identityRepo.CreateIdentity(ctx, link); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// provisionUser creates a user without password in the tenant mapped from the identity
+func (s *ssoService) provisionUser(ctx context.Context,
+	p *ssoProvider, identity *sso.Identity,
+) (*types.User, error) {
+	if identity.Email == "" {
+		return nil, werrors.NewBadRequestError("ID 공급자가 이메일 주소를 제공하지 않았습니다")
+	}
+	if existing, _ := s.userRepo.GetUserByEmail(ctx, identity.Email); existing != nil {
+		return nil, werrors.NewConflictError("같은 이메일의 계정이 이미 있습니다. 관리자에게 문의하세요")
+	}
+	username, err := s.uniqueUsername(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenantID uint64
+	role := types.TenantRole(p.cfg.DefaultRole)
+	if role == "" {
+		role = types.TenantRoleViewer
+	}
+	if mapping := sso.MatchTenantMapping(p.cfg.TenantMappings, identity); mapping != nil {
+		if _, err := s.tenantService.GetTenantByID(ctx, mapping.TenantID); err != nil {
+			logger.Errorf(ctx, "Tenant %d of SSO provider %s mapping not found: %v", mapping.TenantID, p.cfg.Name, err)
+			return nil, werrors.NewForbiddenError("매핑된 테넌트를 찾을 수 없습니다")
+		}
+		tenantID = mapping.TenantID
+		if mapping.Role != "" {
+			role = types.TenantRole(mapping.Role)
+		}
+	} else if p.cfg.CreateTenant {
+		tenant, err := s.tenantService.CreateTenant(ctx, &types.Tenant{
+			Name:        fmt.Sprintf("%s's Workspace", secutils.SanitizeForLog(username)),
+			Description: "Default workspace",
+			Status:      "active",
+		})
+		if err != nil {
+			logger.Errorf(ctx, "Failed to create tenant")
+			return nil, errors.New("failed to create workspace")
+		}
+		tenantID = tenant.ID
+		role = types.TenantRoleOwner
+	} else {
+		return nil, werrors.NewForbiddenError("이 계정에 매핑된 테넌트가 없습니다")
+	}
+
+	user := &types.User{
+		ID:        uuid.New().String(),
+		Username:  username,
+		Email:     identity.Email,
+		TenantID:  tenantID,
+		Role:      role,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		logger.Errorf(ctx, "Failed to create user: %v", err)
+		return nil, errors.New("failed to create user")
+	}
+	logger.Infof(ctx, "Provisioned user %s from SSO provider %s in tenant %d", user.ID, p.cfg.Name, tenantID)
+	return user, nil
+}
+
+// syncRole applies the role of the matching tenant mapping to a returning user of that tenant.
+// Owners are managed in the application and keep their role.
+func (s *ssoService) syncRole(ctx context.Context, p *ssoProvider, identity *sso.Identity, user *types.User) {
+	mapping := sso.MatchTenantMapping(p.cfg.TenantMappings, identity)
+	if mapping == nil || mapping.Role == "" || mapping.TenantID != user.TenantID ||
+		user.Role == types.TenantRoleOwner || user.Role == types.TenantRole(mapping.Role) {
+		return
+	}
+	user.Role = types.TenantRole(mapping.Role)
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		logger.Warnf(ctx, "Failed to sync role of user %s: %v", user.ID, err)
+	}
+}
+
+// uniqueUsername derives an unused username from the identity
+func (s *ssoService) uniqueUsername(ctx context.Context, identity *sso.Identity) (string, error) {
+	base := identity.Email
+	if at := strings.LastIndex(base, "@"); at > 0 {
+		base = base[:at]
+	}
+	base = strings.Trim(usernameUnsafeChars.ReplaceAllString(base, "-"), "-")
+	if len(base) < 3 {
+		base = "user-" + base
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		existing, err := s.userRepo.GetUserByUsername(ctx, candidate)
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+		candidate = base + "-" + uuid.New().String()[:6]
+	}
+	return "", werrors.NewConflictError("사용자 이름을 만들 수 없습니다")
+}
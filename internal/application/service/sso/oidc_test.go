@@ -0,0 +1,242 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Tencent/WeKnora/internal/config"
+)
+
+// testOIDCIdP is a stand-in OpenID provider that issues a code for a fixed user
+type testOIDCIdP struct {
+	t         *testing.T
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+func newTestOIDCIdP(t *testing.T) *testOIDCIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &testOIDCIdP{t: t, key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "client" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		if r.PostFormValue("code") != "good-code" || PKCEChallenge(r.PostFormValue("code_verifier")) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   "client",
+			"sub":   "user-1",
+			"email": "alice@example.com",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": idp.nonce,
+		}
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(idp.key)
+		if err != nil {
+			t.Error(err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "id_token": signed})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize plays the authorization endpoint by recording the PKCE challenge and nonce of the login URL
+func (idp *testOIDCIdP) authorize(authURL string) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	if got := u.Query().Get("code_challenge_method"); got != "S256" {
+		idp.t.Fatalf("code_challenge_method = %q, want S256", got)
+	}
+	idp.challenge = u.Query().Get("code_challenge")
+	idp.nonce = u.Query().Get("nonce")
+}
+
+func newTestOIDCProvider(t *testing.T, idp *testOIDCIdP) *OIDCProvider {
+	t.Helper()
+	p, err := NewOIDCProvider(&config.SSOProviderConfig{
+		Name:         "corp",
+		Type:         TypeOIDC,
+		Issuer:       idp.server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/api/v1/auth/sso/corp/callback",
+		GroupsClaim:  "realm.groups",
+	}, idp.server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestOIDCProvider_Login(t *testing.T) {
+	idp := newTestOIDCIdP(t)
+	idp.claims = jwt.MapClaims{
+		"email_verified": true,
+		"name":           "Alice",
+		"realm":          map[string]interface{}{"groups": []interface{}{"eng", "ops"}},
+	}
+	p := newTestOIDCProvider(t, idp)
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	if !strings.HasPrefix(authURL, idp.server.URL+"/authorize?") || !strings.Contains(authURL, "state=state-1") {
+		t.Fatalf("AuthCodeURL() = %s", authURL)
+	}
+	idp.authorize(authURL)
+
+	identity, err := p.Exchange(ctx, "good-code", "verifier-1", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if identity.Subject != "user-1" || identity.Email != "alice@example.com" || !identity.EmailVerified ||
+		identity.Name != "Alice" || strings.Join(identity.Groups, ",") != "eng,ops" {
+		t.Fatalf("Exchange() identity = %+v", identity)
+	}
+}
+
+func TestOIDCProvider_Rejects(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		verifier string
+		nonce    string
+	}{
+		{name: "wrong code verifier", verifier: "other", nonce: "nonce-1"},
+		{name: "wrong nonce", verifier: "verifier-1", nonce: "nonce-2"},
+		{name: "wrong audience", claims: jwt.MapClaims{"aud": "someone-else"}, verifier: "verifier-1", nonce: "nonce-1"},
+		{name: "wrong issuer", claims: jwt.MapClaims{"iss": "https://evil"}, verifier: "verifier-1", nonce: "nonce-1"},
+		{
+			name:     "expired",
+			claims:   jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()},
+			verifier: "verifier-1",
+			nonce:    "nonce-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newTestOIDCIdP(t)
+			idp.claims = tt.claims
+			p := newTestOIDCProvider(t, idp)
+			authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			idp.authorize(authURL)
+			if _, err := p.Exchange(ctx, "good-code", tt.verifier, tt.nonce); err == nil {
+				t.Fatal("Exchange() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestMatchTenantMapping(t *testing.T) {
+	mappings := []config.SSOTenantMapping{
+		{EmailDomain: "example.com", Group: "admins", TenantID: 1, Role: "admin"},
+		{Group: "contractors", TenantID: 2, Role: "viewer"},
+		{EmailDomain: "@Example.com", TenantID: 3, Role: "editor"},
+	}
+	tests := []struct {
+		email  string
+		groups []string
+		want   uint64
+	}{
+		{email: "a@example.com", groups: []string{"admins"}, want: 1},
+		{email: "a@EXAMPLE.com", want: 3},
+		{email: "a@other.com", groups: []string{"contractors"}, want: 2},
+		{email: "a@other.com", groups: []string{"admins"}, want: 0},
+	}
+	for _, tt := range tests {
+		got := MatchTenantMapping(mappings, &Identity{Email: tt.email, Groups: tt.groups})
+		var gotID uint64
+		if got != nil {
+			gotID = got.TenantID
+		}
+		if gotID != tt.want {
+			t.Errorf("MatchTenantMapping(%s, %v) = %d, want %d", tt.email, tt.groups, gotID, tt.want)
+		}
+	}
+}
+
+func TestCanLinkUser(t *testing.T) {
+	mappings := []config.SSOTenantMapping{
+		{EmailDomain: "example.com", TenantID: 1, Role: "editor"},
+		{Group: "contractors", TenantID: 2, Role: "viewer"},
+	}
+	tests := []struct {
+		name     string
+		identity *Identity
+		tenantID uint64
+		want     bool
+	}{
+		{"mapped tenant and domain", &Identity{Email: "alice@example.com", EmailVerified: true}, 1, true},
+		{"user of another tenant", &Identity{Email: "admin@example.com", EmailVerified: true}, 7, false},
+		{"unverified email", &Identity{Email: "alice@example.com"}, 1, false},
+		{"unmapped domain", &Identity{Email: "admin@victim.com", EmailVerified: true}, 1, false},
+		{"group mapping without domain",
+			&Identity{Email: "bob@other.com", EmailVerified: true, Groups: []string{"contractors"}}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanLinkUser(mappings, tt.identity, tt.tenantID); got != tt.want {
+				t.Errorf("CanLinkUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package sso
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/config"
+)
+
+// SAML namespaces, bindings and status codes
+const (
+	nsSAMLProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsSAMLAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+
+	bindingHTTPPost       = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	statusSuccess         = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	confirmationBearer    = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	nameIDFormatEmail     = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	nameIDFormatTransient = "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"
+	nameIDFormatUnspec    = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+
+	// samlClockSkew is the tolerated clock difference to the identity provider
+	samlClockSkew = 2 * time.Minute
+	// maxSAMLResponseSize limits the size of a decoded SAML response
+	maxSAMLResponseSize = 1 << 20
+)
+
+// defaultSAMLEmailAttributes are the attribute names commonly used for the email address
+var defaultSAMLEmailAttributes = []string{
+	"email",
+	"mail",
+	"urn:oid:0.9.2342.19200300.100.1.3",
+	"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+}
+
+// SAMLAssertion is the verified content of a SAML assertion
+type SAMLAssertion struct {
+	// ID is the assertion ID, used to reject replayed assertions
+	ID string
+	// ExpiresAt is the time after which the assertion is no longer accepted
+	ExpiresAt time.Time
+	Identity  *Identity
+}
+
+// SAMLProvider is a SAML 2.0 service provider using the HTTP-Redirect binding for requests and the
+// HTTP-POST binding for responses
+type SAMLProvider struct {
+	cfg   *config.SSOProviderConfig
+	certs []*x509.Certificate
+}
+
+// NewSAMLProvider creates a SAML service provider
+func NewSAMLProvider(cfg *config.SSOProviderConfig) (*SAMLProvider, error) {
+	if cfg.EntityID == "" || cfg.ACSURL == "" || cfg.IdPSSOURL == "" || cfg.IdPCertificate == "" {
+		return nil, fmt.Errorf(
+			"saml provider %s: entity_id, acs_url, idp_sso_url and idp_certificate are required", cfg.Name)
+	}
+	certs, err := parseCertificates(cfg.IdPCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("saml provider %s: %w", cfg.Name, err)
+	}
+	return &SAMLProvider{cfg: cfg, certs: certs}, nil
+}
+
+// parseCertificates parses PEM certificates, a bare base64 certificate as found in IdP metadata is accepted too
+func parseCertificates(data string) ([]*x509.Certificate, error) {
+	data = strings.TrimSpace(data)
+	if !strings.Contains(data, "-----BEGIN") {
+		der, err := decodeBase64(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid idp certificate: %w", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("invalid idp certificate: %w", err)
+		}
+		return []*x509.Certificate{cert}, nil
+	}
+
+	var certs []*x509.Certificate
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid idp certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no idp certificate found")
+	}
+	return certs, nil
+}
+
+// AuthnRequestURL returns the IdP URL carrying an AuthnRequest with the HTTP-Redirect binding
+func (p *SAMLProvider) AuthnRequestURL(requestID, relayState string, now time.Time) (string, error) {
+	var request bytes.Buffer
+	request.WriteString(`<samlp:AuthnRequest xmlns:samlp="` + nsSAMLProtocol + `" xmlns:saml="` + nsSAMLAssertion + `"`)
+	writeXMLAttr(&request, "ID", requestID)
+	writeXMLAttr(&request, "Version", "2.0")
+	writeXMLAttr(&request, "IssueInstant", now.UTC().Format(time.RFC3339))
+	writeXMLAttr(&request, "Destination", p.cfg.IdPSSOURL)
+	writeXMLAttr(&request, "AssertionConsumerServiceURL", p.cfg.ACSURL)
+	writeXMLAttr(&request, "ProtocolBinding", bindingHTTPPost)
+	request.WriteString(`><saml:Issuer>`)
+	_ = xml.EscapeText(&request, []byte(p.cfg.EntityID))
+	request.WriteString(`</saml:Issuer><samlp:NameIDPolicy AllowCreate="true"`)
+	writeXMLAttr(&request, "Format", nameIDFormatUnspec)
+	request.WriteString(`/></samlp:AuthnRequest>`)
+
+	var compressed bytes.Buffer
+	w, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(request.Bytes()); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	ssoURL, err := url.Parse(p.cfg.IdPSSOURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid idp sso url: %w", err)
+	}
+	query := ssoURL.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(compressed.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	ssoURL.RawQuery = query.Encode()
+	return ssoURL.String(), nil
+}
+
+func writeXMLAttr(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(" " + name + `="`)
+	_ = xml.EscapeText(buf, []byte(value))
+	buf.WriteByte('"')
+}
+
+// ParseResponse verifies a base64 encoded SAML response received with the HTTP-POST binding.
+// The response must answer the request with the given ID; unsolicited responses are rejected.
+func (p *SAMLProvider) ParseResponse(encoded, requestID string, now time.Time) (*SAMLAssertion, error) {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saml response encoding: %w", err)
+	}
+	if len(data) > maxSAMLResponseSize {
+		return nil, errors.New("saml response is too large")
+	}
+	response, err := parseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saml response: %w", err)
+	}
+	if !response.is(nsSAMLProtocol, "Response") {
+		return nil, errors.New("not a saml response")
+	}
+	if response.attr("Version") != "2.0" {
+		return nil, errors.New("unsupported saml version")
+	}
+	if response.attr("InResponseTo") != requestID {
+		return nil, errors.New("saml response does not answer the login request")
+	}
+	if dest := response.attr("Destination"); dest != "" && dest != p.cfg.ACSURL {
+		return nil, fmt.Errorf("saml response destination %q does not match", dest)
+	}
+	if err := p.checkIssuer(response); err != nil {
+		return nil, err
+	}
+
+	status := response.child(nsSAMLProtocol, "Status")
+	var statusCode *xmlNode
+	if status != nil {
+		statusCode = status.child(nsSAMLProtocol, "StatusCode")
+	}
+	if statusCode == nil || statusCode.attr("Value") != statusSuccess {
+		code := ""
+		if statusCode != nil {
+			code = statusCode.attr("Value")
+		}
+		return nil, fmt.Errorf("saml login failed with status %q", code)
+	}
+
+	if len(response.childrenNamed(nsSAMLAssertion, "EncryptedAssertion")) > 0 {
+		return nil, errors.New("encrypted assertions are not supported")
+	}
+	assertions := response.childrenNamed(nsSAMLAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, errors.New("saml response must contain exactly one assertion")
+	}
+	assertion := assertions[0]
+
+	// Either the response or the assertion must be signed, every present signature must be valid
+	responseErr := verifyEnvelopedSignature(response, p.certs)
+	if responseErr != nil && !errors.Is(responseErr, errNotSigned) {
+		return nil, fmt.Errorf("invalid response signature: %w", responseErr)
+	}
+	assertionErr := verifyEnvelopedSignature(assertion, p.certs)
+	if assertionErr != nil && !errors.Is(assertionErr, errNotSigned) {
+		return nil, fmt.Errorf("invalid assertion signature: %w", assertionErr)
+	}
+	if responseErr != nil && assertionErr != nil {
+		return nil, errors.New("saml response is not signed")
+	}
+
+	return p.readAssertion(assertion, requestID, now)
+}
+
+// checkIssuer compares the issuer of a response or assertion with the configured IdP entity ID
+func (p *SAMLProvider) checkIssuer(n *xmlNode) error {
+	issuer := n.child(nsSAMLAssertion, "Issuer")
+	if issuer == nil || p.cfg.IdPEntityID == "" {
+		return nil
+	}
+	if value := strings.TrimSpace(issuer.textContent()); value != p.cfg.IdPEntityID {
+		return fmt.Errorf("unexpected saml issuer %q", value)
+	}
+	return nil
+}
+
+// readAssertion checks the conditions and subject confirmation of a verified assertion and reads the identity
+func (p *SAMLProvider) readAssertion(assertion *xmlNode, requestID string, now time.Time) (*SAMLAssertion, error) {
+	if assertion.attr("ID") == "" {
+		return nil, errors.New("assertion has no id")
+	}
+	if assertion.child(nsSAMLAssertion, "Issuer") == nil {
+		return nil, errors.New("assertion has no issuer")
+	}
+	if err := p.checkIssuer(assertion); err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := p.checkConditions(assertion.child(nsSAMLAssertion, "Conditions"), now)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := assertion.child(nsSAMLAssertion, "Subject")
+	if subject == nil {
+		return nil, errors.New("assertion has no subject")
+	}
+	confirmationExpiresAt, err := p.checkSubjectConfirmation(subject, requestID, now)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.IsZero() || confirmationExpiresAt.Before(expiresAt) {
+		expiresAt = confirmationExpiresAt
+	}
+
+	nameID := subject.child(nsSAMLAssertion, "NameID")
+	if nameID == nil || strings.TrimSpace(nameID.textContent()) == "" {
+		return nil, errors.New("assertion has no name id")
+	}
+	if nameID.attr("Format") == nameIDFormatTransient {
+		return nil, errors.New("transient name ids cannot identify a user")
+	}
+
+	attributes := readSAMLAttributes(assertion)
+	identity := &Identity{
+		Subject: strings.TrimSpace(nameID.textContent()),
+		// SAML has no standard claim for verified emails, the provider configuration decides
+		EmailVerified: p.cfg.TrustEmail,
+	}
+	emailNames := defaultSAMLEmailAttributes
+	if p.cfg.EmailClaim != "" {
+		emailNames = []string{p.cfg.EmailClaim}
+	}
+	for _, name := range emailNames {
+		if values := attributes[name]; len(values) > 0 {
+			identity.Email = values[0]
+			break
+		}
+	}
+	if identity.Email == "" && (nameID.attr("Format") == nameIDFormatEmail || strings.Contains(identity.Subject, "@")) {
+		identity.Email = identity.Subject
+	}
+	if values := attributes[orDefault(p.cfg.NameClaim, "name")]; len(values) > 0 {
+		identity.Name = values[0]
+	} else if values := attributes["displayName"]; len(values) > 0 {
+		identity.Name = values[0]
+	}
+	identity.Groups = attributes[orDefault(p.cfg.GroupsClaim, "groups")]
+
+	return &SAMLAssertion{ID: assertion.attr("ID"), ExpiresAt: expiresAt, Identity: identity}, nil
+}
+
+// checkConditions checks the validity period and audience of an assertion
+func (p *SAMLProvider) checkConditions(conditions *xmlNode, now time.Time) (time.Time, error) {
+	if conditions == nil {
+		return time.Time{}, nil
+	}
+	if v := conditions.attr("NotBefore"); v != "" {
+		notBefore, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid NotBefore: %w", err)
+		}
+		if now.Add(samlClockSkew).Before(notBefore) {
+			return time.Time{}, errors.New("assertion is not yet valid")
+		}
+	}
+	var expiresAt time.Time
+	if v := conditions.attr("NotOnOrAfter"); v != "" {
+		notOnOrAfter, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid NotOnOrAfter: %w", err)
+		}
+		if !now.Add(-samlClockSkew).Before(notOnOrAfter) {
+			return time.Time{}, errors.New("assertion has expired")
+		}
+		expiresAt = notOnOrAfter
+	}
+	for _, restriction := range conditions.childrenNamed(nsSAMLAssertion, "AudienceRestriction") {
+		matched := false
+		for _, audience := range restriction.childrenNamed(nsSAMLAssertion, "Audience") {
+			if strings.TrimSpace(audience.textContent()) == p.cfg.EntityID {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return time.Time{}, errors.New("assertion is not intended for this service provider")
+		}
+	}
+	return expiresAt, nil
+}
+
+// checkSubjectConfirmation requires a bearer confirmation for this request, ACS URL and time
+func (p *SAMLProvider) checkSubjectConfirmation(subject *xmlNode, requestID string, now time.Time) (time.Time, error) {
+	for _, confirmation := range subject.childrenNamed(nsSAMLAssertion, "SubjectConfirmation") {
+		if confirmation.attr("Method") != confirmationBearer {
+			continue
+		}
+		data := confirmation.child(nsSAMLAssertion, "SubjectConfirmationData")
+		if data == nil {
+			continue
+		}
+		if data.attr("InResponseTo") != requestID || data.attr("Recipient") != p.cfg.ACSURL {
+			continue
+		}
+		if data.attr("NotBefore") != "" {
+			continue
+		}
+		notOnOrAfter, err := time.Parse(time.RFC3339Nano, data.attr("NotOnOrAfter"))
+		if err != nil || !now.Add(-samlClockSkew).Before(notOnOrAfter) {
+			continue
+		}
+		return notOnOrAfter, nil
+	}
+	return time.Time{}, errors.New("assertion has no valid bearer subject confirmation")
+}
+
+// readSAMLAttributes reads the attribute statements, attributes are keyed by name and friendly name
+func readSAMLAttributes(assertion *xmlNode) map[string][]string {
+	attributes := map[string][]string{}
+	for _, statement := range assertion.childrenNamed(nsSAMLAssertion, "AttributeStatement") {
+		for _, attribute := range statement.childrenNamed(nsSAMLAssertion, "Attribute") {
+			var values []string
+			for _, value := range attribute.childrenNamed(nsSAMLAssertion, "AttributeValue") {
+				if v := strings.TrimSpace(value.textContent()); v != "" {
+					values = append(values, v)
+				}
+			}
+			for _, name := range []string{attribute.attr("Name"), attribute.attr("FriendlyName")} {
+				if name != "" {
+					attributes[name] = append(attributes[name], values...)
+				}
+			}
+		}
+	}
+	return attributes
+}
+
+// Metadata returns the service provider metadata to register at the identity provider
+func (p *SAMLProvider) Metadata() ([]byte, error) {
+	type acs struct {
+		Binding  string `xml:"Binding,attr"`
+		Location string `xml:"Location,attr"`
+		Index    int    `xml:"index,attr"`
+	}
+	type spDescriptor struct {
+		AuthnRequestsSigned        bool   `xml:"AuthnRequestsSigned,attr"`
+		WantAssertionsSigned       bool   `xml:"WantAssertionsSigned,attr"`
+		ProtocolSupportEnumeration string `xml:"protocolSupportEnumeration,attr"`
+		NameIDFormat               string `xml:"NameIDFormat"`
+		AssertionConsumerService   acs    `xml:"AssertionConsumerService"`
+	}
+	type entityDescriptor struct {
+		XMLName         xml.Name     `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+		EntityID        string       `xml:"entityID,attr"`
+		SPSSODescriptor spDescriptor `xml:"SPSSODescriptor"`
+	}
+
+	metadata := entityDescriptor{
+		EntityID: p.cfg.EntityID,
+		SPSSODescriptor: spDescriptor{
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: nsSAMLProtocol,
+			NameIDFormat:               nameIDFormatUnspec,
+			AssertionConsumerService:   acs{Binding: bindingHTTPPost, Location: p.cfg.ACSURL, Index: 0},
+		},
+	}
+	out, err := xml.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,310 @@
+package sso
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/config"
+)
+
+const (
+	testACSURL   = "https://weknora.example.com/api/v1/auth/sso/corp/acs"
+	testEntityID = "https://weknora.example.com"
+	testIdPID    = "https://idp.example.com"
+)
+
+// testSAMLIdP is a stand-in identity provider that signs assertions with a self-signed certificate
+type testSAMLIdP struct {
+	key     *rsa.PrivateKey
+	certPEM string
+}
+
+func newTestSAMLIdP(t *testing.T) *testSAMLIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testSAMLIdP{
+		key:     key,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+// assertion builds an assertion answering the request, with {SIG} marking the signature position
+func (idp *testSAMLIdP) assertion(requestID string, now time.Time, email string) string {
+	return `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_a1" Version="2.0" IssueInstant="` +
+		now.UTC().Format(time.RFC3339) + `">` +
+		`<saml:Issuer>` + testIdPID + `</saml:Issuer>{SIG}` +
+		`<saml:Subject><saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:persistent">u-42</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+		`<saml:SubjectConfirmationData InResponseTo="` + requestID + `" Recipient="` + testACSURL +
+		`" NotOnOrAfter="` + now.Add(5*time.Minute).UTC().Format(time.RFC3339) + `"/>` +
+		`</saml:SubjectConfirmation></saml:Subject>` +
+		`<saml:Conditions NotBefore="` + now.Add(-time.Minute).UTC().Format(time.RFC3339) +
+		`" NotOnOrAfter="` + now.Add(10*time.Minute).UTC().Format(time.RFC3339) + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + testEntityID + `</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute Name="email"><saml:AttributeValue>` + email + `</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="groups"><saml:AttributeValue>eng</saml:AttributeValue>` +
+		`<saml:AttributeValue>ops</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement></saml:Assertion>`
+}
+
+// sign replaces {SIG} with an enveloped signature over the element with the given ID
+func (idp *testSAMLIdP) sign(t *testing.T, element, id string) string {
+	t.Helper()
+	unsigned, err := parseXML([]byte(strings.Replace(element, "{SIG}", "", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := canonicalize(unsigned, nil, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(canonical)
+	signedInfo := `<ds:SignedInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"/>` +
+		`<ds:Reference URI="#` + id + `"><ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/></ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference></ds:SignedInfo>`
+	signedInfoNode, err := parseXML([]byte(signedInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonicalSignedInfo, err := canonicalize(signedInfoNode, nil, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed := sha256.Sum256(canonicalSignedInfo)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` + signedInfo +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(sig) + `</ds:SignatureValue></ds:Signature>`
+	return strings.Replace(element, "{SIG}", signature, 1)
+}
+
+func testSAMLResponse(requestID string, assertions ...string) string {
+	response := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="_r1" Version="2.0" ` +
+		`InResponseTo="` + requestID + `" Destination="` + testACSURL + `">` +
+		`<saml:Issuer xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">` + testIdPID + `</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` +
+		strings.Join(assertions, "") + `</samlp:Response>`
+	return base64.StdEncoding.EncodeToString([]byte(response))
+}
+
+func newTestSAMLProvider(t *testing.T, idp *testSAMLIdP) *SAMLProvider {
+	t.Helper()
+	p, err := NewSAMLProvider(&config.SSOProviderConfig{
+		Name:           "corp",
+		Type:           TypeSAML,
+		EntityID:       testEntityID,
+		ACSURL:         testACSURL,
+		IdPEntityID:    testIdPID,
+		IdPSSOURL:      "https://idp.example.com/sso",
+		IdPCertificate: idp.certPEM,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestCanonicalize(t *testing.T) {
+	doc := `<a:root xmlns:a="urn:a" xmlns:b="urn:b" xmlns="urn:d">` +
+		`<a:child b:attr="1" z="2" a:y="3">t &amp; &lt; <!-- note --></a:child><b:x/></a:root>`
+	root, err := parseXML([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := canonicalize(root, nil, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<a:root xmlns:a="urn:a"><a:child xmlns:b="urn:b" z="2" a:y="3" b:attr="1">t &amp; &lt; </a:child>` +
+		`<b:x xmlns:b="urn:b"></b:x></a:root>`
+	if string(got) != want {
+		t.Fatalf("canonicalize() =\n%s\nwant\n%s", got, want)
+	}
+
+	got, err = canonicalize(root.elements()[1], nil, []string{"#default"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<b:x xmlns="urn:d" xmlns:b="urn:b"></b:x>`; string(got) != want {
+		t.Fatalf("canonicalize() with inclusive namespaces = %s, want %s", got, want)
+	}
+}
+
+func TestSAMLProvider_AuthnRequestURL(t *testing.T) {
+	p := newTestSAMLProvider(t, newTestSAMLIdP(t))
+	authURL, err := p.AuthnRequestURL("_req1", "relay", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Query().Get("RelayState") != "relay" {
+		t.Fatalf("RelayState = %q", u.Query().Get("RelayState"))
+	}
+	compressed, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := parseXML(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !root.is(nsSAMLProtocol, "AuthnRequest") || root.attr("ID") != "_req1" ||
+		root.attr("AssertionConsumerServiceURL") != testACSURL {
+		t.Fatalf("unexpected AuthnRequest %s", request)
+	}
+}
+
+func TestSAMLProvider_ParseResponse(t *testing.T) {
+	idp := newTestSAMLIdP(t)
+	p := newTestSAMLProvider(t, idp)
+	now := time.Now()
+
+	signed := idp.sign(t, idp.assertion("_req1", now, "alice@example.com"), "_a1")
+	assertion, err := p.ParseResponse(testSAMLResponse("_req1", signed), "_req1", now)
+	if err != nil {
+		t.Fatalf("ParseResponse() error = %v", err)
+	}
+	identity := assertion.Identity
+	if assertion.ID != "_a1" || identity.Subject != "u-42" || identity.Email != "alice@example.com" ||
+		strings.Join(identity.Groups, ",") != "eng,ops" {
+		t.Fatalf("ParseResponse() = %+v, identity %+v", assertion, identity)
+	}
+}
+
+// A SAML email is not trusted unless the provider is configured to, so an IdP user who can set their own
+// email attribute cannot take over the local account of another tenant with that email
+func TestSAMLProvider_EmailTrust(t *testing.T) {
+	idp := newTestSAMLIdP(t)
+	p := newTestSAMLProvider(t, idp)
+	now := time.Now()
+	mappings := []config.SSOTenantMapping{{EmailDomain: "example.com", TenantID: 1}}
+
+	signed := idp.sign(t, idp.assertion("_req1", now, "admin@victim.com"), "_a1")
+	assertion, err := p.ParseResponse(testSAMLResponse("_req1", signed), "_req1", now)
+	if err != nil {
+		t.Fatalf("ParseResponse() error = %v", err)
+	}
+	if assertion.Identity.EmailVerified {
+		t.Error("SAML email should not be verified by default")
+	}
+	if CanLinkUser(mappings, assertion.Identity, 2) {
+		t.Error("unverified SAML email should not link to an existing user")
+	}
+
+	p.cfg.TrustEmail = true
+	assertion, err = p.ParseResponse(testSAMLResponse("_req2",
+		idp.sign(t, idp.assertion("_req2", now, "admin@victim.com"), "_a1")), "_req2", now)
+	if err != nil {
+		t.Fatalf("ParseResponse() error = %v", err)
+	}
+	if !assertion.Identity.EmailVerified {
+		t.Error("SAML email should be verified when the provider trusts it")
+	}
+	if CanLinkUser(mappings, assertion.Identity, 2) {
+		t.Error("trusted SAML email outside the mapped domain and tenant should not link to an existing user")
+	}
+}
+
+func TestSAMLProvider_ParseResponseRejects(t *testing.T) {
+	idp := newTestSAMLIdP(t)
+	p := newTestSAMLProvider(t, idp)
+	now := time.Now()
+	signed := idp.sign(t, idp.assertion("_req1", now, "alice@example.com"), "_a1")
+	unsigned := strings.Replace(idp.assertion("_req1", now, "mallory@example.com"), "{SIG}", "", 1)
+
+	tests := []struct {
+		name      string
+		response  string
+		requestID string
+		now       time.Time
+	}{
+		{
+			name:      "unsigned",
+			response:  testSAMLResponse("_req1", unsigned),
+			requestID: "_req1",
+			now:       now,
+		},
+		{
+			name: "tampered",
+			response: testSAMLResponse("_req1",
+				strings.Replace(signed, "alice@example.com", "mallory@example.com", 1)),
+			requestID: "_req1",
+			now:       now,
+		},
+		{
+			name:      "wrapped",
+			response:  testSAMLResponse("_req1", unsigned, signed),
+			requestID: "_req1",
+			now:       now,
+		},
+		{
+			name:      "other request",
+			response:  testSAMLResponse("_req1", signed),
+			requestID: "_req2",
+			now:       now,
+		},
+		{
+			name:      "expired",
+			response:  testSAMLResponse("_req1", signed),
+			requestID: "_req1",
+			now:       now.Add(time.Hour),
+		},
+		{
+			name:      "untrusted key",
+			response:  testSAMLResponse("_req1", newTestSAMLIdP(t).sign(t, idp.assertion("_req1", now, "a@b.c"), "_a1")),
+			requestID: "_req1",
+			now:       now,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.ParseResponse(tt.response, tt.requestID, tt.now); err == nil {
+				t.Fatal("ParseResponse() error = nil, want error")
+			}
+		})
+	}
+}
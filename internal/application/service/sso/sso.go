@@ -0,0 +1,103 @@
+// Package sso implements the identity provider protocols used for single sign-on:
+// OpenID Connect authorization code flow with PKCE and SAML 2.0 Web Browser SSO.
+package sso
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/config"
+)
+
+// Provider types
+const (
+	TypeOIDC = "oidc"
+	TypeSAML = "saml"
+)
+
+// Identity is the user identity asserted by an identity provider
+type Identity struct {
+	// Subject is the stable identifier of the user at the provider
+	Subject string
+	Email   string
+	// EmailVerified reports whether the provider vouches for the email address
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// EmailDomain returns the lower-cased domain of the email address
+func (i *Identity) EmailDomain() string {
+	at := strings.LastIndex(i.Email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(i.Email[at+1:])
+}
+
+// MatchTenantMapping returns the first mapping that matches the identity, or nil
+func MatchTenantMapping(mappings []config.SSOTenantMapping, identity *Identity) *config.SSOTenantMapping {
+	domain := identity.EmailDomain()
+	for i := range mappings {
+		m := &mappings[i]
+		if m.EmailDomain == "" && m.Group == "" {
+			continue
+		}
+		if m.EmailDomain != "" && !strings.EqualFold(strings.TrimPrefix(m.EmailDomain, "@"), domain) {
+			continue
+		}
+		if m.Group != "" && !containsGroup(identity.Groups, m.Group) {
+			continue
+		}
+		return m
+	}
+	return nil
+}
+
+// CanLinkUser reports whether an identity may be linked to the existing user with the same email in a tenant.
+// The email must be verified and the first matching mapping must assign its email domain to that tenant,
+// so a provider cannot sign in to accounts of tenants or domains it is not configured for.
+func CanLinkUser(mappings []config.SSOTenantMapping, identity *Identity, tenantID uint64) bool {
+	if !identity.EmailVerified || identity.EmailDomain() == "" {
+		return false
+	}
+	mapping := MatchTenantMapping(mappings, identity)
+	return mapping != nil && mapping.EmailDomain != "" && mapping.TenantID == tenantID
+}
+
+func containsGroup(groups []string, group string) bool {
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// RandomToken returns a URL-safe random string with n bytes of entropy
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewSAMLRequestID returns a random SAML request ID, which must not start with a digit
+func NewSAMLRequestID() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate request id: %w", err)
+	}
+	return "_" + hex.EncodeToString(b), nil
+}
+
+// PKCEChallenge returns the S256 code challenge of a PKCE code verifier
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
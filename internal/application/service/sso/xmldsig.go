@@ -0,0 +1,531 @@
+package sso
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	// hash functions used by the supported signature algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// XML namespaces and algorithms of XML Signature
+const (
+	nsXML  = "http://www.w3.org/XML/1998/namespace"
+	nsDSig = "http://www.w3.org/2000/09/xmldsig#"
+
+	algExcC14N             = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algExcC14NWithComments = "http://www.w3.org/2001/10/xml-exc-c14n#WithComments"
+	algEnvelopedSignature  = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algRSASHA256           = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algRSASHA512           = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	algSHA256              = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algSHA512              = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+// signatureHashes maps the supported signature algorithms to their hash, SHA-1 is rejected
+var signatureHashes = map[string]crypto.Hash{
+	algRSASHA256: crypto.SHA256,
+	algRSASHA512: crypto.SHA512,
+}
+
+// digestHashes maps the supported digest algorithms to their hash, SHA-1 is rejected
+var digestHashes = map[string]crypto.Hash{
+	algSHA256: crypto.SHA256,
+	algSHA512: crypto.SHA512,
+}
+
+// errNotSigned is returned when the element carries no signature
+var errNotSigned = errors.New("element is not signed")
+
+type xmlNodeKind int
+
+const (
+	documentNode xmlNodeKind = iota
+	elementNode
+	textNode
+	commentNode
+)
+
+// xmlNode is a minimal XML tree. Unlike encoding/xml unmarshalling it keeps namespace prefixes and
+// declarations as written, which exclusive canonicalization needs.
+type xmlNode struct {
+	kind     xmlNodeKind
+	parent   *xmlNode
+	prefix   string
+	local    string
+	attrs    []xml.Attr // Name.Space holds the prefix as written
+	children []*xmlNode
+	text     string
+}
+
+// parseXML parses a document into a tree, documents with a DTD are rejected
+func parseXML(data []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	doc := &xmlNode{kind: documentNode}
+	cur := doc
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if cur == doc && len(doc.elements()) > 0 {
+				return nil, errors.New("multiple root elements")
+			}
+			n := &xmlNode{
+				kind:   elementNode,
+				parent: cur,
+				prefix: t.Name.Space,
+				local:  t.Name.Local,
+				attrs:  append([]xml.Attr(nil), t.Attr...),
+			}
+			cur.children = append(cur.children, n)
+			cur = n
+		case xml.EndElement:
+			if cur.kind != elementNode || cur.prefix != t.Name.Space || cur.local != t.Name.Local {
+				return nil, fmt.Errorf("unexpected end element %s", t.Name.Local)
+			}
+			cur = cur.parent
+		case xml.CharData:
+			if cur == doc {
+				continue
+			}
+			if last := len(cur.children) - 1; last >= 0 && cur.children[last].kind == textNode {
+				cur.children[last].text += string(t)
+			} else {
+				cur.children = append(cur.children, &xmlNode{kind: textNode, parent: cur, text: string(t)})
+			}
+		case xml.Comment:
+			if cur != doc {
+				cur.children = append(cur.children, &xmlNode{kind: commentNode, parent: cur, text: string(t)})
+			}
+		case xml.Directive:
+			return nil, errors.New("document type declarations are not allowed")
+		}
+	}
+	if cur != doc {
+		return nil, errors.New("unexpected end of document")
+	}
+	roots := doc.elements()
+	if len(roots) != 1 {
+		return nil, errors.New("document has no root element")
+	}
+	return roots[0], nil
+}
+
+// namespace resolves a prefix in the scope of the element, the empty prefix is the default namespace
+func (n *xmlNode) namespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return nsXML, true
+	}
+	for e := n; e != nil && e.kind == elementNode; e = e.parent {
+		for _, a := range e.attrs {
+			if prefix == "" && a.Name.Space == "" && a.Name.Local == "xmlns" {
+				return a.Value, true
+			}
+			if prefix != "" && a.Name.Space == "xmlns" && a.Name.Local == prefix {
+				return a.Value, true
+			}
+		}
+	}
+	return "", prefix == ""
+}
+
+// is reports whether the node is an element with the given namespace and local name
+func (n *xmlNode) is(ns, local string) bool {
+	if n.kind != elementNode || n.local != local {
+		return false
+	}
+	uri, _ := n.namespace(n.prefix)
+	return uri == ns
+}
+
+// elements returns the child elements
+func (n *xmlNode) elements() []*xmlNode {
+	var elements []*xmlNode
+	for _, c := range n.children {
+		if c.kind == elementNode {
+			elements = append(elements, c)
+		}
+	}
+	return elements
+}
+
+// childrenNamed returns the child elements with the given namespace and local name
+func (n *xmlNode) childrenNamed(ns, local string) []*xmlNode {
+	var elements []*xmlNode
+	for _, c := range n.children {
+		if c.is(ns, local) {
+			elements = append(elements, c)
+		}
+	}
+	return elements
+}
+
+// child returns the first child element with the given namespace and local name, or nil
+func (n *xmlNode) child(ns, local string) *xmlNode {
+	for _, c := range n.children {
+		if c.is(ns, local) {
+			return c
+		}
+	}
+	return nil
+}
+
+// attr returns the value of an unprefixed attribute
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// textContent returns the concatenated text of the element
+func (n *xmlNode) textContent() string {
+	var sb strings.Builder
+	for _, c := range n.children {
+		switch c.kind {
+		case textNode:
+			sb.WriteString(c.text)
+		case elementNode:
+			sb.WriteString(c.textContent())
+		}
+	}
+	return sb.String()
+}
+
+func isNamespaceDecl(a xml.Attr) bool {
+	return a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns")
+}
+
+func qualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+// canonicalizer serializes a subtree with Exclusive XML Canonicalization 1.0
+type canonicalizer struct {
+	buf          bytes.Buffer
+	exclude      *xmlNode
+	inclusive    []string
+	withComments bool
+}
+
+type namespaceDecl struct {
+	prefix string
+	uri    string
+}
+
+type canonicalAttr struct {
+	uri   string
+	local string
+	name  string
+	value string
+}
+
+// canonicalize serializes the element with exclusive canonicalization, omitting the excluded subtree.
+// Prefixes in inclusive are treated as in inclusive canonicalization, "#default" is the default namespace.
+func canonicalize(n *xmlNode, exclude *xmlNode, inclusive []string, withComments bool) ([]byte, error) {
+	c := &canonicalizer{exclude: exclude, withComments: withComments}
+	for _, p := range inclusive {
+		if p == "#default" {
+			p = ""
+		}
+		c.inclusive = append(c.inclusive, p)
+	}
+	if err := c.element(n, map[string]string{}); err != nil {
+		return nil, err
+	}
+	return c.buf.Bytes(), nil
+}
+
+func (c *canonicalizer) element(n *xmlNode, rendered map[string]string) error {
+	// namespaces visibly utilized by the element and its attributes
+	prefixes := []string{n.prefix}
+	var attrs []canonicalAttr
+	for _, a := range n.attrs {
+		if isNamespaceDecl(a) {
+			continue
+		}
+		uri := ""
+		if a.Name.Space != "" {
+			var ok bool
+			if uri, ok = n.namespace(a.Name.Space); !ok {
+				return fmt.Errorf("undeclared namespace prefix %q", a.Name.Space)
+			}
+			if a.Name.Space != "xml" {
+				prefixes = append(prefixes, a.Name.Space)
+			}
+		}
+		attrs = append(attrs, canonicalAttr{
+			uri: uri, local: a.Name.Local, name: qualifiedName(a.Name.Space, a.Name.Local), value: a.Value,
+		})
+	}
+
+	var decls []namespaceDecl
+	seen := map[string]bool{}
+	addDecl := func(prefix string, required bool) error {
+		if seen[prefix] {
+			return nil
+		}
+		seen[prefix] = true
+		uri, ok := n.namespace(prefix)
+		if !ok {
+			if required {
+				return fmt.Errorf("undeclared namespace prefix %q", prefix)
+			}
+			return nil
+		}
+		prev, wasRendered := rendered[prefix]
+		if prefix == "" && uri == "" && prev == "" {
+			return nil
+		}
+		if wasRendered && prev == uri {
+			return nil
+		}
+		decls = append(decls, namespaceDecl{prefix: prefix, uri: uri})
+		return nil
+	}
+	for _, p := range prefixes {
+		if err := addDecl(p, true); err != nil {
+			return err
+		}
+	}
+	for _, p := range c.inclusive {
+		if err := addDecl(p, false); err != nil {
+			return err
+		}
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].prefix < decls[j].prefix })
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].uri != attrs[j].uri {
+			return attrs[i].uri < attrs[j].uri
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	childRendered := rendered
+	if len(decls) > 0 {
+		childRendered = make(map[string]string, len(rendered)+len(decls))
+		for k, v := range rendered {
+			childRendered[k] = v
+		}
+	}
+
+	name := qualifiedName(n.prefix, n.local)
+	c.buf.WriteByte('<')
+	c.buf.WriteString(name)
+	for _, d := range decls {
+		childRendered[d.prefix] = d.uri
+		if d.prefix == "" {
+			c.buf.WriteString(` xmlns="`)
+		} else {
+			c.buf.WriteString(` xmlns:` + d.prefix + `="`)
+		}
+		c.buf.WriteString(escapeAttrValue(d.uri))
+		c.buf.WriteByte('"')
+	}
+	for _, a := range attrs {
+		c.buf.WriteString(" " + a.name + `="` + escapeAttrValue(a.value) + `"`)
+	}
+	c.buf.WriteByte('>')
+
+	for _, child := range n.children {
+		switch child.kind {
+		case elementNode:
+			if child == c.exclude {
+				continue
+			}
+			if err := c.element(child, childRendered); err != nil {
+				return err
+			}
+		case textNode:
+			c.buf.WriteString(escapeText(child.text))
+		case commentNode:
+			if c.withComments {
+				c.buf.WriteString("<!--" + child.text + "-->")
+			}
+		}
+	}
+
+	c.buf.WriteString("</" + name + ">")
+	return nil
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+var attrEscaper = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;",
+)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func escapeAttrValue(s string) string {
+	return attrEscaper.Replace(s)
+}
+
+// verifyEnvelopedSignature verifies the enveloped signature of an element against the trusted certificates.
+// The signature must reference the element itself by its ID attribute, so only the verified element
+// may be read afterwards.
+func verifyEnvelopedSignature(el *xmlNode, certs []*x509.Certificate) error {
+	signatures := el.childrenNamed(nsDSig, "Signature")
+	if len(signatures) == 0 {
+		return errNotSigned
+	}
+	if len(signatures) > 1 {
+		return errors.New("multiple signatures")
+	}
+	signature := signatures[0]
+	signedInfo := signature.child(nsDSig, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("signature has no SignedInfo")
+	}
+
+	siPrefixes, siComments, err := canonicalizationParams(signedInfo.child(nsDSig, "CanonicalizationMethod"))
+	if err != nil {
+		return err
+	}
+	method := signedInfo.child(nsDSig, "SignatureMethod")
+	if method == nil {
+		return errors.New("signature has no SignatureMethod")
+	}
+	signatureHash, ok := signatureHashes[method.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported signature algorithm %q", method.attr("Algorithm"))
+	}
+
+	references := signedInfo.childrenNamed(nsDSig, "Reference")
+	if len(references) != 1 {
+		return errors.New("signature must have exactly one reference")
+	}
+	reference := references[0]
+	id := el.attr("ID")
+	if id == "" || reference.attr("URI") != "#"+id {
+		return errors.New("signature does not reference the signed element")
+	}
+
+	refPrefixes, refComments, err := referenceTransforms(reference)
+	if err != nil {
+		return err
+	}
+	digestMethod := reference.child(nsDSig, "DigestMethod")
+	digestValue := reference.child(nsDSig, "DigestValue")
+	if digestMethod == nil || digestValue == nil {
+		return errors.New("reference has no digest")
+	}
+	digestHash, ok := digestHashes[digestMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported digest algorithm %q", digestMethod.attr("Algorithm"))
+	}
+	expectedDigest, err := decodeBase64(digestValue.textContent())
+	if err != nil {
+		return fmt.Errorf("invalid digest value: %w", err)
+	}
+
+	canonical, err := canonicalize(el, signature, refPrefixes, refComments)
+	if err != nil {
+		return err
+	}
+	h := digestHash.New()
+	h.Write(canonical)
+	if subtle.ConstantTimeCompare(h.Sum(nil), expectedDigest) != 1 {
+		return errors.New("digest mismatch")
+	}
+
+	signatureValue := signature.child(nsDSig, "SignatureValue")
+	if signatureValue == nil {
+		return errors.New("signature has no SignatureValue")
+	}
+	sigBytes, err := decodeBase64(signatureValue.textContent())
+	if err != nil {
+		return fmt.Errorf("invalid signature value: %w", err)
+	}
+	canonicalSignedInfo, err := canonicalize(signedInfo, nil, siPrefixes, siComments)
+	if err != nil {
+		return err
+	}
+	h = signatureHash.New()
+	h.Write(canonicalSignedInfo)
+	hashed := h.Sum(nil)
+	for _, cert := range certs {
+		pub, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		if rsa.VerifyPKCS1v15(pub, signatureHash, hashed, sigBytes) == nil {
+			return nil
+		}
+	}
+	return errors.New("signature verification failed")
+}
+
+// canonicalizationParams reads an exclusive canonicalization method, other methods are rejected
+func canonicalizationParams(method *xmlNode) (prefixes []string, withComments bool, err error) {
+	if method == nil {
+		return nil, false, errors.New("missing canonicalization method")
+	}
+	switch method.attr("Algorithm") {
+	case algExcC14N:
+	case algExcC14NWithComments:
+		withComments = true
+	default:
+		return nil, false, fmt.Errorf("unsupported canonicalization algorithm %q", method.attr("Algorithm"))
+	}
+	if inclusive := method.child(algExcC14N, "InclusiveNamespaces"); inclusive != nil {
+		prefixes = strings.Fields(inclusive.attr("PrefixList"))
+	}
+	return prefixes, withComments, nil
+}
+
+// referenceTransforms checks that the reference uses the enveloped signature transform followed by
+// exclusive canonicalization, and returns the canonicalization parameters
+func referenceTransforms(reference *xmlNode) (prefixes []string, withComments bool, err error) {
+	transforms := reference.child(nsDSig, "Transforms")
+	if transforms == nil {
+		return nil, false, errors.New("reference has no transforms")
+	}
+	enveloped, canonical := false, false
+	for _, t := range transforms.childrenNamed(nsDSig, "Transform") {
+		if t.attr("Algorithm") == algEnvelopedSignature {
+			enveloped = true
+			continue
+		}
+		if canonical {
+			return nil, false, errors.New("multiple canonicalization transforms")
+		}
+		if prefixes, withComments, err = canonicalizationParams(t); err != nil {
+			return nil, false, err
+		}
+		canonical = true
+	}
+	if !enveloped {
+		return nil, false, errors.New("reference must use the enveloped signature transform")
+	}
+	return prefixes, withComments, nil
+}
+
+// decodeBase64 decodes base64 content that may be wrapped over several lines
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Tencent/WeKnora/internal/agent"
+	"github.com/Tencent/WeKnora/internal/agent/tools"
+	"github.com/Tencent/WeKnora/internal/event"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/models/chat"
+	"github.com/Tencent/WeKnora/internal/models/rerank"
+	"github.com/Tencent/WeKnora/internal/types"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+)
+
+// subAgentEvents are the events of a sub-agent streamed to the client through the delegating agent.
+// The sub-agent's answer and references reach the delegating agent as the result of its tool call.
+var subAgentEvents = []event.EventType{
+	event.EventAgentThought,
+	event.EventAgentToolCall,
+	event.EventAgentToolResult,
+	event.EventAgentToolApproval,
+	event.EventAgentReflection,
+	event.EventAgentFinalAnswer,
+	event.EventError,
+}
+
+// newDelegateAgentTool creates the delegate_agent tool for the sub-agents of an agent.
+// It returns nil when the agent is nested too deep to delegate or none of its sub-agents can be used.
+func (s *agentService) newDelegateAgentTool(
+	ctx context.Context,
+	config *types.AgentConfig,
+	chatModel chat.Chat,
+	rerankModel rerank.Reranker,
+	eventBus *event.EventBus,
+	sessionID string,
+) types.Tool {
+	if config.DelegationDepth >= agent.MaxAgentDelegationDepth {
+		logger.Infof(ctx, "Agent at delegation depth %d may not delegate further", config.DelegationDepth)
+		return nil
+	}
+	subAgents := s.resolveSubAgents(ctx, config.SubAgents)
+	if len(subAgents) == 0 {
+		logger.Warnf(ctx, "No usable sub-agents among %v, delegate_agent not registered", config.SubAgents)
+		return nil
+	}
+	logger.Infof(ctx, "Registered delegate_agent tool with %d sub-agents", len(subAgents))
+	return tools.NewDelegateAgentTool(subAgents, &subAgentRunner{
+		service:     s,
+		depth:       config.DelegationDepth + 1,
+		chatModel:   chatModel,
+		rerankModel: rerankModel,
+		eventBus:    eventBus,
+		sessionID:   sessionID,
+	})
+}
+
+// resolveSubAgents loads the sub-agents the requesting user may use, skipping agents that are not in agent mode
+func (s *agentService) resolveSubAgents(ctx context.Context, ids []string) []*types.CustomAgent {
+	allowed, err := s.permissionService.FilterAccessible(ctx, types.ResourceTypeAgent, ids, types.PermissionRead)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to check sub-agent access: %v", err)
+		return nil
+	}
+
+	subAgents := make([]*types.CustomAgent, 0, len(allowed))
+	for _, id := range allowed {
+		customAgent, err := s.customAgentService.GetAgentByID(ctx, id)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to get sub-agent %s: %v", secutils.SanitizeForLog(id), err)
+			continue
+		}
+		if !customAgent.IsAgentMode() {
+			logger.Warnf(ctx, "Sub-agent %s is not in agent mode, skipped", customAgent.ID)
+			continue
+		}
+		customAgent.EnsureDefaults()
+		subAgents = append(subAgents, customAgent)
+	}
+	return subAgents
+}
+
+// subAgentRunner runs the tasks an agent delegates as nested agent engines.
+// A sub-agent has its own tools and iteration budget and shares the session, but not the conversation.
+type subAgentRunner struct {
+	service     *agentService
+	depth       int             // Delegation depth of the sub-agents
+	chatModel   chat.Chat       // Model of the delegating agent, used by sub-agents without their own
+	rerankModel rerank.Reranker // Rerank model of the delegating agent, used by sub-agents without their own
+	eventBus    *event.EventBus // EventBus of the delegating agent
+	sessionID   string
+}
+
+// RunSubAgent runs the task on the agent and streams its events through the delegating agent's EventBus
+func (r *subAgentRunner) RunSubAgent(ctx context.Context,
+	customAgent *types.CustomAgent, task string,
+) (*tools.SubAgentResult, error) {
+	call, _ := tools.ToolCallFromContext(ctx)
+	ctx = types.WithUsageScope(ctx, types.UsageScope{
+		AgentID: customAgent.ID, Source: types.UsageSourceAgentQA,
+	})
+	logger.Infof(ctx, "Running sub-agent %s at depth %d for tool call %s", customAgent.ID, r.depth, call.ID)
+
+	config, chatModel, rerankModel, err := r.buildConfig(ctx, customAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	eventBus := event.NewEventBus()
+	references := &subAgentReferences{seen: make(map[string]bool)}
+	eventBus.On(event.EventAgentToolResult, references.collect)
+	for _, eventType := range subAgentEvents {
+		eventBus.On(eventType, r.forward(call.ID, customAgent))
+	}
+	// Events of agents the sub-agent delegates to are already wrapped with their own tool call
+	eventBus.On(event.EventAgentSubAgent, func(ctx context.Context, evt event.Event) error {
+		return r.eventBus.Emit(ctx, evt)
+	})
+
+	// The sub-agent neither writes to the session context nor checkpoints, a resumed run repeats the delegation
+	engine, err := r.service.CreateAgentEngine(ctx, config, chatModel, rerankModel, eventBus, nil, r.sessionID, nil)
+	if err != nil {
+		return nil, err
+	}
+	state, err := engine.Execute(ctx, r.sessionID, call.MessageID, task, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &tools.SubAgentResult{
+		Answer:     state.FinalAnswer,
+		References: references.list(),
+		Rounds:     state.CurrentRound,
+	}, nil
+}
+
+// buildConfig creates the configuration and models of a sub-agent.
+// Models the sub-agent does not configure are inherited from the delegating agent.
+func (r *subAgentRunner) buildConfig(ctx context.Context,
+	customAgent *types.CustomAgent,
+) (*types.AgentConfig, chat.Chat, rerank.Reranker, error) {
+	config := newAgentConfig(customAgent)
+	config.DelegationDepth = r.depth
+	// The task carries everything the sub-agent needs, it does not see the conversation
+	config.MultiTurnEnabled = false
+
+	chatModel := r.chatModel
+	if customAgent.Config.ModelID != "" {
+		model, err := r.service.modelService.GetChatModel(ctx, customAgent.Config.ModelID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get chat model: %w", err)
+		}
+		chatModel = model
+	}
+
+	kbIDs := resolveKnowledgeBasesFromAgent(ctx, r.service.knowledgeBaseService, customAgent)
+	rerankModel := r.rerankModel
+	if len(kbIDs) > 0 && customAgent.Config.RerankModelID != "" {
+		model, err := r.service.modelService.GetRerankModel(ctx, customAgent.Config.RerankModelID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get rerank model: %w", err)
+		}
+		rerankModel = model
+	}
+	if len(kbIDs) > 0 && rerankModel == nil {
+		logger.Warnf(ctx, "No rerank model for sub-agent %s, running without knowledge bases", customAgent.ID)
+		kbIDs = nil
+	}
+
+	// Keep only the knowledge bases the user may read, the agent tools search within them
+	if len(kbIDs) > 0 {
+		readable, err := r.service.permissionService.FilterAccessible(ctx,
+			types.ResourceTypeKnowledgeBase, kbIDs, types.PermissionRead)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		config.KnowledgeBases = readable
+	}
+	for _, kbID := range config.KnowledgeBases {
+		config.SearchTargets = append(config.SearchTargets, &types.SearchTarget{
+			Type:            types.SearchTargetTypeKnowledgeBase,
+			KnowledgeBaseID: kbID,
+		})
+	}
+	return config, chatModel, rerankModel, nil
+}
+
+// forward wraps the events of a sub-agent in sub-agent events of the delegating agent
+func (r *subAgentRunner) forward(toolCallID string, customAgent *types.CustomAgent) event.EventHandler {
+	return func(ctx context.Context, evt event.Event) error {
+		return r.eventBus.Emit(ctx, event.Event{
+			ID:        toolCallID + "-" + evt.ID,
+			Type:      event.EventAgentSubAgent,
+			SessionID: r.sessionID,
+			Data: event.AgentSubAgentData{
+				ToolCallID: toolCallID,
+				AgentID:    customAgent.ID,
+				AgentName:  customAgent.Name,
+				Depth:      r.depth,
+				Event:      evt,
+			},
+		})
+	}
+}
+
+// subAgentReferences collects the knowledge a sub-agent retrieves, in retrieval order and without duplicates
+type subAgentReferences struct {
+	mu   sync.Mutex
+	seen map[string]bool
+	refs []*types.SearchResult
+}
+
+// collect takes the references from knowledge_search results and from the answers of nested sub-agents
+func (c *subAgentReferences) collect(ctx context.Context, evt event.Event) error {
+	data, ok := evt.Data.(event.AgentToolResultData)
+	if !ok || !data.Success {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch data.ToolName {
+	case tools.ToolKnowledgeSearch:
+		results, _ := data.Data["results"].([]map[string]interface{})
+		for _, result := range results {
+			ref := &types.SearchResult{}
+			ref.ID, _ = result["chunk_id"].(string)
+			ref.Content, _ = result["content"].(string)
+			ref.KnowledgeID, _ = result["knowledge_id"].(string)
+			ref.KnowledgeTitle, _ = result["knowledge_title"].(string)
+			ref.MatchType, _ = result["match_type"].(types.MatchType)
+			c.add(ref)
+		}
+	case tools.ToolDelegateAgent:
+		refs, _ := data.Data["references"].([]*types.SearchResult)
+		for _, ref := range refs {
+			c.add(ref)
+		}
+	}
+	return nil
+}
+
+// add appends a reference that has not been collected yet
+func (c *subAgentReferences) add(ref *types.SearchResult) {
+	if ref.ID == "" || c.seen[ref.ID] {
+		return
+	}
+	c.seen[ref.ID] = true
+	c.refs = append(c.refs, ref)
+}
+
+// list returns the collected references
+func (c *subAgentReferences) list() []*types.SearchResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*types.SearchResult(nil), c.refs...)
+}
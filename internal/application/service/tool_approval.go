@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tencent/WeKnora/internal/application/repository"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// toolApprovalPollInterval is how often a waiting tool call checks for a decision.
+// Decisions may be made on another instance, so the stored approval is the source of truth.
+const toolApprovalPollInterval = 500 * time.Millisecond
+
+// toolApprovalService implements the ToolApprovalService interface
+type toolApprovalService struct {
+	repo        interfaces.ToolApprovalRepository
+	sessionRepo interfaces.SessionRepository
+}
+
+// NewToolApprovalService creates a new tool approval service
+func NewToolApprovalService(
+	repo interfaces.ToolApprovalRepository,
+	sessionRepo interfaces.SessionRepository,
+) interfaces.ToolApprovalService {
+	return &toolApprovalService{
+		repo:        repo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+// RequestApproval persists a pending approval for a tool call. The arguments to execute start as the requested ones.
+func (s *toolApprovalService) RequestApproval(ctx context.Context, approval *types.ToolApproval) error {
+	approval.TenantID = ctx.Value(types.TenantIDContextKey).(uint64)
+	approval.Status = types.ToolApprovalPending
+	approval.ApprovedArguments = approval.Arguments
+	return s.repo.Create(ctx, approval)
+}
+
+// WaitForDecision blocks until the approval is decided or expires
+func (s *toolApprovalService) WaitForDecision(ctx context.Context, id string) (*types.ToolApproval, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	ticker := time.NewTicker(toolApprovalPollInterval)
+	defer ticker.Stop()
+
+	for {
+		approval, err := s.repo.Get(ctx, tenantID, id)
+		if err != nil {
+			return nil, err
+		}
+		if approval.Status != types.ToolApprovalPending {
+			return approval, nil
+		}
+		if !approval.IsPending(time.Now()) {
+			return s.expire(ctx, approval, "승인 대기 시간이 지났습니다")
+		}
+
+		select {
+		case <-ctx.Done():
+			// The run was stopped, nothing will execute the tool call after a late approval
+			if _, err := s.expire(context.WithoutCancel(ctx), approval, "에이전트 실행이 중지되었습니다"); err != nil {
+				logger.Warnf(ctx, "Failed to expire tool approval %s: %v", id, err)
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// expire marks a pending approval as expired, returning the stored approval if it was decided meanwhile
+func (s *toolApprovalService) expire(ctx context.Context,
+	approval *types.ToolApproval, comment string,
+) (*types.ToolApproval, error) {
+	now := time.Now()
+	approval.Status = types.ToolApprovalExpired
+	approval.Comment = comment
+	approval.DecidedAt = &now
+	approval.UpdatedAt = now
+	stored, err := s.repo.Decide(ctx, approval)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		return s.repo.Get(ctx, approval.TenantID, approval.ID)
+	}
+	return approval, nil
+}
+
+// checkSession checks that the session belongs to the tenant in the context
+func (s *toolApprovalService) checkSession(ctx context.Context, sessionID string) (uint64, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	if _, err := s.sessionRepo.Get(ctx, tenantID, sessionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, werrors.NewNotFoundError("세션을 찾을 수 없습니다")
+		}
+		return 0, err
+	}
+	return tenantID, nil
+}
+
+// ListApprovals lists the approvals of a session, newest first.
+// Pending approvals past their deadline whose run is gone are reported as expired.
+func (s *toolApprovalService) ListApprovals(ctx context.Context,
+	sessionID string, status types.ToolApprovalStatus,
+) ([]*types.ToolApproval, error) {
+	if status != "" && !status.IsValid() {
+		return nil, werrors.NewBadRequestError("올바르지 않은 승인 상태입니다").WithDetails(string(status))
+	}
+	tenantID, err := s.checkSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	approvals, err := s.repo.ListBySession(ctx, tenantID, sessionID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]*types.ToolApproval, 0, len(approvals))
+	for _, approval := range approvals {
+		if approval.Status == types.ToolApprovalPending && !approval.IsPending(now) {
+			if status == types.ToolApprovalPending {
+				continue
+			}
+			approval.Status = types.ToolApprovalExpired
+		}
+		result = append(result, approval)
+	}
+	return result, nil
+}
+
+// GetApproval gets an approval of a session
+func (s *toolApprovalService) GetApproval(ctx context.Context, sessionID, id string) (*types.ToolApproval, error) {
+	tenantID, err := s.checkSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	approval, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrToolApprovalNotFound) {
+			return nil, werrors.NewNotFoundError("승인 요청을 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	if approval.SessionID != sessionID {
+		return nil, werrors.NewNotFoundError("승인 요청을 찾을 수 없습니다")
+	}
+	if approval.Status == types.ToolApprovalPending && !approval.IsPending(time.Now()) {
+		approval.Status = types.ToolApprovalExpired
+	}
+	return approval, nil
+}
+
+// Approve approves a pending approval, optionally replacing the arguments of the tool call
+func (s *toolApprovalService) Approve(ctx context.Context,
+	sessionID, id string, decision *types.ToolApprovalDecision,
+) (*types.ToolApproval, error) {
+	return s.decide(ctx, sessionID, id, types.ToolApprovalApproved, decision)
+}
+
+// Reject rejects a pending approval
+func (s *toolApprovalService) Reject(ctx context.Context,
+	sessionID, id string, decision *types.ToolApprovalDecision,
+) (*types.ToolApproval, error) {
+	return s.decide(ctx, sessionID, id, types.ToolApprovalRejected, decision)
+}
+
+// decide stores the decision of a pending approval, the waiting tool call picks it up on its next check
+func (s *toolApprovalService) decide(ctx context.Context,
+	sessionID, id string, status types.ToolApprovalStatus, decision *types.ToolApprovalDecision,
+) (*types.ToolApproval, error) {
+	approval, err := s.GetApproval(ctx, sessionID, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != types.ToolApprovalPending {
+		return nil, werrors.NewConflictError("이미 처리된 승인 요청입니다").WithDetails(string(approval.Status))
+	}
+
+	if status == types.ToolApprovalApproved && decision.Arguments != nil {
+		arguments, err := json.Marshal(decision.Arguments)
+		if err != nil {
+			return nil, werrors.NewBadRequestError("올바르지 않은 도구 인자입니다").WithDetails(err.Error())
+		}
+		approval.ApprovedArguments = types.JSON(arguments)
+	}
+	now := time.Now()
+	approval.Status = status
+	approval.Comment = strings.TrimSpace(decision.Comment)
+	approval.DecidedAt = &now
+	approval.UpdatedAt = now
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil {
+		approval.DecidedBy = subject.UserID
+	}
+
+	stored, err := s.repo.Decide(ctx, approval)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		return nil, werrors.NewConflictError("이미 처리된 승인 요청입니다")
+	}
+	logger.Infof(ctx, "Tool approval %s of tool %s %s", approval.ID, approval.ToolName, status)
+	return approval, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Tencent/WeKnora/internal/application/service/usage"
+	"github.com/Tencent/WeKnora/internal/config"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// usageRollupDays is the number of recent days recomputed by each rollup,
+// records are kept at least this long so that late records of yesterday are still rolled up
+const usageRollupDays = 2
+
+// usageService implements the UsageService interface
+type usageService struct {
+	repo interfaces.UsageRepository
+	cfg  *config.Config
+}
+
+// NewUsageService creates a new usage service
+func NewUsageService(repo interfaces.UsageRepository, cfg *config.Config) interfaces.UsageService {
+	return &usageService{repo: repo, cfg: cfg}
+}
+
+// RecordUsage stores the usage of a model call, calls without a tenant are ignored.
+// A failed write is logged only, metering must not fail the model call.
+func (s *usageService) RecordUsage(ctx context.Context, record *types.UsageRecord) {
+	if record.TenantID == 0 {
+		return
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+	if err := s.repo.CreateUsageRecord(ctx, record); err != nil {
+		logger.Warnf(ctx, "Failed to record usage of model %s for tenant %d: %v", record.ModelID, record.TenantID, err)
+	}
+}
+
+// GetUsageSummary reports the usage of the tenant in the context from the daily rollups
+func (s *usageService) GetUsageSummary(ctx context.Context, query *types.UsageQuery) (*types.UsageSummary, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+
+	from, to, err := usage.ParseDateRange(query.From, query.To, time.Now())
+	if err != nil {
+		return nil, werrors.NewBadRequestError("올바르지 않은 조회 기간입니다").WithDetails(err.Error())
+	}
+	groupBy, err := usage.ParseGroupBy(query.GroupBy)
+	if err != nil {
+		return nil, werrors.NewBadRequestError("올바르지 않은 그룹 기준입니다").WithDetails(err.Error())
+	}
+
+	rows, err := s.repo.SummarizeUsage(ctx, tenantID, from, to, groupBy, query)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		return nil, err
+	}
+	return &types.UsageSummary{
+		From:    from.Format(time.DateOnly),
+		To:      to.Format(time.DateOnly),
+		GroupBy: groupBy,
+		Rows:    rows,
+		Total:   usage.Total(rows),
+	}, nil
+}
+
+// ListUsageRecords lists the per-call usage records of the tenant in the context, newest first
+func (s *usageService) ListUsageRecords(ctx context.Context,
+	filter *types.UsageRecordFilter, page *types.Pagination,
+) (*types.PageResult, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	records, total, err := s.repo.ListUsageRecords(ctx, tenantID, filter, page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		return nil, err
+	}
+	return types.NewPageResult(total, page, records), nil
+}
+
+// ProcessUsageRollup rolls up the records of yesterday and today and deletes expired records, it runs periodically
+func (s *usageService) ProcessUsageRollup(ctx context.Context, t *asynq.Task) error {
+	now := time.Now()
+	since := usage.Day(now).AddDate(0, 0, -(usageRollupDays - 1))
+	rows, err := s.repo.RollupUsage(ctx, since)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to roll up usage since %s: %v", since.Format(time.DateOnly), err)
+		return err
+	}
+	logger.Infof(ctx, "Rolled up %d daily usage rows since %s", rows, since.Format(time.DateOnly))
+
+	if s.cfg == nil || s.cfg.Usage == nil || s.cfg.Usage.RecordRetentionDays <= 0 {
+		return nil
+	}
+	days := max(s.cfg.Usage.RecordRetentionDays, usageRollupDays)
+	before := usage.Day(now).AddDate(0, 0, -days)
+	deleted, err := s.repo.PurgeUsageRecords(ctx, before)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to purge usage records: %v", err)
+		return nil
+	}
+	if deleted > 0 {
+		logger.Infof(ctx, "Purged %d usage records older than %s", deleted, before.Format(time.DateOnly))
+	}
+	return nil
+}
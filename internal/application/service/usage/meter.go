@@ -0,0 +1,287 @@
+// Package usage meters the token consumption of chat, embedding and rerank model calls.
+// Models are wrapped when they are created, so every caller is metered without changes,
+// and each call is attributed to the tenant and usage scope in its context.
+package usage
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Tencent/WeKnora/internal/models/chat"
+	"github.com/Tencent/WeKnora/internal/models/embedding"
+	"github.com/Tencent/WeKnora/internal/models/rerank"
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// messageOverheadTokens approximates the tokens a chat message takes besides its content
+const messageOverheadTokens = 4
+
+// Recorder stores a usage record, it is called after each successful model call
+type Recorder func(ctx context.Context, record *types.UsageRecord)
+
+// ModelInfo identifies the model usage is recorded for
+type ModelInfo struct {
+	ID   string
+	Name string
+	Type types.ModelType
+}
+
+// EstimateTokens roughly estimates the token count of a text.
+// ASCII text averages four characters per token, other characters such as CJK take about one token each.
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// estimateMessages estimates the prompt tokens of chat messages
+func estimateMessages(messages []chat.Message) int {
+	tokens := 0
+	for _, message := range messages {
+		tokens += messageOverheadTokens + EstimateTokens(message.Content)
+		for _, call := range message.ToolCalls {
+			tokens += EstimateTokens(call.Function.Name) + EstimateTokens(call.Function.Arguments)
+		}
+	}
+	return tokens
+}
+
+// estimateCompletion estimates the completion tokens of a chat response
+func estimateCompletion(content string, toolCalls []types.LLMToolCall) int {
+	tokens := EstimateTokens(content)
+	for _, call := range toolCalls {
+		tokens += EstimateTokens(call.Function.Name) + EstimateTokens(call.Function.Arguments)
+	}
+	return tokens
+}
+
+// estimateTexts estimates the tokens of texts passed to an embedding model
+func estimateTexts(texts []string) int {
+	tokens := 0
+	for _, text := range texts {
+		tokens += EstimateTokens(text)
+	}
+	return tokens
+}
+
+// meter records the usage of one model
+type meter struct {
+	info     ModelInfo
+	recorder Recorder
+}
+
+// record passes a usage record for the tenant and scope in the context to the recorder
+func (m *meter) record(ctx context.Context,
+	start time.Time, inputCount int, usage types.TokenUsage, estimated bool,
+) {
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	tenantID, _ := ctx.Value(types.TenantIDContextKey).(uint64)
+	scope := types.UsageScopeFromContext(ctx)
+	// the call may have finished because its request was cancelled, the record is still stored
+	m.recorder(context.WithoutCancel(ctx), &types.UsageRecord{
+		TenantID:         tenantID,
+		ModelID:          m.info.ID,
+		ModelName:        m.info.Name,
+		ModelType:        m.info.Type,
+		SessionID:        scope.SessionID,
+		AgentID:          scope.AgentID,
+		Source:           scope.Source,
+		InputCount:       inputCount,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		Estimated:        estimated,
+		DurationMs:       time.Since(start).Milliseconds(),
+	})
+}
+
+// chatUsage returns the usage reported by the provider, or an estimate if it reported none
+func chatUsage(reported *types.TokenUsage,
+	messages []chat.Message, content string, toolCalls []types.LLMToolCall,
+) (types.TokenUsage, bool) {
+	if reported != nil && (reported.TotalTokens > 0 || reported.PromptTokens > 0 || reported.CompletionTokens > 0) {
+		return *reported, false
+	}
+	return types.TokenUsage{
+		PromptTokens:     estimateMessages(messages),
+		CompletionTokens: estimateCompletion(content, toolCalls),
+	}, true
+}
+
+// meteredChat records the usage of each chat call
+type meteredChat struct {
+	meter
+	inner chat.Chat
+}
+
+// MeterChat wraps a chat model so that the usage of each call is recorded
+func MeterChat(model chat.Chat, info ModelInfo, recorder Recorder) chat.Chat {
+	return &meteredChat{meter: meter{info: info, recorder: recorder}, inner: model}
+}
+
+// Chat performs a non-streaming chat and records its usage
+func (m *meteredChat) Chat(ctx context.Context,
+	messages []chat.Message, opts *chat.ChatOptions,
+) (*types.ChatResponse, error) {
+	start := time.Now()
+	resp, err := m.inner.Chat(ctx, messages, opts)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	usage, estimated := chatUsage(&resp.Usage, messages, resp.Content, resp.ToolCalls)
+	m.record(ctx, start, len(messages), usage, estimated)
+	return resp, nil
+}
+
+// ChatStream performs a streaming chat, the usage is recorded when the stream ends
+func (m *meteredChat) ChatStream(ctx context.Context,
+	messages []chat.Message, opts *chat.ChatOptions,
+) (<-chan types.StreamResponse, error) {
+	start := time.Now()
+	stream, err := m.inner.ChatStream(ctx, messages, opts)
+	if err != nil {
+		return stream, err
+	}
+
+	out := make(chan types.StreamResponse)
+	go func() {
+		var reported *types.TokenUsage
+		var content strings.Builder
+		var toolCalls []types.LLMToolCall
+		for resp := range stream {
+			if resp.Usage != nil {
+				reported = resp.Usage
+			}
+			if resp.ResponseType == types.ResponseTypeAnswer {
+				content.WriteString(resp.Content)
+			}
+			if len(resp.ToolCalls) > 0 {
+				toolCalls = resp.ToolCalls
+			}
+			out <- resp
+		}
+		close(out)
+
+		usage, estimated := chatUsage(reported, messages, content.String(), toolCalls)
+		m.record(ctx, start, len(messages), usage, estimated)
+	}()
+	return out, nil
+}
+
+// GetModelName returns the model name
+func (m *meteredChat) GetModelName() string {
+	return m.inner.GetModelName()
+}
+
+// GetModelID returns the model ID
+func (m *meteredChat) GetModelID() string {
+	return m.inner.GetModelID()
+}
+
+// meteredEmbedder records the usage of each embedding call.
+// Embedding providers do not report usage through the Embedder interface, so tokens are estimated.
+type meteredEmbedder struct {
+	meter
+	embedding.Embedder
+}
+
+// meteredTokenEmbedder additionally meters a late-interaction embedder
+type meteredTokenEmbedder struct {
+	*meteredEmbedder
+	tokens embedding.TokenEmbedder
+}
+
+// MeterEmbedder wraps an embedding model so that the usage of each call is recorded.
+// The wrapper implements TokenEmbedder if the model does.
+func MeterEmbedder(model embedding.Embedder, info ModelInfo, recorder Recorder) embedding.Embedder {
+	metered := &meteredEmbedder{meter: meter{info: info, recorder: recorder}, Embedder: model}
+	if tokens, ok := model.(embedding.TokenEmbedder); ok {
+		return &meteredTokenEmbedder{meteredEmbedder: metered, tokens: tokens}
+	}
+	return metered
+}
+
+// Embed converts text to a vector and records its usage
+func (m *meteredEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	start := time.Now()
+	vector, err := m.Embedder.Embed(ctx, text)
+	if err == nil {
+		m.record(ctx, start, 1, types.TokenUsage{PromptTokens: EstimateTokens(text)}, true)
+	}
+	return vector, err
+}
+
+// BatchEmbed converts texts to vectors and records their usage.
+// BatchEmbedWithPool calls BatchEmbed of the model it is given, so pooled batches are metered here too.
+func (m *meteredEmbedder) BatchEmbed(ctx context.Context, texts []string) ([][]float32, error) {
+	start := time.Now()
+	vectors, err := m.Embedder.BatchEmbed(ctx, texts)
+	if err == nil {
+		m.record(ctx, start, len(texts), types.TokenUsage{PromptTokens: estimateTexts(texts)}, true)
+	}
+	return vectors, err
+}
+
+// EmbedQueryTokens converts a query to per-token vectors and records its usage
+func (m *meteredTokenEmbedder) EmbedQueryTokens(ctx context.Context, text string) ([][]float32, error) {
+	start := time.Now()
+	vectors, err := m.tokens.EmbedQueryTokens(ctx, text)
+	if err == nil {
+		m.record(ctx, start, 1, types.TokenUsage{PromptTokens: EstimateTokens(text)}, true)
+	}
+	return vectors, err
+}
+
+// BatchEmbedDocumentTokens converts documents to per-token vectors and records their usage
+func (m *meteredTokenEmbedder) BatchEmbedDocumentTokens(ctx context.Context, texts []string) ([][][]float32, error) {
+	start := time.Now()
+	vectors, err := m.tokens.BatchEmbedDocumentTokens(ctx, texts)
+	if err == nil {
+		m.record(ctx, start, len(texts), types.TokenUsage{PromptTokens: estimateTexts(texts)}, true)
+	}
+	return vectors, err
+}
+
+// meteredReranker records the usage of each rerank call, tokens are estimated
+type meteredReranker struct {
+	meter
+	inner rerank.Reranker
+}
+
+// MeterReranker wraps a rerank model so that the usage of each call is recorded
+func MeterReranker(model rerank.Reranker, info ModelInfo, recorder Recorder) rerank.Reranker {
+	return &meteredReranker{meter: meter{info: info, recorder: recorder}, inner: model}
+}
+
+// Rerank reranks documents and records the usage, the query is counted once per document
+func (m *meteredReranker) Rerank(ctx context.Context,
+	query string, documents []string,
+) ([]rerank.RankResult, error) {
+	start := time.Now()
+	results, err := m.inner.Rerank(ctx, query, documents)
+	if err == nil {
+		tokens := EstimateTokens(query)*len(documents) + estimateTexts(documents)
+		m.record(ctx, start, len(documents), types.TokenUsage{PromptTokens: tokens}, true)
+	}
+	return results, err
+}
+
+// GetModelName returns the model name
+func (m *meteredReranker) GetModelName() string {
+	return m.inner.GetModelName()
+}
+
+// GetModelID returns the model ID
+func (m *meteredReranker) GetModelID() string {
+	return m.inner.GetModelID()
+}
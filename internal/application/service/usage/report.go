@@ -0,0 +1,102 @@
+package usage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+const (
+	// DefaultReportDays is the number of days reported when no range is given
+	DefaultReportDays = 30
+	// MaxReportDays bounds the range of a report
+	MaxReportDays = 366
+)
+
+// DefaultGroupBy is used when a report does not specify how to group
+var DefaultGroupBy = []string{types.UsageGroupByModel, types.UsageGroupByAgent}
+
+// groupByOptions are the valid group by options in report column order
+var groupByOptions = []string{
+	types.UsageGroupByDay,
+	types.UsageGroupByModel,
+	types.UsageGroupByModelType,
+	types.UsageGroupByAgent,
+	types.UsageGroupBySource,
+}
+
+// ParseGroupBy parses comma separated group by options, they are returned in report column order
+func ParseGroupBy(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultGroupBy, nil
+	}
+	selected := map[string]bool{}
+	for _, option := range strings.Split(value, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		if !slices.Contains(groupByOptions, option) {
+			return nil, fmt.Errorf("unknown group by option %q", option)
+		}
+		selected[option] = true
+	}
+	groupBy := make([]string, 0, len(selected))
+	for _, option := range groupByOptions {
+		if selected[option] {
+			groupBy = append(groupBy, option)
+		}
+	}
+	return groupBy, nil
+}
+
+// ParseDateRange parses an inclusive range of UTC days in YYYY-MM-DD format.
+// A missing end is today and a missing start is DefaultReportDays days before the end.
+func ParseDateRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	end := Day(now)
+	if to != "" {
+		parsed, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date %q", to)
+		}
+		end = parsed
+	}
+	start := end.AddDate(0, 0, -(DefaultReportDays - 1))
+	if from != "" {
+		parsed, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start date %q", from)
+		}
+		start = parsed
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start date %s is after end date %s",
+			start.Format(time.DateOnly), end.Format(time.DateOnly))
+	}
+	if end.Sub(start) >= MaxReportDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("range exceeds %d days", MaxReportDays)
+	}
+	return start, end, nil
+}
+
+// Day returns the start of the UTC day of a time, rollups are kept per UTC day
+func Day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// Total sums the rows of a report
+func Total(rows []*types.UsageSummaryRow) *types.UsageSummaryRow {
+	total := &types.UsageSummaryRow{}
+	for _, row := range rows {
+		total.Calls += row.Calls
+		total.InputCount += row.InputCount
+		total.PromptTokens += row.PromptTokens
+		total.CompletionTokens += row.CompletionTokens
+		total.TotalTokens += row.TotalTokens
+		total.EstimatedCalls += row.EstimatedCalls
+	}
+	return total
+}
@@ -0,0 +1,193 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/models/chat"
+	"github.com/Tencent/WeKnora/internal/models/embedding"
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	cases := map[string]int{
+		"":      0,
+		"abcd":  1,
+		"abcde": 2,
+		"안녕하세요": 5,
+		"hi 세계": 3,
+	}
+	for text, want := range cases {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	groupBy, err := ParseGroupBy("source, day,model,day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{types.UsageGroupByDay, types.UsageGroupByModel, types.UsageGroupBySource}
+	if len(groupBy) != len(want) {
+		t.Fatalf("got %v, want %v", groupBy, want)
+	}
+	for i := range want {
+		if groupBy[i] != want[i] {
+			t.Fatalf("got %v, want %v", groupBy, want)
+		}
+	}
+
+	if groupBy, _ := ParseGroupBy(""); len(groupBy) != len(DefaultGroupBy) {
+		t.Errorf("empty group by should use the default, got %v", groupBy)
+	}
+	if _, err := ParseGroupBy("tenant"); err == nil {
+		t.Error("unknown option should be rejected")
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	from, to, err := ParseDateRange("", "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to.Format(time.DateOnly) != "2024-03-10" || from.Format(time.DateOnly) != "2024-02-10" {
+		t.Errorf("unexpected default range %s - %s", from, to)
+	}
+
+	if _, _, err := ParseDateRange("2024-03-11", "2024-03-10", now); err == nil {
+		t.Error("start after end should be rejected")
+	}
+	if _, _, err := ParseDateRange("2022-01-01", "2024-01-01", now); err == nil {
+		t.Error("range longer than the maximum should be rejected")
+	}
+	if _, _, err := ParseDateRange("03/01/2024", "", now); err == nil {
+		t.Error("invalid date should be rejected")
+	}
+}
+
+// fakeChat streams a fixed answer with optional usage
+type fakeChat struct {
+	usage *types.TokenUsage
+}
+
+func (f *fakeChat) Chat(ctx context.Context, messages []chat.Message, opts *chat.ChatOptions) (*types.ChatResponse, error) {
+	resp := &types.ChatResponse{Content: "answer"}
+	if f.usage != nil {
+		resp.Usage = *f.usage
+	}
+	return resp, nil
+}
+
+func (f *fakeChat) ChatStream(ctx context.Context,
+	messages []chat.Message, opts *chat.ChatOptions,
+) (<-chan types.StreamResponse, error) {
+	stream := make(chan types.StreamResponse, 3)
+	stream <- types.StreamResponse{ResponseType: types.ResponseTypeAnswer, Content: "hello world!"}
+	stream <- types.StreamResponse{ResponseType: types.ResponseTypeAnswer, Done: true}
+	if f.usage != nil {
+		stream <- types.StreamResponse{ResponseType: types.ResponseTypeAnswer, Done: true, Usage: f.usage}
+	}
+	close(stream)
+	return stream, nil
+}
+
+func (f *fakeChat) GetModelName() string { return "fake" }
+
+func (f *fakeChat) GetModelID() string { return "fake-id" }
+
+// collect returns a recorder storing the records on a channel
+func collect() (Recorder, chan *types.UsageRecord) {
+	records := make(chan *types.UsageRecord, 1)
+	return func(ctx context.Context, record *types.UsageRecord) { records <- record }, records
+}
+
+func TestMeterChatStream(t *testing.T) {
+	ctx := context.WithValue(context.Background(), types.TenantIDContextKey, uint64(7))
+	ctx = types.WithUsageScope(ctx, types.UsageScope{SessionID: "s1", Source: types.UsageSourceKnowledgeQA})
+	ctx = types.WithUsageScope(ctx, types.UsageScope{AgentID: "a1"})
+	messages := []chat.Message{{Role: "user", Content: "question"}}
+
+	recorder, records := collect()
+	reported := &types.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+	model := MeterChat(&fakeChat{usage: reported}, ModelInfo{ID: "m1", Type: types.ModelTypeKnowledgeQA}, recorder)
+	stream, err := model.ChatStream(ctx, messages, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for range stream {
+		count++
+	}
+	if count != 3 {
+		t.Fatalf("all stream responses should be forwarded, got %d", count)
+	}
+	record := <-records
+	if record.TenantID != 7 || record.SessionID != "s1" || record.AgentID != "a1" ||
+		record.Source != types.UsageSourceKnowledgeQA {
+		t.Errorf("record not attributed to the context scope: %+v", record)
+	}
+	if record.Estimated || record.TotalTokens != 15 || record.PromptTokens != 10 {
+		t.Errorf("reported usage should be recorded: %+v", record)
+	}
+
+	// providers without usage are estimated from the messages and the streamed answer
+	recorder, records = collect()
+	model = MeterChat(&fakeChat{}, ModelInfo{ID: "m1"}, recorder)
+	stream, _ = model.ChatStream(ctx, messages, nil)
+	for range stream {
+	}
+	record = <-records
+	if !record.Estimated || record.PromptTokens != messageOverheadTokens+2 || record.CompletionTokens != 3 {
+		t.Errorf("usage should be estimated: %+v", record)
+	}
+	if record.TotalTokens != record.PromptTokens+record.CompletionTokens {
+		t.Errorf("total should be the sum of prompt and completion: %+v", record)
+	}
+}
+
+// fakeEmbedder is a late-interaction embedder
+type fakeEmbedder struct {
+	embedding.Embedder
+}
+
+func (f *fakeEmbedder) BatchEmbed(ctx context.Context, texts []string) ([][]float32, error) {
+	return make([][]float32, len(texts)), nil
+}
+
+func (f *fakeEmbedder) EmbedQueryTokens(ctx context.Context, text string) ([][]float32, error) {
+	return nil, nil
+}
+
+func (f *fakeEmbedder) BatchEmbedDocumentTokens(ctx context.Context, texts []string) ([][][]float32, error) {
+	return make([][][]float32, len(texts)), nil
+}
+
+func TestMeterEmbedder(t *testing.T) {
+	ctx := context.WithValue(context.Background(), types.TenantIDContextKey, uint64(1))
+	recorder, records := collect()
+	model := MeterEmbedder(&fakeEmbedder{}, ModelInfo{ID: "e1", Type: types.ModelTypeEmbedding}, recorder)
+
+	tokens, ok := model.(embedding.TokenEmbedder)
+	if !ok {
+		t.Fatal("metered model should keep implementing TokenEmbedder")
+	}
+	if _, err := tokens.BatchEmbedDocumentTokens(ctx, []string{"abcd", "abcdefgh"}); err != nil {
+		t.Fatal(err)
+	}
+	record := <-records
+	if record.InputCount != 2 || record.PromptTokens != 3 || !record.Estimated {
+		t.Errorf("unexpected record: %+v", record)
+	}
+
+	if _, err := model.BatchEmbed(ctx, []string{"abcd"}); err != nil {
+		t.Fatal(err)
+	}
+	if record := <-records; record.InputCount != 1 || record.ModelType != types.ModelTypeEmbedding {
+		t.Errorf("unexpected record: %+v", record)
+	}
+}
@@ -112,6 +112,7 @@ func (s *userService) Register(ctx context.Context, req *types.RegisterRequest)
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
 		TenantID:     createdTenant.ID,
+		Role:         types.TenantRoleOwner,
 		IsActive:     true,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
@@ -156,6 +157,15 @@ func (s *userService) Login(ctx context.Context, req *types.LoginRequest) (*type
 		}, nil
 	}
 
+	// Users provisioned by single sign-on have no password
+	if user.PasswordHash == "" {
+		logger.Warn(ctx, "Password login attempted for SSO user")
+		return &types.LoginResponse{
+			Success: false,
+			Message: "This account signs in with SSO",
+		}, nil
+	}
+
 	// Verify password
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
@@ -231,6 +241,10 @@ func (s *userService) ChangePassword(ctx context.Context, userID string, oldPass
 		return err
 	}
 
+	if user.PasswordHash == "" {
+		return errors.New("password is managed by the identity provider")
+	}
+
 	// Verify old password
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(oldPassword))
 	if err != nil {
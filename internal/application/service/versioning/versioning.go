@@ -0,0 +1,161 @@
+// Package versioning compares knowledge versions and resolves the point in time of versioned searches
+package versioning
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+// ChunkContentHash returns the stored content hash of a chunk or computes it
+func ChunkContentHash(chunk *types.Chunk) string {
+	if chunk.ContentHash != "" {
+		return chunk.ContentHash
+	}
+	return types.CalculateChunkContentHash(chunk.Content)
+}
+
+// DocumentChunkDiff is the result of matching the chunks of a re-parsed document with its existing chunks
+type DocumentChunkDiff struct {
+	// Kept are the existing chunks with unchanged content, moved to their new position
+	Kept []*types.Chunk
+	// Added are the parsed chunks without an existing chunk of the same content
+	Added []*types.Chunk
+	// Removed are the existing chunks without a parsed chunk of the same content
+	Removed []*types.Chunk
+	// Summaries are the existing summary chunks, they are outdated once the content changes
+	Summaries []*types.Chunk
+}
+
+// Changed reports whether the content of the document has changed
+func (d *DocumentChunkDiff) Changed() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0
+}
+
+// DiffDocumentChunks matches parsed chunks with existing chunks by the content hash of the text chunks.
+// Image OCR and caption chunks follow their parent text chunk. Repeated content is matched in order.
+// Chunks of other types, such as data table summaries, are neither kept nor removed.
+func DiffDocumentChunks(existing []*types.Chunk, parsed []*types.Chunk) *DocumentChunkDiff {
+	diff := &DocumentChunkDiff{}
+
+	existingText := make([]*types.Chunk, 0, len(existing))
+	existingChildren := make(map[string][]*types.Chunk)
+	for _, chunk := range existing {
+		switch chunk.ChunkType {
+		case types.ChunkTypeText:
+			existingText = append(existingText, chunk)
+		case types.ChunkTypeImageOCR, types.ChunkTypeImageCaption:
+			existingChildren[chunk.ParentChunkID] = append(existingChildren[chunk.ParentChunkID], chunk)
+		case types.ChunkTypeSummary:
+			diff.Summaries = append(diff.Summaries, chunk)
+		}
+	}
+	sort.SliceStable(existingText, func(i, j int) bool {
+		return existingText[i].ChunkIndex < existingText[j].ChunkIndex
+	})
+
+	// Chunks created before content hashes were stored are hashed on the fly
+	candidates := make(map[string][]*types.Chunk, len(existingText))
+	for _, chunk := range existingText {
+		hash := ChunkContentHash(chunk)
+		candidates[hash] = append(candidates[hash], chunk)
+	}
+
+	parsedChildren := make(map[string][]*types.Chunk)
+	for _, chunk := range parsed {
+		if chunk.ChunkType != types.ChunkTypeText {
+			parsedChildren[chunk.ParentChunkID] = append(parsedChildren[chunk.ParentChunkID], chunk)
+		}
+	}
+
+	for _, chunk := range parsed {
+		if chunk.ChunkType != types.ChunkTypeText {
+			continue
+		}
+		matches := candidates[chunk.ContentHash]
+		if len(matches) == 0 {
+			diff.Added = append(diff.Added, chunk)
+			diff.Added = append(diff.Added, parsedChildren[chunk.ID]...)
+			continue
+		}
+		match := matches[0]
+		candidates[chunk.ContentHash] = matches[1:]
+
+		match.ContentHash = chunk.ContentHash
+		match.ChunkIndex = chunk.ChunkIndex
+		match.StartAt = chunk.StartAt
+		match.EndAt = chunk.EndAt
+		diff.Kept = append(diff.Kept, match)
+		diff.Kept = append(diff.Kept, existingChildren[match.ID]...)
+	}
+
+	// Existing chunks left unmatched are removed
+	for _, chunk := range existingText {
+		if slices.Contains(candidates[ChunkContentHash(chunk)], chunk) {
+			diff.Removed = append(diff.Removed, chunk)
+			diff.Removed = append(diff.Removed, existingChildren[chunk.ID]...)
+		}
+	}
+	return diff
+}
+
+// DiffChunks matches the chunks of two versions by content hash, repeated content is matched by count
+func DiffChunks(from []*types.Chunk, to []*types.Chunk) *types.KnowledgeVersionDiff {
+	toVersionChunk := func(chunk *types.Chunk) *types.KnowledgeVersionChunk {
+		return &types.KnowledgeVersionChunk{ChunkID: chunk.ID, ChunkIndex: chunk.ChunkIndex, Content: chunk.Content}
+	}
+	diff := &types.KnowledgeVersionDiff{
+		Added:   []*types.KnowledgeVersionChunk{},
+		Removed: []*types.KnowledgeVersionChunk{},
+	}
+
+	remaining := make(map[string]int, len(from))
+	for _, chunk := range from {
+		remaining[ChunkContentHash(chunk)]++
+	}
+	for _, chunk := range to {
+		hash := ChunkContentHash(chunk)
+		if remaining[hash] > 0 {
+			remaining[hash]--
+			diff.UnchangedCount++
+			continue
+		}
+		diff.Added = append(diff.Added, toVersionChunk(chunk))
+	}
+	// The last occurrences of repeated content left unmatched are the removed ones
+	for i := len(from) - 1; i >= 0; i-- {
+		hash := ChunkContentHash(from[i])
+		if remaining[hash] > 0 {
+			remaining[hash]--
+			diff.Removed = append([]*types.KnowledgeVersionChunk{toVersionChunk(from[i])}, diff.Removed...)
+		}
+	}
+	return diff
+}
+
+// AsOf resolves the point in time of a search, the time of a version takes precedence
+func AsOf(ctx context.Context,
+	versionRepo interfaces.KnowledgeVersionRepository, tenantID uint64, params types.SearchParams,
+) (*time.Time, error) {
+	if params.AsOfVersion <= 0 {
+		return params.AsOf, nil
+	}
+	if len(params.KnowledgeIDs) != 1 {
+		return nil, werrors.NewBadRequestError("버전 기준 검색은 지식 하나만 지정해야 합니다")
+	}
+	version, err := versionRepo.GetVersion(ctx, tenantID, params.KnowledgeIDs[0], params.AsOfVersion)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, werrors.NewNotFoundError("지식 버전을 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	return &version.CreatedAt, nil
+}
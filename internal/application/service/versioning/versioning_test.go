@@ -0,0 +1,266 @@
+package versioning
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"gorm.io/gorm"
+)
+
+func textChunks(contents ...string) []*types.Chunk {
+	chunks := make([]*types.Chunk, 0, len(contents))
+	for i, content := range contents {
+		chunks = append(chunks, &types.Chunk{ID: fmt.Sprintf("c%d", i), ChunkIndex: i, Content: content})
+	}
+	return chunks
+}
+
+func versionContents(chunks []*types.KnowledgeVersionChunk) []string {
+	contents := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		contents = append(contents, chunk.Content)
+	}
+	return contents
+}
+
+func TestDiffChunks(t *testing.T) {
+	tests := []struct {
+		name      string
+		from, to  []string
+		added     []string
+		removed   []string
+		unchanged int
+	}{
+		{name: "identical", from: []string{"a", "b"}, to: []string{"a", "b"}, unchanged: 2},
+		{name: "reordered", from: []string{"a", "b"}, to: []string{"b", "a"}, unchanged: 2},
+		{name: "edited", from: []string{"a", "b", "c"}, to: []string{"a", "b2", "c"},
+			added: []string{"b2"}, removed: []string{"b"}, unchanged: 2},
+		{name: "repeated content removed", from: []string{"x", "a", "x"}, to: []string{"x", "a"},
+			removed: []string{"x"}, unchanged: 2},
+		{name: "repeated content added", from: []string{"x"}, to: []string{"x", "x"},
+			added: []string{"x"}, unchanged: 1},
+		{name: "first version", to: []string{"a"}, added: []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffChunks(textChunks(tt.from...), textChunks(tt.to...))
+			if got := versionContents(diff.Added); !slices.Equal(got, tt.added) {
+				t.Errorf("Added = %v, want %v", got, tt.added)
+			}
+			if got := versionContents(diff.Removed); !slices.Equal(got, tt.removed) {
+				t.Errorf("Removed = %v, want %v", got, tt.removed)
+			}
+			if diff.UnchangedCount != tt.unchanged {
+				t.Errorf("UnchangedCount = %d, want %d", diff.UnchangedCount, tt.unchanged)
+			}
+		})
+	}
+
+	// The last occurrence of repeated content is the removed one
+	from := textChunks("x", "a", "x")
+	diff := DiffChunks(from, textChunks("x", "a"))
+	if len(diff.Removed) != 1 || diff.Removed[0].ChunkID != from[2].ID || diff.Removed[0].ChunkIndex != 2 {
+		t.Errorf("Removed = %+v, want chunk %s", diff.Removed, from[2].ID)
+	}
+}
+
+// textChunk builds a text chunk of a document, legacy chunks have no stored content hash
+func textChunk(id string, index int, content string, legacy bool) *types.Chunk {
+	chunk := &types.Chunk{ID: id, ChunkIndex: index, Content: content, ChunkType: types.ChunkTypeText}
+	if !legacy {
+		chunk.ContentHash = types.CalculateChunkContentHash(content)
+	}
+	return chunk
+}
+
+func childChunk(id string, chunkType types.ChunkType, parentID string) *types.Chunk {
+	return &types.Chunk{ID: id, Content: id, ChunkType: chunkType, ParentChunkID: parentID}
+}
+
+func chunkIDs(chunks []*types.Chunk) []string {
+	ids := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		ids = append(ids, chunk.ID)
+	}
+	return ids
+}
+
+func TestDiffDocumentChunks(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  []*types.Chunk
+		parsed    []*types.Chunk
+		kept      []string
+		added     []string
+		removed   []string
+		summaries []string
+		changed   bool
+	}{
+		{
+			name:     "unchanged",
+			existing: []*types.Chunk{textChunk("e0", 0, "a", false), textChunk("e1", 1, "b", false)},
+			parsed:   []*types.Chunk{textChunk("p0", 0, "a", false), textChunk("p1", 1, "b", false)},
+			kept:     []string{"e0", "e1"},
+		},
+		{
+			name:     "reordered",
+			existing: []*types.Chunk{textChunk("e0", 0, "a", false), textChunk("e1", 1, "b", false)},
+			parsed:   []*types.Chunk{textChunk("p0", 0, "b", false), textChunk("p1", 1, "a", false)},
+			kept:     []string{"e1", "e0"},
+		},
+		{
+			name:     "edited",
+			existing: []*types.Chunk{textChunk("e0", 0, "a", false), textChunk("e1", 1, "b", false)},
+			parsed:   []*types.Chunk{textChunk("p0", 0, "a", false), textChunk("p1", 1, "b2", false)},
+			kept:     []string{"e0"},
+			added:    []string{"p1"},
+			removed:  []string{"e1"},
+			changed:  true,
+		},
+		{
+			name: "duplicate content removed",
+			existing: []*types.Chunk{
+				textChunk("e0", 0, "x", false), textChunk("e1", 1, "a", false), textChunk("e2", 2, "x", false),
+			},
+			parsed:  []*types.Chunk{textChunk("p0", 0, "x", false), textChunk("p1", 1, "a", false)},
+			kept:    []string{"e0", "e1"},
+			removed: []string{"e2"},
+			changed: true,
+		},
+		{
+			name:     "duplicate content added",
+			existing: []*types.Chunk{textChunk("e0", 0, "x", false)},
+			parsed:   []*types.Chunk{textChunk("p0", 0, "x", false), textChunk("p1", 1, "x", false)},
+			kept:     []string{"e0"},
+			added:    []string{"p1"},
+			changed:  true,
+		},
+		{
+			name: "children follow their parent",
+			existing: []*types.Chunk{
+				textChunk("e0", 0, "a", false), childChunk("e0-ocr", types.ChunkTypeImageOCR, "e0"),
+				textChunk("e1", 1, "b", false), childChunk("e1-caption", types.ChunkTypeImageCaption, "e1"),
+			},
+			parsed: []*types.Chunk{
+				textChunk("p0", 0, "a", false), childChunk("p0-ocr", types.ChunkTypeImageOCR, "p0"),
+				textChunk("p1", 1, "c", false), childChunk("p1-caption", types.ChunkTypeImageCaption, "p1"),
+			},
+			kept:    []string{"e0", "e0-ocr"},
+			added:   []string{"p1", "p1-caption"},
+			removed: []string{"e1", "e1-caption"},
+			changed: true,
+		},
+		{
+			name:     "legacy chunks without stored hash",
+			existing: []*types.Chunk{textChunk("e0", 0, "a", true), textChunk("e1", 1, "b", true)},
+			parsed:   []*types.Chunk{textChunk("p0", 0, "a", false), textChunk("p1", 1, "c", false)},
+			kept:     []string{"e0"},
+			added:    []string{"p1"},
+			removed:  []string{"e1"},
+			changed:  true,
+		},
+		{
+			name: "summaries and other chunk types",
+			existing: []*types.Chunk{
+				textChunk("e0", 0, "a", false),
+				{ID: "summary", ChunkType: types.ChunkTypeSummary},
+				{ID: "table", ChunkType: types.ChunkTypeTableSummary},
+			},
+			parsed:    []*types.Chunk{textChunk("p0", 0, "a", false)},
+			kept:      []string{"e0"},
+			summaries: []string{"summary"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffDocumentChunks(tt.existing, tt.parsed)
+			if got := chunkIDs(diff.Kept); !slices.Equal(got, tt.kept) {
+				t.Errorf("Kept = %v, want %v", got, tt.kept)
+			}
+			if got := chunkIDs(diff.Added); !slices.Equal(got, tt.added) {
+				t.Errorf("Added = %v, want %v", got, tt.added)
+			}
+			if got := chunkIDs(diff.Removed); !slices.Equal(got, tt.removed) {
+				t.Errorf("Removed = %v, want %v", got, tt.removed)
+			}
+			if got := chunkIDs(diff.Summaries); !slices.Equal(got, tt.summaries) {
+				t.Errorf("Summaries = %v, want %v", got, tt.summaries)
+			}
+			if diff.Changed() != tt.changed {
+				t.Errorf("Changed() = %v, want %v", diff.Changed(), tt.changed)
+			}
+		})
+	}
+
+	// Kept chunks move to the position of their parsed chunk and store its hash
+	existing := []*types.Chunk{textChunk("e0", 0, "a", true), textChunk("e1", 1, "b", true)}
+	diff := DiffDocumentChunks(existing, []*types.Chunk{textChunk("p0", 0, "b", false), textChunk("p1", 1, "a", false)})
+	for _, chunk := range diff.Kept {
+		want := map[string]int{"e0": 1, "e1": 0}[chunk.ID]
+		if chunk.ChunkIndex != want {
+			t.Errorf("ChunkIndex of %s = %d, want %d", chunk.ID, chunk.ChunkIndex, want)
+		}
+		if chunk.ContentHash != types.CalculateChunkContentHash(chunk.Content) {
+			t.Errorf("ContentHash of %s = %q, want the hash of its content", chunk.ID, chunk.ContentHash)
+		}
+	}
+}
+
+// fakeVersionRepository serves the versions of one knowledge
+type fakeVersionRepository struct {
+	interfaces.KnowledgeVersionRepository
+	versions map[int]*types.KnowledgeVersion
+}
+
+func (r *fakeVersionRepository) GetVersion(
+	ctx context.Context, tenantID uint64, knowledgeID string, version int,
+) (*types.KnowledgeVersion, error) {
+	v, ok := r.versions[version]
+	if !ok || v.TenantID != tenantID || v.KnowledgeID != knowledgeID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return v, nil
+}
+
+func TestAsOf(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeVersionRepository{versions: map[int]*types.KnowledgeVersion{
+		2: {TenantID: 1, KnowledgeID: "k1", Version: 2, CreatedAt: created},
+	}}
+	ctx := context.Background()
+
+	got, err := AsOf(ctx, repo, 1, types.SearchParams{AsOf: &asOf})
+	if err != nil || got != &asOf {
+		t.Errorf("AsOf() without version = %v, %v, want the requested time", got, err)
+	}
+	got, err = AsOf(ctx, repo, 1, types.SearchParams{AsOf: &asOf, AsOfVersion: 2, KnowledgeIDs: []string{"k1"}})
+	if err != nil || got == nil || !got.Equal(created) {
+		t.Errorf("AsOf() of version 2 = %v, %v, want %v", got, err, created)
+	}
+
+	errorCode := func(err error) werrors.ErrorCode {
+		if appErr, ok := werrors.IsAppError(err); ok {
+			return appErr.Code
+		}
+		return 0
+	}
+	_, err = AsOf(ctx, repo, 1, types.SearchParams{AsOfVersion: 2, KnowledgeIDs: []string{"k1", "k2"}})
+	if errorCode(err) != werrors.ErrBadRequest {
+		t.Errorf("AsOf() with two knowledge items error = %v, want bad request", err)
+	}
+	_, err = AsOf(ctx, repo, 1, types.SearchParams{AsOfVersion: 3, KnowledgeIDs: []string{"k1"}})
+	if errorCode(err) != werrors.ErrNotFound {
+		t.Errorf("AsOf() of a missing version error = %v, want not found", err)
+	}
+	_, err = AsOf(ctx, repo, 2, types.SearchParams{AsOfVersion: 2, KnowledgeIDs: []string{"k1"}})
+	if errorCode(err) != werrors.ErrNotFound {
+		t.Errorf("AsOf() of another tenant error = %v, want not found", err)
+	}
+}
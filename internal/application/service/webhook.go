@@ -0,0 +1,518 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"github.com/Tencent/WeKnora/internal/application/repository"
+	"github.com/Tencent/WeKnora/internal/application/service/sso"
+	"github.com/Tencent/WeKnora/internal/application/service/webhook"
+	"github.com/Tencent/WeKnora/internal/config"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+)
+
+const (
+	// webhookSecretPrefix marks webhook signing secrets
+	webhookSecretPrefix = "whsec_"
+	// webhookSecretRandomBytes is the number of random bytes of a signing secret
+	webhookSecretRandomBytes = 32
+	// defaultWebhookTimeout is the request timeout used when none is configured
+	defaultWebhookTimeout = 10 * time.Second
+	// defaultWebhookMaxRetries is the number of retries used when none is configured,
+	// with the exponential backoff of asynq the last retry happens about a day after the event
+	defaultWebhookMaxRetries = 8
+	// webhookUserAgent is the user agent of webhook requests
+	webhookUserAgent = "WeKnora-Webhook/1.0"
+)
+
+// webhookService implements the WebhookService interface
+type webhookService struct {
+	repo   interfaces.WebhookRepository
+	task   *asynq.Client
+	cfg    *config.Config
+	client *http.Client
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(
+	repo interfaces.WebhookRepository,
+	task *asynq.Client,
+	cfg *config.Config,
+) interfaces.WebhookService {
+	s := &webhookService{repo: repo, task: task, cfg: cfg}
+	s.client = &http.Client{
+		Timeout: s.timeout(),
+		// Receivers are dialed directly and only on public addresses, unless their host is allowed
+		Transport: &http.Transport{
+			DialContext:         webhook.DialContext(&net.Dialer{Timeout: s.timeout()}, s.allowedHosts()),
+			TLSHandshakeTimeout: s.timeout(),
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// Redirects are not followed, the configured URL must answer itself
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return s
+}
+
+// timeout returns the configured request timeout
+func (s *webhookService) timeout() time.Duration {
+	if s.cfg != nil && s.cfg.Webhook != nil && s.cfg.Webhook.Timeout > 0 {
+		return time.Duration(s.cfg.Webhook.Timeout) * time.Second
+	}
+	return defaultWebhookTimeout
+}
+
+// maxRetries returns the configured number of retries
+func (s *webhookService) maxRetries() int {
+	if s.cfg != nil && s.cfg.Webhook != nil && s.cfg.Webhook.MaxRetries > 0 {
+		return s.cfg.Webhook.MaxRetries
+	}
+	return defaultWebhookMaxRetries
+}
+
+// allowedHosts returns the configured hosts that may resolve to private addresses
+func (s *webhookService) allowedHosts() []string {
+	if s.cfg != nil && s.cfg.Webhook != nil {
+		return s.cfg.Webhook.AllowedHosts
+	}
+	return nil
+}
+
+// newWebhookSecret returns a random signing secret
+func newWebhookSecret() (string, error) {
+	random, err := sso.RandomToken(webhookSecretRandomBytes)
+	if err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + random, nil
+}
+
+// validateWebhookURL checks that a webhook URL is an http(s) URL whose host is not a private address,
+// host names are checked again when they are resolved for each delivery
+func (s *webhookService) validateWebhookURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !secutils.IsValidURL(rawURL) {
+		return "", werrors.NewBadRequestError("올바르지 않은 웹훅 URL입니다").WithDetails(rawURL)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", werrors.NewBadRequestError("올바르지 않은 웹훅 URL입니다").WithDetails(rawURL)
+	}
+	if err := webhook.CheckHost(s.allowedHosts(), parsed.Hostname()); err != nil {
+		return "", werrors.NewBadRequestError("내부 네트워크 주소로는 웹훅을 보낼 수 없습니다").WithDetails(rawURL)
+	}
+	return rawURL, nil
+}
+
+// validateWebhookEvents checks the subscribed events and removes duplicates
+func validateWebhookEvents(events []string) (types.StringArray, error) {
+	if len(events) == 0 {
+		return nil, werrors.NewBadRequestError("구독할 이벤트를 하나 이상 지정해야 합니다")
+	}
+	result := make(types.StringArray, 0, len(events))
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if !types.WebhookEvent(event).IsValid() {
+			return nil, werrors.NewBadRequestError("올바르지 않은 웹훅 이벤트입니다: " + event)
+		}
+		if !slices.Contains(result, event) {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// ListWebhooks lists the webhooks of the tenant in the context
+func (s *webhookService) ListWebhooks(ctx context.Context) ([]*types.Webhook, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	return s.repo.ListWebhooks(ctx, tenantID)
+}
+
+// GetWebhook gets a webhook of the tenant in the context
+func (s *webhookService) GetWebhook(ctx context.Context, id string) (*types.Webhook, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	hook, err := s.repo.GetWebhook(ctx, tenantID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return nil, werrors.NewNotFoundError("웹훅을 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	return hook, nil
+}
+
+// CreateWebhook creates a webhook for the tenant in the context, the signing secret is returned only here
+func (s *webhookService) CreateWebhook(ctx context.Context,
+	req *types.CreateWebhookRequest,
+) (*types.WebhookSecretResponse, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, werrors.NewBadRequestError("웹훅 이름은 필수입니다")
+	}
+	url, err := s.validateWebhookURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	events, err := validateWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	hook := &types.Webhook{
+		TenantID:    tenantID,
+		Name:        name,
+		URL:         url,
+		Secret:      secret,
+		Events:      events,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Description: req.Description,
+	}
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil {
+		hook.CreatedBy = subject.UserID
+	}
+	if err := s.repo.CreateWebhook(ctx, hook); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		return nil, err
+	}
+
+	logger.Infof(ctx, "Webhook created, ID: %s, tenant: %d, events: %v", hook.ID, tenantID, events)
+	return &types.WebhookSecretResponse{Webhook: hook, Secret: secret}, nil
+}
+
+// UpdateWebhook updates a webhook of the tenant in the context, the signing secret is returned when it is rotated
+func (s *webhookService) UpdateWebhook(ctx context.Context,
+	id string, req *types.UpdateWebhookRequest,
+) (*types.WebhookSecretResponse, error) {
+	hook, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, werrors.NewBadRequestError("웹훅 이름은 필수입니다")
+		}
+		hook.Name = name
+	}
+	if req.URL != nil {
+		if hook.URL, err = s.validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+	}
+	if req.Events != nil {
+		if hook.Events, err = validateWebhookEvents(req.Events); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		hook.Description = *req.Description
+	}
+	if req.Enabled != nil {
+		hook.Enabled = *req.Enabled
+	}
+	response := &types.WebhookSecretResponse{Webhook: hook}
+	if req.RotateSecret {
+		if hook.Secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+		response.Secret = hook.Secret
+	}
+	hook.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateWebhook(ctx, hook); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"webhook_id": id})
+		return nil, err
+	}
+	logger.Infof(ctx, "Webhook updated, ID: %s, secret rotated: %v", id, req.RotateSecret)
+	return response, nil
+}
+
+// DeleteWebhook deletes a webhook of the tenant in the context and its delivery log
+func (s *webhookService) DeleteWebhook(ctx context.Context, id string) error {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	if err := s.repo.DeleteWebhook(ctx, tenantID, id); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return werrors.NewNotFoundError("웹훅을 찾을 수 없습니다")
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"webhook_id": id})
+		return err
+	}
+	logger.Infof(ctx, "Webhook deleted, ID: %s, tenant: %d", id, tenantID)
+	return nil
+}
+
+// TestWebhook sends a ping event to a webhook, whatever events it subscribes to
+func (s *webhookService) TestWebhook(ctx context.Context, id string) (*types.WebhookDelivery, error) {
+	hook, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	eventID, payload, err := newWebhookPayload(hook.TenantID, types.WebhookEventPing,
+		map[string]string{"webhook_id": hook.ID})
+	if err != nil {
+		return nil, err
+	}
+	return s.deliver(ctx, hook, eventID, types.WebhookEventPing, payload)
+}
+
+// ListDeliveries lists the deliveries of a webhook of the tenant in the context, newest first
+func (s *webhookService) ListDeliveries(ctx context.Context,
+	webhookID string, filter *types.WebhookDeliveryFilter, page *types.Pagination,
+) (*types.PageResult, error) {
+	hook, err := s.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, total, err := s.repo.ListDeliveries(ctx, hook.TenantID, hook.ID, filter, page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"webhook_id": webhookID})
+		return nil, err
+	}
+	return types.NewPageResult(total, page, deliveries), nil
+}
+
+// GetDelivery gets a delivery of a webhook of the tenant in the context
+func (s *webhookService) GetDelivery(ctx context.Context, webhookID, id string) (*types.WebhookDelivery, error) {
+	tenantID := ctx.Value(types.TenantIDContextKey).(uint64)
+	delivery, err := s.repo.GetDelivery(ctx, tenantID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+			return nil, werrors.NewNotFoundError("웹훅 전달 기록을 찾을 수 없습니다")
+		}
+		return nil, err
+	}
+	if delivery.WebhookID != webhookID {
+		return nil, werrors.NewNotFoundError("웹훅 전달 기록을 찾을 수 없습니다")
+	}
+	return delivery, nil
+}
+
+// Redeliver sends the event of a delivery again as a new delivery with the same event ID,
+// so that receivers deduplicating on the event ID can tell it apart from a new event
+func (s *webhookService) Redeliver(ctx context.Context, webhookID, id string) (*types.WebhookDelivery, error) {
+	hook, err := s.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	original, err := s.GetDelivery(ctx, webhookID, id)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof(ctx, "Redelivering webhook delivery %s of event %s", original.ID, original.EventID)
+	return s.deliver(ctx, hook, original.EventID, original.Event, original.Payload)
+}
+
+// newWebhookPayload returns the ID and the request body of a new event
+func newWebhookPayload(tenantID uint64, event types.WebhookEvent, data interface{}) (string, types.JSON, error) {
+	eventID := uuid.New().String()
+	payload, err := json.Marshal(types.WebhookEnvelope{
+		ID:        eventID,
+		Type:      event,
+		CreatedAt: time.Now().UTC(),
+		TenantID:  tenantID,
+		Data:      data,
+	})
+	return eventID, payload, err
+}
+
+// Publish delivers an event of the tenant in the context to the subscribed webhooks.
+// Failures are logged only, publishing must not fail the caller.
+func (s *webhookService) Publish(ctx context.Context, event types.WebhookEvent, data interface{}) {
+	tenantID, ok := ctx.Value(types.TenantIDContextKey).(uint64)
+	if !ok || tenantID == 0 {
+		return
+	}
+	hooks, err := s.repo.ListEnabledWebhooks(ctx, tenantID)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to list webhooks of tenant %d for event %s: %v", tenantID, event, err)
+		return
+	}
+	hooks = slices.DeleteFunc(hooks, func(hook *types.Webhook) bool { return !hook.Subscribes(event) })
+	if len(hooks) == 0 {
+		return
+	}
+
+	eventID, payload, err := newWebhookPayload(tenantID, event, data)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to encode webhook event %s: %v", event, err)
+		return
+	}
+	for _, hook := range hooks {
+		if _, err := s.deliver(ctx, hook, eventID, event, payload); err != nil {
+			logger.Warnf(ctx, "Failed to deliver event %s to webhook %s: %v", event, hook.ID, err)
+		}
+	}
+}
+
+// deliver records a delivery of an event to a webhook and enqueues the task sending it
+func (s *webhookService) deliver(ctx context.Context, hook *types.Webhook,
+	eventID string, event types.WebhookEvent, payload types.JSON,
+) (*types.WebhookDelivery, error) {
+	delivery := &types.WebhookDelivery{
+		TenantID:  hook.TenantID,
+		WebhookID: hook.ID,
+		EventID:   eventID,
+		Event:     event,
+		Payload:   payload,
+		Status:    types.WebhookDeliveryPending,
+	}
+	if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	taskPayload, err := json.Marshal(types.WebhookDeliveryPayload{TenantID: hook.TenantID, DeliveryID: delivery.ID})
+	if err != nil {
+		return nil, err
+	}
+	task := asynq.NewTask(types.TypeWebhookDelivery, taskPayload,
+		asynq.Queue("default"), asynq.MaxRetry(s.maxRetries()), asynq.Timeout(2*s.timeout()))
+	if _, err := s.task.Enqueue(task); err != nil {
+		delivery.Status = types.WebhookDeliveryFailed
+		delivery.Error = "failed to enqueue delivery: " + err.Error()
+		delivery.UpdatedAt = time.Now()
+		if updateErr := s.repo.UpdateDelivery(ctx, delivery); updateErr != nil {
+			logger.Warnf(ctx, "Failed to update webhook delivery %s: %v", delivery.ID, updateErr)
+		}
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+// ProcessWebhookDelivery sends a delivery.
+// A failed attempt returns an error so that asynq retries it with backoff, the last failed attempt marks the delivery failed.
+func (s *webhookService) ProcessWebhookDelivery(ctx context.Context, t *asynq.Task) error {
+	var payload types.WebhookDeliveryPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		logger.Errorf(ctx, "failed to unmarshal webhook delivery task payload: %v", err)
+		return nil
+	}
+	ctx = context.WithValue(ctx, types.TenantIDContextKey, payload.TenantID)
+
+	delivery, err := s.repo.GetDelivery(ctx, payload.TenantID, payload.DeliveryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+			return nil
+		}
+		return err
+	}
+	if delivery.Status == types.WebhookDeliverySuccess || delivery.Status == types.WebhookDeliveryFailed {
+		return nil
+	}
+	hook, err := s.repo.GetWebhook(ctx, payload.TenantID, delivery.WebhookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	retryCount, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	isLastRetry := retryCount >= maxRetry
+
+	delivery.Attempts++
+	sendErr := s.send(ctx, hook, delivery)
+	now := time.Now()
+	delivery.UpdatedAt = now
+	switch {
+	case sendErr == nil:
+		delivery.Status = types.WebhookDeliverySuccess
+		delivery.Error = ""
+		delivery.DeliveredAt = &now
+	case isLastRetry:
+		delivery.Status = types.WebhookDeliveryFailed
+		delivery.Error = sendErr.Error()
+	default:
+		delivery.Status = types.WebhookDeliveryRetrying
+		delivery.Error = sendErr.Error()
+	}
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		logger.Warnf(ctx, "Failed to update webhook delivery %s: %v", delivery.ID, err)
+	}
+
+	if sendErr != nil {
+		logger.Warnf(ctx, "Webhook delivery %s to %s failed, attempt %d: %v",
+			delivery.ID, hook.ID, delivery.Attempts, sendErr)
+		if isLastRetry {
+			return nil
+		}
+		return sendErr
+	}
+	logger.Infof(ctx, "Webhook delivery %s to %s succeeded, attempt %d", delivery.ID, hook.ID, delivery.Attempts)
+	return nil
+}
+
+// send posts a delivery to the webhook URL and records the response status, non-2xx responses are errors.
+// The response body is discarded so a receiver cannot read back internal responses through the delivery log.
+func (s *webhookService) send(ctx context.Context, hook *types.Webhook, delivery *types.WebhookDelivery) error {
+	if !hook.Enabled {
+		return errors.New("webhook is disabled")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set(types.WebhookEventHeader, string(delivery.Event))
+	req.Header.Set(types.WebhookEventIDHeader, delivery.EventID)
+	req.Header.Set(types.WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(types.WebhookSignatureHeader, webhook.Sign(hook.Secret, time.Now(), delivery.Payload))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.ResponseStatus = 0
+		return err
+	}
+	defer resp.Body.Close()
+	delivery.ResponseStatus = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ProcessWebhookRetention deletes the deliveries older than the retention period, it runs periodically
+func (s *webhookService) ProcessWebhookRetention(ctx context.Context, t *asynq.Task) error {
+	if s.cfg == nil || s.cfg.Webhook == nil || s.cfg.Webhook.DeliveryRetentionDays <= 0 {
+		return nil
+	}
+	before := time.Now().AddDate(0, 0, -s.cfg.Webhook.DeliveryRetentionDays)
+	deleted, err := s.repo.PurgeDeliveries(ctx, before)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to purge webhook deliveries: %v", err)
+		return nil
+	}
+	if deleted > 0 {
+		logger.Infof(ctx, "Purged %d webhook deliveries older than %s", deleted, before.Format(time.DateOnly))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// ErrBlockedAddress is returned when a webhook host is or resolves to an address that is not publicly routable
+var ErrBlockedAddress = errors.New("webhook address is not allowed")
+
+// reservedPrefixes are the special purpose ranges not covered by the netip predicates,
+// including the translation ranges that can embed a private IPv4 address
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// IsPublicAddr reports whether an address is a publicly routable unicast address
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// HostAllowed reports whether a host is in the allowlist of hosts that may be private, case insensitively
+func HostAllowed(allowedHosts []string, host string) bool {
+	for _, allowed := range allowedHosts {
+		if strings.EqualFold(strings.TrimSpace(allowed), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHost rejects a host that is an IP literal or localhost name which is not publicly routable,
+// host names are checked again when they are resolved for each request
+func CheckHost(allowedHosts []string, host string) error {
+	if host == "" || HostAllowed(allowedHosts, host) {
+		return nil
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil && !IsPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// DialContext returns a dial function that resolves the host itself and connects only to public addresses,
+// so a host name cannot be rebound to an internal address after it was validated.
+// Hosts in the allowlist are dialed without the check.
+func DialContext(dialer *net.Dialer, allowedHosts []string) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if HostAllowed(allowedHosts, host) {
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if !IsPublicAddr(addr) {
+				return nil, fmt.Errorf("%w: %s resolves to %s", ErrBlockedAddress, host, addr)
+			}
+		}
+
+		// Connect to the checked addresses instead of resolving the host again
+		var lastErr error = fmt.Errorf("%w: %s has no address", ErrBlockedAddress, host)
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
@@ -0,0 +1,83 @@
+// Package webhook signs webhook requests and verifies their signatures.
+//
+// A request is signed with HMAC-SHA256 over "<unix seconds>.<body>" using the secret of the webhook,
+// and the X-WeKnora-Signature header carries "t=<unix seconds>,v1=<hex signature>".
+// Receivers recompute the signature and reject requests whose timestamp is too old to prevent replays.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signatureVersion is the scheme of the signatures produced by Sign
+const signatureVersion = "v1"
+
+var (
+	// ErrInvalidSignatureHeader is returned when a signature header cannot be parsed
+	ErrInvalidSignatureHeader = errors.New("invalid webhook signature header")
+	// ErrSignatureMismatch is returned when no signature of the header matches the body
+	ErrSignatureMismatch = errors.New("webhook signature mismatch")
+	// ErrSignatureExpired is returned when the timestamp of the header is outside the tolerance
+	ErrSignatureExpired = errors.New("webhook signature timestamp outside tolerance")
+)
+
+// Sign returns the signature header of a body sent at the given time
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,%s=%s", ts, signatureVersion, compute(secret, ts, body))
+}
+
+// Verify checks a signature header against a body.
+// A tolerance of zero skips the timestamp check.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case signatureVersion:
+			signatures = append(signatures, value)
+		}
+	}
+	if ts == "" || len(signatures) == 0 {
+		return ErrInvalidSignatureHeader
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignatureHeader
+	}
+	if tolerance > 0 {
+		if diff := now.Sub(time.Unix(unix, 0)); diff > tolerance || diff < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	expected := compute(secret, ts, body)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+// compute returns the hex HMAC-SHA256 of "<timestamp>.<body>"
+func compute(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
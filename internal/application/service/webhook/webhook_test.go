@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"knowledge.parsed"}`)
+	header := Sign("secret", now, body)
+
+	if err := Verify("secret", header, body, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Fatalf("a fresh signature should verify: %v", err)
+	}
+	if err := Verify("other", header, body, 0, now); !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("another secret should not verify, got %v", err)
+	}
+	if err := Verify("secret", header, []byte(`{}`), 0, now); !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("another body should not verify, got %v", err)
+	}
+	if err := Verify("secret", header, body, 5*time.Minute, now.Add(time.Hour)); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("an old signature should expire, got %v", err)
+	}
+	if err := Verify("secret", "v1=abc", body, 0, now); !errors.Is(err, ErrInvalidSignatureHeader) {
+		t.Errorf("a header without timestamp is invalid, got %v", err)
+	}
+}
+
+func TestVerifyRotatedSecret(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("body")
+	header := Sign("old", now, body) + "," + Sign("new", now, body)[len("t=1700000000,"):]
+	if err := Verify("new", header, body, 0, now); err != nil {
+		t.Errorf("any of the signatures should verify: %v", err)
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":        true,
+		"2606:4700:4700::1111": true,
+		"127.0.0.1":            false,
+		"10.0.0.1":             false,
+		"172.16.5.4":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"fd00::1":              false,
+		"fe80::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"64:ff9b::a00:1":       false,
+		"2002:c0a8:101::1":     false,
+		"::ffff:93.184.216.34": true,
+		"ff02::1":              false,
+		"255.255.255.255":      false,
+		"198.51.100.7":         false,
+		"2001:db8::1":          false,
+		"2001:4860:4860::8888": true,
+		"8.8.8.8":              true,
+		"224.0.0.1":            false,
+		"fc00:ec:abcd:1234::1": false,
+		"::":                   false,
+		"192.0.2.10":           false,
+		"203.0.113.200":        false,
+		"198.18.0.1":           false,
+		"240.0.0.1":            false,
+		"192.0.0.8":            false,
+	}
+	for addr, want := range tests {
+		if got := IsPublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		allowed []string
+		blocked bool
+	}{
+		{"hooks.example.com", nil, false},
+		{"93.184.216.34", nil, false},
+		{"127.0.0.1", nil, true},
+		{"[::1]", nil, true},
+		{"LOCALHOST", nil, true},
+		{"api.localhost", nil, true},
+		{"169.254.169.254", nil, true},
+		{"10.0.0.8", []string{"10.0.0.8"}, false},
+	}
+	for _, tt := range tests {
+		err := CheckHost(tt.allowed, tt.host)
+		if tt.blocked != errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("CheckHost(%v, %s) = %v, want blocked %v", tt.allowed, tt.host, err, tt.blocked)
+		}
+	}
+}
+
+func TestDialContextRejectsPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	post := func(allowedHosts []string, host string) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: DialContext(&net.Dialer{Timeout: time.Second}, allowedHosts),
+		}}
+		return client.Post("http://"+net.JoinHostPort(host, port), "application/json", nil)
+	}
+
+	// Host names are resolved when dialing, so a name pointing at an internal address is rejected too
+	for _, host := range []string{"127.0.0.1", "localhost"} {
+		if resp, err := post(nil, host); !errors.Is(err, ErrBlockedAddress) {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			t.Errorf("dial %s error = %v, want ErrBlockedAddress", host, err)
+		}
+	}
+
+	resp, err := post([]string{"127.0.0.1"}, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("dial allowed host error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+}
@@ -27,6 +27,13 @@ type Config struct {
 	ExtractManager  *ExtractManagerConfig  `yaml:"extract"          json:"extract"`
 	WebSearch       *WebSearchConfig       `yaml:"web_search"       json:"web_search"`
 	PromptTemplates *PromptTemplatesConfig `yaml:"prompt_templates" json:"prompt_templates"`
+	SSO             *SSOConfig             `yaml:"sso"              json:"sso"`
+	Audit           *AuditConfig           `yaml:"audit"            json:"audit"`
+	Usage           *UsageConfig           `yaml:"usage"            json:"usage"`
+	Quota           *QuotaConfig           `yaml:"quota"            json:"quota"`
+	Webhook         *WebhookConfig         `yaml:"webhook"          json:"webhook"`
+	Feedback        *FeedbackConfig        `yaml:"feedback"         json:"feedback"`
+	Memory          *MemoryConfig          `yaml:"memory"           json:"memory"`
 }
 
 type DocReaderConfig struct {
@@ -281,7 +288,7 @@ func loadPromptTemplates(configDir string) (*PromptTemplatesConfig, error) {
 type WebSearchConfig struct {
 	Providers []WebSearchProviderConfig `yaml:"providers" json:"providers"`
 	Default   WebSearchDefaultConfig    `yaml:"default"   json:"default"`
-	Timeout   int                       `yaml:"timeout"   json:"timeout"` // 타임아웃(초)
+	Timeout   int                       `yaml:"timeout"                 json:"timeout"` // 타임아웃(초)
 }
 
 // WebSearchProviderConfig 웹 검색 제공자 구성
@@ -302,3 +309,114 @@ type WebSearchDefaultConfig struct {
 	CompressionMethod string   `yaml:"compression_method" json:"compression_method"`
 	Blacklist         []string `yaml:"blacklist"          json:"blacklist"`
 }
+
+// SSOConfig 외부 ID 공급자를 통한 싱글 사인온 구성
+type SSOConfig struct {
+	// FrontendURL 로그인 완료 후 토큰을 URL 프래그먼트로 전달할 프런트엔드 주소, 비어 있으면 JSON으로 응답
+	FrontendURL string `yaml:"frontend_url"           json:"frontend_url"`
+	// DisablePasswordLogin 비밀번호 로그인과 등록을 비활성화하고 SSO 로그인만 허용합니다.
+	DisablePasswordLogin bool                `yaml:"disable_password_login" json:"disable_password_login"`
+	Providers            []SSOProviderConfig `yaml:"providers"              json:"providers"`
+}
+
+// SSOProviderConfig SSO ID 공급자 구성
+type SSOProviderConfig struct {
+	Name        string `yaml:"name"         json:"name"`         // 공급자 식별자, URL 경로에 사용
+	Type        string `yaml:"type"         json:"type"`         // 유형: "oidc" 또는 "saml"
+	DisplayName string `yaml:"display_name" json:"display_name"` // 로그인 화면에 표시할 이름
+
+	// OIDC 구성
+	Issuer       string   `yaml:"issuer"        json:"issuer"`       // 발급자 URL, 디스커버리 문서의 기준
+	ClientID     string   `yaml:"client_id"     json:"client_id"`    // 클라이언트 ID
+	ClientSecret string   `yaml:"client_secret" json:"-"`            // 클라이언트 시크릿, 공개 클라이언트는 비워 둠
+	Scopes       []string `yaml:"scopes"        json:"scopes"`       // openid 외에 요청할 스코프
+	RedirectURL  string   `yaml:"redirect_url"  json:"redirect_url"` // 콜백 URL (/api/v1/auth/sso/{name}/callback)
+
+	// SAML 구성
+	EntityID       string `yaml:"entity_id"       json:"entity_id"`       // SP 엔티티 ID
+	ACSURL         string `yaml:"acs_url"         json:"acs_url"`         // 어설션 소비 서비스 URL (/api/v1/auth/sso/{name}/acs)
+	IdPEntityID    string `yaml:"idp_entity_id"   json:"idp_entity_id"`   // IdP 엔티티 ID, 어설션 발급자와 비교
+	IdPSSOURL      string `yaml:"idp_sso_url"     json:"idp_sso_url"`     // IdP SSO URL (HTTP-Redirect 바인딩)
+	IdPCertificate string `yaml:"idp_certificate" json:"idp_certificate"` // IdP 서명 인증서 (PEM)
+	// TrustEmail 어설션의 이메일을 IdP가 검증한 주소로 신뢰할지 여부, 기본값 false.
+	// 신뢰하지 않으면 같은 이메일의 기존 계정에 연결하지 않음
+	TrustEmail bool `yaml:"trust_email" json:"trust_email"`
+
+	// 클레임 또는 속성 이름, 비어 있으면 기본값 사용
+	EmailClaim  string `yaml:"email_claim"  json:"email_claim"`
+	NameClaim   string `yaml:"name_claim"   json:"name_claim"`
+	GroupsClaim string `yaml:"groups_claim" json:"groups_claim"`
+
+	// 사용자 프로비저닝 구성
+	TenantMappings []SSOTenantMapping `yaml:"tenant_mappings" json:"tenant_mappings"` // 순서대로 비교하여 처음 일치하는 매핑 사용
+	DefaultRole    string             `yaml:"default_role"    json:"default_role"`    // 매핑에 역할이 없을 때 사용할 역할, 기본값 viewer
+	CreateTenant   bool               `yaml:"create_tenant"   json:"create_tenant"`   // 일치하는 매핑이 없으면 개인 작업 공간 생성
+}
+
+// AuditConfig 감사 로그 구성
+type AuditConfig struct {
+	// RetentionDays 감사 로그 보존 기간(일), 0이면 삭제하지 않음
+	RetentionDays int `yaml:"retention_days" json:"retention_days"`
+	// HashKey 해시 체인의 HMAC 키, 설정하면 데이터베이스 접근만으로는 체인을 다시 계산할 수 없음
+	HashKey string `yaml:"hash_key"       json:"-"`
+}
+
+// UsageConfig 모델 사용량 계량 구성
+type UsageConfig struct {
+	// RecordRetentionDays 호출별 사용량 기록 보존 기간(일), 0이면 삭제하지 않음. 일별 집계는 삭제되지 않음
+	RecordRetentionDays int `yaml:"record_retention_days" json:"record_retention_days"`
+}
+
+// QuotaConfig 요청 수, 대화 스트림, 토큰과 지식 업로드 할당량 구성
+type QuotaConfig struct {
+	// Store 카운터 저장소 (redis, memory), redis는 여러 인스턴스가 카운터를 공유하며 오류 시 메모리로 대체
+	Store string `yaml:"store"   json:"store"`
+	// Tenant 정책이 없는 테넌트의 기본 제한
+	Tenant types.QuotaLimits `yaml:"tenant"  json:"tenant"`
+	// User 정책이 없는 사용자의 기본 제한
+	User types.QuotaLimits `yaml:"user"    json:"user"`
+	// APIKey 정책이 없는 테넌트 API 키의 기본 제한
+	APIKey types.QuotaLimits `yaml:"api_key" json:"api_key"`
+}
+
+// WebhookConfig 웹훅 전송 구성
+type WebhookConfig struct {
+	// Timeout 요청 제한 시간(초), 기본값 10
+	Timeout int `yaml:"timeout"                 json:"timeout"`
+	// MaxRetries 실패한 전송의 재시도 횟수, 기본값 8. 재시도 간격은 지수적으로 늘어남
+	MaxRetries int `yaml:"max_retries"             json:"max_retries"`
+	// DeliveryRetentionDays 전달 기록 보존 기간(일), 0이면 삭제하지 않음
+	DeliveryRetentionDays int `yaml:"delivery_retention_days" json:"delivery_retention_days"`
+	// AllowedHosts 내부 네트워크 주소여도 웹훅을 보낼 수 있는 호스트 목록, 그 외 호스트는 공인 주소로만 전송
+	AllowedHosts []string `yaml:"allowed_hosts"           json:"allowed_hosts"`
+}
+
+// FeedbackConfig 응답 피드백 집계 및 검색 점수 반영 구성
+type FeedbackConfig struct {
+	// BoostWeight 청크 신호가 검색 점수를 바꾸는 최대 비율(0~1), 기본값 0.2. 0이면 반영하지 않음
+	BoostWeight *float64 `yaml:"boost_weight"    json:"boost_weight"`
+	// Prior 점수 평활화에 사용하는 중립 투표 수, 기본값 2
+	Prior float64 `yaml:"prior"           json:"prior"`
+	// AggregateDelay 피드백 제출 후 집계까지 기다리는 시간(초), 기본값 60. 그 사이 피드백은 한 번에 집계됨
+	AggregateDelay int `yaml:"aggregate_delay" json:"aggregate_delay"`
+}
+
+// MemoryConfig 사용자 장기 기억 구성
+type MemoryConfig struct {
+	// Enabled 응답이 완료된 대화에서 기억을 추출하고 {{user_memories}}에 주입할지 여부
+	Enabled bool `yaml:"enabled"      json:"enabled"`
+	// TopK 프롬프트에 주입할 최대 기억 수, 기본값 5
+	TopK int `yaml:"top_k"        json:"top_k"`
+	// MinScore 주입할 기억의 최소 유사도(0~1), 기본값 0.3
+	MinScore float64 `yaml:"min_score"    json:"min_score"`
+	// MaxPerUser 사용자별 최대 기억 수, 기본값 200. 넘으면 오래 갱신되지 않은 기억부터 삭제
+	MaxPerUser int `yaml:"max_per_user" json:"max_per_user"`
+}
+
+// SSOTenantMapping 이메일 도메인 또는 IdP 그룹으로 테넌트를 결정하는 매핑, 둘 다 지정하면 모두 일치해야 함
+type SSOTenantMapping struct {
+	EmailDomain string `yaml:"email_domain" json:"email_domain"`
+	Group       string `yaml:"group"        json:"group"`
+	TenantID    uint64 `yaml:"tenant_id"    json:"tenant_id"`
+	Role        string `yaml:"role"         json:"role"`
+}
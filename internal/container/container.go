@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"slices"
@@ -28,6 +29,7 @@ import (
 	"github.com/Tencent/WeKnora/internal/application/repository"
 	elasticsearchRepoV7 "github.com/Tencent/WeKnora/internal/application/repository/retriever/elasticsearch/v7"
 	elasticsearchRepoV8 "github.com/Tencent/WeKnora/internal/application/repository/retriever/elasticsearch/v8"
+	embeddedRepo "github.com/Tencent/WeKnora/internal/application/repository/retriever/embedded"
 	neo4jRepo "github.com/Tencent/WeKnora/internal/application/repository/retriever/neo4j"
 	postgresRepo "github.com/Tencent/WeKnora/internal/application/repository/retriever/postgres"
 	qdrantRepo "github.com/Tencent/WeKnora/internal/application/repository/retriever/qdrant"
@@ -43,6 +45,7 @@ import (
 	"github.com/Tencent/WeKnora/internal/handler/session"
 	"github.com/Tencent/WeKnora/internal/logger"
 	"github.com/Tencent/WeKnora/internal/mcp"
+	"github.com/Tencent/WeKnora/internal/mcpserver"
 	"github.com/Tencent/WeKnora/internal/models/embedding"
 	"github.com/Tencent/WeKnora/internal/models/utils/ollama"
 	"github.com/Tencent/WeKnora/internal/router"
@@ -91,6 +94,7 @@ func BuildContainer(container *dig.Container) *dig.Container {
 	must(container.Provide(repository.NewKnowledgeBaseRepository))
 	must(container.Provide(repository.NewKnowledgeRepository))
 	must(container.Provide(repository.NewChunkRepository))
+	must(container.Provide(repository.NewKnowledgeVersionRepository))
 	must(container.Provide(repository.NewKnowledgeTagRepository))
 	must(container.Provide(repository.NewSessionRepository))
 	must(container.Provide(repository.NewMessageRepository))
@@ -100,6 +104,19 @@ func BuildContainer(container *dig.Container) *dig.Container {
 	must(container.Provide(neo4jRepo.NewNeo4jRepository))
 	must(container.Provide(repository.NewMCPServiceRepository))
 	must(container.Provide(repository.NewCustomAgentRepository))
+	must(container.Provide(repository.NewConnectorRepository))
+	must(container.Provide(repository.NewPermissionRepository))
+	must(container.Provide(repository.NewUserIdentityRepository))
+	must(container.Provide(repository.NewAPIKeyRepository))
+	must(container.Provide(repository.NewAuditRepository))
+	must(container.Provide(repository.NewUsageRepository))
+	must(container.Provide(repository.NewQuotaRepository))
+	must(container.Provide(repository.NewWebhookRepository))
+	must(container.Provide(repository.NewFeedbackRepository))
+	must(container.Provide(repository.NewEvaluationRepository))
+	must(container.Provide(repository.NewToolApprovalRepository))
+	must(container.Provide(repository.NewAgentRunRepository))
+	must(container.Provide(repository.NewMemoryRepository))
 	must(container.Provide(service.NewWebSearchStateService))
 
 	// MCP 클라이언트 연결 관리를 위한 MCP 관리자
@@ -107,10 +124,17 @@ func BuildContainer(container *dig.Container) *dig.Container {
 
 	// 비즈니스 서비스 계층
 	must(container.Provide(service.NewTenantService))
+	must(container.Provide(service.NewWebhookService))
+	must(container.Provide(service.NewFeedbackService))
+	must(container.Provide(service.NewToolApprovalService))
+	must(container.Provide(service.NewAgentRunService))
+	must(container.Provide(service.NewMemoryService))
 	must(container.Provide(service.NewKnowledgeBaseService))
 	must(container.Provide(service.NewKnowledgeService))
 	must(container.Provide(service.NewChunkService))
 	must(container.Provide(service.NewKnowledgeTagService))
+	must(container.Provide(service.NewUsageService))
+	must(container.Provide(service.NewQuotaService))
 	must(container.Provide(embedding.NewBatchEmbedder))
 	must(container.Provide(service.NewModelService))
 	must(container.Provide(service.NewDatasetService))
@@ -124,6 +148,11 @@ func BuildContainer(container *dig.Container) *dig.Container {
 	must(container.Provide(service.NewMessageService))
 	must(container.Provide(service.NewMCPServiceService))
 	must(container.Provide(service.NewCustomAgentService))
+	must(container.Provide(service.NewConnectorService))
+	must(container.Provide(service.NewPermissionService))
+	must(container.Provide(service.NewSSOService))
+	must(container.Provide(service.NewAPIKeyService))
+	must(container.Provide(service.NewAuditService))
 
 	// 웹 검색 서비스 (AgentService에 필요)
 	must(container.Provide(service.NewWebSearchService))
@@ -139,6 +168,7 @@ func BuildContainer(container *dig.Container) *dig.Container {
 
 	must(container.Provide(router.NewAsyncqClient))
 	must(container.Provide(router.NewAsynqServer))
+	must(container.Provide(router.NewAsynqScheduler))
 
 	// 채팅 요청 처리를 위한 채팅 파이프라인 구성 요소
 	must(container.Provide(chatpipline.NewEventManager))
@@ -175,6 +205,20 @@ func BuildContainer(container *dig.Container) *dig.Container {
 	must(container.Provide(handler.NewMCPServiceHandler))
 	must(container.Provide(handler.NewWebSearchHandler))
 	must(container.Provide(handler.NewCustomAgentHandler))
+	must(container.Provide(handler.NewConnectorHandler))
+	must(container.Provide(handler.NewPermissionHandler))
+	must(container.Provide(handler.NewSSOHandler))
+	must(container.Provide(handler.NewAPIKeyHandler))
+	must(container.Provide(handler.NewAuditHandler))
+	must(container.Provide(handler.NewUsageHandler))
+	must(container.Provide(handler.NewQuotaHandler))
+	must(container.Provide(handler.NewWebhookHandler))
+	must(container.Provide(handler.NewFeedbackHandler))
+	must(container.Provide(handler.NewToolApprovalHandler))
+	must(container.Provide(handler.NewMemoryHandler))
+
+	// WeKnora 자체를 노출하는 MCP 서버
+	must(container.Provide(mcpserver.NewServer))
 
 	// 라우터 구성
 	must(container.Provide(router.NewRouter))
@@ -387,15 +431,18 @@ func initFileService(cfg *config.Config) (interfaces.FileService, error) {
 
 // initRetrieveEngineRegistry 검색 엔진 레지스트리를 초기화합니다.
 // 구성에 따라 다양한 검색 엔진 백엔드를 설정하고 구성합니다.
-// 여러 검색 엔진(PostgreSQL, ElasticsearchV7, ElasticsearchV8)을 지원합니다.
+// 여러 검색 엔진(PostgreSQL, ElasticsearchV7, ElasticsearchV8, Qdrant, Qdrant 멀티 벡터, 내장 엔진)을 지원합니다.
 // 매개변수:
 //   - db: 데이터베이스 연결
 //   - cfg: 애플리케이션 구성
+//   - cleaner: 리소스 정리기 (내장 엔진의 인덱스를 종료 시 디스크에 저장)
 //
 // 반환값:
 //   - 구성된 검색 엔진 레지스트리
 //   - 초기화 실패 시 오류
-func initRetrieveEngineRegistry(db *gorm.DB, cfg *config.Config) (interfaces.RetrieveEngineRegistry, error) {
+func initRetrieveEngineRegistry(db *gorm.DB, cfg *config.Config,
+	cleaner interfaces.ResourceCleaner,
+) (interfaces.RetrieveEngineRegistry, error) {
 	registry := retriever.NewRetrieveEngineRegistry()
 	retrieveDriver := strings.Split(os.Getenv("RETRIEVE_DRIVER"), ",")
 	log := logger.GetLogger(context.Background())
@@ -454,7 +501,9 @@ func initRetrieveEngineRegistry(db *gorm.DB, cfg *config.Config) (interfaces.Ret
 		}
 	}
 
-	if slices.Contains(retrieveDriver, "qdrant") {
+	useQdrant := slices.Contains(retrieveDriver, "qdrant")
+	useQdrantMultiVector := slices.Contains(retrieveDriver, "qdrant_multivector")
+	if useQdrant || useQdrantMultiVector {
 		qdrantHost := os.Getenv("QDRANT_HOST")
 		if qdrantHost == "" {
 			qdrantHost = "localhost"
@@ -489,15 +538,55 @@ func initRetrieveEngineRegistry(db *gorm.DB, cfg *config.Config) (interfaces.Ret
 		if err != nil {
 			log.Errorf("Create qdrant client failed: %v", err)
 		} else {
-			qdrantRepository := qdrantRepo.NewQdrantRetrieveEngineRepository(client)
+			if useQdrant {
+				qdrantRepository := qdrantRepo.NewQdrantRetrieveEngineRepository(client)
+				if err := registry.Register(
+					retriever.NewKVHybridRetrieveEngine(
+						qdrantRepository, types.QdrantRetrieverEngineType,
+					),
+				); err != nil {
+					log.Errorf("Register qdrant retrieve engine failed: %v", err)
+				} else {
+					log.Infof("Register qdrant retrieve engine success")
+				}
+			}
+			// 멀티 벡터(ColBERT 방식) 검색 엔진은 동일한 Qdrant 클라이언트를 공유
+			if useQdrantMultiVector {
+				multiVectorRepository := qdrantRepo.NewQdrantMultiVectorRetrieveEngineRepository(client)
+				if err := registry.Register(
+					retriever.NewKVHybridRetrieveEngine(
+						multiVectorRepository, types.QdrantMultiVectorRetrieverEngineType,
+					),
+				); err != nil {
+					log.Errorf("Register qdrant_multivector retrieve engine failed: %v", err)
+				} else {
+					log.Infof("Register qdrant_multivector retrieve engine success")
+				}
+			}
+		}
+	}
+
+	// 외부 서비스 없이 동작하는 내장 검색 엔진 (단일 바이너리 배포용)
+	if slices.Contains(retrieveDriver, "embedded") {
+		indexDir := os.Getenv("EMBEDDED_INDEX_DIR")
+		if indexDir == "" {
+			indexDir = "./data/index"
+		}
+		embeddedRepository, err := embeddedRepo.NewEmbeddedRetrieveEngineRepository(indexDir)
+		if err != nil {
+			log.Errorf("Create embedded retrieve engine failed: %v", err)
+		} else {
+			if closer, ok := embeddedRepository.(io.Closer); ok {
+				cleaner.RegisterWithName("EmbeddedRetrieveEngine", closer.Close)
+			}
 			if err := registry.Register(
 				retriever.NewKVHybridRetrieveEngine(
-					qdrantRepository, types.QdrantRetrieverEngineType,
+					embeddedRepository, types.EmbeddedRetrieverEngineType,
 				),
 			); err != nil {
-				log.Errorf("Register qdrant retrieve engine failed: %v", err)
+				log.Errorf("Register embedded retrieve engine failed: %v", err)
 			} else {
-				log.Infof("Register qdrant retrieve engine success")
+				log.Infof("Register embedded retrieve engine success")
 			}
 		}
 	}
@@ -103,6 +103,15 @@ func NewConflictError(message string) *AppError {
 	}
 }
 
+// NewTooManyRequestsError creates a too many requests error
+func NewTooManyRequestsError(message string) *AppError {
+	return &AppError{
+		Code:     ErrTooManyRequests,
+		Message:  message,
+		HTTPCode: http.StatusTooManyRequests,
+	}
+}
+
 // NewInternalServerError creates an internal server error
 func NewInternalServerError(message string) *AppError {
 	if message == "" {
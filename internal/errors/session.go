@@ -13,4 +13,6 @@ var (
 	ErrInvalidSessionID = errors.New("invalid session id")
 	// ErrInvalidTenantID invalid tenant ID error
 	ErrInvalidTenantID = errors.New("invalid tenant id")
+	// ErrAgentRunSuspended agent run paused or handed over to another instance error
+	ErrAgentRunSuspended = errors.New("agent run suspended")
 )
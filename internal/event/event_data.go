@@ -1,5 +1,7 @@
 package event
 
+import "time"
+
 // EventData contains common event data structures for different stages
 
 // QueryData represents query-related event data
@@ -175,6 +177,27 @@ type AgentToolResultData struct {
 	Data       map[string]interface{} `json:"data,omitempty"` // Structured data from tool result (e.g., display_type, formatted results)
 }
 
+// AgentToolApprovalData represents a tool call approval request or its decision
+type AgentToolApprovalData struct {
+	ApprovalID string         `json:"approval_id"`
+	ToolCallID string         `json:"tool_call_id"`
+	ToolName   string         `json:"tool_name"`
+	Arguments  map[string]any `json:"arguments,omitempty"` // Arguments the tool runs with once approved
+	Status     string         `json:"status"`              // pending, approved, rejected or expired
+	Comment    string         `json:"comment,omitempty"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+	Iteration  int            `json:"iteration"`
+}
+
+// AgentSubAgentData wraps an event of an agent running a task delegated by the delegate_agent tool
+type AgentSubAgentData struct {
+	ToolCallID string `json:"tool_call_id"` // Delegate tool call of the parent agent
+	AgentID    string `json:"agent_id"`
+	AgentName  string `json:"agent_name"`
+	Depth      int    `json:"depth"` // Nesting depth of the sub-agent, 1 for agents called by the top-level agent
+	Event      Event  `json:"event"` // Event emitted by the sub-agent
+}
+
 // AgentReferencesData represents knowledge references data
 type AgentReferencesData struct {
 	References interface{} `json:"references"` // []*types.SearchResult
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler 테넌트 API 키 관리 HTTP 요청 처리
+type APIKeyHandler struct {
+	apiKeyService interfaces.APIKeyService
+}
+
+// NewAPIKeyHandler 새로운 API 키 핸들러 생성
+func NewAPIKeyHandler(apiKeyService interfaces.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// handleAPIKeyError 서비스 오류를 응답 오류로 변환합니다.
+func handleAPIKeyError(c *gin.Context, err error, fields map[string]interface{}) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+		return
+	}
+	logger.ErrorWithFields(c.Request.Context(), err, fields)
+	c.Error(errors.NewInternalServerError(err.Error()))
+}
+
+// ListAPIKeys godoc
+// @Summary      API 키 목록 조회
+// @Description  현재 테넌트의 API 키 목록 조회, 키 원문은 포함되지 않음. 관리자 권한 필요
+// @Tags         테넌트 관리
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "API 키 목록"
+// @Failure      403  {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /tenant/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		handleAPIKeyError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    keys,
+	})
+}
+
+// CreateAPIKey godoc
+// @Summary      API 키 생성
+// @Description  범위(search, chat, ingest, admin), 지식베이스 허용 목록과 만료 시간을 지정하여 API 키 생성.
+// @Description  키 원문은 이 응답에서만 반환되며 X-API-Key 헤더로 사용. 관리자 권한 필요
+// @Tags         테넌트 관리
+// @Accept       json
+// @Produce      json
+// @Param        request  body      types.CreateAPIKeyRequest  true  "API 키 정보"
+// @Success      200      {object}  map[string]interface{}     "생성된 API 키"
+// @Failure      400      {object}  errors.AppError            "요청 매개변수 오류"
+// @Failure      403      {object}  errors.AppError            "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /tenant/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req types.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	key, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), &req)
+	if err != nil {
+		handleAPIKeyError(c, err, map[string]interface{}{"name": secutils.SanitizeForLog(req.Name)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    key,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary      API 키 취소
+// @Description  API 키를 취소하여 더 이상 인증에 사용할 수 없게 함. 관리자 권한 필요
+// @Tags         테넌트 관리
+// @Produce      json
+// @Param        id   path      string  true  "API 키 ID"
+// @Success      200  {object}  map[string]interface{}  "취소 성공"
+// @Failure      403  {object}  errors.AppError         "권한 없음"
+// @Failure      404  {object}  errors.AppError         "API 키를 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /tenant/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), id); err != nil {
+		handleAPIKeyError(c, err, map[string]interface{}{"api_key_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
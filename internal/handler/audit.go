@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"github.com/gin-gonic/gin"
+)
+
+// auditExportColumns 감사 기록 CSV 내보내기의 열
+var auditExportColumns = []string{
+	"seq", "id", "created_at", "actor_type", "actor_id", "actor_name", "action",
+	"resource_type", "resource_id", "method", "path", "status_code", "client_ip", "request_id",
+	"changes", "detail", "prev_hash", "hash",
+}
+
+// AuditHandler 감사 기록 HTTP 요청 처리
+type AuditHandler struct {
+	auditService interfaces.AuditService
+}
+
+// NewAuditHandler 새로운 감사 기록 핸들러 생성
+func NewAuditHandler(auditService interfaces.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// ListAuditLogs godoc
+// @Summary      감사 기록 목록 조회
+// @Description  현재 테넌트의 감사 기록을 최신순으로 조회. 사용자, 작업, 리소스와 기간으로 필터링 가능. 관리자 권한 필요
+// @Tags         감사 기록
+// @Produce      json
+// @Param        actor_id       query     string  false  "작업자 ID"
+// @Param        actor_type     query     string  false  "작업자 유형 (user, api_key, tenant_key, system)"
+// @Param        action         query     string  false  "작업 이름"
+// @Param        resource_type  query     string  false  "리소스 유형"
+// @Param        resource_id    query     string  false  "리소스 ID"
+// @Param        from           query     string  false  "시작 시간 (RFC3339)"
+// @Param        to             query     string  false  "종료 시간 (RFC3339)"
+// @Param        page           query     int     false  "페이지 번호"
+// @Param        page_size      query     int     false  "페이지당 개수"
+// @Success      200            {object}  map[string]interface{}  "감사 기록 목록"
+// @Failure      400            {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403            {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /audit-logs [get]
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	ctx := c.Request.Context()
+	var filter types.AuditLogFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	var page types.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid pagination parameters").WithDetails(err.Error()))
+		return
+	}
+
+	result, err := h.auditService.ListAuditLogs(ctx, &filter, &page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// ExportAuditLogs godoc
+// @Summary      감사 기록 내보내기
+// @Description  필터와 일치하는 감사 기록을 오래된 순서로 CSV 또는 JSONL 파일로 내보내기.
+// @Description  각 기록의 해시가 포함되어 외부에서 체인을 검증할 수 있음. 관리자 권한 필요
+// @Tags         감사 기록
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Param        format         query     string  false  "파일 형식 (csv, jsonl), 기본값 csv"
+// @Param        actor_id       query     string  false  "작업자 ID"
+// @Param        action         query     string  false  "작업 이름"
+// @Param        resource_type  query     string  false  "리소스 유형"
+// @Param        resource_id    query     string  false  "리소스 ID"
+// @Param        from           query     string  false  "시작 시간 (RFC3339)"
+// @Param        to             query     string  false  "종료 시간 (RFC3339)"
+// @Success      200            {file}    file    "감사 기록 파일"
+// @Failure      400            {object}  errors.AppError  "요청 매개변수 오류"
+// @Failure      403            {object}  errors.AppError  "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /audit-logs/export [get]
+func (h *AuditHandler) ExportAuditLogs(c *gin.Context) {
+	ctx := c.Request.Context()
+	var filter types.AuditLogFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		c.Error(errors.NewBadRequestError("지원하지 않는 내보내기 형식입니다").WithDetails(format))
+		return
+	}
+
+	filename := fmt.Sprintf("audit_logs_%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+	}
+	c.Status(http.StatusOK)
+
+	var write func(log *types.AuditLog) error
+	var flush func() error
+	if format == "csv" {
+		w := csv.NewWriter(c.Writer)
+		// UTF-8 호환성을 위한 BOM 추가
+		if _, err := c.Writer.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			return
+		}
+		if err := w.Write(auditExportColumns); err != nil {
+			return
+		}
+		write = func(log *types.AuditLog) error {
+			return w.Write(auditExportRecord(log))
+		}
+		flush = func() error {
+			w.Flush()
+			return w.Error()
+		}
+	} else {
+		encoder := json.NewEncoder(c.Writer)
+		write = func(log *types.AuditLog) error {
+			return encoder.Encode(log)
+		}
+		flush = func() error { return nil }
+	}
+
+	// 응답이 이미 시작되었으므로 오류는 로그로만 남기고 파일이 불완전하게 끝남
+	if err := h.auditService.ExportAuditLogs(ctx, &filter, write); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"format": format})
+	}
+	if err := flush(); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"format": format})
+	}
+}
+
+// auditExportRecord 감사 기록을 CSV 행으로 변환합니다.
+func auditExportRecord(log *types.AuditLog) []string {
+	return []string{
+		strconv.FormatUint(log.Seq, 10),
+		log.ID,
+		log.CreatedAt.UTC().Format(time.RFC3339Nano),
+		string(log.ActorType),
+		log.ActorID,
+		log.ActorName,
+		log.Action,
+		log.ResourceType,
+		log.ResourceID,
+		log.Method,
+		log.Path,
+		strconv.Itoa(log.StatusCode),
+		log.ClientIP,
+		log.RequestID,
+		string(log.Changes),
+		string(log.Detail),
+		log.PrevHash,
+		log.Hash,
+	}
+}
+
+// VerifyAuditChain godoc
+// @Summary      감사 기록 무결성 검증
+// @Description  현재 테넌트의 감사 기록 해시 체인을 처음부터 검증하여 변경되거나 삭제된 기록을 찾음.
+// @Description  보존 기간이 지나 삭제된 기록 이후부터 검증. 관리자 권한 필요
+// @Tags         감사 기록
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "검증 결과"
+// @Failure      403  {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /audit-logs/verify [get]
+func (h *AuditHandler) VerifyAuditChain(c *gin.Context) {
+	ctx := c.Request.Context()
+	result, err := h.auditService.VerifyAuditChain(ctx)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
@@ -38,6 +38,11 @@ func NewAuthHandler(configInfo *config.Config,
 	}
 }
 
+// passwordLoginDisabled 구성에서 SSO 로그인만 허용하는지 확인
+func (h *AuthHandler) passwordLoginDisabled() bool {
+	return h.configInfo != nil && h.configInfo.SSO != nil && h.configInfo.SSO.DisablePasswordLogin
+}
+
 // Register godoc
 // @Summary      사용자 등록
 // @Description  새 사용자 계정 등록
@@ -62,6 +67,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	if h.passwordLoginDisabled() {
+		logger.Warn(ctx, "Registration is disabled because only SSO login is allowed")
+		c.Error(errors.NewForbiddenError("Registration is disabled, please sign in with SSO"))
+		return
+	}
+
 	var req types.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Error(ctx, "Failed to parse registration request parameters", err)
@@ -111,12 +122,19 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Param        request  body      types.LoginRequest  true  "로그인 요청 매개변수"
 // @Success      200      {object}  types.LoginResponse
 // @Failure      401      {object}  errors.AppError  "인증 실패"
+// @Failure      403      {object}  errors.AppError  "비밀번호 로그인 비활성화됨"
 // @Router       /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	logger.Info(ctx, "Start user login")
 
+	if h.passwordLoginDisabled() {
+		logger.Warn(ctx, "Password login is disabled because only SSO login is allowed")
+		c.Error(errors.NewForbiddenError("Password login is disabled, please sign in with SSO"))
+		return
+	}
+
 	var req types.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Error(ctx, "Failed to parse login request parameters", err)
@@ -14,12 +14,13 @@ import (
 
 // ChunkHandler 청크 작업을 위한 HTTP 핸들러 정의
 type ChunkHandler struct {
-	service interfaces.ChunkService
+	service          interfaces.ChunkService
+	knowledgeService interfaces.KnowledgeService
 }
 
 // NewChunkHandler 새로운 청크 핸들러 생성
-func NewChunkHandler(service interfaces.ChunkService) *ChunkHandler {
-	return &ChunkHandler{service: service}
+func NewChunkHandler(service interfaces.ChunkService, knowledgeService interfaces.KnowledgeService) *ChunkHandler {
+	return &ChunkHandler{service: service, knowledgeService: knowledgeService}
 }
 
 // GetChunkByIDOnly godoc
@@ -170,7 +171,7 @@ type UpdateChunkRequest struct {
 	ImageInfo  string    `json:"image_info"`
 }
 
-// validateAndGetChunk 요청 매개변수 검증 및 청크 조회
+// validateAndGetChunk 요청 매개변수 검증 및 수정할 청크 조회
 // 청크 정보, 지식 ID, 오류 반환
 func (h *ChunkHandler) validateAndGetChunk(c *gin.Context) (*types.Chunk, string, error) {
 	ctx := c.Request.Context()
@@ -222,6 +223,11 @@ func (h *ChunkHandler) validateAndGetChunk(c *gin.Context) (*types.Chunk, string
 		return nil, knowledgeID, errors.NewForbiddenError("No permission to access this chunk")
 	}
 
+	// 재임베딩 중인 지식베이스의 청크는 수정할 수 없음
+	if err := h.knowledgeService.EnsureKnowledgeBaseWritable(ctx, chunk.KnowledgeBaseID); err != nil {
+		return nil, knowledgeID, err
+	}
+
 	return chunk, knowledgeID, nil
 }
 
@@ -338,8 +344,19 @@ func (h *ChunkHandler) DeleteChunksByKnowledgeID(c *gin.Context) {
 		return
 	}
 
+	knowledge, err := h.knowledgeService.GetKnowledgeByID(ctx, knowledgeID)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewNotFoundError("Knowledge not found"))
+		return
+	}
+	if err := h.knowledgeService.EnsureKnowledgeBaseWritable(ctx, knowledge.KnowledgeBaseID); err != nil {
+		c.Error(err)
+		return
+	}
+
 	// 지식 하의 모든 청크 삭제
-	err := h.service.DeleteChunksByKnowledgeID(ctx, knowledgeID)
+	err = h.service.DeleteChunksByKnowledgeID(ctx, knowledgeID)
 	if err != nil {
 		logger.ErrorWithFields(ctx, err, nil)
 		c.Error(errors.NewInternalServerError(err.Error()))
@@ -412,6 +429,10 @@ func (h *ChunkHandler) DeleteGeneratedQuestion(c *gin.Context) {
 		c.Error(errors.NewForbiddenError("No permission to access this chunk"))
 		return
 	}
+	if err := h.knowledgeService.EnsureKnowledgeBaseWritable(ctx, chunk.KnowledgeBaseID); err != nil {
+		c.Error(err)
+		return
+	}
 
 	// ID로 생성된 질문 삭제
 	if err := h.service.DeleteGeneratedQuestion(ctx, chunkID, req.QuestionID); err != nil {
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ConnectorHandler 지식베이스 커넥터 관련 HTTP 요청 처리
+type ConnectorHandler struct {
+	connectorService interfaces.ConnectorService
+}
+
+// NewConnectorHandler 새로운 커넥터 핸들러 생성
+func NewConnectorHandler(connectorService interfaces.ConnectorService) *ConnectorHandler {
+	return &ConnectorHandler{connectorService: connectorService}
+}
+
+// connectorRequest 커넥터 생성 및 업데이트 요청
+type connectorRequest struct {
+	Name                string                `json:"name"`
+	Type                types.ConnectorType   `json:"type"`
+	Config              types.ConnectorConfig `json:"config"`
+	Enabled             *bool                 `json:"enabled"`
+	SyncIntervalMinutes int                   `json:"sync_interval_minutes"`
+}
+
+// connector 요청으로부터 커넥터를 생성합니다. enabled를 지정하지 않으면 활성화됩니다.
+func (r *connectorRequest) connector() *types.KnowledgeConnector {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return &types.KnowledgeConnector{
+		Name:                r.Name,
+		Type:                r.Type,
+		Config:              r.Config,
+		Enabled:             enabled,
+		SyncIntervalMinutes: r.SyncIntervalMinutes,
+	}
+}
+
+// handleConnectorError 서비스 오류를 응답 오류로 변환합니다.
+func handleConnectorError(c *gin.Context, err error, fields map[string]interface{}) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+		return
+	}
+	logger.ErrorWithFields(c.Request.Context(), err, fields)
+	c.Error(errors.NewInternalServerError(err.Error()))
+}
+
+// CreateConnector godoc
+// @Summary      커넥터 생성
+// @Description  외부 소스(local, git, s3, web)의 항목을 지식베이스로 주기적으로 동기화하는 커넥터 생성
+// @Tags         커넥터
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string            true  "지식베이스 ID"
+// @Param        request  body      connectorRequest  true  "커넥터 구성"
+// @Success      200      {object}  map[string]interface{}  "생성된 커넥터"
+// @Failure      400      {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      404      {object}  errors.AppError         "지식베이스를 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /knowledge-bases/{id}/connectors [post]
+func (h *ConnectorHandler) CreateConnector(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	kbID := secutils.SanitizeForLog(c.Param("id"))
+	if kbID == "" {
+		c.Error(errors.NewBadRequestError("Knowledge base ID cannot be empty"))
+		return
+	}
+	var req connectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+
+	kc := req.connector()
+	kc.KnowledgeBaseID = kbID
+	created, err := h.connectorService.CreateConnector(ctx, kc)
+	if err != nil {
+		handleConnectorError(c, err, map[string]interface{}{"knowledge_base_id": kbID})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    created,
+	})
+}
+
+// ListConnectors godoc
+// @Summary      커넥터 목록 조회
+// @Description  지식베이스의 모든 커넥터 조회
+// @Tags         커넥터
+// @Produce      json
+// @Param        id   path      string  true  "지식베이스 ID"
+// @Success      200  {object}  map[string]interface{}  "커넥터 목록"
+// @Failure      400  {object}  errors.AppError         "요청 매개변수 오류"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /knowledge-bases/{id}/connectors [get]
+func (h *ConnectorHandler) ListConnectors(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	kbID := secutils.SanitizeForLog(c.Param("id"))
+	if kbID == "" {
+		c.Error(errors.NewBadRequestError("Knowledge base ID cannot be empty"))
+		return
+	}
+
+	connectors, err := h.connectorService.ListConnectors(ctx, kbID)
+	if err != nil {
+		handleConnectorError(c, err, map[string]interface{}{"knowledge_base_id": kbID})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    connectors,
+	})
+}
+
+// GetConnector godoc
+// @Summary      커넥터 상세 조회
+// @Description  ID로 커넥터 상세 정보 조회
+// @Tags         커넥터
+// @Produce      json
+// @Param        id   path      string  true  "커넥터 ID"
+// @Success      200  {object}  map[string]interface{}  "커넥터 상세 정보"
+// @Failure      404  {object}  errors.AppError         "커넥터를 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /connectors/{id} [get]
+func (h *ConnectorHandler) GetConnector(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := secutils.SanitizeForLog(c.Param("id"))
+	kc, err := h.connectorService.GetConnector(ctx, id)
+	if err != nil {
+		handleConnectorError(c, err, map[string]interface{}{"connector_id": id})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    kc,
+	})
+}
+
+// UpdateConnector godoc
+// @Summary      커넥터 업데이트
+// @Description  커넥터의 이름, 구성 및 동기화 일정 업데이트. 구성이 바뀌면 다음 동기화에서 모든 항목을 다시 비교
+// @Tags         커넥터
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string            true  "커넥터 ID"
+// @Param        request  body      connectorRequest  true  "커넥터 구성"
+// @Success      200      {object}  map[string]interface{}  "업데이트된 커넥터"
+// @Failure      400      {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      404      {object}  errors.AppError         "커넥터를 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /connectors/{id} [put]
+func (h *ConnectorHandler) UpdateConnector(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := secutils.SanitizeForLog(c.Param("id"))
+	var req connectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+
+	kc := req.connector()
+	kc.ID = id
+	updated, err := h.connectorService.UpdateConnector(ctx, kc)
+	if err != nil {
+		handleConnectorError(c, err, map[string]interface{}{"connector_id": id})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// DeleteConnector godoc
+// @Summary      커넥터 삭제
+// @Description  커넥터 삭제. delete_knowledge가 true이면 커넥터로 동기화된 지식도 삭제
+// @Tags         커넥터
+// @Produce      json
+// @Param        id                path      string  true   "커넥터 ID"
+// @Param        delete_knowledge  query     bool    false  "동기화된 지식 삭제 여부"
+// @Success      200               {object}  map[string]interface{}  "삭제 성공"
+// @Failure      404               {object}  errors.AppError         "커넥터를 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /connectors/{id} [delete]
+func (h *ConnectorHandler) DeleteConnector(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := secutils.SanitizeForLog(c.Param("id"))
+	deleteKnowledge := c.Query("delete_knowledge") == "true"
+	if err := h.connectorService.DeleteConnector(ctx, id, deleteKnowledge); err != nil {
+		handleConnectorError(c, err, map[string]interface{}{"connector_id": id})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Connector deleted successfully",
+	})
+}
+
+// SyncConnector godoc
+// @Summary      커넥터 동기화 실행
+// @Description  커넥터 동기화 작업을 대기열에 추가. force가 true이면 소스가 변경되지 않았어도 모든 항목을 비교
+// @Tags         커넥터
+// @Produce      json
+// @Param        id     path      string  true   "커넥터 ID"
+// @Param        force  query     bool    false  "모든 항목 비교 여부"
+// @Success      200    {object}  map[string]interface{}  "동기화 상태"
+// @Failure      404    {object}  errors.AppError         "커넥터를 찾을 수 없음"
+// @Failure      409    {object}  errors.AppError         "동기화가 이미 대기 중이거나 진행 중"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /connectors/{id}/sync [post]
+func (h *ConnectorHandler) SyncConnector(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := secutils.SanitizeForLog(c.Param("id"))
+	force := c.Query("force") == "true"
+	state, err := h.connectorService.TriggerSync(ctx, id, force)
+	if err != nil {
+		handleConnectorError(c, err, map[string]interface{}{"connector_id": id})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    state,
+	})
+}
+
+// GetConnectorSyncStatus godoc
+// @Summary      커넥터 동기화 상태 조회
+// @Description  커넥터의 동기화 상태, 커서, 마지막 오류, 통계 및 다음 예약 동기화 시간 조회
+// @Tags         커넥터
+// @Produce      json
+// @Param        id   path      string  true  "커넥터 ID"
+// @Success      200  {object}  map[string]interface{}  "동기화 상태"
+// @Failure      404  {object}  errors.AppError         "커넥터를 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /connectors/{id}/sync-status [get]
+func (h *ConnectorHandler) GetConnectorSyncStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := secutils.SanitizeForLog(c.Param("id"))
+	state, err := h.connectorService.GetSyncState(ctx, id)
+	if err != nil {
+		handleConnectorError(c, err, map[string]interface{}{"connector_id": id})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    state,
+	})
+}
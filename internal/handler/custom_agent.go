@@ -14,13 +14,18 @@ import (
 
 // CustomAgentHandler 사용자 정의 에이전트 작업을 위한 HTTP 핸들러 정의
 type CustomAgentHandler struct {
-	service interfaces.CustomAgentService
+	service           interfaces.CustomAgentService
+	permissionService interfaces.PermissionService
 }
 
 // NewCustomAgentHandler 새로운 사용자 정의 에이전트 핸들러 인스턴스 생성
-func NewCustomAgentHandler(service interfaces.CustomAgentService) *CustomAgentHandler {
+func NewCustomAgentHandler(
+	service interfaces.CustomAgentService,
+	permissionService interfaces.PermissionService,
+) *CustomAgentHandler {
 	return &CustomAgentHandler{
-		service: service,
+		service:           service,
+		permissionService: permissionService,
 	}
 }
 
@@ -80,13 +85,19 @@ func (h *CustomAgentHandler) CreateAgent(c *gin.Context) {
 	createdAgent, err := h.service.CreateAgent(ctx, agent)
 	if err != nil {
 		logger.ErrorWithFields(ctx, err, nil)
-		if err == service.ErrAgentNameRequired {
+		if err == service.ErrAgentNameRequired || err == service.ErrInvalidToolApproval {
 			c.Error(errors.NewBadRequestError(err.Error()))
 			return
 		}
 		c.Error(errors.NewInternalServerError(err.Error()))
 		return
 	}
+	// 편집자가 생성한 에이전트는 생성자에게 관리 권한을 부여
+	if err := h.permissionService.GrantCreator(ctx, types.ResourceTypeAgent, createdAgent.ID); err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
 
 	logger.Infof(ctx, "Custom agent created successfully, ID: %s, name: %s",
 		secutils.SanitizeForLog(createdAgent.ID), secutils.SanitizeForLog(createdAgent.Name))
@@ -160,6 +171,14 @@ func (h *CustomAgentHandler) ListAgents(c *gin.Context) {
 		c.Error(errors.NewInternalServerError(err.Error()))
 		return
 	}
+	// 접근 가능한 에이전트만 반환
+	agents, err = filterAccessible(ctx, h.permissionService, types.ResourceTypeAgent, agents,
+		func(agent *types.CustomAgent) string { return agent.ID })
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -225,7 +244,7 @@ func (h *CustomAgentHandler) UpdateAgent(c *gin.Context) {
 			c.Error(errors.NewNotFoundError("Agent not found"))
 		case service.ErrCannotModifyBuiltin:
 			c.Error(errors.NewForbiddenError("Cannot modify built-in agent"))
-		case service.ErrAgentNameRequired:
+		case service.ErrAgentNameRequired, service.ErrInvalidToolApproval, service.ErrInvalidSubAgent:
 			c.Error(errors.NewBadRequestError(err.Error()))
 		default:
 			c.Error(errors.NewInternalServerError(err.Error()))
@@ -285,6 +304,9 @@ func (h *CustomAgentHandler) DeleteAgent(c *gin.Context) {
 		}
 		return
 	}
+	if err := h.permissionService.DeleteResourceGrants(ctx, types.ResourceTypeAgent, id); err != nil {
+		logger.Warnf(ctx, "Failed to delete grants of agent %s: %v", secutils.SanitizeForLog(id), err)
+	}
 
 	logger.Infof(ctx, "Custom agent deleted successfully, ID: %s", secutils.SanitizeForLog(id))
 	c.JSON(http.StatusOK, gin.H{
@@ -335,6 +357,11 @@ func (h *CustomAgentHandler) CopyAgent(c *gin.Context) {
 		}
 		return
 	}
+	if err := h.permissionService.GrantCreator(ctx, types.ResourceTypeAgent, copiedAgent.ID); err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
 
 	logger.Infof(ctx, "Custom agent copied successfully, source ID: %s, new ID: %s",
 		secutils.SanitizeForLog(id), secutils.SanitizeForLog(copiedAgent.ID))
@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/Tencent/WeKnora/internal/application/service/evaluation"
 	"github.com/Tencent/WeKnora/internal/errors"
 	"github.com/Tencent/WeKnora/internal/logger"
 	"github.com/Tencent/WeKnora/internal/types"
@@ -14,30 +17,40 @@ import (
 // EvaluationHandler 평가 관련 HTTP 요청 처리
 type EvaluationHandler struct {
 	evaluationService interfaces.EvaluationService // 평가 작업을 위한 서비스
+	datasetService    interfaces.DatasetService    // 평가 데이터셋 서비스
 }
 
 // NewEvaluationHandler 새로운 EvaluationHandler 인스턴스 생성
-func NewEvaluationHandler(evaluationService interfaces.EvaluationService) *EvaluationHandler {
-	return &EvaluationHandler{evaluationService: evaluationService}
+func NewEvaluationHandler(
+	evaluationService interfaces.EvaluationService,
+	datasetService interfaces.DatasetService,
+) *EvaluationHandler {
+	return &EvaluationHandler{evaluationService: evaluationService, datasetService: datasetService}
 }
 
-// EvaluationRequest 평가 요청 매개변수 포함
-type EvaluationRequest struct {
-	DatasetID       string `json:"dataset_id"`        // 평가할 데이터셋 ID
-	KnowledgeBaseID string `json:"knowledge_base_id"` // 사용할 지식베이스 ID
-	ChatModelID     string `json:"chat_id"`           // 사용할 채팅 모델 ID
-	RerankModelID   string `json:"rerank_id"`         // 사용할 재순위 모델 ID
+// handleEvaluationError 서비스 오류를 응답 오류로 변환합니다.
+func handleEvaluationError(c *gin.Context, err error, fields map[string]interface{}) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+		return
+	}
+	logger.ErrorWithFields(c.Request.Context(), err, fields)
+	c.Error(errors.NewInternalServerError(err.Error()))
 }
 
 // Evaluation godoc
 // @Summary      평가 실행
-// @Description  지식베이스에 대한 평가 테스트 수행
+// @Description  데이터셋으로 지식베이스와 에이전트 구성을 평가하는 실행을 백그라운드에서 시작. 작업 ID는 실행 ID와 같음.
+// @Description  dataset_id가 비어 있거나 default이면 기본 데이터셋을 임시 지식베이스로 평가하고,
+// @Description  업로드한 데이터셋은 knowledge_base_id(없으면 agent_id의 지식베이스)를 그대로 검색하여 평가함.
+// @Description  모델과 검색 설정은 에이전트 구성을 따르며 chat_id, rerank_id로 모델을 바꿀 수 있음
 // @Tags         평가
 // @Accept       json
 // @Produce      json
-// @Param        request  body      EvaluationRequest  true  "평가 요청 매개변수"
+// @Param        request  body      types.EvaluationRequest  true  "평가 요청 매개변수"
 // @Success      200      {object}  map[string]interface{}  "평가 작업"
 // @Failure      400      {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403      {object}  errors.AppError         "에이전트 또는 지식베이스 읽기 권한 없음"
 // @Security     Bearer
 // @Security     ApiKeyAuth
 // @Router       /evaluation/ [post]
@@ -46,7 +59,7 @@ func (e *EvaluationHandler) Evaluation(c *gin.Context) {
 
 	logger.Info(ctx, "Start processing evaluation request")
 
-	var request EvaluationRequest
+	var request types.EvaluationRequest
 	if err := c.ShouldBind(&request); err != nil {
 		logger.Error(ctx, "Failed to parse request parameters", err)
 		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
@@ -68,15 +81,9 @@ func (e *EvaluationHandler) Evaluation(c *gin.Context) {
 		secutils.SanitizeForLog(request.RerankModelID),
 	)
 
-	task, err := e.evaluationService.Evaluation(ctx,
-		secutils.SanitizeForLog(request.DatasetID),
-		secutils.SanitizeForLog(request.KnowledgeBaseID),
-		secutils.SanitizeForLog(request.ChatModelID),
-		secutils.SanitizeForLog(request.RerankModelID),
-	)
+	task, err := e.evaluationService.Evaluation(ctx, &request)
 	if err != nil {
-		logger.ErrorWithFields(ctx, err, nil)
-		c.Error(errors.NewInternalServerError(err.Error()))
+		handleEvaluationError(c, err, nil)
 		return
 	}
 
@@ -118,8 +125,7 @@ func (e *EvaluationHandler) GetEvaluationResult(c *gin.Context) {
 
 	result, err := e.evaluationService.EvaluationResult(ctx, secutils.SanitizeForLog(request.TaskID))
 	if err != nil {
-		logger.ErrorWithFields(ctx, err, nil)
-		c.Error(errors.NewInternalServerError(err.Error()))
+		handleEvaluationError(c, err, nil)
 		return
 	}
 
@@ -129,3 +135,319 @@ func (e *EvaluationHandler) GetEvaluationResult(c *gin.Context) {
 		"data":    result,
 	})
 }
+
+// ListRuns godoc
+// @Summary      평가 실행 목록 조회
+// @Description  현재 테넌트의 평가 실행을 최신순으로 조회
+// @Tags         평가
+// @Produce      json
+// @Param        dataset_id  query     string  false  "데이터셋 ID"
+// @Param        agent_id    query     string  false  "에이전트 ID"
+// @Param        page        query     int     false  "페이지 번호"
+// @Param        page_size   query     int     false  "페이지당 개수"
+// @Success      200         {object}  map[string]interface{}  "평가 실행 목록"
+// @Failure      400         {object}  errors.AppError         "요청 매개변수 오류"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /evaluation/runs [get]
+func (e *EvaluationHandler) ListRuns(c *gin.Context) {
+	var filter types.EvaluationRunFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	var page types.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid pagination parameters").WithDetails(err.Error()))
+		return
+	}
+
+	result, err := e.evaluationService.ListRuns(c.Request.Context(), &filter, &page)
+	if err != nil {
+		handleEvaluationError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetRun godoc
+// @Summary      평가 실행 조회
+// @Description  평가 실행의 구성, 진행 상황과 평균 메트릭 조회
+// @Tags         평가
+// @Produce      json
+// @Param        id   path      string  true  "평가 실행 ID"
+// @Success      200  {object}  map[string]interface{}  "평가 실행"
+// @Failure      404  {object}  errors.AppError         "평가 실행을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /evaluation/runs/{id} [get]
+func (e *EvaluationHandler) GetRun(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	run, err := e.evaluationService.GetRun(c.Request.Context(), id)
+	if err != nil {
+		handleEvaluationError(c, err, map[string]interface{}{"run_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    run,
+	})
+}
+
+// ListRunItems godoc
+// @Summary      질문별 평가 결과 조회
+// @Description  평가 실행의 질문별 검색 청크, 응답, 메트릭(BLEU, ROUGE, MRR, MAP, NDCG, 정밀도, 재현율)을 데이터셋 순서로 조회
+// @Tags         평가
+// @Produce      json
+// @Param        id         path      string  true   "평가 실행 ID"
+// @Param        page       query     int     false  "페이지 번호"
+// @Param        page_size  query     int     false  "페이지당 개수"
+// @Success      200        {object}  map[string]interface{}  "질문별 평가 결과"
+// @Failure      404        {object}  errors.AppError         "평가 실행을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /evaluation/runs/{id}/items [get]
+func (e *EvaluationHandler) ListRunItems(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	var page types.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid pagination parameters").WithDetails(err.Error()))
+		return
+	}
+
+	result, err := e.evaluationService.ListRunItems(c.Request.Context(), id, &page)
+	if err != nil {
+		handleEvaluationError(c, err, map[string]interface{}{"run_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// DeleteRun godoc
+// @Summary      평가 실행 삭제
+// @Description  완료된 평가 실행과 질문별 결과 삭제
+// @Tags         평가
+// @Produce      json
+// @Param        id   path      string  true  "평가 실행 ID"
+// @Success      200  {object}  map[string]interface{}  "삭제 결과"
+// @Failure      400  {object}  errors.AppError         "진행 중인 평가 실행"
+// @Failure      404  {object}  errors.AppError         "평가 실행을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /evaluation/runs/{id} [delete]
+func (e *EvaluationHandler) DeleteRun(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if err := e.evaluationService.DeleteRun(c.Request.Context(), id); err != nil {
+		handleEvaluationError(c, err, map[string]interface{}{"run_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Evaluation run deleted successfully",
+	})
+}
+
+// CompareRuns godoc
+// @Summary      평가 실행 비교
+// @Description  기준 실행과 대상 실행의 평균 메트릭과 질문별 결과를 나란히 비교. 질문은 질문 내용으로 짝지음.
+// @Description  검색 메트릭(정밀도, 재현율, NDCG, MRR, MAP)이 허용 오차보다 많이 떨어지면 passed가 false이므로
+// @Description  구성 변경 전후 검색 품질 검증에 사용할 수 있음. 두 실행 모두 성공적으로 완료되어야 함
+// @Tags         평가
+// @Produce      json
+// @Param        base       query     string  true   "기준 평가 실행 ID"
+// @Param        target     query     string  true   "대상 평가 실행 ID"
+// @Param        tolerance  query     number  false  "허용 오차 (기본값 0.01)"
+// @Success      200        {object}  map[string]interface{}  "비교 결과"
+// @Failure      400        {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      404        {object}  errors.AppError         "평가 실행을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /evaluation/compare [get]
+func (e *EvaluationHandler) CompareRuns(c *gin.Context) {
+	baseID := secutils.SanitizeForLog(c.Query("base"))
+	targetID := secutils.SanitizeForLog(c.Query("target"))
+	if baseID == "" || targetID == "" {
+		c.Error(errors.NewBadRequestError("base and target are required"))
+		return
+	}
+	tolerance := evaluation.DefaultTolerance
+	if value := c.Query("tolerance"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.Error(errors.NewBadRequestError("Invalid tolerance").WithDetails(err.Error()))
+			return
+		}
+		tolerance = parsed
+	}
+
+	result, err := e.evaluationService.CompareRuns(c.Request.Context(), baseID, targetID, tolerance)
+	if err != nil {
+		handleEvaluationError(c, err, map[string]interface{}{"base": baseID, "target": targetID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// CreateDataset godoc
+// @Summary      평가 데이터셋 업로드
+// @Description  질문과 정답 청크 ID 또는 정답을 담은 CSV, JSONL 파일을 평가 데이터셋으로 업로드.
+// @Description  CSV는 question, answer, gold_chunk_ids 헤더를 사용하고 청크 ID는 쉼표, 세미콜론, | 로 구분.
+// @Description  JSONL은 줄마다 question, answer, gold_chunk_ids(배열)를 가지며 피드백 데이터셋 내보내기 파일을 그대로 사용할 수 있음
+// @Tags         평가
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file         formData  file    true   "데이터셋 파일 (.csv, .jsonl)"
+// @Param        name         formData  string  false  "데이터셋 이름, 없으면 파일 이름"
+// @Param        description  formData  string  false  "설명"
+// @Success      200          {object}  map[string]interface{}  "생성된 데이터셋"
+// @Failure      400          {object}  errors.AppError         "요청 매개변수 오류"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /evaluation/datasets [post]
+func (e *EvaluationHandler) CreateDataset(c *gin.Context) {
+	ctx := c.Request.Context()
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.Error(errors.NewBadRequestError("Unable to get upload file").WithDetails(err.Error()))
+		return
+	}
+	if file.Size > secutils.GetMaxFileSize() {
+		c.Error(errors.NewBadRequestError(fmt.Sprintf("파일 크기는 %dMB를 초과할 수 없습니다", secutils.GetMaxFileSizeMB())))
+		return
+	}
+	reader, err := file.Open()
+	if err != nil {
+		c.Error(errors.NewBadRequestError("Unable to read upload file").WithDetails(err.Error()))
+		return
+	}
+	defer reader.Close()
+
+	dataset, err := e.datasetService.CreateDataset(ctx,
+		c.PostForm("name"), c.PostForm("description"), file.Filename, reader)
+	if err != nil {
+		handleEvaluationError(c, err, map[string]interface{}{"filename": secutils.SanitizeForLog(file.Filename)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    dataset,
+	})
+}
+
+// ListDatasets godoc
+// @Summary      평가 데이터셋 목록 조회
+// @Description  현재 테넌트가 업로드한 평가 데이터셋을 최신순으로 조회
+// @Tags         평가
+// @Produce      json
+// @Param        page       query     int  false  "페이지 번호"
+// @Param        page_size  query     int  false  "페이지당 개수"
+// @Success      200        {object}  map[string]interface{}  "평가 데이터셋 목록"
+// @Failure      400        {object}  errors.AppError         "요청 매개변수 오류"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /evaluation/datasets [get]
+func (e *EvaluationHandler) ListDatasets(c *gin.Context) {
+	var page types.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid pagination parameters").WithDetails(err.Error()))
+		return
+	}
+
+	result, err := e.datasetService.ListDatasets(c.Request.Context(), &page)
+	if err != nil {
+		handleEvaluationError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetDataset godoc
+// @Summary      평가 데이터셋 조회
+// @Description  업로드한 평가 데이터셋 정보 조회
+// @Tags         평가
+// @Produce      json
+// @Param        id   path      string  true  "데이터셋 ID"
+// @Success      200  {object}  map[string]interface{}  "평가 데이터셋"
+// @Failure      404  {object}  errors.AppError         "데이터셋을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /evaluation/datasets/{id} [get]
+func (e *EvaluationHandler) GetDataset(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	dataset, err := e.datasetService.GetDataset(c.Request.Context(), id)
+	if err != nil {
+		handleEvaluationError(c, err, map[string]interface{}{"dataset_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    dataset,
+	})
+}
+
+// ListDatasetItems godoc
+// @Summary      평가 데이터셋 질문 조회
+// @Description  업로드한 평가 데이터셋의 질문, 정답, 정답 청크 ID를 순서대로 조회
+// @Tags         평가
+// @Produce      json
+// @Param        id         path      string  true   "데이터셋 ID"
+// @Param        page       query     int     false  "페이지 번호"
+// @Param        page_size  query     int     false  "페이지당 개수"
+// @Success      200        {object}  map[string]interface{}  "데이터셋 질문 목록"
+// @Failure      404        {object}  errors.AppError         "데이터셋을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /evaluation/datasets/{id}/items [get]
+func (e *EvaluationHandler) ListDatasetItems(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	var page types.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid pagination parameters").WithDetails(err.Error()))
+		return
+	}
+
+	result, err := e.datasetService.ListDatasetItems(c.Request.Context(), id, &page)
+	if err != nil {
+		handleEvaluationError(c, err, map[string]interface{}{"dataset_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// DeleteDataset godoc
+// @Summary      평가 데이터셋 삭제
+// @Description  업로드한 평가 데이터셋 삭제. 이 데이터셋으로 만든 평가 실행은 유지됨
+// @Tags         평가
+// @Produce      json
+// @Param        id   path      string  true  "데이터셋 ID"
+// @Success      200  {object}  map[string]interface{}  "삭제 결과"
+// @Failure      404  {object}  errors.AppError         "데이터셋을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /evaluation/datasets/{id} [delete]
+func (e *EvaluationHandler) DeleteDataset(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if err := e.datasetService.DeleteDataset(c.Request.Context(), id); err != nil {
+		handleEvaluationError(c, err, map[string]interface{}{"dataset_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Evaluation dataset deleted successfully",
+	})
+}
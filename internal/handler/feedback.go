@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// FeedbackHandler 응답 피드백 HTTP 요청 처리
+type FeedbackHandler struct {
+	feedbackService interfaces.FeedbackService
+}
+
+// NewFeedbackHandler 새로운 피드백 핸들러 생성
+func NewFeedbackHandler(feedbackService interfaces.FeedbackService) *FeedbackHandler {
+	return &FeedbackHandler{feedbackService: feedbackService}
+}
+
+// handleFeedbackError 서비스 오류를 응답 오류로 변환합니다.
+func handleFeedbackError(c *gin.Context, err error, fields map[string]interface{}) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+		return
+	}
+	logger.ErrorWithFields(c.Request.Context(), err, fields)
+	c.Error(errors.NewInternalServerError(err.Error()))
+}
+
+// SubmitFeedback godoc
+// @Summary      메시지 피드백 제출
+// @Description  어시스턴트 메시지에 평가(up, down), 이유(incorrect, incomplete, wrong_citation, irrelevant, outdated, other),
+// @Description  의견과 인용 청크별 관련성을 남김. 이전 피드백은 대체됨. 청크는 메시지의 knowledge_references에 있어야 함.
+// @Description  피드백은 잠시 후 청크 신호로 집계되어 검색 결과 재정렬에 반영됨
+// @Tags         피드백
+// @Accept       json
+// @Produce      json
+// @Param        session_id  path      string                       true  "세션 ID"
+// @Param        id          path      string                       true  "메시지 ID"
+// @Param        request     body      types.SubmitFeedbackRequest  true  "피드백"
+// @Success      200         {object}  map[string]interface{}       "저장된 피드백"
+// @Failure      400         {object}  errors.AppError              "요청 매개변수 오류"
+// @Failure      404         {object}  errors.AppError              "메시지를 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /messages/{session_id}/{id}/feedback [put]
+func (h *FeedbackHandler) SubmitFeedback(c *gin.Context) {
+	sessionID := secutils.SanitizeForLog(c.Param("session_id"))
+	messageID := secutils.SanitizeForLog(c.Param("id"))
+	var req types.SubmitFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	feedback, err := h.feedbackService.SubmitFeedback(c.Request.Context(), sessionID, messageID, &req)
+	if err != nil {
+		handleFeedbackError(c, err, map[string]interface{}{"session_id": sessionID, "message_id": messageID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    feedback,
+	})
+}
+
+// DeleteFeedback godoc
+// @Summary      메시지 피드백 삭제
+// @Description  어시스턴트 메시지의 피드백을 삭제. 청크 신호는 잠시 후 다시 집계됨
+// @Tags         피드백
+// @Produce      json
+// @Param        session_id  path      string  true  "세션 ID"
+// @Param        id          path      string  true  "메시지 ID"
+// @Success      200         {object}  map[string]interface{}  "삭제 결과"
+// @Failure      404         {object}  errors.AppError         "메시지를 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /messages/{session_id}/{id}/feedback [delete]
+func (h *FeedbackHandler) DeleteFeedback(c *gin.Context) {
+	sessionID := secutils.SanitizeForLog(c.Param("session_id"))
+	messageID := secutils.SanitizeForLog(c.Param("id"))
+	if err := h.feedbackService.DeleteFeedback(c.Request.Context(), sessionID, messageID); err != nil {
+		handleFeedbackError(c, err, map[string]interface{}{"session_id": sessionID, "message_id": messageID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Feedback deleted successfully",
+	})
+}
+
+// ListFeedback godoc
+// @Summary      피드백 목록 조회
+// @Description  현재 테넌트의 피드백을 질문, 응답, 인용 청크와 함께 최신순으로 조회. 편집자 이상 권한 필요
+// @Tags         피드백
+// @Produce      json
+// @Param        rating      query     string  false  "평가 (up, down)"
+// @Param        reason      query     string  false  "이유"
+// @Param        session_id  query     string  false  "세션 ID"
+// @Param        from        query     string  false  "시작 시간 (RFC3339)"
+// @Param        to          query     string  false  "종료 시간 (RFC3339)"
+// @Param        page        query     int     false  "페이지 번호"
+// @Param        page_size   query     int     false  "페이지당 개수"
+// @Success      200         {object}  map[string]interface{}  "피드백 목록"
+// @Failure      400         {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403         {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /feedback [get]
+func (h *FeedbackHandler) ListFeedback(c *gin.Context) {
+	var filter types.FeedbackFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	var page types.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid pagination parameters").WithDetails(err.Error()))
+		return
+	}
+
+	result, err := h.feedbackService.ListFeedback(c.Request.Context(), &filter, &page)
+	if err != nil {
+		handleFeedbackError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// ListChunkSignals godoc
+// @Summary      청크 신호 목록 조회
+// @Description  피드백으로 집계한 청크별 관련성 신호를 강한 순서로 조회. score는 -1(강등)부터 1(가산)까지. 편집자 이상 권한 필요
+// @Tags         피드백
+// @Produce      json
+// @Param        page       query     int  false  "페이지 번호"
+// @Param        page_size  query     int  false  "페이지당 개수"
+// @Success      200        {object}  map[string]interface{}  "청크 신호 목록"
+// @Failure      400        {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403        {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /feedback/chunk-signals [get]
+func (h *FeedbackHandler) ListChunkSignals(c *gin.Context) {
+	var page types.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid pagination parameters").WithDetails(err.Error()))
+		return
+	}
+
+	result, err := h.feedbackService.ListChunkSignals(c.Request.Context(), &page)
+	if err != nil {
+		handleFeedbackError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// ExportFeedbackDataset godoc
+// @Summary      피드백 평가 데이터셋 내보내기
+// @Description  필터와 일치하는 피드백을 평가 데이터셋 JSONL 파일로 내보내기. 각 줄은 질문, 긍정 평가를 받은 응답,
+// @Description  관련 있다고 평가된 청크 ID(gold_chunk_ids)와 관련 없다고 평가된 청크 ID를 포함.
+// @Description  관련 청크가 없는 피드백은 제외됨. 편집자 이상 권한 필요
+// @Tags         피드백
+// @Produce      application/x-ndjson
+// @Param        rating      query     string  false  "평가 (up, down)"
+// @Param        reason      query     string  false  "이유"
+// @Param        session_id  query     string  false  "세션 ID"
+// @Param        from        query     string  false  "시작 시간 (RFC3339)"
+// @Param        to          query     string  false  "종료 시간 (RFC3339)"
+// @Success      200         {file}    file    "평가 데이터셋 파일"
+// @Failure      400         {object}  errors.AppError  "요청 매개변수 오류"
+// @Failure      403         {object}  errors.AppError  "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /feedback/dataset [get]
+func (h *FeedbackHandler) ExportFeedbackDataset(c *gin.Context) {
+	ctx := c.Request.Context()
+	var filter types.FeedbackFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+
+	filename := fmt.Sprintf("feedback_dataset_%s.jsonl", time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	// 응답이 이미 시작되었으므로 오류는 로그로만 남기고 파일이 불완전하게 끝남
+	encoder := json.NewEncoder(c.Writer)
+	if err := h.feedbackService.ExportDataset(ctx, &filter, func(item *types.FeedbackDatasetItem) error {
+		return encoder.Encode(item)
+	}); err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+	}
+}
@@ -238,17 +238,16 @@ func (h *InitializationHandler) UpdateKBConfig(c *gin.Context) {
 		return
 	}
 
+	// 재임베딩 중에는 구성을 수정할 수 없음
+	if err := h.knowledgeService.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
 	// Embedding 모델 수정 가능 여부 확인
 	if kb.EmbeddingModelID != "" && kb.EmbeddingModelID != req.EmbeddingModelID {
-		// 파일 존재 여부 확인
-		knowledgeList, err := h.knowledgeService.ListPagedKnowledgeByKnowledgeBaseID(ctx,
-			kbIdStr, &types.Pagination{
-				Page:     1,
-				PageSize: 1,
-			}, "", "", "")
-		if err == nil && knowledgeList != nil && knowledgeList.Total > 0 {
-			logger.Error(ctx, "Cannot change embedding model when files exist")
-			c.Error(errors.NewBadRequestError("지식베이스에 파일이 있어 Embedding 모델을 수정할 수 없습니다"))
+		if err := h.ensureEmbeddingModelChangeable(ctx, kb.ID); err != nil {
+			c.Error(err)
 			return
 		}
 	}
@@ -428,6 +427,20 @@ func (h *InitializationHandler) InitializeByKB(c *gin.Context) {
 		return
 	}
 
+	// 재임베딩 중에는 구성을 수정할 수 없음
+	if err := h.knowledgeService.EnsureKnowledgeBaseWritable(ctx, kb.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 기존 Embedding 모델을 다른 모델로 바꾸는 경우 파일이 없어야 함
+	if h.embeddingModelChanged(ctx, kb, req) {
+		if err := h.ensureEmbeddingModelChangeable(ctx, kb.ID); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
 	processedModels, err := h.processInitializationModels(ctx, kb, kbIdStr, req)
 	if err != nil {
 		c.Error(err)
@@ -452,6 +465,51 @@ func (h *InitializationHandler) InitializeByKB(c *gin.Context) {
 	})
 }
 
+// ensureEmbeddingModelChangeable 지식베이스의 Embedding 모델을 직접 변경할 수 있는지 확인합니다.
+// 파일이 있는 지식베이스의 벡터는 기존 모델로 생성되었으므로 재임베딩을 사용해야 합니다.
+func (h *InitializationHandler) ensureEmbeddingModelChangeable(ctx context.Context, kbID string) error {
+	knowledgeList, err := h.knowledgeService.ListPagedKnowledgeByKnowledgeBaseID(ctx,
+		kbID, &types.Pagination{
+			Page:     1,
+			PageSize: 1,
+		}, "", "", "")
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"kbId": kbID})
+		return errors.NewInternalServerError("지식베이스 파일 확인 실패: " + err.Error())
+	}
+	if knowledgeList != nil && knowledgeList.Total > 0 {
+		logger.Error(ctx, "Cannot change embedding model when files exist")
+		return errors.NewBadRequestError("지식베이스에 파일이 있어 Embedding 모델을 직접 수정할 수 없습니다. 재임베딩(/knowledge-bases/{id}/reembed)을 사용하세요")
+	}
+	return nil
+}
+
+// embeddingModelChanged 초기화 요청이 지식베이스의 기존 Embedding 모델을 다른 모델로 바꾸는지 확인합니다.
+// 초기화는 기존 모델 레코드를 요청 내용으로 덮어쓰므로 모델 ID가 같아도 모델이 바뀔 수 있습니다.
+func (h *InitializationHandler) embeddingModelChanged(
+	ctx context.Context, kb *types.KnowledgeBase, req *InitializationRequest,
+) bool {
+	if kb.EmbeddingModelID == "" {
+		return false
+	}
+	for _, descriptor := range buildModelDescriptors(req) {
+		if descriptor.modelType != types.ModelTypeEmbedding {
+			continue
+		}
+		existing, err := h.modelService.GetModelByID(ctx, kb.EmbeddingModelID)
+		if err != nil || existing == nil {
+			return true
+		}
+		model := descriptor.toModel()
+		return existing.Name != model.Name ||
+			existing.Source != model.Source ||
+			existing.Parameters.BaseURL != model.Parameters.BaseURL ||
+			existing.Parameters.EmbeddingParameters.Dimension != model.Parameters.EmbeddingParameters.Dimension
+	}
+	// Embedding 모델이 없는 요청은 지식베이스의 Embedding 모델을 비움
+	return true
+}
+
 func (h *InitializationHandler) bindInitializationRequest(ctx context.Context, c *gin.Context) (*InitializationRequest, error) {
 	var req InitializationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -18,16 +19,47 @@ import (
 
 // KnowledgeHandler 지식 리소스 관련 HTTP 요청 처리
 type KnowledgeHandler struct {
-	kgService interfaces.KnowledgeService
-	kbService interfaces.KnowledgeBaseService
+	kgService         interfaces.KnowledgeService
+	kbService         interfaces.KnowledgeBaseService
+	permissionService interfaces.PermissionService
 }
 
 // NewKnowledgeHandler 새로운 KnowledgeHandler 인스턴스 생성
 func NewKnowledgeHandler(
 	kgService interfaces.KnowledgeService,
 	kbService interfaces.KnowledgeBaseService,
+	permissionService interfaces.PermissionService,
 ) *KnowledgeHandler {
-	return &KnowledgeHandler{kgService: kgService, kbService: kbService}
+	return &KnowledgeHandler{kgService: kgService, kbService: kbService, permissionService: permissionService}
+}
+
+// filterAccessibleKnowledge 요청 주체가 지정된 권한을 가진 지식베이스의 지식만 남깁니다.
+func (h *KnowledgeHandler) filterAccessibleKnowledge(ctx context.Context,
+	knowledges []*types.Knowledge, permission types.Permission,
+) ([]*types.Knowledge, error) {
+	kbIDs := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, k := range knowledges {
+		if !seen[k.KnowledgeBaseID] {
+			seen[k.KnowledgeBaseID] = true
+			kbIDs = append(kbIDs, k.KnowledgeBaseID)
+		}
+	}
+	allowed, err := h.permissionService.FilterAccessible(ctx, types.ResourceTypeKnowledgeBase, kbIDs, permission)
+	if err != nil {
+		return nil, err
+	}
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, id := range allowed {
+		allowedSet[id] = true
+	}
+	accessible := make([]*types.Knowledge, 0, len(knowledges))
+	for _, k := range knowledges {
+		if allowedSet[k.KnowledgeBaseID] {
+			accessible = append(accessible, k)
+		}
+	}
+	return accessible, nil
 }
 
 // validateKnowledgeBaseAccess 지식베이스에 대한 접근 권한을 확인합니다.
@@ -586,6 +618,12 @@ func (h *KnowledgeHandler) GetKnowledgeBatch(c *gin.Context) {
 		c.Error(errors.NewInternalServerError("Failed to retrieve knowledge list").WithDetails(err.Error()))
 		return
 	}
+	knowledges, err = h.filterAccessibleKnowledge(ctx, knowledges, types.PermissionRead)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError("Failed to retrieve knowledge list").WithDetails(err.Error()))
+		return
+	}
 
 	logger.Infof(
 		ctx,
@@ -694,6 +732,218 @@ func (h *KnowledgeHandler) UpdateManualKnowledge(c *gin.Context) {
 	})
 }
 
+// UpdateKnowledgeFile godoc
+// @Summary      지식 파일 업데이트
+// @Description  파일 지식을 새 버전의 파일로 교체. 변경되지 않은 청크는 유지되고 추가되거나 변경된 청크만 다시 처리
+// @Tags         지식 관리
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id                path      string  true   "지식 ID"
+// @Param        file              formData  file    true   "새 버전의 파일"
+// @Param        enable_multimodel formData  bool    false  "멀티모달 처리 활성화"
+// @Success      200               {object}  map[string]interface{}  "업데이트된 지식"
+// @Failure      400               {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      409               {object}  errors.AppError         "지식이 처리 중"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /knowledge/{id}/file [put]
+func (h *KnowledgeHandler) UpdateKnowledgeFile(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger.Info(ctx, "Start updating knowledge file")
+
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if id == "" {
+		logger.Error(ctx, "Knowledge ID is empty")
+		c.Error(errors.NewBadRequestError("Knowledge ID cannot be empty"))
+		return
+	}
+
+	// 업로드된 파일 가져오기
+	file, err := c.FormFile("file")
+	if err != nil {
+		logger.Error(ctx, "File upload failed", err)
+		c.Error(errors.NewBadRequestError("File upload failed").WithDetails(err.Error()))
+		return
+	}
+
+	// 파일 크기 확인 (MAX_FILE_SIZE_MB를 통해 구성 가능)
+	if file.Size > secutils.GetMaxFileSize() {
+		logger.Error(ctx, "File size too large")
+		c.Error(errors.NewBadRequestError(fmt.Sprintf("파일 크기는 %dMB를 초과할 수 없습니다", secutils.GetMaxFileSizeMB())))
+		return
+	}
+
+	var enableMultimodel *bool
+	if enableMultimodelForm := c.PostForm("enable_multimodel"); enableMultimodelForm != "" {
+		parseBool, err := strconv.ParseBool(enableMultimodelForm)
+		if err != nil {
+			logger.Error(ctx, "Failed to parse enable_multimodel", err)
+			c.Error(errors.NewBadRequestError("Invalid enable_multimodel format").WithDetails(err.Error()))
+			return
+		}
+		enableMultimodel = &parseBool
+	}
+
+	knowledge, err := h.kgService.UpdateKnowledgeFile(ctx, id, file, enableMultimodel)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			c.Error(appErr)
+			return
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{
+			"knowledge_id": id,
+		})
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+
+	logger.Infof(ctx, "Knowledge file updated successfully, knowledge ID: %s", id)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    knowledge,
+	})
+}
+
+// ListKnowledgeVersions godoc
+// @Summary      지식 버전 목록 조회
+// @Description  지식의 버전 기록을 최신 버전부터 조회
+// @Tags         지식 관리
+// @Produce      json
+// @Param        id   path      string  true  "지식 ID"
+// @Success      200  {object}  map[string]interface{}  "버전 목록"
+// @Failure      404  {object}  errors.AppError         "지식을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /knowledge/{id}/versions [get]
+func (h *KnowledgeHandler) ListKnowledgeVersions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if id == "" {
+		c.Error(errors.NewBadRequestError("Knowledge ID cannot be empty"))
+		return
+	}
+
+	versions, err := h.kgService.ListKnowledgeVersions(ctx, id)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			c.Error(appErr)
+			return
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{
+			"knowledge_id": id,
+		})
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    versions,
+	})
+}
+
+// DiffKnowledgeVersions godoc
+// @Summary      지식 버전 비교
+// @Description  지식의 두 버전 사이에 추가되거나 삭제된 청크를 조회
+// @Tags         지식 관리
+// @Produce      json
+// @Param        id    path      string  true  "지식 ID"
+// @Param        from  query     int     true  "기준 버전"
+// @Param        to    query     int     true  "대상 버전"
+// @Success      200   {object}  map[string]interface{}  "버전 비교 결과"
+// @Failure      400   {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      404   {object}  errors.AppError         "버전을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /knowledge/{id}/versions/diff [get]
+func (h *KnowledgeHandler) DiffKnowledgeVersions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if id == "" {
+		c.Error(errors.NewBadRequestError("Knowledge ID cannot be empty"))
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from <= 0 {
+		c.Error(errors.NewBadRequestError("Invalid from version"))
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil || to <= 0 {
+		c.Error(errors.NewBadRequestError("Invalid to version"))
+		return
+	}
+
+	diff, err := h.kgService.DiffKnowledgeVersions(ctx, id, from, to)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			c.Error(appErr)
+			return
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{
+			"knowledge_id": id,
+		})
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    diff,
+	})
+}
+
+// RestoreKnowledgeVersion godoc
+// @Summary      지식 버전 복원
+// @Description  이전 버전의 내용을 새 버전으로 복원. 파일 지식은 보존된 파일로, 수동 지식은 저장된 내용으로 다시 처리
+// @Tags         지식 관리
+// @Produce      json
+// @Param        id       path      string  true  "지식 ID"
+// @Param        version  path      int     true  "복원할 버전"
+// @Success      200      {object}  map[string]interface{}  "복원된 지식"
+// @Failure      400      {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      404      {object}  errors.AppError         "버전을 찾을 수 없음"
+// @Failure      409      {object}  errors.AppError         "지식이 처리 중"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /knowledge/{id}/versions/{version}/restore [post]
+func (h *KnowledgeHandler) RestoreKnowledgeVersion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if id == "" {
+		c.Error(errors.NewBadRequestError("Knowledge ID cannot be empty"))
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.Error(errors.NewBadRequestError("Invalid version"))
+		return
+	}
+
+	knowledge, err := h.kgService.RestoreKnowledgeVersion(ctx, id, version)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			c.Error(appErr)
+			return
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{
+			"knowledge_id": id,
+			"version":      version,
+		})
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+
+	logger.Infof(ctx, "Knowledge version restored, knowledge ID: %s, version: %d", id, version)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    knowledge,
+	})
+}
+
 type knowledgeTagBatchRequest struct {
 	Updates map[string]*string `json:"updates" binding:"required,min=1"`
 }
@@ -718,6 +968,27 @@ func (h *KnowledgeHandler) UpdateKnowledgeTagBatch(c *gin.Context) {
 		c.Error(errors.NewBadRequestError("요청 매개변수가 유효하지 않습니다").WithDetails(err.Error()))
 		return
 	}
+	// 모든 지식이 속한 지식베이스에 대한 쓰기 권한 확인
+	ids := make([]string, 0, len(req.Updates))
+	for id := range req.Updates {
+		ids = append(ids, id)
+	}
+	knowledges, err := h.kgService.GetKnowledgeBatch(ctx, c.GetUint64(types.TenantIDContextKey.String()), ids)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+	writable, err := h.filterAccessibleKnowledge(ctx, knowledges, types.PermissionWrite)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+	if len(writable) < len(knowledges) {
+		c.Error(errors.NewForbiddenError("이 리소스에 대한 권한이 없습니다"))
+		return
+	}
 	if err := h.kgService.UpdateKnowledgeTagBatch(ctx, req.Updates); err != nil {
 		logger.ErrorWithFields(ctx, err, nil)
 		c.Error(err)
@@ -825,6 +1096,12 @@ func (h *KnowledgeHandler) SearchKnowledge(c *gin.Context) {
 		c.Error(errors.NewInternalServerError("Failed to search knowledge").WithDetails(err.Error()))
 		return
 	}
+	knowledges, err = h.filterAccessibleKnowledge(ctx, knowledges, types.PermissionRead)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError("Failed to search knowledge").WithDetails(err.Error()))
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":  true,
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/Tencent/WeKnora/internal/application/service/retriever"
 	"github.com/Tencent/WeKnora/internal/errors"
 	"github.com/Tencent/WeKnora/internal/logger"
 	"github.com/Tencent/WeKnora/internal/types"
@@ -19,21 +20,24 @@ import (
 
 // KnowledgeBaseHandler 지식베이스 작업을 위한 HTTP 핸들러 정의
 type KnowledgeBaseHandler struct {
-	service          interfaces.KnowledgeBaseService
-	knowledgeService interfaces.KnowledgeService
-	asynqClient      *asynq.Client
+	service           interfaces.KnowledgeBaseService
+	knowledgeService  interfaces.KnowledgeService
+	permissionService interfaces.PermissionService
+	asynqClient       *asynq.Client
 }
 
 // NewKnowledgeBaseHandler 새로운 지식베이스 핸들러 인스턴스 생성
 func NewKnowledgeBaseHandler(
 	service interfaces.KnowledgeBaseService,
 	knowledgeService interfaces.KnowledgeService,
+	permissionService interfaces.PermissionService,
 	asynqClient *asynq.Client,
 ) *KnowledgeBaseHandler {
 	return &KnowledgeBaseHandler{
-		service:          service,
-		knowledgeService: knowledgeService,
-		asynqClient:      asynqClient,
+		service:           service,
+		knowledgeService:  knowledgeService,
+		permissionService: permissionService,
+		asynqClient:       asynqClient,
 	}
 }
 
@@ -71,6 +75,22 @@ func (h *KnowledgeBaseHandler) HybridSearch(c *gin.Context) {
 		return
 	}
 
+	// 메타데이터 필터 검증
+	if req.Filter != nil {
+		if err := req.Filter.Validate(); err != nil {
+			logger.Error(ctx, "Invalid metadata filter", err)
+			c.Error(errors.NewBadRequestError("Invalid metadata filter").WithDetails(err.Error()))
+			return
+		}
+	}
+
+	// 융합 구성 검증
+	if err := retriever.ValidateFusionConfig(req.FusionConfig); err != nil {
+		logger.Error(ctx, "Invalid fusion config", err)
+		c.Error(err)
+		return
+	}
+
 	logger.Infof(ctx, "Executing hybrid search, knowledge base ID: %s, query: %s",
 		secutils.SanitizeForLog(id), secutils.SanitizeForLog(req.QueryText))
 
@@ -78,6 +98,10 @@ func (h *KnowledgeBaseHandler) HybridSearch(c *gin.Context) {
 	results, err := h.service.HybridSearch(ctx, id, req)
 	if err != nil {
 		logger.ErrorWithFields(ctx, err, nil)
+		if appErr, ok := errors.IsAppError(err); ok {
+			c.Error(appErr)
+			return
+		}
 		c.Error(errors.NewInternalServerError(err.Error()))
 		return
 	}
@@ -119,11 +143,26 @@ func (h *KnowledgeBaseHandler) CreateKnowledgeBase(c *gin.Context) {
 		c.Error(err)
 		return
 	}
+	if err := retriever.ValidateFusionConfig(req.FusionConfig); err != nil {
+		logger.Error(ctx, "Invalid fusion config", err)
+		c.Error(err)
+		return
+	}
 
 	logger.Infof(ctx, "Creating knowledge base, name: %s", secutils.SanitizeForLog(req.Name))
 	// 서비스를 사용하여 지식베이스 생성
 	kb, err := h.service.CreateKnowledgeBase(ctx, &req)
 	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		if appErr, ok := errors.IsAppError(err); ok {
+			c.Error(appErr)
+			return
+		}
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+	// 편집자가 생성한 지식베이스는 생성자에게 관리 권한을 부여
+	if err := h.permissionService.GrantCreator(ctx, types.ResourceTypeKnowledgeBase, kb.ID); err != nil {
 		logger.ErrorWithFields(ctx, err, nil)
 		c.Error(errors.NewInternalServerError(err.Error()))
 		return
@@ -225,6 +264,15 @@ func (h *KnowledgeBaseHandler) ListKnowledgeBases(c *gin.Context) {
 		return
 	}
 
+	// 접근 가능한 지식베이스만 반환
+	kbs, err = filterAccessible(ctx, h.permissionService, types.ResourceTypeKnowledgeBase, kbs,
+		func(kb *types.KnowledgeBase) string { return kb.ID })
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    kbs,
@@ -269,6 +317,11 @@ func (h *KnowledgeBaseHandler) UpdateKnowledgeBase(c *gin.Context) {
 		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
 		return
 	}
+	if err := retriever.ValidateFusionConfig(req.Config.FusionConfig); err != nil {
+		logger.Error(ctx, "Invalid fusion config", err)
+		c.Error(err)
+		return
+	}
 
 	logger.Infof(ctx, "Updating knowledge base, ID: %s, name: %s",
 		secutils.SanitizeForLog(id), secutils.SanitizeForLog(req.Name))
@@ -277,6 +330,10 @@ func (h *KnowledgeBaseHandler) UpdateKnowledgeBase(c *gin.Context) {
 	kb, err := h.service.UpdateKnowledgeBase(ctx, id, req.Name, req.Description, req.Config)
 	if err != nil {
 		logger.ErrorWithFields(ctx, err, nil)
+		if appErr, ok := errors.IsAppError(err); ok {
+			c.Error(appErr)
+			return
+		}
 		c.Error(errors.NewInternalServerError(err.Error()))
 		return
 	}
@@ -321,6 +378,9 @@ func (h *KnowledgeBaseHandler) DeleteKnowledgeBase(c *gin.Context) {
 		c.Error(errors.NewInternalServerError(err.Error()))
 		return
 	}
+	if err := h.permissionService.DeleteResourceGrants(ctx, types.ResourceTypeKnowledgeBase, id); err != nil {
+		logger.Warnf(ctx, "Failed to delete grants of knowledge base %s: %v", secutils.SanitizeForLog(id), err)
+	}
 
 	logger.Infof(ctx, "Knowledge base deleted successfully, ID: %s",
 		secutils.SanitizeForLog(id))
@@ -372,6 +432,22 @@ func (h *KnowledgeBaseHandler) CopyKnowledgeBase(c *gin.Context) {
 		return
 	}
 
+	// 원본 지식베이스 읽기 권한과 대상 지식베이스 쓰기 권한 확인
+	if err := h.permissionService.CheckPermission(
+		ctx, types.ResourceTypeKnowledgeBase, req.SourceID, types.PermissionRead,
+	); err != nil {
+		c.Error(err)
+		return
+	}
+	if req.TargetID != "" {
+		if err := h.permissionService.CheckPermission(
+			ctx, types.ResourceTypeKnowledgeBase, req.TargetID, types.PermissionWrite,
+		); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
 	// 작업 ID 생성
 	taskID := uuid.New().String()
 
@@ -437,6 +513,7 @@ func (h *KnowledgeBaseHandler) CopyKnowledgeBase(c *gin.Context) {
 // @Produce      json
 // @Param        task_id  path      string  true  "작업 ID"
 // @Success      200      {object}  map[string]interface{}  "진행 정보"
+// @Failure      403      {object}  errors.AppError         "원본 지식베이스 읽기 권한 없음"
 // @Failure      404      {object}  errors.AppError         "작업을 찾을 수 없음"
 // @Security     Bearer
 // @Security     ApiKeyAuth
@@ -464,6 +541,95 @@ func (h *KnowledgeBaseHandler) GetKBCloneProgress(c *gin.Context) {
 	})
 }
 
+// ReembedKnowledgeBaseRequest 지식베이스 재임베딩 요청 정의
+// EmbeddingModelID가 비어 있거나 현재 모델과 같으면 현재 모델로 재색인하여 필터 속성을 다시 기록
+type ReembedKnowledgeBaseRequest struct {
+	EmbeddingModelID string `json:"embedding_model_id"`
+}
+
+// ReembedKnowledgeBase godoc
+// @Summary      지식베이스 재임베딩
+// @Description  새 임베딩 모델로 지식베이스의 모든 청크를 다시 임베딩 (비동기 작업), 완료 시 새 인덱스로 전환
+// @Description  모델을 생략하거나 현재 모델을 지정하면 현재 모델로 재색인하여 기존 인덱스의 메타데이터 필터 속성을 채움
+// @Tags         지식베이스
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                       true  "지식베이스 ID"
+// @Param        request  body      ReembedKnowledgeBaseRequest  true  "재임베딩 요청"
+// @Success      200      {object}  map[string]interface{}       "작업 진행 정보"
+// @Failure      400      {object}  errors.AppError              "요청 매개변수 오류"
+// @Failure      409      {object}  errors.AppError              "이미 진행 중인 작업 존재"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /knowledge-bases/{id}/reembed [post]
+func (h *KnowledgeBaseHandler) ReembedKnowledgeBase(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 지식베이스 검증 및 가져오기
+	kb, _, err := h.validateAndGetKnowledgeBase(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req ReembedKnowledgeBaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error(ctx, "Failed to parse request parameters", err)
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+
+	progress, err := h.knowledgeService.ReembedKnowledgeBase(ctx, kb.ID, req.EmbeddingModelID)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(err)
+		return
+	}
+
+	logger.Infof(ctx, "KB reembed task started: %s, knowledge base: %s, model: %s",
+		progress.TaskID, kb.ID, secutils.SanitizeForLog(req.EmbeddingModelID))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    progress,
+	})
+}
+
+// GetKBReembedProgress godoc
+// @Summary      지식베이스 재임베딩 진행 상황 조회
+// @Description  지식베이스 재임베딩 작업의 진행 상황 조회
+// @Tags         지식베이스
+// @Accept       json
+// @Produce      json
+// @Param        task_id  path      string  true  "작업 ID"
+// @Success      200      {object}  map[string]interface{}  "진행 정보"
+// @Failure      403      {object}  errors.AppError         "지식베이스 읽기 권한 없음"
+// @Failure      404      {object}  errors.AppError         "작업을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /knowledge-bases/reembed/progress/{task_id} [get]
+func (h *KnowledgeBaseHandler) GetKBReembedProgress(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		logger.Error(ctx, "Task ID is empty")
+		c.Error(errors.NewBadRequestError("Task ID cannot be empty"))
+		return
+	}
+
+	progress, err := h.knowledgeService.GetKBReembedProgress(ctx, taskID)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    progress,
+	})
+}
+
 // validateExtractConfig 그래프 구성 매개변수 검증
 func validateExtractConfig(config *types.ExtractConfig) error {
 	logger.Errorf(context.Background(), "Validating extract configuration: %+v", config)
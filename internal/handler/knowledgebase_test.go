@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/middleware"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeKnowledgeBaseService answers hybrid searches with a fixed error and saves knowledge bases of tenant 1
+type fakeKnowledgeBaseService struct {
+	interfaces.KnowledgeBaseService
+	searchErr error
+	searched  bool
+	saved     bool
+}
+
+func (s *fakeKnowledgeBaseService) GetKnowledgeBaseByID(ctx context.Context, id string) (*types.KnowledgeBase, error) {
+	return &types.KnowledgeBase{ID: id, TenantID: 1}, nil
+}
+
+func (s *fakeKnowledgeBaseService) CreateKnowledgeBase(ctx context.Context,
+	kb *types.KnowledgeBase,
+) (*types.KnowledgeBase, error) {
+	s.saved = true
+	kb.ID = "kb1"
+	return kb, nil
+}
+
+func (s *fakeKnowledgeBaseService) UpdateKnowledgeBase(ctx context.Context,
+	id string, name string, description string, config *types.KnowledgeBaseConfig,
+) (*types.KnowledgeBase, error) {
+	s.saved = true
+	return &types.KnowledgeBase{ID: id, TenantID: 1, Name: name, FusionConfig: config.FusionConfig}, nil
+}
+
+// grantingPermissionService accepts every grant
+type grantingPermissionService struct {
+	interfaces.PermissionService
+}
+
+func (s *grantingPermissionService) GrantCreator(ctx context.Context,
+	resourceType types.ResourceType, resourceID string,
+) error {
+	return nil
+}
+
+func (s *fakeKnowledgeBaseService) HybridSearch(ctx context.Context,
+	id string, params types.SearchParams,
+) ([]*types.SearchResult, error) {
+	s.searched = true
+	return nil, s.searchErr
+}
+
+func TestKnowledgeBaseHandler_HybridSearchFusionConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name         string
+		body         string
+		searchErr    error
+		wantStatus   int
+		wantSearched bool
+	}{
+		{name: "default fusion", body: `{"query_text":"pump"}`, wantStatus: http.StatusOK, wantSearched: true},
+		{name: "weighted fusion", body: `{"query_text":"pump","fusion_config":{"method":"weighted","vector_weight":0.7}}`,
+			wantStatus: http.StatusOK, wantSearched: true},
+		{name: "unknown method", body: `{"query_text":"pump","fusion_config":{"method":"borda"}}`,
+			wantStatus: http.StatusBadRequest},
+		{name: "negative weight", body: `{"query_text":"pump","fusion_config":{"method":"rrf","keyword_weight":-1}}`,
+			wantStatus: http.StatusBadRequest},
+		{name: "service error", body: `{"query_text":"pump"}`,
+			searchErr: errors.NewNotFoundError("지식베이스를 찾을 수 없습니다"), wantStatus: http.StatusNotFound, wantSearched: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeKnowledgeBaseService{searchErr: tt.searchErr}
+			h := NewKnowledgeBaseHandler(service, nil, nil, nil)
+			router := gin.New()
+			router.Use(middleware.ErrorHandler())
+			router.GET("/knowledge-bases/:id/hybrid-search", h.HybridSearch)
+
+			req := httptest.NewRequest(http.MethodGet, "/knowledge-bases/kb1/hybrid-search", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if service.searched != tt.wantSearched {
+				t.Errorf("searched = %v, want %v", service.searched, tt.wantSearched)
+			}
+		})
+	}
+}
+
+func TestKnowledgeBaseHandler_SaveFusionConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "create with weighted fusion", method: http.MethodPost, path: "/knowledge-bases",
+			body:       `{"name":"kb","fusion_config":{"method":"weighted","vector_weight":0.7}}`,
+			wantStatus: http.StatusCreated},
+		{name: "create with unknown method", method: http.MethodPost, path: "/knowledge-bases",
+			body: `{"name":"kb","fusion_config":{"method":"borda"}}`, wantStatus: http.StatusBadRequest},
+		{name: "create with negative weight", method: http.MethodPost, path: "/knowledge-bases",
+			body: `{"name":"kb","fusion_config":{"keyword_weight":-1}}`, wantStatus: http.StatusBadRequest},
+		{name: "update with rrf", method: http.MethodPut, path: "/knowledge-bases/kb1",
+			body:       `{"name":"kb","config":{"fusion_config":{"method":"rrf","rrf_k":10}}}`,
+			wantStatus: http.StatusOK},
+		{name: "update with unknown method", method: http.MethodPut, path: "/knowledge-bases/kb1",
+			body: `{"name":"kb","config":{"fusion_config":{"method":"borda"}}}`, wantStatus: http.StatusBadRequest},
+		{name: "update with negative rrf_k", method: http.MethodPut, path: "/knowledge-bases/kb1",
+			body: `{"name":"kb","config":{"fusion_config":{"rrf_k":-5}}}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeKnowledgeBaseService{}
+			h := NewKnowledgeBaseHandler(service, nil, &grantingPermissionService{}, nil)
+			router := gin.New()
+			router.Use(middleware.ErrorHandler())
+			router.Use(func(c *gin.Context) {
+				c.Set(types.TenantIDContextKey.String(), uint64(1))
+				c.Request = c.Request.WithContext(
+					context.WithValue(c.Request.Context(), types.TenantIDContextKey, uint64(1)))
+				c.Next()
+			})
+			router.POST("/knowledge-bases", h.CreateKnowledgeBase)
+			router.PUT("/knowledge-bases/:id", h.UpdateKnowledgeBase)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			// An invalid fusion config is never stored
+			if wantSaved := tt.wantStatus < 300; service.saved != wantSaved {
+				t.Errorf("saved = %v, want %v", service.saved, wantSaved)
+			}
+		})
+	}
+}
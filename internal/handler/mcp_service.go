@@ -14,12 +14,17 @@ import (
 // MCPServiceHandler MCP 서비스 관련 HTTP 요청 처리
 type MCPServiceHandler struct {
 	mcpServiceService interfaces.MCPServiceService
+	permissionService interfaces.PermissionService
 }
 
 // NewMCPServiceHandler 새로운 MCP 서비스 핸들러 생성
-func NewMCPServiceHandler(mcpServiceService interfaces.MCPServiceService) *MCPServiceHandler {
+func NewMCPServiceHandler(
+	mcpServiceService interfaces.MCPServiceService,
+	permissionService interfaces.PermissionService,
+) *MCPServiceHandler {
 	return &MCPServiceHandler{
 		mcpServiceService: mcpServiceService,
+		permissionService: permissionService,
 	}
 }
 
@@ -58,6 +63,12 @@ func (h *MCPServiceHandler) CreateMCPService(c *gin.Context) {
 		c.Error(errors.NewInternalServerError("Failed to create MCP service: " + err.Error()))
 		return
 	}
+	// 편집자가 생성한 MCP 서비스는 생성자에게 관리 권한을 부여
+	if err := h.permissionService.GrantCreator(ctx, types.ResourceTypeMCPService, service.ID); err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"service_id": service.ID})
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -92,6 +103,14 @@ func (h *MCPServiceHandler) ListMCPServices(c *gin.Context) {
 		c.Error(errors.NewInternalServerError("Failed to list MCP services: " + err.Error()))
 		return
 	}
+	// 접근 가능한 MCP 서비스만 반환
+	services, err = filterAccessible(ctx, h.permissionService, types.ResourceTypeMCPService, services,
+		func(service *types.MCPService) string { return service.ID })
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"tenant_id": tenantID})
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -295,6 +314,9 @@ func (h *MCPServiceHandler) DeleteMCPService(c *gin.Context) {
 		c.Error(errors.NewInternalServerError("Failed to delete MCP service: " + err.Error()))
 		return
 	}
+	if err := h.permissionService.DeleteResourceGrants(ctx, types.ResourceTypeMCPService, serviceID); err != nil {
+		logger.Warnf(ctx, "Failed to delete grants of MCP service %s: %v", secutils.SanitizeForLog(serviceID), err)
+	}
 
 	logger.Infof(ctx, "MCP service deleted successfully: %s", secutils.SanitizeForLog(serviceID))
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// MemoryHandler 사용자 장기 기억 HTTP 요청 처리
+type MemoryHandler struct {
+	memoryService interfaces.MemoryService
+}
+
+// NewMemoryHandler 새로운 사용자 기억 핸들러 생성
+func NewMemoryHandler(memoryService interfaces.MemoryService) *MemoryHandler {
+	return &MemoryHandler{memoryService: memoryService}
+}
+
+// handleMemoryError 서비스 오류를 응답 오류로 변환합니다.
+func handleMemoryError(c *gin.Context, err error, fields map[string]interface{}) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+		return
+	}
+	logger.ErrorWithFields(c.Request.Context(), err, fields)
+	c.Error(errors.NewInternalServerError(err.Error()))
+}
+
+// ListMemories godoc
+// @Summary      사용자 기억 목록 조회
+// @Description  대화에서 추출한 현재 사용자의 기억을 최근 업데이트 순으로 조회. 로그인한 사용자만 사용 가능
+// @Tags         사용자 기억
+// @Produce      json
+// @Param        category   query     string  false  "기억 유형 (fact, preference)"
+// @Param        keyword    query     string  false  "내용에 포함된 키워드"
+// @Param        page       query     int     false  "페이지 번호"
+// @Param        page_size  query     int     false  "페이지당 개수"
+// @Success      200        {object}  map[string]interface{}  "기억 목록"
+// @Failure      400        {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403        {object}  errors.AppError         "로그인한 사용자가 아님"
+// @Security     Bearer
+// @Router       /memories [get]
+func (h *MemoryHandler) ListMemories(c *gin.Context) {
+	var filter types.UserMemoryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	var page types.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid pagination parameters").WithDetails(err.Error()))
+		return
+	}
+
+	result, err := h.memoryService.ListMemories(c.Request.Context(), &filter, &page)
+	if err != nil {
+		handleMemoryError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetMemory godoc
+// @Summary      사용자 기억 조회
+// @Description  현재 사용자의 기억 하나를 조회
+// @Tags         사용자 기억
+// @Produce      json
+// @Param        id   path      string  true  "기억 ID"
+// @Success      200  {object}  map[string]interface{}  "기억"
+// @Failure      403  {object}  errors.AppError         "로그인한 사용자가 아님"
+// @Failure      404  {object}  errors.AppError         "기억을 찾을 수 없음"
+// @Security     Bearer
+// @Router       /memories/{id} [get]
+func (h *MemoryHandler) GetMemory(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	memory, err := h.memoryService.GetMemory(c.Request.Context(), id)
+	if err != nil {
+		handleMemoryError(c, err, map[string]interface{}{"memory_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    memory,
+	})
+}
+
+// UpdateMemory godoc
+// @Summary      사용자 기억 수정
+// @Description  현재 사용자의 기억 내용과 유형을 수정. 수정한 내용으로 다시 임베딩됨
+// @Tags         사용자 기억
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                         true  "기억 ID"
+// @Param        request  body      types.UpdateUserMemoryRequest  true  "수정할 정보"
+// @Success      200      {object}  map[string]interface{}         "수정된 기억"
+// @Failure      400      {object}  errors.AppError                "요청 매개변수 오류"
+// @Failure      403      {object}  errors.AppError                "로그인한 사용자가 아님"
+// @Failure      404      {object}  errors.AppError                "기억을 찾을 수 없음"
+// @Security     Bearer
+// @Router       /memories/{id} [put]
+func (h *MemoryHandler) UpdateMemory(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	var req types.UpdateUserMemoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	memory, err := h.memoryService.UpdateMemory(c.Request.Context(), id, &req)
+	if err != nil {
+		handleMemoryError(c, err, map[string]interface{}{"memory_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    memory,
+	})
+}
+
+// DeleteMemory godoc
+// @Summary      사용자 기억 삭제
+// @Description  현재 사용자의 기억 하나를 삭제. 삭제된 기억은 더 이상 프롬프트에 주입되지 않음
+// @Tags         사용자 기억
+// @Produce      json
+// @Param        id   path      string  true  "기억 ID"
+// @Success      200  {object}  map[string]interface{}  "삭제 성공"
+// @Failure      403  {object}  errors.AppError         "로그인한 사용자가 아님"
+// @Failure      404  {object}  errors.AppError         "기억을 찾을 수 없음"
+// @Security     Bearer
+// @Router       /memories/{id} [delete]
+func (h *MemoryHandler) DeleteMemory(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if err := h.memoryService.DeleteMemory(c.Request.Context(), id); err != nil {
+		handleMemoryError(c, err, map[string]interface{}{"memory_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// DeleteAllMemories godoc
+// @Summary      사용자 기억 전체 삭제
+// @Description  현재 사용자의 모든 기억을 삭제하고 삭제된 개수를 반환
+// @Tags         사용자 기억
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "삭제된 기억 개수"
+// @Failure      403  {object}  errors.AppError         "로그인한 사용자가 아님"
+// @Security     Bearer
+// @Router       /memories [delete]
+func (h *MemoryHandler) DeleteAllMemories(c *gin.Context) {
+	deleted, err := h.memoryService.DeleteAllMemories(c.Request.Context())
+	if err != nil {
+		handleMemoryError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"deleted": deleted},
+	})
+}
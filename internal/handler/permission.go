@@ -0,0 +1,434 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionHandler 테넌트 구성원, 사용자 그룹 및 리소스 권한 부여 관련 HTTP 요청 처리
+type PermissionHandler struct {
+	permissionService interfaces.PermissionService
+}
+
+// NewPermissionHandler 새로운 권한 핸들러 생성
+func NewPermissionHandler(permissionService interfaces.PermissionService) *PermissionHandler {
+	return &PermissionHandler{permissionService: permissionService}
+}
+
+// handlePermissionError 서비스 오류를 응답 오류로 변환합니다.
+func handlePermissionError(c *gin.Context, err error, fields map[string]interface{}) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+		return
+	}
+	logger.ErrorWithFields(c.Request.Context(), err, fields)
+	c.Error(errors.NewInternalServerError(err.Error()))
+}
+
+// filterAccessible 요청 주체가 읽기 권한을 가진 리소스만 남깁니다.
+func filterAccessible[T any](ctx context.Context, permissionService interfaces.PermissionService,
+	resourceType types.ResourceType, items []T, id func(T) string,
+) ([]T, error) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, id(item))
+	}
+	allowed, err := permissionService.FilterAccessible(ctx, resourceType, ids, types.PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	if len(allowed) == len(items) {
+		return items, nil
+	}
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, id := range allowed {
+		allowedSet[id] = true
+	}
+	accessible := make([]T, 0, len(allowed))
+	for _, item := range items {
+		if allowedSet[id(item)] {
+			accessible = append(accessible, item)
+		}
+	}
+	return accessible, nil
+}
+
+// ListMembers godoc
+// @Summary      테넌트 구성원 목록 조회
+// @Description  현재 테넌트의 모든 구성원과 역할 조회
+// @Tags         권한
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "구성원 목록"
+// @Security     Bearer
+// @Router       /tenant/members [get]
+func (h *PermissionHandler) ListMembers(c *gin.Context) {
+	members, err := h.permissionService.ListMembers(c.Request.Context())
+	if err != nil {
+		handlePermissionError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    members,
+	})
+}
+
+// CreateMember godoc
+// @Summary      테넌트 구성원 생성
+// @Description  현재 테넌트에 지정한 역할(owner, admin, editor, viewer)의 사용자 계정 생성, 관리자 권한 필요
+// @Tags         권한
+// @Accept       json
+// @Produce      json
+// @Param        request  body      types.CreateMemberRequest  true  "구성원 정보"
+// @Success      200      {object}  map[string]interface{}     "생성된 구성원"
+// @Failure      400      {object}  errors.AppError            "요청 매개변수 오류"
+// @Failure      403      {object}  errors.AppError            "권한 없음"
+// @Failure      409      {object}  errors.AppError            "이메일 또는 사용자 이름 중복"
+// @Security     Bearer
+// @Router       /tenant/members [post]
+func (h *PermissionHandler) CreateMember(c *gin.Context) {
+	var req types.CreateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	member, err := h.permissionService.CreateMember(c.Request.Context(), &req)
+	if err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"username": secutils.SanitizeForLog(req.Username)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    member,
+	})
+}
+
+// UpdateMemberRole godoc
+// @Summary      구성원 역할 변경
+// @Description  테넌트 구성원의 역할 변경, 소유자 역할은 소유자만 지정하거나 변경할 수 있음
+// @Tags         권한
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                         true  "사용자 ID"
+// @Param        request  body      types.UpdateMemberRoleRequest  true  "역할"
+// @Success      200      {object}  map[string]interface{}         "변경된 구성원"
+// @Failure      400      {object}  errors.AppError                "요청 매개변수 오류"
+// @Failure      403      {object}  errors.AppError                "권한 없음"
+// @Failure      404      {object}  errors.AppError                "구성원을 찾을 수 없음"
+// @Security     Bearer
+// @Router       /tenant/members/{user_id}/role [put]
+func (h *PermissionHandler) UpdateMemberRole(c *gin.Context) {
+	userID := secutils.SanitizeForLog(c.Param("user_id"))
+	var req types.UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	member, err := h.permissionService.UpdateMemberRole(c.Request.Context(), userID, req.Role)
+	if err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"user_id": userID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    member,
+	})
+}
+
+// RemoveMember godoc
+// @Summary      테넌트 구성원 삭제
+// @Description  테넌트에서 구성원을 삭제하고 그룹 소속 및 권한 부여를 제거
+// @Tags         권한
+// @Produce      json
+// @Param        user_id  path      string  true  "사용자 ID"
+// @Success      200      {object}  map[string]interface{}  "삭제 성공"
+// @Failure      403      {object}  errors.AppError         "권한 없음"
+// @Failure      404      {object}  errors.AppError         "구성원을 찾을 수 없음"
+// @Security     Bearer
+// @Router       /tenant/members/{user_id} [delete]
+func (h *PermissionHandler) RemoveMember(c *gin.Context) {
+	userID := secutils.SanitizeForLog(c.Param("user_id"))
+	if err := h.permissionService.RemoveMember(c.Request.Context(), userID); err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"user_id": userID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// ListGroups godoc
+// @Summary      사용자 그룹 목록 조회
+// @Description  현재 테넌트의 모든 사용자 그룹 조회
+// @Tags         권한
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "사용자 그룹 목록"
+// @Security     Bearer
+// @Router       /groups [get]
+func (h *PermissionHandler) ListGroups(c *gin.Context) {
+	groups, err := h.permissionService.ListGroups(c.Request.Context())
+	if err != nil {
+		handlePermissionError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    groups,
+	})
+}
+
+// CreateGroup godoc
+// @Summary      사용자 그룹 생성
+// @Description  권한 부여에 사용할 사용자 그룹 생성, 관리자 권한 필요
+// @Tags         권한
+// @Accept       json
+// @Produce      json
+// @Param        request  body      types.UserGroupRequest  true  "사용자 그룹 정보"
+// @Success      200      {object}  map[string]interface{}  "생성된 사용자 그룹"
+// @Failure      400      {object}  errors.AppError         "요청 매개변수 오류"
+// @Security     Bearer
+// @Router       /groups [post]
+func (h *PermissionHandler) CreateGroup(c *gin.Context) {
+	var req types.UserGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	group, err := h.permissionService.CreateGroup(c.Request.Context(), &types.UserGroup{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		handlePermissionError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    group,
+	})
+}
+
+// UpdateGroup godoc
+// @Summary      사용자 그룹 업데이트
+// @Description  사용자 그룹의 이름과 설명 업데이트
+// @Tags         권한
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                  true  "사용자 그룹 ID"
+// @Param        request  body      types.UserGroupRequest  true  "사용자 그룹 정보"
+// @Success      200      {object}  map[string]interface{}  "업데이트된 사용자 그룹"
+// @Failure      404      {object}  errors.AppError         "사용자 그룹을 찾을 수 없음"
+// @Security     Bearer
+// @Router       /groups/{id} [put]
+func (h *PermissionHandler) UpdateGroup(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	var req types.UserGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	group, err := h.permissionService.UpdateGroup(c.Request.Context(), &types.UserGroup{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"group_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    group,
+	})
+}
+
+// DeleteGroup godoc
+// @Summary      사용자 그룹 삭제
+// @Description  사용자 그룹과 그 구성원 및 권한 부여를 삭제
+// @Tags         권한
+// @Produce      json
+// @Param        id   path      string  true  "사용자 그룹 ID"
+// @Success      200  {object}  map[string]interface{}  "삭제 성공"
+// @Failure      404  {object}  errors.AppError         "사용자 그룹을 찾을 수 없음"
+// @Security     Bearer
+// @Router       /groups/{id} [delete]
+func (h *PermissionHandler) DeleteGroup(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if err := h.permissionService.DeleteGroup(c.Request.Context(), id); err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"group_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// ListGroupMembers godoc
+// @Summary      사용자 그룹 구성원 목록 조회
+// @Description  사용자 그룹에 속한 구성원 조회
+// @Tags         권한
+// @Produce      json
+// @Param        id   path      string  true  "사용자 그룹 ID"
+// @Success      200  {object}  map[string]interface{}  "구성원 목록"
+// @Failure      404  {object}  errors.AppError         "사용자 그룹을 찾을 수 없음"
+// @Security     Bearer
+// @Router       /groups/{id}/members [get]
+func (h *PermissionHandler) ListGroupMembers(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	members, err := h.permissionService.ListGroupMembers(c.Request.Context(), id)
+	if err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"group_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    members,
+	})
+}
+
+// AddGroupMember godoc
+// @Summary      사용자 그룹 구성원 추가
+// @Description  테넌트 구성원을 사용자 그룹에 추가
+// @Tags         권한
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                    true  "사용자 그룹 ID"
+// @Param        request  body      types.GroupMemberRequest  true  "구성원"
+// @Success      200      {object}  map[string]interface{}    "추가 성공"
+// @Failure      404      {object}  errors.AppError           "사용자 그룹 또는 구성원을 찾을 수 없음"
+// @Security     Bearer
+// @Router       /groups/{id}/members [post]
+func (h *PermissionHandler) AddGroupMember(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	var req types.GroupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	if err := h.permissionService.AddGroupMember(c.Request.Context(), id, req.UserID); err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"group_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// RemoveGroupMember godoc
+// @Summary      사용자 그룹 구성원 제거
+// @Description  사용자 그룹에서 구성원 제거
+// @Tags         권한
+// @Produce      json
+// @Param        id       path      string  true  "사용자 그룹 ID"
+// @Param        user_id  path      string  true  "사용자 ID"
+// @Success      200      {object}  map[string]interface{}  "제거 성공"
+// @Failure      404      {object}  errors.AppError         "사용자 그룹을 찾을 수 없음"
+// @Security     Bearer
+// @Router       /groups/{id}/members/{user_id} [delete]
+func (h *PermissionHandler) RemoveGroupMember(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	userID := secutils.SanitizeForLog(c.Param("user_id"))
+	if err := h.permissionService.RemoveGroupMember(c.Request.Context(), id, userID); err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"group_id": id, "user_id": userID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// ListGrants godoc
+// @Summary      리소스 권한 부여 목록 조회
+// @Description  지식베이스, 에이전트 또는 MCP 서비스의 권한 부여 조회, 리소스 관리 권한 필요
+// @Tags         권한
+// @Produce      json
+// @Param        resource_type  query     string  true  "리소스 유형 (knowledge_base, agent, mcp_service)"
+// @Param        resource_id    query     string  true  "리소스 ID"
+// @Success      200            {object}  map[string]interface{}  "권한 부여 목록"
+// @Failure      400            {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403            {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Router       /grants [get]
+func (h *PermissionHandler) ListGrants(c *gin.Context) {
+	resourceType := types.ResourceType(c.Query("resource_type"))
+	resourceID := secutils.SanitizeForLog(c.Query("resource_id"))
+	if resourceID == "" {
+		c.Error(errors.NewBadRequestError("resource_id cannot be empty"))
+		return
+	}
+	grants, err := h.permissionService.ListGrants(c.Request.Context(), resourceType, resourceID)
+	if err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"resource_id": resourceID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    grants,
+	})
+}
+
+// SetGrant godoc
+// @Summary      리소스 권한 부여 설정
+// @Description  사용자 또는 사용자 그룹에 리소스 권한(read, write, manage)을 부여, 같은 주체에 대한 기존 권한은 변경됨.
+// @Description  권한 부여가 있는 리소스는 관리자와 권한을 부여받은 주체만 접근할 수 있음
+// @Tags         권한
+// @Accept       json
+// @Produce      json
+// @Param        request  body      types.SetGrantRequest   true  "권한 부여"
+// @Success      200      {object}  map[string]interface{}  "설정된 권한 부여"
+// @Failure      400      {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403      {object}  errors.AppError         "권한 없음"
+// @Failure      404      {object}  errors.AppError         "리소스 또는 주체를 찾을 수 없음"
+// @Security     Bearer
+// @Router       /grants [post]
+func (h *PermissionHandler) SetGrant(c *gin.Context) {
+	var req types.SetGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	grant, err := h.permissionService.SetGrant(c.Request.Context(), &types.ResourceGrant{
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		SubjectType:  req.SubjectType,
+		SubjectID:    req.SubjectID,
+		Permission:   req.Permission,
+	})
+	if err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"resource_id": secutils.SanitizeForLog(req.ResourceID)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    grant,
+	})
+}
+
+// DeleteGrant godoc
+// @Summary      리소스 권한 부여 삭제
+// @Description  권한 부여 삭제, 리소스의 마지막 권한 부여가 삭제되면 리소스는 다시 역할 기반 접근으로 돌아감
+// @Tags         권한
+// @Produce      json
+// @Param        id   path      string  true  "권한 부여 ID"
+// @Success      200  {object}  map[string]interface{}  "삭제 성공"
+// @Failure      403  {object}  errors.AppError         "권한 없음"
+// @Failure      404  {object}  errors.AppError         "권한 부여를 찾을 수 없음"
+// @Security     Bearer
+// @Router       /grants/{id} [delete]
+func (h *PermissionHandler) DeleteGrant(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if err := h.permissionService.DeleteGrant(c.Request.Context(), id); err != nil {
+		handlePermissionError(c, err, map[string]interface{}{"grant_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
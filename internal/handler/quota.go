@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Tencent/WeKnora/internal/config"
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaHandler 할당량 HTTP 요청 처리
+type QuotaHandler struct {
+	quotaService interfaces.QuotaService
+	userService  interfaces.UserService
+	config       *config.Config
+}
+
+// NewQuotaHandler 새로운 할당량 핸들러 생성
+func NewQuotaHandler(
+	quotaService interfaces.QuotaService,
+	userService interfaces.UserService,
+	config *config.Config,
+) *QuotaHandler {
+	return &QuotaHandler{quotaService: quotaService, userService: userService, config: config}
+}
+
+// handleQuotaError 서비스 오류를 응답 오류로 변환
+func handleQuotaError(c *gin.Context, err error) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+		return
+	}
+	logger.ErrorWithFields(c.Request.Context(), err, nil)
+	c.Error(errors.NewInternalServerError(err.Error()))
+}
+
+// canManageTenantQuota 테넌트 전체 할당량은 테넌트 간 접근 권한이 있는 사용자만 변경할 수 있음,
+// 테넌트 관리자가 자신의 제한을 올릴 수 없도록 함
+func (h *QuotaHandler) canManageTenantQuota(c *gin.Context) bool {
+	if h.config == nil || h.config.Tenant == nil || !h.config.Tenant.EnableCrossTenantAccess {
+		return false
+	}
+	user, err := h.userService.GetCurrentUser(c.Request.Context())
+	return err == nil && user != nil && user.CanAccessAllTenants
+}
+
+// GetQuotaStatus godoc
+// @Summary      할당량 조회
+// @Description  요청 주체에 적용되는 테넌트, 사용자 또는 API 키별 제한과 현재 사용량 조회. 0은 제한 없음
+// @Tags         할당량
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "대상별 제한과 사용량"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /quotas [get]
+func (h *QuotaHandler) GetQuotaStatus(c *gin.Context) {
+	statuses, err := h.quotaService.GetQuotaStatus(c.Request.Context())
+	if err != nil {
+		handleQuotaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    statuses,
+	})
+}
+
+// ListQuotaPolicies godoc
+// @Summary      할당량 정책 목록 조회
+// @Description  현재 테넌트에 설정된 대상별 할당량 정책 조회. 정책이 없는 대상은 설정 파일의 기본 제한이 적용됨. 관리자 권한 필요
+// @Tags         할당량
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "할당량 정책 목록"
+// @Failure      403  {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /quotas/policies [get]
+func (h *QuotaHandler) ListQuotaPolicies(c *gin.Context) {
+	policies, err := h.quotaService.ListQuotaPolicies(c.Request.Context())
+	if err != nil {
+		handleQuotaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policies,
+	})
+}
+
+// SetQuotaPolicy godoc
+// @Summary      할당량 정책 설정
+// @Description  테넌트, 사용자 또는 API 키의 제한을 설정. 설정한 값이 기본 제한을 모두 대신하며 0은 제한 없음.
+// @Description  사용자와 API 키 정책은 관리자가, 테넌트 정책은 테넌트 간 접근 권한이 있는 사용자만 설정 가능
+// @Tags         할당량
+// @Accept       json
+// @Produce      json
+// @Param        request  body      types.SetQuotaPolicyRequest  true  "할당량 정책"
+// @Success      200      {object}  map[string]interface{}       "설정된 정책"
+// @Failure      400      {object}  errors.AppError              "요청 매개변수 오류"
+// @Failure      403      {object}  errors.AppError              "권한 없음"
+// @Failure      404      {object}  errors.AppError              "대상을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /quotas/policies [put]
+func (h *QuotaHandler) SetQuotaPolicy(c *gin.Context) {
+	var req types.SetQuotaPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	if req.SubjectType == types.QuotaSubjectTenant && !h.canManageTenantQuota(c) {
+		c.Error(errors.NewForbiddenError("테넌트 할당량은 테넌트 간 접근 권한이 있는 사용자만 변경할 수 있습니다"))
+		return
+	}
+
+	policy, err := h.quotaService.SetQuotaPolicy(c.Request.Context(), &req)
+	if err != nil {
+		handleQuotaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+// DeleteQuotaPolicy godoc
+// @Summary      할당량 정책 삭제
+// @Description  대상의 할당량 정책을 삭제하여 기본 제한을 다시 적용. 테넌트 정책은 테넌트 간 접근 권한이 있는 사용자만 삭제 가능
+// @Tags         할당량
+// @Produce      json
+// @Param        subject_type  query     string  true   "대상 유형 (tenant, user, api_key)"
+// @Param        subject_id    query     string  false  "대상 ID, 테넌트 대상이면 생략"
+// @Success      200           {object}  map[string]interface{}  "삭제 성공"
+// @Failure      400           {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403           {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /quotas/policies [delete]
+func (h *QuotaHandler) DeleteQuotaPolicy(c *gin.Context) {
+	subjectType := types.QuotaSubjectType(c.Query("subject_type"))
+	if subjectType == types.QuotaSubjectTenant && !h.canManageTenantQuota(c) {
+		c.Error(errors.NewForbiddenError("테넌트 할당량은 테넌트 간 접근 권한이 있는 사용자만 변경할 수 있습니다"))
+		return
+	}
+
+	if err := h.quotaService.DeleteQuotaPolicy(c.Request.Context(), subjectType, c.Query("subject_id")); err != nil {
+		handleQuotaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
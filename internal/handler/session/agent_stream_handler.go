@@ -60,9 +60,11 @@ func (h *AgentStreamHandler) Subscribe() {
 	h.eventBus.On(event.EventAgentThought, h.handleThought)
 	h.eventBus.On(event.EventAgentToolCall, h.handleToolCall)
 	h.eventBus.On(event.EventAgentToolResult, h.handleToolResult)
+	h.eventBus.On(event.EventAgentToolApproval, h.handleToolApproval)
 	h.eventBus.On(event.EventAgentReferences, h.handleReferences)
 	h.eventBus.On(event.EventAgentFinalAnswer, h.handleFinalAnswer)
 	h.eventBus.On(event.EventAgentReflection, h.handleReflection)
+	h.eventBus.On(event.EventAgentSubAgent, h.handleSubAgent)
 	h.eventBus.On(event.EventError, h.handleError)
 	h.eventBus.On(event.EventSessionTitle, h.handleSessionTitle)
 	h.eventBus.On(event.EventAgentComplete, h.handleComplete)
@@ -210,6 +212,127 @@ func (h *AgentStreamHandler) handleToolResult(ctx context.Context, evt event.Eve
 	return nil
 }
 
+// handleToolApproval 도구 호출 승인 요청 및 결정 이벤트 처리
+// 승인 요청은 done=false로, 결정되면 같은 이벤트 ID로 done=true를 전송합니다.
+func (h *AgentStreamHandler) handleToolApproval(ctx context.Context, evt event.Event) error {
+	data, ok := evt.Data.(event.AgentToolApprovalData)
+	if !ok {
+		return nil
+	}
+
+	metadata := map[string]interface{}{
+		"approval_id":  data.ApprovalID,
+		"tool_call_id": data.ToolCallID,
+		"tool_name":    data.ToolName,
+		"arguments":    data.Arguments,
+		"status":       data.Status,
+		"comment":      data.Comment,
+		"expires_at":   data.ExpiresAt.Unix(),
+	}
+
+	content := fmt.Sprintf("Waiting for approval: %s", data.ToolName)
+	pending := data.Status == string(types.ToolApprovalPending)
+	if !pending {
+		content = fmt.Sprintf("Tool call %s: %s", data.Status, data.ToolName)
+	}
+
+	// 이벤트를 스트림에 추가
+	if err := h.streamManager.AppendEvent(h.ctx, h.sessionID, h.assistantMessageID, interfaces.StreamEvent{
+		ID:        evt.ID,
+		Type:      types.ResponseTypeToolApproval,
+		Content:   content,
+		Done:      !pending,
+		Timestamp: time.Now(),
+		Data:      metadata,
+	}); err != nil {
+		logger.GetLogger(h.ctx).Error("Append tool approval event to stream failed", "error", err)
+	}
+
+	return nil
+}
+
+// handleSubAgent 위임받은 하위 에이전트의 이벤트 처리
+// 하위 에이전트 이벤트는 sub_agent 유형으로 전송되고 원래 응답 유형은 data.event_type에 담깁니다.
+// 하위 에이전트의 답변은 위임한 에이전트의 도구 결과가 되므로 어시스턴트 메시지에 누적하지 않습니다.
+func (h *AgentStreamHandler) handleSubAgent(ctx context.Context, evt event.Event) error {
+	data, ok := evt.Data.(event.AgentSubAgentData)
+	if !ok {
+		return nil
+	}
+
+	metadata := map[string]interface{}{
+		"tool_call_id": data.ToolCallID,
+		"agent_id":     data.AgentID,
+		"agent_name":   data.AgentName,
+		"depth":        data.Depth,
+		"event_id":     data.Event.ID,
+	}
+
+	var eventType types.ResponseType
+	var content string
+	done := false
+	switch inner := data.Event.Data.(type) {
+	case event.AgentThoughtData:
+		eventType, content, done = types.ResponseTypeThinking, inner.Content, inner.Done
+	case event.AgentToolCallData:
+		eventType = types.ResponseTypeToolCall
+		content = fmt.Sprintf("Calling tool: %s", inner.ToolName)
+		metadata["child_tool_call_id"] = inner.ToolCallID
+		metadata["tool_name"] = inner.ToolName
+		metadata["arguments"] = inner.Arguments
+	case event.AgentToolResultData:
+		eventType, content = types.ResponseTypeToolResult, inner.Output
+		if !inner.Success {
+			eventType, content = types.ResponseTypeError, inner.Error
+		}
+		metadata["child_tool_call_id"] = inner.ToolCallID
+		metadata["tool_name"] = inner.ToolName
+		metadata["success"] = inner.Success
+		metadata["output"] = inner.Output
+		metadata["error"] = inner.Error
+		metadata["duration_ms"] = inner.Duration
+		metadata["tool_data"] = inner.Data
+	case event.AgentToolApprovalData:
+		eventType = types.ResponseTypeToolApproval
+		content = fmt.Sprintf("Waiting for approval: %s", inner.ToolName)
+		done = inner.Status != string(types.ToolApprovalPending)
+		if done {
+			content = fmt.Sprintf("Tool call %s: %s", inner.Status, inner.ToolName)
+		}
+		metadata["approval_id"] = inner.ApprovalID
+		metadata["child_tool_call_id"] = inner.ToolCallID
+		metadata["tool_name"] = inner.ToolName
+		metadata["arguments"] = inner.Arguments
+		metadata["status"] = inner.Status
+		metadata["comment"] = inner.Comment
+		metadata["expires_at"] = inner.ExpiresAt.Unix()
+	case event.AgentReflectionData:
+		eventType, content, done = types.ResponseTypeReflection, inner.Content, inner.Done
+	case event.AgentFinalAnswerData:
+		eventType, content, done = types.ResponseTypeAnswer, inner.Content, inner.Done
+	case event.ErrorData:
+		eventType, content, done = types.ResponseTypeError, inner.Error, true
+		metadata["stage"] = inner.Stage
+	default:
+		return nil
+	}
+	metadata["event_type"] = eventType
+
+	// 이벤트를 스트림에 추가 (프론트엔드에서 이벤트 ID별로 누적)
+	if err := h.streamManager.AppendEvent(h.ctx, h.sessionID, h.assistantMessageID, interfaces.StreamEvent{
+		ID:        evt.ID,
+		Type:      types.ResponseTypeSubAgent,
+		Content:   content,
+		Done:      done,
+		Timestamp: time.Now(),
+		Data:      metadata,
+	}); err != nil {
+		logger.GetLogger(h.ctx).Error("Append sub-agent event to stream failed", "error", err)
+	}
+
+	return nil
+}
+
 // handleReferences 지식 참조 이벤트 처리
 func (h *AgentStreamHandler) handleReferences(ctx context.Context, evt event.Event) error {
 	data, ok := evt.Data.(event.AgentReferencesData)
@@ -20,6 +20,8 @@ type Handler struct {
 	config               *config.Config                  // 애플리케이션 구성
 	knowledgebaseService interfaces.KnowledgeBaseService // 지식베이스 관리 서비스
 	customAgentService   interfaces.CustomAgentService   // 사용자 정의 에이전트 관리 서비스
+	permissionService    interfaces.PermissionService    // 리소스 권한 검사 서비스
+	agentRunService      interfaces.AgentRunService      // 에이전트 실행 체크포인트 서비스
 }
 
 // NewHandler 필요한 모든 종속성을 가진 Handler의 새 인스턴스를 생성합니다.
@@ -30,6 +32,8 @@ func NewHandler(
 	config *config.Config,
 	knowledgebaseService interfaces.KnowledgeBaseService,
 	customAgentService interfaces.CustomAgentService,
+	permissionService interfaces.PermissionService,
+	agentRunService interfaces.AgentRunService,
 ) *Handler {
 	return &Handler{
 		sessionService:       sessionService,
@@ -38,6 +42,8 @@ func NewHandler(
 		config:               config,
 		knowledgebaseService: knowledgebaseService,
 		customAgentService:   customAgentService,
+		permissionService:    permissionService,
+		agentRunService:      agentRunService,
 	}
 }
 
@@ -106,6 +106,23 @@ func sendCompletionEvent(c *gin.Context, requestID string) {
 	c.Writer.Flush()
 }
 
+// sendPausedEvent 에이전트 실행이 일시 중지되었음을 알리는 최종 이벤트 전송
+func sendPausedEvent(c *gin.Context, requestID string) {
+	c.SSEvent("message", &types.StreamResponse{
+		ID:           requestID,
+		ResponseType: types.ResponseTypePaused,
+		Content:      "Generation paused",
+		Done:         true,
+	})
+	c.Writer.Flush()
+}
+
+// isAgentRunSuspended 메시지의 에이전트 실행이 재개를 기다리는 상태인지 확인
+func (h *Handler) isAgentRunSuspended(ctx context.Context, sessionID, messageID string) bool {
+	run, err := h.agentRunService.GetRun(ctx, sessionID, messageID)
+	return err == nil && run.Status.IsResumable()
+}
+
 // createAgentQueryEvent 표준 에이전트 쿼리 이벤트 생성
 func createAgentQueryEvent(sessionID, assistantMessageID string) interfaces.StreamEvent {
 	return interfaces.StreamEvent{
@@ -166,6 +183,10 @@ func (h *Handler) setupStopEventHandler(
 	eventBus.On(event.EventStop, func(ctx context.Context, evt event.Event) error {
 		logger.Infof(ctx, "Received stop event, cancelling async operations for session: %s", sessionID)
 		cancel()
+		// 일시 중지된 에이전트 실행은 나중에 재개하므로 메시지를 완료하지 않음
+		if data, ok := evt.Data.(event.StopData); ok && data.Reason == stopReasonPaused {
+			return nil
+		}
 		assistantMessage.Content = "사용자가 대화를 중지했습니다"
 		h.completeAssistantMessage(ctx, assistantMessage)
 		return nil
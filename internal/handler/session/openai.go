@@ -0,0 +1,556 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/event"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// openAISessionTitleLength 새 세션 제목으로 사용할 질문의 최대 글자 수
+const openAISessionTitleLength = 50
+
+// openAIDelta 에이전트 이벤트에서 변환한 응답 증분
+type openAIDelta struct {
+	content    string
+	reasoning  string
+	references []*types.SearchResult
+	toolCall   *types.OpenAIToolActivity
+	err        string
+	done       bool
+}
+
+// openAIEventCollector 요청 전용 EventBus의 이벤트를 OpenAI 응답 증분으로 변환합니다.
+// 이벤트는 QA 고루틴에서 방출되므로 채널을 통해 응답을 쓰는 핸들러 고루틴으로 전달합니다.
+type openAIEventCollector struct {
+	ctx       context.Context
+	agentMode bool
+	deltas    chan openAIDelta
+
+	mu         sync.Mutex
+	finished   bool
+	toolCalls  map[string]*types.OpenAIToolActivity
+	references map[string]bool
+}
+
+// newOpenAIEventCollector 새 이벤트 수집기 생성 및 구독
+func newOpenAIEventCollector(ctx context.Context, eventBus *event.EventBus, agentMode bool) *openAIEventCollector {
+	collector := &openAIEventCollector{
+		ctx:        ctx,
+		agentMode:  agentMode,
+		deltas:     make(chan openAIDelta, 64),
+		toolCalls:  make(map[string]*types.OpenAIToolActivity),
+		references: make(map[string]bool),
+	}
+	eventBus.On(event.EventAgentThought, collector.handleThought)
+	eventBus.On(event.EventAgentToolCall, collector.handleToolCall)
+	eventBus.On(event.EventAgentToolResult, collector.handleToolResult)
+	eventBus.On(event.EventAgentReferences, collector.handleReferences)
+	eventBus.On(event.EventAgentFinalAnswer, collector.handleFinalAnswer)
+	eventBus.On(event.EventAgentComplete, collector.handleComplete)
+	eventBus.On(event.EventError, collector.handleError)
+	return collector
+}
+
+// send 증분을 핸들러로 전달, 완료 또는 오류 이후의 증분은 버림
+func (s *openAIEventCollector) send(delta openAIDelta) {
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	if delta.done || delta.err != "" {
+		s.finished = true
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.deltas <- delta:
+	case <-s.ctx.Done():
+	}
+}
+
+// handleThought 사고 과정을 reasoning_content로 전달
+func (s *openAIEventCollector) handleThought(ctx context.Context, evt event.Event) error {
+	if data, ok := evt.Data.(event.AgentThoughtData); ok && data.Content != "" {
+		s.send(openAIDelta{reasoning: data.Content})
+	}
+	return nil
+}
+
+// handleToolCall 도구 호출 시작 전달
+func (s *openAIEventCollector) handleToolCall(ctx context.Context, evt event.Event) error {
+	data, ok := evt.Data.(event.AgentToolCallData)
+	if !ok {
+		return nil
+	}
+	activity := &types.OpenAIToolActivity{
+		ID:        data.ToolCallID,
+		Name:      data.ToolName,
+		Arguments: data.Arguments,
+		Status:    types.OpenAIToolStatusRunning,
+	}
+	s.mu.Lock()
+	s.toolCalls[data.ToolCallID] = activity
+	s.mu.Unlock()
+
+	call := *activity
+	s.send(openAIDelta{toolCall: &call})
+	return nil
+}
+
+// handleToolResult 도구 실행 결과 전달
+func (s *openAIEventCollector) handleToolResult(ctx context.Context, evt event.Event) error {
+	data, ok := evt.Data.(event.AgentToolResultData)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	activity, exists := s.toolCalls[data.ToolCallID]
+	if !exists {
+		activity = &types.OpenAIToolActivity{ID: data.ToolCallID, Name: data.ToolName}
+		s.toolCalls[data.ToolCallID] = activity
+	}
+	activity.Status = types.OpenAIToolStatusSuccess
+	if !data.Success {
+		activity.Status = types.OpenAIToolStatusFailed
+	}
+	activity.Output = data.Output
+	activity.Error = data.Error
+	activity.DurationMs = data.Duration
+	call := *activity
+	s.mu.Unlock()
+
+	s.send(openAIDelta{toolCall: &call})
+	return nil
+}
+
+// handleReferences 아직 전달하지 않은 지식 참조만 전달
+func (s *openAIEventCollector) handleReferences(ctx context.Context, evt event.Event) error {
+	data, ok := evt.Data.(event.AgentReferencesData)
+	if !ok {
+		return nil
+	}
+	var results []*types.SearchResult
+	switch refs := data.References.(type) {
+	case []*types.SearchResult:
+		results = refs
+	case []interface{}:
+		for _, ref := range refs {
+			if result, ok := ref.(*types.SearchResult); ok {
+				results = append(results, result)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	fresh := make([]*types.SearchResult, 0, len(results))
+	for _, result := range results {
+		if result == nil || s.references[result.ID] {
+			continue
+		}
+		s.references[result.ID] = true
+		fresh = append(fresh, result)
+	}
+	s.mu.Unlock()
+
+	if len(fresh) > 0 {
+		s.send(openAIDelta{references: fresh})
+	}
+	return nil
+}
+
+// handleFinalAnswer 답변 청크 전달, 일반 모드는 마지막 청크에서 완료
+func (s *openAIEventCollector) handleFinalAnswer(ctx context.Context, evt event.Event) error {
+	data, ok := evt.Data.(event.AgentFinalAnswerData)
+	if !ok {
+		return nil
+	}
+	if data.Content != "" {
+		s.send(openAIDelta{content: data.Content})
+	}
+	if data.Done && !s.agentMode {
+		s.send(openAIDelta{done: true})
+	}
+	return nil
+}
+
+// handleComplete 에이전트 모드 완료
+func (s *openAIEventCollector) handleComplete(ctx context.Context, evt event.Event) error {
+	s.send(openAIDelta{done: true})
+	return nil
+}
+
+// handleError 실행 오류 전달
+func (s *openAIEventCollector) handleError(ctx context.Context, evt event.Event) error {
+	data, ok := evt.Data.(event.ErrorData)
+	if !ok {
+		return nil
+	}
+	s.send(openAIDelta{err: data.Error})
+	return nil
+}
+
+// splitOpenAIMessages 마지막 사용자 메시지를 질문으로, 그 앞의 사용자와 어시스턴트 메시지를 이전 대화로 나눕니다.
+// 연속된 사용자 메시지는 하나로 합치고, 시스템과 도구 메시지는 에이전트 설정을 따르므로 무시합니다.
+func splitOpenAIMessages(messages []types.OpenAIChatMessage) (string, []*types.History) {
+	var history []*types.History
+	var pending []string
+	for _, message := range messages {
+		content := strings.TrimSpace(string(message.Content))
+		if content == "" {
+			continue
+		}
+		switch message.Role {
+		case "user":
+			pending = append(pending, content)
+		case "assistant":
+			if len(pending) > 0 {
+				history = append(history, &types.History{Query: strings.Join(pending, "\n"), Answer: content})
+				pending = nil
+			}
+		}
+	}
+	return strings.Join(pending, "\n"), history
+}
+
+// resolveOpenAISession 헤더로 지정된 세션을 가져오거나, 없으면 새 세션을 만들고 요청의 이전 대화를 가져옵니다.
+func (h *Handler) resolveOpenAISession(ctx context.Context, c *gin.Context,
+	query string, history []*types.History,
+) (*types.Session, error) {
+	if sessionID := secutils.SanitizeForLog(c.GetHeader(types.OpenAISessionIDHeader)); sessionID != "" {
+		// 기존 세션은 서버에 저장된 대화 기록을 사용
+		session, err := h.sessionService.GetSession(ctx, sessionID)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to get session, session ID: %s, error: %v", sessionID, err)
+			return nil, errors.NewNotFoundError("Session not found")
+		}
+		return session, nil
+	}
+
+	title := []rune(query)
+	if len(title) > openAISessionTitleLength {
+		title = title[:openAISessionTitleLength]
+	}
+	session, err := h.sessionService.CreateSession(ctx, &types.Session{
+		TenantID: c.GetUint64(types.TenantIDContextKey.String()),
+		Title:    string(title),
+	})
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		return nil, errors.NewInternalServerError(err.Error())
+	}
+	if err := h.sessionService.ImportHistory(ctx, session.ID, history); err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		return nil, errors.NewInternalServerError(err.Error())
+	}
+	return session, nil
+}
+
+// ChatCompletions godoc
+// @Summary      OpenAI 호환 대화 완성
+// @Description  model에 지정한 사용자 정의 에이전트 또는 내장 에이전트로 답변. 에이전트 모드이면 AgentEngine을, 아니면 지식베이스 질의응답 파이프라인을 실행
+// @Description  stream이 true이면 OpenAI 형식의 SSE 청크로 응답. 지식 참조와 서버에서 실행된 도구 호출은 weknora 확장 필드로 전달
+// @Description  X-Session-ID 헤더로 세션을 지정하면 서버의 대화 기록을 사용하고, 없으면 새 세션을 만들어 요청의 이전 메시지를 기록으로 사용. 사용한 세션 ID는 X-Session-ID 응답 헤더로 반환
+// @Tags         OpenAI 호환
+// @Accept       json
+// @Produce      json
+// @Produce      text/event-stream
+// @Param        X-Session-ID  header    string                             false  "세션 ID"
+// @Param        request       body      types.OpenAIChatCompletionRequest  true   "대화 완성 요청"
+// @Success      200           {object}  types.OpenAIChatCompletion         "대화 완성 응답"
+// @Failure      400           {object}  errors.AppError                    "요청 매개변수 오류"
+// @Failure      404           {object}  errors.AppError                    "모델 또는 세션을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /chat/completions [post]
+func (h *Handler) ChatCompletions(c *gin.Context) {
+	ctx := logger.CloneContext(c.Request.Context())
+
+	var request types.OpenAIChatCompletionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	query, history := splitOpenAIMessages(request.Messages)
+	if query == "" {
+		c.Error(errors.NewBadRequestError("messages must end with a user message"))
+		return
+	}
+	query = secutils.SanitizeForLog(query)
+	model := secutils.SanitizeForLog(request.Model)
+
+	// model은 에이전트 ID
+	if err := h.permissionService.CheckPermission(
+		ctx, types.ResourceTypeAgent, model, types.PermissionRead,
+	); err != nil {
+		c.Error(err)
+		return
+	}
+	customAgent, err := h.customAgentService.GetAgentByID(ctx, model)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to get custom agent for model %s: %v", model, err)
+		c.Error(errors.NewNotFoundError(fmt.Sprintf("The model '%s' does not exist", model)))
+		return
+	}
+	agentMode := customAgent.IsAgentMode()
+
+	session, err := h.resolveOpenAISession(ctx, c, query, history)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header(types.OpenAISessionIDHeader, session.ID)
+	logger.Infof(ctx, "[OpenAI] Chat completion, session ID: %s, model: %s, agent mode: %v, stream: %v",
+		session.ID, model, agentMode, request.Stream)
+
+	// 사용자 메시지와 어시스턴트 메시지 생성
+	requestID := getRequestID(c)
+	if err := h.createUserMessage(ctx, session.ID, query, requestID, nil); err != nil {
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+	assistantMessage, err := h.createAssistantMessage(ctx, &types.Message{
+		SessionID: session.ID,
+		Role:      "assistant",
+		RequestID: requestID,
+	})
+	if err != nil {
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+
+	// 클라이언트 연결이 끊기면 실행도 취소
+	eventBus := event.NewEventBus()
+	asyncCtx, cancel := context.WithCancel(logger.CloneContext(c.Request.Context()))
+	defer cancel()
+	collector := newOpenAIEventCollector(asyncCtx, eventBus, agentMode)
+	go h.runOpenAIQA(asyncCtx, session, query, assistantMessage.ID, customAgent, agentMode, eventBus)
+
+	completion := &types.OpenAIChatCompletion{
+		ID:      "chatcmpl-" + assistantMessage.ID,
+		Created: time.Now().Unix(),
+		Model:   model,
+	}
+	extension := &types.OpenAIExtension{SessionID: session.ID, MessageID: assistantMessage.ID}
+	if request.Stream {
+		h.streamOpenAICompletion(ctx, c, completion, extension, collector, assistantMessage)
+		return
+	}
+	h.writeOpenAICompletion(ctx, c, completion, extension, collector, assistantMessage)
+}
+
+// runOpenAIQA 에이전트 모드에 따라 에이전트 또는 지식베이스 질의응답을 실행
+func (h *Handler) runOpenAIQA(ctx context.Context, session *types.Session, query, assistantMessageID string,
+	customAgent *types.CustomAgent, agentMode bool, eventBus *event.EventBus,
+) {
+	defer func() {
+		if r := recover(); r != nil {
+			buf := make([]byte, 10240)
+			runtime.Stack(buf, true)
+			logger.ErrorWithFields(ctx,
+				errors.NewInternalServerError(fmt.Sprintf("OpenAI chat completion panicked: %v\n%s", r, string(buf))), nil)
+			eventBus.Emit(ctx, event.Event{
+				Type:      event.EventError,
+				SessionID: session.ID,
+				Data:      event.ErrorData{Error: "internal error", Stage: "openai_chat_completion", SessionID: session.ID},
+			})
+		}
+	}()
+
+	var err error
+	if agentMode {
+		err = h.sessionService.AgentQA(ctx, session, query, assistantMessageID, "", eventBus, customAgent, nil, nil)
+	} else {
+		err = h.sessionService.KnowledgeQA(ctx, session, query, nil, nil, assistantMessageID, "",
+			customAgent.Config.WebSearchEnabled, eventBus, customAgent)
+	}
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		eventBus.Emit(ctx, event.Event{
+			Type:      event.EventError,
+			SessionID: session.ID,
+			Data:      event.ErrorData{Error: err.Error(), Stage: "openai_chat_completion", SessionID: session.ID},
+		})
+	}
+}
+
+// finishOpenAIMessage 응답한 내용과 참조로 어시스턴트 메시지를 완료
+func (h *Handler) finishOpenAIMessage(ctx context.Context, assistantMessage *types.Message,
+	content string, references []*types.SearchResult,
+) {
+	assistantMessage.Content = content
+	assistantMessage.KnowledgeReferences = references
+	h.completeAssistantMessage(ctx, assistantMessage)
+}
+
+// streamOpenAICompletion 증분을 OpenAI 형식의 SSE 청크로 전송
+func (h *Handler) streamOpenAICompletion(ctx context.Context, c *gin.Context,
+	completion *types.OpenAIChatCompletion, extension *types.OpenAIExtension,
+	collector *openAIEventCollector, assistantMessage *types.Message,
+) {
+	setSSEHeaders(c)
+	c.Status(http.StatusOK)
+	completion.Object = types.OpenAIObjectChatCompletionChunk
+
+	var content strings.Builder
+	var references []*types.SearchResult
+	defer func() {
+		h.finishOpenAIMessage(ctx, assistantMessage, content.String(), references)
+	}()
+
+	writeChunk := func(delta types.OpenAIChatMessageDelta, ext *types.OpenAIExtension, finishReason *string) {
+		chunk := *completion
+		chunk.Choices = []types.OpenAIChatCompletionChoice{{Delta: &delta, FinishReason: finishReason}}
+		chunk.WeKnora = ext
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		c.Writer.Flush()
+	}
+
+	// 첫 청크로 역할과 세션 정보 전송
+	writeChunk(types.OpenAIChatMessageDelta{Role: "assistant"}, extension, nil)
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			logger.Infof(ctx, "[OpenAI] Client disconnected, session ID: %s", extension.SessionID)
+			return
+		case delta := <-collector.deltas:
+			switch {
+			case delta.err != "":
+				data, _ := json.Marshal(gin.H{"error": gin.H{"message": delta.err, "type": "server_error"}})
+				fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+				c.Writer.Flush()
+				return
+			case delta.done:
+				stop := types.OpenAIFinishReasonStop
+				writeChunk(types.OpenAIChatMessageDelta{}, nil, &stop)
+				fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+				c.Writer.Flush()
+				return
+			case delta.references != nil:
+				references = append(references, delta.references...)
+				writeChunk(types.OpenAIChatMessageDelta{}, &types.OpenAIExtension{References: delta.references}, nil)
+			case delta.toolCall != nil:
+				writeChunk(types.OpenAIChatMessageDelta{},
+					&types.OpenAIExtension{ToolCalls: []types.OpenAIToolActivity{*delta.toolCall}}, nil)
+			default:
+				content.WriteString(delta.content)
+				writeChunk(types.OpenAIChatMessageDelta{Content: delta.content, ReasoningContent: delta.reasoning}, nil, nil)
+			}
+		}
+	}
+}
+
+// writeOpenAICompletion 실행이 끝날 때까지 증분을 모아 하나의 응답으로 반환
+func (h *Handler) writeOpenAICompletion(ctx context.Context, c *gin.Context,
+	completion *types.OpenAIChatCompletion, extension *types.OpenAIExtension,
+	collector *openAIEventCollector, assistantMessage *types.Message,
+) {
+	var content, reasoning strings.Builder
+	toolCalls := make(map[string]int)
+	defer func() {
+		h.finishOpenAIMessage(ctx, assistantMessage, content.String(), extension.References)
+	}()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			logger.Infof(ctx, "[OpenAI] Client disconnected, session ID: %s", extension.SessionID)
+			return
+		case delta := <-collector.deltas:
+			switch {
+			case delta.err != "":
+				c.Error(errors.NewInternalServerError(delta.err))
+				return
+			case delta.done:
+				stop := types.OpenAIFinishReasonStop
+				completion.Object = types.OpenAIObjectChatCompletion
+				completion.Choices = []types.OpenAIChatCompletionChoice{{
+					Message: &types.OpenAIChatResponseMessage{
+						Role:             "assistant",
+						Content:          content.String(),
+						ReasoningContent: reasoning.String(),
+					},
+					FinishReason: &stop,
+				}}
+				completion.WeKnora = extension
+				c.JSON(http.StatusOK, completion)
+				return
+			case delta.references != nil:
+				extension.References = append(extension.References, delta.references...)
+			case delta.toolCall != nil:
+				// 같은 호출의 시작과 결과는 하나로 합침
+				if index, ok := toolCalls[delta.toolCall.ID]; ok {
+					extension.ToolCalls[index] = *delta.toolCall
+				} else {
+					toolCalls[delta.toolCall.ID] = len(extension.ToolCalls)
+					extension.ToolCalls = append(extension.ToolCalls, *delta.toolCall)
+				}
+			default:
+				content.WriteString(delta.content)
+				reasoning.WriteString(delta.reasoning)
+			}
+		}
+	}
+}
+
+// ListModels godoc
+// @Summary      OpenAI 호환 모델 목록
+// @Description  대화 완성의 model로 사용할 수 있는, 현재 사용자가 읽을 수 있는 에이전트 목록
+// @Tags         OpenAI 호환
+// @Produce      json
+// @Success      200  {object}  types.OpenAIModelList  "모델 목록"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /models [get]
+func (h *Handler) ListModels(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	agents, err := h.customAgentService.ListAgents(ctx)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+	ids := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		ids = append(ids, agent.ID)
+	}
+	allowed, err := h.permissionService.FilterAccessible(ctx, types.ResourceTypeAgent, ids, types.PermissionRead)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+	readable := make(map[string]bool, len(allowed))
+	for _, id := range allowed {
+		readable[id] = true
+	}
+
+	models := make([]types.OpenAIModel, 0, len(allowed))
+	for _, agent := range agents {
+		if !readable[agent.ID] {
+			continue
+		}
+		models = append(models, types.OpenAIModel{
+			ID:      agent.ID,
+			Object:  types.OpenAIObjectModel,
+			Created: agent.CreatedAt.Unix(),
+			OwnedBy: "weknora",
+			Name:    agent.Name,
+		})
+	}
+	c.JSON(http.StatusOK, types.OpenAIModelList{Object: types.OpenAIObjectList, Data: models})
+}
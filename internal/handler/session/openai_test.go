@@ -0,0 +1,357 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/event"
+	"github.com/Tencent/WeKnora/internal/middleware"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// fakeSessionService keeps sessions in memory and answers by emitting agent events
+type fakeSessionService struct {
+	interfaces.SessionService
+
+	mu       sync.Mutex
+	sessions map[string]*types.Session
+	created  []*types.Session
+	imported map[string][]*types.History
+	queries  []string
+}
+
+func newFakeSessionService() *fakeSessionService {
+	return &fakeSessionService{
+		sessions: map[string]*types.Session{"existing-session": {ID: "existing-session", TenantID: 1}},
+		imported: make(map[string][]*types.History),
+	}
+}
+
+func (s *fakeSessionService) CreateSession(ctx context.Context, session *types.Session) (*types.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session.ID = fmt.Sprintf("new-session-%d", len(s.created)+1)
+	s.created = append(s.created, session)
+	s.sessions[session.ID] = session
+	return session, nil
+}
+
+func (s *fakeSessionService) GetSession(ctx context.Context, id string) (*types.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, errors.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (s *fakeSessionService) ImportHistory(ctx context.Context, sessionID string, history []*types.History) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.imported[sessionID] = history
+	return nil
+}
+
+func (s *fakeSessionService) KnowledgeQA(ctx context.Context,
+	session *types.Session, query string, knowledgeBaseIDs []string, knowledgeIDs []string,
+	assistantMessageID string, summaryModelID string, webSearchEnabled bool, eventBus *event.EventBus,
+	customAgent *types.CustomAgent,
+) error {
+	s.recordQuery(query)
+	eventBus.Emit(ctx, event.Event{Type: event.EventAgentReferences, Data: event.AgentReferencesData{
+		References: []*types.SearchResult{{ID: "chunk-1", Content: "X is a pump"}},
+	}})
+	eventBus.Emit(ctx, event.Event{Type: event.EventAgentFinalAnswer, Data: event.AgentFinalAnswerData{Content: "X is "}})
+	eventBus.Emit(ctx, event.Event{Type: event.EventAgentFinalAnswer,
+		Data: event.AgentFinalAnswerData{Content: "a pump", Done: true}})
+	return nil
+}
+
+func (s *fakeSessionService) AgentQA(ctx context.Context,
+	session *types.Session, query string, assistantMessageID string, summaryModelID string,
+	eventBus *event.EventBus, customAgent *types.CustomAgent, knowledgeBaseIDs []string, knowledgeIDs []string,
+) error {
+	s.recordQuery(query)
+	eventBus.Emit(ctx, event.Event{Type: event.EventAgentThought, Data: event.AgentThoughtData{Content: "Searching"}})
+	eventBus.Emit(ctx, event.Event{Type: event.EventAgentToolCall,
+		Data: event.AgentToolCallData{ToolCallID: "call-1", ToolName: "knowledge_search"}})
+	eventBus.Emit(ctx, event.Event{Type: event.EventAgentToolResult,
+		Data: event.AgentToolResultData{ToolCallID: "call-1", ToolName: "knowledge_search", Success: true, Output: "1 result"}})
+	eventBus.Emit(ctx, event.Event{Type: event.EventAgentFinalAnswer,
+		Data: event.AgentFinalAnswerData{Content: "X is a pump", Done: true}})
+	eventBus.Emit(ctx, event.Event{Type: event.EventAgentComplete, Data: event.AgentCompleteData{}})
+	return nil
+}
+
+func (s *fakeSessionService) recordQuery(query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, query)
+}
+
+// fakeMessageService keeps the created and completed messages
+type fakeMessageService struct {
+	interfaces.MessageService
+
+	mu       sync.Mutex
+	messages []*types.Message
+	updated  *types.Message
+}
+
+func (s *fakeMessageService) CreateMessage(ctx context.Context, message *types.Message) (*types.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	message.ID = fmt.Sprintf("msg-%d", len(s.messages)+1)
+	s.messages = append(s.messages, message)
+	return message, nil
+}
+
+func (s *fakeMessageService) UpdateMessage(ctx context.Context, message *types.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updated = message
+	return nil
+}
+
+// fakeCustomAgentService serves a knowledge QA agent and an agent mode agent
+type fakeCustomAgentService struct {
+	interfaces.CustomAgentService
+}
+
+func (s *fakeCustomAgentService) GetAgentByID(ctx context.Context, id string) (*types.CustomAgent, error) {
+	switch id {
+	case "quick-answer", "private-agent":
+		return &types.CustomAgent{ID: id, TenantID: 1}, nil
+	case "smart-reasoning":
+		return &types.CustomAgent{ID: id, TenantID: 1,
+			Config: types.CustomAgentConfig{AgentMode: types.AgentModeSmartReasoning}}, nil
+	}
+	return nil, errors.NewNotFoundError("agent not found")
+}
+
+// agentPermissionService denies reading private-agent
+type agentPermissionService struct {
+	interfaces.PermissionService
+}
+
+func (s *agentPermissionService) CheckPermission(ctx context.Context,
+	resourceType types.ResourceType, resourceID string, permission types.Permission,
+) error {
+	if resourceType == types.ResourceTypeAgent && resourceID == "private-agent" {
+		return errors.NewForbiddenError("이 리소스에 대한 권한이 없습니다")
+	}
+	return nil
+}
+
+func newOpenAIRouter(sessionService *fakeSessionService, messageService *fakeMessageService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(sessionService, messageService, nil, nil, nil,
+		&fakeCustomAgentService{}, &agentPermissionService{}, nil)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.Use(func(c *gin.Context) {
+		c.Set(types.TenantIDContextKey.String(), uint64(1))
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), types.TenantIDContextKey, uint64(1)))
+		c.Next()
+	})
+	r.POST("/chat/completions", h.ChatCompletions)
+	return r
+}
+
+func postChatCompletion(r *gin.Engine, body string, sessionID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(types.OpenAISessionIDHeader, sessionID)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+const openAIConversation = `[
+	{"role": "system", "content": "ignored"},
+	{"role": "user", "content": "Hi"},
+	{"role": "assistant", "content": "Hello!"},
+	{"role": "user", "content": "What is X?"}
+]`
+
+func TestChatCompletions_NonStream(t *testing.T) {
+	sessionService, messageService := newFakeSessionService(), &fakeMessageService{}
+	r := newOpenAIRouter(sessionService, messageService)
+
+	w := postChatCompletion(r, `{"model": "quick-answer", "messages": `+openAIConversation+`}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var completion types.OpenAIChatCompletion
+	if err := json.Unmarshal(w.Body.Bytes(), &completion); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if completion.Object != types.OpenAIObjectChatCompletion || len(completion.Choices) != 1 {
+		t.Fatalf("completion = %+v, want one chat completion choice", completion)
+	}
+	choice := completion.Choices[0]
+	if choice.Message == nil || choice.Message.Content != "X is a pump" {
+		t.Errorf("message = %+v, want content %q", choice.Message, "X is a pump")
+	}
+	if choice.FinishReason == nil || *choice.FinishReason != types.OpenAIFinishReasonStop {
+		t.Errorf("finish_reason = %v, want stop", choice.FinishReason)
+	}
+	if completion.WeKnora == nil || completion.WeKnora.SessionID != "new-session-1" ||
+		len(completion.WeKnora.References) != 1 {
+		t.Errorf("weknora = %+v, want the new session and one reference", completion.WeKnora)
+	}
+	if got := w.Header().Get(types.OpenAISessionIDHeader); got != "new-session-1" {
+		t.Errorf("%s = %q, want new-session-1", types.OpenAISessionIDHeader, got)
+	}
+	if messageService.updated == nil || messageService.updated.Content != "X is a pump" {
+		t.Errorf("completed message = %+v, want the answer stored", messageService.updated)
+	}
+}
+
+func TestChatCompletions_Stream(t *testing.T) {
+	sessionService, messageService := newFakeSessionService(), &fakeMessageService{}
+	r := newOpenAIRouter(sessionService, messageService)
+
+	w := postChatCompletion(r,
+		`{"model": "smart-reasoning", "stream": true, "messages": [{"role": "user", "content": "What is X?"}]}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		t.Errorf("Content-Type = %q, want text/event-stream", w.Header().Get("Content-Type"))
+	}
+
+	var content, reasoning strings.Builder
+	var toolStatuses []string
+	var finished, done bool
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			done = true
+			continue
+		}
+		var chunk types.OpenAIChatCompletion
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("failed to decode chunk %q: %v", data, err)
+		}
+		if chunk.Object != types.OpenAIObjectChatCompletionChunk || len(chunk.Choices) != 1 {
+			t.Fatalf("chunk = %+v, want one chat completion chunk choice", chunk)
+		}
+		if delta := chunk.Choices[0].Delta; delta != nil {
+			content.WriteString(delta.Content)
+			reasoning.WriteString(delta.ReasoningContent)
+		}
+		if chunk.WeKnora != nil {
+			for _, call := range chunk.WeKnora.ToolCalls {
+				toolStatuses = append(toolStatuses, call.Status)
+			}
+		}
+		if chunk.Choices[0].FinishReason != nil {
+			finished = true
+		}
+	}
+	if content.String() != "X is a pump" || reasoning.String() != "Searching" {
+		t.Errorf("content = %q, reasoning = %q, want the answer and the thought", content.String(), reasoning.String())
+	}
+	if want := []string{types.OpenAIToolStatusRunning, types.OpenAIToolStatusSuccess}; !reflect.DeepEqual(toolStatuses, want) {
+		t.Errorf("tool call statuses = %v, want %v", toolStatuses, want)
+	}
+	if !finished || !done {
+		t.Errorf("finish chunk = %v, [DONE] = %v, want both", finished, done)
+	}
+	if messageService.updated == nil || messageService.updated.Content != "X is a pump" {
+		t.Errorf("completed message = %+v, want the answer stored", messageService.updated)
+	}
+}
+
+func TestChatCompletions_Session(t *testing.T) {
+	t.Run("new session imports history", func(t *testing.T) {
+		sessionService := newFakeSessionService()
+		r := newOpenAIRouter(sessionService, &fakeMessageService{})
+
+		w := postChatCompletion(r, `{"model": "quick-answer", "messages": `+openAIConversation+`}`, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		if len(sessionService.created) != 1 || sessionService.created[0].Title != "What is X?" {
+			t.Fatalf("created sessions = %+v, want one titled with the question", sessionService.created)
+		}
+		want := []*types.History{{Query: "Hi", Answer: "Hello!"}}
+		if got := sessionService.imported["new-session-1"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("imported history = %+v, want %+v", got, want)
+		}
+		if !reflect.DeepEqual(sessionService.queries, []string{"What is X?"}) {
+			t.Errorf("queries = %v, want the last user message", sessionService.queries)
+		}
+	})
+
+	t.Run("existing session keeps server history", func(t *testing.T) {
+		sessionService := newFakeSessionService()
+		r := newOpenAIRouter(sessionService, &fakeMessageService{})
+
+		w := postChatCompletion(r, `{"model": "quick-answer", "messages": `+openAIConversation+`}`, "existing-session")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		if len(sessionService.created) != 0 || len(sessionService.imported) != 0 {
+			t.Errorf("created = %d, imported = %d, want the existing session reused without history",
+				len(sessionService.created), len(sessionService.imported))
+		}
+		if got := w.Header().Get(types.OpenAISessionIDHeader); got != "existing-session" {
+			t.Errorf("%s = %q, want existing-session", types.OpenAISessionIDHeader, got)
+		}
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		r := newOpenAIRouter(newFakeSessionService(), &fakeMessageService{})
+		w := postChatCompletion(r, `{"model": "quick-answer", "messages": `+openAIConversation+`}`, "missing")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", w.Code)
+		}
+	})
+}
+
+func TestChatCompletions_Model(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		want  int
+	}{
+		{"unknown model", "gpt-4o", http.StatusNotFound},
+		{"unreadable agent", "private-agent", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionService, messageService := newFakeSessionService(), &fakeMessageService{}
+			r := newOpenAIRouter(sessionService, messageService)
+
+			w := postChatCompletion(r,
+				`{"model": "`+tt.model+`", "messages": [{"role": "user", "content": "What is X?"}]}`, "")
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if len(sessionService.created) != 0 || len(messageService.messages) != 0 {
+				t.Errorf("created %d sessions and %d messages, want none",
+					len(sessionService.created), len(messageService.messages))
+			}
+		})
+	}
+}
@@ -74,6 +74,12 @@ func (h *Handler) parseQARequest(c *gin.Context, logPrefix string) (*qaRequestCo
 	// agent_id가 제공된 경우 사용자 정의 에이전트 가져오기
 	var customAgent *types.CustomAgent
 	if request.AgentID != "" {
+		// 에이전트 사용 권한 확인
+		if err := h.permissionService.CheckPermission(
+			ctx, types.ResourceTypeAgent, request.AgentID, types.PermissionRead,
+		); err != nil {
+			return nil, nil, err
+		}
 		logger.Infof(ctx, "Fetching custom agent, agent ID: %s", secutils.SanitizeForLog(request.AgentID))
 		agent, err := h.customAgentService.GetAgentByID(ctx, request.AgentID)
 		if err != nil {
@@ -212,6 +218,15 @@ func (h *Handler) SearchKnowledge(c *gin.Context) {
 		return
 	}
 
+	// 메타데이터 필터 검증
+	if request.Filter != nil {
+		if err := request.Filter.Validate(); err != nil {
+			logger.Error(ctx, "Invalid metadata filter", err)
+			c.Error(errors.NewBadRequestError("Invalid metadata filter").WithDetails(err.Error()))
+			return
+		}
+	}
+
 	logger.Infof(
 		ctx,
 		"Knowledge search request, knowledge base IDs: %v, knowledge IDs: %v, query: %s",
@@ -221,7 +236,9 @@ func (h *Handler) SearchKnowledge(c *gin.Context) {
 	)
 
 	// LLM 요약 없이 지식 검색 서비스 직접 호출
-	searchResults, err := h.sessionService.SearchKnowledge(ctx, knowledgeBaseIDs, request.KnowledgeIDs, request.Query)
+	searchResults, err := h.sessionService.SearchKnowledge(
+		ctx, knowledgeBaseIDs, request.KnowledgeIDs, request.Query, request.Filter,
+	)
 	if err != nil {
 		logger.ErrorWithFields(ctx, err, nil)
 		c.Error(errors.NewInternalServerError(err.Error()))
@@ -382,7 +399,7 @@ func (h *Handler) executeNormalModeQA(reqCtx *qaRequestContext, generateTitle bo
 	// SSE 이벤트 처리 (블로킹)
 	shouldWaitForTitle := generateTitle && reqCtx.session.Title == ""
 	h.handleAgentEventsForSSE(ctx, reqCtx.c, sessionID, reqCtx.assistantMessage.ID,
-		reqCtx.requestID, streamCtx.eventBus, shouldWaitForTitle)
+		reqCtx.requestID, streamCtx.eventBus, shouldWaitForTitle, 0)
 }
 
 // executeAgentModeQA 에이전트 모드 실행
@@ -426,6 +443,7 @@ func (h *Handler) executeAgentModeQA(reqCtx *qaRequestContext) {
 
 	// AgentQA 비동기 실행
 	go func() {
+		var err error
 		defer func() {
 			if r := recover(); r != nil {
 				buf := make([]byte, 1024)
@@ -434,11 +452,16 @@ func (h *Handler) executeAgentModeQA(reqCtx *qaRequestContext) {
 					errors.NewInternalServerError(fmt.Sprintf("Agent QA service panicked: %v\n%s", r, string(buf))),
 					map[string]interface{}{"session_id": sessionID})
 			}
+			// 일시 중지되었거나 다른 인스턴스가 이어받은 실행은 재개하는 쪽에서 메시지를 완료함
+			if err == errors.ErrAgentRunSuspended {
+				logger.Infof(streamCtx.asyncCtx, "Agent QA suspended for session: %s", sessionID)
+				return
+			}
 			h.completeAssistantMessage(streamCtx.asyncCtx, streamCtx.assistantMessage)
 			logger.Infof(streamCtx.asyncCtx, "Agent QA service completed for session: %s", sessionID)
 		}()
 
-		err := h.sessionService.AgentQA(
+		err = h.sessionService.AgentQA(
 			streamCtx.asyncCtx,
 			reqCtx.session,
 			reqCtx.query,
@@ -449,7 +472,7 @@ func (h *Handler) executeAgentModeQA(reqCtx *qaRequestContext) {
 			reqCtx.knowledgeBaseIDs,
 			reqCtx.knowledgeIDs,
 		)
-		if err != nil {
+		if err != nil && err != errors.ErrAgentRunSuspended {
 			logger.ErrorWithFields(streamCtx.asyncCtx, err, nil)
 			streamCtx.eventBus.Emit(streamCtx.asyncCtx, event.Event{
 				Type:      event.EventError,
@@ -465,7 +488,7 @@ func (h *Handler) executeAgentModeQA(reqCtx *qaRequestContext) {
 
 	// SSE 이벤트 처리 (블로킹)
 	h.handleAgentEventsForSSE(ctx, reqCtx.c, sessionID, reqCtx.assistantMessage.ID,
-		reqCtx.requestID, streamCtx.eventBus, reqCtx.session.Title == "")
+		reqCtx.requestID, streamCtx.eventBus, reqCtx.session.Title == "", 0)
 }
 
 // completeAssistantMessage 어시스턴트 메시지를 완료로 표시하고 업데이트
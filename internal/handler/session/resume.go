@@ -0,0 +1,177 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/event"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+)
+
+// ResumeSession godoc
+// @Summary      에이전트 실행 재개
+// @Description  일시 중지되었거나 중단된 에이전트 실행을 마지막으로 완료된 라운드부터 재개하고,
+// @Description  재개 이후의 이벤트를 스트리밍. 재개 이전 이벤트는 continue 엔드포인트로 조회
+// @Tags         질의응답
+// @Accept       json
+// @Produce      text/event-stream
+// @Param        session_id  path      string                true  "세션 ID"
+// @Param        request     body      ResumeSessionRequest  true  "재개 요청"
+// @Success      200         {object}  map[string]interface{}  "스트림 응답"
+// @Failure      400         {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      404         {object}  errors.AppError         "세션, 메시지 또는 에이전트 실행이 없음"
+// @Failure      409         {object}  errors.AppError         "재개할 수 없는 에이전트 실행"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /sessions/{session_id}/resume [post]
+func (h *Handler) ResumeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := secutils.SanitizeForLog(c.Param("session_id"))
+
+	var req ResumeSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("message_id is required").WithDetails(err.Error()))
+		return
+	}
+	messageID := secutils.SanitizeForLog(req.MessageID)
+	logger.Infof(ctx, "Resume agent run request for session: %s, message: %s", sessionID, messageID)
+
+	session, err := h.sessionService.GetSession(ctx, sessionID)
+	if err != nil {
+		if err == errors.ErrSessionNotFound {
+			c.Error(errors.NewNotFoundError(err.Error()))
+			return
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"session_id": sessionID})
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+
+	message, err := h.messageService.GetMessage(ctx, sessionID, messageID)
+	if err != nil || message == nil {
+		c.Error(errors.NewNotFoundError("Message not found"))
+		return
+	}
+	if message.IsCompleted {
+		c.Error(errors.NewConflictError("이미 완료된 메시지입니다"))
+		return
+	}
+
+	// 재개 이전 이벤트(이전 중지 이벤트 포함)는 다시 보내지 않음
+	_, offset, err := h.streamManager.GetEvents(ctx, sessionID, messageID, 0)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"session_id": sessionID, "message_id": messageID})
+		c.Error(errors.NewInternalServerError(fmt.Sprintf("Failed to get stream data: %s", err.Error())))
+		return
+	}
+
+	run, err := h.agentRunService.Claim(ctx, sessionID, messageID,
+		types.AgentRunPaused, types.AgentRunInterrupted)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			c.Error(appErr)
+			return
+		}
+		logger.ErrorWithFields(ctx, err, map[string]interface{}{"session_id": sessionID, "message_id": messageID})
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+
+	setSSEHeaders(c)
+	eventBus := event.NewEventBus()
+	asyncCtx, cancel := context.WithCancel(logger.CloneContext(ctx))
+	h.setupStopEventHandler(eventBus, sessionID, message, cancel)
+	h.setupStreamHandler(asyncCtx, sessionID, messageID, message.RequestID, message, eventBus)
+
+	go h.resumeAgentRun(asyncCtx, session, run, message, eventBus)
+
+	// SSE 이벤트 처리 (블로킹)
+	h.handleAgentEventsForSSE(ctx, c, sessionID, messageID, message.RequestID, eventBus, false, offset)
+}
+
+// ProcessAgentRunResume 실행하던 인스턴스가 종료되어 중단된 에이전트 실행을 백그라운드에서 재개합니다.
+// 재개된 이벤트는 스트림에 기록되므로 클라이언트는 continue 엔드포인트로 이어서 받을 수 있습니다.
+func (h *Handler) ProcessAgentRunResume(ctx context.Context, t *asynq.Task) error {
+	var payload types.AgentRunResumePayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal agent run resume payload: %w", err)
+	}
+	ctx = context.WithValue(ctx, types.TenantIDContextKey, payload.TenantID)
+
+	message, err := h.messageService.GetMessage(ctx, payload.SessionID, payload.MessageID)
+	if err != nil || message == nil {
+		logger.Warnf(ctx, "Message %s of agent run not found, giving up the run: %v", payload.MessageID, err)
+		_, _ = h.agentRunService.Cancel(ctx, payload.SessionID, payload.MessageID)
+		return nil
+	}
+	if message.IsCompleted {
+		_, _ = h.agentRunService.Cancel(ctx, payload.SessionID, payload.MessageID)
+		return nil
+	}
+
+	// 사용자가 이미 재개했거나 다른 작업이 가져간 실행은 건너뜀
+	run, err := h.agentRunService.Claim(ctx, payload.SessionID, payload.MessageID, types.AgentRunInterrupted)
+	if err != nil {
+		if _, ok := errors.IsAppError(err); ok {
+			logger.Infof(ctx, "Agent run of message %s is not waiting for resumption: %v", payload.MessageID, err)
+			return nil
+		}
+		return err
+	}
+	ctx, err = h.agentRunService.RestoreContext(ctx, run)
+	if err != nil {
+		return err
+	}
+	session, err := h.sessionService.GetSession(ctx, payload.SessionID)
+	if err != nil {
+		return err
+	}
+
+	eventBus := event.NewEventBus()
+	h.setupStreamHandler(ctx, session.ID, message.ID, message.RequestID, message, eventBus)
+	h.resumeAgentRun(ctx, session, run, message, eventBus)
+	return nil
+}
+
+// resumeAgentRun 에이전트 실행을 재개하고, 일시 중지되지 않으면 어시스턴트 메시지를 완료합니다.
+func (h *Handler) resumeAgentRun(ctx context.Context, session *types.Session, run *types.AgentRun,
+	assistantMessage *types.Message, eventBus *event.EventBus,
+) {
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			buf := make([]byte, 1024)
+			runtime.Stack(buf, true)
+			logger.ErrorWithFields(ctx,
+				errors.NewInternalServerError(fmt.Sprintf("Agent run resume panicked: %v\n%s", r, string(buf))),
+				map[string]interface{}{"session_id": session.ID})
+		}
+		if err == errors.ErrAgentRunSuspended {
+			logger.Infof(ctx, "Resumed agent run suspended again for session: %s", session.ID)
+			return
+		}
+		h.completeAssistantMessage(ctx, assistantMessage)
+		logger.Infof(ctx, "Resumed agent run completed for session: %s", session.ID)
+	}()
+
+	err = h.sessionService.ResumeAgentQA(ctx, session, run, eventBus)
+	if err != nil && err != errors.ErrAgentRunSuspended {
+		logger.ErrorWithFields(ctx, err, nil)
+		eventBus.Emit(ctx, event.Event{
+			Type:      event.EventError,
+			SessionID: session.ID,
+			Data: event.ErrorData{
+				Error:     err.Error(),
+				Stage:     "agent_execution",
+				SessionID: session.ID,
+			},
+		})
+	}
+}
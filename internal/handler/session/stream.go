@@ -15,6 +15,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// 중지 이벤트 사유
+const (
+	stopReasonUserRequested = "user_requested" // 사용자가 생성을 중지함
+	stopReasonPaused        = "paused"         // 에이전트 실행이 일시 중지되어 재개할 수 있음
+)
+
 // ContinueStream godoc
 // @Summary      스트림 응답 계속
 // @Description  진행 중인 스트림 응답 계속 받기
@@ -130,6 +136,13 @@ func (h *Handler) ContinueStream(c *gin.Context) {
 		return
 	}
 
+	// 일시 중지되었거나 중단된 에이전트 실행은 재개될 때까지 새 이벤트가 없음
+	if h.isAgentRunSuspended(ctx, sessionID, messageID) {
+		logger.Infof(ctx, "Agent run is suspended, session ID: %s, message ID: %s", sessionID, messageID)
+		sendPausedEvent(c, message.RequestID)
+		return
+	}
+
 	// 새로운 이벤트 폴링 계속
 	logger.Debug(ctx, "Starting event update monitoring")
 	ticker := time.NewTicker(100 * time.Millisecond)
@@ -157,6 +170,13 @@ func (h *Handler) ContinueStream(c *gin.Context) {
 					streamCompletedNow = true
 				}
 
+				// 일시 중지 이벤트 확인
+				if evt.Type == types.ResponseType(event.EventStop) && getString(evt.Data, "reason") == stopReasonPaused {
+					logger.Infof(ctx, "Agent run paused, session ID: %s, message ID: %s", sessionID, messageID)
+					sendPausedEvent(c, message.RequestID)
+					return
+				}
+
 				response := buildStreamResponse(evt, message.RequestID)
 				c.SSEvent("message", response)
 				c.Writer.Flush()
@@ -263,6 +283,31 @@ func (h *Handler) StopSession(c *gin.Context) {
 		return
 	}
 
+	// 실행 중인 에이전트는 중지 대신 일시 중지하여 마지막으로 완료된 라운드부터 재개할 수 있게 함
+	reason := stopReasonUserRequested
+	if run, err := h.agentRunService.GetRun(ctx, sessionID, assistantMessageID); err == nil {
+		switch {
+		case run.Status == types.AgentRunRunning:
+			if _, err := h.agentRunService.Pause(ctx, sessionID, assistantMessageID); err != nil {
+				logger.Warnf(ctx, "Failed to pause agent run of message %s: %v", assistantMessageID, err)
+			} else {
+				reason = stopReasonPaused
+			}
+		case run.Status.IsResumable():
+			// 이미 일시 중지된 실행을 중지하면 재개하지 않고 메시지를 완료함
+			if _, err := h.agentRunService.Cancel(ctx, sessionID, assistantMessageID); err != nil {
+				logger.Warnf(ctx, "Failed to cancel agent run of message %s: %v", assistantMessageID, err)
+			}
+			message.Content = "사용자가 대화를 중지했습니다"
+			h.completeAssistantMessage(ctx, message)
+			c.JSON(200, gin.H{
+				"success": true,
+				"message": "Generation stopped",
+			})
+			return
+		}
+	}
+
 	// 분산 지원을 위해 StreamManager에 중지 이벤트 기록
 	stopEvent := interfaces.StreamEvent{
 		ID:        fmt.Sprintf("stop-%d", time.Now().UnixNano()),
@@ -273,7 +318,7 @@ func (h *Handler) StopSession(c *gin.Context) {
 		Data: map[string]interface{}{
 			"session_id": sessionID,
 			"message_id": assistantMessageID,
-			"reason":     "user_requested",
+			"reason":     reason,
 		},
 	}
 
@@ -286,7 +331,16 @@ func (h *Handler) StopSession(c *gin.Context) {
 		return
 	}
 
-	logger.Infof(ctx, "Stop event written successfully for session: %s, message: %s", sessionID, assistantMessageID)
+	logger.Infof(ctx, "Stop event written successfully for session: %s, message: %s, reason: %s",
+		sessionID, assistantMessageID, reason)
+	if reason == stopReasonPaused {
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Generation paused",
+			"paused":  true,
+		})
+		return
+	}
 	c.JSON(200, gin.H{
 		"success": true,
 		"message": "Generation stopped",
@@ -297,17 +351,19 @@ func (h *Handler) StopSession(c *gin.Context) {
 // 핸들러는 이미 이벤트를 구독하고 있으며 AgentQA는 이미 실행 중입니다.
 // 이 함수는 StreamManager를 폴링하고 이벤트를 SSE로 푸시하여 연결 끊김을 정상적으로 처리합니다.
 // waitForTitle: true인 경우 완료 후 제목 이벤트를 기다림 (제목이 없는 새 세션의 경우)
+// fromOffset: 이 오프셋부터 이벤트를 전송 (재개한 실행은 재개 이전 이벤트를 다시 보내지 않음)
 func (h *Handler) handleAgentEventsForSSE(
 	ctx context.Context,
 	c *gin.Context,
 	sessionID, assistantMessageID, requestID string,
 	eventBus *event.EventBus,
 	waitForTitle bool,
+	fromOffset int,
 ) {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
-	lastOffset := 0
+	lastOffset := fromOffset
 	log := logger.GetLogger(ctx)
 
 	log.Infof("Starting pull-based SSE streaming for session=%s, message=%s", sessionID, assistantMessageID)
@@ -338,6 +394,10 @@ func (h *Handler) handleAgentEventsForSSE(
 				// 중지 이벤트 확인
 				if evt.Type == types.ResponseType(event.EventStop) {
 					log.Infof("Detected stop event, triggering stop via EventBus for session=%s", sessionID)
+					reason := getString(evt.Data, "reason")
+					if reason == "" {
+						reason = stopReasonUserRequested
+					}
 
 					// 컨텍스트 취소를 트리거하기 위해 EventBus에 중지 이벤트 방출
 					if eventBus != nil {
@@ -347,12 +407,16 @@ func (h *Handler) handleAgentEventsForSSE(
 							Data: event.StopData{
 								SessionID: sessionID,
 								MessageID: assistantMessageID,
-								Reason:    "user_requested",
+								Reason:    reason,
 							},
 						})
 					}
 
-					// 프론트엔드에 중지 알림 전송
+					// 프론트엔드에 일시 중지 또는 중지 알림 전송
+					if reason == stopReasonPaused {
+						sendPausedEvent(c, requestID)
+						return
+					}
 					c.SSEvent("message", &types.StreamResponse{
 						ID:           requestID,
 						ResponseType: "stop",
@@ -46,9 +46,16 @@ type SearchKnowledgeRequest struct {
 	KnowledgeBaseID  string   `json:"knowledge_base_id"`                     // 단일 지식베이스 ID (하위 호환성을 위해)
 	KnowledgeBaseIDs []string `json:"knowledge_base_ids"`                    // 검색할 지식베이스 ID 목록 (다중 KB 지원)
 	KnowledgeIDs     []string `json:"knowledge_ids"`                         // 검색할 특정 지식(파일) ID 목록
+	// 지식 및 청크 메타데이터 필터 (예: 제품 버전, 문서 날짜)
+	Filter *types.MetadataFilter `json:"filter,omitempty"`
 }
 
 // StopSessionRequest 세션 중지 요청을 나타냅니다.
 type StopSessionRequest struct {
 	MessageID string `json:"message_id" binding:"required"`
 }
+
+// ResumeSessionRequest 에이전트 실행 재개 요청을 나타냅니다.
+type ResumeSessionRequest struct {
+	MessageID string `json:"message_id" binding:"required"`
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tencent/WeKnora/internal/config"
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+)
+
+const (
+	// ssoStateCookie 로그인을 시작한 브라우저에서만 OIDC 콜백을 완료하도록 상태 값을 보관하는 쿠키
+	ssoStateCookie = "weknora_sso_state"
+	// ssoCookiePath 상태 쿠키를 전송할 경로
+	ssoCookiePath = "/api/v1/auth/sso"
+)
+
+// SSOHandler 외부 ID 공급자를 통한 싱글 사인온 HTTP 요청 처리
+type SSOHandler struct {
+	ssoService interfaces.SSOService
+	configInfo *config.Config
+}
+
+// NewSSOHandler 새로운 SSO 핸들러 생성
+func NewSSOHandler(configInfo *config.Config, ssoService interfaces.SSOService) *SSOHandler {
+	return &SSOHandler{configInfo: configInfo, ssoService: ssoService}
+}
+
+// handleSSOError 서비스 오류를 응답 오류로 변환합니다.
+func handleSSOError(c *gin.Context, err error, fields map[string]interface{}) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+		return
+	}
+	logger.ErrorWithFields(c.Request.Context(), err, fields)
+	c.Error(errors.NewInternalServerError(err.Error()))
+}
+
+// ListProviders godoc
+// @Summary      SSO 공급자 목록 조회
+// @Description  로그인 화면에 표시할 구성된 ID 공급자 목록 조회
+// @Tags         인증
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "SSO 공급자 목록"
+// @Router       /auth/sso/providers [get]
+func (h *SSOHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.ssoService.ListProviders(c.Request.Context()),
+	})
+}
+
+// Login godoc
+// @Summary      SSO 로그인 시작
+// @Description  ID 공급자의 인증 페이지로 리디렉션. OIDC는 PKCE 인증 코드 흐름, SAML은 HTTP-Redirect 바인딩 사용
+// @Tags         인증
+// @Param        provider  path  string  true  "공급자 이름"
+// @Success      302       "ID 공급자로 리디렉션"
+// @Failure      404       {object}  errors.AppError  "공급자를 찾을 수 없음"
+// @Router       /auth/sso/{provider}/login [get]
+func (h *SSOHandler) Login(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	provider := secutils.SanitizeForLog(c.Param("provider"))
+	redirect, err := h.ssoService.BeginLogin(ctx, provider)
+	if err != nil {
+		handleSSOError(c, err, map[string]interface{}{"provider": provider})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, redirect.State, 600, ssoCookiePath, "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, redirect.URL)
+}
+
+// Callback godoc
+// @Summary      OIDC 로그인 콜백
+// @Description  ID 공급자가 반환한 인증 코드로 로그인을 완료하고 토큰 발급. 처음 로그인하는 사용자는 자동으로 생성
+// @Tags         인증
+// @Produce      json
+// @Param        provider  path      string  true   "공급자 이름"
+// @Param        code      query     string  false  "인증 코드"
+// @Param        state     query     string  true   "로그인 상태"
+// @Success      200       {object}  types.LoginResponse
+// @Failure      401       {object}  errors.AppError  "인증 실패"
+// @Failure      403       {object}  errors.AppError  "매핑된 테넌트 없음"
+// @Router       /auth/sso/{provider}/callback [get]
+func (h *SSOHandler) Callback(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	provider := secutils.SanitizeForLog(c.Param("provider"))
+	if idpErr := c.Query("error"); idpErr != "" {
+		logger.Warnf(ctx, "SSO provider %s returned error: %s", provider, secutils.SanitizeForLog(idpErr))
+		h.finishLogin(c, nil, errors.NewUnauthorizedError("ID 공급자 인증에 실패했습니다").
+			WithDetails(c.Query("error_description")))
+		return
+	}
+
+	state := c.Query("state")
+	cookie, _ := c.Cookie(ssoStateCookie)
+	c.SetCookie(ssoStateCookie, "", -1, ssoCookiePath, "", c.Request.TLS != nil, true)
+	if state == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		h.finishLogin(c, nil, errors.NewBadRequestError("로그인을 시작한 브라우저에서 완료해야 합니다"))
+		return
+	}
+
+	response, err := h.ssoService.CompleteOIDCLogin(ctx, provider, c.Query("code"), state)
+	h.finishLogin(c, response, err)
+}
+
+// AssertionConsumerService godoc
+// @Summary      SAML 어설션 소비 서비스
+// @Description  ID 공급자가 HTTP-POST 바인딩으로 전송한 SAML 응답을 검증하고 토큰 발급. 처음 로그인하는 사용자는 자동으로 생성
+// @Tags         인증
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        provider      path      string  true  "공급자 이름"
+// @Param        SAMLResponse  formData  string  true  "SAML 응답"
+// @Param        RelayState    formData  string  true  "로그인 상태"
+// @Success      200           {object}  types.LoginResponse
+// @Failure      401           {object}  errors.AppError  "인증 실패"
+// @Failure      403           {object}  errors.AppError  "매핑된 테넌트 없음"
+// @Router       /auth/sso/{provider}/acs [post]
+func (h *SSOHandler) AssertionConsumerService(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	provider := secutils.SanitizeForLog(c.Param("provider"))
+	response, err := h.ssoService.CompleteSAMLLogin(ctx, provider, c.PostForm("SAMLResponse"), c.PostForm("RelayState"))
+	h.finishLogin(c, response, err)
+}
+
+// Metadata godoc
+// @Summary      SAML SP 메타데이터 조회
+// @Description  ID 공급자에 등록할 서비스 공급자 메타데이터 조회
+// @Tags         인증
+// @Produce      xml
+// @Param        provider  path  string  true  "공급자 이름"
+// @Success      200       {string}  string  "SP 메타데이터"
+// @Failure      404       {object}  errors.AppError  "공급자를 찾을 수 없음"
+// @Router       /auth/sso/{provider}/metadata [get]
+func (h *SSOHandler) Metadata(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	provider := secutils.SanitizeForLog(c.Param("provider"))
+	metadata, err := h.ssoService.SAMLMetadata(ctx, provider)
+	if err != nil {
+		handleSSOError(c, err, map[string]interface{}{"provider": provider})
+		return
+	}
+	c.Data(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
+// finishLogin 로그인 결과를 응답합니다.
+// 프런트엔드 주소가 구성되어 있으면 토큰 또는 오류를 URL 프래그먼트에 담아 리디렉션하고, 그렇지 않으면 JSON으로 응답합니다.
+func (h *SSOHandler) finishLogin(c *gin.Context, response *types.LoginResponse, err error) {
+	frontendURL := ""
+	if h.configInfo != nil && h.configInfo.SSO != nil {
+		frontendURL = strings.TrimSpace(h.configInfo.SSO.FrontendURL)
+	}
+
+	if err != nil {
+		if frontendURL == "" {
+			handleSSOError(c, err, map[string]interface{}{"provider": c.Param("provider")})
+			return
+		}
+		message := "SSO login failed"
+		if appErr, ok := errors.IsAppError(err); ok {
+			message = appErr.Message
+		} else {
+			logger.ErrorWithFields(c.Request.Context(), err, map[string]interface{}{"provider": c.Param("provider")})
+		}
+		fragment := url.Values{"error": {message}}
+		c.Redirect(http.StatusFound, frontendURL+"#"+fragment.Encode())
+		return
+	}
+
+	if frontendURL == "" {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	fragment := url.Values{
+		"token":         {response.Token},
+		"refresh_token": {response.RefreshToken},
+	}
+	c.Redirect(http.StatusFound, frontendURL+"#"+fragment.Encode())
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ToolApprovalHandler 에이전트 도구 호출 승인 HTTP 요청 처리
+type ToolApprovalHandler struct {
+	toolApprovalService interfaces.ToolApprovalService
+}
+
+// NewToolApprovalHandler 새로운 도구 승인 핸들러 생성
+func NewToolApprovalHandler(toolApprovalService interfaces.ToolApprovalService) *ToolApprovalHandler {
+	return &ToolApprovalHandler{toolApprovalService: toolApprovalService}
+}
+
+// handleToolApprovalError 서비스 오류를 응답 오류로 변환합니다.
+func handleToolApprovalError(c *gin.Context, err error, fields map[string]interface{}) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+		return
+	}
+	logger.ErrorWithFields(c.Request.Context(), err, fields)
+	c.Error(errors.NewInternalServerError(err.Error()))
+}
+
+// ListToolApprovals godoc
+// @Summary      도구 승인 요청 목록 조회
+// @Description  세션의 도구 호출 승인 요청을 최신순으로 조회. 대기 시간이 지난 요청은 expired로 표시됨
+// @Tags         도구 승인
+// @Produce      json
+// @Param        session_id  path      string  true   "세션 ID"
+// @Param        status      query     string  false  "상태 (pending, approved, rejected, expired)"
+// @Success      200         {object}  map[string]interface{}  "승인 요청 목록"
+// @Failure      400         {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      404         {object}  errors.AppError         "세션을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /tool-approvals/{session_id} [get]
+func (h *ToolApprovalHandler) ListToolApprovals(c *gin.Context) {
+	sessionID := secutils.SanitizeForLog(c.Param("session_id"))
+	status := types.ToolApprovalStatus(c.Query("status"))
+
+	approvals, err := h.toolApprovalService.ListApprovals(c.Request.Context(), sessionID, status)
+	if err != nil {
+		handleToolApprovalError(c, err, map[string]interface{}{"session_id": sessionID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    approvals,
+	})
+}
+
+// GetToolApproval godoc
+// @Summary      도구 승인 요청 조회
+// @Description  도구 호출 승인 요청의 인자와 상태를 조회
+// @Tags         도구 승인
+// @Produce      json
+// @Param        session_id  path      string  true  "세션 ID"
+// @Param        id          path      string  true  "승인 요청 ID"
+// @Success      200         {object}  map[string]interface{}  "승인 요청"
+// @Failure      404         {object}  errors.AppError         "승인 요청을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /tool-approvals/{session_id}/{id} [get]
+func (h *ToolApprovalHandler) GetToolApproval(c *gin.Context) {
+	sessionID := secutils.SanitizeForLog(c.Param("session_id"))
+	id := secutils.SanitizeForLog(c.Param("id"))
+
+	approval, err := h.toolApprovalService.GetApproval(c.Request.Context(), sessionID, id)
+	if err != nil {
+		handleToolApprovalError(c, err, map[string]interface{}{"session_id": sessionID, "approval_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
+
+// ApproveToolApproval godoc
+// @Summary      도구 호출 승인
+// @Description  대기 중인 도구 호출을 승인하여 에이전트가 실행을 이어가게 함.
+// @Description  arguments를 지정하면 수정한 인자로 도구를 실행함
+// @Tags         도구 승인
+// @Accept       json
+// @Produce      json
+// @Param        session_id  path      string                      true   "세션 ID"
+// @Param        id          path      string                      true   "승인 요청 ID"
+// @Param        request     body      types.ToolApprovalDecision  false  "수정할 인자와 의견"
+// @Success      200         {object}  map[string]interface{}      "승인된 요청"
+// @Failure      400         {object}  errors.AppError             "요청 매개변수 오류"
+// @Failure      403         {object}  errors.AppError             "편집자 이상의 역할이 필요함"
+// @Failure      404         {object}  errors.AppError             "승인 요청을 찾을 수 없음"
+// @Failure      409         {object}  errors.AppError             "이미 처리되었거나 만료된 요청"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /tool-approvals/{session_id}/{id}/approve [post]
+func (h *ToolApprovalHandler) ApproveToolApproval(c *gin.Context) {
+	h.decide(c, h.toolApprovalService.Approve)
+}
+
+// RejectToolApproval godoc
+// @Summary      도구 호출 거절
+// @Description  대기 중인 도구 호출을 거절. 도구는 실행되지 않고 거절 사유가 에이전트에게 전달됨
+// @Tags         도구 승인
+// @Accept       json
+// @Produce      json
+// @Param        session_id  path      string                      true   "세션 ID"
+// @Param        id          path      string                      true   "승인 요청 ID"
+// @Param        request     body      types.ToolApprovalDecision  false  "거절 사유"
+// @Success      200         {object}  map[string]interface{}      "거절된 요청"
+// @Failure      400         {object}  errors.AppError             "요청 매개변수 오류"
+// @Failure      403         {object}  errors.AppError             "편집자 이상의 역할이 필요함"
+// @Failure      404         {object}  errors.AppError             "승인 요청을 찾을 수 없음"
+// @Failure      409         {object}  errors.AppError             "이미 처리되었거나 만료된 요청"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /tool-approvals/{session_id}/{id}/reject [post]
+func (h *ToolApprovalHandler) RejectToolApproval(c *gin.Context) {
+	h.decide(c, h.toolApprovalService.Reject)
+}
+
+// decide 승인 또는 거절 요청을 처리합니다. 본문은 생략할 수 있습니다.
+func (h *ToolApprovalHandler) decide(c *gin.Context,
+	decide func(ctx context.Context, sessionID, id string, decision *types.ToolApprovalDecision) (*types.ToolApproval, error),
+) {
+	sessionID := secutils.SanitizeForLog(c.Param("session_id"))
+	id := secutils.SanitizeForLog(c.Param("id"))
+
+	var decision types.ToolApprovalDecision
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&decision); err != nil {
+			c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+			return
+		}
+	}
+
+	approval, err := decide(c.Request.Context(), sessionID, id, &decision)
+	if err != nil {
+		handleToolApprovalError(c, err, map[string]interface{}{"session_id": sessionID, "approval_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	"github.com/gin-gonic/gin"
+)
+
+// UsageHandler 모델 사용량 HTTP 요청 처리
+type UsageHandler struct {
+	usageService interfaces.UsageService
+}
+
+// NewUsageHandler 새로운 사용량 핸들러 생성
+func NewUsageHandler(usageService interfaces.UsageService) *UsageHandler {
+	return &UsageHandler{usageService: usageService}
+}
+
+// GetUsageSummary godoc
+// @Summary      사용량 보고서 조회
+// @Description  현재 테넌트의 모델 토큰 사용량을 일별 집계에서 조회. 날짜, 모델, 모델 유형, 에이전트, 출처별로 그룹화 가능.
+// @Description  집계는 10분마다 갱신되며 날짜는 UTC 기준. 관리자 권한 필요
+// @Tags         사용량
+// @Produce      json
+// @Param        from        query     string  false  "시작 날짜 (YYYY-MM-DD, 포함), 기본값 30일 전"
+// @Param        to          query     string  false  "종료 날짜 (YYYY-MM-DD, 포함), 기본값 오늘"
+// @Param        group_by    query     string  false  "그룹 기준, 쉼표로 구분 (day, model, model_type, agent, source), 기본값 model,agent"
+// @Param        model_id    query     string  false  "모델 ID"
+// @Param        model_type  query     string  false  "모델 유형 (KnowledgeQA, Embedding, Rerank, VLLM)"
+// @Param        agent_id    query     string  false  "사용자 정의 에이전트 ID"
+// @Param        source      query     string  false  "사용량 출처"
+// @Success      200         {object}  map[string]interface{}  "사용량 보고서"
+// @Failure      400         {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403         {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /usage [get]
+func (h *UsageHandler) GetUsageSummary(c *gin.Context) {
+	ctx := c.Request.Context()
+	var query types.UsageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+
+	summary, err := h.usageService.GetUsageSummary(ctx, &query)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			c.Error(appErr)
+			return
+		}
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    summary,
+	})
+}
+
+// ListUsageRecords godoc
+// @Summary      사용량 기록 목록 조회
+// @Description  현재 테넌트의 모델 호출별 사용량 기록을 최신순으로 조회. 세션, 에이전트, 모델, 출처와 기간으로 필터링 가능.
+// @Description  보존 기간이 지난 기록은 삭제되며 일별 집계만 남음. 관리자 권한 필요
+// @Tags         사용량
+// @Produce      json
+// @Param        session_id  query     string  false  "세션 ID"
+// @Param        agent_id    query     string  false  "사용자 정의 에이전트 ID"
+// @Param        model_id    query     string  false  "모델 ID"
+// @Param        source      query     string  false  "사용량 출처"
+// @Param        from        query     string  false  "시작 시간 (RFC3339)"
+// @Param        to          query     string  false  "종료 시간 (RFC3339)"
+// @Param        page        query     int     false  "페이지 번호"
+// @Param        page_size   query     int     false  "페이지당 개수"
+// @Success      200         {object}  map[string]interface{}  "사용량 기록 목록"
+// @Failure      400         {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403         {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /usage/records [get]
+func (h *UsageHandler) ListUsageRecords(c *gin.Context) {
+	ctx := c.Request.Context()
+	var filter types.UsageRecordFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	var page types.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid pagination parameters").WithDetails(err.Error()))
+		return
+	}
+
+	result, err := h.usageService.ListUsageRecords(ctx, &filter, &page)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		c.Error(errors.NewInternalServerError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler 테넌트 웹훅 관리 HTTP 요청 처리
+type WebhookHandler struct {
+	webhookService interfaces.WebhookService
+}
+
+// NewWebhookHandler 새로운 웹훅 핸들러 생성
+func NewWebhookHandler(webhookService interfaces.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// handleWebhookError 서비스 오류를 응답 오류로 변환합니다.
+func handleWebhookError(c *gin.Context, err error, fields map[string]interface{}) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+		return
+	}
+	logger.ErrorWithFields(c.Request.Context(), err, fields)
+	c.Error(errors.NewInternalServerError(err.Error()))
+}
+
+// ListWebhooks godoc
+// @Summary      웹훅 목록 조회
+// @Description  현재 테넌트의 웹훅 목록 조회, 서명 비밀은 포함되지 않음. 관리자 권한 필요
+// @Tags         웹훅
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "웹훅 목록"
+// @Failure      403  {object}  errors.AppError         "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookService.ListWebhooks(c.Request.Context())
+	if err != nil {
+		handleWebhookError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    webhooks,
+	})
+}
+
+// GetWebhook godoc
+// @Summary      웹훅 조회
+// @Description  웹훅 하나를 조회, 서명 비밀은 포함되지 않음. 관리자 권한 필요
+// @Tags         웹훅
+// @Produce      json
+// @Param        id   path      string  true  "웹훅 ID"
+// @Success      200  {object}  map[string]interface{}  "웹훅"
+// @Failure      403  {object}  errors.AppError         "권한 없음"
+// @Failure      404  {object}  errors.AppError         "웹훅을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	webhook, err := h.webhookService.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		handleWebhookError(c, err, map[string]interface{}{"webhook_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    webhook,
+	})
+}
+
+// CreateWebhook godoc
+// @Summary      웹훅 생성
+// @Description  URL과 구독할 이벤트(knowledge.parsed, knowledge.failed, faq_import.completed, faq_import.failed,
+// @Description  kb_clone.completed, kb_clone.failed, message.completed, 모든 이벤트는 *)를 지정하여 웹훅 생성.
+// @Description  서명 비밀은 이 응답에서만 반환되며 X-WeKnora-Signature 헤더 검증에 사용. 관리자 권한 필요
+// @Tags         웹훅
+// @Accept       json
+// @Produce      json
+// @Param        request  body      types.CreateWebhookRequest  true  "웹훅 정보"
+// @Success      200      {object}  map[string]interface{}      "생성된 웹훅"
+// @Failure      400      {object}  errors.AppError             "요청 매개변수 오류"
+// @Failure      403      {object}  errors.AppError             "권한 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req types.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	webhook, err := h.webhookService.CreateWebhook(c.Request.Context(), &req)
+	if err != nil {
+		handleWebhookError(c, err, map[string]interface{}{"name": secutils.SanitizeForLog(req.Name)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    webhook,
+	})
+}
+
+// UpdateWebhook godoc
+// @Summary      웹훅 수정
+// @Description  웹훅의 이름, URL, 구독 이벤트, 활성화 여부를 수정. rotate_secret이 true이면 새 서명 비밀을 발급하여 응답에 포함. 관리자 권한 필요
+// @Tags         웹훅
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                      true  "웹훅 ID"
+// @Param        request  body      types.UpdateWebhookRequest  true  "수정할 정보"
+// @Success      200      {object}  map[string]interface{}      "수정된 웹훅"
+// @Failure      400      {object}  errors.AppError             "요청 매개변수 오류"
+// @Failure      403      {object}  errors.AppError             "권한 없음"
+// @Failure      404      {object}  errors.AppError             "웹훅을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	var req types.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	webhook, err := h.webhookService.UpdateWebhook(c.Request.Context(), id, &req)
+	if err != nil {
+		handleWebhookError(c, err, map[string]interface{}{"webhook_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    webhook,
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary      웹훅 삭제
+// @Description  웹훅과 전달 기록을 삭제. 관리자 권한 필요
+// @Tags         웹훅
+// @Produce      json
+// @Param        id   path      string  true  "웹훅 ID"
+// @Success      200  {object}  map[string]interface{}  "삭제 성공"
+// @Failure      403  {object}  errors.AppError         "권한 없음"
+// @Failure      404  {object}  errors.AppError         "웹훅을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	if err := h.webhookService.DeleteWebhook(c.Request.Context(), id); err != nil {
+		handleWebhookError(c, err, map[string]interface{}{"webhook_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// TestWebhook godoc
+// @Summary      웹훅 테스트
+// @Description  구독 이벤트와 관계없이 ping 이벤트를 전송. 결과는 전달 기록에서 확인. 관리자 권한 필요
+// @Tags         웹훅
+// @Produce      json
+// @Param        id   path      string  true  "웹훅 ID"
+// @Success      200  {object}  map[string]interface{}  "생성된 전달 기록"
+// @Failure      403  {object}  errors.AppError         "권한 없음"
+// @Failure      404  {object}  errors.AppError         "웹훅을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /webhooks/{id}/test [post]
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	delivery, err := h.webhookService.TestWebhook(c.Request.Context(), id)
+	if err != nil {
+		handleWebhookError(c, err, map[string]interface{}{"webhook_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    delivery,
+	})
+}
+
+// ListWebhookDeliveries godoc
+// @Summary      웹훅 전달 기록 조회
+// @Description  웹훅의 전달 기록을 최신순으로 조회. 이벤트와 상태(pending, retrying, success, failed)로 필터링 가능. 관리자 권한 필요
+// @Tags         웹훅
+// @Produce      json
+// @Param        id         path      string  true   "웹훅 ID"
+// @Param        event      query     string  false  "이벤트 유형"
+// @Param        status     query     string  false  "전달 상태"
+// @Param        page       query     int     false  "페이지 번호"
+// @Param        page_size  query     int     false  "페이지당 개수"
+// @Success      200        {object}  map[string]interface{}  "전달 기록 목록"
+// @Failure      400        {object}  errors.AppError         "요청 매개변수 오류"
+// @Failure      403        {object}  errors.AppError         "권한 없음"
+// @Failure      404        {object}  errors.AppError         "웹훅을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	var filter types.WebhookDeliveryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid request parameters").WithDetails(err.Error()))
+		return
+	}
+	var page types.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.Error(errors.NewBadRequestError("Invalid pagination parameters").WithDetails(err.Error()))
+		return
+	}
+
+	result, err := h.webhookService.ListDeliveries(c.Request.Context(), id, &filter, &page)
+	if err != nil {
+		handleWebhookError(c, err, map[string]interface{}{"webhook_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetWebhookDelivery godoc
+// @Summary      웹훅 전달 기록 상세 조회
+// @Description  전송한 본문과 마지막 응답을 포함한 전달 기록 조회. 관리자 권한 필요
+// @Tags         웹훅
+// @Produce      json
+// @Param        id           path      string  true  "웹훅 ID"
+// @Param        delivery_id  path      string  true  "전달 ID"
+// @Success      200          {object}  map[string]interface{}  "전달 기록"
+// @Failure      403          {object}  errors.AppError         "권한 없음"
+// @Failure      404          {object}  errors.AppError         "전달 기록을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /webhooks/{id}/deliveries/{delivery_id} [get]
+func (h *WebhookHandler) GetWebhookDelivery(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	deliveryID := secutils.SanitizeForLog(c.Param("delivery_id"))
+	delivery, err := h.webhookService.GetDelivery(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		handleWebhookError(c, err, map[string]interface{}{"webhook_id": id, "delivery_id": deliveryID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    delivery,
+	})
+}
+
+// RedeliverWebhook godoc
+// @Summary      웹훅 재전송
+// @Description  전달 기록의 이벤트를 같은 본문과 이벤트 ID로 다시 전송. 새 전달 기록이 생성됨. 관리자 권한 필요
+// @Tags         웹훅
+// @Produce      json
+// @Param        id           path      string  true  "웹훅 ID"
+// @Param        delivery_id  path      string  true  "전달 ID"
+// @Success      200          {object}  map[string]interface{}  "새 전달 기록"
+// @Failure      403          {object}  errors.AppError         "권한 없음"
+// @Failure      404          {object}  errors.AppError         "전달 기록을 찾을 수 없음"
+// @Security     Bearer
+// @Security     ApiKeyAuth
+// @Router       /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	id := secutils.SanitizeForLog(c.Param("id"))
+	deliveryID := secutils.SanitizeForLog(c.Param("delivery_id"))
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		handleWebhookError(c, err, map[string]interface{}{"webhook_id": id, "delivery_id": deliveryID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    delivery,
+	})
+}
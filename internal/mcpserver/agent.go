@@ -0,0 +1,254 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/Tencent/WeKnora/internal/event"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+)
+
+// sessionTitleLength is the number of runes of the question used as the title of a new session
+const sessionTitleLength = 50
+
+// agentReference is a knowledge reference of an agent answer
+type agentReference struct {
+	ChunkID        string  `json:"chunk_id"`
+	KnowledgeID    string  `json:"knowledge_id"`
+	KnowledgeTitle string  `json:"knowledge_title"`
+	Score          float64 `json:"score"`
+}
+
+// agentAnswer is the result of ask_agent
+type agentAnswer struct {
+	SessionID  string           `json:"session_id"`
+	MessageID  string           `json:"message_id"`
+	Answer     string           `json:"answer"`
+	References []agentReference `json:"references,omitempty"`
+}
+
+// answerCollector collects the answer and references emitted while an agent runs
+type answerCollector struct {
+	agentMode bool
+	done      chan struct{}
+
+	mu         sync.Mutex
+	finished   bool
+	err        string
+	answer     strings.Builder
+	references []*types.SearchResult
+	seen       map[string]bool
+}
+
+// newAnswerCollector creates a collector subscribed to the event bus of one run
+func newAnswerCollector(eventBus *event.EventBus, agentMode bool) *answerCollector {
+	c := &answerCollector{
+		agentMode: agentMode,
+		done:      make(chan struct{}),
+		seen:      make(map[string]bool),
+	}
+	eventBus.On(event.EventAgentReferences, c.handleReferences)
+	eventBus.On(event.EventAgentFinalAnswer, c.handleFinalAnswer)
+	eventBus.On(event.EventAgentComplete, func(ctx context.Context, evt event.Event) error {
+		c.finish("")
+		return nil
+	})
+	eventBus.On(event.EventError, func(ctx context.Context, evt event.Event) error {
+		if data, ok := evt.Data.(event.ErrorData); ok {
+			c.finish(data.Error)
+		}
+		return nil
+	})
+	return c
+}
+
+// finish marks the run as finished, only the first call counts
+func (c *answerCollector) finish(err string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.finished {
+		return
+	}
+	c.finished = true
+	c.err = err
+	close(c.done)
+}
+
+// handleReferences collects the references not seen yet
+func (c *answerCollector) handleReferences(ctx context.Context, evt event.Event) error {
+	data, ok := evt.Data.(event.AgentReferencesData)
+	if !ok {
+		return nil
+	}
+	var results []*types.SearchResult
+	switch refs := data.References.(type) {
+	case []*types.SearchResult:
+		results = refs
+	case []interface{}:
+		for _, ref := range refs {
+			if result, ok := ref.(*types.SearchResult); ok {
+				results = append(results, result)
+			}
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, result := range results {
+		if result == nil || c.seen[result.ID] {
+			continue
+		}
+		c.seen[result.ID] = true
+		c.references = append(c.references, result)
+	}
+	return nil
+}
+
+// handleFinalAnswer collects answer chunks, knowledge QA finishes with the last chunk
+func (c *answerCollector) handleFinalAnswer(ctx context.Context, evt event.Event) error {
+	data, ok := evt.Data.(event.AgentFinalAnswerData)
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	c.answer.WriteString(data.Content)
+	c.mu.Unlock()
+	if data.Done && !c.agentMode {
+		c.finish("")
+	}
+	return nil
+}
+
+// result returns the collected answer, references and error
+func (c *answerCollector) result() (string, []*types.SearchResult, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.answer.String(), c.references, c.err
+}
+
+// askAgent runs an agent on a question and waits for its answer.
+// Agents in agent mode run the AgentEngine, the others run the knowledge QA pipeline, as in the chat API.
+func (s *Server) askAgent(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireScope(ctx, types.APIKeyScopeChat); err != nil {
+		return toolError(err), nil
+	}
+	agentID, err := request.RequireString("agent_id")
+	if err != nil {
+		return mcp.NewToolResultError("agent_id is required"), nil
+	}
+	query, err := request.RequireString("query")
+	if err != nil || strings.TrimSpace(query) == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+	query = secutils.SanitizeForLog(strings.TrimSpace(query))
+
+	if err := s.permissionService.CheckPermission(
+		ctx, types.ResourceTypeAgent, agentID, types.PermissionRead,
+	); err != nil {
+		return toolError(err), nil
+	}
+	customAgent, err := s.customAgentService.GetAgentByID(ctx, agentID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("agent %s not found", agentID)), nil
+	}
+	agentMode := customAgent.IsAgentMode()
+
+	session, err := s.resolveSession(ctx, request.GetString("session_id", ""), query)
+	if err != nil {
+		return toolError(err), nil
+	}
+	logger.Infof(ctx, "[MCP] ask_agent, session ID: %s, agent ID: %s, agent mode: %v", session.ID, agentID, agentMode)
+
+	requestID := uuid.New().String()
+	if _, err := s.messageService.CreateMessage(ctx, &types.Message{
+		SessionID:   session.ID,
+		Role:        "user",
+		Content:     query,
+		RequestID:   requestID,
+		CreatedAt:   time.Now(),
+		IsCompleted: true,
+	}); err != nil {
+		return toolError(err), nil
+	}
+	assistantMessage, err := s.messageService.CreateMessage(ctx, &types.Message{
+		SessionID: session.ID,
+		Role:      "assistant",
+		RequestID: requestID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	// the run is cancelled when the call is cancelled or the client disconnects
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	eventBus := event.NewEventBus()
+	collector := newAnswerCollector(eventBus, agentMode)
+	go func() {
+		var err error
+		if agentMode {
+			err = s.sessionService.AgentQA(runCtx, session, query, assistantMessage.ID, "", eventBus, customAgent, nil, nil)
+		} else {
+			err = s.sessionService.KnowledgeQA(runCtx, session, query, nil, nil, assistantMessage.ID, "",
+				customAgent.Config.WebSearchEnabled, eventBus, customAgent)
+		}
+		if err != nil {
+			logger.ErrorWithFields(runCtx, err, nil)
+			collector.finish(err.Error())
+		}
+	}()
+
+	select {
+	case <-collector.done:
+	case <-ctx.Done():
+		collector.finish(ctx.Err().Error())
+	}
+	answer, references, runErr := collector.result()
+
+	assistantMessage.Content = answer
+	assistantMessage.KnowledgeReferences = references
+	assistantMessage.UpdatedAt = time.Now()
+	assistantMessage.IsCompleted = true
+	if err := s.messageService.UpdateMessage(context.WithoutCancel(ctx), assistantMessage); err != nil {
+		logger.Warnf(ctx, "[MCP] Failed to complete assistant message %s: %v", assistantMessage.ID, err)
+	}
+	if runErr != "" {
+		return mcp.NewToolResultError(runErr), nil
+	}
+
+	result := agentAnswer{SessionID: session.ID, MessageID: assistantMessage.ID, Answer: answer}
+	for _, ref := range references {
+		result.References = append(result.References, agentReference{
+			ChunkID:        ref.ID,
+			KnowledgeID:    ref.KnowledgeID,
+			KnowledgeTitle: ref.KnowledgeTitle,
+			Score:          ref.Score,
+		})
+	}
+	return jsonResult(result)
+}
+
+// resolveSession returns the given session, or creates one titled after the question
+func (s *Server) resolveSession(ctx context.Context, sessionID, query string) (*types.Session, error) {
+	if sessionID != "" {
+		session, err := s.sessionService.GetSession(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("session %s not found", sessionID)
+		}
+		return session, nil
+	}
+	title := []rune(query)
+	if len(title) > sessionTitleLength {
+		title = title[:sessionTitleLength]
+	}
+	tenantID, _ := ctx.Value(types.TenantIDContextKey).(uint64)
+	return s.sessionService.CreateSession(ctx, &types.Session{TenantID: tenantID, Title: string(title)})
+}
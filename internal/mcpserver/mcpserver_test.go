@@ -0,0 +1,61 @@
+package mcpserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+func TestDocumentText(t *testing.T) {
+	chunks := []*types.Chunk{
+		{ChunkIndex: 2, Content: "third", ChunkType: types.ChunkTypeText, IsEnabled: true},
+		{ChunkIndex: 0, Content: "first", ChunkType: types.ChunkTypeText, IsEnabled: true},
+		{ChunkIndex: 1, Content: "disabled", ChunkType: types.ChunkTypeText},
+		{ChunkIndex: 1, Content: "summary", ChunkType: types.ChunkTypeSummary, IsEnabled: true},
+		{ChunkIndex: 1, Content: "second", ChunkType: types.ChunkTypeText, IsEnabled: true},
+	}
+	if text := documentText(chunks); text != "first\n\nsecond\n\nthird" {
+		t.Errorf("unexpected document text: %q", text)
+	}
+	if text := documentText(nil); text != "" {
+		t.Errorf("a document without chunks should be empty, got %q", text)
+	}
+}
+
+func TestTemplateArgument(t *testing.T) {
+	request := mcp.ReadResourceRequest{}
+	request.Params.Arguments = map[string]any{"id": []string{"kb-1"}, "name": "n"}
+	if id := templateArgument(request, "id"); id != "kb-1" {
+		t.Errorf("expected kb-1, got %q", id)
+	}
+	if name := templateArgument(request, "name"); name != "n" {
+		t.Errorf("expected n, got %q", name)
+	}
+	if missing := templateArgument(request, "missing"); missing != "" {
+		t.Errorf("expected empty, got %q", missing)
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	if err := requireScope(context.Background(), types.APIKeyScopeIngest); err != nil {
+		t.Errorf("calls without an API key are left to the permission service: %v", err)
+	}
+
+	searchKey := &types.TenantAPIKey{Scopes: []string{string(types.APIKeyScopeSearch)}}
+	ctx := context.WithValue(context.Background(), types.APIKeyContextKey, searchKey)
+	if err := requireScope(ctx, readScopes...); err != nil {
+		t.Errorf("a search key should read: %v", err)
+	}
+	if err := requireScope(ctx, types.APIKeyScopeIngest); err == nil {
+		t.Error("a search key should not ingest")
+	}
+
+	adminKey := &types.TenantAPIKey{Scopes: []string{string(types.APIKeyScopeAdmin)}}
+	ctx = context.WithValue(context.Background(), types.APIKeyContextKey, adminKey)
+	if err := requireScope(ctx, types.APIKeyScopeChat); err != nil {
+		t.Errorf("an admin key has every scope: %v", err)
+	}
+}
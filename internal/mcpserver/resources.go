@@ -0,0 +1,200 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+const (
+	// knowledgeBasesURI lists the readable knowledge bases
+	knowledgeBasesURI = "weknora://knowledge-bases"
+	// knowledgeBaseURITemplate lists the documents of a knowledge base
+	knowledgeBaseURITemplate = "weknora://knowledge-bases/{id}"
+	// knowledgeURITemplate is the text of a document
+	knowledgeURITemplate = "weknora://knowledge/{id}"
+	// knowledgeURIPrefix is the prefix of document URIs
+	knowledgeURIPrefix = "weknora://knowledge/"
+
+	// maxListedDocuments caps the documents listed by a knowledge base resource
+	maxListedDocuments = 100
+)
+
+// documentSummary is a document as listed by a knowledge base resource
+type documentSummary struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	FileName    string `json:"file_name,omitempty"`
+	Type        string `json:"type"`
+	Source      string `json:"source,omitempty"`
+	ParseStatus string `json:"parse_status"`
+	URI         string `json:"uri"`
+}
+
+// knowledgeBaseDocuments is the content of a knowledge base resource
+type knowledgeBaseDocuments struct {
+	knowledgeBaseSummary
+	Total     int64             `json:"total"`
+	Documents []documentSummary `json:"documents"`
+}
+
+// knowledgeURI returns the resource URI of a document
+func knowledgeURI(knowledgeID string) string {
+	return knowledgeURIPrefix + knowledgeID
+}
+
+// registerResources registers the knowledge base and document resources
+func (s *Server) registerResources() {
+	s.mcp.AddResource(mcp.NewResource(knowledgeBasesURI, "Knowledge bases",
+		mcp.WithResourceDescription("The knowledge bases the caller can read"),
+		mcp.WithMIMEType("application/json"),
+	), s.readKnowledgeBases)
+
+	s.mcp.AddResourceTemplate(mcp.NewResourceTemplate(knowledgeBaseURITemplate, "Knowledge base documents",
+		mcp.WithTemplateDescription(fmt.Sprintf(
+			"The most recent %d documents of a knowledge base with their parse status", maxListedDocuments)),
+		mcp.WithTemplateMIMEType("application/json"),
+	), s.readKnowledgeBase)
+
+	s.mcp.AddResourceTemplate(mcp.NewResourceTemplate(knowledgeURITemplate, "Document",
+		mcp.WithTemplateDescription("The parsed text of a document, in chunk order"),
+		mcp.WithTemplateMIMEType("text/markdown"),
+	), s.readKnowledge)
+}
+
+// readKnowledgeBases returns the readable knowledge bases
+func (s *Server) readKnowledgeBases(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	if err := requireScope(ctx, readScopes...); err != nil {
+		return nil, err
+	}
+	kbs, err := s.readableKnowledgeBases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]knowledgeBaseSummary, 0, len(kbs))
+	for _, kb := range kbs {
+		summaries = append(summaries, knowledgeBaseSummary{
+			ID: kb.ID, Name: kb.Name, Type: kb.Type, Description: kb.Description,
+		})
+	}
+	return jsonContents(request.Params.URI, summaries)
+}
+
+// readKnowledgeBase returns the documents of a readable knowledge base
+func (s *Server) readKnowledgeBase(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	if err := requireScope(ctx, readScopes...); err != nil {
+		return nil, err
+	}
+	kbID := templateArgument(request, "id")
+	if err := s.permissionService.CheckPermission(
+		ctx, types.ResourceTypeKnowledgeBase, kbID, types.PermissionRead,
+	); err != nil {
+		return nil, err
+	}
+	kb, err := s.kbService.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge base %s not found", kbID)
+	}
+	page, err := s.knowledgeService.ListPagedKnowledgeByKnowledgeBaseID(ctx, kbID,
+		&types.Pagination{Page: 1, PageSize: maxListedDocuments}, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	knowledgeList, _ := page.Data.([]*types.Knowledge)
+
+	result := knowledgeBaseDocuments{
+		knowledgeBaseSummary: knowledgeBaseSummary{
+			ID: kb.ID, Name: kb.Name, Type: kb.Type, Description: kb.Description,
+		},
+		Total:     page.Total,
+		Documents: make([]documentSummary, 0, len(knowledgeList)),
+	}
+	for _, knowledge := range knowledgeList {
+		result.Documents = append(result.Documents, documentSummary{
+			ID:          knowledge.ID,
+			Title:       knowledge.Title,
+			FileName:    knowledge.FileName,
+			Type:        knowledge.Type,
+			Source:      knowledge.Source,
+			ParseStatus: knowledge.ParseStatus,
+			URI:         knowledgeURI(knowledge.ID),
+		})
+	}
+	return jsonContents(request.Params.URI, result)
+}
+
+// readKnowledge returns the text of a document of a readable knowledge base
+func (s *Server) readKnowledge(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	if err := requireScope(ctx, readScopes...); err != nil {
+		return nil, err
+	}
+	knowledgeID := templateArgument(request, "id")
+	knowledge, err := s.knowledgeService.GetKnowledgeByID(ctx, knowledgeID)
+	if err != nil {
+		return nil, fmt.Errorf("document %s not found", knowledgeID)
+	}
+	if err := s.permissionService.CheckPermission(
+		ctx, types.ResourceTypeKnowledgeBase, knowledge.KnowledgeBaseID, types.PermissionRead,
+	); err != nil {
+		return nil, err
+	}
+	chunks, err := s.chunkService.ListChunksByKnowledgeID(ctx, knowledgeID)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      request.Params.URI,
+		MIMEType: "text/markdown",
+		Text:     documentText(chunks),
+	}}, nil
+}
+
+// documentText joins the enabled text chunks of a document in chunk order.
+// Summaries, generated questions and image chunks are derived from the text and left out.
+func documentText(chunks []*types.Chunk) string {
+	texts := make([]*types.Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.ChunkType == types.ChunkTypeText && chunk.IsEnabled {
+			texts = append(texts, chunk)
+		}
+	}
+	sort.SliceStable(texts, func(i, j int) bool { return texts[i].ChunkIndex < texts[j].ChunkIndex })
+
+	parts := make([]string, 0, len(texts))
+	for _, chunk := range texts {
+		parts = append(parts, chunk.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// templateArgument returns a variable of the matched URI template
+func templateArgument(request mcp.ReadResourceRequest, name string) string {
+	switch value := request.Params.Arguments[name].(type) {
+	case string:
+		return value
+	case []string:
+		if len(value) > 0 {
+			return value[0]
+		}
+	}
+	return ""
+}
+
+// jsonContents returns v as the JSON content of a resource
+func jsonContents(uri string, v interface{}) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      uri,
+		MIMEType: "application/json",
+		Text:     string(data),
+	}}, nil
+}
@@ -0,0 +1,134 @@
+// Package mcpserver exposes WeKnora itself as an MCP server, so that MCP clients such as IDE agents
+// can search knowledge bases, read documents, ingest URLs and ask agents without a separate bridge.
+//
+// The server is served over streamable HTTP at /mcp by the main router, and over stdio by the
+// "mcp-stdio" command of the server binary. Both transports authenticate with tenant API keys:
+// over HTTP through the auth middleware, over stdio through the WEKNORA_API_KEY environment variable.
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+const (
+	// serverName is the implementation name reported to MCP clients
+	serverName = "weknora"
+	// serverVersion is the implementation version reported to MCP clients
+	serverVersion = "1.0.0"
+	// EndpointPath is the path the streamable HTTP transport is mounted at
+	EndpointPath = "/mcp"
+)
+
+// serverInstructions tells MCP clients how the tools fit together
+const serverInstructions = `WeKnora is a knowledge base system.
+Use list_knowledge_bases to find knowledge bases, search_knowledge to retrieve relevant chunks,
+get_chunk to read a chunk in full and ask_agent to get an answer written by a WeKnora agent.
+Documents can be read as resources: weknora://knowledge-bases/{id} lists the documents of a
+knowledge base and weknora://knowledge/{id} returns the text of a document.`
+
+// Server is the MCP server of WeKnora, it runs every call with the tenant and API key of the caller
+type Server struct {
+	mcp *server.MCPServer
+
+	kbService          interfaces.KnowledgeBaseService
+	knowledgeService   interfaces.KnowledgeService
+	chunkService       interfaces.ChunkService
+	sessionService     interfaces.SessionService
+	messageService     interfaces.MessageService
+	customAgentService interfaces.CustomAgentService
+	permissionService  interfaces.PermissionService
+	auditService       interfaces.AuditService
+	apiKeyService      interfaces.APIKeyService
+	tenantService      interfaces.TenantService
+}
+
+// NewServer creates the MCP server and registers its tools and resources
+func NewServer(
+	kbService interfaces.KnowledgeBaseService,
+	knowledgeService interfaces.KnowledgeService,
+	chunkService interfaces.ChunkService,
+	sessionService interfaces.SessionService,
+	messageService interfaces.MessageService,
+	customAgentService interfaces.CustomAgentService,
+	permissionService interfaces.PermissionService,
+	auditService interfaces.AuditService,
+	apiKeyService interfaces.APIKeyService,
+	tenantService interfaces.TenantService,
+) *Server {
+	s := &Server{
+		mcp: server.NewMCPServer(serverName, serverVersion,
+			server.WithToolCapabilities(false),
+			server.WithResourceCapabilities(false, false),
+			server.WithInstructions(serverInstructions),
+			server.WithRecovery(),
+		),
+		kbService:          kbService,
+		knowledgeService:   knowledgeService,
+		chunkService:       chunkService,
+		sessionService:     sessionService,
+		messageService:     messageService,
+		customAgentService: customAgentService,
+		permissionService:  permissionService,
+		auditService:       auditService,
+		apiKeyService:      apiKeyService,
+		tenantService:      tenantService,
+	}
+	s.registerTools()
+	s.registerResources()
+	return s
+}
+
+// HTTPHandler returns the streamable HTTP transport.
+// It is stateless, every request carries its own credentials and is authenticated by the auth middleware,
+// which has put the tenant, user and API key into the request context.
+func (s *Server) HTTPHandler() http.Handler {
+	return server.NewStreamableHTTPServer(s.mcp,
+		server.WithStateLess(true),
+		server.WithEndpointPath(EndpointPath),
+	)
+}
+
+// ServeStdio serves the stdio transport until stdin is closed or ctx is cancelled.
+// ctx must already be authenticated, see AuthenticateAPIKey.
+func (s *Server) ServeStdio(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	return server.NewStdioServer(s.mcp).Listen(ctx, stdin, stdout)
+}
+
+// AuthenticateAPIKey authenticates a tenant API key and returns ctx with the tenant and the key,
+// the same values the auth middleware sets for requests authenticated with the key
+func (s *Server) AuthenticateAPIKey(ctx context.Context, apiKey string) (context.Context, error) {
+	if !s.apiKeyService.IsAPIKey(apiKey) {
+		return nil, werrors.NewUnauthorizedError("invalid API key")
+	}
+	key, err := s.apiKeyService.Authenticate(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := s.tenantService.GetTenantByID(ctx, key.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant %d of API key: %w", key.TenantID, err)
+	}
+	ctx = context.WithValue(ctx, types.TenantIDContextKey, key.TenantID)
+	ctx = context.WithValue(ctx, types.TenantInfoContextKey, tenant)
+	ctx = context.WithValue(ctx, types.APIKeyContextKey, key)
+	return ctx, nil
+}
+
+// requireScope checks that the API key of the call, if any, has one of the scopes.
+// Calls authenticated as a user have no key and are checked by the permission service only.
+func requireScope(ctx context.Context, scopes ...types.APIKeyScope) error {
+	key := types.APIKeyFromContext(ctx)
+	if key == nil || key.HasScope(scopes...) {
+		return nil
+	}
+	return werrors.NewForbiddenError(fmt.Sprintf("API key scope does not allow this call, one of %v is required", scopes))
+}
@@ -0,0 +1,334 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/Tencent/WeKnora/internal/application/service/audit"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/logger"
+	"github.com/Tencent/WeKnora/internal/types"
+	secutils "github.com/Tencent/WeKnora/internal/utils"
+)
+
+const (
+	// defaultSearchLimit is the number of chunks search_knowledge returns when no limit is given
+	defaultSearchLimit = 10
+	// maxSearchLimit caps the limit argument of search_knowledge
+	maxSearchLimit = 50
+)
+
+// readScopes are the API key scopes that may read knowledge bases, documents and chunks
+var readScopes = []types.APIKeyScope{types.APIKeyScopeSearch, types.APIKeyScopeIngest}
+
+// knowledgeBaseSummary is a knowledge base as returned to MCP clients
+type knowledgeBaseSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// searchHit is a retrieved chunk as returned by search_knowledge
+type searchHit struct {
+	ChunkID        string  `json:"chunk_id"`
+	KnowledgeID    string  `json:"knowledge_id"`
+	KnowledgeTitle string  `json:"knowledge_title"`
+	ChunkIndex     int     `json:"chunk_index"`
+	Score          float64 `json:"score"`
+	Content        string  `json:"content"`
+}
+
+// chunkDetail is a chunk as returned by get_chunk
+type chunkDetail struct {
+	ChunkID         string `json:"chunk_id"`
+	KnowledgeID     string `json:"knowledge_id"`
+	KnowledgeBaseID string `json:"knowledge_base_id"`
+	KnowledgeTitle  string `json:"knowledge_title,omitempty"`
+	ChunkIndex      int    `json:"chunk_index"`
+	Content         string `json:"content"`
+	DocumentURI     string `json:"document_uri"`
+}
+
+// ingestedKnowledge is a document created by ingest_url
+type ingestedKnowledge struct {
+	KnowledgeID     string `json:"knowledge_id"`
+	KnowledgeBaseID string `json:"knowledge_base_id"`
+	Title           string `json:"title"`
+	ParseStatus     string `json:"parse_status"`
+	Duplicate       bool   `json:"duplicate,omitempty"`
+	DocumentURI     string `json:"document_uri"`
+}
+
+// registerTools registers the tools of the server
+func (s *Server) registerTools() {
+	s.mcp.AddTool(mcp.NewTool("list_knowledge_bases",
+		mcp.WithDescription("List the knowledge bases the caller can read."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), s.listKnowledgeBases)
+
+	s.mcp.AddTool(mcp.NewTool("search_knowledge",
+		mcp.WithDescription("Search knowledge bases with hybrid vector and keyword retrieval. "+
+			"Returns the most relevant chunks with their document titles and scores."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("query", mcp.Required(), mcp.Description("The search query")),
+		mcp.WithArray("knowledge_base_ids", mcp.WithStringItems(),
+			mcp.Description("Knowledge bases to search, all readable knowledge bases when omitted")),
+		mcp.WithArray("knowledge_ids", mcp.WithStringItems(),
+			mcp.Description("Restrict the search to these documents")),
+		mcp.WithNumber("limit", mcp.Min(1), mcp.Max(maxSearchLimit),
+			mcp.Description(fmt.Sprintf("Maximum number of chunks to return, %d by default", defaultSearchLimit))),
+	), s.searchKnowledge)
+
+	s.mcp.AddTool(mcp.NewTool("get_chunk",
+		mcp.WithDescription("Get the full content of a chunk returned by search_knowledge."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("chunk_id", mcp.Required(), mcp.Description("The chunk ID")),
+	), s.getChunk)
+
+	s.mcp.AddTool(mcp.NewTool("ask_agent",
+		mcp.WithDescription("Ask a WeKnora agent a question. The agent retrieves from its knowledge bases "+
+			"and runs its own tools on the server, then answers with references. "+
+			"Pass the returned session_id to continue the conversation."),
+		mcp.WithString("agent_id", mcp.Required(), mcp.Description("The agent ID")),
+		mcp.WithString("query", mcp.Required(), mcp.Description("The question")),
+		mcp.WithString("session_id", mcp.Description("A session returned by an earlier call, a new session is created when omitted")),
+	), s.askAgent)
+
+	s.mcp.AddTool(mcp.NewTool("ingest_url",
+		mcp.WithDescription("Add a web page to a knowledge base. The page is fetched, parsed and indexed "+
+			"in the background, read the returned document URI to check its parse status."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("knowledge_base_id", mcp.Required(), mcp.Description("The knowledge base to add the page to")),
+		mcp.WithString("url", mcp.Required(), mcp.Description("The URL of the page")),
+		mcp.WithString("title", mcp.Description("The document title, taken from the page when omitted")),
+	), s.ingestURL)
+}
+
+// listKnowledgeBases lists the readable knowledge bases
+func (s *Server) listKnowledgeBases(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireScope(ctx, readScopes...); err != nil {
+		return toolError(err), nil
+	}
+	kbs, err := s.readableKnowledgeBases(ctx)
+	if err != nil {
+		return toolError(err), nil
+	}
+	summaries := make([]knowledgeBaseSummary, 0, len(kbs))
+	for _, kb := range kbs {
+		summaries = append(summaries, knowledgeBaseSummary{
+			ID:          kb.ID,
+			Name:        kb.Name,
+			Type:        kb.Type,
+			Description: kb.Description,
+		})
+	}
+	return jsonResult(summaries)
+}
+
+// searchKnowledge searches the given or all readable knowledge bases
+func (s *Server) searchKnowledge(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireScope(ctx, types.APIKeyScopeSearch); err != nil {
+		return toolError(err), nil
+	}
+	query, err := request.RequireString("query")
+	if err != nil || strings.TrimSpace(query) == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+	kbIDs := request.GetStringSlice("knowledge_base_ids", nil)
+	knowledgeIDs := request.GetStringSlice("knowledge_ids", nil)
+	limit := request.GetInt("limit", defaultSearchLimit)
+	if limit < 1 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+
+	if len(kbIDs) == 0 && len(knowledgeIDs) == 0 {
+		kbs, err := s.readableKnowledgeBases(ctx)
+		if err != nil {
+			return toolError(err), nil
+		}
+		for _, kb := range kbs {
+			kbIDs = append(kbIDs, kb.ID)
+		}
+		if len(kbIDs) == 0 {
+			return jsonResult([]searchHit{})
+		}
+	}
+
+	logger.Infof(ctx, "[MCP] search_knowledge, knowledge bases: %v, query: %s",
+		kbIDs, secutils.SanitizeForLog(query))
+	// unreadable knowledge bases are dropped when the search targets are built
+	results, err := s.sessionService.SearchKnowledge(ctx, kbIDs, knowledgeIDs, query, nil)
+	if err != nil {
+		logger.ErrorWithFields(ctx, err, nil)
+		return toolError(err), nil
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	hits := make([]searchHit, 0, len(results))
+	for _, result := range results {
+		hits = append(hits, searchHit{
+			ChunkID:        result.ID,
+			KnowledgeID:    result.KnowledgeID,
+			KnowledgeTitle: result.KnowledgeTitle,
+			ChunkIndex:     result.ChunkIndex,
+			Score:          result.Score,
+			Content:        result.Content,
+		})
+	}
+	return jsonResult(hits)
+}
+
+// getChunk returns a chunk of a readable knowledge base
+func (s *Server) getChunk(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireScope(ctx, readScopes...); err != nil {
+		return toolError(err), nil
+	}
+	chunkID, err := request.RequireString("chunk_id")
+	if err != nil {
+		return mcp.NewToolResultError("chunk_id is required"), nil
+	}
+	chunk, err := s.chunkService.GetChunkByID(ctx, chunkID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("chunk %s not found", chunkID)), nil
+	}
+	if err := s.permissionService.CheckPermission(
+		ctx, types.ResourceTypeKnowledgeBase, chunk.KnowledgeBaseID, types.PermissionRead,
+	); err != nil {
+		return toolError(err), nil
+	}
+
+	detail := chunkDetail{
+		ChunkID:         chunk.ID,
+		KnowledgeID:     chunk.KnowledgeID,
+		KnowledgeBaseID: chunk.KnowledgeBaseID,
+		ChunkIndex:      chunk.ChunkIndex,
+		Content:         chunk.Content,
+		DocumentURI:     knowledgeURI(chunk.KnowledgeID),
+	}
+	if knowledge, err := s.knowledgeService.GetKnowledgeByID(ctx, chunk.KnowledgeID); err == nil {
+		detail.KnowledgeTitle = knowledge.Title
+	}
+	return jsonResult(detail)
+}
+
+// ingestURL creates a document from a URL in a writable knowledge base
+func (s *Server) ingestURL(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireScope(ctx, types.APIKeyScopeIngest); err != nil {
+		return toolError(err), nil
+	}
+	kbID, err := request.RequireString("knowledge_base_id")
+	if err != nil {
+		return mcp.NewToolResultError("knowledge_base_id is required"), nil
+	}
+	url, err := request.RequireString("url")
+	if err != nil || strings.TrimSpace(url) == "" {
+		return mcp.NewToolResultError("url is required"), nil
+	}
+	title := request.GetString("title", "")
+
+	if err := s.permissionService.CheckPermission(
+		ctx, types.ResourceTypeKnowledgeBase, kbID, types.PermissionWrite,
+	); err != nil {
+		return toolError(err), nil
+	}
+
+	logger.Infof(ctx, "[MCP] ingest_url, knowledge base ID: %s, URL: %s",
+		secutils.SanitizeForLog(kbID), secutils.SanitizeForLog(url))
+	knowledge, err := s.knowledgeService.CreateKnowledgeFromURL(ctx, kbID, url, nil, title)
+	// an already ingested URL is reported as the existing document instead of an error
+	var dupErr *types.DuplicateKnowledgeError
+	if errors.As(err, &dupErr) && knowledge == nil {
+		knowledge = dupErr.Knowledge
+	}
+	if err != nil && (dupErr == nil || knowledge == nil) {
+		logger.ErrorWithFields(ctx, err, nil)
+		return toolError(err), nil
+	}
+	if dupErr == nil {
+		s.recordAudit(ctx, "ingest_url", "knowledge.create", types.AuditResourceKnowledge, knowledge.ID,
+			map[string]interface{}{"knowledge_base_id": kbID, "url": url})
+	}
+	return jsonResult(ingestedKnowledge{
+		KnowledgeID:     knowledge.ID,
+		KnowledgeBaseID: knowledge.KnowledgeBaseID,
+		Title:           knowledge.Title,
+		ParseStatus:     knowledge.ParseStatus,
+		Duplicate:       dupErr != nil,
+		DocumentURI:     knowledgeURI(knowledge.ID),
+	})
+}
+
+// readableKnowledgeBases lists the knowledge bases of the tenant that the caller can read
+func (s *Server) readableKnowledgeBases(ctx context.Context) ([]*types.KnowledgeBase, error) {
+	kbs, err := s.kbService.ListKnowledgeBases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(kbs))
+	for _, kb := range kbs {
+		ids = append(ids, kb.ID)
+	}
+	allowed, err := s.permissionService.FilterAccessible(ctx, types.ResourceTypeKnowledgeBase, ids, types.PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	readable := make(map[string]bool, len(allowed))
+	for _, id := range allowed {
+		readable[id] = true
+	}
+	result := make([]*types.KnowledgeBase, 0, len(allowed))
+	for _, kb := range kbs {
+		if readable[kb.ID] {
+			result = append(result, kb)
+		}
+	}
+	return result, nil
+}
+
+// recordAudit records a change made by a tool.
+// The audit middleware only sees the MCP endpoint, so write tools record their changes themselves.
+func (s *Server) recordAudit(ctx context.Context, tool, action, resourceType, resourceID string,
+	detail map[string]interface{},
+) {
+	detail["tool"] = tool
+	entry := &types.AuditLog{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Method:       "MCP",
+		Path:         EndpointPath,
+		StatusCode:   200,
+		Detail:       audit.Encode(detail),
+	}
+	if types.APIKeyFromContext(ctx) == nil && types.AccessSubjectFromContext(ctx) == nil {
+		entry.ActorType = types.AuditActorTenantKey
+	}
+	if err := s.auditService.Record(context.WithoutCancel(ctx), entry); err != nil {
+		logger.Warnf(ctx, "[MCP] Failed to record audit log %s: %v", action, err)
+	}
+}
+
+// jsonResult returns v as indented JSON text
+func jsonResult(v interface{}) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// toolError reports an error to the MCP client as a failed tool result, so the calling model can react to it.
+// Application errors are reported with their message only.
+func toolError(err error) *mcp.CallToolResult {
+	if appErr, ok := werrors.IsAppError(err); ok {
+		return mcp.NewToolResultError(appErr.Message)
+	}
+	return mcp.NewToolResultError(err.Error())
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// apiKeyScopeRule 경로와 메서드에 필요한 API 키 범위, 하나라도 가지고 있으면 허용
+type apiKeyScopeRule struct {
+	path    string
+	methods []string
+	scopes  []types.APIKeyScope
+}
+
+var (
+	readMethods  = []string{"GET"}
+	writeMethods = []string{"POST", "PUT", "DELETE"}
+	allMethods   = []string{"GET", "POST", "PUT", "DELETE"}
+)
+
+// API 키 범위 규칙, 위에서부터 처음 일치하는 규칙을 적용합니다.
+// 경로는 라우트 경로이며 *로 끝나면 접두사로 일치시킵니다. 일치하는 규칙이 없으면 admin 범위가 필요합니다.
+var apiKeyScopeRules = []apiKeyScopeRule{
+	// 검색
+	{"/api/v1/knowledge-search", []string{"POST"}, []types.APIKeyScope{types.APIKeyScopeSearch}},
+	{"/api/v1/knowledge-bases/:id/faq/search", []string{"POST"}, []types.APIKeyScope{types.APIKeyScopeSearch}},
+	{"/api/v1/knowledge-bases/:id/hybrid-search", readMethods, []types.APIKeyScope{types.APIKeyScopeSearch}},
+
+	// 지식 편집
+	{"/api/v1/knowledge-bases/:id/knowledge*", writeMethods, []types.APIKeyScope{types.APIKeyScopeIngest}},
+	{"/api/v1/knowledge-bases/:id/faq/*", writeMethods, []types.APIKeyScope{types.APIKeyScopeIngest}},
+	{"/api/v1/knowledge-bases/:id/tags*", writeMethods, []types.APIKeyScope{types.APIKeyScopeIngest}},
+	{"/api/v1/knowledge-bases/:id/connectors*", writeMethods, []types.APIKeyScope{types.APIKeyScopeIngest}},
+	{"/api/v1/knowledge/*", writeMethods, []types.APIKeyScope{types.APIKeyScopeIngest}},
+	{"/api/v1/chunks/*", writeMethods, []types.APIKeyScope{types.APIKeyScopeIngest}},
+	{"/api/v1/connectors/*", writeMethods, []types.APIKeyScope{types.APIKeyScopeIngest}},
+
+	// 조회
+	{"/api/v1/knowledge-bases/copy/*", readMethods, []types.APIKeyScope{types.APIKeyScopeAdmin}},
+	{"/api/v1/knowledge-bases*", readMethods, []types.APIKeyScope{types.APIKeyScopeSearch, types.APIKeyScopeIngest}},
+	{"/api/v1/knowledge/*", readMethods, []types.APIKeyScope{types.APIKeyScopeSearch, types.APIKeyScopeIngest}},
+	{"/api/v1/chunks/*", readMethods, []types.APIKeyScope{types.APIKeyScopeSearch, types.APIKeyScopeIngest}},
+	{"/api/v1/connectors/*", readMethods, []types.APIKeyScope{types.APIKeyScopeSearch, types.APIKeyScopeIngest}},
+	{"/api/v1/faq/import/*", readMethods, []types.APIKeyScope{types.APIKeyScopeSearch, types.APIKeyScopeIngest}},
+
+	// 대화
+	{"/api/v1/sessions*", allMethods, []types.APIKeyScope{types.APIKeyScopeChat}},
+	{"/api/v1/messages/*", allMethods, []types.APIKeyScope{types.APIKeyScopeChat}},
+	{"/api/v1/knowledge-chat/*", []string{"POST"}, []types.APIKeyScope{types.APIKeyScopeChat}},
+	{"/api/v1/agent-chat/*", []string{"POST"}, []types.APIKeyScope{types.APIKeyScopeChat}},
+	{"/api/v1/agents*", readMethods, []types.APIKeyScope{types.APIKeyScopeChat}},
+	{"/v1/chat/completions", []string{"POST"}, []types.APIKeyScope{types.APIKeyScopeChat}},
+	{"/v1/models", readMethods, []types.APIKeyScope{types.APIKeyScopeChat}},
+	// MCP 서버, 도구와 리소스별 범위는 MCP 서버에서 검사
+	{"/mcp", allMethods, []types.APIKeyScope{
+		types.APIKeyScopeSearch, types.APIKeyScopeIngest, types.APIKeyScopeChat,
+	}},
+
+	// 자신의 할당량 조회
+	{"/api/v1/quotas", readMethods, []types.APIKeyScope{
+		types.APIKeyScopeSearch, types.APIKeyScopeIngest, types.APIKeyScopeChat,
+	}},
+}
+
+// matchRoute 경로가 규칙 경로와 일치하는지 확인, *로 끝나면 접두사 일치
+func matchRoute(pattern string, path string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(path, strings.TrimSuffix(pattern, "*"))
+	}
+	return path == pattern
+}
+
+// requiredAPIKeyScopes 라우트 경로와 메서드에 필요한 API 키 범위를 반환합니다.
+func requiredAPIKeyScopes(path string, method string) []types.APIKeyScope {
+	for _, rule := range apiKeyScopeRules {
+		if matchRoute(rule.path, path) && slices.Contains(rule.methods, method) {
+			return rule.scopes
+		}
+	}
+	return []types.APIKeyScope{types.APIKeyScopeAdmin}
+}
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tencent/WeKnora/internal/application/service/audit"
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// auditBodyLimit 감사 기록을 위해 읽는 요청 및 응답 본문의 최대 크기
+const auditBodyLimit = 64 << 10
+
+// AuditSnapshotFunc 리소스의 현재 상태를 조회합니다. 변경 전후 비교에 사용됩니다.
+type AuditSnapshotFunc func(c *gin.Context, id string) (interface{}, error)
+
+// AuditRoute 감사 대상 라우트의 기록 방법
+type AuditRoute struct {
+	// 작업 이름, 예: knowledge_base.delete
+	Action string
+	// 리소스 유형
+	ResourceType string
+	// 리소스 ID 경로 매개변수, 비어 있으면 응답의 data.id 사용
+	IDParam string
+	// 리소스 상태 조회 함수, 있으면 요청 전후 상태의 차이를 기록하고 없으면 요청 본문을 기록
+	Snapshot AuditSnapshotFunc
+	// 요청 본문을 기록하지 않음 (대화 내용 등)
+	SkipBody bool
+	// 기록하지 않음 (연결 테스트 등 상태를 바꾸지 않는 요청)
+	Skip bool
+}
+
+// Audit 감사 미들웨어, 인증 미들웨어 뒤에 등록해야 합니다.
+// routes는 "메서드 라우트 경로"를 키로 하며, 목록에 없는 변경 요청(POST, PUT, PATCH, DELETE)도 메서드와 경로를 작업 이름으로 기록합니다.
+func Audit(auditService interfaces.AuditService, routes map[string]AuditRoute) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		path := c.FullPath()
+		route, listed := routes[method+" "+path]
+		if path == "" || route.Skip || (!listed && !isMutatingMethod(method)) {
+			c.Next()
+			return
+		}
+		if !listed {
+			route = AuditRoute{Action: strings.ToLower(method) + " " + path}
+		}
+		if _, ok := c.Get(types.TenantIDContextKey.String()); !ok {
+			c.Next()
+			return
+		}
+
+		resourceID := ""
+		if route.IDParam != "" {
+			resourceID = c.Param(route.IDParam)
+		}
+		var before interface{}
+		if route.Snapshot != nil && resourceID != "" {
+			before, _ = route.Snapshot(c, resourceID)
+		}
+		var body []byte
+		if !route.SkipBody && route.Snapshot == nil {
+			body = readAuditBody(c)
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		status := auditStatus(c)
+		success := status < 400
+		if resourceID == "" && success {
+			resourceID = responseDataID(writer.body.Bytes())
+		}
+
+		entry := &types.AuditLog{
+			Action:       route.Action,
+			ResourceType: route.ResourceType,
+			ResourceID:   resourceID,
+			Method:       method,
+			Path:         c.Request.URL.Path,
+			StatusCode:   status,
+			ClientIP:     c.ClientIP(),
+		}
+		if types.APIKeyFromContext(c.Request.Context()) == nil &&
+			types.AccessSubjectFromContext(c.Request.Context()) == nil {
+			entry.ActorType = types.AuditActorTenantKey
+		}
+
+		detail := map[string]interface{}{}
+		if success && route.Snapshot != nil {
+			var after interface{}
+			if method != http.MethodDelete && resourceID != "" {
+				after, _ = route.Snapshot(c, resourceID)
+			}
+			if changes, err := audit.Diff(before, after); err == nil && len(changes) > 0 {
+				entry.Changes = audit.Encode(changes)
+			}
+		}
+		if len(body) > 0 {
+			if request, err := audit.Redact(json.RawMessage(body)); err == nil {
+				detail["request"] = request
+			}
+		}
+		if !success && len(c.Errors) > 0 {
+			detail["error"] = c.Errors.Last().Error()
+		}
+		entry.Detail = audit.Encode(detail)
+
+		// 요청이 취소되어도 기록은 남도록 취소되지 않는 컨텍스트 사용
+		if err := auditService.Record(context.WithoutCancel(c.Request.Context()), entry); err != nil {
+			log.Printf("Failed to record audit log %s for %s %s: %v", entry.Action, method, entry.Path, err)
+		}
+	}
+}
+
+// auditStatus 응답 상태 코드를 반환합니다.
+// 핸들러 오류는 감사 미들웨어가 끝난 뒤 오류 처리 미들웨어가 응답하므로 오류에서 상태 코드를 결정합니다.
+func auditStatus(c *gin.Context) int {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return c.Writer.Status()
+	}
+	if appErr, ok := errors.IsAppError(c.Errors.Last().Err); ok {
+		return appErr.HTTPCode
+	}
+	return http.StatusInternalServerError
+}
+
+// isMutatingMethod 상태를 바꾸는 HTTP 메서드인지 확인
+func isMutatingMethod(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// readAuditBody JSON 요청 본문을 읽고 핸들러가 다시 읽을 수 있도록 복원합니다. 크기 제한을 넘으면 기록하지 않습니다.
+func readAuditBody(c *gin.Context) []byte {
+	if c.Request.Body == nil || !strings.HasPrefix(c.ContentType(), "application/json") {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, auditBodyLimit+1))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), c.Request.Body))
+	if err != nil || len(data) > auditBodyLimit || !json.Valid(data) {
+		return nil
+	}
+	return data
+}
+
+// responseDataID 응답 본문의 data.id를 가져옵니다. 생성된 리소스의 ID를 기록하는 데 사용됩니다.
+func responseDataID(body []byte) string {
+	var response struct {
+		Data struct {
+			ID json.RawMessage `json:"id"`
+		} `json:"data"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &response) != nil || len(response.Data.ID) == 0 {
+		return ""
+	}
+	var id string
+	if json.Unmarshal(response.Data.ID, &id) == nil {
+		return id
+	}
+	return string(response.Data.ID)
+}
+
+// auditResponseWriter JSON 응답 본문을 크기 제한까지 복사합니다.
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *auditResponseWriter) capture(data []byte) {
+	if w.body.Len()+len(data) > auditBodyLimit ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		return
+	}
+	w.body.Write(data)
+}
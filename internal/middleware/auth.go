@@ -21,23 +21,26 @@ var noAuthAPI = map[string][]string{
 	"/api/v1/auth/register": {"POST"},
 	"/api/v1/auth/login":    {"POST"},
 	"/api/v1/auth/refresh":  {"POST"},
+	"/api/v1/auth/sso/*":    {"GET", "POST"},
 }
 
 // 요청이 인증이 필요 없는 API 목록에 있는지 확인
 func isNoAuthAPI(path string, method string) bool {
 	for api, methods := range noAuthAPI {
 		// *로 끝나는 경우 접두사 일치 확인, 그렇지 않으면 전체 경로 일치 확인
-		if strings.HasSuffix(api, "*") {
-			if strings.HasPrefix(path, strings.TrimSuffix(api, "*")) && slices.Contains(methods, method) {
-				return true
-			}
-		} else if path == api && slices.Contains(methods, method) {
+		if matchRoute(api, path) && slices.Contains(methods, method) {
 			return true
 		}
 	}
 	return false
 }
 
+// acceptsBearerAPIKey Bearer 토큰으로 API 키를 받는 API인지 확인.
+// OpenAI 호환 클라이언트와 MCP 클라이언트는 API 키를 Authorization 헤더로만 전송할 수 있습니다.
+func acceptsBearerAPIKey(path string) bool {
+	return strings.HasPrefix(path, "/v1/") || path == "/mcp"
+}
+
 // canAccessTenant checks if a user can access a target tenant
 func canAccessTenant(user *types.User, targetTenantID uint64, cfg *config.Config) bool {
 	// 1. 기능 활성화 여부 확인
@@ -56,10 +59,23 @@ func canAccessTenant(user *types.User, targetTenantID uint64, cfg *config.Config
 	return true
 }
 
+// subjectRole 목표 테넌트에서 사용자의 역할을 결정합니다.
+// 크로스 테넌트 접근 사용자는 관리자로 간주하고, 알 수 없는 역할은 뷰어로 제한합니다.
+func subjectRole(user *types.User, targetTenantID uint64) types.TenantRole {
+	if user.TenantID != targetTenantID {
+		return types.TenantRoleAdmin
+	}
+	if !user.Role.IsValid() {
+		return types.TenantRoleViewer
+	}
+	return user.Role
+}
+
 // Auth 인증 미들웨어
 func Auth(
 	tenantService interfaces.TenantService,
 	userService interfaces.UserService,
+	apiKeyService interfaces.APIKeyService,
 	cfg *config.Config,
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -127,17 +143,24 @@ func Auth(
 					return
 				}
 
+				// 요청 주체 구성, 권한 검사에 사용
+				subject := &types.AccessSubject{UserID: user.ID, Role: subjectRole(user, targetTenantID)}
+
 				// 사용자 및 테넌트 정보를 컨텍스트에 저장
 				c.Set(types.TenantIDContextKey.String(), targetTenantID)
 				c.Set(types.TenantInfoContextKey.String(), tenant)
 				c.Set("user", user)
+				c.Set(types.AccessSubjectContextKey.String(), subject)
 				c.Request = c.Request.WithContext(
 					context.WithValue(
 						context.WithValue(
-							context.WithValue(c.Request.Context(), types.TenantIDContextKey, targetTenantID),
-							types.TenantInfoContextKey, tenant,
+							context.WithValue(
+								context.WithValue(c.Request.Context(), types.TenantIDContextKey, targetTenantID),
+								types.TenantInfoContextKey, tenant,
+							),
+							"user", user,
 						),
-						"user", user,
+						types.AccessSubjectContextKey, subject,
 					),
 				)
 				c.Next()
@@ -145,8 +168,18 @@ func Auth(
 			}
 		}
 
-		// X-API-Key 인증 시도 (호환 모드)
+		// X-API-Key 인증 시도
 		apiKey := c.GetHeader("X-API-Key")
+		// OpenAI 호환 클라이언트와 MCP 클라이언트는 API 키를 Bearer 토큰으로 전송
+		if apiKey == "" && acceptsBearerAPIKey(c.Request.URL.Path) && strings.HasPrefix(authHeader, "Bearer ") {
+			apiKey = strings.TrimPrefix(authHeader, "Bearer ")
+		}
+		if apiKey != "" && apiKeyService.IsAPIKey(apiKey) {
+			authenticateAPIKey(c, tenantService, apiKeyService, apiKey)
+			return
+		}
+
+		// 테넌트 기본 API 키 인증 시도 (호환 모드)
 		if apiKey != "" {
 			// Get tenant information
 			tenantID, err := tenantService.ExtractTenantIDFromAPIKey(apiKey)
@@ -196,6 +229,59 @@ func Auth(
 	}
 }
 
+// authenticateAPIKey 테넌트 API 키로 요청을 인증하고 키의 범위를 검사합니다.
+// 키로 인증된 요청에는 요청 주체가 없으며, 지식베이스 허용 목록은 리소스 권한 미들웨어와 권한 서비스에서 적용합니다.
+func authenticateAPIKey(c *gin.Context,
+	tenantService interfaces.TenantService, apiKeyService interfaces.APIKeyService, apiKey string,
+) {
+	key, err := apiKeyService.Authenticate(c.Request.Context(), apiKey)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: invalid API key",
+		})
+		c.Abort()
+		return
+	}
+
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	if required := requiredAPIKeyScopes(path, c.Request.Method); !key.HasScope(required...) {
+		log.Printf("API key %s without scope %v attempted %s %s", key.ID, required, c.Request.Method, path)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Forbidden: API key scope does not allow this request",
+		})
+		c.Abort()
+		return
+	}
+
+	t, err := tenantService.GetTenantByID(c.Request.Context(), key.TenantID)
+	if err != nil || t == nil {
+		log.Printf("Error getting tenant by ID: %v, tenantID: %d", err, key.TenantID)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: invalid API key",
+		})
+		c.Abort()
+		return
+	}
+
+	// 테넌트 ID와 API 키를 컨텍스트에 저장
+	c.Set(types.TenantIDContextKey.String(), key.TenantID)
+	c.Set(types.TenantInfoContextKey.String(), t)
+	c.Set(types.APIKeyContextKey.String(), key)
+	c.Request = c.Request.WithContext(
+		context.WithValue(
+			context.WithValue(
+				context.WithValue(c.Request.Context(), types.TenantIDContextKey, key.TenantID),
+				types.TenantInfoContextKey, t,
+			),
+			types.APIKeyContextKey, key,
+		),
+	)
+	c.Next()
+}
+
 // GetTenantIDFromContext helper function to get tenant ID from context
 func GetTenantIDFromContext(ctx context.Context) (uint64, error) {
 	tenantID, ok := ctx.Value("tenantID").(uint64)
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+func TestSubjectRole(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   *types.User
+		tenant uint64
+		want   types.TenantRole
+	}{
+		{"owner", &types.User{TenantID: 1, Role: types.TenantRoleOwner}, 1, types.TenantRoleOwner},
+		{"editor", &types.User{TenantID: 1, Role: types.TenantRoleEditor}, 1, types.TenantRoleEditor},
+		{"viewer", &types.User{TenantID: 1, Role: types.TenantRoleViewer}, 1, types.TenantRoleViewer},
+		{"missing role", &types.User{TenantID: 1}, 1, types.TenantRoleViewer},
+		{"unknown role", &types.User{TenantID: 1, Role: "superuser"}, 1, types.TenantRoleViewer},
+		{"cross tenant", &types.User{TenantID: 2, Role: types.TenantRoleViewer}, 1, types.TenantRoleAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subjectRole(tt.user, tt.tenant); got != tt.want {
+				t.Errorf("subjectRole() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// ResourceResolver 요청에서 권한을 검사할 리소스 ID를 가져옵니다.
+type ResourceResolver func(c *gin.Context) (string, error)
+
+// ParamResource 경로 매개변수를 리소스 ID로 사용하는 리졸버
+func ParamResource(name string) ResourceResolver {
+	return func(c *gin.Context) (string, error) {
+		return c.Param(name), nil
+	}
+}
+
+// RequireRole 요청 주체가 주어진 테넌트 역할 이상인지 확인하는 미들웨어
+func RequireRole(permissionService interfaces.PermissionService, role types.TenantRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := permissionService.CheckRole(c.Request.Context(), role); err != nil {
+			abortWithError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission 요청 주체가 리소스에 대한 권한을 가지고 있는지 확인하는 미들웨어
+func RequirePermission(
+	permissionService interfaces.PermissionService,
+	resourceType types.ResourceType,
+	resolve ResourceResolver,
+	permission types.Permission,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		// 주체가 없는 API 키 요청은 지식베이스 허용 목록이 있는 키만 리소스를 확인함
+		if types.AccessSubjectFromContext(ctx) == nil && !scopedAPIKey(ctx, resourceType) {
+			c.Next()
+			return
+		}
+		resourceID, err := resolve(c)
+		if err != nil || resourceID == "" {
+			// 리소스를 확인할 수 없으면 거부
+			abortWithError(c, errors.NewNotFoundError("리소스를 찾을 수 없습니다"))
+			return
+		}
+		if err := permissionService.CheckPermission(ctx, resourceType, resourceID, permission); err != nil {
+			abortWithError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// scopedAPIKey 요청의 API 키가 지식베이스 허용 목록으로 리소스를 제한하는지 확인합니다.
+func scopedAPIKey(ctx context.Context, resourceType types.ResourceType) bool {
+	key := types.APIKeyFromContext(ctx)
+	return key != nil && len(key.KnowledgeBaseIDs) > 0 && resourceType == types.ResourceTypeKnowledgeBase
+}
+
+// abortWithError 오류를 기록하고 요청 처리를 중단합니다.
+func abortWithError(c *gin.Context, err error) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		c.Error(appErr)
+	} else {
+		c.Error(errors.NewInternalServerError(err.Error()))
+	}
+	c.Abort()
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// allowlistPermissionService applies the knowledge base allowlist of the API key like the permission service
+// does for requests without a subject
+type allowlistPermissionService struct {
+	interfaces.PermissionService
+}
+
+func (s *allowlistPermissionService) CheckPermission(ctx context.Context,
+	resourceType types.ResourceType, resourceID string, permission types.Permission,
+) error {
+	if key := types.APIKeyFromContext(ctx); key != nil && resourceType == types.ResourceTypeKnowledgeBase &&
+		!key.AllowsKnowledgeBase(resourceID) {
+		return errors.NewForbiddenError("이 리소스에 대한 권한이 없습니다")
+	}
+	return nil
+}
+
+// resourceKBs maps knowledge and chunk IDs to the knowledge base they belong to
+var resourceKBs = map[string]string{
+	"knowledge-a": "kb-a",
+	"knowledge-b": "kb-b",
+	"chunk-a":     "kb-a",
+	"chunk-b":     "kb-b",
+}
+
+// kbOf resolves the knowledge base of the resource in a path parameter, like the knowledge and chunk guards
+func kbOf(param string) ResourceResolver {
+	return func(c *gin.Context) (string, error) {
+		kbID, ok := resourceKBs[c.Param(param)]
+		if !ok {
+			return "", fmt.Errorf("resource %s not found", c.Param(param))
+		}
+		return kbID, nil
+	}
+}
+
+func newPermissionRouter(key *types.TenantAPIKey) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	permissionService := &allowlistPermissionService{}
+
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.Use(func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), types.TenantIDContextKey, uint64(1))
+		if key != nil {
+			ctx = context.WithValue(ctx, types.APIKeyContextKey, key)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	})
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/knowledge-bases/:id", RequirePermission(permissionService,
+		types.ResourceTypeKnowledgeBase, ParamResource("id"), types.PermissionRead), ok)
+	r.DELETE("/knowledge/:id", RequirePermission(permissionService,
+		types.ResourceTypeKnowledgeBase, kbOf("id"), types.PermissionWrite), ok)
+	r.PUT("/chunks/:id", RequirePermission(permissionService,
+		types.ResourceTypeKnowledgeBase, kbOf("id"), types.PermissionWrite), ok)
+	return r
+}
+
+func TestRequirePermissionAPIKeyAllowlist(t *testing.T) {
+	scoped := newPermissionRouter(&types.TenantAPIKey{ID: "key", TenantID: 1, KnowledgeBaseIDs: []string{"kb-a"}})
+	unscoped := newPermissionRouter(&types.TenantAPIKey{ID: "key", TenantID: 1})
+
+	tests := []struct {
+		name   string
+		router *gin.Engine
+		method string
+		path   string
+		want   int
+	}{
+		{"kb in scope", scoped, http.MethodGet, "/knowledge-bases/kb-a", http.StatusOK},
+		{"kb out of scope", scoped, http.MethodGet, "/knowledge-bases/kb-b", http.StatusForbidden},
+		{"knowledge in scope", scoped, http.MethodDelete, "/knowledge/knowledge-a", http.StatusOK},
+		{"knowledge out of scope", scoped, http.MethodDelete, "/knowledge/knowledge-b", http.StatusForbidden},
+		{"chunk in scope", scoped, http.MethodPut, "/chunks/chunk-a", http.StatusOK},
+		{"chunk out of scope", scoped, http.MethodPut, "/chunks/chunk-b", http.StatusForbidden},
+		{"unknown resource", scoped, http.MethodPut, "/chunks/missing", http.StatusNotFound},
+		{"key without allowlist", unscoped, http.MethodDelete, "/knowledge/knowledge-b", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
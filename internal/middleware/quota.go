@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tencent/WeKnora/internal/application/service/quota"
+	werrors "github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// quotaStreamRoutes 대화 스트림 라우트, 일일 토큰 할당량과 동시 스트림 수를 검사합니다.
+var quotaStreamRoutes = map[string]bool{
+	"POST /api/v1/knowledge-chat/:session_id": true,
+	"POST /api/v1/agent-chat/:session_id":     true,
+	"POST /v1/chat/completions":               true,
+}
+
+// quotaIngestionRoutes 지식 업로드 라우트, 요청 본문 크기를 일일 업로드 할당량에 더합니다.
+var quotaIngestionRoutes = map[string]bool{
+	"POST /api/v1/knowledge-bases/:id/knowledge/file":   true,
+	"POST /api/v1/knowledge-bases/:id/knowledge/url":    true,
+	"POST /api/v1/knowledge-bases/:id/knowledge/manual": true,
+	"PUT /api/v1/knowledge/:id/file":                    true,
+	"PUT /api/v1/knowledge/manual/:id":                  true,
+	"POST /api/v1/knowledge-bases/:id/faq/entries":      true,
+	"POST /api/v1/knowledge-bases/:id/faq/entry":        true,
+}
+
+// Quota 할당량 미들웨어, 인증 미들웨어 뒤에 등록해야 합니다.
+// 모든 요청의 분당 요청 수를 검사하고, 대화 스트림과 지식 업로드 라우트는 해당 할당량도 검사합니다.
+// 할당량을 초과하면 429 응답과 Retry-After 헤더를 반환합니다.
+func Quota(quotaService interfaces.QuotaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(types.TenantIDContextKey.String()); !ok {
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+		if err := quotaService.CheckRequest(ctx); abortQuotaExceeded(c, err) {
+			return
+		}
+
+		route := c.Request.Method + " " + c.FullPath()
+		// 청크 전송 요청은 크기를 미리 알 수 없어 검사하지 않음
+		if quotaIngestionRoutes[route] && c.Request.ContentLength > 0 {
+			if err := quotaService.ConsumeIngestion(ctx, c.Request.ContentLength); abortQuotaExceeded(c, err) {
+				return
+			}
+		}
+		if quotaStreamRoutes[route] {
+			release, err := quotaService.AcquireStream(ctx)
+			if abortQuotaExceeded(c, err) {
+				return
+			}
+			// 스트림 핸들러는 스트림이 끝날 때까지 반환하지 않음
+			if release != nil {
+				defer release()
+			}
+		}
+		c.Next()
+	}
+}
+
+// abortQuotaExceeded 할당량 초과 오류이면 요청을 중단하고 true를 반환합니다. 다른 오류는 요청을 막지 않습니다.
+func abortQuotaExceeded(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	var exceeded *types.QuotaExceededError
+	if !errors.As(err, &exceeded) {
+		log.Printf("Quota check failed: %v", err)
+		return false
+	}
+	retryAfter := quota.RetryAfterSeconds(exceeded.RetryAfter)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.Error(werrors.NewTooManyRequestsError("요청 한도를 초과했습니다. 잠시 후 다시 시도해 주세요").WithDetails(gin.H{
+		"subject_type": exceeded.Subject.Type,
+		"subject_id":   exceeded.Subject.ID,
+		"limit":        exceeded.Limit,
+		"retry_after":  retryAfter,
+	}))
+	c.Abort()
+	return true
+}
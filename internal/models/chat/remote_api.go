@@ -128,6 +128,10 @@ func (c *RemoteAPIChat) buildChatCompletionRequest(messages []Message,
 		Messages: c.convertMessages(messages),
 		Stream:   isStream,
 	}
+	if isStream {
+		// 스트림 마지막에 토큰 사용량을 받기 위해 설정
+		req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
 	thinking := false
 
 	// 선택적 매개변수 추가
@@ -397,6 +401,7 @@ func (c *RemoteAPIChat) ChatStream(ctx context.Context,
 		defer stream.Close()
 
 		toolCallMap := make(map[int]*types.LLMToolCall)
+		var usage *types.TokenUsage
 		lastFunctionName := make(map[int]string)
 		nameNotified := make(map[int]bool)
 
@@ -427,6 +432,7 @@ func (c *RemoteAPIChat) ChatStream(ctx context.Context,
 						Content:      "",
 						Done:         true,
 						ToolCalls:    buildOrderedToolCalls(),
+						Usage:        usage,
 					}
 				} else {
 					// Actual error, send error response
@@ -439,6 +445,15 @@ func (c *RemoteAPIChat) ChatStream(ctx context.Context,
 				return
 			}
 
+			// 사용량은 choices가 비어 있는 마지막 블록으로 전달됨
+			if response.Usage != nil {
+				usage = &types.TokenUsage{
+					PromptTokens:     response.Usage.PromptTokens,
+					CompletionTokens: response.Usage.CompletionTokens,
+					TotalTokens:      response.Usage.TotalTokens,
+				}
+			}
+
 			if len(response.Choices) > 0 {
 				delta := response.Choices[0].Delta
 				isDone := string(response.Choices[0].FinishReason) != ""
@@ -31,6 +31,16 @@ type Embedder interface {
 	EmbedderPooler
 }
 
+// TokenEmbedder is implemented by late-interaction (ColBERT-style) embedders that
+// produce one vector per token instead of a single pooled vector
+type TokenEmbedder interface {
+	// EmbedQueryTokens converts a query to per-token vectors
+	EmbedQueryTokens(ctx context.Context, text string) ([][]float32, error)
+
+	// BatchEmbedDocumentTokens converts multiple documents to per-token vectors in batch
+	BatchEmbedDocumentTokens(ctx context.Context, texts []string) ([][][]float32, error)
+}
+
 type EmbedderPooler interface {
 	BatchEmbedWithPool(ctx context.Context, model Embedder, texts []string) ([][]float32, error)
 }
@@ -126,6 +136,18 @@ func NewEmbedder(config Config) (Embedder, error) {
 			})
 			return embedder, err
 		case provider.ProviderJina:
+			// ColBERT models are served by the multi-vector endpoint and produce per-token vectors
+			if strings.Contains(strings.ToLower(config.ModelName), "colbert") {
+				runtime.GetContainer().Invoke(func(pooler EmbedderPooler) {
+					embedder, err = NewJinaColBERTEmbedder(config.APIKey,
+						config.BaseURL,
+						config.ModelName,
+						config.Dimensions,
+						config.ModelID,
+						pooler)
+				})
+				return embedder, err
+			}
 			// Jina AI uses different API format (truncate instead of truncate_prompt_tokens)
 			runtime.GetContainer().Invoke(func(pooler EmbedderPooler) {
 				embedder, err = NewJinaEmbedder(config.APIKey,
@@ -0,0 +1,254 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/logger"
+)
+
+const (
+	jinaInputTypeQuery    = "query"
+	jinaInputTypeDocument = "document"
+)
+
+// JinaColBERTEmbedder implements late-interaction (ColBERT-style) embedding using the Jina multi-vector API.
+// It produces per-token vectors for multi-vector retrieval, and mean-pooled vectors so that it can
+// still be used as a regular single-vector Embedder
+type JinaColBERTEmbedder struct {
+	apiKey     string
+	baseURL    string
+	modelName  string
+	dimensions int
+	modelID    string
+	httpClient *http.Client
+	maxRetries int
+	EmbedderPooler
+}
+
+// JinaMultiVectorRequest represents a Jina multi-vector embedding request
+type JinaMultiVectorRequest struct {
+	Model         string   `json:"model"`
+	Input         []string `json:"input"`
+	InputType     string   `json:"input_type"`
+	EmbeddingType string   `json:"embedding_type"`
+	Dimensions    int      `json:"dimensions,omitempty"`
+}
+
+// JinaMultiVectorResponse represents a Jina multi-vector embedding response
+type JinaMultiVectorResponse struct {
+	Data []struct {
+		Embeddings [][]float32 `json:"embeddings"`
+		Index      int         `json:"index"`
+	} `json:"data"`
+}
+
+// NewJinaColBERTEmbedder creates a new Jina ColBERT embedder
+func NewJinaColBERTEmbedder(apiKey, baseURL, modelName string,
+	dimensions int, modelID string, pooler EmbedderPooler,
+) (*JinaColBERTEmbedder, error) {
+	if baseURL == "" {
+		baseURL = "https://api.jina.ai/v1"
+	}
+
+	if modelName == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+
+	return &JinaColBERTEmbedder{
+		apiKey:         apiKey,
+		baseURL:        baseURL,
+		modelName:      modelName,
+		dimensions:     dimensions,
+		modelID:        modelID,
+		httpClient:     &http.Client{Timeout: 60 * time.Second},
+		maxRetries:     3,
+		EmbedderPooler: pooler,
+	}, nil
+}
+
+// Embed converts text to a single mean-pooled vector
+func (e *JinaColBERTEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.BatchEmbed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("no embedding returned")
+	}
+	return embeddings[0], nil
+}
+
+// BatchEmbed converts multiple texts to mean-pooled vectors in batch
+func (e *JinaColBERTEmbedder) BatchEmbed(ctx context.Context, texts []string) ([][]float32, error) {
+	tokenEmbeddings, err := e.BatchEmbedDocumentTokens(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	embeddings := make([][]float32, 0, len(tokenEmbeddings))
+	for _, tokens := range tokenEmbeddings {
+		embeddings = append(embeddings, meanPool(tokens))
+	}
+	return embeddings, nil
+}
+
+// EmbedQueryTokens converts a query to per-token vectors
+func (e *JinaColBERTEmbedder) EmbedQueryTokens(ctx context.Context, text string) ([][]float32, error) {
+	embeddings, err := e.embedTokens(ctx, []string{text}, jinaInputTypeQuery)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("no embedding returned")
+	}
+	return embeddings[0], nil
+}
+
+// BatchEmbedDocumentTokens converts multiple documents to per-token vectors in batch
+func (e *JinaColBERTEmbedder) BatchEmbedDocumentTokens(ctx context.Context, texts []string) ([][][]float32, error) {
+	return e.embedTokens(ctx, texts, jinaInputTypeDocument)
+}
+
+func (e *JinaColBERTEmbedder) embedTokens(ctx context.Context, texts []string, inputType string) ([][][]float32, error) {
+	reqBody := JinaMultiVectorRequest{
+		Model:         e.modelName,
+		Input:         texts,
+		InputType:     inputType,
+		EmbeddingType: "float",
+	}
+	if e.dimensions > 0 {
+		reqBody.Dimensions = e.dimensions
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		logger.GetLogger(ctx).Errorf("JinaColBERTEmbedder marshal request error: %v", err)
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := e.doRequestWithRetry(ctx, jsonData)
+	if err != nil {
+		logger.GetLogger(ctx).Errorf("JinaColBERTEmbedder send request error: %v", err)
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.GetLogger(ctx).Errorf("JinaColBERTEmbedder read response error: %v", err)
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		logger.GetLogger(ctx).Errorf("JinaColBERTEmbedder API error: Http Status %s, Body: %s", resp.Status, string(body))
+		return nil, fmt.Errorf("multi-vector API error: Http Status %s", resp.Status)
+	}
+
+	var response JinaMultiVectorResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		logger.GetLogger(ctx).Errorf("JinaColBERTEmbedder unmarshal response error: %v", err)
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("multi-vector API returned %d embeddings for %d inputs", len(response.Data), len(texts))
+	}
+
+	embeddings := make([][][]float32, len(texts))
+	for _, data := range response.Data {
+		if data.Index < 0 || data.Index >= len(texts) {
+			return nil, fmt.Errorf("multi-vector API returned invalid index %d", data.Index)
+		}
+		embeddings[data.Index] = data.Embeddings
+	}
+	return embeddings, nil
+}
+
+func (e *JinaColBERTEmbedder) doRequestWithRetry(ctx context.Context, jsonData []byte) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+	url := e.baseURL + "/multi-vector"
+
+	for i := 0; i <= e.maxRetries; i++ {
+		if i > 0 {
+			backoffTime := time.Duration(1<<uint(i-1)) * time.Second
+			if backoffTime > 10*time.Second {
+				backoffTime = 10 * time.Second
+			}
+			logger.GetLogger(ctx).
+				Infof("JinaColBERTEmbedder retrying request (%d/%d), waiting %v", i, e.maxRetries, backoffTime)
+
+			select {
+			case <-time.After(backoffTime):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		// Rebuild request each time to ensure Body is valid
+		req, reqErr := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+		if reqErr != nil {
+			err = reqErr
+			logger.GetLogger(ctx).Errorf("JinaColBERTEmbedder failed to create request: %v", err)
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+
+		resp, err = e.httpClient.Do(req)
+		if err == nil {
+			return resp, nil
+		}
+
+		logger.GetLogger(ctx).Errorf("JinaColBERTEmbedder request failed (attempt %d/%d): %v", i+1, e.maxRetries+1, err)
+	}
+
+	return nil, err
+}
+
+// GetModelName returns the model name
+func (e *JinaColBERTEmbedder) GetModelName() string {
+	return e.modelName
+}
+
+// GetDimensions returns the vector dimensions
+func (e *JinaColBERTEmbedder) GetDimensions() int {
+	return e.dimensions
+}
+
+// GetModelID returns the model ID
+func (e *JinaColBERTEmbedder) GetModelID() string {
+	return e.modelID
+}
+
+// meanPool averages token vectors into a single L2-normalized vector
+func meanPool(tokens [][]float32) []float32 {
+	if len(tokens) == 0 {
+		return nil
+	}
+	pooled := make([]float32, len(tokens[0]))
+	for _, token := range tokens {
+		for i := range pooled {
+			if i < len(token) {
+				pooled[i] += token[i]
+			}
+		}
+	}
+	var norm float64
+	for _, v := range pooled {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return pooled
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range pooled {
+		pooled[i] *= scale
+	}
+	return pooled
+}
@@ -0,0 +1,315 @@
+package router
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tencent/WeKnora/internal/middleware"
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// auditSnapshots 감사 기록의 변경 전후 비교를 위해 리소스 상태를 조회합니다.
+type auditSnapshots struct {
+	params RouterParams
+}
+
+func (s *auditSnapshots) knowledgeBase(c *gin.Context, id string) (interface{}, error) {
+	return s.params.KBService.GetKnowledgeBaseByID(c.Request.Context(), id)
+}
+
+func (s *auditSnapshots) knowledge(c *gin.Context, id string) (interface{}, error) {
+	return s.params.KnowledgeService.GetKnowledgeByID(c.Request.Context(), id)
+}
+
+func (s *auditSnapshots) chunk(c *gin.Context, id string) (interface{}, error) {
+	return s.params.ChunkService.GetChunkByID(c.Request.Context(), id)
+}
+
+func (s *auditSnapshots) faqEntry(c *gin.Context, id string) (interface{}, error) {
+	return s.params.KnowledgeService.GetFAQEntry(c.Request.Context(), c.Param("id"), id)
+}
+
+func (s *auditSnapshots) connector(c *gin.Context, id string) (interface{}, error) {
+	return s.params.ConnectorService.GetConnector(c.Request.Context(), id)
+}
+
+func (s *auditSnapshots) model(c *gin.Context, id string) (interface{}, error) {
+	return s.params.ModelService.GetModelByID(c.Request.Context(), id)
+}
+
+func (s *auditSnapshots) agent(c *gin.Context, id string) (interface{}, error) {
+	return s.params.CustomAgentService.GetAgentByID(c.Request.Context(), id)
+}
+
+func (s *auditSnapshots) mcpService(c *gin.Context, id string) (interface{}, error) {
+	tenantID := c.GetUint64(types.TenantIDContextKey.String())
+	return s.params.MCPServiceService.GetMCPServiceByID(c.Request.Context(), tenantID, id)
+}
+
+func (s *auditSnapshots) tenant(c *gin.Context, id string) (interface{}, error) {
+	tenantID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return s.params.TenantService.GetTenantByID(c.Request.Context(), tenantID)
+}
+
+// currentTenant 경로 매개변수와 관계없이 요청 테넌트의 상태를 조회합니다. 테넌트 KV 구성 변경에 사용됩니다.
+func (s *auditSnapshots) currentTenant(c *gin.Context, _ string) (interface{}, error) {
+	return s.params.TenantService.GetTenantByID(c.Request.Context(), c.GetUint64(types.TenantIDContextKey.String()))
+}
+
+func (s *auditSnapshots) webhook(c *gin.Context, id string) (interface{}, error) {
+	return s.params.WebhookService.GetWebhook(c.Request.Context(), id)
+}
+
+func (s *auditSnapshots) member(c *gin.Context, id string) (interface{}, error) {
+	return s.params.UserService.GetUserByID(c.Request.Context(), id)
+}
+
+// newAuditRoutes 감사 대상 라우트 목록을 생성합니다.
+// 목록에 없는 변경 요청도 메서드와 경로로 기록되므로, 라우트를 추가할 때 의미 있는 작업 이름과 리소스를 함께 등록합니다.
+// 조회 요청은 다운로드, 내보내기, 검색처럼 데이터 접근으로 기록할 라우트만 등록합니다.
+func newAuditRoutes(params RouterParams) map[string]middleware.AuditRoute {
+	s := &auditSnapshots{params: params}
+	return map[string]middleware.AuditRoute{
+		// 인증
+		"POST /api/v1/auth/logout":          {Action: "user.logout"},
+		"POST /api/v1/auth/change-password": {Action: "user.change_password"},
+
+		// 지식베이스
+		"POST /api/v1/knowledge-bases": {Action: "knowledge_base.create",
+			ResourceType: types.AuditResourceKnowledgeBase, Snapshot: s.knowledgeBase},
+		"PUT /api/v1/knowledge-bases/:id": {Action: "knowledge_base.update",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "id", Snapshot: s.knowledgeBase},
+		"DELETE /api/v1/knowledge-bases/:id": {Action: "knowledge_base.delete",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "id", Snapshot: s.knowledgeBase},
+		"POST /api/v1/knowledge-bases/copy": {Action: "knowledge_base.copy",
+			ResourceType: types.AuditResourceKnowledgeBase},
+		"POST /api/v1/knowledge-bases/:id/reembed": {Action: "knowledge_base.reembed",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "id"},
+		"GET /api/v1/knowledge-bases/:id/hybrid-search": {Action: "knowledge_base.search",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "id"},
+		"POST /api/v1/initialization/initialize/:kbId": {Action: "knowledge_base.initialize",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "kbId", Snapshot: s.knowledgeBase},
+		"PUT /api/v1/initialization/config/:kbId": {Action: "knowledge_base.update_models",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "kbId", Snapshot: s.knowledgeBase},
+
+		// 지식
+		"POST /api/v1/knowledge-bases/:id/knowledge/file": {Action: "knowledge.create",
+			ResourceType: types.AuditResourceKnowledge, Snapshot: s.knowledge},
+		"POST /api/v1/knowledge-bases/:id/knowledge/url": {Action: "knowledge.create",
+			ResourceType: types.AuditResourceKnowledge, Snapshot: s.knowledge},
+		"POST /api/v1/knowledge-bases/:id/knowledge/manual": {Action: "knowledge.create",
+			ResourceType: types.AuditResourceKnowledge, Snapshot: s.knowledge},
+		"PUT /api/v1/knowledge/:id": {Action: "knowledge.update",
+			ResourceType: types.AuditResourceKnowledge, IDParam: "id", Snapshot: s.knowledge},
+		"PUT /api/v1/knowledge/manual/:id": {Action: "knowledge.update",
+			ResourceType: types.AuditResourceKnowledge, IDParam: "id", Snapshot: s.knowledge},
+		"PUT /api/v1/knowledge/:id/file": {Action: "knowledge.update_file",
+			ResourceType: types.AuditResourceKnowledge, IDParam: "id", Snapshot: s.knowledge},
+		"DELETE /api/v1/knowledge/:id": {Action: "knowledge.delete",
+			ResourceType: types.AuditResourceKnowledge, IDParam: "id", Snapshot: s.knowledge},
+		"POST /api/v1/knowledge/:id/versions/:version/restore": {Action: "knowledge.restore_version",
+			ResourceType: types.AuditResourceKnowledge, IDParam: "id", Snapshot: s.knowledge},
+		"PUT /api/v1/knowledge/image/:id/:chunk_id": {Action: "knowledge.update_image",
+			ResourceType: types.AuditResourceKnowledge, IDParam: "id"},
+		"PUT /api/v1/knowledge/tags": {Action: "knowledge.update_tags",
+			ResourceType: types.AuditResourceKnowledge},
+		"GET /api/v1/knowledge/:id/download": {Action: "knowledge.download",
+			ResourceType: types.AuditResourceKnowledge, IDParam: "id"},
+		"GET /api/v1/knowledge/search": {Action: "knowledge.search",
+			ResourceType: types.AuditResourceKnowledge},
+		"POST /api/v1/knowledge-search": {Action: "knowledge.search",
+			ResourceType: types.AuditResourceKnowledge},
+
+		// 청크
+		"PUT /api/v1/chunks/:knowledge_id/:id": {Action: "chunk.update",
+			ResourceType: types.AuditResourceChunk, IDParam: "id", Snapshot: s.chunk},
+		"DELETE /api/v1/chunks/:knowledge_id/:id": {Action: "chunk.delete",
+			ResourceType: types.AuditResourceChunk, IDParam: "id", Snapshot: s.chunk},
+		"DELETE /api/v1/chunks/:knowledge_id": {Action: "knowledge.delete_chunks",
+			ResourceType: types.AuditResourceKnowledge, IDParam: "knowledge_id"},
+		"DELETE /api/v1/chunks/by-id/:id/questions": {Action: "chunk.delete_question",
+			ResourceType: types.AuditResourceChunk, IDParam: "id", Snapshot: s.chunk},
+
+		// FAQ
+		"POST /api/v1/knowledge-bases/:id/faq/entry": {Action: "faq_entry.create",
+			ResourceType: types.AuditResourceFAQEntry, Snapshot: s.faqEntry},
+		"PUT /api/v1/knowledge-bases/:id/faq/entries/:entry_id": {Action: "faq_entry.update",
+			ResourceType: types.AuditResourceFAQEntry, IDParam: "entry_id", Snapshot: s.faqEntry},
+		"POST /api/v1/knowledge-bases/:id/faq/entries": {Action: "faq_entry.upsert",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "id"},
+		"PUT /api/v1/knowledge-bases/:id/faq/entries/fields": {Action: "faq_entry.update_fields",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "id"},
+		"PUT /api/v1/knowledge-bases/:id/faq/entries/tags": {Action: "faq_entry.update_tags",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "id"},
+		"DELETE /api/v1/knowledge-bases/:id/faq/entries": {Action: "faq_entry.delete",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "id"},
+		"POST /api/v1/knowledge-bases/:id/faq/search": {Action: "faq_entry.search",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "id"},
+		"GET /api/v1/knowledge-bases/:id/faq/entries/export": {Action: "faq_entry.export",
+			ResourceType: types.AuditResourceKnowledgeBase, IDParam: "id"},
+
+		// 태그
+		"POST /api/v1/knowledge-bases/:id/tags": {Action: "tag.create",
+			ResourceType: types.AuditResourceTag},
+		"PUT /api/v1/knowledge-bases/:id/tags/:tag_id": {Action: "tag.update",
+			ResourceType: types.AuditResourceTag, IDParam: "tag_id"},
+		"DELETE /api/v1/knowledge-bases/:id/tags/:tag_id": {Action: "tag.delete",
+			ResourceType: types.AuditResourceTag, IDParam: "tag_id"},
+
+		// 커넥터
+		"POST /api/v1/knowledge-bases/:id/connectors": {Action: "connector.create",
+			ResourceType: types.AuditResourceConnector, Snapshot: s.connector},
+		"PUT /api/v1/connectors/:id": {Action: "connector.update",
+			ResourceType: types.AuditResourceConnector, IDParam: "id", Snapshot: s.connector},
+		"DELETE /api/v1/connectors/:id": {Action: "connector.delete",
+			ResourceType: types.AuditResourceConnector, IDParam: "id", Snapshot: s.connector},
+		"POST /api/v1/connectors/:id/sync": {Action: "connector.sync",
+			ResourceType: types.AuditResourceConnector, IDParam: "id"},
+
+		// 세션 및 대화, 대화 내용은 기록하지 않음
+		"POST /api/v1/sessions": {Action: "session.create",
+			ResourceType: types.AuditResourceSession, SkipBody: true},
+		"PUT /api/v1/sessions/:id": {Action: "session.update",
+			ResourceType: types.AuditResourceSession, IDParam: "id", SkipBody: true},
+		"DELETE /api/v1/sessions/:id": {Action: "session.delete",
+			ResourceType: types.AuditResourceSession, IDParam: "id"},
+		"POST /api/v1/sessions/:session_id/generate_title": {Skip: true},
+		"POST /api/v1/sessions/:session_id/stop":           {Skip: true},
+		"POST /api/v1/sessions/:session_id/resume": {Action: "session.resume",
+			ResourceType: types.AuditResourceSession, IDParam: "session_id"},
+		"POST /api/v1/tool-approvals/:session_id/:id/approve": {Action: "session.tool_approve",
+			ResourceType: types.AuditResourceSession, IDParam: "session_id"},
+		"POST /api/v1/tool-approvals/:session_id/:id/reject": {Action: "session.tool_reject",
+			ResourceType: types.AuditResourceSession, IDParam: "session_id"},
+		"POST /api/v1/knowledge-chat/:session_id": {Action: "session.chat",
+			ResourceType: types.AuditResourceSession, IDParam: "session_id", SkipBody: true},
+		"POST /api/v1/agent-chat/:session_id": {Action: "session.agent_chat",
+			ResourceType: types.AuditResourceSession, IDParam: "session_id", SkipBody: true},
+		"DELETE /api/v1/messages/:session_id/:id": {Action: "message.delete",
+			ResourceType: types.AuditResourceMessage, IDParam: "id"},
+		"PUT /api/v1/messages/:session_id/:id/feedback": {Action: "message.feedback",
+			ResourceType: types.AuditResourceMessage, IDParam: "id"},
+		"DELETE /api/v1/messages/:session_id/:id/feedback": {Action: "message.feedback_delete",
+			ResourceType: types.AuditResourceMessage, IDParam: "id"},
+
+		// 사용자 기억, 기억 내용은 기록하지 않음
+		"PUT /api/v1/memories/:id": {Action: "memory.update",
+			ResourceType: types.AuditResourceMemory, IDParam: "id", SkipBody: true},
+		"DELETE /api/v1/memories/:id": {Action: "memory.delete",
+			ResourceType: types.AuditResourceMemory, IDParam: "id"},
+		"DELETE /api/v1/memories": {Action: "memory.delete_all",
+			ResourceType: types.AuditResourceMemory},
+
+		// 테넌트
+		"POST /api/v1/tenants": {Action: "tenant.create",
+			ResourceType: types.AuditResourceTenant, Snapshot: s.tenant},
+		"PUT /api/v1/tenants/:id": {Action: "tenant.update",
+			ResourceType: types.AuditResourceTenant, IDParam: "id", Snapshot: s.tenant},
+		"DELETE /api/v1/tenants/:id": {Action: "tenant.delete",
+			ResourceType: types.AuditResourceTenant, IDParam: "id", Snapshot: s.tenant},
+		"PUT /api/v1/tenants/kv/:key": {Action: "tenant.update_config",
+			ResourceType: types.AuditResourceTenant, IDParam: "key", Snapshot: s.currentTenant},
+
+		// 구성원, 사용자 그룹 및 권한 부여
+		"POST /api/v1/tenant/members": {Action: "member.create",
+			ResourceType: types.AuditResourceMember, Snapshot: s.member},
+		"PUT /api/v1/tenant/members/:user_id/role": {Action: "member.update_role",
+			ResourceType: types.AuditResourceMember, IDParam: "user_id", Snapshot: s.member},
+		"DELETE /api/v1/tenant/members/:user_id": {Action: "member.delete",
+			ResourceType: types.AuditResourceMember, IDParam: "user_id", Snapshot: s.member},
+		"POST /api/v1/groups": {Action: "group.create",
+			ResourceType: types.AuditResourceGroup},
+		"PUT /api/v1/groups/:id": {Action: "group.update",
+			ResourceType: types.AuditResourceGroup, IDParam: "id"},
+		"DELETE /api/v1/groups/:id": {Action: "group.delete",
+			ResourceType: types.AuditResourceGroup, IDParam: "id"},
+		"POST /api/v1/groups/:id/members": {Action: "group.add_member",
+			ResourceType: types.AuditResourceGroup, IDParam: "id"},
+		"DELETE /api/v1/groups/:id/members/:user_id": {Action: "group.remove_member",
+			ResourceType: types.AuditResourceGroup, IDParam: "id"},
+		"POST /api/v1/grants": {Action: "grant.set",
+			ResourceType: types.AuditResourceGrant},
+		"DELETE /api/v1/grants/:id": {Action: "grant.delete",
+			ResourceType: types.AuditResourceGrant, IDParam: "id"},
+
+		// API 키
+		"POST /api/v1/tenant/api-keys": {Action: "api_key.create",
+			ResourceType: types.AuditResourceAPIKey},
+		"DELETE /api/v1/tenant/api-keys/:id": {Action: "api_key.revoke",
+			ResourceType: types.AuditResourceAPIKey, IDParam: "id"},
+
+		// 웹훅
+		"POST /api/v1/webhooks": {Action: "webhook.create",
+			ResourceType: types.AuditResourceWebhook},
+		"PUT /api/v1/webhooks/:id": {Action: "webhook.update",
+			ResourceType: types.AuditResourceWebhook, IDParam: "id", Snapshot: s.webhook},
+		"DELETE /api/v1/webhooks/:id": {Action: "webhook.delete",
+			ResourceType: types.AuditResourceWebhook, IDParam: "id", Snapshot: s.webhook},
+		"POST /api/v1/webhooks/:id/test": {Action: "webhook.test",
+			ResourceType: types.AuditResourceWebhook, IDParam: "id"},
+		"POST /api/v1/webhooks/:id/deliveries/:delivery_id/redeliver": {Action: "webhook.redeliver",
+			ResourceType: types.AuditResourceWebhook, IDParam: "id"},
+
+		// 모델
+		"POST /api/v1/models": {Action: "model.create",
+			ResourceType: types.AuditResourceModel, Snapshot: s.model},
+		"PUT /api/v1/models/:id": {Action: "model.update",
+			ResourceType: types.AuditResourceModel, IDParam: "id", Snapshot: s.model},
+		"DELETE /api/v1/models/:id": {Action: "model.delete",
+			ResourceType: types.AuditResourceModel, IDParam: "id", Snapshot: s.model},
+		"POST /api/v1/initialization/ollama/models/download": {Action: "model.download"},
+		"POST /api/v1/initialization/ollama/models/check":    {Skip: true},
+		"POST /api/v1/initialization/remote/check":           {Skip: true},
+		"POST /api/v1/initialization/embedding/test":         {Skip: true},
+		"POST /api/v1/initialization/rerank/check":           {Skip: true},
+		"POST /api/v1/initialization/multimodal/test":        {Skip: true},
+		"POST /api/v1/initialization/extract/text-relation":  {Skip: true},
+		"POST /api/v1/initialization/extract/fabri-tag":      {Skip: true},
+		"POST /api/v1/initialization/extract/fabri-text":     {Skip: true},
+
+		// 평가
+		"POST /api/v1/evaluation/": {Action: "evaluation.run",
+			ResourceType: types.AuditResourceEvaluation},
+		"DELETE /api/v1/evaluation/runs/:id": {Action: "evaluation.run_delete",
+			ResourceType: types.AuditResourceEvaluation, IDParam: "id"},
+		"POST /api/v1/evaluation/datasets": {Action: "evaluation.dataset_create",
+			ResourceType: types.AuditResourceEvaluation},
+		"DELETE /api/v1/evaluation/datasets/:id": {Action: "evaluation.dataset_delete",
+			ResourceType: types.AuditResourceEvaluation, IDParam: "id"},
+
+		// MCP 서비스
+		"POST /api/v1/mcp-services": {Action: "mcp_service.create",
+			ResourceType: types.AuditResourceMCPService, Snapshot: s.mcpService},
+		"PUT /api/v1/mcp-services/:id": {Action: "mcp_service.update",
+			ResourceType: types.AuditResourceMCPService, IDParam: "id", Snapshot: s.mcpService},
+		"DELETE /api/v1/mcp-services/:id": {Action: "mcp_service.delete",
+			ResourceType: types.AuditResourceMCPService, IDParam: "id", Snapshot: s.mcpService},
+		"POST /api/v1/mcp-services/:id/test": {Skip: true},
+
+		// 에이전트
+		"POST /api/v1/agents": {Action: "agent.create",
+			ResourceType: types.AuditResourceAgent, Snapshot: s.agent},
+		"PUT /api/v1/agents/:id": {Action: "agent.update",
+			ResourceType: types.AuditResourceAgent, IDParam: "id", Snapshot: s.agent},
+		"DELETE /api/v1/agents/:id": {Action: "agent.delete",
+			ResourceType: types.AuditResourceAgent, IDParam: "id", Snapshot: s.agent},
+		"POST /api/v1/agents/:id/copy": {Action: "agent.copy",
+			ResourceType: types.AuditResourceAgent, Snapshot: s.agent},
+
+		// OpenAI 호환 API와 MCP 서버, MCP 쓰기 도구의 변경은 MCP 서버에서 직접 기록
+		"POST /v1/chat/completions": {Action: "session.chat",
+			ResourceType: types.AuditResourceSession, SkipBody: true},
+		"POST /mcp":   {Skip: true},
+		"DELETE /mcp": {Skip: true},
+
+		// 감사 기록
+		"GET /api/v1/audit-logs/export": {Action: "audit_log.export",
+			ResourceType: types.AuditResourceAuditLog},
+		"GET /api/v1/audit-logs/verify": {Action: "audit_log.verify",
+			ResourceType: types.AuditResourceAuditLog},
+	}
+}
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tencent/WeKnora/internal/middleware"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// accessGuard 라우트별 역할 및 리소스 권한 검사 미들웨어를 생성합니다.
+type accessGuard struct {
+	permissionService interfaces.PermissionService
+	knowledgeService  interfaces.KnowledgeService
+	chunkService      interfaces.ChunkService
+	connectorService  interfaces.ConnectorService
+}
+
+// newAccessGuard 라우터 매개변수로 accessGuard 생성
+func newAccessGuard(params RouterParams) *accessGuard {
+	return &accessGuard{
+		permissionService: params.PermissionService,
+		knowledgeService:  params.KnowledgeService,
+		chunkService:      params.ChunkService,
+		connectorService:  params.ConnectorService,
+	}
+}
+
+// role 테넌트 역할 검사
+func (g *accessGuard) role(role types.TenantRole) gin.HandlerFunc {
+	return middleware.RequireRole(g.permissionService, role)
+}
+
+// kb 경로 매개변수의 지식베이스에 대한 권한 검사
+func (g *accessGuard) kb(param string, permission types.Permission) gin.HandlerFunc {
+	return middleware.RequirePermission(g.permissionService,
+		types.ResourceTypeKnowledgeBase, middleware.ParamResource(param), permission)
+}
+
+// knowledge 경로 매개변수의 지식이 속한 지식베이스에 대한 권한 검사
+func (g *accessGuard) knowledge(param string, permission types.Permission) gin.HandlerFunc {
+	return middleware.RequirePermission(g.permissionService, types.ResourceTypeKnowledgeBase,
+		func(c *gin.Context) (string, error) {
+			knowledge, err := g.knowledgeService.GetKnowledgeByID(c.Request.Context(), c.Param(param))
+			if err != nil {
+				return "", err
+			}
+			return knowledge.KnowledgeBaseID, nil
+		}, permission)
+}
+
+// chunk 경로 매개변수의 청크가 속한 지식베이스에 대한 권한 검사
+func (g *accessGuard) chunk(param string, permission types.Permission) gin.HandlerFunc {
+	return middleware.RequirePermission(g.permissionService, types.ResourceTypeKnowledgeBase,
+		func(c *gin.Context) (string, error) {
+			chunk, err := g.chunkService.GetChunkByID(c.Request.Context(), c.Param(param))
+			if err != nil {
+				return "", err
+			}
+			return chunk.KnowledgeBaseID, nil
+		}, permission)
+}
+
+// connector 경로 매개변수의 커넥터가 속한 지식베이스에 대한 권한 검사
+func (g *accessGuard) connector(param string, permission types.Permission) gin.HandlerFunc {
+	return middleware.RequirePermission(g.permissionService, types.ResourceTypeKnowledgeBase,
+		func(c *gin.Context) (string, error) {
+			kc, err := g.connectorService.GetConnector(c.Request.Context(), c.Param(param))
+			if err != nil {
+				return "", err
+			}
+			return kc.KnowledgeBaseID, nil
+		}, permission)
+}
+
+// kbCloneTask 경로 매개변수의 복사 작업이 복사하는 원본 지식베이스에 대한 권한 검사
+func (g *accessGuard) kbCloneTask(param string, permission types.Permission) gin.HandlerFunc {
+	return middleware.RequirePermission(g.permissionService, types.ResourceTypeKnowledgeBase,
+		func(c *gin.Context) (string, error) {
+			progress, err := g.knowledgeService.GetKBCloneProgress(c.Request.Context(), c.Param(param))
+			if err != nil {
+				return "", err
+			}
+			return progress.SourceID, nil
+		}, permission)
+}
+
+// kbReembedTask 경로 매개변수의 재임베딩 작업 대상 지식베이스에 대한 권한 검사
+func (g *accessGuard) kbReembedTask(param string, permission types.Permission) gin.HandlerFunc {
+	return middleware.RequirePermission(g.permissionService, types.ResourceTypeKnowledgeBase,
+		func(c *gin.Context) (string, error) {
+			progress, err := g.knowledgeService.GetKBReembedProgress(c.Request.Context(), c.Param(param))
+			if err != nil {
+				return "", err
+			}
+			return progress.KnowledgeBaseID, nil
+		}, permission)
+}
+
+// agent 경로 매개변수의 에이전트에 대한 권한 검사
+func (g *accessGuard) agent(param string, permission types.Permission) gin.HandlerFunc {
+	return middleware.RequirePermission(g.permissionService,
+		types.ResourceTypeAgent, middleware.ParamResource(param), permission)
+}
+
+// mcpService 경로 매개변수의 MCP 서비스에 대한 권한 검사
+func (g *accessGuard) mcpService(param string, permission types.Permission) gin.HandlerFunc {
+	return middleware.RequirePermission(g.permissionService,
+		types.ResourceTypeMCPService, middleware.ParamResource(param), permission)
+}
@@ -0,0 +1,221 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Tencent/WeKnora/internal/errors"
+	"github.com/Tencent/WeKnora/internal/handler"
+	"github.com/Tencent/WeKnora/internal/middleware"
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/Tencent/WeKnora/internal/types/interfaces"
+)
+
+// rolePermissionService checks the tenant role of the subject and its permissions like the permission service does.
+// Resources in grants are restricted to the permission granted to the subject, others allow the base permission
+// of the role.
+type rolePermissionService struct {
+	interfaces.PermissionService
+	grants map[string]types.Permission
+}
+
+func (s *rolePermissionService) CheckRole(ctx context.Context, role types.TenantRole) error {
+	if subject := types.AccessSubjectFromContext(ctx); subject != nil && !subject.Role.AtLeast(role) {
+		return errors.NewForbiddenError("이 작업을 수행할 권한이 없습니다")
+	}
+	return nil
+}
+
+func (s *rolePermissionService) CheckPermission(ctx context.Context,
+	resourceType types.ResourceType, resourceID string, permission types.Permission,
+) error {
+	subject := types.AccessSubjectFromContext(ctx)
+	if subject == nil || subject.Role.AtLeast(types.TenantRoleAdmin) {
+		return nil
+	}
+	allowed := subject.Role.BasePermission().Allows(permission)
+	if grant, restricted := s.grants[resourceID]; restricted {
+		allowed = grant.Allows(permission)
+	}
+	if !allowed {
+		return errors.NewForbiddenError("이 리소스에 대한 권한이 없습니다")
+	}
+	return nil
+}
+
+// testGrants restricts kb-shared to reading and kb-private to nobody but admins
+var testGrants = map[string]types.Permission{
+	"kb-shared":  types.PermissionRead,
+	"kb-private": "",
+}
+
+// fakeKnowledgeService serves the progress of the copy and re-embedding tasks
+type fakeKnowledgeService struct {
+	interfaces.KnowledgeService
+}
+
+func (s *fakeKnowledgeService) GetKBCloneProgress(ctx context.Context, taskID string) (*types.KBCloneProgress, error) {
+	sources := map[string]string{"copy-open": "kb-open", "copy-private": "kb-private"}
+	source, ok := sources[taskID]
+	if !ok {
+		return nil, errors.NewNotFoundError("KB clone task not found")
+	}
+	return &types.KBCloneProgress{TaskID: taskID, SourceID: source, TargetID: "kb-copy"}, nil
+}
+
+func (s *fakeKnowledgeService) GetKBReembedProgress(ctx context.Context, taskID string) (*types.KBReembedProgress, error) {
+	kbs := map[string]string{"reembed-shared": "kb-shared", "reembed-private": "kb-private"}
+	kbID, ok := kbs[taskID]
+	if !ok {
+		return nil, errors.NewNotFoundError("KB reembed task not found")
+	}
+	return &types.KBReembedProgress{TaskID: taskID, KnowledgeBaseID: kbID}, nil
+}
+
+// fakeToolApprovalService decides every approval request
+type fakeToolApprovalService struct {
+	interfaces.ToolApprovalService
+}
+
+func (s *fakeToolApprovalService) Approve(ctx context.Context,
+	sessionID, id string, decision *types.ToolApprovalDecision,
+) (*types.ToolApproval, error) {
+	return &types.ToolApproval{ID: id, SessionID: sessionID, Status: types.ToolApprovalApproved}, nil
+}
+
+func (s *fakeToolApprovalService) Reject(ctx context.Context,
+	sessionID, id string, decision *types.ToolApprovalDecision,
+) (*types.ToolApproval, error) {
+	return &types.ToolApproval{ID: id, SessionID: sessionID, Status: types.ToolApprovalRejected}, nil
+}
+
+// newGuardedRouter creates a router whose requests are authorized as a subject with the role
+func newGuardedRouter(role types.TenantRole, g *accessGuard,
+	register func(r *gin.RouterGroup, g *accessGuard),
+) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.Use(func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), types.TenantIDContextKey, uint64(1))
+		ctx = context.WithValue(ctx, types.AccessSubjectContextKey, &types.AccessSubject{UserID: "user", Role: role})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	})
+	register(r.Group("/api/v1"), g)
+	return r
+}
+
+func TestToolApprovalRoutesRequireEditor(t *testing.T) {
+	register := func(r *gin.RouterGroup, g *accessGuard) {
+		RegisterToolApprovalRoutes(r, handler.NewToolApprovalHandler(&fakeToolApprovalService{}), g)
+	}
+	tests := []struct {
+		role types.TenantRole
+		path string
+		want int
+	}{
+		{types.TenantRoleViewer, "/api/v1/tool-approvals/s1/a1/approve", http.StatusForbidden},
+		{types.TenantRoleViewer, "/api/v1/tool-approvals/s1/a1/reject", http.StatusForbidden},
+		{types.TenantRoleEditor, "/api/v1/tool-approvals/s1/a1/approve", http.StatusOK},
+		{types.TenantRoleEditor, "/api/v1/tool-approvals/s1/a1/reject", http.StatusOK},
+		{types.TenantRoleAdmin, "/api/v1/tool-approvals/s1/a1/approve", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			g := &accessGuard{permissionService: &rolePermissionService{}}
+			newGuardedRouter(tt.role, g, register).ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, nil))
+			if w.Code != tt.want {
+				t.Errorf("%s POST %s = %d, want %d", tt.role, tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessGuardPermissionMatrix(t *testing.T) {
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	register := func(r *gin.RouterGroup, g *accessGuard) {
+		for _, role := range []types.TenantRole{
+			types.TenantRoleViewer, types.TenantRoleEditor, types.TenantRoleAdmin, types.TenantRoleOwner,
+		} {
+			r.GET("/roles/"+string(role), g.role(role), ok)
+		}
+		r.GET("/knowledge-bases/:id", g.kb("id", types.PermissionRead), ok)
+		r.PUT("/knowledge-bases/:id", g.kb("id", types.PermissionWrite), ok)
+		r.DELETE("/knowledge-bases/:id", g.kb("id", types.PermissionManage), ok)
+	}
+	tests := []struct {
+		role   types.TenantRole
+		method string
+		path   string
+		want   int
+	}{
+		{types.TenantRoleViewer, http.MethodGet, "/api/v1/roles/viewer", http.StatusOK},
+		{types.TenantRoleViewer, http.MethodGet, "/api/v1/roles/editor", http.StatusForbidden},
+		{types.TenantRoleEditor, http.MethodGet, "/api/v1/roles/editor", http.StatusOK},
+		{types.TenantRoleEditor, http.MethodGet, "/api/v1/roles/admin", http.StatusForbidden},
+		{types.TenantRoleAdmin, http.MethodGet, "/api/v1/roles/admin", http.StatusOK},
+		{types.TenantRoleAdmin, http.MethodGet, "/api/v1/roles/owner", http.StatusForbidden},
+		{types.TenantRoleOwner, http.MethodGet, "/api/v1/roles/owner", http.StatusOK},
+
+		{types.TenantRoleViewer, http.MethodGet, "/api/v1/knowledge-bases/kb-open", http.StatusOK},
+		{types.TenantRoleViewer, http.MethodPut, "/api/v1/knowledge-bases/kb-open", http.StatusForbidden},
+		{types.TenantRoleViewer, http.MethodGet, "/api/v1/knowledge-bases/kb-shared", http.StatusOK},
+		{types.TenantRoleViewer, http.MethodGet, "/api/v1/knowledge-bases/kb-private", http.StatusForbidden},
+		{types.TenantRoleEditor, http.MethodPut, "/api/v1/knowledge-bases/kb-open", http.StatusOK},
+		{types.TenantRoleEditor, http.MethodDelete, "/api/v1/knowledge-bases/kb-open", http.StatusForbidden},
+		{types.TenantRoleEditor, http.MethodPut, "/api/v1/knowledge-bases/kb-shared", http.StatusForbidden},
+		{types.TenantRoleEditor, http.MethodGet, "/api/v1/knowledge-bases/kb-private", http.StatusForbidden},
+		{types.TenantRoleAdmin, http.MethodDelete, "/api/v1/knowledge-bases/kb-open", http.StatusOK},
+		{types.TenantRoleAdmin, http.MethodDelete, "/api/v1/knowledge-bases/kb-private", http.StatusOK},
+		{types.TenantRoleOwner, http.MethodDelete, "/api/v1/knowledge-bases/kb-private", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+tt.method+" "+tt.path, func(t *testing.T) {
+			g := &accessGuard{permissionService: &rolePermissionService{grants: testGrants}}
+			w := httptest.NewRecorder()
+			newGuardedRouter(tt.role, g, register).ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.want {
+				t.Errorf("%s %s %s = %d, want %d", tt.role, tt.method, tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestKnowledgeBaseTaskProgressRoutesRequireRead(t *testing.T) {
+	permissionService := &rolePermissionService{grants: testGrants}
+	knowledgeService := &fakeKnowledgeService{}
+	g := &accessGuard{permissionService: permissionService, knowledgeService: knowledgeService}
+	register := func(r *gin.RouterGroup, g *accessGuard) {
+		RegisterKnowledgeBaseRoutes(r,
+			handler.NewKnowledgeBaseHandler(nil, knowledgeService, permissionService, nil), g)
+	}
+	tests := []struct {
+		role types.TenantRole
+		path string
+		want int
+	}{
+		{types.TenantRoleViewer, "/api/v1/knowledge-bases/copy/progress/copy-open", http.StatusOK},
+		{types.TenantRoleViewer, "/api/v1/knowledge-bases/copy/progress/copy-private", http.StatusForbidden},
+		{types.TenantRoleViewer, "/api/v1/knowledge-bases/copy/progress/missing", http.StatusNotFound},
+		{types.TenantRoleAdmin, "/api/v1/knowledge-bases/copy/progress/copy-private", http.StatusOK},
+		{types.TenantRoleViewer, "/api/v1/knowledge-bases/reembed/progress/reembed-shared", http.StatusOK},
+		{types.TenantRoleEditor, "/api/v1/knowledge-bases/reembed/progress/reembed-private", http.StatusForbidden},
+		{types.TenantRoleViewer, "/api/v1/knowledge-bases/reembed/progress/missing", http.StatusNotFound},
+		{types.TenantRoleAdmin, "/api/v1/knowledge-bases/reembed/progress/reembed-private", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newGuardedRouter(tt.role, g, register).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.want {
+				t.Errorf("%s GET %s = %d, want %d", tt.role, tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/Tencent/WeKnora/internal/config"
 	"github.com/Tencent/WeKnora/internal/handler"
 	"github.com/Tencent/WeKnora/internal/handler/session"
+	"github.com/Tencent/WeKnora/internal/mcpserver"
 	"github.com/Tencent/WeKnora/internal/middleware"
+	"github.com/Tencent/WeKnora/internal/types"
 	"github.com/Tencent/WeKnora/internal/types/interfaces"
 
 	_ "github.com/Tencent/WeKnora/docs" // swagger docs
@@ -48,6 +50,26 @@ type RouterParams struct {
 	FAQHandler            *handler.FAQHandler
 	TagHandler            *handler.TagHandler
 	CustomAgentHandler    *handler.CustomAgentHandler
+	ConnectorHandler      *handler.ConnectorHandler
+	ConnectorService      interfaces.ConnectorService
+	PermissionService     interfaces.PermissionService
+	PermissionHandler     *handler.PermissionHandler
+	SSOHandler            *handler.SSOHandler
+	APIKeyService         interfaces.APIKeyService
+	APIKeyHandler         *handler.APIKeyHandler
+	CustomAgentService    interfaces.CustomAgentService
+	MCPServiceService     interfaces.MCPServiceService
+	AuditService          interfaces.AuditService
+	AuditHandler          *handler.AuditHandler
+	UsageHandler          *handler.UsageHandler
+	QuotaService          interfaces.QuotaService
+	QuotaHandler          *handler.QuotaHandler
+	WebhookService        interfaces.WebhookService
+	WebhookHandler        *handler.WebhookHandler
+	FeedbackHandler       *handler.FeedbackHandler
+	ToolApprovalHandler   *handler.ToolApprovalHandler
+	MemoryHandler         *handler.MemoryHandler
+	MCPServer             *mcpserver.Server
 }
 
 // NewRouter 새 라우터 생성
@@ -58,8 +80,8 @@ func NewRouter(params RouterParams) *gin.Engine {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-Request-ID"},
-		ExposeHeaders:    []string{"Content-Length", "Access-Control-Allow-Origin"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-Request-ID", types.OpenAISessionIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "Access-Control-Allow-Origin", types.OpenAISessionIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -87,113 +109,147 @@ func NewRouter(params RouterParams) *gin.Engine {
 	}
 
 	// 인증 미들웨어
-	r.Use(middleware.Auth(params.TenantService, params.UserService, params.Config))
+	r.Use(middleware.Auth(params.TenantService, params.UserService, params.APIKeyService, params.Config))
 
 	// OpenTelemetry 추적 미들웨어 추가
 	r.Use(middleware.TracingMiddleware())
 
+	// 할당량 미들웨어, 인증된 테넌트, 사용자와 API 키별로 요청 수와 스트림, 업로드를 제한
+	r.Use(middleware.Quota(params.QuotaService))
+
+	// 감사 기록 미들웨어, 인증 후 테넌트와 사용자가 확정된 요청을 기록
+	r.Use(middleware.Audit(params.AuditService, newAuditRoutes(params)))
+
 	// 인증이 필요한 API 라우트
+	// 역할 및 리소스 권한 검사
+	g := newAccessGuard(params)
+
 	v1 := r.Group("/api/v1")
 	{
 		RegisterAuthRoutes(v1, params.AuthHandler)
-		RegisterTenantRoutes(v1, params.TenantHandler)
-		RegisterKnowledgeBaseRoutes(v1, params.KBHandler)
-		RegisterKnowledgeTagRoutes(v1, params.TagHandler)
-		RegisterKnowledgeRoutes(v1, params.KnowledgeHandler)
-		RegisterFAQRoutes(v1, params.FAQHandler)
-		RegisterChunkRoutes(v1, params.ChunkHandler)
+		RegisterSSORoutes(v1, params.SSOHandler)
+		RegisterTenantRoutes(v1, params.TenantHandler, g)
+		RegisterPermissionRoutes(v1, params.PermissionHandler, g)
+		RegisterAPIKeyRoutes(v1, params.APIKeyHandler, g)
+		RegisterAuditRoutes(v1, params.AuditHandler, g)
+		RegisterUsageRoutes(v1, params.UsageHandler, g)
+		RegisterQuotaRoutes(v1, params.QuotaHandler, g)
+		RegisterWebhookRoutes(v1, params.WebhookHandler, g)
+		RegisterFeedbackRoutes(v1, params.FeedbackHandler, g)
+		RegisterMemoryRoutes(v1, params.MemoryHandler)
+		RegisterKnowledgeBaseRoutes(v1, params.KBHandler, g)
+		RegisterKnowledgeTagRoutes(v1, params.TagHandler, g)
+		RegisterConnectorRoutes(v1, params.ConnectorHandler, g)
+		RegisterKnowledgeRoutes(v1, params.KnowledgeHandler, g)
+		RegisterFAQRoutes(v1, params.FAQHandler, g)
+		RegisterChunkRoutes(v1, params.ChunkHandler, g)
 		RegisterSessionRoutes(v1, params.SessionHandler)
+		RegisterToolApprovalRoutes(v1, params.ToolApprovalHandler, g)
 		RegisterChatRoutes(v1, params.SessionHandler)
 		RegisterMessageRoutes(v1, params.MessageHandler)
-		RegisterModelRoutes(v1, params.ModelHandler)
-		RegisterEvaluationRoutes(v1, params.EvaluationHandler)
-		RegisterInitializationRoutes(v1, params.InitializationHandler)
-		RegisterSystemRoutes(v1, params.SystemHandler)
-		RegisterMCPServiceRoutes(v1, params.MCPServiceHandler)
+		RegisterModelRoutes(v1, params.ModelHandler, g)
+		RegisterEvaluationRoutes(v1, params.EvaluationHandler, g)
+		RegisterInitializationRoutes(v1, params.InitializationHandler, g)
+		RegisterSystemRoutes(v1, params.SystemHandler, g)
+		RegisterMCPServiceRoutes(v1, params.MCPServiceHandler, g)
 		RegisterWebSearchRoutes(v1, params.WebSearchHandler)
-		RegisterCustomAgentRoutes(v1, params.CustomAgentHandler)
+		RegisterCustomAgentRoutes(v1, params.CustomAgentHandler, g)
 	}
 
+	// OpenAI 호환 API, OpenAI 클라이언트의 base URL을 {host}/v1로 설정하여 사용
+	RegisterOpenAIRoutes(r.Group("/v1"), params.SessionHandler)
+
+	// MCP 서버, MCP 클라이언트에서 {host}/mcp를 streamable HTTP 서버로 등록하여 사용
+	RegisterMCPServerRoutes(r, params.MCPServer)
+
 	return r
 }
 
 // RegisterChunkRoutes 청크 관련 라우트 등록
-func RegisterChunkRoutes(r *gin.RouterGroup, handler *handler.ChunkHandler) {
+func RegisterChunkRoutes(r *gin.RouterGroup, handler *handler.ChunkHandler, g *accessGuard) {
 	// 청크 라우트 그룹
 	chunks := r.Group("/chunks")
 	{
 		// 청크 목록 조회
-		chunks.GET("/:knowledge_id", handler.ListKnowledgeChunks)
+		chunks.GET("/:knowledge_id", g.knowledge("knowledge_id", types.PermissionRead), handler.ListKnowledgeChunks)
 		// chunk_id로 단일 청크 조회 (knowledge_id 불필요)
-		chunks.GET("/by-id/:id", handler.GetChunkByIDOnly)
+		chunks.GET("/by-id/:id", g.chunk("id", types.PermissionRead), handler.GetChunkByIDOnly)
 		// 청크 삭제
-		chunks.DELETE("/:knowledge_id/:id", handler.DeleteChunk)
+		chunks.DELETE("/:knowledge_id/:id", g.knowledge("knowledge_id", types.PermissionWrite), handler.DeleteChunk)
 		// 지식 하위 모든 청크 삭제
-		chunks.DELETE("/:knowledge_id", handler.DeleteChunksByKnowledgeID)
+		chunks.DELETE("/:knowledge_id", g.knowledge("knowledge_id", types.PermissionWrite), handler.DeleteChunksByKnowledgeID)
 		// 청크 정보 업데이트
-		chunks.PUT("/:knowledge_id/:id", handler.UpdateChunk)
+		chunks.PUT("/:knowledge_id/:id", g.knowledge("knowledge_id", types.PermissionWrite), handler.UpdateChunk)
 		// 단일 생성 질문 삭제 (질문 ID 사용)
-		chunks.DELETE("/by-id/:id/questions", handler.DeleteGeneratedQuestion)
+		chunks.DELETE("/by-id/:id/questions", g.chunk("id", types.PermissionWrite), handler.DeleteGeneratedQuestion)
 	}
 }
 
 // RegisterKnowledgeRoutes 지식 관련 라우트 등록
-func RegisterKnowledgeRoutes(r *gin.RouterGroup, handler *handler.KnowledgeHandler) {
+func RegisterKnowledgeRoutes(r *gin.RouterGroup, handler *handler.KnowledgeHandler, g *accessGuard) {
 	// 지식베이스 하위 지식 라우트 그룹
 	kb := r.Group("/knowledge-bases/:id/knowledge")
 	{
 		// 파일에서 지식 생성
-		kb.POST("/file", handler.CreateKnowledgeFromFile)
+		kb.POST("/file", g.kb("id", types.PermissionWrite), handler.CreateKnowledgeFromFile)
 		// URL에서 지식 생성
-		kb.POST("/url", handler.CreateKnowledgeFromURL)
+		kb.POST("/url", g.kb("id", types.PermissionWrite), handler.CreateKnowledgeFromURL)
 		// 수동 마크다운 입력
-		kb.POST("/manual", handler.CreateManualKnowledge)
+		kb.POST("/manual", g.kb("id", types.PermissionWrite), handler.CreateManualKnowledge)
 		// 지식베이스 하위 지식 목록 조회
-		kb.GET("", handler.ListKnowledge)
+		kb.GET("", g.kb("id", types.PermissionRead), handler.ListKnowledge)
 	}
 
 	// 지식 라우트 그룹
 	k := r.Group("/knowledge")
 	{
-		// 지식 일괄 조회
+		// 지식 일괄 조회 (접근 가능한 지식베이스의 지식만 반환)
 		k.GET("/batch", handler.GetKnowledgeBatch)
 		// 지식 상세 조회
-		k.GET("/:id", handler.GetKnowledge)
+		k.GET("/:id", g.knowledge("id", types.PermissionRead), handler.GetKnowledge)
 		// 지식 삭제
-		k.DELETE("/:id", handler.DeleteKnowledge)
+		k.DELETE("/:id", g.knowledge("id", types.PermissionWrite), handler.DeleteKnowledge)
 		// 지식 업데이트
-		k.PUT("/:id", handler.UpdateKnowledge)
+		k.PUT("/:id", g.knowledge("id", types.PermissionWrite), handler.UpdateKnowledge)
 		// 수동 마크다운 지식 업데이트
-		k.PUT("/manual/:id", handler.UpdateManualKnowledge)
+		k.PUT("/manual/:id", g.knowledge("id", types.PermissionWrite), handler.UpdateManualKnowledge)
+		// 지식 파일 업데이트 (변경된 청크만 다시 처리)
+		k.PUT("/:id/file", g.knowledge("id", types.PermissionWrite), handler.UpdateKnowledgeFile)
+		// 지식 버전 목록 조회
+		k.GET("/:id/versions", g.knowledge("id", types.PermissionRead), handler.ListKnowledgeVersions)
+		// 지식 버전 비교
+		k.GET("/:id/versions/diff", g.knowledge("id", types.PermissionRead), handler.DiffKnowledgeVersions)
+		// 지식 버전 복원
+		k.POST("/:id/versions/:version/restore", g.knowledge("id", types.PermissionWrite), handler.RestoreKnowledgeVersion)
 		// 지식 파일 다운로드
-		k.GET("/:id/download", handler.DownloadKnowledgeFile)
+		k.GET("/:id/download", g.knowledge("id", types.PermissionRead), handler.DownloadKnowledgeFile)
 		// 이미지 청크 정보 업데이트
-		k.PUT("/image/:id/:chunk_id", handler.UpdateImageInfo)
-		// 지식 태그 일괄 업데이트
+		k.PUT("/image/:id/:chunk_id", g.knowledge("id", types.PermissionWrite), handler.UpdateImageInfo)
+		// 지식 태그 일괄 업데이트 (핸들러에서 지식베이스별 권한 검사)
 		k.PUT("/tags", handler.UpdateKnowledgeTagBatch)
-		// 지식 검색
+		// 지식 검색 (접근 가능한 지식베이스의 지식만 반환)
 		k.GET("/search", handler.SearchKnowledge)
 	}
 }
 
 // RegisterFAQRoutes FAQ 관련 라우트 등록
-func RegisterFAQRoutes(r *gin.RouterGroup, handler *handler.FAQHandler) {
+func RegisterFAQRoutes(r *gin.RouterGroup, handler *handler.FAQHandler, g *accessGuard) {
 	if handler == nil {
 		return
 	}
 	faq := r.Group("/knowledge-bases/:id/faq")
 	{
-		faq.GET("/entries", handler.ListEntries)
-		faq.GET("/entries/export", handler.ExportEntries)
-		faq.GET("/entries/:entry_id", handler.GetEntry)
-		faq.POST("/entries", handler.UpsertEntries)
-		faq.POST("/entry", handler.CreateEntry)
-		faq.PUT("/entries/:entry_id", handler.UpdateEntry)
+		faq.GET("/entries", g.kb("id", types.PermissionRead), handler.ListEntries)
+		faq.GET("/entries/export", g.kb("id", types.PermissionRead), handler.ExportEntries)
+		faq.GET("/entries/:entry_id", g.kb("id", types.PermissionRead), handler.GetEntry)
+		faq.POST("/entries", g.kb("id", types.PermissionWrite), handler.UpsertEntries)
+		faq.POST("/entry", g.kb("id", types.PermissionWrite), handler.CreateEntry)
+		faq.PUT("/entries/:entry_id", g.kb("id", types.PermissionWrite), handler.UpdateEntry)
 		// 통합 일괄 업데이트 API - is_enabled, is_recommended, tag_id 지원
-		faq.PUT("/entries/fields", handler.UpdateEntryFieldsBatch)
-		faq.PUT("/entries/tags", handler.UpdateEntryTagBatch)
-		faq.DELETE("/entries", handler.DeleteEntries)
-		faq.POST("/search", handler.SearchFAQ)
+		faq.PUT("/entries/fields", g.kb("id", types.PermissionWrite), handler.UpdateEntryFieldsBatch)
+		faq.PUT("/entries/tags", g.kb("id", types.PermissionWrite), handler.UpdateEntryTagBatch)
+		faq.DELETE("/entries", g.kb("id", types.PermissionWrite), handler.DeleteEntries)
+		faq.POST("/search", g.kb("id", types.PermissionRead), handler.SearchFAQ)
 	}
 	// FAQ 가져오기 진행 상황 라우트 (지식베이스 범위 외부)
 	faqImport := r.Group("/faq/import")
@@ -203,40 +259,68 @@ func RegisterFAQRoutes(r *gin.RouterGroup, handler *handler.FAQHandler) {
 }
 
 // RegisterKnowledgeBaseRoutes 지식베이스 관련 라우트 등록
-func RegisterKnowledgeBaseRoutes(r *gin.RouterGroup, handler *handler.KnowledgeBaseHandler) {
+func RegisterKnowledgeBaseRoutes(r *gin.RouterGroup, handler *handler.KnowledgeBaseHandler, g *accessGuard) {
 	// 지식베이스 라우트 그룹
 	kb := r.Group("/knowledge-bases")
 	{
 		// 지식베이스 생성
-		kb.POST("", handler.CreateKnowledgeBase)
-		// 지식베이스 목록 조회
+		kb.POST("", g.role(types.TenantRoleEditor), handler.CreateKnowledgeBase)
+		// 지식베이스 목록 조회 (접근 가능한 지식베이스만 반환)
 		kb.GET("", handler.ListKnowledgeBases)
 		// 지식베이스 상세 조회
-		kb.GET("/:id", handler.GetKnowledgeBase)
+		kb.GET("/:id", g.kb("id", types.PermissionRead), handler.GetKnowledgeBase)
 		// 지식베이스 업데이트
-		kb.PUT("/:id", handler.UpdateKnowledgeBase)
+		kb.PUT("/:id", g.kb("id", types.PermissionWrite), handler.UpdateKnowledgeBase)
 		// 지식베이스 삭제
-		kb.DELETE("/:id", handler.DeleteKnowledgeBase)
+		kb.DELETE("/:id", g.kb("id", types.PermissionManage), handler.DeleteKnowledgeBase)
 		// 하이브리드 검색
-		kb.GET("/:id/hybrid-search", handler.HybridSearch)
+		kb.GET("/:id/hybrid-search", g.kb("id", types.PermissionRead), handler.HybridSearch)
 		// 지식베이스 복사
-		kb.POST("/copy", handler.CopyKnowledgeBase)
+		kb.POST("/copy", g.role(types.TenantRoleEditor), handler.CopyKnowledgeBase)
 		// 지식베이스 복사 진행 상황 조회
-		kb.GET("/copy/progress/:task_id", handler.GetKBCloneProgress)
+		kb.GET("/copy/progress/:task_id", g.kbCloneTask("task_id", types.PermissionRead), handler.GetKBCloneProgress)
+		// 지식베이스 재임베딩
+		kb.POST("/:id/reembed", g.kb("id", types.PermissionWrite), handler.ReembedKnowledgeBase)
+		// 지식베이스 재임베딩 진행 상황 조회
+		kb.GET("/reembed/progress/:task_id", g.kbReembedTask("task_id", types.PermissionRead), handler.GetKBReembedProgress)
+	}
+}
+
+// RegisterConnectorRoutes 지식베이스 커넥터 관련 라우트 등록
+func RegisterConnectorRoutes(r *gin.RouterGroup, handler *handler.ConnectorHandler, g *accessGuard) {
+	kbConnectors := r.Group("/knowledge-bases/:id/connectors")
+	{
+		// 커넥터 생성
+		kbConnectors.POST("", g.kb("id", types.PermissionWrite), handler.CreateConnector)
+		// 지식베이스의 커넥터 목록 조회
+		kbConnectors.GET("", g.kb("id", types.PermissionRead), handler.ListConnectors)
+	}
+	connectors := r.Group("/connectors")
+	{
+		// 커넥터 상세 조회
+		connectors.GET("/:id", g.connector("id", types.PermissionRead), handler.GetConnector)
+		// 커넥터 업데이트
+		connectors.PUT("/:id", g.connector("id", types.PermissionWrite), handler.UpdateConnector)
+		// 커넥터 삭제
+		connectors.DELETE("/:id", g.connector("id", types.PermissionWrite), handler.DeleteConnector)
+		// 커넥터 동기화 실행
+		connectors.POST("/:id/sync", g.connector("id", types.PermissionWrite), handler.SyncConnector)
+		// 커넥터 동기화 상태 조회
+		connectors.GET("/:id/sync-status", g.connector("id", types.PermissionRead), handler.GetConnectorSyncStatus)
 	}
 }
 
 // RegisterKnowledgeTagRoutes 지식베이스 태그 관련 라우트 등록
-func RegisterKnowledgeTagRoutes(r *gin.RouterGroup, tagHandler *handler.TagHandler) {
+func RegisterKnowledgeTagRoutes(r *gin.RouterGroup, tagHandler *handler.TagHandler, g *accessGuard) {
 	if tagHandler == nil {
 		return
 	}
 	kbTags := r.Group("/knowledge-bases/:id/tags")
 	{
-		kbTags.GET("", tagHandler.ListTags)
-		kbTags.POST("", tagHandler.CreateTag)
-		kbTags.PUT("/:tag_id", tagHandler.UpdateTag)
-		kbTags.DELETE("/:tag_id", tagHandler.DeleteTag)
+		kbTags.GET("", g.kb("id", types.PermissionRead), tagHandler.ListTags)
+		kbTags.POST("", g.kb("id", types.PermissionWrite), tagHandler.CreateTag)
+		kbTags.PUT("/:tag_id", g.kb("id", types.PermissionWrite), tagHandler.UpdateTag)
+		kbTags.DELETE("/:tag_id", g.kb("id", types.PermissionWrite), tagHandler.DeleteTag)
 	}
 }
 
@@ -263,6 +347,8 @@ func RegisterSessionRoutes(r *gin.RouterGroup, handler *session.Handler) {
 		sessions.DELETE("/:id", handler.DeleteSession)
 		sessions.POST("/:session_id/generate_title", handler.GenerateTitle)
 		sessions.POST("/:session_id/stop", handler.StopSession)
+		// 일시 중지되었거나 중단된 에이전트 실행 재개
+		sessions.POST("/:session_id/resume", handler.ResumeSession)
 		// 활성 스트림 계속 수신
 		sessions.GET("/continue-stream/:session_id", handler.ContinueStream)
 	}
@@ -288,8 +374,22 @@ func RegisterChatRoutes(r *gin.RouterGroup, handler *session.Handler) {
 	}
 }
 
+// RegisterOpenAIRoutes OpenAI 호환 라우트 등록, model은 에이전트 ID
+func RegisterOpenAIRoutes(r *gin.RouterGroup, handler *session.Handler) {
+	r.POST("/chat/completions", handler.ChatCompletions)
+	r.GET("/models", handler.ListModels)
+}
+
+// RegisterMCPServerRoutes MCP 서버 라우트 등록, 무상태 streamable HTTP 전송으로 요청마다 인증
+func RegisterMCPServerRoutes(r *gin.Engine, server *mcpserver.Server) {
+	handler := gin.WrapH(server.HTTPHandler())
+	r.POST(mcpserver.EndpointPath, handler)
+	r.GET(mcpserver.EndpointPath, handler)
+	r.DELETE(mcpserver.EndpointPath, handler)
+}
+
 // RegisterTenantRoutes 테넌트 관련 라우트 등록
-func RegisterTenantRoutes(r *gin.RouterGroup, handler *handler.TenantHandler) {
+func RegisterTenantRoutes(r *gin.RouterGroup, handler *handler.TenantHandler, g *accessGuard) {
 	// 모든 테넌트 조회 라우트 추가 (크로스 테넌트 권한 필요)
 	r.GET("/tenants/all", handler.ListAllTenants)
 	// 테넌트 검색 라우트 추가 (크로스 테넌트 권한 필요, 페이징 및 검색 지원)
@@ -299,42 +399,56 @@ func RegisterTenantRoutes(r *gin.RouterGroup, handler *handler.TenantHandler) {
 	{
 		tenantRoutes.POST("", handler.CreateTenant)
 		tenantRoutes.GET("/:id", handler.GetTenant)
-		tenantRoutes.PUT("/:id", handler.UpdateTenant)
-		tenantRoutes.DELETE("/:id", handler.DeleteTenant)
+		tenantRoutes.PUT("/:id", g.role(types.TenantRoleAdmin), handler.UpdateTenant)
+		tenantRoutes.DELETE("/:id", g.role(types.TenantRoleOwner), handler.DeleteTenant)
 		tenantRoutes.GET("", handler.ListTenants)
 
 		// 일반적인 KV 구성 관리 (테넌트 수준)
 		// 테넌트 ID는 인증 컨텍스트에서 가져옴
 		tenantRoutes.GET("/kv/:key", handler.GetTenantKV)
-		tenantRoutes.PUT("/kv/:key", handler.UpdateTenantKV)
+		tenantRoutes.PUT("/kv/:key", g.role(types.TenantRoleAdmin), handler.UpdateTenantKV)
 	}
 }
 
 // RegisterModelRoutes 모델 관련 라우트 등록
-func RegisterModelRoutes(r *gin.RouterGroup, handler *handler.ModelHandler) {
+func RegisterModelRoutes(r *gin.RouterGroup, handler *handler.ModelHandler, g *accessGuard) {
 	// 모델 라우트 그룹
 	models := r.Group("/models")
 	{
 		// 모델 공급업체 목록 조회
 		models.GET("/providers", handler.ListModelProviders)
 		// 모델 생성
-		models.POST("", handler.CreateModel)
+		models.POST("", g.role(types.TenantRoleAdmin), handler.CreateModel)
 		// 모델 목록 조회
 		models.GET("", handler.ListModels)
 		// 단일 모델 조회
 		models.GET("/:id", handler.GetModel)
 		// 모델 업데이트
-		models.PUT("/:id", handler.UpdateModel)
+		models.PUT("/:id", g.role(types.TenantRoleAdmin), handler.UpdateModel)
 		// 모델 삭제
-		models.DELETE("/:id", handler.DeleteModel)
+		models.DELETE("/:id", g.role(types.TenantRoleAdmin), handler.DeleteModel)
 	}
 }
 
-func RegisterEvaluationRoutes(r *gin.RouterGroup, handler *handler.EvaluationHandler) {
-	evaluationRoutes := r.Group("/evaluation")
+func RegisterEvaluationRoutes(r *gin.RouterGroup, handler *handler.EvaluationHandler, g *accessGuard) {
+	evaluationRoutes := r.Group("/evaluation", g.role(types.TenantRoleEditor))
 	{
 		evaluationRoutes.POST("/", handler.Evaluation)
 		evaluationRoutes.GET("/", handler.GetEvaluationResult)
+
+		// 평가 실행 기록
+		evaluationRoutes.GET("/runs", handler.ListRuns)
+		evaluationRoutes.GET("/runs/:id", handler.GetRun)
+		evaluationRoutes.GET("/runs/:id/items", handler.ListRunItems)
+		evaluationRoutes.DELETE("/runs/:id", handler.DeleteRun)
+		evaluationRoutes.GET("/compare", handler.CompareRuns)
+
+		// 업로드한 평가 데이터셋
+		evaluationRoutes.POST("/datasets", handler.CreateDataset)
+		evaluationRoutes.GET("/datasets", handler.ListDatasets)
+		evaluationRoutes.GET("/datasets/:id", handler.GetDataset)
+		evaluationRoutes.GET("/datasets/:id/items", handler.ListDatasetItems)
+		evaluationRoutes.DELETE("/datasets/:id", handler.DeleteDataset)
 	}
 }
 
@@ -349,60 +463,72 @@ func RegisterAuthRoutes(r *gin.RouterGroup, handler *handler.AuthHandler) {
 	r.POST("/auth/change-password", handler.ChangePassword)
 }
 
-func RegisterInitializationRoutes(r *gin.RouterGroup, handler *handler.InitializationHandler) {
+// RegisterSSORoutes 싱글 사인온 관련 라우트 등록, 모두 인증 없이 접근 가능
+func RegisterSSORoutes(r *gin.RouterGroup, handler *handler.SSOHandler) {
+	sso := r.Group("/auth/sso")
+	{
+		sso.GET("/providers", handler.ListProviders)
+		sso.GET("/:provider/login", handler.Login)
+		sso.GET("/:provider/callback", handler.Callback)
+		sso.POST("/:provider/acs", handler.AssertionConsumerService)
+		sso.GET("/:provider/metadata", handler.Metadata)
+	}
+}
+
+func RegisterInitializationRoutes(r *gin.RouterGroup, handler *handler.InitializationHandler, g *accessGuard) {
 	// 초기화 인터페이스
-	r.GET("/initialization/config/:kbId", handler.GetCurrentConfigByKB)
-	r.POST("/initialization/initialize/:kbId", handler.InitializeByKB)
-	r.PUT("/initialization/config/:kbId", handler.UpdateKBConfig) // 모델 ID만 전달하는 새로운 간소화된 인터페이스
+	r.GET("/initialization/config/:kbId", g.kb("kbId", types.PermissionRead), handler.GetCurrentConfigByKB)
+	r.POST("/initialization/initialize/:kbId", g.kb("kbId", types.PermissionWrite), handler.InitializeByKB)
+	r.PUT("/initialization/config/:kbId", g.kb("kbId", types.PermissionWrite), handler.UpdateKBConfig) // 모델 ID만 전달하는 새로운 간소화된 인터페이스
 
 	// Ollama 관련 인터페이스
-	r.GET("/initialization/ollama/status", handler.CheckOllamaStatus)
-	r.GET("/initialization/ollama/models", handler.ListOllamaModels)
-	r.POST("/initialization/ollama/models/check", handler.CheckOllamaModels)
-	r.POST("/initialization/ollama/models/download", handler.DownloadOllamaModel)
-	r.GET("/initialization/ollama/download/progress/:taskId", handler.GetDownloadProgress)
-	r.GET("/initialization/ollama/download/tasks", handler.ListDownloadTasks)
+	r.GET("/initialization/ollama/status", g.role(types.TenantRoleAdmin), handler.CheckOllamaStatus)
+	r.GET("/initialization/ollama/models", g.role(types.TenantRoleAdmin), handler.ListOllamaModels)
+	r.POST("/initialization/ollama/models/check", g.role(types.TenantRoleAdmin), handler.CheckOllamaModels)
+	r.POST("/initialization/ollama/models/download", g.role(types.TenantRoleAdmin), handler.DownloadOllamaModel)
+	r.GET("/initialization/ollama/download/progress/:taskId", g.role(types.TenantRoleAdmin), handler.GetDownloadProgress)
+	r.GET("/initialization/ollama/download/tasks", g.role(types.TenantRoleAdmin), handler.ListDownloadTasks)
 
 	// 원격 API 관련 인터페이스
-	r.POST("/initialization/remote/check", handler.CheckRemoteModel)
-	r.POST("/initialization/embedding/test", handler.TestEmbeddingModel)
-	r.POST("/initialization/rerank/check", handler.CheckRerankModel)
-	r.POST("/initialization/multimodal/test", handler.TestMultimodalFunction)
-
-	r.POST("/initialization/extract/text-relation", handler.ExtractTextRelations)
-	r.POST("/initialization/extract/fabri-tag", handler.FabriTag)
-	r.POST("/initialization/extract/fabri-text", handler.FabriText)
+	r.POST("/initialization/remote/check", g.role(types.TenantRoleEditor), handler.CheckRemoteModel)
+	r.POST("/initialization/embedding/test", g.role(types.TenantRoleEditor), handler.TestEmbeddingModel)
+	r.POST("/initialization/rerank/check", g.role(types.TenantRoleEditor), handler.CheckRerankModel)
+	r.POST("/initialization/multimodal/test", g.role(types.TenantRoleEditor), handler.TestMultimodalFunction)
+
+	r.POST("/initialization/extract/text-relation", g.role(types.TenantRoleEditor), handler.ExtractTextRelations)
+	r.POST("/initialization/extract/fabri-tag", g.role(types.TenantRoleEditor), handler.FabriTag)
+	r.POST("/initialization/extract/fabri-text", g.role(types.TenantRoleEditor), handler.FabriText)
 }
 
 // RegisterSystemRoutes 시스템 정보 라우트 등록
-func RegisterSystemRoutes(r *gin.RouterGroup, handler *handler.SystemHandler) {
+func RegisterSystemRoutes(r *gin.RouterGroup, handler *handler.SystemHandler, g *accessGuard) {
 	systemRoutes := r.Group("/system")
 	{
 		systemRoutes.GET("/info", handler.GetSystemInfo)
-		systemRoutes.GET("/minio/buckets", handler.ListMinioBuckets)
+		systemRoutes.GET("/minio/buckets", g.role(types.TenantRoleAdmin), handler.ListMinioBuckets)
 	}
 }
 
 // RegisterMCPServiceRoutes MCP 서비스 라우트 등록
-func RegisterMCPServiceRoutes(r *gin.RouterGroup, handler *handler.MCPServiceHandler) {
+func RegisterMCPServiceRoutes(r *gin.RouterGroup, handler *handler.MCPServiceHandler, g *accessGuard) {
 	mcpServices := r.Group("/mcp-services")
 	{
 		// MCP 서비스 생성
-		mcpServices.POST("", handler.CreateMCPService)
-		// MCP 서비스 목록 조회
+		mcpServices.POST("", g.role(types.TenantRoleEditor), handler.CreateMCPService)
+		// MCP 서비스 목록 조회 (접근 가능한 서비스만 반환)
 		mcpServices.GET("", handler.ListMCPServices)
 		// ID로 MCP 서비스 조회
-		mcpServices.GET("/:id", handler.GetMCPService)
+		mcpServices.GET("/:id", g.mcpService("id", types.PermissionRead), handler.GetMCPService)
 		// MCP 서비스 업데이트
-		mcpServices.PUT("/:id", handler.UpdateMCPService)
+		mcpServices.PUT("/:id", g.mcpService("id", types.PermissionWrite), handler.UpdateMCPService)
 		// MCP 서비스 삭제
-		mcpServices.DELETE("/:id", handler.DeleteMCPService)
+		mcpServices.DELETE("/:id", g.mcpService("id", types.PermissionManage), handler.DeleteMCPService)
 		// MCP 서비스 연결 테스트
-		mcpServices.POST("/:id/test", handler.TestMCPService)
+		mcpServices.POST("/:id/test", g.mcpService("id", types.PermissionWrite), handler.TestMCPService)
 		// MCP 서비스 도구 조회
-		mcpServices.GET("/:id/tools", handler.GetMCPServiceTools)
+		mcpServices.GET("/:id/tools", g.mcpService("id", types.PermissionRead), handler.GetMCPServiceTools)
 		// MCP 서비스 리소스 조회
-		mcpServices.GET("/:id/resources", handler.GetMCPServiceResources)
+		mcpServices.GET("/:id/resources", g.mcpService("id", types.PermissionRead), handler.GetMCPServiceResources)
 	}
 }
 
@@ -417,22 +543,187 @@ func RegisterWebSearchRoutes(r *gin.RouterGroup, webSearchHandler *handler.WebSe
 }
 
 // RegisterCustomAgentRoutes 사용자 정의 에이전트 라우트 등록
-func RegisterCustomAgentRoutes(r *gin.RouterGroup, agentHandler *handler.CustomAgentHandler) {
+func RegisterCustomAgentRoutes(r *gin.RouterGroup, agentHandler *handler.CustomAgentHandler, g *accessGuard) {
 	agents := r.Group("/agents")
 	{
 		// 플레이스홀더 정의 조회 (충돌 방지를 위해 /:id 앞에 있어야 함)
 		agents.GET("/placeholders", agentHandler.GetPlaceholders)
 		// 사용자 정의 에이전트 생성
-		agents.POST("", agentHandler.CreateAgent)
-		// 모든 에이전트 목록 조회 (내장 포함)
+		agents.POST("", g.role(types.TenantRoleEditor), agentHandler.CreateAgent)
+		// 모든 에이전트 목록 조회 (내장 포함, 접근 가능한 에이전트만 반환)
 		agents.GET("", agentHandler.ListAgents)
 		// ID로 에이전트 조회
-		agents.GET("/:id", agentHandler.GetAgent)
+		agents.GET("/:id", g.agent("id", types.PermissionRead), agentHandler.GetAgent)
 		// 에이전트 업데이트
-		agents.PUT("/:id", agentHandler.UpdateAgent)
+		agents.PUT("/:id", g.agent("id", types.PermissionWrite), agentHandler.UpdateAgent)
 		// 에이전트 삭제
-		agents.DELETE("/:id", agentHandler.DeleteAgent)
+		agents.DELETE("/:id", g.agent("id", types.PermissionManage), agentHandler.DeleteAgent)
 		// 에이전트 복사
-		agents.POST("/:id/copy", agentHandler.CopyAgent)
+		agents.POST("/:id/copy", g.role(types.TenantRoleEditor), g.agent("id", types.PermissionRead), agentHandler.CopyAgent)
+	}
+}
+
+// RegisterPermissionRoutes 구성원, 사용자 그룹 및 리소스 권한 부여 라우트 등록
+func RegisterPermissionRoutes(r *gin.RouterGroup, handler *handler.PermissionHandler, g *accessGuard) {
+	members := r.Group("/tenant/members")
+	{
+		// 테넌트 구성원 목록 조회
+		members.GET("", handler.ListMembers)
+		// 테넌트 구성원 생성
+		members.POST("", g.role(types.TenantRoleAdmin), handler.CreateMember)
+		// 구성원 역할 변경
+		members.PUT("/:user_id/role", g.role(types.TenantRoleAdmin), handler.UpdateMemberRole)
+		// 구성원 삭제
+		members.DELETE("/:user_id", g.role(types.TenantRoleAdmin), handler.RemoveMember)
+	}
+	groups := r.Group("/groups")
+	{
+		// 사용자 그룹 목록 조회
+		groups.GET("", handler.ListGroups)
+		// 사용자 그룹 생성
+		groups.POST("", g.role(types.TenantRoleAdmin), handler.CreateGroup)
+		// 사용자 그룹 업데이트
+		groups.PUT("/:id", g.role(types.TenantRoleAdmin), handler.UpdateGroup)
+		// 사용자 그룹 삭제
+		groups.DELETE("/:id", g.role(types.TenantRoleAdmin), handler.DeleteGroup)
+		// 사용자 그룹 구성원 목록 조회
+		groups.GET("/:id/members", handler.ListGroupMembers)
+		// 사용자 그룹 구성원 추가
+		groups.POST("/:id/members", g.role(types.TenantRoleAdmin), handler.AddGroupMember)
+		// 사용자 그룹 구성원 제거
+		groups.DELETE("/:id/members/:user_id", g.role(types.TenantRoleAdmin), handler.RemoveGroupMember)
+	}
+	grants := r.Group("/grants")
+	{
+		// 리소스 권한 부여 목록 조회 (리소스 관리 권한 필요)
+		grants.GET("", handler.ListGrants)
+		// 리소스 권한 부여 설정
+		grants.POST("", handler.SetGrant)
+		// 리소스 권한 부여 삭제
+		grants.DELETE("/:id", handler.DeleteGrant)
+	}
+}
+
+// RegisterAPIKeyRoutes 테넌트 API 키 관리 라우트 등록
+func RegisterAPIKeyRoutes(r *gin.RouterGroup, handler *handler.APIKeyHandler, g *accessGuard) {
+	apiKeys := r.Group("/tenant/api-keys", g.role(types.TenantRoleAdmin))
+	{
+		// API 키 목록 조회
+		apiKeys.GET("", handler.ListAPIKeys)
+		// API 키 생성
+		apiKeys.POST("", handler.CreateAPIKey)
+		// API 키 취소
+		apiKeys.DELETE("/:id", handler.RevokeAPIKey)
+	}
+}
+
+// RegisterAuditRoutes 감사 기록 라우트 등록
+func RegisterAuditRoutes(r *gin.RouterGroup, handler *handler.AuditHandler, g *accessGuard) {
+	auditLogs := r.Group("/audit-logs", g.role(types.TenantRoleAdmin))
+	{
+		// 감사 기록 목록 조회
+		auditLogs.GET("", handler.ListAuditLogs)
+		// 감사 기록 내보내기
+		auditLogs.GET("/export", handler.ExportAuditLogs)
+		// 감사 기록 해시 체인 검증
+		auditLogs.GET("/verify", handler.VerifyAuditChain)
+	}
+}
+
+// RegisterUsageRoutes 모델 사용량 라우트 등록
+func RegisterUsageRoutes(r *gin.RouterGroup, handler *handler.UsageHandler, g *accessGuard) {
+	usage := r.Group("/usage", g.role(types.TenantRoleAdmin))
+	{
+		// 사용량 보고서 조회
+		usage.GET("", handler.GetUsageSummary)
+		// 호출별 사용량 기록 조회
+		usage.GET("/records", handler.ListUsageRecords)
+	}
+}
+
+// RegisterQuotaRoutes 할당량 라우트 등록
+func RegisterQuotaRoutes(r *gin.RouterGroup, handler *handler.QuotaHandler, g *accessGuard) {
+	quotas := r.Group("/quotas")
+	{
+		// 요청 주체의 제한과 사용량 조회
+		quotas.GET("", handler.GetQuotaStatus)
+		// 할당량 정책 관리
+		quotas.GET("/policies", g.role(types.TenantRoleAdmin), handler.ListQuotaPolicies)
+		quotas.PUT("/policies", g.role(types.TenantRoleAdmin), handler.SetQuotaPolicy)
+		quotas.DELETE("/policies", g.role(types.TenantRoleAdmin), handler.DeleteQuotaPolicy)
+	}
+}
+
+// RegisterWebhookRoutes 웹훅 라우트 등록
+func RegisterWebhookRoutes(r *gin.RouterGroup, handler *handler.WebhookHandler, g *accessGuard) {
+	webhooks := r.Group("/webhooks", g.role(types.TenantRoleAdmin))
+	{
+		// 웹훅 목록 조회
+		webhooks.GET("", handler.ListWebhooks)
+		// 웹훅 생성
+		webhooks.POST("", handler.CreateWebhook)
+		// 웹훅 조회
+		webhooks.GET("/:id", handler.GetWebhook)
+		// 웹훅 수정
+		webhooks.PUT("/:id", handler.UpdateWebhook)
+		// 웹훅 삭제
+		webhooks.DELETE("/:id", handler.DeleteWebhook)
+		// ping 이벤트 전송
+		webhooks.POST("/:id/test", handler.TestWebhook)
+		// 전달 기록 조회
+		webhooks.GET("/:id/deliveries", handler.ListWebhookDeliveries)
+		// 전달 기록 상세 조회
+		webhooks.GET("/:id/deliveries/:delivery_id", handler.GetWebhookDelivery)
+		// 전달 기록 재전송
+		webhooks.POST("/:id/deliveries/:delivery_id/redeliver", handler.RedeliverWebhook)
+	}
+}
+
+// RegisterToolApprovalRoutes 에이전트 도구 호출 승인 라우트 등록
+func RegisterToolApprovalRoutes(r *gin.RouterGroup, handler *handler.ToolApprovalHandler, g *accessGuard) {
+	approvals := r.Group("/tool-approvals/:session_id")
+	{
+		// 승인 요청 목록 조회
+		approvals.GET("", handler.ListToolApprovals)
+		// 승인 요청 조회
+		approvals.GET("/:id", handler.GetToolApproval)
+		// 도구 호출 승인, 쓰기 도구가 실행되므로 편집자 이상만 결정할 수 있음
+		approvals.POST("/:id/approve", g.role(types.TenantRoleEditor), handler.ApproveToolApproval)
+		// 도구 호출 거절
+		approvals.POST("/:id/reject", g.role(types.TenantRoleEditor), handler.RejectToolApproval)
+	}
+}
+
+// RegisterFeedbackRoutes 응답 피드백 라우트 등록
+func RegisterFeedbackRoutes(r *gin.RouterGroup, handler *handler.FeedbackHandler, g *accessGuard) {
+	// 메시지 피드백 제출 및 삭제
+	r.PUT("/messages/:session_id/:id/feedback", handler.SubmitFeedback)
+	r.DELETE("/messages/:session_id/:id/feedback", handler.DeleteFeedback)
+
+	feedback := r.Group("/feedback", g.role(types.TenantRoleEditor))
+	{
+		// 피드백 목록 조회
+		feedback.GET("", handler.ListFeedback)
+		// 청크 신호 목록 조회
+		feedback.GET("/chunk-signals", handler.ListChunkSignals)
+		// 평가 데이터셋 내보내기
+		feedback.GET("/dataset", handler.ExportFeedbackDataset)
+	}
+}
+
+// RegisterMemoryRoutes 사용자 기억 라우트 등록
+func RegisterMemoryRoutes(r *gin.RouterGroup, handler *handler.MemoryHandler) {
+	memories := r.Group("/memories")
+	{
+		// 기억 목록 조회
+		memories.GET("", handler.ListMemories)
+		// 기억 전체 삭제
+		memories.DELETE("", handler.DeleteAllMemories)
+		// 기억 조회
+		memories.GET("/:id", handler.GetMemory)
+		// 기억 수정
+		memories.PUT("/:id", handler.UpdateMemory)
+		// 기억 삭제
+		memories.DELETE("/:id", handler.DeleteMemory)
 	}
 }
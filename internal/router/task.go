@@ -1,11 +1,13 @@
 package router
 
 import (
+	"context"
 	"log"
 	"os"
 	"strconv"
 	"time"
 
+	"github.com/Tencent/WeKnora/internal/handler/session"
 	"github.com/Tencent/WeKnora/internal/types"
 	"github.com/Tencent/WeKnora/internal/types/interfaces"
 	"github.com/hibiken/asynq"
@@ -16,9 +18,18 @@ type AsynqTaskParams struct {
 	dig.In
 
 	Server               *asynq.Server
+	Scheduler            *asynq.Scheduler
 	KnowledgeService     interfaces.KnowledgeService
 	KnowledgeBaseService interfaces.KnowledgeBaseService
 	TagService           interfaces.KnowledgeTagService
+	ConnectorService     interfaces.ConnectorService
+	AuditService         interfaces.AuditService
+	UsageService         interfaces.UsageService
+	WebhookService       interfaces.WebhookService
+	FeedbackService      interfaces.FeedbackService
+	AgentRunService      interfaces.AgentRunService
+	MemoryService        interfaces.MemoryService
+	SessionHandler       *session.Handler
 	ChunkExtracter       interfaces.TaskHandler `name:"chunkExtracter"`
 	DataTableSummary     interfaces.TaskHandler `name:"dataTableSummary"`
 }
@@ -65,10 +76,18 @@ func NewAsynqServer() *asynq.Server {
 	return srv
 }
 
+// NewAsynqScheduler creates the scheduler enqueueing periodic tasks
+func NewAsynqScheduler() *asynq.Scheduler {
+	return asynq.NewScheduler(getAsynqRedisClientOpt(), nil)
+}
+
 func RunAsynqServer(params AsynqTaskParams) *asynq.ServeMux {
 	// Create a new mux and register all handlers
 	mux := asynq.NewServeMux()
 
+	// Attribute model usage of background tasks to the task type
+	mux.Use(usageScopeMiddleware)
+
 	// Register extract handlers - router will dispatch to appropriate handler
 	mux.HandleFunc(types.TypeChunkExtract, params.ChunkExtracter.Handle)
 	mux.HandleFunc(types.TypeDataTableSummary, params.DataTableSummary.Handle)
@@ -87,6 +106,7 @@ func RunAsynqServer(params AsynqTaskParams) *asynq.ServeMux {
 
 	// Register KB clone handler
 	mux.HandleFunc(types.TypeKBClone, params.KnowledgeService.ProcessKBClone)
+	mux.HandleFunc(types.TypeKBReembed, params.KnowledgeService.ProcessKBReembed)
 
 	// Register index delete handler
 	mux.HandleFunc(types.TypeIndexDelete, params.TagService.ProcessIndexDelete)
@@ -94,11 +114,79 @@ func RunAsynqServer(params AsynqTaskParams) *asynq.ServeMux {
 	// Register KB delete handler
 	mux.HandleFunc(types.TypeKBDelete, params.KnowledgeBaseService.ProcessKBDelete)
 
+	// Register connector handlers
+	mux.HandleFunc(types.TypeConnectorSync, params.ConnectorService.ProcessConnectorSync)
+	mux.HandleFunc(types.TypeConnectorSchedule, params.ConnectorService.ProcessConnectorSchedule)
+
+	// Check for due connector syncs every minute, the task is unique so that
+	// several server instances do not check at the same time
+	if _, err := params.Scheduler.Register("@every 1m",
+		asynq.NewTask(types.TypeConnectorSchedule, nil),
+		asynq.Queue("low"), asynq.Unique(50*time.Second), asynq.MaxRetry(0),
+	); err != nil {
+		log.Fatalf("could not register connector schedule: %v", err)
+	}
+
+	// Register audit retention handler, old audit logs are purged hourly
+	mux.HandleFunc(types.TypeAuditRetention, params.AuditService.ProcessAuditRetention)
+	if _, err := params.Scheduler.Register("@every 1h",
+		asynq.NewTask(types.TypeAuditRetention, nil),
+		asynq.Queue("low"), asynq.Unique(50*time.Minute), asynq.MaxRetry(0),
+	); err != nil {
+		log.Fatalf("could not register audit retention: %v", err)
+	}
+
+	// Register usage rollup handler, usage records are rolled up every ten minutes
+	mux.HandleFunc(types.TypeUsageRollup, params.UsageService.ProcessUsageRollup)
+	if _, err := params.Scheduler.Register("@every 10m",
+		asynq.NewTask(types.TypeUsageRollup, nil),
+		asynq.Queue("low"), asynq.Unique(9*time.Minute), asynq.MaxRetry(0),
+	); err != nil {
+		log.Fatalf("could not register usage rollup: %v", err)
+	}
+
+	// Register webhook handlers, deliveries are retried with backoff and old deliveries are purged hourly
+	mux.HandleFunc(types.TypeWebhookDelivery, params.WebhookService.ProcessWebhookDelivery)
+	mux.HandleFunc(types.TypeWebhookRetention, params.WebhookService.ProcessWebhookRetention)
+	if _, err := params.Scheduler.Register("@every 1h",
+		asynq.NewTask(types.TypeWebhookRetention, nil),
+		asynq.Queue("low"), asynq.Unique(50*time.Minute), asynq.MaxRetry(0),
+	); err != nil {
+		log.Fatalf("could not register webhook retention: %v", err)
+	}
+
+	// Register feedback aggregation, enqueued with a delay when feedback changes
+	mux.HandleFunc(types.TypeFeedbackAggregate, params.FeedbackService.ProcessFeedbackAggregate)
+
+	// Register agent run recovery, runs of stopped instances are resumed from their last checkpoint
+	mux.HandleFunc(types.TypeAgentRunResume, params.SessionHandler.ProcessAgentRunResume)
+	mux.HandleFunc(types.TypeAgentRunRecovery, params.AgentRunService.ProcessAgentRunRecovery)
+	if _, err := params.Scheduler.Register("@every 30s",
+		asynq.NewTask(types.TypeAgentRunRecovery, nil),
+		asynq.Queue("low"), asynq.Unique(25*time.Second), asynq.MaxRetry(0),
+	); err != nil {
+		log.Fatalf("could not register agent run recovery: %v", err)
+	}
+
+	// Register user memory extraction, enqueued when an answer of a signed-in user completes
+	mux.HandleFunc(types.TypeMemoryExtract, params.MemoryService.ProcessMemoryExtract)
+
 	go func() {
 		// Start the server
 		if err := params.Server.Run(mux); err != nil {
 			log.Fatalf("could not run server: %v", err)
 		}
 	}()
+	if err := params.Scheduler.Start(); err != nil {
+		log.Fatalf("could not start scheduler: %v", err)
+	}
 	return mux
 }
+
+// usageScopeMiddleware sets the usage source of a task to its type, so that model calls
+// made while processing documents or generating questions are reported separately
+func usageScopeMiddleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		return next.ProcessTask(types.WithUsageScope(ctx, types.UsageScope{Source: t.Type()}), t)
+	})
+}
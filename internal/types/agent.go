@@ -29,6 +29,32 @@ type AgentConfig struct {
 	// MCP service selection
 	MCPSelectionMode string   `json:"mcp_selection_mode"` // MCP selection mode: "all", "selected", "none"
 	MCPServices      []string `json:"mcp_services"`       // Selected MCP service IDs (when mode is "selected")
+	// Tool execution
+	MaxParallelToolCalls int `json:"max_parallel_tool_calls"` // Maximum tool calls of a round running at once (0 uses the default)
+	ToolTimeoutSeconds   int `json:"tool_timeout_seconds"`    // Timeout of a single tool call in seconds (0 uses the default)
+	// Tool approval
+	ToolApprovalPolicies       map[string]ToolApprovalPolicy `json:"tool_approval_policies,omitempty"` // Approval policy per tool name
+	MCPApprovalPolicies        map[string]ToolApprovalPolicy `json:"mcp_approval_policies,omitempty"`  // Approval policy per MCP service ID, for all of its tools
+	ToolApprovalTimeoutSeconds int                           `json:"tool_approval_timeout_seconds"`    // How long a tool call waits for approval (0 uses the default)
+	// Delegation
+	SubAgents       []string `json:"sub_agents,omitempty"` // Custom agent IDs the agent may delegate tasks to
+	DelegationDepth int      `json:"delegation_depth"`     // Nesting depth of the agent, 0 for the agent answering the user
+	// Long-term memory
+	UserMemories string `json:"user_memories,omitempty"` // Memories of the user recalled for the query, rendered into {{user_memories}}
+}
+
+// ApprovalPolicy resolves the approval policy of a tool. A policy set for the tool name takes precedence
+// over the one set for the MCP service providing it; tools without a policy run without approval.
+func (c *AgentConfig) ApprovalPolicy(toolName, mcpServiceID string) ToolApprovalPolicy {
+	if policy, ok := c.ToolApprovalPolicies[toolName]; ok && policy.IsValid() {
+		return policy
+	}
+	if mcpServiceID != "" {
+		if policy, ok := c.MCPApprovalPolicies[mcpServiceID]; ok && policy.IsValid() {
+			return policy
+		}
+	}
+	return ToolApprovalAuto
 }
 
 // SessionAgentConfig represents session-level agent configuration
@@ -115,6 +141,20 @@ type Tool interface {
 	Execute(ctx context.Context, args json.RawMessage) (*ToolResult, error)
 }
 
+// ParallelizableTool is optionally implemented by tools to declare whether their calls may run
+// concurrently with other tool calls of the same round. Tools that do not implement it are parallelizable.
+type ParallelizableTool interface {
+	// Parallelizable reports whether the tool can run alongside other tool calls
+	Parallelizable() bool
+}
+
+// LongRunningTool is optionally implemented by tools whose calls may take longer than the
+// per-call timeout of the agent, such as a tool running another agent.
+type LongRunningTool interface {
+	// Timeout returns the timeout of a single call to the tool
+	Timeout() time.Duration
+}
+
 // ToolResult represents the result of a tool execution
 type ToolResult struct {
 	Success bool                   `json:"success"`         // Whether the tool executed successfully
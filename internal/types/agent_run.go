@@ -0,0 +1,87 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AgentRunStatus 에이전트 실행 상태
+type AgentRunStatus string
+
+const (
+	AgentRunRunning     AgentRunStatus = "running"     // 인스턴스에서 실행 중
+	AgentRunPaused      AgentRunStatus = "paused"      // 사용자가 일시 중지, 사용자가 재개할 때까지 대기
+	AgentRunInterrupted AgentRunStatus = "interrupted" // 실행하던 인스턴스가 종료되어 다른 인스턴스에서 재개 대기
+	AgentRunCompleted   AgentRunStatus = "completed"   // 최종 답변 생성 완료
+	AgentRunFailed      AgentRunStatus = "failed"      // 오류로 종료
+)
+
+// IsResumable 마지막 체크포인트부터 재개할 수 있는 상태인지 확인합니다.
+func (s AgentRunStatus) IsResumable() bool {
+	return s == AgentRunPaused || s == AgentRunInterrupted
+}
+
+// AgentRun 어시스턴트 메시지 하나를 생성하는 에이전트 실행입니다.
+// ReAct 라운드가 끝날 때마다 상태와 LLM 메시지를 체크포인트로 저장하므로,
+// 중단된 실행은 다른 인스턴스에서 마지막으로 완료된 라운드부터 이어서 실행할 수 있습니다.
+type AgentRun struct {
+	// 실행의 고유 식별자
+	ID string `json:"id"              gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"`
+	// 세션 ID
+	SessionID string `json:"session_id"      gorm:"type:varchar(36)"`
+	// 생성 중인 어시스턴트 메시지 ID
+	MessageID string `json:"message_id"      gorm:"type:varchar(36);uniqueIndex"`
+	// 사용자 정의 에이전트 ID
+	AgentID string `json:"agent_id"        gorm:"type:varchar(36)"`
+	// 사용자 질문
+	Query string `json:"query"           gorm:"type:text"`
+	// 실행에 사용하는 에이전트 구성, 재개할 때 그대로 사용
+	Config AgentConfig `json:"-"               gorm:"type:jsonb"`
+	// 대화 모델 ID
+	ModelID string `json:"model_id"        gorm:"type:varchar(64)"`
+	// 재정렬 모델 ID, 지식베이스가 없으면 비어 있음
+	RerankModelID string `json:"rerank_model_id" gorm:"type:varchar(64)"`
+	// 실행을 요청한 사용자 ID, 재개할 때 같은 권한으로 실행
+	UserID string `json:"user_id"         gorm:"type:varchar(36)"`
+	// 실행을 요청한 사용자의 역할
+	UserRole TenantRole `json:"-"               gorm:"type:varchar(32)"`
+	// 실행 상태
+	Status AgentRunStatus `json:"status"          gorm:"type:varchar(16)"`
+	// 완료된 라운드 수
+	Round int `json:"round"`
+	// 마지막 체크포인트의 에이전트 상태 (AgentState)
+	State JSON `json:"-"               gorm:"type:jsonb"`
+	// 마지막 체크포인트의 LLM 메시지 목록
+	Messages JSON `json:"-"               gorm:"type:jsonb"`
+	// 실행 중인 인스턴스 ID
+	Owner string `json:"owner"           gorm:"type:varchar(64)"`
+	// 실행을 시작하거나 이어받은 횟수
+	Attempts int `json:"attempts"`
+	// 실행 중인 인스턴스가 마지막으로 갱신한 시간, 오래되면 중단된 것으로 봄
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+	// 실패한 경우 오류 메시지
+	Error string `json:"error"           gorm:"type:text"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 AgentRun 엔티티에 대한 UUID를 생성합니다.
+func (r *AgentRun) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// AgentRunResumePayload 중단된 에이전트 실행 재개 작업 페이로드
+type AgentRunResumePayload struct {
+	TenantID  uint64 `json:"tenant_id"`
+	SessionID string `json:"session_id"`
+	MessageID string `json:"message_id"`
+}
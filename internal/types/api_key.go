@@ -0,0 +1,121 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyScope API 키로 호출할 수 있는 API 범위
+type APIKeyScope string
+
+const (
+	APIKeyScopeSearch APIKeyScope = "search" // 지식베이스와 지식 조회 및 검색 (읽기 전용)
+	APIKeyScopeChat   APIKeyScope = "chat"   // 세션 생성과 대화
+	APIKeyScopeIngest APIKeyScope = "ingest" // 지식, 청크, FAQ, 태그 및 커넥터 편집
+	APIKeyScopeAdmin  APIKeyScope = "admin"  // 모든 API
+)
+
+// IsValid 유효한 범위인지 확인합니다.
+func (s APIKeyScope) IsValid() bool {
+	switch s {
+	case APIKeyScopeSearch, APIKeyScopeChat, APIKeyScopeIngest, APIKeyScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// TenantAPIKey 테넌트의 이름 있는 API 키를 나타냅니다.
+// 키 원문은 생성할 때 한 번만 반환되고, 데이터베이스에는 해시만 저장됩니다.
+type TenantAPIKey struct {
+	// API 키의 고유 식별자
+	ID string `json:"id"                  gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"           gorm:"index"`
+	// 키 이름
+	Name string `json:"name"                gorm:"type:varchar(255);not null"`
+	// 키를 구별하기 위해 표시하는 키 앞부분
+	Prefix string `json:"prefix"              gorm:"type:varchar(32);not null"`
+	// 키의 SHA-256 해시
+	KeyHash string `json:"-"                   gorm:"type:varchar(64);uniqueIndex;not null"`
+	// 허용된 범위
+	Scopes StringArray `json:"scopes"              gorm:"type:json"`
+	// 접근할 수 있는 지식베이스 ID 목록, 비어 있으면 모든 지식베이스
+	KnowledgeBaseIDs StringArray `json:"knowledge_base_ids"  gorm:"type:json"`
+	// 만료 시간, 비어 있으면 만료되지 않음
+	ExpiresAt *time.Time `json:"expires_at"`
+	// 마지막 사용 시간
+	LastUsedAt *time.Time `json:"last_used_at"`
+	// 취소 시간
+	RevokedAt *time.Time `json:"revoked_at"`
+	// 키를 생성한 사용자 ID
+	CreatedBy string `json:"created_by"          gorm:"type:varchar(36)"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 TenantAPIKey 엔티티에 대한 UUID를 생성합니다.
+func (k *TenantAPIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// HasScope 키가 주어진 범위 중 하나를 가지는지 확인합니다. admin 범위는 모든 범위를 포함합니다.
+func (k *TenantAPIKey) HasScope(scopes ...APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if APIKeyScope(s) == APIKeyScopeAdmin {
+			return true
+		}
+		for _, scope := range scopes {
+			if APIKeyScope(s) == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllowsKnowledgeBase 키가 지식베이스에 접근할 수 있는지 확인합니다.
+func (k *TenantAPIKey) AllowsKnowledgeBase(kbID string) bool {
+	if len(k.KnowledgeBaseIDs) == 0 {
+		return true
+	}
+	for _, id := range k.KnowledgeBaseIDs {
+		if id == kbID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive 키가 취소되거나 만료되지 않았는지 확인합니다.
+func (k *TenantAPIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// APIKeyFromContext 컨텍스트에서 요청을 인증한 API 키를 가져옵니다. 테넌트 기본 키나 사용자 토큰이면 nil을 반환합니다.
+func APIKeyFromContext(ctx context.Context) *TenantAPIKey {
+	key, _ := ctx.Value(APIKeyContextKey).(*TenantAPIKey)
+	return key
+}
+
+// CreateAPIKeyRequest API 키 생성 요청을 나타냅니다.
+type CreateAPIKeyRequest struct {
+	Name             string        `json:"name"               binding:"required,max=255"`
+	Scopes           []APIKeyScope `json:"scopes"             binding:"required"`
+	KnowledgeBaseIDs []string      `json:"knowledge_base_ids"`
+	ExpiresAt        *time.Time    `json:"expires_at"`
+}
+
+// CreateAPIKeyResponse 생성된 API 키를 나타냅니다. Key는 이 응답에서만 확인할 수 있습니다.
+type CreateAPIKeyResponse struct {
+	*TenantAPIKey
+	// 키 원문
+	Key string `json:"key"`
+}
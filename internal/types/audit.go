@@ -0,0 +1,146 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditActorType 감사 기록의 행위자 유형
+type AuditActorType string
+
+const (
+	AuditActorUser      AuditActorType = "user"       // 로그인한 사용자
+	AuditActorAPIKey    AuditActorType = "api_key"    // 테넌트 API 키
+	AuditActorTenantKey AuditActorType = "tenant_key" // 테넌트 기본 API 키
+	AuditActorSystem    AuditActorType = "system"     // 백그라운드 작업
+)
+
+// 감사 기록의 리소스 유형
+const (
+	AuditResourceKnowledgeBase = "knowledge_base"
+	AuditResourceKnowledge     = "knowledge"
+	AuditResourceChunk         = "chunk"
+	AuditResourceFAQEntry      = "faq_entry"
+	AuditResourceTag           = "tag"
+	AuditResourceConnector     = "connector"
+	AuditResourceModel         = "model"
+	AuditResourceAgent         = "agent"
+	AuditResourceMCPService    = "mcp_service"
+	AuditResourceTenant        = "tenant"
+	AuditResourceMember        = "member"
+	AuditResourceGroup         = "group"
+	AuditResourceGrant         = "grant"
+	AuditResourceAPIKey        = "api_key"
+	AuditResourceWebhook       = "webhook"
+	AuditResourceSession       = "session"
+	AuditResourceMessage       = "message"
+	AuditResourceAuditLog      = "audit_log"
+	AuditResourceEvaluation    = "evaluation"
+	AuditResourceMemory        = "memory"
+)
+
+// AuditLog 관리 작업 또는 데이터 접근 작업의 감사 기록을 나타냅니다.
+// 테넌트별로 순번이 매겨지고 이전 기록의 해시를 포함하여 해시 체인을 이루므로, 기록을 수정하거나 삭제하면 검증에서 드러납니다.
+type AuditLog struct {
+	// 감사 기록의 고유 식별자
+	ID string `json:"id"            gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"     gorm:"uniqueIndex:idx_audit_logs_tenant_seq,priority:1"`
+	// 테넌트 내 순번, 1부터 시작
+	Seq uint64 `json:"seq"           gorm:"uniqueIndex:idx_audit_logs_tenant_seq,priority:2"`
+	// 행위자 유형
+	ActorType AuditActorType `json:"actor_type"    gorm:"type:varchar(32)"`
+	// 행위자 ID (사용자 ID 또는 API 키 ID)
+	ActorID string `json:"actor_id"      gorm:"type:varchar(64);index"`
+	// 행위자 이름
+	ActorName string `json:"actor_name"    gorm:"type:varchar(255)"`
+	// 작업, 예: knowledge_base.delete
+	Action string `json:"action"        gorm:"type:varchar(128);index"`
+	// 리소스 유형
+	ResourceType string `json:"resource_type" gorm:"type:varchar(64)"`
+	// 리소스 ID
+	ResourceID string `json:"resource_id"   gorm:"type:varchar(255)"`
+	// HTTP 메서드
+	Method string `json:"method"        gorm:"type:varchar(16)"`
+	// 요청 경로
+	Path string `json:"path"          gorm:"type:varchar(512)"`
+	// 응답 상태 코드
+	StatusCode int `json:"status_code"`
+	// 클라이언트 IP
+	ClientIP string `json:"client_ip"     gorm:"type:varchar(64)"`
+	// 요청 ID
+	RequestID string `json:"request_id"    gorm:"type:varchar(64)"`
+	// 변경 내용, AuditChange 목록
+	Changes JSON `json:"changes"       gorm:"type:json"`
+	// 추가 정보 (요청 본문, 도구 인수 등)
+	Detail JSON `json:"detail"        gorm:"type:json"`
+	// 이전 기록의 해시, 첫 기록은 빈 문자열
+	PrevHash string `json:"prev_hash"     gorm:"type:varchar(64)"`
+	// 이 기록의 해시
+	Hash string `json:"hash"          gorm:"type:varchar(64)"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"    gorm:"index"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 AuditLog 엔티티에 대한 UUID를 생성합니다.
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// AuditChange 리소스 필드 하나의 변경 전후 값
+type AuditChange struct {
+	// 필드 경로, 중첩 필드는 점으로 구분
+	Field string `json:"field"`
+	// 변경 전 값, 생성이면 비어 있음
+	Before interface{} `json:"before,omitempty"`
+	// 변경 후 값, 삭제면 비어 있음
+	After interface{} `json:"after,omitempty"`
+}
+
+// AuditChainHead 테넌트 감사 해시 체인의 마지막 상태를 나타냅니다.
+// 기록을 추가할 때 잠금으로 사용되며, 체인 끝의 기록이 삭제되었는지 검증하는 데 사용됩니다.
+type AuditChainHead struct {
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"          gorm:"primaryKey;autoIncrement:false"`
+	// 마지막 기록의 순번
+	Seq uint64 `json:"seq"`
+	// 마지막 기록의 해시
+	Hash string `json:"hash"               gorm:"type:varchar(64)"`
+	// 보존 기간이 지나 삭제된 마지막 순번
+	PurgedThroughSeq uint64 `json:"purged_through_seq"`
+	// 삭제된 마지막 기록의 해시, 남은 첫 기록의 이전 해시와 일치해야 함
+	PurgedThroughHash string `json:"purged_through_hash" gorm:"type:varchar(64)"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AuditLogFilter 감사 기록 조회 조건
+type AuditLogFilter struct {
+	ActorID      string     `form:"actor_id"`
+	ActorType    string     `form:"actor_type"`
+	Action       string     `form:"action"`
+	ResourceType string     `form:"resource_type"`
+	ResourceID   string     `form:"resource_id"`
+	From         *time.Time `form:"from"          time_format:"2006-01-02T15:04:05Z07:00"`
+	To           *time.Time `form:"to"            time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// AuditChainVerification 감사 해시 체인 검증 결과
+type AuditChainVerification struct {
+	// 체인이 온전한지 여부
+	Valid bool `json:"valid"`
+	// 검증한 기록 수
+	Checked int64 `json:"checked"`
+	// 검증한 첫 순번과 마지막 순번
+	FirstSeq uint64 `json:"first_seq"`
+	LastSeq  uint64 `json:"last_seq"`
+	// 문제가 발견된 순번
+	BrokenSeq uint64 `json:"broken_seq,omitempty"`
+	// 문제 설명
+	Reason string `json:"reason,omitempty"`
+}
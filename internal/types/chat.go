@@ -26,14 +26,7 @@ type ChatResponse struct {
 	// Finish reason
 	FinishReason string `json:"finish_reason,omitempty"` // "stop", "tool_calls", "length", etc.
 	// Usage information
-	Usage struct {
-		// Prompt tokens
-		PromptTokens int `json:"prompt_tokens"`
-		// Completion tokens
-		CompletionTokens int `json:"completion_tokens"`
-		// Total tokens
-		TotalTokens int `json:"total_tokens"`
-	} `json:"usage"`
+	Usage TokenUsage `json:"usage"`
 }
 
 // Response type
@@ -50,6 +43,8 @@ const (
 	ResponseTypeToolCall ResponseType = "tool_call"
 	// Tool result response type (for agent tool results)
 	ResponseTypeToolResult ResponseType = "tool_result"
+	// Tool approval response type (for agent tool calls waiting for or resolved by user approval)
+	ResponseTypeToolApproval ResponseType = "tool_approval"
 	// Error response type
 	ResponseTypeError ResponseType = "error"
 	// Reflection response type (for agent reflection)
@@ -60,6 +55,10 @@ const (
 	ResponseTypeAgentQuery ResponseType = "agent_query"
 	// Complete response type (agent complete)
 	ResponseTypeComplete ResponseType = "complete"
+	// Paused response type (agent run paused, it can be resumed from its last completed round)
+	ResponseTypePaused ResponseType = "paused"
+	// Sub-agent response type (event of an agent running a task delegated by the agent)
+	ResponseTypeSubAgent ResponseType = "sub_agent"
 )
 
 // StreamResponse stream response
@@ -82,6 +81,8 @@ type StreamResponse struct {
 	ToolCalls []LLMToolCall `json:"tool_calls,omitempty"`
 	// Additional metadata for enhanced display
 	Data map[string]interface{} `json:"data,omitempty"`
+	// Token usage, set on the final response when the provider reports it
+	Usage *TokenUsage `json:"usage,omitempty"`
 }
 
 // References references
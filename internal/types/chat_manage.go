@@ -10,6 +10,8 @@ type ChatManage struct {
 
 	KnowledgeBaseIDs []string `json:"knowledge_base_ids"`      // IDs of knowledge bases to search (multi-KB support)
 	KnowledgeIDs     []string `json:"knowledge_ids,omitempty"` // IDs of specific files to search (optional)
+	// Filter is the metadata filter applied to every knowledge base search (optional)
+	Filter *MetadataFilter `json:"filter,omitempty"`
 	// SearchTargets is the pre-computed unified search targets
 	// Computed once at request entry point, used throughout the pipeline
 	SearchTargets    SearchTargets `json:"-"`
@@ -29,6 +31,7 @@ type ChatManage struct {
 	FallbackStrategy FallbackStrategy `json:"fallback_strategy"` // Strategy when no relevant results are found
 	FallbackResponse string           `json:"fallback_response"` // Default response when fallback occurs
 	FallbackPrompt   string           `json:"fallback_prompt"`   // Prompt for model-based fallback response
+	UserMemories     string           `json:"-"`                 // Memories of the user rendered into {{user_memories}}
 
 	EnableRewrite        bool   `json:"enable_rewrite"`         // Whether to enable rewrite
 	EnableQueryExpansion bool   `json:"enable_query_expansion"` // Whether to enable query expansion with LLM
@@ -90,6 +93,7 @@ func (c *ChatManage) Clone() *ChatManage {
 		SessionID:        c.SessionID,
 		KnowledgeBaseIDs: knowledgeBaseIDs,
 		KnowledgeIDs:     knowledgeIDs,
+		Filter:           c.Filter,
 		SearchTargets:    searchTargets,
 		VectorThreshold:  c.VectorThreshold,
 		KeywordThreshold: c.KeywordThreshold,
@@ -118,6 +122,7 @@ func (c *ChatManage) Clone() *ChatManage {
 		FallbackStrategy:     c.FallbackStrategy,
 		FallbackResponse:     c.FallbackResponse,
 		FallbackPrompt:       c.FallbackPrompt,
+		UserMemories:         c.UserMemories,
 		RewritePromptSystem:  c.RewritePromptSystem,
 		RewritePromptUser:    c.RewritePromptUser,
 		EnableRewrite:        c.EnableRewrite,
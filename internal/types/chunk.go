@@ -3,6 +3,8 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"gorm.io/gorm"
@@ -139,7 +141,7 @@ type Chunk struct {
 	IndirectRelationChunks JSON `json:"indirect_relation_chunks" gorm:"type:json"`
 	// Metadata 청크 수준의 확장 정보 저장 (예: FAQ 메타데이터)
 	Metadata JSON `json:"metadata"                 gorm:"type:json"`
-	// ContentHash 빠른 매칭을 위한 내용 해시 값 저장 (주로 FAQ에 사용)
+	// ContentHash 빠른 매칭을 위한 내용 해시 값 저장 (FAQ 중복 제거 및 문서 증분 업데이트에 사용)
 	ContentHash string `json:"content_hash"             gorm:"type:varchar(64);index"`
 	// 이미지 정보, JSON으로 저장
 	ImageInfo string `json:"image_info"               gorm:"type:text"`
@@ -150,3 +152,10 @@ type Chunk struct {
 	// Soft delete marker, supports data recovery
 	DeletedAt gorm.DeletedAt `json:"deleted_at"               gorm:"index"`
 }
+
+// CalculateChunkContentHash 문서 청크 내용의 해시 값을 계산합니다.
+// 문서 업데이트 시 변경되지 않은 청크를 찾는 데 사용됩니다.
+func CalculateChunkContentHash(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,260 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ConnectorType 외부 소스 커넥터의 유형
+type ConnectorType string
+
+const (
+	ConnectorTypeLocal ConnectorType = "local" // 로컬 또는 NFS 디렉터리
+	ConnectorTypeGit   ConnectorType = "git"   // Git 저장소
+	ConnectorTypeS3    ConnectorType = "s3"    // S3/MinIO 버킷 접두사
+	ConnectorTypeWeb   ConnectorType = "web"   // 웹사이트 크롤링 (시작 URL 또는 sitemap.xml)
+)
+
+// ConnectorSyncStatus 커넥터 동기화 상태
+type ConnectorSyncStatus string
+
+const (
+	ConnectorSyncStatusIdle      ConnectorSyncStatus = "idle"      // 아직 동기화되지 않음
+	ConnectorSyncStatusPending   ConnectorSyncStatus = "pending"   // 동기화 작업 대기 중
+	ConnectorSyncStatusSyncing   ConnectorSyncStatus = "syncing"   // 동기화 중
+	ConnectorSyncStatusCompleted ConnectorSyncStatus = "completed" // 마지막 동기화 완료
+	ConnectorSyncStatusFailed    ConnectorSyncStatus = "failed"    // 마지막 동기화 실패
+)
+
+// KnowledgeConnector 외부 소스의 항목을 지식베이스로 주기적으로 동기화하는 커넥터를 나타냅니다.
+// 동기화 상태, 커서 및 오류는 커넥터와 함께 저장됩니다.
+type KnowledgeConnector struct {
+	// 커넥터의 고유 식별자
+	ID string `json:"id"                gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"         gorm:"index"`
+	// 동기화 대상 지식베이스 ID
+	KnowledgeBaseID string `json:"knowledge_base_id" gorm:"type:varchar(36);index"`
+	// 커넥터 이름
+	Name string `json:"name"              gorm:"type:varchar(255);not null"`
+	// 커넥터 유형
+	Type ConnectorType `json:"type"              gorm:"type:varchar(32);not null"`
+	// 소스 구성
+	Config ConnectorConfig `json:"config"            gorm:"type:json"`
+	// 예약 동기화 활성화 여부
+	Enabled bool `json:"enabled"           gorm:"default:true"`
+	// 예약 동기화 간격(분), 0이면 수동 동기화만 수행
+	SyncIntervalMinutes int `json:"sync_interval_minutes"`
+	// 동기화 상태
+	SyncStatus ConnectorSyncStatus `json:"sync_status"       gorm:"type:varchar(32);default:'idle'"`
+	// 마지막으로 성공한 동기화 시점의 소스 커서, 커서가 같으면 소스가 변경되지 않은 것으로 간주
+	Cursor string `json:"cursor"            gorm:"type:varchar(255)"`
+	// 마지막 동기화 오류
+	LastError string `json:"last_error"        gorm:"type:text"`
+	// 마지막 동기화 통계
+	LastSyncStats ConnectorSyncStats `json:"last_sync_stats"   gorm:"type:json"`
+	// 동기화된 항목 수
+	ItemCount int `json:"item_count"`
+	// 마지막 동기화 시간
+	LastSyncAt *time.Time `json:"last_sync_at"`
+	// 마지막 성공 동기화 시간
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+	// 삭제 시간
+	DeletedAt gorm.DeletedAt `json:"deleted_at"        gorm:"index"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 KnowledgeConnector 엔티티에 대한 UUID를 생성합니다.
+func (c *KnowledgeConnector) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// NextSyncAt 다음 예약 동기화 시간을 반환합니다. 예약 동기화가 비활성화된 경우 nil을 반환합니다.
+func (c *KnowledgeConnector) NextSyncAt() *time.Time {
+	if !c.Enabled || c.SyncIntervalMinutes <= 0 {
+		return nil
+	}
+	if c.LastSyncAt == nil {
+		now := time.Now()
+		return &now
+	}
+	next := c.LastSyncAt.Add(time.Duration(c.SyncIntervalMinutes) * time.Minute)
+	return &next
+}
+
+// IsSyncDue 예약 동기화 시간이 되었는지 확인합니다.
+func (c *KnowledgeConnector) IsSyncDue(now time.Time) bool {
+	next := c.NextSyncAt()
+	return next != nil && !next.After(now)
+}
+
+// ConnectorConfig 커넥터 소스 구성을 나타냅니다. 사용되는 필드는 커넥터 유형에 따라 다릅니다.
+type ConnectorConfig struct {
+	// 로컬 커넥터: 디렉터리 경로, CONNECTOR_LOCAL_ROOTS에 설정된 디렉터리 하위만 허용
+	Path string `json:"path,omitempty"`
+	// Git 커넥터: 저장소 URL (http/https)
+	RepoURL string `json:"repo_url,omitempty"`
+	// Git 커넥터: 브랜치, 비어 있으면 기본 브랜치
+	Branch string `json:"branch,omitempty"`
+	// Git 커넥터: 인증 사용자 이름
+	Username string `json:"username,omitempty"`
+	// Git 커넥터: 액세스 토큰
+	Token string `json:"token,omitempty"`
+	// S3 커넥터: 엔드포인트
+	Endpoint string `json:"endpoint,omitempty"`
+	// S3 커넥터: 액세스 키 ID
+	AccessKeyID string `json:"access_key_id,omitempty"`
+	// S3 커넥터: 비밀 액세스 키
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	// S3 커넥터: 버킷 이름
+	Bucket string `json:"bucket,omitempty"`
+	// S3 커넥터: 객체 키 접두사
+	Prefix string `json:"prefix,omitempty"`
+	// S3 커넥터: SSL 사용 여부
+	UseSSL bool `json:"use_ssl,omitempty"`
+	// 웹 커넥터: 크롤링 시작 URL 또는 sitemap.xml URL, 같은 호스트의 링크만 따라감
+	SeedURL string `json:"seed_url,omitempty"`
+	// 웹 커넥터: 시작 페이지로부터 따라갈 최대 링크 깊이, 0이면 기본값
+	MaxDepth int `json:"max_depth,omitempty"`
+	// 웹 커넥터: 수집할 최대 페이지 수, 0이면 기본값
+	MaxPages int `json:"max_pages,omitempty"`
+	// 동기화할 항목의 경로 패턴, 비어 있으면 모든 항목 (웹 커넥터는 /docs/* 와 같은 URL 경로)
+	IncludePatterns []string `json:"include_patterns,omitempty"`
+	// 동기화에서 제외할 항목의 경로 패턴
+	ExcludePatterns []string `json:"exclude_patterns,omitempty"`
+}
+
+// Value 데이터베이스 저장을 위한 driver.Valuer 인터페이스 구현
+func (c ConnectorConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan 데이터베이스 값을 ConnectorConfig로 변환하는 sql.Scanner 인터페이스 구현
+func (c *ConnectorConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(b, c)
+}
+
+// ConnectorSyncStats 한 번의 동기화 결과 통계를 나타냅니다.
+type ConnectorSyncStats struct {
+	Listed    int      `json:"listed"`    // 소스에서 나열된 항목 수
+	Added     int      `json:"added"`     // 새로 생성된 지식 수
+	Updated   int      `json:"updated"`   // 파일이 업데이트된 지식 수
+	Deleted   int      `json:"deleted"`   // 소스에서 삭제되어 삭제된 지식 수
+	Unchanged int      `json:"unchanged"` // 변경되지 않은 항목 수
+	Skipped   int      `json:"skipped"`   // 지원되지 않는 형식 또는 크기 제한으로 건너뛴 항목 수
+	Failed    int      `json:"failed"`    // 실패한 항목 수, 다음 동기화에서 다시 시도됨
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// Value 데이터베이스 저장을 위한 driver.Valuer 인터페이스 구현
+func (s ConnectorSyncStats) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan 데이터베이스 값을 ConnectorSyncStats로 변환하는 sql.Scanner 인터페이스 구현
+func (s *ConnectorSyncStats) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(b, s)
+}
+
+// ConnectorItem 커넥터로 동기화된 소스 항목과 지식의 매핑을 나타냅니다.
+type ConnectorItem struct {
+	// 항목의 고유 식별자
+	ID string `json:"id"           gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"`
+	// 커넥터 ID
+	ConnectorID string `json:"connector_id" gorm:"type:varchar(36);index"`
+	// 소스 내 항목 키 (상대 경로 또는 객체 키)
+	ItemKey string `json:"item_key"     gorm:"type:text"`
+	// 항목이 동기화된 지식 ID
+	KnowledgeID string `json:"knowledge_id" gorm:"type:varchar(36)"`
+	// 마지막으로 동기화된 항목 버전 (ETag, Last-Modified, Git blob 해시 또는 수정 시간과 크기)
+	Version string `json:"version"      gorm:"type:varchar(255)"`
+	// 항목 크기
+	Size int64 `json:"size"`
+	// 마지막 동기화 시간
+	SyncedAt time.Time `json:"synced_at"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 ConnectorItem 엔티티에 대한 UUID를 생성합니다.
+func (i *ConnectorItem) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ConnectorSourceItem 커넥터가 소스에서 나열한 항목을 나타냅니다.
+type ConnectorSourceItem struct {
+	// 소스 내 항목 키 (상대 경로 또는 객체 키), 지식의 파일 이름으로 사용됨
+	Key string `json:"key"`
+	// 항목 버전, 버전이 바뀌면 항목이 변경된 것으로 간주
+	Version string `json:"version"`
+	// 항목 크기
+	Size int64 `json:"size"`
+	// 수정 시간
+	ModifiedAt time.Time `json:"modified_at"`
+	// 웹 페이지 URL, 설정된 항목은 파일 대신 URL로 지식을 생성
+	URL string `json:"url,omitempty"`
+	// 웹 페이지 제목
+	Title string `json:"title,omitempty"`
+}
+
+// ConnectorListing 커넥터가 나열한 소스의 현재 상태를 나타냅니다.
+type ConnectorListing struct {
+	// 소스의 항목
+	Items []*ConnectorSourceItem `json:"items"`
+	// 나열된 상태를 나타내는 커서 (Git 커밋 해시 또는 목록의 요약 해시)
+	Cursor string `json:"cursor"`
+}
+
+// ConnectorSyncState 커넥터의 동기화 상태 조회 결과를 나타냅니다.
+type ConnectorSyncState struct {
+	ConnectorID     string              `json:"connector_id"`
+	KnowledgeBaseID string              `json:"knowledge_base_id"`
+	Type            ConnectorType       `json:"type"`
+	Status          ConnectorSyncStatus `json:"status"`
+	Cursor          string              `json:"cursor"`
+	LastError       string              `json:"last_error"`
+	LastSyncStats   ConnectorSyncStats  `json:"last_sync_stats"`
+	ItemCount       int                 `json:"item_count"`
+	LastSyncAt      *time.Time          `json:"last_sync_at"`
+	LastSuccessAt   *time.Time          `json:"last_success_at"`
+	NextSyncAt      *time.Time          `json:"next_sync_at"`
+}
+
+// ConnectorSyncPayload 커넥터 동기화 작업 페이로드를 나타냅니다.
+type ConnectorSyncPayload struct {
+	TenantID    uint64 `json:"tenant_id"`
+	ConnectorID string `json:"connector_id"`
+	// 커서가 같아도 모든 항목을 비교
+	Force bool `json:"force,omitempty"`
+}
@@ -12,6 +12,12 @@ const (
 	RequestIDContextKey ContextKey = "RequestID"
 	// LoggerContextKey is the context key for logger
 	LoggerContextKey ContextKey = "Logger"
+	// AccessSubjectContextKey is the context key for the user and role a request is authorized as
+	AccessSubjectContextKey ContextKey = "AccessSubject"
+	// APIKeyContextKey is the context key for the tenant API key a request is authorized with
+	APIKeyContextKey ContextKey = "APIKey"
+	// UsageScopeContextKey is the context key for the session, agent and source model usage is attributed to
+	UsageScopeContextKey ContextKey = "UsageScope"
 )
 
 // String returns the string representation of the context key
@@ -91,6 +91,18 @@ type CustomAgentConfig struct {
 	MCPSelectionMode string `yaml:"mcp_selection_mode" json:"mcp_selection_mode"`
 	// Selected MCP service IDs (only used when MCPSelectionMode is "selected")
 	MCPServices []string `yaml:"mcp_services" json:"mcp_services"`
+	// Maximum number of tool calls of one round executed concurrently (only for agent type, 0 uses the default)
+	MaxParallelToolCalls int `yaml:"max_parallel_tool_calls" json:"max_parallel_tool_calls"`
+	// Timeout of a single tool call in seconds (only for agent type, 0 uses the default)
+	ToolTimeoutSeconds int `yaml:"tool_timeout_seconds" json:"tool_timeout_seconds"`
+	// Approval policy ("auto", "ask" or "deny") per tool name, e.g. database_query or mcp.{service}.{tool} (only for agent type)
+	ToolApprovalPolicies map[string]ToolApprovalPolicy `yaml:"tool_approval_policies" json:"tool_approval_policies"`
+	// Approval policy per MCP service ID, applied to all tools of the service without their own policy (only for agent type)
+	MCPApprovalPolicies map[string]ToolApprovalPolicy `yaml:"mcp_approval_policies" json:"mcp_approval_policies"`
+	// Seconds a tool call waits for approval before it is treated as rejected (only for agent type, 0 uses the default)
+	ToolApprovalTimeoutSeconds int `yaml:"tool_approval_timeout_seconds" json:"tool_approval_timeout_seconds"`
+	// IDs of other agents in agent mode this agent may delegate sub-questions to (only for agent type)
+	SubAgents []string `yaml:"sub_agents" json:"sub_agents"`
 
 	// ===== Knowledge Base Settings =====
 	// Knowledge base selection mode: "all" = all KBs, "selected" = specific KBs, "none" = no KB
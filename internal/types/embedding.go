@@ -37,4 +37,6 @@ type IndexInfo struct {
 	KnowledgeType   string     // 지식 유형 (예: "faq", "manual")
 	TagID           string     // 분류를 위한 태그 ID (FAQ 우선순위 필터링에 사용)
 	IsEnabled       bool       // 검색을 위해 청크가 활성화되었는지 여부
+	// 메타데이터 필터링을 위해 인덱스에 비정규화되는 지식 및 청크 속성 (nil이면 저장하지 않음)
+	Attributes *IndexAttributes
 }
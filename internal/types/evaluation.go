@@ -1,10 +1,13 @@
 package types
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/yanyiwu/gojieba"
+	"gorm.io/gorm"
 )
 
 // Jieba is a global instance of Chinese text segmentation tool
@@ -41,6 +44,11 @@ type EvaluationDetail struct {
 	Metric *MetricResult   `json:"metric,omitempty"` // Evaluation metrics
 }
 
+// IsFinished reports whether the task is no longer pending or running
+func (s EvaluationStatue) IsFinished() bool {
+	return s == EvaluationStatueSuccess || s == EvaluationStatueFailed
+}
+
 // String returns JSON representation of EvaluationTask
 func (e *EvaluationTask) String() string {
 	b, _ := json.Marshal(e)
@@ -62,6 +70,26 @@ type MetricResult struct {
 	GenerationMetrics GenerationMetrics `json:"generation_metrics"` // Text generation quality metrics
 }
 
+// Value implements driver.Valuer interface for MetricResult
+func (m *MetricResult) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner interface for MetricResult
+func (m *MetricResult) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(b, m)
+}
+
 // RetrievalMetrics contains metrics for retrieval evaluation
 type RetrievalMetrics struct {
 	Precision float64 `json:"precision"` // Precision score
@@ -97,3 +125,172 @@ const (
 	StateAfterComplete                      // After completion
 	StateEnd                                // Evaluation ended
 )
+
+// DefaultEvaluationDatasetID is the built-in sample dataset, evaluated against a temporary knowledge base
+// built from its passages
+const DefaultEvaluationDatasetID = "default"
+
+// EvaluationRequest starts an evaluation run
+type EvaluationRequest struct {
+	Name      string `json:"name"`       // Optional run name
+	DatasetID string `json:"dataset_id"` // Uploaded dataset ID, the built-in dataset when empty or "default"
+	// Knowledge base to evaluate. Uploaded datasets run against it directly,
+	// the built-in dataset copies its models into a temporary knowledge base.
+	KnowledgeBaseID string `json:"knowledge_base_id"`
+	// Custom agent whose models and retrieval settings are evaluated, its knowledge bases are used
+	// when no knowledge base is given
+	AgentID       string `json:"agent_id"`
+	ChatModelID   string `json:"chat_id"`   // Chat model override
+	RerankModelID string `json:"rerank_id"` // Rerank model override
+}
+
+// EvaluationRunConfig is the retrieval and generation configuration an evaluation run was made with
+type EvaluationRunConfig struct {
+	ChatModelID              string  `json:"chat_model_id"`
+	RerankModelID            string  `json:"rerank_model_id"`
+	VectorThreshold          float64 `json:"vector_threshold"`
+	KeywordThreshold         float64 `json:"keyword_threshold"`
+	EmbeddingTopK            int     `json:"embedding_top_k"`
+	RerankTopK               int     `json:"rerank_top_k"`
+	RerankThreshold          float64 `json:"rerank_threshold"`
+	FAQPriorityEnabled       bool    `json:"faq_priority_enabled"`
+	FAQDirectAnswerThreshold float64 `json:"faq_direct_answer_threshold"`
+	FAQScoreBoost            float64 `json:"faq_score_boost"`
+	Temperature              float64 `json:"temperature"`
+}
+
+// Value implements driver.Valuer interface for EvaluationRunConfig
+func (c *EvaluationRunConfig) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner interface for EvaluationRunConfig
+func (c *EvaluationRunConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(b, c)
+}
+
+// EvaluationDataset is an uploaded QA dataset
+type EvaluationDataset struct {
+	ID          string    `json:"id"          gorm:"type:varchar(36);primaryKey"`
+	TenantID    uint64    `json:"tenant_id"   gorm:"index"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Format      string    `json:"format"` // Uploaded file format: csv or jsonl
+	ItemCount   int       `json:"item_count"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BeforeCreate generates the dataset ID
+func (d *EvaluationDataset) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// EvaluationDatasetItem is a question of a dataset with its gold chunks and/or gold answer
+type EvaluationDatasetItem struct {
+	ID           uint64      `json:"-"              gorm:"primaryKey;autoIncrement"`
+	DatasetID    string      `json:"dataset_id"     gorm:"type:varchar(36)"`
+	TenantID     uint64      `json:"-"`
+	Seq          int         `json:"seq"`
+	Question     string      `json:"question"`
+	Answer       string      `json:"answer"`
+	GoldChunkIDs StringArray `json:"gold_chunk_ids" gorm:"type:json"`
+}
+
+// EvaluationRun is a persisted evaluation of a dataset against a knowledge base and configuration
+type EvaluationRun struct {
+	ID               string               `json:"id"                 gorm:"type:varchar(36);primaryKey"`
+	TenantID         uint64               `json:"tenant_id"          gorm:"index"`
+	Name             string               `json:"name"`
+	DatasetID        string               `json:"dataset_id"         gorm:"type:varchar(36)"`
+	KnowledgeBaseIDs StringArray          `json:"knowledge_base_ids" gorm:"type:json"`
+	AgentID          string               `json:"agent_id"`
+	Config           *EvaluationRunConfig `json:"config"             gorm:"type:jsonb"`
+	Status           EvaluationStatue     `json:"status"`
+	ErrMsg           string               `json:"err_msg,omitempty"`
+	Total            int                  `json:"total"`
+	Finished         int                  `json:"finished"`
+	Metric           *MetricResult        `json:"metric,omitempty"   gorm:"type:jsonb"`
+	StartedAt        time.Time            `json:"started_at"`
+	FinishedAt       *time.Time           `json:"finished_at,omitempty"`
+	CreatedBy        string               `json:"created_by"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
+
+// BeforeCreate generates the run ID
+func (r *EvaluationRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// EvaluationRunItem is the result of a question of an evaluation run
+type EvaluationRunItem struct {
+	ID                uint64        `json:"-"                   gorm:"primaryKey;autoIncrement"`
+	RunID             string        `json:"run_id"              gorm:"type:varchar(36)"`
+	TenantID          uint64        `json:"-"`
+	Seq               int           `json:"seq"`
+	Question          string        `json:"question"`
+	ExpectedAnswer    string        `json:"expected_answer"`
+	GoldChunkIDs      StringArray   `json:"gold_chunk_ids"      gorm:"type:json"`
+	RetrievedChunkIDs StringArray   `json:"retrieved_chunk_ids" gorm:"type:json"`
+	Answer            string        `json:"answer"`
+	Metric            *MetricResult `json:"metric"              gorm:"type:jsonb"`
+	Error             string        `json:"error,omitempty"`
+	LatencyMs         int64         `json:"latency_ms"`
+	CreatedAt         time.Time     `json:"created_at"`
+}
+
+// EvaluationRunFilter filters evaluation runs
+type EvaluationRunFilter struct {
+	DatasetID string `form:"dataset_id"`
+	AgentID   string `form:"agent_id"`
+}
+
+// MetricRegression is a metric that dropped more than the tolerance between two runs
+type MetricRegression struct {
+	Metric string  `json:"metric"`
+	Base   float64 `json:"base"`
+	Target float64 `json:"target"`
+	Delta  float64 `json:"delta"`
+}
+
+// QuestionComparison compares the results of a question in two runs
+type QuestionComparison struct {
+	Question string             `json:"question"`
+	Base     *EvaluationRunItem `json:"base"`
+	Target   *EvaluationRunItem `json:"target"`
+	Delta    *MetricResult      `json:"delta"`
+	// Whether a retrieval metric of the question dropped more than the tolerance
+	Regressed bool `json:"regressed"`
+}
+
+// EvaluationComparison compares a target run with a base run side by side.
+// The comparison passes when no retrieval metric dropped more than the tolerance.
+type EvaluationComparison struct {
+	Base        *EvaluationRun       `json:"base"`
+	Target      *EvaluationRun       `json:"target"`
+	Tolerance   float64              `json:"tolerance"`
+	Delta       *MetricResult        `json:"delta"`
+	Regressions []MetricRegression   `json:"regressions"`
+	Passed      bool                 `json:"passed"`
+	Questions   []QuestionComparison `json:"questions"`
+	// Questions of either run without a counterpart in the other
+	Unmatched int `json:"unmatched"`
+}
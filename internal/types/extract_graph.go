@@ -10,6 +10,17 @@ const (
 	TypeIndexDelete        = "index:delete"        // 인덱스 삭제 작업
 	TypeKBDelete           = "kb:delete"           // 지식베이스 삭제 작업
 	TypeDataTableSummary   = "datatable:summary"   // 데이터 테이블 요약 작업
+	TypeKBReembed          = "kb:reembed"          // 지식베이스 재임베딩 작업
+	TypeConnectorSync      = "connector:sync"      // 커넥터 동기화 작업
+	TypeConnectorSchedule  = "connector:schedule"  // 예약 동기화 대상 커넥터 확인 작업
+	TypeAuditRetention     = "audit:retention"     // 보존 기간이 지난 감사 기록 삭제 작업
+	TypeUsageRollup        = "usage:rollup"        // 사용량 일별 집계 및 오래된 기록 삭제 작업
+	TypeWebhookDelivery    = "webhook:delivery"    // 웹훅 전송 작업
+	TypeWebhookRetention   = "webhook:retention"   // 보존 기간이 지난 웹훅 전달 기록 삭제 작업
+	TypeFeedbackAggregate  = "feedback:aggregate"  // 테넌트 피드백을 청크 신호로 집계하는 작업
+	TypeAgentRunRecovery   = "agent:recovery"      // 중단된 에이전트 실행 확인 작업
+	TypeAgentRunResume     = "agent:resume"        // 중단된 에이전트 실행 재개 작업
+	TypeMemoryExtract      = "memory:extract"      // 대화에서 사용자 기억을 추출하는 작업
 )
 
 // ExtractChunkPayload 청크 추출 작업 페이로드를 나타냅니다.
@@ -33,6 +44,7 @@ type DocumentProcessPayload struct {
 	EnableMultimodel         bool     `json:"enable_multimodel"`
 	EnableQuestionGeneration bool     `json:"enable_question_generation"` // 질문 생성 활성화 여부
 	QuestionCount            int      `json:"question_count,omitempty"`   // 청크당 생성할 질문 수
+	Incremental              bool     `json:"incremental,omitempty"`      // 기존 청크와 비교하여 변경된 청크만 다시 처리
 }
 
 // FAQImportPayload FAQ 가져오기 작업 페이로드를 나타냅니다.
@@ -47,10 +59,11 @@ type FAQImportPayload struct {
 
 // QuestionGenerationPayload 질문 생성 작업 페이로드를 나타냅니다.
 type QuestionGenerationPayload struct {
-	TenantID        uint64 `json:"tenant_id"`
-	KnowledgeBaseID string `json:"knowledge_base_id"`
-	KnowledgeID     string `json:"knowledge_id"`
-	QuestionCount   int    `json:"question_count"`
+	TenantID        uint64   `json:"tenant_id"`
+	KnowledgeBaseID string   `json:"knowledge_base_id"`
+	KnowledgeID     string   `json:"knowledge_id"`
+	QuestionCount   int      `json:"question_count"`
+	ChunkIDs        []string `json:"chunk_ids,omitempty"` // 질문을 생성할 청크 ID, 비어 있으면 모든 텍스트 청크
 }
 
 // SummaryGenerationPayload 요약 생성 작업 페이로드를 나타냅니다.
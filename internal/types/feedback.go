@@ -0,0 +1,163 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// FeedbackRating 응답 평가
+type FeedbackRating string
+
+const (
+	// FeedbackRatingUp 도움이 된 응답
+	FeedbackRatingUp FeedbackRating = "up"
+	// FeedbackRatingDown 도움이 되지 않은 응답
+	FeedbackRatingDown FeedbackRating = "down"
+)
+
+// IsValid 지원하는 평가인지 확인
+func (r FeedbackRating) IsValid() bool {
+	return r == FeedbackRatingUp || r == FeedbackRatingDown
+}
+
+// FeedbackReason 부정적 평가의 이유
+type FeedbackReason string
+
+const (
+	// FeedbackReasonIncorrect 응답 내용이 틀림
+	FeedbackReasonIncorrect FeedbackReason = "incorrect"
+	// FeedbackReasonIncomplete 응답 내용이 부족함
+	FeedbackReasonIncomplete FeedbackReason = "incomplete"
+	// FeedbackReasonWrongCitation 인용한 청크가 응답과 맞지 않음
+	FeedbackReasonWrongCitation FeedbackReason = "wrong_citation"
+	// FeedbackReasonIrrelevant 질문과 관련 없는 응답
+	FeedbackReasonIrrelevant FeedbackReason = "irrelevant"
+	// FeedbackReasonOutdated 오래된 정보
+	FeedbackReasonOutdated FeedbackReason = "outdated"
+	// FeedbackReasonOther 기타
+	FeedbackReasonOther FeedbackReason = "other"
+)
+
+// IsValid 지원하는 이유인지 확인
+func (r FeedbackReason) IsValid() bool {
+	switch r {
+	case FeedbackReasonIncorrect, FeedbackReasonIncomplete, FeedbackReasonWrongCitation,
+		FeedbackReasonIrrelevant, FeedbackReasonOutdated, FeedbackReasonOther:
+		return true
+	}
+	return false
+}
+
+// BlamesReferences 인용된 청크 전체가 잘못되었음을 뜻하는 이유인지 확인
+func (r FeedbackReason) BlamesReferences() bool {
+	return r == FeedbackReasonWrongCitation || r == FeedbackReasonIrrelevant
+}
+
+// ReferenceFeedback 응답이 인용한 청크 하나의 관련성 평가
+type ReferenceFeedback struct {
+	// 청크 ID, 메시지의 knowledge_references에 있어야 함
+	ChunkID string `json:"chunk_id"`
+	// 지식 ID
+	KnowledgeID string `json:"knowledge_id"`
+	// 질문과 관련 있는 청크인지 여부
+	Relevant bool `json:"relevant"`
+}
+
+// MessageFeedback 어시스턴트 메시지에 대한 피드백, 메시지의 knowledge_references와 함께 저장됨
+type MessageFeedback struct {
+	// 평가
+	Rating FeedbackRating `json:"rating"`
+	// 이유
+	Reason FeedbackReason `json:"reason,omitempty"`
+	// 의견
+	Comment string `json:"comment,omitempty"`
+	// 인용 청크별 관련성
+	References []ReferenceFeedback `json:"references,omitempty"`
+	// 피드백을 남긴 사용자 ID
+	UserID string `json:"user_id,omitempty"`
+	// 마지막 수정 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Value implements driver.Valuer interface for MessageFeedback
+func (f *MessageFeedback) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	return json.Marshal(f)
+}
+
+// Scan implements sql.Scanner interface for MessageFeedback
+func (f *MessageFeedback) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(b, f)
+}
+
+// SubmitFeedbackRequest 메시지 피드백 제출 요청, 이전 피드백을 대체함
+type SubmitFeedbackRequest struct {
+	Rating     FeedbackRating      `json:"rating"     binding:"required"`
+	Reason     FeedbackReason      `json:"reason"`
+	Comment    string              `json:"comment"`
+	References []ReferenceFeedback `json:"references"`
+}
+
+// FeedbackFilter 피드백 조회 필터
+type FeedbackFilter struct {
+	Rating    FeedbackRating `form:"rating"`
+	Reason    FeedbackReason `form:"reason"`
+	SessionID string         `form:"session_id"`
+	From      *time.Time     `form:"from"       time_format:"2006-01-02T15:04:05Z07:00"`
+	To        *time.Time     `form:"to"         time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// FeedbackRecord 피드백이 있는 어시스턴트 메시지와 그 질문
+type FeedbackRecord struct {
+	MessageID           string           `json:"message_id"           gorm:"column:message_id"`
+	SessionID           string           `json:"session_id"           gorm:"column:session_id"`
+	RequestID           string           `json:"request_id"           gorm:"column:request_id"`
+	Question            string           `json:"question"             gorm:"column:question"`
+	Answer              string           `json:"answer"               gorm:"column:answer"`
+	KnowledgeReferences References       `json:"knowledge_references" gorm:"column:knowledge_references"`
+	Feedback            *MessageFeedback `json:"feedback"             gorm:"column:feedback"`
+	FeedbackAt          time.Time        `json:"feedback_at"          gorm:"column:feedback_at"`
+}
+
+// ChunkFeedbackSignal 피드백으로 집계한 청크별 관련성 신호, 검색 결과 재정렬에 사용됨
+type ChunkFeedbackSignal struct {
+	TenantID    uint64 `json:"tenant_id"    gorm:"primaryKey"`
+	ChunkID     string `json:"chunk_id"     gorm:"type:varchar(36);primaryKey"`
+	KnowledgeID string `json:"knowledge_id" gorm:"type:varchar(36)"`
+	// 긍정 신호 가중 합계
+	Positive float64 `json:"positive"`
+	// 부정 신호 가중 합계
+	Negative float64 `json:"negative"`
+	// -1(강등)부터 1(가산)까지의 점수
+	Score     float64   `json:"score"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FeedbackAggregatePayload 테넌트 피드백 집계 작업 페이로드
+type FeedbackAggregatePayload struct {
+	TenantID uint64 `json:"tenant_id"`
+}
+
+// FeedbackDatasetItem 피드백으로 만든 평가 데이터셋 항목, JSONL 한 줄로 내보내짐
+type FeedbackDatasetItem struct {
+	// 질문
+	Question string `json:"question"`
+	// 긍정 평가를 받은 응답, 부정 평가이면 비어 있음
+	Answer string `json:"answer,omitempty"`
+	// 질문과 관련 있는 청크 ID
+	GoldChunkIDs []string `json:"gold_chunk_ids"`
+	// 관련 없다고 평가된 청크 ID
+	NegativeChunkIDs []string `json:"negative_chunk_ids,omitempty"`
+	// 원본 메시지 ID
+	SourceMessageID string `json:"source_message_id"`
+}
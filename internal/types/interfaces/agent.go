@@ -26,11 +26,28 @@ type AgentEngine interface {
 		sessionID, messageID, query string,
 		llmContext []chat.Message,
 	) (*types.AgentState, error)
+
+	// Resume continues an agent run from a checkpoint, messages are the LLM messages of the
+	// checkpoint including the system prompt and the query
+	Resume(
+		ctx context.Context,
+		sessionID, messageID, query string,
+		state *types.AgentState,
+		messages []chat.Message,
+	) (*types.AgentState, error)
+}
+
+// AgentCheckpointer stores the state of an agent run after each completed round
+type AgentCheckpointer interface {
+	// SaveCheckpoint stores the state and LLM messages of the last completed round.
+	// It returns errors.ErrAgentRunSuspended when the run no longer belongs to this execution.
+	SaveCheckpoint(ctx context.Context, state *types.AgentState, messages []chat.Message) error
 }
 
 // AgentService defines the interface for agent-related operations
 type AgentService interface {
-	// CreateAgentEngine creates an agent engine with the given configuration, EventBus, and ContextManager
+	// CreateAgentEngine creates an agent engine with the given configuration, EventBus, and ContextManager.
+	// The checkpointer is optional, without it the run is not checkpointed.
 	CreateAgentEngine(
 		ctx context.Context,
 		config *types.AgentConfig,
@@ -39,6 +56,7 @@ type AgentService interface {
 		eventBus *event.EventBus,
 		contextManager ContextManager,
 		sessionID string,
+		checkpointer AgentCheckpointer,
 	) (AgentEngine, error)
 
 	// ValidateConfig validates an agent configuration
@@ -0,0 +1,60 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/hibiken/asynq"
+)
+
+// AgentRunService defines the durable agent run service interface.
+// A run is held by one instance at a time, which keeps it alive with heartbeats.
+type AgentRunService interface {
+	// Start records a new run held by this instance
+	Start(ctx context.Context, run *types.AgentRun) error
+	// GetRun gets the run generating a message of a session
+	GetRun(ctx context.Context, sessionID, messageID string) (*types.AgentRun, error)
+	// Claim takes over a run in one of the given statuses for this instance
+	Claim(ctx context.Context,
+		sessionID, messageID string, from ...types.AgentRunStatus,
+	) (*types.AgentRun, error)
+	// Pause pauses a running run, the instance holding it stops at its next heartbeat
+	Pause(ctx context.Context, sessionID, messageID string) (*types.AgentRun, error)
+	// Cancel gives up a paused or interrupted run, it is not resumed anymore
+	Cancel(ctx context.Context, sessionID, messageID string) (*types.AgentRun, error)
+	// Execute runs fn while this instance holds the run and records the outcome.
+	// The context of fn is cancelled once the run is paused or taken over, Execute then returns
+	// errors.ErrAgentRunSuspended and leaves the run to its new holder.
+	Execute(ctx context.Context, run *types.AgentRun,
+		fn func(ctx context.Context, checkpointer AgentCheckpointer) error,
+	) error
+	// RestoreContext rebuilds the tenant and user context of a run for resuming it in the background
+	RestoreContext(ctx context.Context, run *types.AgentRun) (context.Context, error)
+	// ProcessAgentRunRecovery marks runs whose instance stopped as interrupted and enqueues their resumption
+	ProcessAgentRunRecovery(ctx context.Context, t *asynq.Task) error
+}
+
+// AgentRunRepository defines the agent run repository interface
+type AgentRunRepository interface {
+	// Create creates a run
+	Create(ctx context.Context, run *types.AgentRun) error
+	// GetByMessage gets the run generating a message
+	GetByMessage(ctx context.Context, tenantID uint64, messageID string) (*types.AgentRun, error)
+	// ListStale lists running and interrupted runs of every tenant whose heartbeat is older than staleBefore
+	ListStale(ctx context.Context, staleBefore time.Time, limit int) ([]*types.AgentRun, error)
+	// SaveCheckpoint stores the checkpoint of a run still running on run.Owner and reports whether it was stored
+	SaveCheckpoint(ctx context.Context, run *types.AgentRun) (bool, error)
+	// Heartbeat refreshes the heartbeat of a run still running on run.Owner and reports whether it was refreshed
+	Heartbeat(ctx context.Context, run *types.AgentRun) (bool, error)
+	// Claim sets a run in one of the given statuses running on run.Owner and reports whether it was claimed
+	Claim(ctx context.Context, run *types.AgentRun, from ...types.AgentRunStatus) (bool, error)
+	// Finish stores the final status of a run still running on run.Owner and reports whether it was stored
+	Finish(ctx context.Context, run *types.AgentRun) (bool, error)
+	// SetStatus moves a run from one of the given statuses to status and reports whether it was moved
+	SetStatus(ctx context.Context,
+		run *types.AgentRun, status types.AgentRunStatus, from ...types.AgentRunStatus,
+	) (bool, error)
+	// Interrupt marks a running run whose heartbeat is older than staleBefore as interrupted
+	Interrupt(ctx context.Context, run *types.AgentRun, staleBefore time.Time) (bool, error)
+}
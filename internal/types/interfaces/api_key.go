@@ -0,0 +1,39 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// APIKeyService defines the interface for named, scoped tenant API keys.
+// Only the hash of a key is stored, the key itself is returned once on creation.
+type APIKeyService interface {
+	// CreateAPIKey creates an API key for the tenant in the context
+	CreateAPIKey(ctx context.Context, req *types.CreateAPIKeyRequest) (*types.CreateAPIKeyResponse, error)
+	// ListAPIKeys lists the API keys of the tenant in the context
+	ListAPIKeys(ctx context.Context) ([]*types.TenantAPIKey, error)
+	// RevokeAPIKey revokes an API key of the tenant in the context
+	RevokeAPIKey(ctx context.Context, id string) error
+	// Authenticate returns the active API key matching the given key
+	Authenticate(ctx context.Context, key string) (*types.TenantAPIKey, error)
+	// IsAPIKey reports whether the given key has the format of a tenant API key
+	IsAPIKey(key string) bool
+}
+
+// APIKeyRepository defines the interface for tenant API key repositories
+type APIKeyRepository interface {
+	// CreateAPIKey creates an API key
+	CreateAPIKey(ctx context.Context, key *types.TenantAPIKey) error
+	// GetAPIKey gets an API key of a tenant by ID
+	GetAPIKey(ctx context.Context, tenantID uint64, id string) (*types.TenantAPIKey, error)
+	// GetAPIKeyByHash gets an API key by the hash of the key
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*types.TenantAPIKey, error)
+	// ListAPIKeys lists the API keys of a tenant
+	ListAPIKeys(ctx context.Context, tenantID uint64) ([]*types.TenantAPIKey, error)
+	// RevokeAPIKey marks an API key as revoked
+	RevokeAPIKey(ctx context.Context, tenantID uint64, id string, revokedAt time.Time) error
+	// TouchAPIKey records the last use of an API key if the previous use is older than olderThan
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time, olderThan time.Time) error
+}
@@ -0,0 +1,42 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/hibiken/asynq"
+)
+
+// AuditService defines the interface for the audit log of administrative and data access actions.
+// The logs of a tenant form a hash chain, so changed or deleted logs are detected by VerifyAuditChain.
+type AuditService interface {
+	// Record appends a log for the tenant and actor in the context, the chain fields are filled in
+	Record(ctx context.Context, log *types.AuditLog) error
+	// ListAuditLogs lists the logs of the tenant in the context, newest first
+	ListAuditLogs(ctx context.Context, filter *types.AuditLogFilter, page *types.Pagination) (*types.PageResult, error)
+	// ExportAuditLogs passes the logs of the tenant in the context to fn, oldest first
+	ExportAuditLogs(ctx context.Context, filter *types.AuditLogFilter, fn func(log *types.AuditLog) error) error
+	// VerifyAuditChain verifies the hash chain of the tenant in the context
+	VerifyAuditChain(ctx context.Context) (*types.AuditChainVerification, error)
+	// ProcessAuditRetention deletes the logs older than the retention period, it runs periodically
+	ProcessAuditRetention(ctx context.Context, t *asynq.Task) error
+}
+
+// AuditRepository defines the interface for audit log repositories
+type AuditRepository interface {
+	// AppendAuditLog appends a log to the chain of its tenant, seal is called with the locked chain head
+	AppendAuditLog(ctx context.Context, log *types.AuditLog, seal func(head *types.AuditChainHead) error) error
+	// ListAuditLogs lists the logs of a tenant matching the filter, newest first
+	ListAuditLogs(ctx context.Context,
+		tenantID uint64, filter *types.AuditLogFilter, page *types.Pagination) ([]*types.AuditLog, int64, error)
+	// ScanAuditLogs lists up to limit logs of a tenant matching the filter after the given sequence, oldest first
+	ScanAuditLogs(ctx context.Context,
+		tenantID uint64, filter *types.AuditLogFilter, afterSeq uint64, limit int) ([]*types.AuditLog, error)
+	// GetAuditChainHead gets the chain head of a tenant
+	GetAuditChainHead(ctx context.Context, tenantID uint64) (*types.AuditChainHead, error)
+	// ListAuditTenantIDs lists the tenants that have audit logs
+	ListAuditTenantIDs(ctx context.Context) ([]uint64, error)
+	// PurgeAuditLogs deletes the logs of a tenant created before the given time
+	PurgeAuditLogs(ctx context.Context, tenantID uint64, before time.Time) (int64, error)
+}
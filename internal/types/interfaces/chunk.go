@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/Tencent/WeKnora/internal/types"
 )
@@ -14,6 +15,24 @@ type ChunkRepository interface {
 	GetChunkByID(ctx context.Context, tenantID uint64, id string) (*types.Chunk, error)
 	// ListChunksByID lists chunks by ids
 	ListChunksByID(ctx context.Context, tenantID uint64, ids []string) ([]*types.Chunk, error)
+	// ListChunksByIDAsOf lists the chunks among the ids that were valid at the given time,
+	// chunks deleted afterwards are included
+	ListChunksByIDAsOf(ctx context.Context, tenantID uint64, ids []string, asOf time.Time) ([]*types.Chunk, error)
+	// ListChunksByKnowledgeIDAsOf lists the chunks of all types of a knowledge valid at the given time,
+	// the current chunks when asOf is nil
+	ListChunksByKnowledgeIDAsOf(
+		ctx context.Context,
+		tenantID uint64,
+		knowledgeID string,
+		asOf *time.Time,
+	) ([]*types.Chunk, error)
+	// ListDeletedChunksByKnowledgeID lists the deleted chunks of the given types of a knowledge
+	ListDeletedChunksByKnowledgeID(
+		ctx context.Context,
+		tenantID uint64,
+		knowledgeID string,
+		chunkType []types.ChunkType,
+	) ([]*types.Chunk, error)
 	// ListChunksByKnowledgeID lists chunks by knowledge id
 	ListChunksByKnowledgeID(ctx context.Context, tenantID uint64, knowledgeID string) ([]*types.Chunk, error)
 	// ListPagedChunksByKnowledgeID lists paged chunks by knowledge id.
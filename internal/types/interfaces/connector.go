@@ -0,0 +1,68 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/hibiken/asynq"
+)
+
+// Connector lists and reads the items of an external source
+type Connector interface {
+	// List lists the current items of the source.
+	// The cursor of the listing is unchanged as long as the items of the source are unchanged.
+	List(ctx context.Context) (*types.ConnectorListing, error)
+	// Open opens the content of a listed item
+	Open(ctx context.Context, item *types.ConnectorSourceItem) (io.ReadCloser, error)
+	// Close releases the resources held by the connector
+	Close() error
+}
+
+// ConnectorService defines the interface for knowledge connector services
+type ConnectorService interface {
+	// CreateConnector creates a connector for a knowledge base
+	CreateConnector(ctx context.Context, connector *types.KnowledgeConnector) (*types.KnowledgeConnector, error)
+	// GetConnector gets a connector by ID
+	GetConnector(ctx context.Context, id string) (*types.KnowledgeConnector, error)
+	// ListConnectors lists the connectors of a knowledge base
+	ListConnectors(ctx context.Context, kbID string) ([]*types.KnowledgeConnector, error)
+	// UpdateConnector updates the name, configuration and schedule of a connector
+	UpdateConnector(ctx context.Context, connector *types.KnowledgeConnector) (*types.KnowledgeConnector, error)
+	// DeleteConnector deletes a connector, the synced knowledge is deleted as well if deleteKnowledge is set
+	DeleteConnector(ctx context.Context, id string, deleteKnowledge bool) error
+	// TriggerSync enqueues a sync of a connector
+	TriggerSync(ctx context.Context, id string, force bool) (*types.ConnectorSyncState, error)
+	// GetSyncState gets the sync state of a connector
+	GetSyncState(ctx context.Context, id string) (*types.ConnectorSyncState, error)
+	// ProcessConnectorSync handles Asynq connector sync tasks
+	ProcessConnectorSync(ctx context.Context, t *asynq.Task) error
+	// ProcessConnectorSchedule handles Asynq tasks enqueueing the syncs of due connectors
+	ProcessConnectorSchedule(ctx context.Context, t *asynq.Task) error
+}
+
+// ConnectorRepository defines the interface for knowledge connector repositories
+type ConnectorRepository interface {
+	// CreateConnector creates a connector
+	CreateConnector(ctx context.Context, connector *types.KnowledgeConnector) error
+	// GetConnectorByID gets a connector by ID
+	GetConnectorByID(ctx context.Context, tenantID uint64, id string) (*types.KnowledgeConnector, error)
+	// ListConnectorsByKnowledgeBaseID lists the connectors of a knowledge base
+	ListConnectorsByKnowledgeBaseID(ctx context.Context, tenantID uint64, kbID string) ([]*types.KnowledgeConnector, error)
+	// ListScheduledConnectors lists the enabled connectors of all tenants with a sync interval
+	ListScheduledConnectors(ctx context.Context) ([]*types.KnowledgeConnector, error)
+	// UpdateConnector updates the name, configuration, schedule and cursor of a connector
+	UpdateConnector(ctx context.Context, connector *types.KnowledgeConnector) error
+	// UpdateSyncState updates the sync status, cursor, error and stats of a connector
+	UpdateSyncState(ctx context.Context, connector *types.KnowledgeConnector) error
+	// UpdateSyncStatus updates the sync status of a connector
+	UpdateSyncStatus(ctx context.Context, tenantID uint64, id string, status types.ConnectorSyncStatus) error
+	// DeleteConnector deletes a connector and its items
+	DeleteConnector(ctx context.Context, tenantID uint64, id string) error
+	// ListItems lists the synced items of a connector
+	ListItems(ctx context.Context, tenantID uint64, connectorID string) ([]*types.ConnectorItem, error)
+	// SaveItem creates or updates a synced item
+	SaveItem(ctx context.Context, item *types.ConnectorItem) error
+	// DeleteItem deletes a synced item
+	DeleteItem(ctx context.Context, tenantID uint64, id string) error
+}
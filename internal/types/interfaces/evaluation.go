@@ -2,18 +2,28 @@ package interfaces
 
 import (
 	"context"
+	"io"
 
 	"github.com/Tencent/WeKnora/internal/types"
 )
 
 // EvaluationService defines operations for evaluation tasks
 type EvaluationService interface {
-	// Evaluation starts a new evaluation task
-	Evaluation(ctx context.Context, datasetID string, knowledgeBaseID string,
-		chatModelID string, rerankModelID string,
-	) (*types.EvaluationDetail, error)
+	// Evaluation starts a new evaluation run in the background, the task ID is the run ID
+	Evaluation(ctx context.Context, req *types.EvaluationRequest) (*types.EvaluationDetail, error)
 	// EvaluationResult retrieves evaluation result by task ID
 	EvaluationResult(ctx context.Context, taskID string) (*types.EvaluationDetail, error)
+	// ListRuns lists the evaluation runs of the tenant in the context, newest first
+	ListRuns(ctx context.Context, filter *types.EvaluationRunFilter, page *types.Pagination) (*types.PageResult, error)
+	// GetRun gets an evaluation run of the tenant in the context
+	GetRun(ctx context.Context, id string) (*types.EvaluationRun, error)
+	// ListRunItems lists the per-question results of an evaluation run in dataset order
+	ListRunItems(ctx context.Context, id string, page *types.Pagination) (*types.PageResult, error)
+	// DeleteRun deletes a finished evaluation run and its results
+	DeleteRun(ctx context.Context, id string) error
+	// CompareRuns compares a target run with a base run, it fails when a retrieval metric
+	// dropped more than the tolerance
+	CompareRuns(ctx context.Context, baseID, targetID string, tolerance float64) (*types.EvaluationComparison, error)
 }
 
 // Metrics defines interface for computing evaluation metrics
@@ -32,4 +42,52 @@ type EvalHook interface {
 type DatasetService interface {
 	// GetDatasetByID retrieves QA pairs from dataset by ID
 	GetDatasetByID(ctx context.Context, datasetID string) ([]*types.QAPair, error)
+	// CreateDataset parses an uploaded CSV or JSONL file into a dataset of the tenant in the context
+	CreateDataset(ctx context.Context, name, description, filename string, r io.Reader) (*types.EvaluationDataset, error)
+	// ListDatasets lists the uploaded datasets of the tenant in the context, newest first
+	ListDatasets(ctx context.Context, page *types.Pagination) (*types.PageResult, error)
+	// GetDataset gets an uploaded dataset of the tenant in the context
+	GetDataset(ctx context.Context, id string) (*types.EvaluationDataset, error)
+	// ListDatasetItems lists the questions of an uploaded dataset in order
+	ListDatasetItems(ctx context.Context, id string, page *types.Pagination) (*types.PageResult, error)
+	// GetDatasetItems gets all questions of an uploaded dataset in order
+	GetDatasetItems(ctx context.Context, id string) ([]*types.EvaluationDatasetItem, error)
+	// DeleteDataset deletes an uploaded dataset, runs made with it are kept
+	DeleteDataset(ctx context.Context, id string) error
+}
+
+// EvaluationRepository defines the interface for evaluation dataset and run repositories
+type EvaluationRepository interface {
+	// CreateDataset creates a dataset with its questions
+	CreateDataset(ctx context.Context, dataset *types.EvaluationDataset, items []*types.EvaluationDatasetItem) error
+	// GetDataset gets a dataset of a tenant by ID
+	GetDataset(ctx context.Context, tenantID uint64, id string) (*types.EvaluationDataset, error)
+	// ListDatasets lists the datasets of a tenant, newest first
+	ListDatasets(ctx context.Context,
+		tenantID uint64, page *types.Pagination) ([]*types.EvaluationDataset, int64, error)
+	// ListDatasetItems lists the questions of a dataset in order
+	ListDatasetItems(ctx context.Context,
+		datasetID string, page *types.Pagination) ([]*types.EvaluationDatasetItem, int64, error)
+	// GetDatasetItems gets all questions of a dataset in order
+	GetDatasetItems(ctx context.Context, datasetID string) ([]*types.EvaluationDatasetItem, error)
+	// DeleteDataset deletes a dataset and its questions
+	DeleteDataset(ctx context.Context, tenantID uint64, id string) error
+	// CreateRun creates an evaluation run
+	CreateRun(ctx context.Context, run *types.EvaluationRun) error
+	// GetRun gets an evaluation run of a tenant by ID
+	GetRun(ctx context.Context, tenantID uint64, id string) (*types.EvaluationRun, error)
+	// ListRuns lists the evaluation runs of a tenant matching the filter, newest first
+	ListRuns(ctx context.Context, tenantID uint64,
+		filter *types.EvaluationRunFilter, page *types.Pagination) ([]*types.EvaluationRun, int64, error)
+	// UpdateRun updates the status, progress and metrics of an evaluation run
+	UpdateRun(ctx context.Context, run *types.EvaluationRun) error
+	// DeleteRun deletes an evaluation run and its results
+	DeleteRun(ctx context.Context, tenantID uint64, id string) error
+	// CreateRunItem creates the result of a question of an evaluation run
+	CreateRunItem(ctx context.Context, item *types.EvaluationRunItem) error
+	// ListRunItems lists the results of an evaluation run in dataset order
+	ListRunItems(ctx context.Context,
+		runID string, page *types.Pagination) ([]*types.EvaluationRunItem, int64, error)
+	// GetRunItems gets all results of an evaluation run in dataset order
+	GetRunItems(ctx context.Context, runID string) ([]*types.EvaluationRunItem, error)
 }
@@ -0,0 +1,51 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/hibiken/asynq"
+)
+
+// FeedbackService defines the answer feedback service interface
+type FeedbackService interface {
+	// SubmitFeedback sets the feedback on an assistant message, replacing the previous one
+	SubmitFeedback(ctx context.Context,
+		sessionID, messageID string, req *types.SubmitFeedbackRequest,
+	) (*types.MessageFeedback, error)
+	// DeleteFeedback removes the feedback on an assistant message
+	DeleteFeedback(ctx context.Context, sessionID, messageID string) error
+	// ListFeedback lists the feedback of the tenant in the context, newest first
+	ListFeedback(ctx context.Context, filter *types.FeedbackFilter, page *types.Pagination) (*types.PageResult, error)
+	// ListChunkSignals lists the chunk relevance signals of the tenant in the context, strongest first
+	ListChunkSignals(ctx context.Context, page *types.Pagination) (*types.PageResult, error)
+	// ExportDataset writes the evaluation samples built from the feedback matching the filter
+	ExportDataset(ctx context.Context,
+		filter *types.FeedbackFilter, write func(item *types.FeedbackDatasetItem) error,
+	) error
+	// ChunkMultipliers returns the retrieval score multipliers of the chunks with a relevance signal
+	ChunkMultipliers(ctx context.Context, tenantID uint64, chunkIDs []string) (map[string]float64, error)
+	// ProcessFeedbackAggregate recomputes the chunk relevance signals of a tenant
+	ProcessFeedbackAggregate(ctx context.Context, t *asynq.Task) error
+}
+
+// FeedbackRepository defines the answer feedback repository interface
+type FeedbackRepository interface {
+	// SetMessageFeedback sets or, when feedback is nil, clears the feedback of a message
+	SetMessageFeedback(ctx context.Context,
+		sessionID, messageID string, feedback *types.MessageFeedback, at time.Time,
+	) error
+	// ListFeedback lists the messages of a tenant with feedback matching the filter, newest first
+	ListFeedback(ctx context.Context, tenantID uint64,
+		filter *types.FeedbackFilter, page *types.Pagination,
+	) ([]*types.FeedbackRecord, int64, error)
+	// ReplaceChunkSignals replaces all chunk relevance signals of a tenant
+	ReplaceChunkSignals(ctx context.Context, tenantID uint64, signals []*types.ChunkFeedbackSignal) error
+	// GetChunkSignals gets the relevance signals of the given chunks of a tenant
+	GetChunkSignals(ctx context.Context, tenantID uint64, chunkIDs []string) ([]*types.ChunkFeedbackSignal, error)
+	// ListChunkSignals lists the chunk relevance signals of a tenant, strongest first
+	ListChunkSignals(ctx context.Context,
+		tenantID uint64, page *types.Pagination,
+	) ([]*types.ChunkFeedbackSignal, int64, error)
+}
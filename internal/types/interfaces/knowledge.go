@@ -68,6 +68,28 @@ type KnowledgeService interface {
 		knowledgeID string,
 		payload *types.ManualKnowledgePayload,
 	) (*types.Knowledge, error)
+	// UpdateKnowledgeFile replaces the file of a file knowledge with a new version.
+	// Only the chunks added or changed by the new version are processed again.
+	UpdateKnowledgeFile(
+		ctx context.Context,
+		knowledgeID string,
+		file *multipart.FileHeader,
+		enableMultimodel *bool,
+	) (*types.Knowledge, error)
+	// RefreshKnowledgeURL fetches the page of a URL knowledge again.
+	// Only the chunks added or changed since the previous fetch are processed again.
+	RefreshKnowledgeURL(ctx context.Context, knowledgeID string) (*types.Knowledge, error)
+	// ListKnowledgeVersions lists the versions of a knowledge, newest first.
+	ListKnowledgeVersions(ctx context.Context, knowledgeID string) ([]*types.KnowledgeVersion, error)
+	// DiffKnowledgeVersions compares the chunk contents of two versions of a knowledge.
+	DiffKnowledgeVersions(
+		ctx context.Context,
+		knowledgeID string,
+		fromVersion int,
+		toVersion int,
+	) (*types.KnowledgeVersionDiff, error)
+	// RestoreKnowledgeVersion restores the content of a previous version as a new version.
+	RestoreKnowledgeVersion(ctx context.Context, knowledgeID string, version int) (*types.Knowledge, error)
 	// CloneKnowledgeBase clones knowledge to another knowledge base.
 	CloneKnowledgeBase(ctx context.Context, srcID, dstID string) error
 	// UpdateImageInfo updates image information for a knowledge chunk.
@@ -123,6 +145,15 @@ type KnowledgeService interface {
 	GetKBCloneProgress(ctx context.Context, taskID string) (*types.KBCloneProgress, error)
 	// SaveKBCloneProgress saves the progress of a knowledge base clone task
 	SaveKBCloneProgress(ctx context.Context, progress *types.KBCloneProgress) error
+	// ReembedKnowledgeBase starts re-embedding a knowledge base with a new embedding model,
+	// or reindexing it with its current model when the model is empty or unchanged
+	ReembedKnowledgeBase(ctx context.Context, kbID string, embeddingModelID string) (*types.KBReembedProgress, error)
+	// ProcessKBReembed handles Asynq knowledge base re-embedding tasks
+	ProcessKBReembed(ctx context.Context, t *asynq.Task) error
+	// GetKBReembedProgress retrieves the progress of a knowledge base re-embedding task
+	GetKBReembedProgress(ctx context.Context, taskID string) (*types.KBReembedProgress, error)
+	// EnsureKnowledgeBaseWritable returns a conflict error while the knowledge base is being re-embedded
+	EnsureKnowledgeBaseWritable(ctx context.Context, kbID string) error
 	// GetFAQImportProgress retrieves the progress of an FAQ import task
 	GetFAQImportProgress(ctx context.Context, taskID string) (*types.FAQImportProgress, error)
 	// SearchKnowledge searches knowledge items by keyword across the tenant.
@@ -169,3 +200,26 @@ type KnowledgeRepository interface {
 	// fileTypes: optional list of file extensions to filter by (e.g., ["csv", "xlsx"])
 	SearchKnowledge(ctx context.Context, tenantID uint64, keyword string, offset, limit int, fileTypes []string) ([]*types.Knowledge, bool, error)
 }
+
+// KnowledgeVersionRepository defines the interface for knowledge version repositories.
+type KnowledgeVersionRepository interface {
+	// CreateVersion creates a version with the next version number of the knowledge
+	// and marks the previous current version as superseded
+	CreateVersion(ctx context.Context, version *types.KnowledgeVersion) error
+	// UpdateVersion updates a version
+	UpdateVersion(ctx context.Context, version *types.KnowledgeVersion) error
+	// GetVersion gets a version of a knowledge by version number
+	GetVersion(ctx context.Context, tenantID uint64, knowledgeID string, version int) (*types.KnowledgeVersion, error)
+	// GetLatestVersion gets the latest version of a knowledge, nil if the knowledge has no version
+	GetLatestVersion(ctx context.Context, tenantID uint64, knowledgeID string) (*types.KnowledgeVersion, error)
+	// ListVersions lists the versions of a knowledge, newest first
+	ListVersions(ctx context.Context, tenantID uint64, knowledgeID string) ([]*types.KnowledgeVersion, error)
+	// IsFileReferenced reports whether a version of a knowledge references the file path
+	IsFileReferenced(ctx context.Context, tenantID uint64, knowledgeID string, filePath string) (bool, error)
+	// DeleteVersionsByKnowledgeIDs deletes the versions of the knowledge items and returns them
+	DeleteVersionsByKnowledgeIDs(
+		ctx context.Context,
+		tenantID uint64,
+		knowledgeIDs []string,
+	) ([]*types.KnowledgeVersion, error)
+}
@@ -154,6 +154,17 @@ type KnowledgeBaseRepository interface {
 	//   - Possible errors such as record not existing, database errors, etc.
 	UpdateKnowledgeBase(ctx context.Context, kb *types.KnowledgeBase) error
 
+	// SwitchEmbeddingModel sets the embedding model of a knowledge base and all of its knowledge
+	// in one transaction
+	// Parameters:
+	//   - ctx: Context information
+	//   - tenantID: Tenant ID
+	//   - id: Knowledge base ID
+	//   - modelID: Embedding model ID
+	// Returns:
+	//   - Possible errors such as record not existing, database errors, etc.
+	SwitchEmbeddingModel(ctx context.Context, tenantID uint64, id string, modelID string) error
+
 	// DeleteKnowledgeBase deletes a knowledge base record
 	// Parameters:
 	//   - ctx: Context information
@@ -0,0 +1,53 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/hibiken/asynq"
+)
+
+// MemoryService defines the long-term user memory service interface.
+// Memories are extracted from completed answers by an asynq task and belong to the user in the context.
+type MemoryService interface {
+	// ListMemories lists the memories of the user in the context, most recently updated first
+	ListMemories(ctx context.Context,
+		filter *types.UserMemoryFilter, page *types.Pagination) (*types.PageResult, error)
+	// GetMemory gets a memory of the user in the context
+	GetMemory(ctx context.Context, id string) (*types.UserMemory, error)
+	// UpdateMemory edits a memory of the user in the context
+	UpdateMemory(ctx context.Context, id string, req *types.UpdateUserMemoryRequest) (*types.UserMemory, error)
+	// DeleteMemory deletes a memory of the user in the context
+	DeleteMemory(ctx context.Context, id string) error
+	// DeleteAllMemories deletes every memory of the user in the context and returns how many were deleted
+	DeleteAllMemories(ctx context.Context) (int64, error)
+	// RecallMemories renders the memories of the user in the context that are relevant to the query
+	// for the {{user_memories}} placeholder. It returns an empty string when there are none.
+	RecallMemories(ctx context.Context, query string) string
+	// ScheduleExtraction enqueues the extraction of memories from a completed answer of the user in the context.
+	// Failures are logged only, scheduling must not fail the caller.
+	ScheduleExtraction(ctx context.Context, message *types.Message)
+	// ProcessMemoryExtract extracts memories from an answer and the question it answers
+	ProcessMemoryExtract(ctx context.Context, t *asynq.Task) error
+}
+
+// MemoryRepository defines the long-term user memory repository interface
+type MemoryRepository interface {
+	// Create creates a memory
+	Create(ctx context.Context, memory *types.UserMemory) error
+	// Get gets a memory of a user
+	Get(ctx context.Context, tenantID uint64, userID, id string) (*types.UserMemory, error)
+	// List lists the memories of a user matching the filter, most recently updated first
+	List(ctx context.Context, tenantID uint64, userID string,
+		filter *types.UserMemoryFilter, page *types.Pagination) ([]*types.UserMemory, int64, error)
+	// ListAll lists every memory of a user, most recently updated first
+	ListAll(ctx context.Context, tenantID uint64, userID string) ([]*types.UserMemory, error)
+	// Update updates the content and embedding of a memory
+	Update(ctx context.Context, memory *types.UserMemory) error
+	// Delete deletes a memory of a user
+	Delete(ctx context.Context, tenantID uint64, userID, id string) error
+	// DeleteAll deletes every memory of a user and returns how many were deleted
+	DeleteAll(ctx context.Context, tenantID uint64, userID string) (int64, error)
+	// Prune deletes the least recently updated memories of a user beyond the given number
+	Prune(ctx context.Context, tenantID uint64, userID string, keep int) (int64, error)
+}
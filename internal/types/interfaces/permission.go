@@ -0,0 +1,98 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// PermissionService defines the interface for role-based access control.
+// The subject of a request is read from the context, requests without a subject
+// (tenant API keys and background tasks) are allowed everything.
+type PermissionService interface {
+	// CheckRole returns a forbidden error unless the subject has at least the given tenant role
+	CheckRole(ctx context.Context, role types.TenantRole) error
+	// CheckPermission returns a forbidden error unless the subject has the permission on the resource
+	CheckPermission(ctx context.Context,
+		resourceType types.ResourceType, resourceID string, permission types.Permission) error
+	// FilterAccessible returns the resource IDs the subject has the permission on, in their given order
+	FilterAccessible(ctx context.Context,
+		resourceType types.ResourceType, resourceIDs []string, permission types.Permission) ([]string, error)
+	// GrantCreator grants the manage permission on a new resource to its creator, unless the creator is an admin
+	GrantCreator(ctx context.Context, resourceType types.ResourceType, resourceID string) error
+	// DeleteResourceGrants deletes the grants of a deleted resource
+	DeleteResourceGrants(ctx context.Context, resourceType types.ResourceType, resourceID string) error
+
+	// ListGrants lists the grants of a resource
+	ListGrants(ctx context.Context, resourceType types.ResourceType, resourceID string) ([]*types.ResourceGrant, error)
+	// SetGrant creates a grant or updates the permission of an existing grant to the same subject
+	SetGrant(ctx context.Context, grant *types.ResourceGrant) (*types.ResourceGrant, error)
+	// DeleteGrant deletes a grant
+	DeleteGrant(ctx context.Context, id string) error
+
+	// ListGroups lists the user groups of the tenant
+	ListGroups(ctx context.Context) ([]*types.UserGroup, error)
+	// CreateGroup creates a user group
+	CreateGroup(ctx context.Context, group *types.UserGroup) (*types.UserGroup, error)
+	// UpdateGroup updates the name and description of a user group
+	UpdateGroup(ctx context.Context, group *types.UserGroup) (*types.UserGroup, error)
+	// DeleteGroup deletes a user group with its members and grants
+	DeleteGroup(ctx context.Context, id string) error
+	// ListGroupMembers lists the members of a user group
+	ListGroupMembers(ctx context.Context, groupID string) ([]*types.UserInfo, error)
+	// AddGroupMember adds a tenant member to a user group
+	AddGroupMember(ctx context.Context, groupID string, userID string) error
+	// RemoveGroupMember removes a member from a user group
+	RemoveGroupMember(ctx context.Context, groupID string, userID string) error
+
+	// ListMembers lists the members of the tenant
+	ListMembers(ctx context.Context) ([]*types.UserInfo, error)
+	// CreateMember creates a user account in the tenant with the given role
+	CreateMember(ctx context.Context, req *types.CreateMemberRequest) (*types.UserInfo, error)
+	// UpdateMemberRole changes the role of a tenant member
+	UpdateMemberRole(ctx context.Context, userID string, role types.TenantRole) (*types.UserInfo, error)
+	// RemoveMember deletes a member from the tenant
+	RemoveMember(ctx context.Context, userID string) error
+}
+
+// PermissionRepository defines the interface for grant and user group repositories
+type PermissionRepository interface {
+	// ListGrants lists the grants of the given resources
+	ListGrants(ctx context.Context,
+		tenantID uint64, resourceType types.ResourceType, resourceIDs []string) ([]*types.ResourceGrant, error)
+	// GetGrantByID gets a grant by ID
+	GetGrantByID(ctx context.Context, tenantID uint64, id string) (*types.ResourceGrant, error)
+	// GetGrantBySubject gets the grant of a resource to a subject
+	GetGrantBySubject(ctx context.Context, tenantID uint64, resourceType types.ResourceType, resourceID string,
+		subjectType types.GrantSubjectType, subjectID string) (*types.ResourceGrant, error)
+	// SaveGrant creates or updates a grant
+	SaveGrant(ctx context.Context, grant *types.ResourceGrant) error
+	// DeleteGrant deletes a grant
+	DeleteGrant(ctx context.Context, tenantID uint64, id string) error
+	// DeleteResourceGrants deletes the grants of a resource
+	DeleteResourceGrants(ctx context.Context, tenantID uint64, resourceType types.ResourceType, resourceID string) error
+	// DeleteSubjectGrants deletes the grants to a subject
+	DeleteSubjectGrants(ctx context.Context,
+		tenantID uint64, subjectType types.GrantSubjectType, subjectID string) error
+
+	// CreateGroup creates a user group
+	CreateGroup(ctx context.Context, group *types.UserGroup) error
+	// GetGroupByID gets a user group by ID
+	GetGroupByID(ctx context.Context, tenantID uint64, id string) (*types.UserGroup, error)
+	// ListGroups lists the user groups of a tenant
+	ListGroups(ctx context.Context, tenantID uint64) ([]*types.UserGroup, error)
+	// UpdateGroup updates a user group
+	UpdateGroup(ctx context.Context, group *types.UserGroup) error
+	// DeleteGroup deletes a user group with its members and grants
+	DeleteGroup(ctx context.Context, tenantID uint64, id string) error
+	// ListGroupMemberIDs lists the user IDs of the members of a user group
+	ListGroupMemberIDs(ctx context.Context, tenantID uint64, groupID string) ([]string, error)
+	// AddGroupMember adds a user to a user group
+	AddGroupMember(ctx context.Context, member *types.UserGroupMember) error
+	// RemoveGroupMember removes a user from a user group
+	RemoveGroupMember(ctx context.Context, tenantID uint64, groupID string, userID string) error
+	// RemoveUserFromGroups removes a user from all user groups of a tenant
+	RemoveUserFromGroups(ctx context.Context, tenantID uint64, userID string) error
+	// ListUserGroupIDs lists the IDs of the user groups a user is a member of
+	ListUserGroupIDs(ctx context.Context, tenantID uint64, userID string) ([]string, error)
+}
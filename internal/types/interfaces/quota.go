@@ -0,0 +1,43 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// QuotaService defines the interface for tenant, user and API key quotas.
+// A request is checked against the limits of every subject it is made by: its tenant,
+// and its user or API key. Failed checks return a *types.QuotaExceededError.
+type QuotaService interface {
+	// CheckRequest takes a request from the per-minute budgets of the subjects in the context
+	CheckRequest(ctx context.Context) error
+	// AcquireStream checks the daily token budgets and takes a concurrent stream slot of the subjects in the context.
+	// The returned function releases the slots and must be called when the stream ends.
+	AcquireStream(ctx context.Context) (func(), error)
+	// ConsumeIngestion adds uploaded bytes to the daily ingestion budgets of the subjects in the context
+	ConsumeIngestion(ctx context.Context, bytes int64) error
+	// ConsumeTokens adds model tokens to the daily token budgets of the subjects in the context
+	ConsumeTokens(ctx context.Context, tokens int64)
+	// GetQuotaStatus returns the limits and usage of the subjects in the context
+	GetQuotaStatus(ctx context.Context) ([]*types.QuotaStatus, error)
+	// ListQuotaPolicies lists the quota policies of the tenant in the context
+	ListQuotaPolicies(ctx context.Context) ([]*types.QuotaPolicy, error)
+	// SetQuotaPolicy sets the limits of a subject of the tenant in the context
+	SetQuotaPolicy(ctx context.Context, req *types.SetQuotaPolicyRequest) (*types.QuotaPolicy, error)
+	// DeleteQuotaPolicy deletes the policy of a subject, the default limits apply again
+	DeleteQuotaPolicy(ctx context.Context, subjectType types.QuotaSubjectType, subjectID string) error
+}
+
+// QuotaRepository defines the interface for quota policy repositories
+type QuotaRepository interface {
+	// GetQuotaPolicy gets the policy of a subject
+	GetQuotaPolicy(ctx context.Context,
+		tenantID uint64, subjectType types.QuotaSubjectType, subjectID string) (*types.QuotaPolicy, error)
+	// ListQuotaPolicies lists the policies of a tenant
+	ListQuotaPolicies(ctx context.Context, tenantID uint64) ([]*types.QuotaPolicy, error)
+	// SaveQuotaPolicy creates the policy of a subject, or replaces the limits of its existing policy
+	SaveQuotaPolicy(ctx context.Context, policy *types.QuotaPolicy) error
+	// DeleteQuotaPolicy deletes the policy of a subject
+	DeleteQuotaPolicy(ctx context.Context, tenantID uint64, subjectType types.QuotaSubjectType, subjectID string) error
+}
@@ -52,6 +52,32 @@ type RetrieveEngineRepository interface {
 	// DeleteByKnowledgeIDList deletes the index info by knowledge id list
 	DeleteByKnowledgeIDList(ctx context.Context, knowledgeIDList []string, dimension int, knowledgeType string) error
 
+	// DeleteByKnowledgeBaseID deletes all index info of a knowledge base
+	DeleteByKnowledgeBaseID(ctx context.Context, knowledgeBaseID string, dimension int) error
+
+	// MoveByChunkIDList moves the index info of chunks to another knowledge base ID,
+	// used to keep the indices of superseded chunks in the archive of the knowledge base
+	MoveByChunkIDList(
+		ctx context.Context,
+		chunkIDList []string,
+		targetKnowledgeBaseID string,
+		dimension int,
+		knowledgeType string,
+	) error
+
+	// ReplaceKnowledgeBaseIndices replaces the index info of a knowledge base with the index info
+	// written under its shadow knowledge base ID, it does nothing when there is no shadow index info
+	// so an interrupted switchover can be retried
+	// oldDimension: dimension of the index info being replaced
+	// newDimension: dimension of the shadow index info
+	ReplaceKnowledgeBaseIndices(
+		ctx context.Context,
+		knowledgeBaseID string,
+		shadowKnowledgeBaseID string,
+		oldDimension int,
+		newDimension int,
+	) error
+
 	// BatchUpdateChunkEnabledStatus updates the enabled status of chunks in batch
 	// chunkStatusMap: map of chunk ID to enabled status (true = enabled, false = disabled)
 	BatchUpdateChunkEnabledStatus(ctx context.Context, chunkStatusMap map[string]bool) error
@@ -119,6 +145,32 @@ type RetrieveEngineService interface {
 	// DeleteByKnowledgeIDList deletes the index info by knowledge id list
 	DeleteByKnowledgeIDList(ctx context.Context, knowledgeIDList []string, dimension int, knowledgeType string) error
 
+	// DeleteByKnowledgeBaseID deletes all index info of a knowledge base
+	DeleteByKnowledgeBaseID(ctx context.Context, knowledgeBaseID string, dimension int) error
+
+	// MoveByChunkIDList moves the index info of chunks to another knowledge base ID,
+	// used to keep the indices of superseded chunks in the archive of the knowledge base
+	MoveByChunkIDList(
+		ctx context.Context,
+		chunkIDList []string,
+		targetKnowledgeBaseID string,
+		dimension int,
+		knowledgeType string,
+	) error
+
+	// ReplaceKnowledgeBaseIndices replaces the index info of a knowledge base with the index info
+	// written under its shadow knowledge base ID, it does nothing when there is no shadow index info
+	// so an interrupted switchover can be retried
+	// oldDimension: dimension of the index info being replaced
+	// newDimension: dimension of the shadow index info
+	ReplaceKnowledgeBaseIndices(
+		ctx context.Context,
+		knowledgeBaseID string,
+		shadowKnowledgeBaseID string,
+		oldDimension int,
+		newDimension int,
+	) error
+
 	// BatchUpdateChunkEnabledStatus updates the enabled status of chunks in batch
 	// chunkStatusMap: map of chunk ID to enabled status (true = enabled, false = disabled)
 	BatchUpdateChunkEnabledStatus(ctx context.Context, chunkStatusMap map[string]bool) error
@@ -45,8 +45,10 @@ type SessionService interface {
 	// SearchKnowledge performs knowledge-based search, without summarization
 	// knowledgeBaseIDs: list of knowledge base IDs to search (supports multi-KB)
 	// knowledgeIDs: list of specific knowledge (file) IDs to search
-	SearchKnowledge(ctx context.Context, knowledgeBaseIDs []string, knowledgeIDs []string, query string) ([]*types.SearchResult, error)
+	SearchKnowledge(ctx context.Context, knowledgeBaseIDs []string, knowledgeIDs []string, query string,
+		filter *types.MetadataFilter) ([]*types.SearchResult, error)
 	// AgentQA performs agent-based question answering with conversation history and streaming support
+	// The run is checkpointed after each round, errors.ErrAgentRunSuspended is returned when it was paused or taken over
 	// eventBus is optional - if nil, uses service's default EventBus
 	// customAgent is optional - if provided, uses custom agent configuration instead of tenant defaults
 	// summaryModelID is optional - if provided, overrides the model from customAgent config
@@ -61,8 +63,14 @@ type SessionService interface {
 		knowledgeBaseIDs []string,
 		knowledgeIDs []string,
 	) error
+	// ResumeAgentQA continues a claimed agent run from its last checkpoint.
+	// Like AgentQA it returns errors.ErrAgentRunSuspended when the run is paused or taken over again.
+	ResumeAgentQA(ctx context.Context, session *types.Session, run *types.AgentRun, eventBus *event.EventBus) error
 	// ClearContext clears the LLM context for a session
 	ClearContext(ctx context.Context, sessionID string) error
+	// ImportHistory appends earlier conversation turns to a session, for clients that keep the history themselves
+	// The turns are stored as messages for knowledge QA and added to the LLM context for agent QA
+	ImportHistory(ctx context.Context, sessionID string, history []*types.History) error
 }
 
 // SessionRepository defines the session repository interface
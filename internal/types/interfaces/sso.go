@@ -0,0 +1,35 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// SSOService defines the interface for single sign-on through external identity providers.
+// Users are provisioned on their first login and receive the same tokens as a password login.
+type SSOService interface {
+	// ListProviders lists the configured identity providers
+	ListProviders(ctx context.Context) []*types.SSOProviderInfo
+	// BeginLogin starts a login and returns the identity provider URL to redirect the browser to
+	BeginLogin(ctx context.Context, provider string) (*types.SSOLoginRedirect, error)
+	// CompleteOIDCLogin completes an OpenID Connect login with the authorization code of the callback
+	CompleteOIDCLogin(ctx context.Context, provider string, code string, state string) (*types.LoginResponse, error)
+	// CompleteSAMLLogin completes a SAML login with the response posted to the assertion consumer service
+	CompleteSAMLLogin(ctx context.Context,
+		provider string, samlResponse string, relayState string) (*types.LoginResponse, error)
+	// SAMLMetadata returns the service provider metadata of a SAML provider
+	SAMLMetadata(ctx context.Context, provider string) ([]byte, error)
+}
+
+// UserIdentityRepository defines the interface for user identity repositories
+type UserIdentityRepository interface {
+	// GetIdentity gets the identity of a provider account
+	GetIdentity(ctx context.Context, provider string, subject string) (*types.UserIdentity, error)
+	// CreateIdentity creates an identity
+	CreateIdentity(ctx context.Context, identity *types.UserIdentity) error
+	// UpdateIdentity updates an identity
+	UpdateIdentity(ctx context.Context, identity *types.UserIdentity) error
+	// DeleteIdentity deletes an identity
+	DeleteIdentity(ctx context.Context, id string) error
+}
@@ -0,0 +1,42 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/Tencent/WeKnora/internal/types"
+)
+
+// ToolApprovalService defines the agent tool call approval service interface
+type ToolApprovalService interface {
+	// RequestApproval persists a pending approval for a tool call of the tenant in the context
+	RequestApproval(ctx context.Context, approval *types.ToolApproval) error
+	// WaitForDecision blocks until the approval is decided or expires. When ctx is done first,
+	// the approval is expired so that it can no longer be decided.
+	WaitForDecision(ctx context.Context, id string) (*types.ToolApproval, error)
+	// ListApprovals lists the approvals of a session, newest first. An empty status matches every approval.
+	ListApprovals(ctx context.Context, sessionID string, status types.ToolApprovalStatus) ([]*types.ToolApproval, error)
+	// GetApproval gets an approval of a session
+	GetApproval(ctx context.Context, sessionID, id string) (*types.ToolApproval, error)
+	// Approve approves a pending approval, optionally replacing the arguments of the tool call
+	Approve(ctx context.Context,
+		sessionID, id string, decision *types.ToolApprovalDecision,
+	) (*types.ToolApproval, error)
+	// Reject rejects a pending approval
+	Reject(ctx context.Context,
+		sessionID, id string, decision *types.ToolApprovalDecision,
+	) (*types.ToolApproval, error)
+}
+
+// ToolApprovalRepository defines the agent tool call approval repository interface
+type ToolApprovalRepository interface {
+	// Create creates an approval
+	Create(ctx context.Context, approval *types.ToolApproval) error
+	// Get gets an approval of a tenant
+	Get(ctx context.Context, tenantID uint64, id string) (*types.ToolApproval, error)
+	// ListBySession lists the approvals of a session, newest first. An empty status matches every approval.
+	ListBySession(ctx context.Context,
+		tenantID uint64, sessionID string, status types.ToolApprovalStatus,
+	) ([]*types.ToolApproval, error)
+	// Decide stores the decision of an approval if it is still pending and reports whether it was stored
+	Decide(ctx context.Context, approval *types.ToolApproval) (bool, error)
+}
@@ -0,0 +1,38 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/hibiken/asynq"
+)
+
+// UsageService defines the interface for metering model usage.
+// Models created by ModelService record each call through RecordUsage, and the records are rolled up daily for reports.
+type UsageService interface {
+	// RecordUsage stores the usage of a model call, calls without a tenant are ignored
+	RecordUsage(ctx context.Context, record *types.UsageRecord)
+	// GetUsageSummary reports the usage of the tenant in the context from the daily rollups
+	GetUsageSummary(ctx context.Context, query *types.UsageQuery) (*types.UsageSummary, error)
+	// ListUsageRecords lists the per-call usage records of the tenant in the context, newest first
+	ListUsageRecords(ctx context.Context, filter *types.UsageRecordFilter, page *types.Pagination) (*types.PageResult, error)
+	// ProcessUsageRollup rolls up recent records and deletes expired ones, it runs periodically
+	ProcessUsageRollup(ctx context.Context, t *asynq.Task) error
+}
+
+// UsageRepository defines the interface for usage repositories
+type UsageRepository interface {
+	// CreateUsageRecord stores the usage of a model call
+	CreateUsageRecord(ctx context.Context, record *types.UsageRecord) error
+	// ListUsageRecords lists the usage records of a tenant matching the filter, newest first
+	ListUsageRecords(ctx context.Context,
+		tenantID uint64, filter *types.UsageRecordFilter, page *types.Pagination) ([]*types.UsageRecord, int64, error)
+	// SummarizeUsage sums the daily rollups of a tenant between two days (inclusive), grouped by the given options
+	SummarizeUsage(ctx context.Context,
+		tenantID uint64, from, to time.Time, groupBy []string, query *types.UsageQuery) ([]*types.UsageSummaryRow, error)
+	// RollupUsage recomputes the daily rollups of every day with records since the given time
+	RollupUsage(ctx context.Context, since time.Time) (int64, error)
+	// PurgeUsageRecords deletes the usage records created before the given time, the rollups are kept
+	PurgeUsageRecords(ctx context.Context, before time.Time) (int64, error)
+}
@@ -54,6 +54,8 @@ type UserRepository interface {
 	DeleteUser(ctx context.Context, id string) error
 	// ListUsers lists users with pagination
 	ListUsers(ctx context.Context, offset, limit int) ([]*types.User, error)
+	// ListUsersByTenantID lists the users of a tenant
+	ListUsersByTenantID(ctx context.Context, tenantID uint64) ([]*types.User, error)
 }
 
 // AuthTokenRepository defines the auth token repository interface
@@ -0,0 +1,67 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tencent/WeKnora/internal/types"
+	"github.com/hibiken/asynq"
+)
+
+// WebhookService defines the interface for tenant webhooks.
+// Services publish events through Publish, and each subscribed webhook gets a signed delivery sent by an asynq task.
+type WebhookService interface {
+	// ListWebhooks lists the webhooks of the tenant in the context
+	ListWebhooks(ctx context.Context) ([]*types.Webhook, error)
+	// GetWebhook gets a webhook of the tenant in the context
+	GetWebhook(ctx context.Context, id string) (*types.Webhook, error)
+	// CreateWebhook creates a webhook, the signing secret is returned only here
+	CreateWebhook(ctx context.Context, req *types.CreateWebhookRequest) (*types.WebhookSecretResponse, error)
+	// UpdateWebhook updates a webhook, the signing secret is returned when it is rotated
+	UpdateWebhook(ctx context.Context, id string, req *types.UpdateWebhookRequest) (*types.WebhookSecretResponse, error)
+	// DeleteWebhook deletes a webhook and its delivery log
+	DeleteWebhook(ctx context.Context, id string) error
+	// TestWebhook sends a ping event to a webhook
+	TestWebhook(ctx context.Context, id string) (*types.WebhookDelivery, error)
+	// ListDeliveries lists the deliveries of a webhook, newest first
+	ListDeliveries(ctx context.Context,
+		webhookID string, filter *types.WebhookDeliveryFilter, page *types.Pagination) (*types.PageResult, error)
+	// GetDelivery gets a delivery of a webhook
+	GetDelivery(ctx context.Context, webhookID, id string) (*types.WebhookDelivery, error)
+	// Redeliver sends the event of a delivery again as a new delivery
+	Redeliver(ctx context.Context, webhookID, id string) (*types.WebhookDelivery, error)
+	// Publish delivers an event of the tenant in the context to the subscribed webhooks.
+	// Failures are logged only, publishing must not fail the caller.
+	Publish(ctx context.Context, event types.WebhookEvent, data interface{})
+	// ProcessWebhookDelivery sends a delivery, failed attempts are retried by asynq
+	ProcessWebhookDelivery(ctx context.Context, t *asynq.Task) error
+	// ProcessWebhookRetention deletes expired deliveries, it runs periodically
+	ProcessWebhookRetention(ctx context.Context, t *asynq.Task) error
+}
+
+// WebhookRepository defines the interface for webhook repositories
+type WebhookRepository interface {
+	// CreateWebhook creates a webhook
+	CreateWebhook(ctx context.Context, webhook *types.Webhook) error
+	// GetWebhook gets a webhook of a tenant by ID
+	GetWebhook(ctx context.Context, tenantID uint64, id string) (*types.Webhook, error)
+	// ListWebhooks lists the webhooks of a tenant, newest first
+	ListWebhooks(ctx context.Context, tenantID uint64) ([]*types.Webhook, error)
+	// ListEnabledWebhooks lists the enabled webhooks of a tenant
+	ListEnabledWebhooks(ctx context.Context, tenantID uint64) ([]*types.Webhook, error)
+	// UpdateWebhook updates a webhook
+	UpdateWebhook(ctx context.Context, webhook *types.Webhook) error
+	// DeleteWebhook deletes a webhook and its deliveries
+	DeleteWebhook(ctx context.Context, tenantID uint64, id string) error
+	// CreateDelivery creates a delivery
+	CreateDelivery(ctx context.Context, delivery *types.WebhookDelivery) error
+	// GetDelivery gets a delivery of a tenant by ID
+	GetDelivery(ctx context.Context, tenantID uint64, id string) (*types.WebhookDelivery, error)
+	// ListDeliveries lists the deliveries of a webhook matching the filter, newest first
+	ListDeliveries(ctx context.Context, tenantID uint64, webhookID string,
+		filter *types.WebhookDeliveryFilter, page *types.Pagination) ([]*types.WebhookDelivery, int64, error)
+	// UpdateDelivery updates the result of a delivery attempt
+	UpdateDelivery(ctx context.Context, delivery *types.WebhookDelivery) error
+	// PurgeDeliveries deletes the deliveries created before the given time
+	PurgeDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
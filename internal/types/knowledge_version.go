@@ -0,0 +1,91 @@
+package types
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ArchiveKnowledgeBaseIDPrefix 이전 버전 청크의 인덱스가 보관되는 지식베이스 ID의 접두사
+const ArchiveKnowledgeBaseIDPrefix = "archive:"
+
+// ArchiveKnowledgeBaseID 지식베이스의 보관 인덱스에 사용되는 지식베이스 ID를 반환합니다.
+// 보관 인덱스는 일반 검색 대상이 아니며 특정 시점 검색에서만 함께 검색됩니다.
+func ArchiveKnowledgeBaseID(knowledgeBaseID string) string {
+	return ArchiveKnowledgeBaseIDPrefix + knowledgeBaseID
+}
+
+// IsArchiveKnowledgeBaseID 보관 인덱스의 지식베이스 ID인지 확인합니다.
+func IsArchiveKnowledgeBaseID(knowledgeBaseID string) bool {
+	return strings.HasPrefix(knowledgeBaseID, ArchiveKnowledgeBaseIDPrefix)
+}
+
+// KnowledgeVersion 지식 항목의 한 버전을 나타냅니다.
+// 버전은 지식 처리가 완료될 때 기록되며, 해당 버전의 청크는 생성 시점에 유효했던 청크입니다.
+type KnowledgeVersion struct {
+	// 버전의 고유 식별자
+	ID string `json:"id"                gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"`
+	// 지식 ID
+	KnowledgeID string `json:"knowledge_id"      gorm:"type:varchar(36);index"`
+	// 지식베이스 ID
+	KnowledgeBaseID string `json:"knowledge_base_id" gorm:"type:varchar(36)"`
+	// 버전 번호, 1부터 시작
+	Version int `json:"version"`
+	// 버전 생성 시점의 지식 제목
+	Title string `json:"title"`
+	// 원본 파일 이름
+	FileName string `json:"file_name"`
+	// 원본 파일 유형
+	FileType string `json:"file_type"`
+	// 원본 파일 크기
+	FileSize int64 `json:"file_size"`
+	// 원본 파일 경로, 이전 버전의 파일은 복원을 위해 보존됨
+	FilePath string `json:"file_path"`
+	// 버전 내용의 해시 (파일 해시 또는 수동 지식 내용의 해시)
+	ContentHash string `json:"content_hash"      gorm:"type:varchar(64)"`
+	// 버전 생성 시점의 지식 메타데이터 (수동 지식의 내용 포함)
+	Metadata JSON `json:"metadata"          gorm:"type:json"`
+	// 버전의 청크 수
+	ChunkCount int `json:"chunk_count"`
+	// 버전 생성 시간, 이 시점부터 버전이 유효함
+	CreatedAt time.Time `json:"created_at"`
+	// 다음 버전으로 대체된 시간, 현재 버전은 비어 있음
+	SupersededAt *time.Time `json:"superseded_at"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 KnowledgeVersion 엔티티에 대한 UUID를 생성합니다.
+func (v *KnowledgeVersion) BeforeCreate(tx *gorm.DB) (err error) {
+	if v.ID == "" {
+		v.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsCurrent 현재 유효한 버전인지 여부를 반환합니다.
+func (v *KnowledgeVersion) IsCurrent() bool {
+	return v.SupersededAt == nil
+}
+
+// KnowledgeVersionChunk 버전 비교 결과에 포함된 청크를 나타냅니다.
+type KnowledgeVersionChunk struct {
+	ChunkID    string `json:"chunk_id"`
+	ChunkIndex int    `json:"chunk_index"`
+	Content    string `json:"content"`
+}
+
+// KnowledgeVersionDiff 지식 항목의 두 버전 간 청크 내용 차이를 나타냅니다.
+type KnowledgeVersionDiff struct {
+	KnowledgeID string `json:"knowledge_id"`
+	FromVersion int    `json:"from_version"`
+	ToVersion   int    `json:"to_version"`
+	// 대상 버전에만 있는 청크
+	Added []*KnowledgeVersionChunk `json:"added"`
+	// 기준 버전에만 있는 청크
+	Removed []*KnowledgeVersionChunk `json:"removed"`
+	// 두 버전에서 내용이 같은 청크 수
+	UnchangedCount int `json:"unchanged_count"`
+}
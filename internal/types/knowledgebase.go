@@ -67,6 +67,8 @@ type KnowledgeBase struct {
 	FAQConfig *FAQConfig `yaml:"faq_config"              json:"faq_config"              gorm:"column:faq_config;type:json"`
 	// QuestionGenerationConfig 문서 지식베이스에 대한 질문 생성 구성 저장
 	QuestionGenerationConfig *QuestionGenerationConfig `yaml:"question_generation_config" json:"question_generation_config" gorm:"column:question_generation_config;type:json"`
+	// FusionConfig 하이브리드 검색 시 키워드/벡터 결과 융합 방식 구성 (비어 있으면 RRF 사용)
+	FusionConfig *FusionConfig `yaml:"fusion_config"           json:"fusion_config"           gorm:"column:fusion_config;type:json"`
 	// 지식베이스 생성 시간
 	CreatedAt time.Time `yaml:"created_at"              json:"created_at"`
 	// 지식베이스 마지막 업데이트 시간
@@ -91,6 +93,8 @@ type KnowledgeBaseConfig struct {
 	ImageProcessingConfig ImageProcessingConfig `yaml:"image_processing_config" json:"image_processing_config"`
 	// FAQ 구성 (FAQ 유형 지식베이스에만 해당)
 	FAQConfig *FAQConfig `yaml:"faq_config"              json:"faq_config"`
+	// 하이브리드 검색 융합 구성
+	FusionConfig *FusionConfig `yaml:"fusion_config"           json:"fusion_config"`
 }
 
 // ChunkingConfig 문서 분할 구성을 나타냅니다
@@ -0,0 +1,105 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserMemoryCategory 사용자 기억 유형
+type UserMemoryCategory string
+
+const (
+	UserMemoryFact       UserMemoryCategory = "fact"       // 사용자에 대한 사실 (담당 제품, 지역, 역할 등)
+	UserMemoryPreference UserMemoryCategory = "preference" // 답변 언어, 형식 등 사용자의 선호
+)
+
+// IsValid 지원하는 기억 유형인지 확인합니다.
+func (c UserMemoryCategory) IsValid() bool {
+	return c == UserMemoryFact || c == UserMemoryPreference
+}
+
+// UserMemory 대화에서 추출한 사용자의 장기 기억입니다.
+// 세션과 관계없이 사용자별로 저장되며, 질문과 관련된 기억이 시스템 프롬프트의 {{user_memories}}에 주입됩니다.
+type UserMemory struct {
+	// 기억의 고유 식별자
+	ID string `json:"id"                gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"         gorm:"index"`
+	// 사용자 ID
+	UserID string `json:"user_id"           gorm:"type:varchar(36);index"`
+	// 기억 유형
+	Category UserMemoryCategory `json:"category"          gorm:"type:varchar(16)"`
+	// 기억 내용
+	Content string `json:"content"           gorm:"type:text"`
+	// 내용의 임베딩
+	Embedding MemoryEmbedding `json:"-"                 gorm:"type:jsonb"`
+	// 임베딩 모델 ID, 다른 모델로 만든 질문 임베딩과는 비교하지 않음
+	EmbeddingModelID string `json:"-"                 gorm:"type:varchar(36)"`
+	// 기억을 추출한 세션 ID
+	SourceSessionID string `json:"source_session_id" gorm:"type:varchar(36)"`
+	// 기억을 추출한 어시스턴트 메시지 ID
+	SourceMessageID string `json:"source_message_id" gorm:"type:varchar(36)"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 UserMemory 엔티티에 대한 UUID를 생성합니다.
+func (m *UserMemory) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// MemoryEmbedding 기억 내용의 임베딩 벡터
+type MemoryEmbedding []float32
+
+// Value MemoryEmbedding을 데이터베이스 값으로 변환하는 driver.Valuer 인터페이스 구현
+func (e MemoryEmbedding) Value() (driver.Value, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return json.Marshal(e)
+}
+
+// Scan 데이터베이스 값을 MemoryEmbedding으로 변환하는 sql.Scanner 인터페이스 구현
+func (e *MemoryEmbedding) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(b, e)
+}
+
+// UserMemoryFilter 기억 목록 조회 조건
+type UserMemoryFilter struct {
+	// 기억 유형
+	Category UserMemoryCategory `form:"category"`
+	// 내용에 포함된 키워드
+	Keyword string `form:"keyword"`
+}
+
+// UpdateUserMemoryRequest 기억 수정 요청
+type UpdateUserMemoryRequest struct {
+	// 기억 내용
+	Content string `json:"content"  binding:"required,max=1000"`
+	// 기억 유형, 비어 있으면 변경하지 않음
+	Category UserMemoryCategory `json:"category"`
+}
+
+// UserMemoryExtractPayload 대화에서 사용자 기억을 추출하는 작업 페이로드
+type UserMemoryExtractPayload struct {
+	TenantID  uint64 `json:"tenant_id"`
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
+	MessageID string `json:"message_id"`
+}
@@ -76,6 +76,10 @@ type Message struct {
 	// Mentioned knowledge bases and files (for user messages)
 	// Stores the @mentioned items when user sends a message
 	MentionedItems MentionedItems `json:"mentioned_items,omitempty" gorm:"type:jsonb,column:mentioned_items"`
+	// Feedback on an assistant message, stored alongside the knowledge references it rates
+	Feedback *MessageFeedback `json:"feedback,omitempty"    gorm:"type:jsonb,column:feedback"`
+	// Time the feedback was last submitted or removed, used to find tenants to re-aggregate
+	FeedbackAt *time.Time `json:"-"                     gorm:"column:feedback_at"`
 	// Whether message generation is complete
 	IsCompleted bool `json:"is_completed"`
 	// Message creation timestamp
@@ -0,0 +1,380 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MetadataFilterOp represents the operator of a metadata filter node
+type MetadataFilterOp string
+
+// MetadataFilterOp constants
+const (
+	// MetadataFilterOpEq matches when the field equals value (or contains it, for array values)
+	MetadataFilterOpEq MetadataFilterOp = "eq"
+	// MetadataFilterOpIn matches when the field equals any of values
+	MetadataFilterOpIn MetadataFilterOp = "in"
+	// MetadataFilterOpRange matches when the field is within the gt/gte/lt/lte bounds
+	MetadataFilterOpRange MetadataFilterOp = "range"
+	// MetadataFilterOpExists matches when the field is present and not null
+	MetadataFilterOpExists MetadataFilterOp = "exists"
+	// MetadataFilterOpAnd matches when all sub filters match
+	MetadataFilterOpAnd MetadataFilterOp = "and"
+	// MetadataFilterOpOr matches when any sub filter matches
+	MetadataFilterOpOr MetadataFilterOp = "or"
+	// MetadataFilterOpNot matches when its single sub filter does not match
+	MetadataFilterOpNot MetadataFilterOp = "not"
+)
+
+// Filterable fields. Metadata fields are addressed by their top-level key,
+// e.g. "knowledge.metadata.version" or "chunk.metadata.standard_question".
+const (
+	MetadataFilterFieldFileType           = "knowledge.file_type"
+	MetadataFilterFieldCreatedAt          = "knowledge.created_at"
+	MetadataFilterKnowledgeMetadataPrefix = "knowledge.metadata."
+	MetadataFilterChunkMetadataPrefix     = "chunk.metadata."
+)
+
+// Limits of a metadata filter expression
+const (
+	maxMetadataFilterDepth = 8
+	maxMetadataFilterNodes = 64
+	// Longer metadata string values are not indexed into the retrieve engines,
+	// filters are meant for short attributes such as versions or categories
+	MaxFilterableMetadataValueLength = 256
+)
+
+// MetadataFilterTarget represents the attribute a filter field refers to
+type MetadataFilterTarget int
+
+// MetadataFilterTarget constants
+const (
+	MetadataFilterTargetUnknown MetadataFilterTarget = iota
+	MetadataFilterTargetFileType
+	MetadataFilterTargetCreatedAt
+	MetadataFilterTargetKnowledgeMetadata
+	MetadataFilterTargetChunkMetadata
+)
+
+// MetadataFilter is a typed filter expression over knowledge and chunk attributes,
+// pushed down to the retrieve engines. Leaf nodes (eq/in/range/exists) set Field,
+// logical nodes (and/or/not) set Filters.
+type MetadataFilter struct {
+	// Filter operator
+	Op MetadataFilterOp `json:"op"`
+	// Field to filter on, used by eq/in/range/exists
+	Field string `json:"field,omitempty"`
+	// Value compared by eq, a string, number or boolean
+	Value any `json:"value,omitempty"`
+	// Values compared by in
+	Values []any `json:"values,omitempty"`
+	// Range bounds, numbers or RFC3339 timestamps (dates are accepted too)
+	Gt  any `json:"gt,omitempty"`
+	Gte any `json:"gte,omitempty"`
+	Lt  any `json:"lt,omitempty"`
+	Lte any `json:"lte,omitempty"`
+	// Sub filters of and/or/not
+	Filters []*MetadataFilter `json:"filters,omitempty"`
+}
+
+// MetadataFilterBound is a normalized range bound
+type MetadataFilterBound struct {
+	// Comparison operator: gt, gte, lt or lte
+	Op string
+	// Number bound, set when IsTime is false
+	Number float64
+	// Time bound, set when IsTime is true
+	Time   time.Time
+	IsTime bool
+}
+
+// Target returns the attribute the field refers to and, for metadata fields, the metadata key
+func (f *MetadataFilter) Target() (MetadataFilterTarget, string) {
+	switch {
+	case f.Field == MetadataFilterFieldFileType:
+		return MetadataFilterTargetFileType, ""
+	case f.Field == MetadataFilterFieldCreatedAt:
+		return MetadataFilterTargetCreatedAt, ""
+	case strings.HasPrefix(f.Field, MetadataFilterKnowledgeMetadataPrefix):
+		return MetadataFilterTargetKnowledgeMetadata, strings.TrimPrefix(f.Field, MetadataFilterKnowledgeMetadataPrefix)
+	case strings.HasPrefix(f.Field, MetadataFilterChunkMetadataPrefix):
+		return MetadataFilterTargetChunkMetadata, strings.TrimPrefix(f.Field, MetadataFilterChunkMetadataPrefix)
+	default:
+		return MetadataFilterTargetUnknown, ""
+	}
+}
+
+// Bounds returns the normalized range bounds of a validated range filter
+func (f *MetadataFilter) Bounds() []MetadataFilterBound {
+	bounds := make([]MetadataFilterBound, 0, 2)
+	for _, b := range []struct {
+		op    string
+		value any
+	}{{"gt", f.Gt}, {"gte", f.Gte}, {"lt", f.Lt}, {"lte", f.Lte}} {
+		if b.value == nil {
+			continue
+		}
+		if number, ok := b.value.(float64); ok {
+			bounds = append(bounds, MetadataFilterBound{Op: b.op, Number: number})
+			continue
+		}
+		if t, ok := b.value.(time.Time); ok {
+			bounds = append(bounds, MetadataFilterBound{Op: b.op, Time: t, IsTime: true})
+		}
+	}
+	return bounds
+}
+
+// Validate checks the filter expression and normalizes its values in place:
+// integers become float64 and range timestamps become time.Time
+func (f *MetadataFilter) Validate() error {
+	nodes := 0
+	return f.validate(1, &nodes)
+}
+
+func (f *MetadataFilter) validate(depth int, nodes *int) error {
+	if f == nil {
+		return fmt.Errorf("filter is empty")
+	}
+	*nodes++
+	if depth > maxMetadataFilterDepth {
+		return fmt.Errorf("filter is nested deeper than %d levels", maxMetadataFilterDepth)
+	}
+	if *nodes > maxMetadataFilterNodes {
+		return fmt.Errorf("filter has more than %d conditions", maxMetadataFilterNodes)
+	}
+
+	switch f.Op {
+	case MetadataFilterOpAnd, MetadataFilterOpOr, MetadataFilterOpNot:
+		if f.Field != "" {
+			return fmt.Errorf("%s filter does not take a field", f.Op)
+		}
+		if len(f.Filters) == 0 {
+			return fmt.Errorf("%s filter requires sub filters", f.Op)
+		}
+		if f.Op == MetadataFilterOpNot && len(f.Filters) != 1 {
+			return fmt.Errorf("not filter requires exactly one sub filter")
+		}
+		for _, sub := range f.Filters {
+			if err := sub.validate(depth+1, nodes); err != nil {
+				return err
+			}
+		}
+		return nil
+	case MetadataFilterOpEq, MetadataFilterOpIn, MetadataFilterOpRange, MetadataFilterOpExists:
+		return f.validateLeaf()
+	default:
+		return fmt.Errorf("unsupported filter op %q", f.Op)
+	}
+}
+
+func (f *MetadataFilter) validateLeaf() error {
+	if len(f.Filters) > 0 {
+		return fmt.Errorf("%s filter does not take sub filters", f.Op)
+	}
+	target, key := f.Target()
+	switch target {
+	case MetadataFilterTargetUnknown:
+		return fmt.Errorf("unsupported filter field %q", f.Field)
+	case MetadataFilterTargetKnowledgeMetadata, MetadataFilterTargetChunkMetadata:
+		if key == "" || strings.ContainsAny(key, ".\"'") {
+			return fmt.Errorf("invalid metadata key in filter field %q", f.Field)
+		}
+	case MetadataFilterTargetFileType:
+		if f.Op != MetadataFilterOpEq && f.Op != MetadataFilterOpIn {
+			return fmt.Errorf("field %s only supports eq and in", f.Field)
+		}
+	case MetadataFilterTargetCreatedAt:
+		if f.Op != MetadataFilterOpRange {
+			return fmt.Errorf("field %s only supports range", f.Field)
+		}
+	}
+
+	switch f.Op {
+	case MetadataFilterOpEq:
+		value, err := normalizeFilterScalar(f.Value)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Field, err)
+		}
+		f.Value = value
+	case MetadataFilterOpIn:
+		if len(f.Values) == 0 {
+			return fmt.Errorf("field %s: in filter requires values", f.Field)
+		}
+		for i, v := range f.Values {
+			value, err := normalizeFilterScalar(v)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", f.Field, err)
+			}
+			f.Values[i] = value
+		}
+	case MetadataFilterOpRange:
+		return f.validateRange(target == MetadataFilterTargetCreatedAt)
+	}
+	if target == MetadataFilterTargetFileType {
+		for _, v := range append([]any{f.Value}, f.Values...) {
+			if _, ok := v.(string); v != nil && !ok {
+				return fmt.Errorf("field %s only supports string values", f.Field)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *MetadataFilter) validateRange(timeOnly bool) error {
+	bounds := []*any{&f.Gt, &f.Gte, &f.Lt, &f.Lte}
+	count, timeCount := 0, 0
+	for _, bound := range bounds {
+		if *bound == nil {
+			continue
+		}
+		count++
+		if s, ok := (*bound).(string); ok {
+			t, err := ParseFilterTime(s)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", f.Field, err)
+			}
+			*bound = t
+			timeCount++
+			continue
+		}
+		if t, ok := (*bound).(time.Time); ok {
+			*bound = t
+			timeCount++
+			continue
+		}
+		number, ok := toFilterNumber(*bound)
+		if !ok {
+			return fmt.Errorf("field %s: range bounds must be numbers or timestamps", f.Field)
+		}
+		*bound = number
+	}
+	if count == 0 {
+		return fmt.Errorf("field %s: range filter requires at least one bound", f.Field)
+	}
+	if timeCount != 0 && timeCount != count {
+		return fmt.Errorf("field %s: range bounds must all be numbers or all be timestamps", f.Field)
+	}
+	if timeOnly && timeCount == 0 {
+		return fmt.Errorf("field %s: range bounds must be timestamps", f.Field)
+	}
+	return nil
+}
+
+// ParseFilterTime parses a timestamp used in a range filter, RFC3339 or a plain date
+func ParseFilterTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q, expected RFC3339 or YYYY-MM-DD", s)
+}
+
+func normalizeFilterScalar(v any) (any, error) {
+	switch value := v.(type) {
+	case string:
+		return value, nil
+	case bool:
+		return value, nil
+	case nil:
+		return nil, fmt.Errorf("filter value is required")
+	}
+	if number, ok := toFilterNumber(v); ok {
+		return number, nil
+	}
+	return nil, fmt.Errorf("filter value must be a string, number or boolean, got %T", v)
+}
+
+func toFilterNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// IndexAttributes are the knowledge and chunk attributes denormalized into retrieve engines
+// that cannot join against the knowledge tables, so metadata filters can be evaluated natively
+type IndexAttributes struct {
+	// File type of the knowledge
+	FileType string
+	// Creation time of the knowledge
+	CreatedAt time.Time
+	// Filterable knowledge metadata
+	KnowledgeMetadata map[string]any
+	// Filterable chunk metadata
+	ChunkMetadata map[string]any
+}
+
+// NewIndexAttributes builds the index attributes of a chunk, either argument may be nil
+func NewIndexAttributes(knowledge *Knowledge, chunk *Chunk) *IndexAttributes {
+	if knowledge == nil && chunk == nil {
+		return nil
+	}
+	attrs := &IndexAttributes{}
+	if knowledge != nil {
+		attrs.FileType = knowledge.FileType
+		attrs.CreatedAt = knowledge.CreatedAt
+		attrs.KnowledgeMetadata = FilterableMetadata(knowledge.Metadata)
+	}
+	if chunk != nil {
+		attrs.ChunkMetadata = FilterableMetadata(chunk.Metadata)
+	}
+	return attrs
+}
+
+// FilterableMetadata returns the top-level metadata entries that can be filtered on:
+// strings up to MaxFilterableMetadataValueLength, numbers, booleans and arrays of those.
+// Nested objects and long texts (e.g. manual knowledge content) are skipped.
+func FilterableMetadata(metadata JSON) map[string]any {
+	metadataMap, err := metadata.Map()
+	if err != nil || len(metadataMap) == 0 {
+		return nil
+	}
+	result := make(map[string]any, len(metadataMap))
+	for key, value := range metadataMap {
+		if key == "" || strings.ContainsAny(key, ".\"'") {
+			continue
+		}
+		if list, ok := value.([]any); ok {
+			if len(list) > 0 && !slices.ContainsFunc(list, func(v any) bool { return !isFilterableMetadataScalar(v) }) {
+				result[key] = list
+			}
+			continue
+		}
+		if isFilterableMetadataScalar(value) {
+			result[key] = value
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func isFilterableMetadataScalar(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return utf8.RuneCountInString(v) <= MaxFilterableMetadataValueLength
+	case float64, bool:
+		return true
+	default:
+		return false
+	}
+}
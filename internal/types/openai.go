@@ -0,0 +1,144 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// OpenAI 호환 API 객체 유형
+const (
+	OpenAIObjectChatCompletion      = "chat.completion"
+	OpenAIObjectChatCompletionChunk = "chat.completion.chunk"
+	OpenAIObjectModel               = "model"
+	OpenAIObjectList                = "list"
+)
+
+// OpenAI 호환 응답의 종료 사유
+const (
+	OpenAIFinishReasonStop = "stop"
+)
+
+// OpenAISessionIDHeader 대화 세션을 지정하는 헤더, 응답에도 사용된 세션 ID를 반환합니다.
+const OpenAISessionIDHeader = "X-Session-ID"
+
+// OpenAIMessageContent 메시지 내용, 문자열과 내용 파트 배열을 모두 받아 텍스트 파트만 이어 붙입니다.
+type OpenAIMessageContent string
+
+// UnmarshalJSON 문자열 또는 [{"type":"text","text":"..."}] 형식의 내용을 파싱
+func (c *OpenAIMessageContent) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*c = ""
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = OpenAIMessageContent(text)
+		return nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("content must be a string or an array of content parts: %w", err)
+	}
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == "text" && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	*c = OpenAIMessageContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+// OpenAIChatMessage 요청 메시지
+type OpenAIChatMessage struct {
+	Role    string               `json:"role"` // system, user, assistant 또는 tool
+	Content OpenAIMessageContent `json:"content"`
+}
+
+// OpenAIChatCompletionRequest OpenAI 호환 대화 완성 요청.
+// model은 사용자 정의 에이전트 또는 내장 에이전트 ID이며, 모델 매개변수는 에이전트 설정을 따르므로
+// temperature 등 나머지 OpenAI 매개변수는 무시합니다.
+type OpenAIChatCompletionRequest struct {
+	Model    string              `json:"model"    binding:"required"`
+	Messages []OpenAIChatMessage `json:"messages" binding:"required"`
+	Stream   bool                `json:"stream"`
+}
+
+// OpenAIChatMessageDelta 스트리밍 청크의 메시지 증분
+type OpenAIChatMessageDelta struct {
+	Role             string `json:"role,omitempty"`
+	Content          string `json:"content,omitempty"`
+	ReasoningContent string `json:"reasoning_content,omitempty"` // 모델 또는 에이전트의 사고 과정
+}
+
+// OpenAIChatResponseMessage 비스트리밍 응답 메시지
+type OpenAIChatResponseMessage struct {
+	Role             string `json:"role"`
+	Content          string `json:"content"`
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+}
+
+// OpenAIChatCompletionChoice 응답 선택지, 비스트리밍 응답은 Message를, 스트리밍 청크는 Delta를 사용
+type OpenAIChatCompletionChoice struct {
+	Index        int                        `json:"index"`
+	Message      *OpenAIChatResponseMessage `json:"message,omitempty"`
+	Delta        *OpenAIChatMessageDelta    `json:"delta,omitempty"`
+	FinishReason *string                    `json:"finish_reason"`
+}
+
+// OpenAIToolActivity 에이전트가 서버에서 실행한 도구 호출.
+// 클라이언트가 실행할 호출이 아니므로 OpenAI의 tool_calls 대신 확장 필드로 전달합니다.
+type OpenAIToolActivity struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+	Status     string         `json:"status"` // running, success 또는 failed
+	Output     string         `json:"output,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	DurationMs int64          `json:"duration_ms,omitempty"`
+}
+
+// OpenAI 호환 응답의 도구 호출 상태
+const (
+	OpenAIToolStatusRunning = "running"
+	OpenAIToolStatusSuccess = "success"
+	OpenAIToolStatusFailed  = "failed"
+)
+
+// OpenAIExtension OpenAI 응답에 없는 WeKnora 정보, 응답과 청크의 weknora 필드로 전달합니다.
+// 스트리밍에서는 각 청크에 새로 생긴 정보만 포함합니다.
+type OpenAIExtension struct {
+	SessionID  string               `json:"session_id,omitempty"`
+	MessageID  string               `json:"message_id,omitempty"`
+	References []*SearchResult      `json:"references,omitempty"`
+	ToolCalls  []OpenAIToolActivity `json:"tool_calls,omitempty"`
+}
+
+// OpenAIChatCompletion 대화 완성 응답 또는 스트리밍 청크
+type OpenAIChatCompletion struct {
+	ID      string                       `json:"id"`
+	Object  string                       `json:"object"`
+	Created int64                        `json:"created"`
+	Model   string                       `json:"model"`
+	Choices []OpenAIChatCompletionChoice `json:"choices"`
+	WeKnora *OpenAIExtension             `json:"weknora,omitempty"`
+}
+
+// OpenAIModel 모델 목록 항목, 호출할 수 있는 에이전트를 나타냅니다.
+type OpenAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+	Name    string `json:"name,omitempty"`
+}
+
+// OpenAIModelList 모델 목록 응답
+type OpenAIModelList struct {
+	Object string        `json:"object"`
+	Data   []OpenAIModel `json:"data"`
+}
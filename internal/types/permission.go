@@ -0,0 +1,225 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TenantRole 테넌트 내 사용자 역할
+type TenantRole string
+
+const (
+	TenantRoleOwner  TenantRole = "owner"  // 소유자, 다른 소유자를 지정할 수 있음
+	TenantRoleAdmin  TenantRole = "admin"  // 관리자, 모든 리소스와 구성원을 관리
+	TenantRoleEditor TenantRole = "editor" // 편집자, 리소스를 생성하고 제한되지 않은 리소스를 편집
+	TenantRoleViewer TenantRole = "viewer" // 뷰어, 제한되지 않은 리소스를 읽기만 가능
+)
+
+// tenantRoleLevels 역할의 권한 수준, 높을수록 권한이 많음
+var tenantRoleLevels = map[TenantRole]int{
+	TenantRoleViewer: 1,
+	TenantRoleEditor: 2,
+	TenantRoleAdmin:  3,
+	TenantRoleOwner:  4,
+}
+
+// IsValid 유효한 역할인지 확인합니다.
+func (r TenantRole) IsValid() bool {
+	_, ok := tenantRoleLevels[r]
+	return ok
+}
+
+// AtLeast 역할이 주어진 역할 이상인지 확인합니다.
+func (r TenantRole) AtLeast(role TenantRole) bool {
+	return tenantRoleLevels[r] >= tenantRoleLevels[role]
+}
+
+// BasePermission 권한 부여가 없는 리소스에 대해 역할이 가지는 기본 권한을 반환합니다.
+func (r TenantRole) BasePermission() Permission {
+	switch {
+	case r.AtLeast(TenantRoleAdmin):
+		return PermissionManage
+	case r == TenantRoleEditor:
+		return PermissionWrite
+	case r == TenantRoleViewer:
+		return PermissionRead
+	default:
+		return ""
+	}
+}
+
+// Permission 리소스에 대한 권한
+type Permission string
+
+const (
+	PermissionRead   Permission = "read"   // 조회, 검색 및 대화에 사용
+	PermissionWrite  Permission = "write"  // 내용 편집
+	PermissionManage Permission = "manage" // 삭제 및 권한 부여 관리
+)
+
+// permissionLevels 권한 수준, 높은 권한은 낮은 권한을 포함
+var permissionLevels = map[Permission]int{
+	PermissionRead:   1,
+	PermissionWrite:  2,
+	PermissionManage: 3,
+}
+
+// IsValid 유효한 권한인지 확인합니다.
+func (p Permission) IsValid() bool {
+	_, ok := permissionLevels[p]
+	return ok
+}
+
+// Allows 권한이 주어진 권한을 포함하는지 확인합니다.
+func (p Permission) Allows(permission Permission) bool {
+	return p.IsValid() && permissionLevels[p] >= permissionLevels[permission]
+}
+
+// ResourceType 권한을 부여할 수 있는 리소스 유형
+type ResourceType string
+
+const (
+	ResourceTypeKnowledgeBase ResourceType = "knowledge_base" // 지식베이스
+	ResourceTypeAgent         ResourceType = "agent"          // 사용자 정의 에이전트
+	ResourceTypeMCPService    ResourceType = "mcp_service"    // MCP 서비스
+)
+
+// IsValid 유효한 리소스 유형인지 확인합니다.
+func (t ResourceType) IsValid() bool {
+	switch t {
+	case ResourceTypeKnowledgeBase, ResourceTypeAgent, ResourceTypeMCPService:
+		return true
+	}
+	return false
+}
+
+// GrantSubjectType 권한을 부여받는 주체 유형
+type GrantSubjectType string
+
+const (
+	GrantSubjectUser  GrantSubjectType = "user"  // 사용자
+	GrantSubjectGroup GrantSubjectType = "group" // 사용자 그룹
+)
+
+// ResourceGrant 사용자 또는 그룹에 대한 리소스 권한 부여를 나타냅니다.
+// 권한 부여가 하나라도 있는 리소스는 제한된 리소스가 되어, 관리자와 권한을 부여받은 주체만 접근할 수 있습니다.
+type ResourceGrant struct {
+	// 권한 부여의 고유 식별자
+	ID string `json:"id"            gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"     gorm:"index"`
+	// 리소스 유형
+	ResourceType ResourceType `json:"resource_type" gorm:"type:varchar(32);not null"`
+	// 리소스 ID
+	ResourceID string `json:"resource_id"   gorm:"type:varchar(64);not null"`
+	// 주체 유형
+	SubjectType GrantSubjectType `json:"subject_type"  gorm:"type:varchar(32);not null"`
+	// 주체 ID (사용자 ID 또는 그룹 ID)
+	SubjectID string `json:"subject_id"    gorm:"type:varchar(36);not null"`
+	// 부여된 권한
+	Permission Permission `json:"permission"    gorm:"type:varchar(32);not null"`
+	// 권한을 부여한 사용자 ID
+	CreatedBy string `json:"created_by"    gorm:"type:varchar(36)"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 ResourceGrant 엔티티에 대한 UUID를 생성합니다.
+func (g *ResourceGrant) BeforeCreate(tx *gorm.DB) (err error) {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// UserGroup 권한 부여에 사용되는 테넌트 내 사용자 그룹을 나타냅니다.
+type UserGroup struct {
+	// 그룹의 고유 식별자
+	ID string `json:"id"          gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"   gorm:"index"`
+	// 그룹 이름
+	Name string `json:"name"        gorm:"type:varchar(255);not null"`
+	// 그룹 설명
+	Description string `json:"description" gorm:"type:text"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+	// 삭제 시간
+	DeletedAt gorm.DeletedAt `json:"deleted_at"  gorm:"index"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 UserGroup 엔티티에 대한 UUID를 생성합니다.
+func (g *UserGroup) BeforeCreate(tx *gorm.DB) (err error) {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// UserGroupMember 사용자 그룹의 구성원을 나타냅니다.
+type UserGroupMember struct {
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"`
+	// 그룹 ID
+	GroupID string `json:"group_id"   gorm:"type:varchar(36);primaryKey"`
+	// 사용자 ID
+	UserID string `json:"user_id"    gorm:"type:varchar(36);primaryKey"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccessSubject 요청을 수행하는 주체와 테넌트 내 역할을 나타냅니다.
+// 컨텍스트에 주체가 없으면 테넌트 API 키 또는 백그라운드 작업으로 간주되어 모든 권한을 가집니다.
+// 단, 지식베이스 허용 목록이 있는 API 키는 목록의 지식베이스에만 접근할 수 있습니다.
+type AccessSubject struct {
+	// 사용자 ID
+	UserID string `json:"user_id"`
+	// 요청 대상 테넌트에서의 역할
+	Role TenantRole `json:"role"`
+}
+
+// AccessSubjectFromContext 컨텍스트에서 요청 주체를 가져옵니다.
+func AccessSubjectFromContext(ctx context.Context) *AccessSubject {
+	subject, _ := ctx.Value(AccessSubjectContextKey).(*AccessSubject)
+	return subject
+}
+
+// CreateMemberRequest 테넌트 구성원 생성 요청을 나타냅니다.
+type CreateMemberRequest struct {
+	Username string     `json:"username" binding:"required,min=3,max=50"`
+	Email    string     `json:"email"    binding:"required,email"`
+	Password string     `json:"password" binding:"required,min=6"`
+	Role     TenantRole `json:"role"     binding:"required"`
+}
+
+// UpdateMemberRoleRequest 테넌트 구성원 역할 변경 요청을 나타냅니다.
+type UpdateMemberRoleRequest struct {
+	Role TenantRole `json:"role" binding:"required"`
+}
+
+// UserGroupRequest 사용자 그룹 생성 및 수정 요청을 나타냅니다.
+type UserGroupRequest struct {
+	Name        string `json:"name"        binding:"required,max=255"`
+	Description string `json:"description"`
+}
+
+// GroupMemberRequest 사용자 그룹 구성원 추가 요청을 나타냅니다.
+type GroupMemberRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// SetGrantRequest 리소스 권한 부여 요청을 나타냅니다.
+type SetGrantRequest struct {
+	ResourceType ResourceType     `json:"resource_type" binding:"required"`
+	ResourceID   string           `json:"resource_id"   binding:"required"`
+	SubjectType  GrantSubjectType `json:"subject_type"  binding:"required"`
+	SubjectID    string           `json:"subject_id"    binding:"required"`
+	Permission   Permission       `json:"permission"    binding:"required"`
+}
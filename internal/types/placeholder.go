@@ -74,6 +74,13 @@ var (
 		Description: "어시스턴트의 답변 내용 (대화 기록 포맷팅에 사용)",
 	}
 
+	// Long-term memory placeholders
+	PlaceholderUserMemories = PromptPlaceholder{
+		Name:        "user_memories",
+		Label:       "사용자 기억",
+		Description: "이전 대화에서 기억한 사용자에 대한 사실과 선호 중 현재 질문과 관련된 목록, 없으면 None",
+	}
+
 	// Agent mode specific placeholders
 	PlaceholderKnowledgeBases = PromptPlaceholder{
 		Name:        "knowledge_bases",
@@ -98,6 +105,7 @@ func PlaceholdersByField(fieldType PromptFieldType) []PromptPlaceholder {
 			PlaceholderContexts,
 			PlaceholderCurrentTime,
 			PlaceholderCurrentWeek,
+			PlaceholderUserMemories,
 		}
 	case PromptFieldAgentSystemPrompt:
 		// Agent mode system prompt
@@ -105,6 +113,7 @@ func PlaceholdersByField(fieldType PromptFieldType) []PromptPlaceholder {
 			PlaceholderKnowledgeBases,
 			PlaceholderWebSearchStatus,
 			PlaceholderCurrentTime,
+			PlaceholderUserMemories,
 		}
 	case PromptFieldContextTemplate:
 		return []PromptPlaceholder{
@@ -147,6 +156,7 @@ func AllPlaceholders() []PromptPlaceholder {
 		PlaceholderConversation,
 		PlaceholderYesterday,
 		PlaceholderAnswer,
+		PlaceholderUserMemories,
 		PlaceholderKnowledgeBases,
 		PlaceholderWebSearchStatus,
 	}
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuotaSubjectType 할당량이 적용되는 대상 유형
+type QuotaSubjectType string
+
+const (
+	QuotaSubjectTenant QuotaSubjectType = "tenant"  // 테넌트 전체
+	QuotaSubjectUser   QuotaSubjectType = "user"    // 사용자
+	QuotaSubjectAPIKey QuotaSubjectType = "api_key" // 테넌트 API 키
+)
+
+// IsValid 유효한 대상 유형인지 확인합니다.
+func (t QuotaSubjectType) IsValid() bool {
+	switch t {
+	case QuotaSubjectTenant, QuotaSubjectUser, QuotaSubjectAPIKey:
+		return true
+	}
+	return false
+}
+
+// 할당량 항목
+const (
+	QuotaLimitRequestsPerMinute   = "requests_per_minute"
+	QuotaLimitConcurrentStreams   = "concurrent_streams"
+	QuotaLimitDailyTokens         = "daily_tokens"
+	QuotaLimitDailyIngestionBytes = "daily_ingestion_bytes"
+)
+
+// QuotaLimits 대상 하나에 적용되는 제한, 0이면 제한하지 않습니다.
+// 일일 제한은 UTC 기준으로 매일 초기화됩니다.
+type QuotaLimits struct {
+	// 분당 API 요청 수
+	RequestsPerMinute int `yaml:"requests_per_minute"   json:"requests_per_minute"`
+	// 동시에 진행할 수 있는 대화 스트림 수
+	ConcurrentStreams int `yaml:"concurrent_streams"    json:"concurrent_streams"`
+	// 하루에 사용할 수 있는 모델 토큰 수
+	DailyTokens int64 `yaml:"daily_tokens"          json:"daily_tokens"`
+	// 하루에 업로드할 수 있는 지식 바이트 수
+	DailyIngestionBytes int64 `yaml:"daily_ingestion_bytes" json:"daily_ingestion_bytes"`
+}
+
+// QuotaPolicy 대상별로 설정한 제한, 설정 파일의 기본 제한을 대신합니다.
+type QuotaPolicy struct {
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"    gorm:"primaryKey"`
+	// 대상 유형
+	SubjectType QuotaSubjectType `json:"subject_type" gorm:"primaryKey;type:varchar(16)"`
+	// 대상 ID, 테넌트 대상이면 비어 있음
+	SubjectID string `json:"subject_id"   gorm:"primaryKey;type:varchar(64)"`
+	// 제한
+	QuotaLimits `gorm:"embedded"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QuotaSubject 할당량이 적용되는 대상
+type QuotaSubject struct {
+	// 대상 유형
+	Type QuotaSubjectType `json:"type"`
+	// 대상 ID, 테넌트 대상이면 테넌트 ID
+	ID string `json:"id"`
+}
+
+// QuotaUsage 대상의 현재 사용량
+type QuotaUsage struct {
+	// 진행 중인 대화 스트림 수
+	ConcurrentStreams int64 `json:"concurrent_streams"`
+	// 오늘 사용한 모델 토큰 수
+	DailyTokens int64 `json:"daily_tokens"`
+	// 오늘 업로드한 지식 바이트 수
+	DailyIngestionBytes int64 `json:"daily_ingestion_bytes"`
+}
+
+// QuotaStatus 요청 주체에 적용되는 대상별 제한과 사용량
+type QuotaStatus struct {
+	QuotaSubject
+	// 정책으로 설정된 제한인지 여부, 아니면 기본 제한
+	Custom bool `json:"custom"`
+	// 적용되는 제한
+	Limits QuotaLimits `json:"limits"`
+	// 현재 사용량
+	Usage QuotaUsage `json:"usage"`
+}
+
+// SetQuotaPolicyRequest 할당량 정책 설정 요청
+type SetQuotaPolicyRequest struct {
+	// 대상 유형
+	SubjectType QuotaSubjectType `json:"subject_type" binding:"required"`
+	// 대상 ID, 테넌트 대상이면 비워 둠
+	SubjectID string `json:"subject_id"`
+	// 제한
+	QuotaLimits
+}
+
+// QuotaExceededError 할당량 초과 오류, 다시 시도할 수 있는 시간을 포함합니다.
+type QuotaExceededError struct {
+	// 제한을 초과한 대상
+	Subject QuotaSubject
+	// 초과한 항목
+	Limit string
+	// 다시 시도하기까지 기다려야 하는 시간
+	RetryAfter time.Duration
+}
+
+// Error 오류 인터페이스를 구현합니다.
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s 할당량을 초과했습니다 (%s %s)", e.Limit, e.Subject.Type, e.Subject.ID)
+}
@@ -0,0 +1,53 @@
+package types
+
+import "strings"
+
+// ShadowKnowledgeBaseIDPrefix 재임베딩 중 섀도 인덱스가 기록되는 지식베이스 ID의 접두사
+const ShadowKnowledgeBaseIDPrefix = "shadow:"
+
+// ShadowKnowledgeBaseID 지식베이스의 섀도 인덱스에 사용되는 지식베이스 ID를 반환합니다.
+// 섀도 인덱스는 검색 대상이 아니며 전환 시 원래 지식베이스 ID로 교체됩니다.
+func ShadowKnowledgeBaseID(knowledgeBaseID string) string {
+	return ShadowKnowledgeBaseIDPrefix + knowledgeBaseID
+}
+
+// IsShadowKnowledgeBaseID 섀도 인덱스의 지식베이스 ID인지 확인합니다.
+func IsShadowKnowledgeBaseID(knowledgeBaseID string) bool {
+	return strings.HasPrefix(knowledgeBaseID, ShadowKnowledgeBaseIDPrefix)
+}
+
+// KBReembedPayload 지식베이스 재임베딩 작업 페이로드를 나타냅니다.
+type KBReembedPayload struct {
+	TenantID         uint64                  `json:"tenant_id"`
+	TaskID           string                  `json:"task_id"`
+	KnowledgeBaseID  string                  `json:"knowledge_base_id"`
+	EmbeddingModelID string                  `json:"embedding_model_id"` // 새 임베딩 모델 ID
+	EffectiveEngines []RetrieverEngineParams `json:"effective_engines"`
+}
+
+// KBReembedTaskStatus 지식베이스 재임베딩 작업의 상태를 나타냅니다.
+type KBReembedTaskStatus string
+
+const (
+	KBReembedStatusPending    KBReembedTaskStatus = "pending"
+	KBReembedStatusProcessing KBReembedTaskStatus = "processing"
+	KBReembedStatusSwitching  KBReembedTaskStatus = "switching" // 섀도 인덱스로 전환 중
+	KBReembedStatusCompleted  KBReembedTaskStatus = "completed"
+	KBReembedStatusFailed     KBReembedTaskStatus = "failed"
+)
+
+// KBReembedProgress 지식베이스 재임베딩 작업의 진행 상황을 나타냅니다.
+type KBReembedProgress struct {
+	TaskID          string              `json:"task_id"`
+	KnowledgeBaseID string              `json:"knowledge_base_id"`
+	SourceModelID   string              `json:"source_model_id"` // 기존 임베딩 모델 ID
+	TargetModelID   string              `json:"target_model_id"` // 새 임베딩 모델 ID
+	Status          KBReembedTaskStatus `json:"status"`
+	Progress        int                 `json:"progress"`   // 0-100
+	Total           int                 `json:"total"`      // 총 청크 수
+	Processed       int                 `json:"processed"`  // 처리된 청크 수
+	Message         string              `json:"message"`    // 상태 메시지
+	Error           string              `json:"error"`      // 오류 메시지
+	CreatedAt       int64               `json:"created_at"` // 작업 생성 시간
+	UpdatedAt       int64               `json:"updated_at"` // 마지막 업데이트 시간
+}
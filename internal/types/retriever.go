@@ -1,5 +1,11 @@
 package types
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
 // RetrieverEngineType represents the type of retriever engine
 type RetrieverEngineType string
 
@@ -10,6 +16,10 @@ const (
 	InfinityRetrieverEngineType      RetrieverEngineType = "infinity"
 	ElasticFaissRetrieverEngineType  RetrieverEngineType = "elasticfaiss"
 	QdrantRetrieverEngineType        RetrieverEngineType = "qdrant"
+	// EmbeddedRetrieverEngineType is an in-process HNSW and BM25 engine persisted to local disk
+	EmbeddedRetrieverEngineType RetrieverEngineType = "embedded"
+	// QdrantMultiVectorRetrieverEngineType stores per-token embeddings in Qdrant multi-vector collections
+	QdrantMultiVectorRetrieverEngineType RetrieverEngineType = "qdrant_multivector"
 )
 
 // RetrieverType represents the type of retriever
@@ -20,14 +30,99 @@ const (
 	KeywordsRetrieverType  RetrieverType = "keywords"  // Keywords retriever
 	VectorRetrieverType    RetrieverType = "vector"    // Vector retriever
 	WebSearchRetrieverType RetrieverType = "websearch" // Web search retriever
+	// Multi-vector (late-interaction) retriever, scores chunks by MaxSim over per-token embeddings
+	MultiVectorRetrieverType RetrieverType = "multi_vector"
+)
+
+// FusionMethod represents the strategy used to merge results from multiple retrievers
+type FusionMethod string
+
+// FusionMethod constants
+const (
+	// RRFFusionMethod ranks results by Reciprocal Rank Fusion, ignoring raw scores
+	RRFFusionMethod FusionMethod = "rrf"
+	// WeightedFusionMethod min-max normalizes each retriever's scores and sums them with weights
+	WeightedFusionMethod FusionMethod = "weighted"
+	// DBSFFusionMethod normalizes each retriever's scores by their distribution (mean ± 3σ)
+	// and sums them with weights
+	DBSFFusionMethod FusionMethod = "dbsf"
 )
 
+// Default fusion parameters
+const (
+	DefaultRRFK                = 60
+	DefaultFusionVectorWeight  = 0.5
+	DefaultFusionKeywordWeight = 0.5
+)
+
+// FusionConfig represents the configuration of the hybrid retrieval fusion stage
+type FusionConfig struct {
+	// Fusion method, defaults to rrf
+	Method FusionMethod `yaml:"method"         json:"method"`
+	// RRF constant k, only used by rrf
+	RRFK int `yaml:"rrf_k"          json:"rrf_k,omitempty"`
+	// Weight of vector and multi-vector retrieval results, used by rrf, weighted and dbsf
+	VectorWeight float64 `yaml:"vector_weight"  json:"vector_weight,omitempty"`
+	// Weight of keyword retrieval results, used by rrf, weighted and dbsf
+	KeywordWeight float64 `yaml:"keyword_weight" json:"keyword_weight,omitempty"`
+}
+
+// WithDefaults returns a copy of the config with empty fields filled by defaults
+func (c *FusionConfig) WithDefaults() FusionConfig {
+	cfg := FusionConfig{}
+	if c != nil {
+		cfg = *c
+	}
+	if cfg.Method == "" {
+		cfg.Method = RRFFusionMethod
+	}
+	if cfg.RRFK <= 0 {
+		cfg.RRFK = DefaultRRFK
+	}
+	if cfg.VectorWeight <= 0 && cfg.KeywordWeight <= 0 {
+		cfg.VectorWeight = DefaultFusionVectorWeight
+		cfg.KeywordWeight = DefaultFusionKeywordWeight
+	}
+	return cfg
+}
+
+// Weight returns the fusion weight of the given retriever type
+func (c FusionConfig) Weight(retrieverType RetrieverType) float64 {
+	switch retrieverType {
+	case VectorRetrieverType, MultiVectorRetrieverType:
+		return c.VectorWeight
+	case KeywordsRetrieverType:
+		return c.KeywordWeight
+	default:
+		return 0
+	}
+}
+
+// Value implements the driver.Valuer interface
+func (c FusionConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements the sql.Scanner interface
+func (c *FusionConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(b, c)
+}
+
 // RetrieveParams represents the parameters for retrieval
 type RetrieveParams struct {
 	// Query text
 	Query string
 	// Query embedding (used for vector retrieval)
 	Embedding []float32
+	// Query per-token embeddings (used for multi-vector retrieval)
+	TokenEmbeddings [][]float32
 	// Knowledge base IDs
 	KnowledgeBaseIDs []string
 	// Knowledge IDs
@@ -38,6 +133,8 @@ type RetrieveParams struct {
 	ExcludeKnowledgeIDs []string
 	// Excluded chunk IDs
 	ExcludeChunkIDs []string
+	// Metadata filter expression, translated natively by each engine
+	Filter *MetadataFilter
 	// Number of results to return
 	TopK int
 	// Similarity threshold
@@ -48,6 +145,22 @@ type RetrieveParams struct {
 	AdditionalParams map[string]interface{}
 	// Retriever type
 	RetrieverType RetrieverType // Retriever type
+	// Point in time to retrieve as of. The archived index info of superseded chunks is searched as well,
+	// the caller drops the chunks that were not valid at that time.
+	AsOf *time.Time
+}
+
+// IndexKnowledgeBaseIDs returns the knowledge base IDs of the index info to search,
+// including the archive knowledge base IDs for point-in-time retrieval
+func (p *RetrieveParams) IndexKnowledgeBaseIDs() []string {
+	if p.AsOf == nil {
+		return p.KnowledgeBaseIDs
+	}
+	ids := make([]string, 0, len(p.KnowledgeBaseIDs)*2)
+	for _, id := range p.KnowledgeBaseIDs {
+		ids = append(ids, id, ArchiveKnowledgeBaseID(id))
+	}
+	return ids
 }
 
 // RetrieverEngineParams represents the parameters for retriever engine
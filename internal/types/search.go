@@ -3,6 +3,7 @@ package types
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"time"
 )
 
 // SearchTargetType 검색 대상의 유형을 나타냅니다.
@@ -99,6 +100,14 @@ type SearchParams struct {
 	DisableVectorMatch   bool     `json:"disable_vector_match"`
 	KnowledgeIDs         []string `json:"knowledge_ids"`
 	TagIDs               []string `json:"tag_ids"` // 필터링을 위한 태그 ID (FAQ 우선순위 필터링에 사용)
+	// FusionConfig 요청 단위 융합 구성, 설정 시 지식베이스 구성보다 우선함
+	FusionConfig *FusionConfig `json:"fusion_config,omitempty"`
+	// Filter 지식 및 청크 메타데이터에 대한 필터 표현식, 각 검색 엔진에서 기본적으로 적용됨
+	Filter *MetadataFilter `json:"filter,omitempty"`
+	// AsOf 지정된 시점에 유효했던 청크만 검색 (이전 버전의 보관 인덱스 포함)
+	AsOf *time.Time `json:"as_of,omitempty"`
+	// AsOfVersion 지정된 버전 시점에 유효했던 청크만 검색, KnowledgeIDs에 지식 하나만 지정해야 함
+	AsOfVersion int `json:"as_of_version,omitempty"`
 }
 
 // Value SearchResult를 데이터베이스 값으로 변환하는 driver.Valuer 인터페이스 구현
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity 사용자와 외부 ID 공급자 계정의 연결을 나타냅니다.
+// SSO 사용자는 비밀번호 없이 생성되며, 공급자와 주체 식별자로 로그인할 때마다 같은 사용자를 찾습니다.
+type UserIdentity struct {
+	// 연결의 고유 식별자
+	ID string `json:"id"            gorm:"type:varchar(36);primaryKey"`
+	// 사용자 ID
+	UserID string `json:"user_id"       gorm:"type:varchar(36);index;not null"`
+	// 구성에 정의된 ID 공급자 이름
+	Provider string `json:"provider"      gorm:"type:varchar(64);not null"`
+	// ID 공급자에서의 사용자 식별자 (OIDC sub 또는 SAML NameID)
+	Subject string `json:"subject"       gorm:"type:varchar(255);not null"`
+	// ID 공급자가 마지막으로 전달한 이메일 주소
+	Email string `json:"email"         gorm:"type:varchar(255)"`
+	// 마지막 로그인 시간
+	LastLoginAt *time.Time `json:"last_login_at"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 UserIdentity 엔티티에 대한 UUID를 생성합니다.
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// SSOProviderInfo 로그인 화면에 표시할 SSO 공급자 정보를 나타냅니다.
+type SSOProviderInfo struct {
+	// 공급자 이름
+	Name string `json:"name"`
+	// 공급자 유형 (oidc, saml)
+	Type string `json:"type"`
+	// 표시 이름
+	DisplayName string `json:"display_name"`
+	// 로그인을 시작하는 URL
+	LoginURL string `json:"login_url"`
+}
+
+// SSOLoginRedirect SSO 로그인을 시작하기 위해 브라우저를 보낼 ID 공급자 주소를 나타냅니다.
+type SSOLoginRedirect struct {
+	// ID 공급자 인증 URL
+	URL string `json:"url"`
+	// 콜백에서 확인할 로그인 상태 값
+	State string `json:"state"`
+}
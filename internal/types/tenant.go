@@ -27,6 +27,13 @@ var retrieverEngineMapping = map[string][]RetrieverEngineParams{
 		{RetrieverType: KeywordsRetrieverType, RetrieverEngineType: QdrantRetrieverEngineType},
 		{RetrieverType: VectorRetrieverType, RetrieverEngineType: QdrantRetrieverEngineType},
 	},
+	"embedded": {
+		{RetrieverType: KeywordsRetrieverType, RetrieverEngineType: EmbeddedRetrieverEngineType},
+		{RetrieverType: VectorRetrieverType, RetrieverEngineType: EmbeddedRetrieverEngineType},
+	},
+	"qdrant_multivector": {
+		{RetrieverType: MultiVectorRetrieverType, RetrieverEngineType: QdrantMultiVectorRetrieverEngineType},
+	},
 }
 
 // GetDefaultRetrieverEngines RETRIEVE_DRIVER 환경 변수에 따라 기본 검색 엔진을 반환합니다.
@@ -0,0 +1,111 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ToolApprovalPolicy 에이전트 도구 호출의 승인 정책
+type ToolApprovalPolicy string
+
+const (
+	ToolApprovalAuto ToolApprovalPolicy = "auto" // 승인 없이 실행
+	ToolApprovalAsk  ToolApprovalPolicy = "ask"  // 사용자가 승인한 뒤 실행
+	ToolApprovalDeny ToolApprovalPolicy = "deny" // 실행하지 않음
+)
+
+// IsValid 지원하는 승인 정책인지 확인합니다.
+func (p ToolApprovalPolicy) IsValid() bool {
+	return p == ToolApprovalAuto || p == ToolApprovalAsk || p == ToolApprovalDeny
+}
+
+// ValidToolApprovalPolicies 정책 목록의 모든 값이 지원하는 승인 정책인지 확인합니다.
+func ValidToolApprovalPolicies(policies map[string]ToolApprovalPolicy) bool {
+	for _, policy := range policies {
+		if !policy.IsValid() {
+			return false
+		}
+	}
+	return true
+}
+
+// ToolApprovalStatus 승인 요청 상태
+type ToolApprovalStatus string
+
+const (
+	ToolApprovalPending  ToolApprovalStatus = "pending"  // 사용자 결정 대기
+	ToolApprovalApproved ToolApprovalStatus = "approved" // 승인되어 실행됨
+	ToolApprovalRejected ToolApprovalStatus = "rejected" // 거절되어 실행되지 않음
+	ToolApprovalExpired  ToolApprovalStatus = "expired"  // 대기 시간이 지나거나 실행이 중지되어 실행되지 않음
+)
+
+// IsValid 지원하는 승인 상태인지 확인합니다.
+func (s ToolApprovalStatus) IsValid() bool {
+	switch s {
+	case ToolApprovalPending, ToolApprovalApproved, ToolApprovalRejected, ToolApprovalExpired:
+		return true
+	}
+	return false
+}
+
+// ToolApproval 정책이 "ask"인 도구 호출의 승인 요청입니다.
+// 에이전트는 요청이 결정되거나 만료될 때까지 해당 도구 호출을 멈추고 기다립니다.
+type ToolApproval struct {
+	// 승인 요청의 고유 식별자
+	ID string `json:"id"                           gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"                    gorm:"index"`
+	// 세션 ID
+	SessionID string `json:"session_id"                   gorm:"type:varchar(36);index"`
+	// 응답 중인 어시스턴트 메시지 ID
+	MessageID string `json:"message_id"                   gorm:"type:varchar(36)"`
+	// LLM이 생성한 도구 호출 ID
+	ToolCallID string `json:"tool_call_id"                 gorm:"type:varchar(128)"`
+	// 도구 이름
+	ToolName string `json:"tool_name"                    gorm:"type:varchar(255)"`
+	// 도구를 제공하는 MCP 서비스 ID, 내장 도구이면 비어 있음
+	MCPServiceID string `json:"mcp_service_id,omitempty"     gorm:"type:varchar(36)"`
+	// 에이전트 반복 회차
+	Iteration int `json:"iteration"`
+	// LLM이 요청한 인자
+	Arguments JSON `json:"arguments"                    gorm:"type:jsonb"`
+	// 실행할 인자, 요청한 인자로 시작하고 승인하면서 수정할 수 있음
+	ApprovedArguments JSON `json:"approved_arguments"           gorm:"type:jsonb"`
+	// 승인 상태
+	Status ToolApprovalStatus `json:"status"                       gorm:"type:varchar(16)"`
+	// 결정한 사용자의 의견 또는 거절 사유
+	Comment string `json:"comment"                      gorm:"type:text"`
+	// 결정한 사용자 ID
+	DecidedBy string `json:"decided_by"                   gorm:"type:varchar(36)"`
+	// 이 시간까지 결정되지 않으면 만료
+	ExpiresAt time.Time `json:"expires_at"`
+	// 결정된 시간
+	DecidedAt *time.Time `json:"decided_at"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 ToolApproval 엔티티에 대한 UUID를 생성합니다.
+func (a *ToolApproval) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsPending 아직 결정할 수 있는 승인 요청인지 확인합니다.
+func (a *ToolApproval) IsPending(now time.Time) bool {
+	return a.Status == ToolApprovalPending && now.Before(a.ExpiresAt)
+}
+
+// ToolApprovalDecision 승인 요청에 대한 사용자 결정
+type ToolApprovalDecision struct {
+	// 승인할 때 도구에 전달할 인자, 비어 있으면 요청한 인자를 그대로 사용
+	Arguments map[string]interface{} `json:"arguments"`
+	// 의견 또는 거절 사유, 거절하면 에이전트에게 전달됨
+	Comment string `json:"comment"   binding:"max=2000"`
+}
@@ -0,0 +1,195 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// 사용량 출처, 어떤 기능에서 모델을 호출했는지 나타냅니다.
+// 비동기 작업에서 호출한 경우 작업 유형(예: question:generation)이 출처가 됩니다.
+const (
+	UsageSourceKnowledgeQA  = "knowledge_qa"  // 지식베이스 질의응답
+	UsageSourceAgentQA      = "agent_qa"      // 에이전트 질의응답
+	UsageSourceSessionTitle = "session_title" // 세션 제목 생성
+	UsageSourceAgentTool    = "agent_tool:"   // 에이전트 도구, 뒤에 도구 이름이 붙음
+)
+
+// TokenUsage 모델 호출의 토큰 사용량
+type TokenUsage struct {
+	// Prompt tokens
+	PromptTokens int `json:"prompt_tokens"`
+	// Completion tokens
+	CompletionTokens int `json:"completion_tokens"`
+	// Total tokens
+	TotalTokens int `json:"total_tokens"`
+}
+
+// UsageScope 모델 호출을 사용량에 귀속시키는 정보, 컨텍스트로 전달됩니다.
+type UsageScope struct {
+	// 세션 ID
+	SessionID string
+	// 사용자 정의 에이전트 ID
+	AgentID string
+	// 사용량 출처
+	Source string
+}
+
+// WithUsageScope 컨텍스트의 사용량 귀속 정보에 비어 있지 않은 항목을 덮어써서 새 컨텍스트를 반환합니다.
+func WithUsageScope(ctx context.Context, scope UsageScope) context.Context {
+	merged := UsageScopeFromContext(ctx)
+	if scope.SessionID != "" {
+		merged.SessionID = scope.SessionID
+	}
+	if scope.AgentID != "" {
+		merged.AgentID = scope.AgentID
+	}
+	if scope.Source != "" {
+		merged.Source = scope.Source
+	}
+	return context.WithValue(ctx, UsageScopeContextKey, merged)
+}
+
+// UsageScopeFromContext 컨텍스트의 사용량 귀속 정보를 가져옵니다.
+func UsageScopeFromContext(ctx context.Context) UsageScope {
+	if scope, ok := ctx.Value(UsageScopeContextKey).(UsageScope); ok {
+		return scope
+	}
+	return UsageScope{}
+}
+
+// UsageRecord 모델 호출 한 번의 사용량 기록
+type UsageRecord struct {
+	// 기록 ID
+	ID uint64 `json:"id"                gorm:"primaryKey;autoIncrement"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"`
+	// 모델 ID
+	ModelID string `json:"model_id"          gorm:"type:varchar(64)"`
+	// 모델 이름, 모델이 삭제되어도 보고서에 표시하기 위해 저장
+	ModelName string `json:"model_name"        gorm:"type:varchar(255)"`
+	// 모델 유형 (KnowledgeQA, Embedding, Rerank)
+	ModelType ModelType `json:"model_type"        gorm:"type:varchar(32)"`
+	// 세션 ID
+	SessionID string `json:"session_id"        gorm:"type:varchar(36)"`
+	// 사용자 정의 에이전트 ID
+	AgentID string `json:"agent_id"          gorm:"type:varchar(36)"`
+	// 사용량 출처
+	Source string `json:"source"            gorm:"type:varchar(128)"`
+	// 입력 수 (임베딩 텍스트 수, 재정렬 문서 수, 채팅은 메시지 수)
+	InputCount int `json:"input_count"`
+	// 입력 토큰 수
+	PromptTokens int `json:"prompt_tokens"`
+	// 출력 토큰 수
+	CompletionTokens int `json:"completion_tokens"`
+	// 전체 토큰 수
+	TotalTokens int `json:"total_tokens"`
+	// 공급자가 사용량을 반환하지 않아 토큰 수를 추정했는지 여부
+	Estimated bool `json:"estimated"`
+	// 호출 시간 (밀리초)
+	DurationMs int64 `json:"duration_ms"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UsageDaily 일별 사용량 집계
+type UsageDaily struct {
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"         gorm:"primaryKey"`
+	// 날짜 (UTC)
+	Day time.Time `json:"day"               gorm:"primaryKey;type:date"`
+	// 모델 ID
+	ModelID string `json:"model_id"          gorm:"primaryKey;type:varchar(64)"`
+	// 모델 유형
+	ModelType ModelType `json:"model_type"        gorm:"primaryKey;type:varchar(32)"`
+	// 사용자 정의 에이전트 ID
+	AgentID string `json:"agent_id"          gorm:"primaryKey;type:varchar(36)"`
+	// 사용량 출처
+	Source string `json:"source"            gorm:"primaryKey;type:varchar(128)"`
+	// 모델 이름
+	ModelName string `json:"model_name"        gorm:"type:varchar(255)"`
+	// 호출 수
+	Calls int64 `json:"calls"`
+	// 입력 수
+	InputCount int64 `json:"input_count"`
+	// 입력 토큰 수
+	PromptTokens int64 `json:"prompt_tokens"`
+	// 출력 토큰 수
+	CompletionTokens int64 `json:"completion_tokens"`
+	// 전체 토큰 수
+	TotalTokens int64 `json:"total_tokens"`
+	// 토큰 수를 추정한 호출 수
+	EstimatedCalls int64 `json:"estimated_calls"`
+	// 갱신 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 일별 사용량 집계 테이블 이름
+func (UsageDaily) TableName() string {
+	return "usage_daily"
+}
+
+// 사용량 보고서의 그룹 기준
+const (
+	UsageGroupByDay       = "day"
+	UsageGroupByModel     = "model"
+	UsageGroupByModelType = "model_type"
+	UsageGroupByAgent     = "agent"
+	UsageGroupBySource    = "source"
+)
+
+// UsageQuery 사용량 보고서 조회 조건
+type UsageQuery struct {
+	// 시작 날짜 (포함, YYYY-MM-DD)
+	From string `form:"from"`
+	// 종료 날짜 (포함, YYYY-MM-DD)
+	To string `form:"to"`
+	// 그룹 기준, 쉼표로 구분 (day, model, model_type, agent, source)
+	GroupBy string `form:"group_by"`
+	// 모델 ID
+	ModelID string `form:"model_id"`
+	// 모델 유형
+	ModelType string `form:"model_type"`
+	// 사용자 정의 에이전트 ID
+	AgentID string `form:"agent_id"`
+	// 사용량 출처
+	Source string `form:"source"`
+}
+
+// UsageRecordFilter 사용량 기록 조회 조건
+type UsageRecordFilter struct {
+	SessionID string     `form:"session_id"`
+	AgentID   string     `form:"agent_id"`
+	ModelID   string     `form:"model_id"`
+	Source    string     `form:"source"`
+	From      *time.Time `form:"from"       time_format:"2006-01-02T15:04:05Z07:00"`
+	To        *time.Time `form:"to"         time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// UsageSummaryRow 사용량 보고서의 한 행, 그룹 기준에 포함되지 않은 항목은 비어 있습니다.
+type UsageSummaryRow struct {
+	Day              string    `json:"day,omitempty"`
+	ModelID          string    `json:"model_id,omitempty"`
+	ModelName        string    `json:"model_name,omitempty"`
+	ModelType        ModelType `json:"model_type,omitempty"`
+	AgentID          string    `json:"agent_id,omitempty"`
+	Source           string    `json:"source,omitempty"`
+	Calls            int64     `json:"calls"`
+	InputCount       int64     `json:"input_count"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	TotalTokens      int64     `json:"total_tokens"`
+	EstimatedCalls   int64     `json:"estimated_calls"`
+}
+
+// UsageSummary 사용량 보고서
+type UsageSummary struct {
+	// 조회 기간
+	From string `json:"from"`
+	To   string `json:"to"`
+	// 그룹 기준
+	GroupBy []string `json:"group_by"`
+	// 그룹별 사용량
+	Rows []*UsageSummaryRow `json:"rows"`
+	// 전체 합계
+	Total *UsageSummaryRow `json:"total"`
+}
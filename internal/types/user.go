@@ -24,6 +24,8 @@ type User struct {
 	IsActive bool `json:"is_active"  gorm:"default:true"`
 	// 사용자가 모든 테넌트에 접근할 수 있는지 여부 (크로스 테넌트 접근)
 	CanAccessAllTenants bool `json:"can_access_all_tenants" gorm:"default:false"`
+	// 사용자가 속한 테넌트에서의 역할
+	Role TenantRole `json:"role"       gorm:"type:varchar(32);default:'owner'"`
 	// 사용자 생성 시간
 	CreatedAt time.Time `json:"created_at"`
 	// 사용자 마지막 업데이트 시간
@@ -91,15 +93,16 @@ type RegisterResponse struct {
 
 // UserInfo API 응답을 위한 사용자 정보를 나타냅니다.
 type UserInfo struct {
-	ID                  string    `json:"id"`
-	Username            string    `json:"username"`
-	Email               string    `json:"email"`
-	Avatar              string    `json:"avatar"`
-	TenantID            uint64    `json:"tenant_id"`
-	IsActive            bool      `json:"is_active"`
-	CanAccessAllTenants bool      `json:"can_access_all_tenants"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
+	ID                  string     `json:"id"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	Avatar              string     `json:"avatar"`
+	TenantID            uint64     `json:"tenant_id"`
+	IsActive            bool       `json:"is_active"`
+	CanAccessAllTenants bool       `json:"can_access_all_tenants"`
+	Role                TenantRole `json:"role"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 // ToUserInfo User를 UserInfo로 변환합니다 (민감한 데이터 제외).
@@ -112,6 +115,7 @@ func (u *User) ToUserInfo() *UserInfo {
 		TenantID:            u.TenantID,
 		IsActive:            u.IsActive,
 		CanAccessAllTenants: u.CanAccessAllTenants,
+		Role:                u.Role,
 		CreatedAt:           u.CreatedAt,
 		UpdatedAt:           u.UpdatedAt,
 	}
@@ -0,0 +1,223 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookEvent 웹훅으로 전달되는 이벤트 유형
+type WebhookEvent string
+
+const (
+	WebhookEventKnowledgeParsed    WebhookEvent = "knowledge.parsed"     // 지식 파싱과 인덱싱 완료, 검색 가능
+	WebhookEventKnowledgeFailed    WebhookEvent = "knowledge.failed"     // 지식 파싱 실패
+	WebhookEventFAQImportCompleted WebhookEvent = "faq_import.completed" // FAQ 가져오기 완료
+	WebhookEventFAQImportFailed    WebhookEvent = "faq_import.failed"    // FAQ 가져오기 실패
+	WebhookEventKBCloneCompleted   WebhookEvent = "kb_clone.completed"   // 지식베이스 복사 완료
+	WebhookEventKBCloneFailed      WebhookEvent = "kb_clone.failed"      // 지식베이스 복사 실패
+	WebhookEventMessageCompleted   WebhookEvent = "message.completed"    // 어시스턴트 응답 완료
+	WebhookEventPing               WebhookEvent = "ping"                 // 연결 확인용 테스트 이벤트, 구독과 관계없이 전송
+	WebhookEventAll                WebhookEvent = "*"                    // 모든 이벤트 구독
+)
+
+// WebhookEvents 구독할 수 있는 이벤트 목록
+var WebhookEvents = []WebhookEvent{
+	WebhookEventKnowledgeParsed,
+	WebhookEventKnowledgeFailed,
+	WebhookEventFAQImportCompleted,
+	WebhookEventFAQImportFailed,
+	WebhookEventKBCloneCompleted,
+	WebhookEventKBCloneFailed,
+	WebhookEventMessageCompleted,
+}
+
+// IsValid 구독할 수 있는 이벤트인지 확인합니다.
+func (e WebhookEvent) IsValid() bool {
+	if e == WebhookEventAll {
+		return true
+	}
+	for _, event := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// 웹훅 요청 헤더
+const (
+	WebhookSignatureHeader = "X-WeKnora-Signature" // t=<unix 초>,v1=<HMAC-SHA256 16진수>
+	WebhookEventHeader     = "X-WeKnora-Event"     // 이벤트 유형
+	WebhookDeliveryHeader  = "X-WeKnora-Delivery"  // 전달 ID, 재전송마다 달라짐
+	WebhookEventIDHeader   = "X-WeKnora-Event-ID"  // 이벤트 ID, 재전송해도 같으므로 중복 제거에 사용
+)
+
+// Webhook 테넌트의 웹훅 구독을 나타냅니다.
+// 구독한 이벤트가 발생하면 서명 비밀로 서명한 POST 요청을 URL로 전송합니다.
+type Webhook struct {
+	// 웹훅의 고유 식별자
+	ID string `json:"id"          gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"   gorm:"index"`
+	// 웹훅 이름
+	Name string `json:"name"        gorm:"type:varchar(255);not null"`
+	// 전송 대상 URL
+	URL string `json:"url"         gorm:"type:varchar(2048);not null"`
+	// 서명 비밀, 생성할 때 한 번만 반환됨
+	Secret string `json:"-"           gorm:"type:varchar(128);not null"`
+	// 구독한 이벤트 목록, "*"이면 모든 이벤트
+	Events StringArray `json:"events"      gorm:"type:json"`
+	// 활성화 여부
+	Enabled bool `json:"enabled"`
+	// 설명
+	Description string `json:"description" gorm:"type:text"`
+	// 웹훅을 생성한 사용자 ID
+	CreatedBy string `json:"created_by"  gorm:"type:varchar(36)"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 Webhook 엔티티에 대한 UUID를 생성합니다.
+func (w *Webhook) BeforeCreate(tx *gorm.DB) (err error) {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Subscribes 웹훅이 주어진 이벤트를 구독하는지 확인합니다.
+func (w *Webhook) Subscribes(event WebhookEvent) bool {
+	for _, e := range w.Events {
+		if WebhookEvent(e) == WebhookEventAll || WebhookEvent(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus 웹훅 전달 상태
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending  WebhookDeliveryStatus = "pending"  // 전송 대기
+	WebhookDeliveryRetrying WebhookDeliveryStatus = "retrying" // 실패 후 재시도 대기
+	WebhookDeliverySuccess  WebhookDeliveryStatus = "success"  // 2xx 응답 수신
+	WebhookDeliveryFailed   WebhookDeliveryStatus = "failed"   // 재시도를 모두 소진
+)
+
+// WebhookDelivery 이벤트 하나를 웹훅 하나로 전송한 기록입니다.
+// 재전송하면 같은 이벤트 ID로 새 전달 기록이 생성됩니다.
+type WebhookDelivery struct {
+	// 전달의 고유 식별자
+	ID string `json:"id"              gorm:"type:varchar(36);primaryKey"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"       gorm:"index"`
+	// 웹훅 ID
+	WebhookID string `json:"webhook_id"      gorm:"type:varchar(36);index"`
+	// 이벤트 ID
+	EventID string `json:"event_id"        gorm:"type:varchar(36);index"`
+	// 이벤트 유형
+	Event WebhookEvent `json:"event"           gorm:"type:varchar(64)"`
+	// 전송한 요청 본문
+	Payload JSON `json:"payload"         gorm:"type:jsonb"`
+	// 전달 상태
+	Status WebhookDeliveryStatus `json:"status"          gorm:"type:varchar(16)"`
+	// 시도 횟수
+	Attempts int `json:"attempts"`
+	// 마지막 응답 상태 코드
+	ResponseStatus int `json:"response_status"`
+	// 마지막 오류 메시지
+	Error string `json:"error"           gorm:"type:text"`
+	// 마지막 시도 소요 시간(밀리초)
+	DurationMs int64 `json:"duration_ms"`
+	// 성공한 시간
+	DeliveredAt *time.Time `json:"delivered_at"`
+	// 생성 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 마지막 업데이트 시간
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 훅은 생성되기 전에 새 WebhookDelivery 엔티티에 대한 UUID를 생성합니다.
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// WebhookEnvelope 웹훅 요청 본문
+type WebhookEnvelope struct {
+	// 이벤트 ID
+	ID string `json:"id"`
+	// 이벤트 유형
+	Type WebhookEvent `json:"type"`
+	// 이벤트 발생 시간
+	CreatedAt time.Time `json:"created_at"`
+	// 테넌트 ID
+	TenantID uint64 `json:"tenant_id"`
+	// 이벤트 데이터
+	Data interface{} `json:"data"`
+}
+
+// WebhookKnowledgeData knowledge.parsed, knowledge.failed 이벤트 데이터
+type WebhookKnowledgeData struct {
+	KnowledgeID     string `json:"knowledge_id"`
+	KnowledgeBaseID string `json:"knowledge_base_id"`
+	Title           string `json:"title"`
+	FileName        string `json:"file_name,omitempty"`
+	Source          string `json:"source,omitempty"`
+	ParseStatus     string `json:"parse_status"`
+	Error           string `json:"error,omitempty"`
+}
+
+// WebhookMessageData message.completed 이벤트 데이터
+type WebhookMessageData struct {
+	SessionID string `json:"session_id"`
+	MessageID string `json:"message_id"`
+	RequestID string `json:"request_id"`
+	Content   string `json:"content"`
+}
+
+// CreateWebhookRequest 웹훅 생성 요청
+type CreateWebhookRequest struct {
+	Name        string   `json:"name"        binding:"required,max=255"`
+	URL         string   `json:"url"         binding:"required"`
+	Events      []string `json:"events"      binding:"required"`
+	Description string   `json:"description"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+// UpdateWebhookRequest 웹훅 수정 요청, 비어 있는 필드는 변경하지 않습니다.
+type UpdateWebhookRequest struct {
+	Name        *string  `json:"name"`
+	URL         *string  `json:"url"`
+	Events      []string `json:"events"`
+	Description *string  `json:"description"`
+	Enabled     *bool    `json:"enabled"`
+	// 서명 비밀을 새로 발급할지 여부
+	RotateSecret bool `json:"rotate_secret"`
+}
+
+// WebhookSecretResponse 서명 비밀을 포함한 웹훅입니다. Secret은 생성하거나 교체한 응답에서만 확인할 수 있습니다.
+type WebhookSecretResponse struct {
+	*Webhook
+	// 서명 비밀 원문
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookDeliveryFilter 웹훅 전달 기록 조회 조건
+type WebhookDeliveryFilter struct {
+	Event  string `form:"event"`
+	Status string `form:"status"`
+}
+
+// WebhookDeliveryPayload 웹훅 전달 작업 페이로드
+type WebhookDeliveryPayload struct {
+	TenantID   uint64 `json:"tenant_id"`
+	DeliveryID string `json:"delivery_id"`
+}
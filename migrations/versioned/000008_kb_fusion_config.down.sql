@@ -0,0 +1,4 @@
+-- Migration: 000008_kb_fusion_config (rollback)
+-- Description: Remove fusion_config column from knowledge_bases
+DO $$ BEGIN RAISE NOTICE '[Migration 000008 DOWN] Removing fusion_config column from knowledge_bases table'; END $$;
+ALTER TABLE knowledge_bases DROP COLUMN IF EXISTS fusion_config;
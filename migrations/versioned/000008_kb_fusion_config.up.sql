@@ -0,0 +1,5 @@
+-- Migration: 000008_kb_fusion_config
+-- Description: Add fusion_config column to knowledge_bases for configurable hybrid retrieval fusion
+DO $$ BEGIN RAISE NOTICE '[Migration 000008] Adding fusion_config column to knowledge_bases table'; END $$;
+ALTER TABLE knowledge_bases ADD COLUMN IF NOT EXISTS fusion_config JSONB;
+DO $$ BEGIN RAISE NOTICE '[Migration 000008] Knowledge base fusion config setup completed!'; END $$;
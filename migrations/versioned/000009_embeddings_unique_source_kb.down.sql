@@ -0,0 +1,11 @@
+-- Restore the unique source index of the embeddings table
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'embeddings') THEN
+        -- Shadow indices of unfinished re-embedding tasks would violate the old index
+        DELETE FROM embeddings WHERE knowledge_base_id LIKE 'shadow:%';
+        DROP INDEX IF EXISTS embeddings_unique_source_kb;
+        CREATE UNIQUE INDEX IF NOT EXISTS embeddings_unique_source ON embeddings(source_id, source_type);
+        RAISE NOTICE '[Migration 000009 Rollback] Restored embeddings_unique_source';
+    END IF;
+END $$;
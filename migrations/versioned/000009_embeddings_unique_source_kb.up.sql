@@ -0,0 +1,13 @@
+-- Include knowledge_base_id in the unique source index of the embeddings table,
+-- so re-embedding can write shadow indices for the same sources before switching over
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'embeddings') THEN
+        DROP INDEX IF EXISTS embeddings_unique_source;
+        CREATE UNIQUE INDEX IF NOT EXISTS embeddings_unique_source_kb
+            ON embeddings(source_id, source_type, knowledge_base_id);
+        RAISE NOTICE '[Migration 000009] Replaced embeddings_unique_source with embeddings_unique_source_kb';
+    ELSE
+        RAISE NOTICE '[Migration 000009] embeddings table does not exist, skipping';
+    END IF;
+END $$;
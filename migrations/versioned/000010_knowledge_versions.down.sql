@@ -0,0 +1,8 @@
+-- Migration: 000010_knowledge_versions (rollback)
+-- Description: Remove knowledge_versions table
+DO $$ BEGIN RAISE NOTICE '[Migration 000010 DOWN] Dropping table: knowledge_versions'; END $$;
+DROP INDEX IF EXISTS idx_chunks_knowledge_created_deleted;
+DROP INDEX IF EXISTS idx_knowledge_versions_knowledge_version;
+DROP INDEX IF EXISTS idx_knowledge_versions_tenant_id;
+DROP INDEX IF EXISTS idx_knowledge_versions_knowledge_base_id;
+DROP TABLE IF EXISTS knowledge_versions;
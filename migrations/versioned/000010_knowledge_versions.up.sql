@@ -0,0 +1,28 @@
+-- Migration: 000010_knowledge_versions
+-- Description: Add knowledge_versions table storing the version history of knowledge entries
+DO $$ BEGIN RAISE NOTICE '[Migration 000010] Creating table: knowledge_versions'; END $$;
+CREATE TABLE IF NOT EXISTS knowledge_versions (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    knowledge_id VARCHAR(36) NOT NULL,
+    knowledge_base_id VARCHAR(36) NOT NULL,
+    version INTEGER NOT NULL,
+    title VARCHAR(255),
+    file_name VARCHAR(255),
+    file_type VARCHAR(50),
+    file_size BIGINT,
+    file_path TEXT,
+    content_hash VARCHAR(64),
+    metadata JSON,
+    chunk_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    superseded_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_versions_knowledge_version ON knowledge_versions(knowledge_id, version);
+CREATE INDEX IF NOT EXISTS idx_knowledge_versions_tenant_id ON knowledge_versions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_knowledge_versions_knowledge_base_id ON knowledge_versions(knowledge_base_id);
+
+-- Point-in-time retrieval looks up chunks by their validity interval, soft deleted chunks included
+CREATE INDEX IF NOT EXISTS idx_chunks_knowledge_created_deleted ON chunks(knowledge_id, created_at, deleted_at);
+DO $$ BEGIN RAISE NOTICE '[Migration 000010] Knowledge versions setup completed!'; END $$;
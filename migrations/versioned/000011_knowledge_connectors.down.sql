@@ -0,0 +1,10 @@
+-- Migration: 000011_knowledge_connectors (rollback)
+-- Description: Remove knowledge_connectors and connector_items tables
+DO $$ BEGIN RAISE NOTICE '[Migration 000011 DOWN] Dropping tables: knowledge_connectors, connector_items'; END $$;
+DROP INDEX IF EXISTS idx_connector_items_connector_key;
+DROP INDEX IF EXISTS idx_connector_items_knowledge_id;
+DROP TABLE IF EXISTS connector_items;
+DROP INDEX IF EXISTS idx_knowledge_connectors_tenant_id;
+DROP INDEX IF EXISTS idx_knowledge_connectors_knowledge_base_id;
+DROP INDEX IF EXISTS idx_knowledge_connectors_deleted_at;
+DROP TABLE IF EXISTS knowledge_connectors;
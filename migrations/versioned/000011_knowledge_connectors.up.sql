@@ -0,0 +1,45 @@
+-- Migration: 000011_knowledge_connectors
+-- Description: Add knowledge_connectors and connector_items tables for scheduled sync from external sources
+DO $$ BEGIN RAISE NOTICE '[Migration 000011] Creating table: knowledge_connectors'; END $$;
+CREATE TABLE IF NOT EXISTS knowledge_connectors (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    knowledge_base_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    config JSON,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    sync_interval_minutes INTEGER NOT NULL DEFAULT 0,
+    sync_status VARCHAR(32) NOT NULL DEFAULT 'idle',
+    cursor VARCHAR(255),
+    last_error TEXT,
+    last_sync_stats JSON,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    last_sync_at TIMESTAMP WITH TIME ZONE,
+    last_success_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_connectors_tenant_id ON knowledge_connectors(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_knowledge_connectors_knowledge_base_id ON knowledge_connectors(knowledge_base_id);
+CREATE INDEX IF NOT EXISTS idx_knowledge_connectors_deleted_at ON knowledge_connectors(deleted_at);
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000011] Creating table: connector_items'; END $$;
+CREATE TABLE IF NOT EXISTS connector_items (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    connector_id VARCHAR(36) NOT NULL,
+    item_key TEXT NOT NULL,
+    knowledge_id VARCHAR(36) NOT NULL,
+    version VARCHAR(255),
+    size BIGINT NOT NULL DEFAULT 0,
+    synced_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_connector_items_connector_key ON connector_items(connector_id, item_key);
+CREATE INDEX IF NOT EXISTS idx_connector_items_knowledge_id ON connector_items(knowledge_id);
+DO $$ BEGIN RAISE NOTICE '[Migration 000011] Knowledge connectors setup completed!'; END $$;
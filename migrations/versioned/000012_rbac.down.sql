@@ -0,0 +1,13 @@
+-- Migration: 000012_rbac (rollback)
+-- Description: Remove tenant roles, user groups and resource grants
+DO $$ BEGIN RAISE NOTICE '[Migration 000012 DOWN] Dropping tables: resource_grants, user_group_members, user_groups'; END $$;
+DROP INDEX IF EXISTS idx_resource_grants_resource_subject;
+DROP INDEX IF EXISTS idx_resource_grants_subject;
+DROP TABLE IF EXISTS resource_grants;
+DROP INDEX IF EXISTS idx_user_group_members_tenant_user;
+DROP TABLE IF EXISTS user_group_members;
+DROP INDEX IF EXISTS idx_user_groups_tenant_id;
+DROP INDEX IF EXISTS idx_user_groups_deleted_at;
+DROP TABLE IF EXISTS user_groups;
+DO $$ BEGIN RAISE NOTICE '[Migration 000012 DOWN] Dropping column: users.role'; END $$;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
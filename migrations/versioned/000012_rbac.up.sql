@@ -0,0 +1,49 @@
+-- Migration: 000012_rbac
+-- Description: Add tenant roles, user groups and per-resource grants for role-based access control
+DO $$ BEGIN RAISE NOTICE '[Migration 000012] Adding column: users.role'; END $$;
+-- Existing users created their own workspace on registration, so they own their tenant
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'owner';
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000012] Creating table: user_groups'; END $$;
+CREATE TABLE IF NOT EXISTS user_groups (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_groups_tenant_id ON user_groups(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_user_groups_deleted_at ON user_groups(deleted_at);
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000012] Creating table: user_group_members'; END $$;
+CREATE TABLE IF NOT EXISTS user_group_members (
+    tenant_id INTEGER NOT NULL,
+    group_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_group_members_tenant_user ON user_group_members(tenant_id, user_id);
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000012] Creating table: resource_grants'; END $$;
+CREATE TABLE IF NOT EXISTS resource_grants (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    resource_type VARCHAR(32) NOT NULL,
+    resource_id VARCHAR(64) NOT NULL,
+    subject_type VARCHAR(32) NOT NULL,
+    subject_id VARCHAR(36) NOT NULL,
+    permission VARCHAR(32) NOT NULL,
+    created_by VARCHAR(36),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resource_grants_resource_subject
+    ON resource_grants(tenant_id, resource_type, resource_id, subject_type, subject_id);
+CREATE INDEX IF NOT EXISTS idx_resource_grants_subject ON resource_grants(tenant_id, subject_type, subject_id);
+DO $$ BEGIN RAISE NOTICE '[Migration 000012] Role-based access control setup completed!'; END $$;
@@ -0,0 +1,6 @@
+-- Migration: 000013_sso (rollback)
+-- Description: Remove single sign-on identity links
+DO $$ BEGIN RAISE NOTICE '[Migration 000013 DOWN] Dropping table: user_identities'; END $$;
+DROP INDEX IF EXISTS idx_user_identities_user_id;
+DROP INDEX IF EXISTS idx_user_identities_provider_subject;
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,6 @@
+-- Migration: 000014_tenant_api_keys (rollback)
+-- Description: Remove tenant API keys
+DO $$ BEGIN RAISE NOTICE '[Migration 000014 DOWN] Dropping table: tenant_api_keys'; END $$;
+DROP INDEX IF EXISTS idx_tenant_api_keys_tenant_id;
+DROP INDEX IF EXISTS idx_tenant_api_keys_key_hash;
+DROP TABLE IF EXISTS tenant_api_keys;
@@ -0,0 +1,11 @@
+-- Migration: 000015_audit_logs (rollback)
+-- Description: Remove audit logs
+DO $$ BEGIN RAISE NOTICE '[Migration 000015 DOWN] Dropping table: audit_chain_heads'; END $$;
+DROP TABLE IF EXISTS audit_chain_heads;
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000015 DOWN] Dropping table: audit_logs'; END $$;
+DROP INDEX IF EXISTS idx_audit_logs_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_action;
+DROP INDEX IF EXISTS idx_audit_logs_actor_id;
+DROP INDEX IF EXISTS idx_audit_logs_tenant_seq;
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,39 @@
+-- Migration: 000015_audit_logs
+-- Description: Add hash-chained audit logs of administrative and data access actions
+DO $$ BEGIN RAISE NOTICE '[Migration 000015] Creating table: audit_logs'; END $$;
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    seq BIGINT NOT NULL,
+    actor_type VARCHAR(32),
+    actor_id VARCHAR(64),
+    actor_name VARCHAR(255),
+    action VARCHAR(128) NOT NULL,
+    resource_type VARCHAR(64),
+    resource_id VARCHAR(255),
+    method VARCHAR(16),
+    path VARCHAR(512),
+    status_code INTEGER,
+    client_ip VARCHAR(64),
+    request_id VARCHAR(64),
+    changes JSON,
+    detail JSON,
+    prev_hash VARCHAR(64),
+    hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_tenant_seq ON audit_logs(tenant_id, seq);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at);
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000015] Creating table: audit_chain_heads'; END $$;
+CREATE TABLE IF NOT EXISTS audit_chain_heads (
+    tenant_id INTEGER PRIMARY KEY,
+    seq BIGINT NOT NULL DEFAULT 0,
+    hash VARCHAR(64) NOT NULL DEFAULT '',
+    purged_through_seq BIGINT NOT NULL DEFAULT 0,
+    purged_through_hash VARCHAR(64) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+-- Migration: 000016_usage (rollback)
+-- Description: Remove model usage records and rollups
+DO $$ BEGIN RAISE NOTICE '[Migration 000016 DOWN] Dropping table: usage_daily'; END $$;
+DROP TABLE IF EXISTS usage_daily;
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000016 DOWN] Dropping table: usage_records'; END $$;
+DROP INDEX IF EXISTS idx_usage_records_session_id;
+DROP INDEX IF EXISTS idx_usage_records_tenant_created_at;
+DROP INDEX IF EXISTS idx_usage_records_created_at;
+DROP TABLE IF EXISTS usage_records;
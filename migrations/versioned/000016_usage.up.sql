@@ -0,0 +1,43 @@
+-- Migration: 000016_usage
+-- Description: Add per-call model usage records and their daily rollups
+DO $$ BEGIN RAISE NOTICE '[Migration 000016] Creating table: usage_records'; END $$;
+CREATE TABLE IF NOT EXISTS usage_records (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    model_id VARCHAR(64) NOT NULL DEFAULT '',
+    model_name VARCHAR(255) NOT NULL DEFAULT '',
+    model_type VARCHAR(32) NOT NULL DEFAULT '',
+    session_id VARCHAR(36) NOT NULL DEFAULT '',
+    agent_id VARCHAR(36) NOT NULL DEFAULT '',
+    source VARCHAR(128) NOT NULL DEFAULT '',
+    input_count INTEGER NOT NULL DEFAULT 0,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    estimated BOOLEAN NOT NULL DEFAULT FALSE,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_records_created_at ON usage_records(created_at);
+CREATE INDEX IF NOT EXISTS idx_usage_records_tenant_created_at ON usage_records(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_usage_records_session_id ON usage_records(session_id);
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000016] Creating table: usage_daily'; END $$;
+CREATE TABLE IF NOT EXISTS usage_daily (
+    tenant_id INTEGER NOT NULL,
+    day DATE NOT NULL,
+    model_id VARCHAR(64) NOT NULL DEFAULT '',
+    model_type VARCHAR(32) NOT NULL DEFAULT '',
+    agent_id VARCHAR(36) NOT NULL DEFAULT '',
+    source VARCHAR(128) NOT NULL DEFAULT '',
+    model_name VARCHAR(255) NOT NULL DEFAULT '',
+    calls BIGINT NOT NULL DEFAULT 0,
+    input_count BIGINT NOT NULL DEFAULT 0,
+    prompt_tokens BIGINT NOT NULL DEFAULT 0,
+    completion_tokens BIGINT NOT NULL DEFAULT 0,
+    total_tokens BIGINT NOT NULL DEFAULT 0,
+    estimated_calls BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, day, model_id, model_type, agent_id, source)
+);
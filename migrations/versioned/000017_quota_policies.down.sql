@@ -0,0 +1,4 @@
+-- Migration: 000017_quota_policies (rollback)
+-- Description: Remove quota policies
+DO $$ BEGIN RAISE NOTICE '[Migration 000017 DOWN] Dropping table: quota_policies'; END $$;
+DROP TABLE IF EXISTS quota_policies;
@@ -0,0 +1,15 @@
+-- Migration: 000017_quota_policies
+-- Description: Add per-subject quota policies overriding the configured default limits
+DO $$ BEGIN RAISE NOTICE '[Migration 000017] Creating table: quota_policies'; END $$;
+CREATE TABLE IF NOT EXISTS quota_policies (
+    tenant_id INTEGER NOT NULL,
+    subject_type VARCHAR(16) NOT NULL,
+    subject_id VARCHAR(64) NOT NULL DEFAULT '',
+    requests_per_minute INTEGER NOT NULL DEFAULT 0,
+    concurrent_streams INTEGER NOT NULL DEFAULT 0,
+    daily_tokens BIGINT NOT NULL DEFAULT 0,
+    daily_ingestion_bytes BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, subject_type, subject_id)
+);
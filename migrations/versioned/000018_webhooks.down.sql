@@ -0,0 +1,7 @@
+-- Migration: 000018_webhooks (rollback)
+-- Description: Remove webhook subscriptions and their delivery log
+DO $$ BEGIN RAISE NOTICE '[Migration 000018 DOWN] Dropping table: webhook_deliveries'; END $$;
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000018 DOWN] Dropping table: webhooks'; END $$;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,9 @@
+-- Migration: 000019_message_feedback (rollback)
+-- Description: Remove message feedback and the chunk relevance signals
+DO $$ BEGIN RAISE NOTICE '[Migration 000019 DOWN] Dropping table: chunk_feedback_signals'; END $$;
+DROP TABLE IF EXISTS chunk_feedback_signals;
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000019 DOWN] Dropping feedback columns from table: messages'; END $$;
+DROP INDEX IF EXISTS idx_messages_feedback_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS feedback_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS feedback;
@@ -0,0 +1,20 @@
+-- Migration: 000019_message_feedback
+-- Description: Add feedback on assistant messages and the per-chunk relevance signals aggregated from it
+DO $$ BEGIN RAISE NOTICE '[Migration 000019] Adding feedback columns to table: messages'; END $$;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS feedback JSONB DEFAULT NULL;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS feedback_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_feedback_at ON messages(feedback_at) WHERE feedback_at IS NOT NULL;
+
+COMMENT ON COLUMN messages.feedback IS 'Rating, reason and per-reference relevance submitted for an assistant message';
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000019] Creating table: chunk_feedback_signals'; END $$;
+CREATE TABLE IF NOT EXISTS chunk_feedback_signals (
+    tenant_id INTEGER NOT NULL,
+    chunk_id VARCHAR(36) NOT NULL,
+    knowledge_id VARCHAR(36),
+    positive DOUBLE PRECISION NOT NULL DEFAULT 0,
+    negative DOUBLE PRECISION NOT NULL DEFAULT 0,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, chunk_id)
+);
@@ -0,0 +1,13 @@
+-- Migration: 000020_evaluation_runs (rollback)
+-- Description: Remove evaluation datasets and runs
+DO $$ BEGIN RAISE NOTICE '[Migration 000020 DOWN] Dropping table: evaluation_run_items'; END $$;
+DROP TABLE IF EXISTS evaluation_run_items;
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000020 DOWN] Dropping table: evaluation_runs'; END $$;
+DROP TABLE IF EXISTS evaluation_runs;
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000020 DOWN] Dropping table: evaluation_dataset_items'; END $$;
+DROP TABLE IF EXISTS evaluation_dataset_items;
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000020 DOWN] Dropping table: evaluation_datasets'; END $$;
+DROP TABLE IF EXISTS evaluation_datasets;
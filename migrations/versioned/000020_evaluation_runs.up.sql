@@ -0,0 +1,67 @@
+-- Migration: 000020_evaluation_runs
+-- Description: Persist uploaded evaluation datasets and evaluation runs with per-question results
+DO $$ BEGIN RAISE NOTICE '[Migration 000020] Creating table: evaluation_datasets'; END $$;
+CREATE TABLE IF NOT EXISTS evaluation_datasets (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    format VARCHAR(16) NOT NULL,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    created_by VARCHAR(36),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_evaluation_datasets_tenant_id ON evaluation_datasets(tenant_id);
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000020] Creating table: evaluation_dataset_items'; END $$;
+CREATE TABLE IF NOT EXISTS evaluation_dataset_items (
+    id BIGSERIAL PRIMARY KEY,
+    dataset_id VARCHAR(36) NOT NULL,
+    tenant_id INTEGER NOT NULL,
+    seq INTEGER NOT NULL,
+    question TEXT NOT NULL,
+    answer TEXT,
+    gold_chunk_ids JSON
+);
+CREATE INDEX IF NOT EXISTS idx_evaluation_dataset_items_dataset ON evaluation_dataset_items(dataset_id, seq);
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000020] Creating table: evaluation_runs'; END $$;
+CREATE TABLE IF NOT EXISTS evaluation_runs (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    name VARCHAR(255),
+    dataset_id VARCHAR(36) NOT NULL,
+    knowledge_base_ids JSON,
+    agent_id VARCHAR(36),
+    config JSONB,
+    status INTEGER NOT NULL DEFAULT 0,
+    err_msg TEXT,
+    total INTEGER NOT NULL DEFAULT 0,
+    finished INTEGER NOT NULL DEFAULT 0,
+    metric JSONB,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(36),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_evaluation_runs_tenant ON evaluation_runs(tenant_id, created_at DESC);
+
+DO $$ BEGIN RAISE NOTICE '[Migration 000020] Creating table: evaluation_run_items'; END $$;
+CREATE TABLE IF NOT EXISTS evaluation_run_items (
+    id BIGSERIAL PRIMARY KEY,
+    run_id VARCHAR(36) NOT NULL,
+    tenant_id INTEGER NOT NULL,
+    seq INTEGER NOT NULL,
+    question TEXT NOT NULL,
+    expected_answer TEXT,
+    gold_chunk_ids JSON,
+    retrieved_chunk_ids JSON,
+    answer TEXT,
+    metric JSONB,
+    error TEXT,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_evaluation_run_items_run ON evaluation_run_items(run_id, seq);
@@ -0,0 +1,4 @@
+-- Migration: 000021_tool_approvals (rollback)
+-- Description: Remove agent tool call approvals
+DO $$ BEGIN RAISE NOTICE '[Migration 000021 DOWN] Dropping table: tool_approvals'; END $$;
+DROP TABLE IF EXISTS tool_approvals;
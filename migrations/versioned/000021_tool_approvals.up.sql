@@ -0,0 +1,26 @@
+-- Migration: 000021_tool_approvals
+-- Description: Add human approval records for agent tool calls
+DO $$ BEGIN RAISE NOTICE '[Migration 000021] Creating table: tool_approvals'; END $$;
+CREATE TABLE IF NOT EXISTS tool_approvals (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    session_id VARCHAR(36) NOT NULL,
+    message_id VARCHAR(36),
+    tool_call_id VARCHAR(128),
+    tool_name VARCHAR(255) NOT NULL,
+    mcp_service_id VARCHAR(36),
+    iteration INTEGER NOT NULL DEFAULT 0,
+    arguments JSONB,
+    approved_arguments JSONB,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    comment TEXT,
+    decided_by VARCHAR(36),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_tool_approvals_session ON tool_approvals(tenant_id, session_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_tool_approvals_pending ON tool_approvals(expires_at) WHERE status = 'pending';
+
+COMMENT ON TABLE tool_approvals IS 'Agent tool calls held for a human decision, with the arguments approved for execution';
@@ -0,0 +1,4 @@
+-- Migration: 000022_agent_runs (rollback)
+-- Description: Remove checkpointed agent runs
+DO $$ BEGIN RAISE NOTICE '[Migration 000022 DOWN] Dropping table: agent_runs'; END $$;
+DROP TABLE IF EXISTS agent_runs;
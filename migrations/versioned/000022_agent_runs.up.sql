@@ -0,0 +1,30 @@
+-- Migration: 000022_agent_runs
+-- Description: Add checkpointed agent runs so that paused or interrupted runs can be resumed
+DO $$ BEGIN RAISE NOTICE '[Migration 000022] Creating table: agent_runs'; END $$;
+CREATE TABLE IF NOT EXISTS agent_runs (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    session_id VARCHAR(36) NOT NULL,
+    message_id VARCHAR(36) NOT NULL,
+    agent_id VARCHAR(36),
+    query TEXT,
+    config JSONB,
+    model_id VARCHAR(64),
+    rerank_model_id VARCHAR(64),
+    user_id VARCHAR(36),
+    user_role VARCHAR(32),
+    status VARCHAR(16) NOT NULL DEFAULT 'running',
+    round INTEGER NOT NULL DEFAULT 0,
+    state JSONB,
+    messages JSONB,
+    owner VARCHAR(64),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agent_runs_message ON agent_runs(message_id);
+CREATE INDEX IF NOT EXISTS idx_agent_runs_stale ON agent_runs(heartbeat_at) WHERE status IN ('running', 'interrupted');
+
+COMMENT ON TABLE agent_runs IS 'Agent runs with the checkpoint of their last completed ReAct round';
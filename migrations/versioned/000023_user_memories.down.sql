@@ -0,0 +1,4 @@
+-- Migration: 000023_user_memories (rollback)
+-- Description: Remove long-term user memories
+DO $$ BEGIN RAISE NOTICE '[Migration 000023 DOWN] Dropping table: user_memories'; END $$;
+DROP TABLE IF EXISTS user_memories;
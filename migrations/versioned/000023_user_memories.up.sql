@@ -0,0 +1,19 @@
+-- Migration: 000023_user_memories
+-- Description: Add long-term user memories extracted from conversations
+DO $$ BEGIN RAISE NOTICE '[Migration 000023] Creating table: user_memories'; END $$;
+CREATE TABLE IF NOT EXISTS user_memories (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    category VARCHAR(16) NOT NULL DEFAULT 'fact',
+    content TEXT NOT NULL,
+    embedding JSONB,
+    embedding_model_id VARCHAR(36),
+    source_session_id VARCHAR(36),
+    source_message_id VARCHAR(36),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_user_memories_user ON user_memories(tenant_id, user_id, updated_at DESC);
+
+COMMENT ON TABLE user_memories IS 'Long-term facts and preferences of users, injected into system prompts';